@@ -0,0 +1,227 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"time"
+
+	ark_config "github.com/andresgarcia29/ark-cli/config"
+	"github.com/andresgarcia29/ark-cli/logs"
+	"github.com/andresgarcia29/ark-cli/paths"
+)
+
+// Kinds of audited events
+const (
+	// EventCredentials is recorded when ark writes role credentials to the credentials file
+	EventCredentials = "credentials"
+	// EventExec is recorded when kubectl runs `ark kubernetes token` through an exec block
+	EventExec = "exec"
+	// EventBreakGlass is recorded before credentials are issued for a break-glass role
+	EventBreakGlass = "break-glass"
+)
+
+// maxWebhookResponse bounds how much of a webhook response is drained
+const maxWebhookResponse = 64 << 10
+
+// Event is one line of the audit log
+type Event struct {
+	Time       time.Time `json:"time"`
+	Event      string    `json:"event"`
+	User       string    `json:"user"`
+	Hostname   string    `json:"hostname"`
+	Profile    string    `json:"profile,omitempty"`
+	AccountID  string    `json:"account_id,omitempty"`
+	Role       string    `json:"role,omitempty"`
+	Cluster    string    `json:"cluster,omitempty"`
+	Expiration time.Time `json:"expiration,omitzero"`
+	// Justification is the reason given for a break-glass login
+	Justification string `json:"justification,omitempty"`
+}
+
+// csvHeader lists the columns written by WriteCSV
+var csvHeader = []string{"time", "event", "user", "hostname", "profile", "account_id", "role", "cluster", "expiration", "justification"}
+
+// LogPath returns the audit log file, honoring audit.path
+func LogPath() (string, error) {
+	if path := ark_config.Get().Audit.Path; path != "" {
+		return path, nil
+	}
+	dir, err := paths.LogDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "audit.log"), nil
+}
+
+// Record appends an event to the audit log when audit.enabled is set
+// Time, user and hostname are filled in when empty; failures are logged, never returned,
+// so auditing can't break a login
+func Record(event Event) {
+	if !ark_config.Get().Audit.Enabled {
+		return
+	}
+
+	if err := Append(event); err != nil {
+		logs.GetLogger().Warnw("Failed to write audit log", "event", event.Event, "error", err)
+	}
+}
+
+// Append writes an event to the audit log even when audit.enabled is off
+// It is used for events that must never go unrecorded, so the caller decides what a failure means
+func Append(event Event) error {
+	path, err := LogPath()
+	if err != nil {
+		return err
+	}
+	return appendEvent(path, withDefaults(event))
+}
+
+// Notify POSTs an event as JSON to a webhook, bounded by the AWS timeout of ark's config
+func Notify(ctx context.Context, url string, event Event) error {
+	data, err := json.Marshal(withDefaults(event))
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: ark_config.Get().AWS.Timeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxWebhookResponse))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("POST %s: %s", url, resp.Status)
+	}
+	return nil
+}
+
+// withDefaults fills the fields describing when and by whom the event happened
+func withDefaults(event Event) Event {
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+	if event.User == "" {
+		event.User = "unknown"
+		if current, err := user.Current(); err == nil && current.Username != "" {
+			event.User = current.Username[strings.LastIndex(current.Username, `\`)+1:]
+		}
+	}
+	if event.Hostname == "" {
+		event.Hostname, _ = os.Hostname()
+	}
+	return event
+}
+
+// appendEvent writes an event as one JSON line; O_APPEND keeps concurrent exec runs from interleaving
+func appendEvent(path string, event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit event: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+// ReadEvents reads the events of an audit log recorded at or after since (zero means all)
+// A missing file has no events
+func ReadEvents(path string, since time.Time) ([]Event, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return nil, fmt.Errorf("invalid audit log entry at %s:%d: %w", path, line, err)
+		}
+		if event.Time.Before(since) {
+			continue
+		}
+		events = append(events, event)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return events, nil
+}
+
+// WriteJSON exports events as an indented JSON array
+func WriteJSON(w io.Writer, events []Event) error {
+	if events == nil {
+		events = []Event{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(events)
+}
+
+// WriteCSV exports events as CSV with a header row
+func WriteCSV(w io.Writer, events []Event) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, event := range events {
+		expiration := ""
+		if !event.Expiration.IsZero() {
+			expiration = event.Expiration.UTC().Format(time.RFC3339)
+		}
+		record := []string{
+			event.Time.UTC().Format(time.RFC3339),
+			event.Event,
+			event.User,
+			event.Hostname,
+			event.Profile,
+			event.AccountID,
+			event.Role,
+			event.Cluster,
+			expiration,
+			event.Justification,
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,122 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppendAndReadEvents(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "audit.log")
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	events := []Event{
+		{Time: base.Add(-48 * time.Hour), Event: EventCredentials, User: "alice", Hostname: "laptop", Profile: "old", AccountID: "111111111111", Role: "ReadOnly"},
+		{Time: base, Event: EventExec, User: "alice", Hostname: "laptop", Profile: "prod", Cluster: "main", Expiration: base.Add(15 * time.Minute)},
+	}
+	for _, event := range events {
+		require.NoError(t, appendEvent(path, event))
+	}
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	all, err := ReadEvents(path, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.True(t, all[1].Expiration.Equal(base.Add(15*time.Minute)))
+
+	recent, err := ReadEvents(path, base.Add(-time.Hour))
+	require.NoError(t, err)
+	require.Len(t, recent, 1)
+	assert.Equal(t, "prod", recent[0].Profile)
+}
+
+func TestReadEventsMissingFile(t *testing.T) {
+	events, err := ReadEvents(filepath.Join(t.TempDir(), "audit.log"), time.Time{})
+	require.NoError(t, err)
+	assert.Empty(t, events)
+}
+
+func TestReadEventsInvalidLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	require.NoError(t, os.WriteFile(path, []byte("{\"event\":\"exec\"}\n\nnot json\n"), 0600))
+
+	_, err := ReadEvents(path, time.Time{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "audit.log:3")
+}
+
+func TestWithDefaults(t *testing.T) {
+	event := withDefaults(Event{Event: EventCredentials})
+	assert.False(t, event.Time.IsZero())
+	assert.NotEmpty(t, event.User)
+
+	fixed := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	event = withDefaults(Event{Time: fixed, User: "bob", Hostname: "ci"})
+	assert.Equal(t, fixed, event.Time)
+	assert.Equal(t, "bob", event.User)
+	assert.Equal(t, "ci", event.Hostname)
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteJSON(&buf, nil))
+	assert.Equal(t, "[]\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, WriteJSON(&buf, []Event{{Event: EventExec, Cluster: "main"}}))
+	var decoded []map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	require.Len(t, decoded, 1)
+	assert.Equal(t, "main", decoded[0]["cluster"])
+	assert.NotContains(t, decoded[0], "expiration")
+}
+
+func TestWriteCSV(t *testing.T) {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var buf bytes.Buffer
+	require.NoError(t, WriteCSV(&buf, []Event{
+		{Time: at, Event: EventCredentials, User: "alice", Hostname: "laptop", Profile: "prod", AccountID: "222222222222", Role: "Admin", Expiration: at.Add(time.Hour)},
+		{Time: at, Event: EventExec, User: "alice", Hostname: "laptop", Cluster: "main"},
+		{Time: at, Event: EventBreakGlass, User: "alice", Hostname: "laptop", Profile: "prod-emergency", Role: "AdminBreakGlass", Justification: "INC-1234, db outage"},
+	}))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 4)
+	assert.Equal(t, strings.Join(csvHeader, ","), lines[0])
+	assert.Equal(t, "2026-03-01T12:00:00Z,credentials,alice,laptop,prod,222222222222,Admin,,2026-03-01T13:00:00Z,", lines[1])
+	assert.Equal(t, "2026-03-01T12:00:00Z,exec,alice,laptop,,,,main,,", lines[2])
+	assert.Equal(t, `2026-03-01T12:00:00Z,break-glass,alice,laptop,prod-emergency,,AdminBreakGlass,,,"INC-1234, db outage"`, lines[3])
+}
+
+func TestNotify(t *testing.T) {
+	var received Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer server.Close()
+
+	require.NoError(t, Notify(context.Background(), server.URL, Event{Event: EventBreakGlass, Justification: "INC-1234"}))
+	assert.Equal(t, "INC-1234", received.Justification)
+	assert.False(t, received.Time.IsZero())
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer failing.Close()
+	assert.ErrorContains(t, Notify(context.Background(), failing.URL, Event{}), "403")
+}
@@ -0,0 +1,171 @@
+package cachefile
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	ark_config "github.com/andresgarcia29/ark-cli/config"
+	"github.com/andresgarcia29/ark-cli/lib"
+	"github.com/andresgarcia29/ark-cli/logs"
+)
+
+// KeyEnvVar provides the cache key as base64, for machines without a keychain such as CI runners
+const KeyEnvVar = "ARK_CACHE_KEY"
+
+// The key is kept in the keychain under this service and account
+const (
+	keychainService = "ark"
+	keychainAccount = "cache-key"
+)
+
+// header starts every encrypted cache file, so plain files written before encryption was enabled are still read
+var header = []byte("ark-encrypted-cache:v1\n")
+
+// Keychain stores the cache key; tests replace it
+type Keychain interface {
+	ReadSecret(service, account string) (string, error)
+	StoreSecret(service, account, secret string) error
+}
+
+// osKeychain is the keychain of the operating system
+type osKeychain struct{}
+
+func (osKeychain) ReadSecret(service, account string) (string, error) {
+	return lib.ReadSecret(service, account)
+}
+
+func (osKeychain) StoreSecret(service, account, secret string) error {
+	return lib.StoreSecret(service, account, secret)
+}
+
+var (
+	keychain Keychain = osKeychain{}
+
+	// key is loaded once per run, since every keychain lookup starts a process
+	keyMu sync.Mutex
+	key   []byte
+)
+
+// Read returns the contents of a cache file, decrypting it when it was written encrypted
+// Decryption doesn't depend on cache.encrypt, so turning it off keeps existing files readable
+func Read(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(data, header) {
+		return data, nil
+	}
+
+	aead, err := cacheCipher(false)
+	if err != nil {
+		return nil, err
+	}
+	sealed := data[len(header):]
+	if len(sealed) < aead.NonceSize() {
+		return nil, fmt.Errorf("encrypted cache file %s is truncated", path)
+	}
+	plain, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(filepath.Base(path)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt cache file %s (was the cache key changed?): %w", path, err)
+	}
+	return plain, nil
+}
+
+// Write stores a cache file readable only by the user, creating its directory
+// With cache.encrypt, the contents are sealed with AES-256-GCM; the key is created in the keychain on first use
+func Write(path string, data []byte) error {
+	return write(path, data, ark_config.Get().Cache.Encrypt)
+}
+
+// write stores a cache file, encrypted or not
+func write(path string, data []byte, encrypt bool) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	if !encrypt {
+		return os.WriteFile(path, data, 0600)
+	}
+
+	aead, err := cacheCipher(true)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	// The file name is authenticated, so one cache file can't be swapped for another
+	sealed := aead.Seal(nonce, nonce, data, []byte(filepath.Base(path)))
+	return os.WriteFile(path, append(append([]byte(nil), header...), sealed...), 0600)
+}
+
+// cacheCipher returns the AES-GCM cipher of the cache key, creating the key when create is set
+func cacheCipher(create bool) (cipher.AEAD, error) {
+	key, err := cacheKey(create)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cache key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// cacheKey returns the 256-bit cache key from ARK_CACHE_KEY or the keychain
+func cacheKey(create bool) ([]byte, error) {
+	keyMu.Lock()
+	defer keyMu.Unlock()
+	if key != nil {
+		return key, nil
+	}
+
+	encoded := os.Getenv(KeyEnvVar)
+	source := KeyEnvVar
+	if encoded == "" {
+		source = "the keychain"
+		secret, err := keychain.ReadSecret(keychainService, keychainAccount)
+		switch {
+		case errors.Is(err, lib.ErrSecretNotFound) && create:
+			if encoded, err = createKey(); err != nil {
+				return nil, err
+			}
+		case errors.Is(err, lib.ErrSecretNotFound):
+			return nil, fmt.Errorf("the cache is encrypted but the keychain has no cache key (set %s or remove the cache)", KeyEnvVar)
+		case err != nil:
+			return nil, fmt.Errorf("failed to read the cache key from the keychain (set %s on machines without one): %w", KeyEnvVar, err)
+		default:
+			encoded = secret
+		}
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(decoded) != 32 {
+		return nil, fmt.Errorf("the cache key from %s must be 32 bytes encoded as base64", source)
+	}
+	key = decoded
+	return key, nil
+}
+
+// createKey generates a cache key and stores it in the keychain
+func createKey() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate cache key: %w", err)
+	}
+	encoded := base64.StdEncoding.EncodeToString(secret)
+	if err := keychain.StoreSecret(keychainService, keychainAccount, encoded); err != nil {
+		return "", fmt.Errorf("failed to store the cache key in the keychain (set %s on machines without one): %w", KeyEnvVar, err)
+	}
+	logs.GetLogger().Infow("Cache key created in the keychain", "service", keychainService, "account", keychainAccount)
+	return encoded, nil
+}
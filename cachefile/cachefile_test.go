@@ -0,0 +1,119 @@
+package cachefile
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/andresgarcia29/ark-cli/lib"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeKeychain keeps secrets in memory
+type fakeKeychain struct {
+	secrets map[string]string
+	err     error
+}
+
+func (k *fakeKeychain) ReadSecret(service, account string) (string, error) {
+	if k.err != nil {
+		return "", k.err
+	}
+	secret, ok := k.secrets[service+"/"+account]
+	if !ok {
+		return "", lib.ErrSecretNotFound
+	}
+	return secret, nil
+}
+
+func (k *fakeKeychain) StoreSecret(service, account, secret string) error {
+	if k.err != nil {
+		return k.err
+	}
+	k.secrets[service+"/"+account] = secret
+	return nil
+}
+
+// useKeychain replaces the keychain and forgets the loaded key for the duration of a test
+func useKeychain(t *testing.T, k Keychain) {
+	original := keychain
+	keychain, key = k, nil
+	t.Cleanup(func() { keychain, key = original, nil })
+}
+
+func TestWriteAndReadEncrypted(t *testing.T) {
+	t.Setenv(KeyEnvVar, "")
+	fake := &fakeKeychain{secrets: map[string]string{}}
+	useKeychain(t, fake)
+
+	path := filepath.Join(t.TempDir(), "cache", "clusters.json")
+	content := []byte(`{"clusters":[{"name":"prod"}]}`)
+	require.NoError(t, write(path, content, true))
+
+	// The key is created in the keychain on first use
+	assert.Len(t, fake.secrets, 1)
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(raw, header))
+	assert.NotContains(t, string(raw), "prod")
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// A new run loads the same key from the keychain
+	key = nil
+	read, err := Read(path)
+	require.NoError(t, err)
+	assert.Equal(t, content, read)
+
+	// A file renamed to another cache entry fails authentication
+	renamed := filepath.Join(filepath.Dir(path), "contexts.json")
+	require.NoError(t, os.Rename(path, renamed))
+	_, err = Read(renamed)
+	assert.ErrorContains(t, err, "failed to decrypt cache file")
+}
+
+func TestReadPlainFile(t *testing.T) {
+	// Plain files don't need a key, so they are read even when the keychain is unavailable
+	useKeychain(t, &fakeKeychain{err: errors.New("no keychain")})
+
+	path := filepath.Join(t.TempDir(), "clusters.json")
+	require.NoError(t, write(path, []byte(`{}`), false))
+	read, err := Read(path)
+	require.NoError(t, err)
+	assert.Equal(t, []byte(`{}`), read)
+}
+
+func TestCacheKeyFromEnvironment(t *testing.T) {
+	useKeychain(t, &fakeKeychain{err: errors.New("no keychain")})
+	t.Setenv(KeyEnvVar, base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, 32)))
+
+	path := filepath.Join(t.TempDir(), "clusters.json")
+	require.NoError(t, write(path, []byte(`{}`), true))
+	read, err := Read(path)
+	require.NoError(t, err)
+	assert.Equal(t, []byte(`{}`), read)
+
+	key = nil
+	t.Setenv(KeyEnvVar, "c2hvcnQ=")
+	_, err = Read(path)
+	assert.EqualError(t, err, "the cache key from ARK_CACHE_KEY must be 32 bytes encoded as base64")
+}
+
+func TestCacheKeyMissing(t *testing.T) {
+	t.Setenv(KeyEnvVar, "")
+	useKeychain(t, &fakeKeychain{secrets: map[string]string{}})
+
+	path := filepath.Join(t.TempDir(), "clusters.json")
+	require.NoError(t, os.WriteFile(path, append(append([]byte(nil), header...), make([]byte, 40)...), 0600))
+	_, err := Read(path)
+	assert.ErrorContains(t, err, "the keychain has no cache key")
+
+	useKeychain(t, &fakeKeychain{err: errors.New("secret-tool: not found")})
+	err = write(path, []byte(`{}`), true)
+	assert.ErrorContains(t, err, "failed to read the cache key from the keychain (set ARK_CACHE_KEY on machines without one): secret-tool: not found")
+}
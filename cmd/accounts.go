@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	"github.com/spf13/cobra"
+)
+
+var (
+	accountsCmd = &cobra.Command{
+		Use:   "accounts",
+		Short: "Work with the accounts of the SSO portal",
+		Long:  `Work with the accounts and roles of the SSO portal, as discovered by ark aws sso.`,
+	}
+
+	accountsExportCmd = &cobra.Command{
+		Use:   "export",
+		Short: "Print the accounts as aws-nuke or Steampipe config",
+		Long: `Print the accounts and roles of the SSO portal as configuration for other tools, instead of copying them by hand:
+  aws-nuke   the regions, blocklist and accounts of an aws-nuke config file
+  steampipe  an aws connection per account using its profile, and an aws_all aggregator
+
+The inventory is the one cached by the last ark aws sso run; run it again to pick up new accounts.
+Steampipe connections need one role per account: pick it with --roles when accounts have several.`,
+		Args: cobra.NoArgs,
+		Run:  accountsExport,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(accountsCmd)
+	accountsCmd.AddCommand(accountsExportCmd)
+	accountsExportCmd.Flags().String("format", "", "Format to export: aws-nuke or steampipe")
+	accountsExportCmd.Flags().String("start-url", "", "AWS SSO start URL (default: the only portal of the SSO profiles)")
+	accountsExportCmd.Flags().StringSlice("accounts", nil, "Accounts to export, by ID or name, with globs, e.g. 'sandbox-*' (default: every account)")
+	accountsExportCmd.Flags().StringSlice("roles", nil, "Roles to export, with globs, e.g. ReadOnlyAccess (default: every role)")
+	accountsExportCmd.Flags().StringSlice("regions", []string{"us-east-1"}, "Regions of the exported config")
+	accountsExportCmd.Flags().StringSlice("blocklist", nil, "Accounts aws-nuke must never touch, by ID or name, with globs, e.g. 'prod-*'")
+	accountsExportCmd.Flags().String("output", "", "File to write the config to (default: stdout)")
+	if err := accountsExportCmd.MarkFlagRequired("format"); err != nil {
+		panic(err)
+	}
+}
+
+func accountsExport(cmd *cobra.Command, args []string) {
+	value, _ := cmd.Flags().GetString("format")
+	startURL, _ := cmd.Flags().GetString("start-url")
+	accounts, _ := cmd.Flags().GetStringSlice("accounts")
+	roles, _ := cmd.Flags().GetStringSlice("roles")
+	regions, _ := cmd.Flags().GetStringSlice("regions")
+	blocklist, _ := cmd.Flags().GetStringSlice("blocklist")
+	output, _ := cmd.Flags().GetString("output")
+
+	// Errors go to stderr, so `ark accounts export > nuke.yaml` never writes one into the file
+	exported, err := exportPortalAccounts(value, startURL, services_aws.ProfileFilter{Accounts: accounts, Roles: roles}, regions, blocklist)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+
+	if output == "" {
+		fmt.Print(exported)
+		return
+	}
+	if err := os.WriteFile(output, []byte(exported), 0644); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+	fmt.Printf("✓ Wrote the %s config to %s\n", value, output)
+}
+
+// exportPortalAccounts renders the cached accounts and roles of the portal matching filter, naming profiles as
+// they are named in ~/.aws/config
+func exportPortalAccounts(value, startURL string, filter services_aws.ProfileFilter, regions, blocklist []string) (string, error) {
+	format, err := services_aws.ParseAccountExportFormat(value)
+	if err != nil {
+		return "", err
+	}
+	portals, err := services_aws.ConfiguredSSOPortals()
+	if err != nil {
+		return "", err
+	}
+	portal, err := chooseSSOPortal(startURL, "", portals)
+	if errors.Is(err, errNoSSOPortal) {
+		return "", errors.New("no SSO portal configured: run `ark aws sso` first, or pass --start-url")
+	}
+	if err != nil {
+		return "", err
+	}
+
+	cache, err := services_aws.LoadProfileCache(portal.StartURL)
+	if errors.Is(err, services_aws.ErrNoDiscoveryCache) {
+		return "", fmt.Errorf("no accounts cached for %s: run `ark aws sso --start-url %s` first", portal.StartURL, portal.StartURL)
+	}
+	if err != nil {
+		return "", err
+	}
+	profiles, err := services_aws.FilterAWSProfiles(cache.Profiles, filter)
+	if err != nil {
+		return "", err
+	}
+	if len(profiles) == 0 {
+		return "", errors.New("no account and role matches --accounts and --roles")
+	}
+
+	names := make(map[string]string)
+	if configured, err := services_aws.ReadAllProfilesFromConfig(); err == nil {
+		for _, profile := range configured {
+			if profile.StartURL != portal.StartURL {
+				continue
+			}
+			accountID, roleName := services_aws.ProfileAccountAndRole(profile)
+			key := accountID + "/" + roleName
+			if _, ok := names[key]; !ok {
+				names[key] = profile.ProfileName
+			}
+		}
+	}
+
+	return services_aws.ExportAccounts(format, services_aws.AccountExport{
+		StartURL:     portal.StartURL,
+		Profiles:     profiles,
+		Regions:      regions,
+		Blocklist:    blocklist,
+		ProfileNames: names,
+	})
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportPortalAccounts(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	startURL := "https://example.awsapps.com/start"
+
+	_, err := exportPortalAccounts("steampipe", startURL, services_aws.ProfileFilter{}, []string{"us-east-1"}, nil)
+	assert.ErrorContains(t, err, "no accounts cached for "+startURL)
+
+	require.NoError(t, services_aws.SaveProfileCache(startURL, []services_aws.AWSProfile{
+		{AccountID: "111111111111", AccountName: "prod", RoleName: "AdministratorAccess"},
+		{AccountID: "111111111111", AccountName: "prod", RoleName: "ReadOnlyAccess"},
+		{AccountID: "222222222222", AccountName: "sandbox", RoleName: "ReadOnlyAccess"},
+	}))
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".aws"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".aws", "config"), []byte(`[profile prod-ro]
+sso_start_url = `+startURL+`
+sso_region = us-east-1
+sso_account_id = 111111111111
+sso_role_name = ReadOnlyAccess
+`), 0600))
+
+	// The only configured portal is used without --start-url, and configured profiles keep their names
+	exported, err := exportPortalAccounts("steampipe", "", services_aws.ProfileFilter{Roles: []string{"readonly*"}}, []string{"us-east-1"}, nil)
+	require.NoError(t, err)
+	assert.Contains(t, exported, `profile = "prod-ro"`)
+	assert.Contains(t, exported, `connection "aws_sandbox"`)
+
+	_, err = exportPortalAccounts("steampipe", "", services_aws.ProfileFilter{Accounts: []string{"staging"}}, nil, nil)
+	assert.EqualError(t, err, "no account and role matches --accounts and --roles")
+
+	_, err = exportPortalAccounts("terraform", "", services_aws.ProfileFilter{}, nil, nil)
+	assert.ErrorContains(t, err, "unknown format")
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// expandAlias replaces an alias in the first argument with the arguments it stands for, keeping the rest,
+// so `ark prod-k8s --yes` runs `ark kubernetes setup ... --yes`. Built-in commands always win over aliases,
+// and aliases may use other aliases
+func expandAlias(root *cobra.Command, args []string, aliases map[string]string) ([]string, error) {
+	var seen []string
+	for len(args) > 0 && !isBuiltinCommand(root, args[0]) {
+		expansion, ok := aliases[args[0]]
+		if !ok {
+			break
+		}
+		if slices.Contains(seen, args[0]) {
+			return nil, fmt.Errorf("alias loop: %s -> %s", strings.Join(seen, " -> "), args[0])
+		}
+		seen = append(seen, args[0])
+
+		words, err := splitAliasArgs(expansion)
+		if err != nil {
+			return nil, fmt.Errorf("invalid alias %s: %w", args[0], err)
+		}
+		if len(words) == 0 {
+			return nil, fmt.Errorf("alias %s is empty", args[0])
+		}
+		args = append(words, args[1:]...)
+	}
+	return args, nil
+}
+
+// isBuiltinCommand reports whether name is a command of root or one of its aliases
+func isBuiltinCommand(root *cobra.Command, name string) bool {
+	// cobra adds help and completion when executing
+	if name == "help" || name == "completion" {
+		return true
+	}
+	for _, cmd := range root.Commands() {
+		if cmd.Name() == name || cmd.HasAlias(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitAliasArgs splits an alias into arguments like a shell: on spaces, with single and double quotes
+// keeping spaces and backslashes escaping the next character outside single quotes
+func splitAliasArgs(s string) ([]string, error) {
+	var (
+		args    []string
+		current strings.Builder
+		inWord  bool
+		quote   rune
+		escaped bool
+	)
+	for _, r := range s {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inWord = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				args = append(args, current.String())
+				current.Reset()
+				inWord = false
+			}
+		default:
+			current.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if escaped {
+		return nil, fmt.Errorf("trailing backslash")
+	}
+	if inWord {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandAlias(t *testing.T) {
+	root := &cobra.Command{Use: "ark"}
+	root.AddCommand(&cobra.Command{Use: "kubernetes", Aliases: []string{"k8s"}})
+	root.AddCommand(&cobra.Command{Use: "regions"})
+
+	aliases := map[string]string{
+		"prod-k8s": "kubernetes setup --role-prefixs prod --regions us-east-1",
+		"prod":     "prod-k8s --yes",
+		"regions":  "regions --latency",
+		"k8s":      "kubernetes list",
+		"loop-a":   "loop-b",
+		"loop-b":   "loop-a --debug",
+		"empty":    "  ",
+		"broken":   `kubernetes setup --regions "us-east-1`,
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected []string
+		err      string
+	}{
+		{name: "no arguments", args: nil, expected: nil},
+		{name: "not an alias", args: []string{"ctx", "-"}, expected: []string{"ctx", "-"}},
+		{
+			name:     "alias with extra arguments",
+			args:     []string{"prod-k8s", "--clean"},
+			expected: []string{"kubernetes", "setup", "--role-prefixs", "prod", "--regions", "us-east-1", "--clean"},
+		},
+		{
+			name:     "alias of an alias",
+			args:     []string{"prod"},
+			expected: []string{"kubernetes", "setup", "--role-prefixs", "prod", "--regions", "us-east-1", "--yes"},
+		},
+		{name: "built-in commands win", args: []string{"regions"}, expected: []string{"regions"}},
+		{name: "built-in command aliases win", args: []string{"k8s"}, expected: []string{"k8s"}},
+		{name: "loop", args: []string{"loop-a"}, err: "alias loop: loop-a -> loop-b -> loop-a"},
+		{name: "empty", args: []string{"empty"}, err: "alias empty is empty"},
+		{name: "unterminated quote", args: []string{"broken"}, err: "invalid alias broken: unterminated \" quote"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, err := expandAlias(root, tt.args, aliases)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, args)
+		})
+	}
+}
+
+func TestSplitAliasArgs(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{input: "kubernetes setup", expected: []string{"kubernetes", "setup"}},
+		{input: "  spaced \t out  ", expected: []string{"spaced", "out"}},
+		{input: `env --cluster "prod main"`, expected: []string{"env", "--cluster", "prod main"}},
+		{input: `k8s setup --context-alias '{{.Cluster}} \n'`, expected: []string{"k8s", "setup", "--context-alias", `{{.Cluster}} \n`}},
+		{input: `a\ b "say \"hi\"" ''`, expected: []string{"a b", `say "hi"`, ""}},
+		{input: "", expected: nil},
+	}
+	for _, tt := range tests {
+		args, err := splitAliasArgs(tt.input)
+		require.NoError(t, err, tt.input)
+		assert.Equal(t, tt.expected, args, tt.input)
+	}
+
+	_, err := splitAliasArgs(`it's`)
+	assert.EqualError(t, err, "unterminated ' quote")
+	_, err = splitAliasArgs(`trailing\`)
+	assert.EqualError(t, err, "trailing backslash")
+}
+
+func TestRootCommandsAreBuiltin(t *testing.T) {
+	assert.True(t, isBuiltinCommand(rootCmd, "kubernetes"))
+	assert.True(t, isBuiltinCommand(rootCmd, "eks"))
+	assert.True(t, isBuiltinCommand(rootCmd, "help"))
+	assert.False(t, isBuiltinCommand(rootCmd, "prod-k8s"))
+}
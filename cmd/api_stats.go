@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/andresgarcia29/ark-cli/lib/animation"
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+)
+
+// printAPICallStats prints the AWS API calls made during the run (shown with --verbose)
+func printAPICallStats(elapsed time.Duration) {
+	stats := services_aws.APICallStats()
+	summary := services_aws.SummarizeAPICalls()
+
+	fmt.Printf("\nRun finished in %s\n", elapsed.Round(time.Millisecond))
+	if len(stats) == 0 {
+		fmt.Println("No AWS API calls were made")
+		return
+	}
+
+	fmt.Print(buildAPICallStatsTable(stats).Render())
+	fmt.Printf("Total: %d calls, %d retries, %d errors, %s spent in AWS APIs\n",
+		summary.Calls, summary.Attempts-summary.Calls, summary.Errors, summary.Duration.Round(time.Millisecond))
+}
+
+// buildAPICallStatsTable lays out per-operation call statistics as a table
+func buildAPICallStatsTable(stats []services_aws.APICallStat) *animation.Table {
+	table := animation.NewTable("Service", "Operation", "Calls", "Retries", "Errors", "Duration")
+	for _, stat := range stats {
+		table.AddRow(
+			stat.Service,
+			stat.Operation,
+			strconv.Itoa(stat.Calls),
+			strconv.Itoa(stat.Attempts-stat.Calls),
+			strconv.Itoa(stat.Errors),
+			stat.Duration.Round(time.Millisecond).String(),
+		)
+	}
+	return table
+}
@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildAPICallStatsTable(t *testing.T) {
+	table := buildAPICallStatsTable([]services_aws.APICallStat{
+		{Service: "EKS", Operation: "ListClusters", Calls: 4, Attempts: 7, Errors: 1, Duration: 1500 * time.Millisecond},
+		{Service: "SSO", Operation: "ListAccounts", Calls: 1, Attempts: 1, Duration: 200 * time.Millisecond},
+	})
+
+	lines := strings.Split(strings.TrimRight(table.Render(), "\n"), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, []string{"EKS", "ListClusters", "4", "3", "1", "1.5s"}, strings.Fields(lines[1]))
+	assert.Equal(t, []string{"SSO", "ListAccounts", "1", "0", "0", "200ms"}, strings.Fields(lines[2]))
+}
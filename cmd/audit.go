@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/andresgarcia29/ark-cli/audit"
+	"github.com/spf13/cobra"
+)
+
+var (
+	auditCmd = &cobra.Command{
+		Use:   "audit",
+		Short: "Inspect the local audit log of issued credentials",
+		Long: `ark can append every credential it issues (logins and kubectl exec runs) to a local audit log.
+Enable it with audit.enabled in the ark config file.`,
+	}
+
+	auditExportCmd = &cobra.Command{
+		Use:   "export",
+		Short: "Export the audit log as JSON or CSV",
+		Long:  `Export the audit log as JSON or CSV for compliance reviews, optionally limited to recent events.`,
+		Run:   auditExport,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+	auditCmd.AddCommand(auditExportCmd)
+	auditExportCmd.Flags().String("format", "json", "Output format: json or csv")
+	auditExportCmd.Flags().Duration("since", 0, "Only export events from this long ago, e.g. 720h (0 exports everything)")
+	auditExportCmd.Flags().StringP("output", "o", "", "File to write (default: stdout)")
+}
+
+func auditExport(cmd *cobra.Command, args []string) {
+	format, _ := cmd.Flags().GetString("format")
+	since, _ := cmd.Flags().GetDuration("since")
+	outputPath, _ := cmd.Flags().GetString("output")
+
+	if err := exportAuditLog(cmd.OutOrStdout(), format, since, outputPath); err != nil {
+		fmt.Println("Error:", err)
+	}
+}
+
+// exportAuditLog writes the audit events of the last since (0 means all) to outputPath, or to stdout when empty
+func exportAuditLog(stdout io.Writer, format string, since time.Duration, outputPath string) error {
+	write, err := auditWriter(format)
+	if err != nil {
+		return err
+	}
+
+	path, err := audit.LogPath()
+	if err != nil {
+		return err
+	}
+	var from time.Time
+	if since > 0 {
+		from = time.Now().Add(-since)
+	}
+	events, err := audit.ReadEvents(path, from)
+	if err != nil {
+		return err
+	}
+
+	out := stdout
+	if outputPath != "" {
+		file, err := os.OpenFile(outputPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", outputPath, err)
+		}
+		defer file.Close()
+		out = file
+	}
+	return write(out, events)
+}
+
+// auditWriter returns the exporter for a --format value
+func auditWriter(format string) (func(io.Writer, []audit.Event) error, error) {
+	switch format {
+	case "json":
+		return audit.WriteJSON, nil
+	case "csv":
+		return audit.WriteCSV, nil
+	default:
+		return nil, fmt.Errorf("unknown format %q (expected json or csv)", format)
+	}
+}
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/andresgarcia29/ark-cli/audit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditWriter(t *testing.T) {
+	events := []audit.Event{{Event: audit.EventExec, Cluster: "main"}}
+
+	write, err := auditWriter("csv")
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, write(&buf, events))
+	assert.Contains(t, buf.String(), "time,event,user")
+
+	write, err = auditWriter("json")
+	require.NoError(t, err)
+	buf.Reset()
+	require.NoError(t, write(&buf, events))
+	assert.Contains(t, buf.String(), `"cluster": "main"`)
+
+	_, err = auditWriter("xml")
+	assert.ErrorContains(t, err, "unknown format")
+}
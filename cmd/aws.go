@@ -2,8 +2,11 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 
+	ark_config "github.com/andresgarcia29/ark-cli/config"
 	controllers "github.com/andresgarcia29/ark-cli/controllers/aws"
 	animation "github.com/andresgarcia29/ark-cli/lib/animation"
 	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
@@ -13,9 +16,11 @@ import (
 var (
 	awsCmd = &cobra.Command{
 		Use:   "aws",
-		Short: "AWS related operations",
-		Long:  `AWS related operations - Interactive profile selection and login`,
-		Run:   aws,
+		Short: "Pick an AWS profile and log in with it",
+		Long: `Pick a profile of ~/.aws/config in an interactive selector and log in with it. SSO profiles sign in to
+their SSO portal when the session expired, and assume-role profiles assume their role from the source profile.
+The subcommands start SSO sessions, log in without the selector and list the profiles.`,
+		Run: aws,
 	}
 )
 
@@ -29,11 +34,18 @@ func aws(cmd *cobra.Command, args []string) {
 
 	// Show interactive profile selector
 	selectedProfile, err := animation.InteractiveProfileSelector()
+	if errors.Is(err, services_aws.ErrNoAWSConfig) {
+		fmt.Print(firstRunGuide())
+		return
+	}
 	if err != nil {
 		fmt.Printf("❌ Error selecting profile: %v\n", err)
 		return
 	}
 
+	// Nudge towards a read-only role of the same account before using an admin one
+	selectedProfile = suggestLowerPrivilege(selectedProfile)
+
 	// Show selected profile information
 	fmt.Printf("\n✅ Selected profile: %s (%s)\n", selectedProfile.ProfileName, selectedProfile.ProfileType)
 	fmt.Println("🔐 Logging in...")
@@ -45,6 +57,21 @@ func aws(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	// The selected profile becomes the default one
+	if !guardDefaultCredentials() {
+		return
+	}
+
+	if err := preflightWrites(services_aws.CredentialsFilePath); err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	if err := authorizeSelectedBreakGlass(ctx, selectedProfile); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return
+	}
+
 	// Perform login with the selected profile using retry
 	if err := controllers.AttemptLoginWithRetry(ctx, selectedProfile.ProfileName, true, ssoRegion, ssoStartURL); err != nil {
 		fmt.Printf("❌ Login failed after retry: %v\n", err)
@@ -54,3 +81,75 @@ func aws(cmd *cobra.Command, args []string) {
 	fmt.Printf("🎉 Successfully logged in with profile: %s\n", selectedProfile.ProfileName)
 	fmt.Println("💡 You can now use AWS CLI commands with this profile")
 }
+
+// suggestLowerPrivilege offers the account's read-only profile when an admin role was selected
+// It returns the profile to log in with; --yes and aws.least_privilege.enabled=false skip the question
+func suggestLowerPrivilege(selected *services_aws.ProfileConfig) *services_aws.ProfileConfig {
+	if AssumeYes {
+		return selected
+	}
+
+	profiles, err := services_aws.ReadAllProfilesFromConfig()
+	if err != nil {
+		return selected
+	}
+	alternative, ok := services_aws.LowerPrivilegeAlternative(*selected, profiles, ark_config.Get().AWS.LeastPrivilege)
+	if !ok {
+		return selected
+	}
+
+	_, alternativeRole := services_aws.ProfileAccountAndRole(alternative)
+	useAlternative, err := animation.Confirm(
+		fmt.Sprintf("%s uses an admin role. Log in with %s (%s) instead?", selected.ProfileName, alternative.ProfileName, alternativeRole),
+		animation.ConfirmOptions{
+			Details: []string{"Prefer the least privileged role that gets the job done", "Disable this hint with aws.least_privilege.enabled: false"},
+		},
+	)
+	if err != nil || !useAlternative {
+		return selected
+	}
+	return &alternative
+}
+
+// authorizeSelectedBreakGlass asks for a justification when the selected profile uses a break-glass role
+// Picking the role in the selector counts as the --break-glass opt-in of `ark aws login`
+func authorizeSelectedBreakGlass(ctx context.Context, selected *services_aws.ProfileConfig) error {
+	if !services_aws.IsBreakGlassRole(*selected, ark_config.Get().AWS.BreakGlass) {
+		return nil
+	}
+
+	fmt.Printf("🚨 %s uses a break-glass role\n", selected.ProfileName)
+	justification, err := animation.PromptInput("Break-glass justification", animation.InputOptions{
+		Placeholder: "e.g. INC-1234 production outage",
+		Validate:    animation.ValidateRequired,
+	})
+	if err != nil {
+		return err
+	}
+	return controllers.AuthorizeBreakGlass(ctx, selected.ProfileName, true, justification)
+}
+
+// firstRunGuide explains how to create the first profiles when the AWS config doesn't exist yet,
+// instead of reporting that no profiles were found
+func firstRunGuide() string {
+	configPath, err := services_aws.ConfigFilePath()
+	if err != nil {
+		configPath = "~/.aws/config"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "👋 No AWS profiles yet: %s doesn't exist\n", configPath)
+	b.WriteString("   Create a profile for every account and role of your SSO portal with:\n")
+	b.WriteString("     ark bootstrap --start-url https://my-org.awsapps.com/start\n")
+	b.WriteString("   or sign in with `ark aws sso`, which writes the same profiles\n")
+	return b.String()
+}
+
+// printProfilesError prints an error from reading the AWS profiles, with the first run guide on a fresh machine
+func printProfilesError(err error) {
+	if errors.Is(err, services_aws.ErrNoAWSConfig) {
+		fmt.Print(firstRunGuide())
+		return
+	}
+	fmt.Println("Error:", err)
+}
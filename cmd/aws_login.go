@@ -2,9 +2,12 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 
 	controllers "github.com/andresgarcia29/ark-cli/controllers/aws"
+	"github.com/andresgarcia29/ark-cli/lib/animation"
 	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
 	"github.com/spf13/cobra"
 )
@@ -13,8 +16,10 @@ var (
 	awsLoginnCmd = &cobra.Command{
 		Use:   "login",
 		Short: "Start a new AWS Login session",
-		Long:  "Configure and start a new AWS Login session with the provided profile, fetching the credentials from the AWS Login cache",
-		Run:   awsLoginCommand,
+		Long: `Configure and start a new AWS Login session with the provided profile, fetching the credentials from the AWS Login cache.
+With --account and --role, log in to an account and role of the SSO portal that has no profile yet, e.g. before
+the first ark aws sso or when the role is new; --write-profile also adds the profile to ~/.aws/config.`,
+		Run: awsLoginCommand,
 	}
 )
 
@@ -25,21 +30,41 @@ var (
 
 func init() {
 	awsCmd.AddCommand(awsLoginnCmd)
-	awsLoginnCmd.Flags().StringVar(&LoginProfile, "profile", "", "AWS profile name to login with")
+	awsLoginnCmd.Flags().StringVar(&LoginProfile, "profile", "", "AWS profile name to login with (prompted when missing)")
 	awsLoginnCmd.Flags().BoolVar(&SetAsDefault, "set-default", false, "Set this profile as default")
-	if err := awsLoginnCmd.MarkFlagRequired("profile"); err != nil {
-		panic(err)
-	}
+	awsLoginnCmd.Flags().Bool("break-glass", false, "Log in with a break-glass role (requires --justification)")
+	awsLoginnCmd.Flags().String("account", "", "AWS account ID to log in to without a profile (requires --role)")
+	awsLoginnCmd.Flags().String("role", "", "SSO role (permission set) to log in with, used with --account")
+	awsLoginnCmd.Flags().String("start-url", "", "AWS SSO start URL for --account (default: the one of the SSO profiles, prompted when missing)")
+	awsLoginnCmd.Flags().String("sso-region", "", "AWS SSO region for --account (default: the one of the SSO profiles, or us-east-1)")
+	awsLoginnCmd.Flags().Bool("write-profile", false, "With --account, also add the profile to ~/.aws/config")
+	awsLoginnCmd.Flags().String("justification", "", "Reason for break-glass access, recorded in the audit log (prompted when missing)")
 }
 
 func awsLoginCommand(cmd *cobra.Command, args []string) {
-	profileName := cmd.Flag("profile").Value.String()
-	setAsDefault, _ := cmd.Flags().GetBool("set-default")
+	if accountID, _ := cmd.Flags().GetString("account"); accountID != "" {
+		awsLoginAccountRole(cmd, accountID)
+		return
+	}
 
-	if profileName == "" {
-		fmt.Println("Error: --profile flag is required")
+	profileName, err := requireStringFlag(cmd, "profile", "AWS profile", animation.InputOptions{})
+	if err != nil {
+		fmt.Println("Error:", err)
 		return
 	}
+	setAsDefault, _ := cmd.Flags().GetBool("set-default")
+	breakGlass, _ := cmd.Flags().GetBool("break-glass")
+	justification := ""
+	if breakGlass {
+		justification, err = requireStringFlag(cmd, "justification", "Break-glass justification", animation.InputOptions{
+			Placeholder: "e.g. INC-1234 production outage",
+			Validate:    animation.ValidateRequired,
+		})
+		if err != nil {
+			fmt.Println("Error:", err)
+			return
+		}
+	}
 
 	fmt.Printf("Logging in with profile: %s\n", profileName)
 
@@ -52,7 +77,23 @@ func awsLoginCommand(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	fmt.Printf("✅ Resolved SSO configuration - Region: %s, Start URL: %s\n", ssoRegion, ssoStartURL)
+	if ssoStartURL != "" {
+		fmt.Printf("✅ Resolved SSO configuration - Region: %s, Start URL: %s\n", ssoRegion, ssoStartURL)
+	}
+
+	if setAsDefault && !guardDefaultCredentials() {
+		return
+	}
+
+	if err := preflightWrites(services_aws.CredentialsFilePath); err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	if err := controllers.AuthorizeBreakGlass(ctx, profileName, breakGlass, justification); err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
 
 	// Use retry function for login
 	if err := controllers.AttemptLoginWithRetry(ctx, profileName, setAsDefault, ssoRegion, ssoStartURL); err != nil {
@@ -66,3 +107,111 @@ func awsLoginCommand(cmd *cobra.Command, args []string) {
 		fmt.Printf("✓ Successfully logged in with profile '%s'\n", profileName)
 	}
 }
+
+// awsLoginAccountRole logs in to an account and role of the SSO portal that has no profile
+func awsLoginAccountRole(cmd *cobra.Command, accountID string) {
+	roleName, _ := cmd.Flags().GetString("role")
+	if roleName == "" {
+		fmt.Println("Error: --role is required with --account")
+		return
+	}
+	startURL, _ := cmd.Flags().GetString("start-url")
+	ssoRegion, _ := cmd.Flags().GetString("sso-region")
+
+	portals, err := services_aws.ConfiguredSSOPortals()
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	portal, err := chooseSSOPortal(startURL, ssoRegion, portals)
+	if errors.Is(err, errNoSSOPortal) {
+		startURL, err = requireStringFlag(cmd, "start-url", "AWS SSO start URL", animation.InputOptions{
+			Placeholder: "https://my-org.awsapps.com/start",
+			Validate:    animation.ValidateSSOStartURL,
+		})
+		if err == nil {
+			portal, err = chooseSSOPortal(startURL, ssoRegion, portals)
+		}
+	}
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	login := controllers.AccountRoleLogin{
+		SSORegion: portal.Region,
+		StartURL:  portal.StartURL,
+		AccountID: accountID,
+		RoleName:  roleName,
+	}
+	login.ProfileName, _ = cmd.Flags().GetString("profile")
+	login.WriteProfile, _ = cmd.Flags().GetBool("write-profile")
+	login.SetAsDefault, _ = cmd.Flags().GetBool("set-default")
+	login.BreakGlass, _ = cmd.Flags().GetBool("break-glass")
+	if login.BreakGlass {
+		login.Justification, err = requireStringFlag(cmd, "justification", "Break-glass justification", animation.InputOptions{
+			Placeholder: "e.g. INC-1234 production outage",
+			Validate:    animation.ValidateRequired,
+		})
+		if err != nil {
+			fmt.Println("Error:", err)
+			return
+		}
+	}
+
+	if login.SetAsDefault && !guardDefaultCredentials() {
+		return
+	}
+	paths := []func() (string, error){services_aws.CredentialsFilePath}
+	if login.WriteProfile {
+		paths = append(paths, services_aws.ConfigFilePath)
+	}
+	if err := preflightWrites(paths...); err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	fmt.Printf("Logging in to account %s with role %s (%s)\n", accountID, roleName, portal.StartURL)
+	profileName, err := controllers.LoginWithAccountRole(context.Background(), login)
+	if err != nil {
+		fmt.Printf("❌ Login failed: %v\n", err)
+		return
+	}
+	if login.SetAsDefault {
+		fmt.Printf("✓ Successfully logged in with profile '%s' and set as default\n", profileName)
+	} else {
+		fmt.Printf("✓ Successfully logged in with profile '%s'\n", profileName)
+	}
+}
+
+// errNoSSOPortal is returned by chooseSSOPortal when neither the flags nor the profiles name an SSO portal
+var errNoSSOPortal = errors.New("no SSO start URL configured")
+
+// chooseSSOPortal picks the SSO portal of an account login: the flags first, then the only portal of the SSO profiles
+func chooseSSOPortal(startURL, ssoRegion string, configured []services_aws.SSOPortal) (services_aws.SSOPortal, error) {
+	if startURL == "" {
+		switch len(configured) {
+		case 0:
+			return services_aws.SSOPortal{}, errNoSSOPortal
+		case 1:
+			startURL = configured[0].StartURL
+		default:
+			urls := make([]string, 0, len(configured))
+			for _, portal := range configured {
+				urls = append(urls, portal.StartURL)
+			}
+			return services_aws.SSOPortal{}, fmt.Errorf("profiles use several SSO portals (%s): choose one with --start-url", strings.Join(urls, ", "))
+		}
+	}
+
+	if ssoRegion == "" {
+		ssoRegion = "us-east-1"
+		for _, portal := range configured {
+			if portal.StartURL == startURL {
+				ssoRegion = portal.Region
+				break
+			}
+		}
+	}
+	return services_aws.SSOPortal{StartURL: startURL, Region: ssoRegion}, nil
+}
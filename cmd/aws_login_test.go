@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -312,3 +313,29 @@ func TestAWSLoginCommandInit(t *testing.T) {
 	assert.Len(t, awsCmd.Commands(), 1)
 	assert.Equal(t, "login", awsCmd.Commands()[0].Use)
 }
+
+func TestChooseSSOPortal(t *testing.T) {
+	orgA := services_aws.SSOPortal{StartURL: "https://org-a.awsapps.com/start", Region: "eu-west-1"}
+	orgB := services_aws.SSOPortal{StartURL: "https://org-b.awsapps.com/start", Region: "us-east-1"}
+
+	_, err := chooseSSOPortal("", "", nil)
+	assert.ErrorIs(t, err, errNoSSOPortal)
+
+	portal, err := chooseSSOPortal("", "", []services_aws.SSOPortal{orgA})
+	require.NoError(t, err)
+	assert.Equal(t, orgA, portal)
+
+	_, err = chooseSSOPortal("", "", []services_aws.SSOPortal{orgA, orgB})
+	assert.ErrorContains(t, err, "choose one with --start-url")
+
+	// The region of a known start URL comes from its profiles; flags always win
+	portal, err = chooseSSOPortal(orgA.StartURL, "", []services_aws.SSOPortal{orgA, orgB})
+	require.NoError(t, err)
+	assert.Equal(t, orgA, portal)
+	portal, err = chooseSSOPortal(orgA.StartURL, "ap-south-1", []services_aws.SSOPortal{orgA})
+	require.NoError(t, err)
+	assert.Equal(t, "ap-south-1", portal.Region)
+	portal, err = chooseSSOPortal("https://new.awsapps.com/start", "", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "us-east-1", portal.Region)
+}
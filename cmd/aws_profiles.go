@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/andresgarcia29/ark-cli/lib/animation"
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	"github.com/spf13/cobra"
+)
+
+var (
+	awsProfilesCmd = &cobra.Command{
+		Use:   "profiles",
+		Short: "List the profiles in ~/.aws/config",
+		Long: `List every profile in ~/.aws/config with its type, account, role and region, plus the role hints configured in aws.role_descriptions
+and the account tags written by ` + "`ark bootstrap --tags-from`" + `. --tags keeps the profiles whose account has the given tags.`,
+		Run: awsProfiles,
+	}
+)
+
+func init() {
+	awsCmd.AddCommand(awsProfilesCmd)
+	addTableFlags(awsProfilesCmd, "profile")
+	awsProfilesCmd.Flags().StringSlice("tags", nil, "Only list profiles whose account has these tags, key=value with glob values, e.g. env=prod")
+	awsProfilesCmd.Flags().String("format", "table", "Output format: table or json")
+}
+
+func awsProfiles(cmd *cobra.Command, args []string) {
+	tagFilters, _ := cmd.Flags().GetStringSlice("tags")
+	format, _ := cmd.Flags().GetString("format")
+	if format != "table" && format != "json" {
+		fmt.Printf("Error: unknown format %q (expected table or json)\n", format)
+		return
+	}
+	tags, err := services_aws.ParseTagFilter(tagFilters)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	profiles, err := services_aws.ReadAllProfilesFromConfig()
+	if err != nil {
+		printProfilesError(err)
+		return
+	}
+	profiles = filterProfilesByTags(profiles, tags)
+
+	if format == "json" {
+		if err := writeProfilesJSON(os.Stdout, profiles, services_aws.RoleDescription); err != nil {
+			fmt.Println("Error:", err)
+		}
+		return
+	}
+	if len(profiles) == 0 && len(tags) > 0 {
+		fmt.Println("No profiles match the tags")
+		return
+	}
+	if len(profiles) == 0 {
+		fmt.Println("No profiles found in ~/.aws/config")
+		return
+	}
+
+	output, err := renderTable(cmd, buildProfilesTable(profiles, services_aws.RoleDescription))
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	fmt.Print(output)
+
+	// custom_config and ark.d silently win over ~/.aws/config, so the table may not show what was edited
+	if issues, err := services_aws.LintConfig(); err == nil && len(issues) > 0 {
+		fmt.Println()
+		fmt.Print(formatConfigIssueSummaries(issues))
+		fmt.Println("💡 Run `ark doctor config` for the keys the definitions disagree on")
+	}
+}
+
+// formatConfigIssueSummaries lists the issues of the AWS config files, one line each
+func formatConfigIssueSummaries(issues []services_aws.ConfigIssue) string {
+	var s strings.Builder
+	for _, issue := range issues {
+		fmt.Fprintf(&s, "⚠️  %s\n", issue.Summary())
+	}
+	return s.String()
+}
+
+// filterProfilesByTags keeps the profiles whose account tags match every tag filter
+func filterProfilesByTags(profiles []services_aws.ProfileConfig, tags map[string]string) []services_aws.ProfileConfig {
+	if len(tags) == 0 {
+		return profiles
+	}
+	var matched []services_aws.ProfileConfig
+	for _, profile := range profiles {
+		if services_aws.MatchesTags(profile.Tags, tags) {
+			matched = append(matched, profile)
+		}
+	}
+	return matched
+}
+
+// profileJSON is one profile of `ark aws profiles --format json`
+type profileJSON struct {
+	Profile     string            `json:"profile"`
+	Type        string            `json:"type"`
+	AccountID   string            `json:"account_id,omitempty"`
+	Role        string            `json:"role,omitempty"`
+	Region      string            `json:"region,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+}
+
+// writeProfilesJSON writes the profiles as an indented JSON array
+func writeProfilesJSON(w io.Writer, profiles []services_aws.ProfileConfig, describe func(roleName string) string) error {
+	entries := make([]profileJSON, 0, len(profiles))
+	for _, profile := range profiles {
+		accountID, roleName := services_aws.ProfileAccountAndRole(profile)
+		role := profile.RoleName
+		if role == "" {
+			role = profile.RoleARN
+		}
+		entries = append(entries, profileJSON{
+			Profile:     profile.ProfileName,
+			Type:        string(profile.ProfileType),
+			AccountID:   accountID,
+			Role:        role,
+			Region:      profile.Region,
+			Description: describe(roleName),
+			Tags:        profile.Tags,
+		})
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(entries)
+}
+
+// buildProfilesTable lays out AWS profiles as a table
+// A Description column is added when describe returns a hint for any of the roles, and a Tags column when
+// any account has tags
+func buildProfilesTable(profiles []services_aws.ProfileConfig, describe func(roleName string) string) *animation.Table {
+	descriptions := make([]string, len(profiles))
+	hasDescriptions, hasTags := false, false
+	for i, profile := range profiles {
+		_, roleName := services_aws.ProfileAccountAndRole(profile)
+		descriptions[i] = describe(roleName)
+		hasDescriptions = hasDescriptions || descriptions[i] != ""
+		hasTags = hasTags || len(profile.Tags) > 0
+	}
+
+	titles := []string{"Profile", "Type", "Account", "Role", "Region"}
+	if hasDescriptions {
+		titles = append(titles, "Description")
+	}
+	if hasTags {
+		titles = append(titles, "Tags")
+	}
+	table := animation.NewTable(titles...)
+	table.Columns[0].MaxWidth = 50
+	table.Columns[3].MaxWidth = 50
+	if hasDescriptions {
+		table.Columns[5].MaxWidth = 60
+	}
+	if hasTags {
+		table.Columns[len(titles)-1].MaxWidth = 60
+	}
+
+	for i, profile := range profiles {
+		role := profile.RoleName
+		if role == "" {
+			role = profile.RoleARN
+		}
+		row := []string{profile.ProfileName, string(profile.ProfileType), profile.AccountID, role, profile.Region}
+		if hasDescriptions {
+			row = append(row, descriptions[i])
+		}
+		if hasTags {
+			row = append(row, services_aws.FormatTags(profile.Tags))
+		}
+		table.AddRow(row...)
+	}
+	return table
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	controllers "github.com/andresgarcia29/ark-cli/controllers/aws"
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	"github.com/spf13/cobra"
+)
+
+var (
+	awsProfilesGenerateAssumeCmd = &cobra.Command{
+		Use:   "generate-assume",
+		Short: "Write assume-role profiles for a list of role ARNs",
+		Long: `Write an assume-role profile to ~/.aws/config for every role ARN of a list, e.g. the roles a platform team
+publishes, assumed with the credentials of --source-profile. The list has one role ARN per line, optionally
+followed by the account name; blank lines and # comments are skipped. --name-template names the profiles like
+ark bootstrap does (fields: AccountID, AccountName, RoleName; the default is account-role, with the account ID
+when the list has no name). Profiles of the same name are updated after confirmation, keeping the keys added
+by hand; the other profiles are left alone.`,
+		Args: cobra.NoArgs,
+		Run:  awsProfilesGenerateAssume,
+	}
+)
+
+func init() {
+	awsProfilesCmd.AddCommand(awsProfilesGenerateAssumeCmd)
+	awsProfilesGenerateAssumeCmd.Flags().String("from", "", "File listing the role ARNs, one per line (required)")
+	awsProfilesGenerateAssumeCmd.Flags().String("source-profile", "", "Profile whose credentials assume the roles (required)")
+	awsProfilesGenerateAssumeCmd.Flags().String("name-template", "", "Template for the profile names (default: account-role)")
+	awsProfilesGenerateAssumeCmd.Flags().String("region", "", "Region to write to the profiles (default: none, the default region applies)")
+	awsProfilesGenerateAssumeCmd.Flags().Bool("diff-only", false, "Show the profiles ~/.aws/config would gain and change, without writing it")
+	for _, name := range []string{"from", "source-profile"} {
+		if err := awsProfilesGenerateAssumeCmd.MarkFlagRequired(name); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func awsProfilesGenerateAssume(cmd *cobra.Command, args []string) {
+	from, _ := cmd.Flags().GetString("from")
+	sourceProfile, _ := cmd.Flags().GetString("source-profile")
+	nameTemplate, _ := cmd.Flags().GetString("name-template")
+	region, _ := cmd.Flags().GetString("region")
+	diffOnly, _ := cmd.Flags().GetBool("diff-only")
+
+	data, err := os.ReadFile(from)
+	if err != nil {
+		fmt.Println("Error: failed to read role list:", err)
+		return
+	}
+	targets, err := services_aws.ParseRoleList(string(data))
+	if err != nil {
+		fmt.Printf("Error: %s: %v\n", from, err)
+		return
+	}
+
+	// --diff-only only reads ~/.aws/config
+	if !diffOnly {
+		if err := preflightWrites(services_aws.ConfigFilePath); err != nil {
+			fmt.Println("Error:", err)
+			return
+		}
+	}
+
+	generator := &controllers.AssumeRoleProfileGenerator{
+		Targets:       targets,
+		SourceProfile: sourceProfile,
+		Region:        region,
+		NameTemplate:  nameTemplate,
+		AssumeYes:     AssumeYes,
+		DiffOnly:      diffOnly,
+	}
+	if err := generator.Run(); err != nil {
+		fmt.Println("Error:", err)
+	}
+}
@@ -0,0 +1,18 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAWSProfilesGenerateAssumeFlags(t *testing.T) {
+	for _, name := range []string{"from", "source-profile", "name-template", "region", "diff-only"} {
+		assert.NotNil(t, awsProfilesGenerateAssumeCmd.Flags().Lookup(name), name)
+	}
+	for _, name := range []string{"from", "source-profile"} {
+		annotations := awsProfilesGenerateAssumeCmd.Flags().Lookup(name).Annotations
+		assert.Equal(t, []string{"true"}, annotations[cobra.BashCompOneRequiredFlag], name)
+	}
+}
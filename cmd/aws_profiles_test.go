@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildProfilesTable(t *testing.T) {
+	table := buildProfilesTable([]services_aws.ProfileConfig{
+		{ProfileName: "dev-readonly", ProfileType: services_aws.ProfileTypeSSO, AccountID: "123", RoleName: "ReadOnly", Region: "us-west-2"},
+		{ProfileName: "prod-admin", ProfileType: services_aws.ProfileTypeAssumeRole, RoleARN: "arn:aws:iam::456:role/Admin"},
+	}, func(string) string { return "" })
+
+	require.Len(t, table.Rows, 2)
+	assert.Equal(t, "ReadOnly", table.Rows[0][3])
+	assert.Equal(t, "arn:aws:iam::456:role/Admin", table.Rows[1][3], "assume-role profiles show their role ARN")
+	assert.Len(t, table.Columns, 5, "no description column without role hints")
+}
+
+func TestBuildProfilesTableWithRoleDescriptions(t *testing.T) {
+	descriptions := map[string]string{"Admin": "Full access, audited", "AdminBreakGlass": "Emergency only, pages security"}
+	table := buildProfilesTable([]services_aws.ProfileConfig{
+		{ProfileName: "dev-admin", ProfileType: services_aws.ProfileTypeSSO, AccountID: "123", RoleName: "Admin"},
+		{ProfileName: "dev-readonly", ProfileType: services_aws.ProfileTypeSSO, AccountID: "123", RoleName: "ReadOnly"},
+		{ProfileName: "prod-breakglass", ProfileType: services_aws.ProfileTypeAssumeRole, RoleARN: "arn:aws:iam::456:role/AdminBreakGlass"},
+	}, func(roleName string) string { return descriptions[roleName] })
+
+	require.Len(t, table.Columns, 6)
+	assert.Equal(t, "Description", table.Columns[5].Title)
+	assert.Equal(t, "Full access, audited", table.Rows[0][5])
+	assert.Empty(t, table.Rows[1][5])
+	assert.Equal(t, "Emergency only, pages security", table.Rows[2][5], "assume-role profiles are described by the role in their ARN")
+}
+
+func TestBuildProfilesTableWithTags(t *testing.T) {
+	table := buildProfilesTable([]services_aws.ProfileConfig{
+		{ProfileName: "dev-readonly", ProfileType: services_aws.ProfileTypeSSO, AccountID: "123", RoleName: "ReadOnly"},
+		{ProfileName: "prod-readonly", ProfileType: services_aws.ProfileTypeSSO, AccountID: "456", RoleName: "ReadOnly", Tags: map[string]string{"team": "payments", "env": "prod"}},
+	}, func(string) string { return "" })
+
+	require.Len(t, table.Columns, 6)
+	assert.Equal(t, "Tags", table.Columns[5].Title)
+	assert.Empty(t, table.Rows[0][5])
+	assert.Equal(t, "env=prod, team=payments", table.Rows[1][5])
+}
+
+func TestFilterProfilesByTags(t *testing.T) {
+	profiles := []services_aws.ProfileConfig{
+		{ProfileName: "dev-readonly", Tags: map[string]string{"env": "dev"}},
+		{ProfileName: "prod-readonly", Tags: map[string]string{"env": "prod"}},
+		{ProfileName: "manual"},
+	}
+
+	assert.Equal(t, profiles, filterProfilesByTags(profiles, nil))
+	assert.Equal(t, profiles[1:2], filterProfilesByTags(profiles, map[string]string{"env": "prod"}))
+}
+
+func TestWriteProfilesJSON(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, writeProfilesJSON(&out, []services_aws.ProfileConfig{
+		{ProfileName: "prod-readonly", ProfileType: services_aws.ProfileTypeSSO, AccountID: "456", RoleName: "ReadOnly", Region: "us-east-1", Tags: map[string]string{"env": "prod"}},
+		{ProfileName: "ci", ProfileType: services_aws.ProfileTypeAssumeRole, RoleARN: "arn:aws:iam::789:role/Deploy"},
+	}, func(roleName string) string {
+		if roleName == "ReadOnly" {
+			return "Read-only access"
+		}
+		return ""
+	}))
+
+	var profiles []profileJSON
+	require.NoError(t, json.Unmarshal(out.Bytes(), &profiles))
+	assert.Equal(t, []profileJSON{
+		{Profile: "prod-readonly", Type: "sso", AccountID: "456", Role: "ReadOnly", Region: "us-east-1", Description: "Read-only access", Tags: map[string]string{"env": "prod"}},
+		{Profile: "ci", Type: "assume_role", AccountID: "789", Role: "arn:aws:iam::789:role/Deploy"},
+	}, profiles)
+
+	out.Reset()
+	require.NoError(t, writeProfilesJSON(&out, nil, func(string) string { return "" }))
+	assert.Equal(t, "[]\n", out.String(), "no profiles is an empty array, not null")
+}
+
+func TestFormatConfigIssueSummaries(t *testing.T) {
+	assert.Equal(t, "⚠️  profile ci is defined twice in config; only the last definition is read\n",
+		formatConfigIssueSummaries([]services_aws.ConfigIssue{
+			{Kind: services_aws.ConfigIssueDuplicate, Profile: "ci", Files: []string{"/home/me/.aws/config"}},
+		}))
+}
@@ -5,17 +5,23 @@ import (
 	"fmt"
 
 	controllers "github.com/andresgarcia29/ark-cli/controllers/aws"
+	"github.com/andresgarcia29/ark-cli/lib/animation"
 	"github.com/spf13/cobra"
 )
 
 var (
-	SSORegion   string
-	SSOStartURL string
+	SSORegion      string
+	SSOStartURL    string
+	SSOOffline     bool
+	SSOForce       bool
+	SSODiffOnly    bool
+	SSOIncremental bool
+	SSODeviceCode  bool
 
 	awsSSOnCmd = &cobra.Command{
 		Use:   "sso",
 		Short: "Start a new AWS SSO session",
-		Long:  "Configure and start a new AWS SSO session with the provided profile, fetching the credentials from the AWS SSO cache",
+		Long:  "Configure and start a new AWS SSO session and write a profile for every account and role to ~/.aws/config. ark bootstrap does the same with filters and a profile name template",
 		Run:   awsSSOCommand,
 	}
 )
@@ -23,18 +29,33 @@ var (
 func init() {
 	awsCmd.AddCommand(awsSSOnCmd)
 	awsSSOnCmd.Flags().StringVar(&SSORegion, "region", "us-east-1", "AWS SSO region")
-	awsSSOnCmd.Flags().StringVar(&SSOStartURL, "start-url", "", "AWS SSO start URL (required)")
-	if err := awsSSOnCmd.MarkFlagRequired("start-url"); err != nil {
-		panic(err)
-	}
+	awsSSOnCmd.Flags().StringVar(&SSOStartURL, "start-url", "", "AWS SSO start URL (prompted when missing)")
+	awsSSOnCmd.Flags().BoolVar(&SSOForce, "force", false, "Re-authenticate even when the cached SSO token is still valid")
+	awsSSOnCmd.Flags().BoolVar(&SSODiffOnly, "diff-only", false, "Show the profiles ~/.aws/config would gain, change and lose, without writing it")
+	awsSSOnCmd.Flags().BoolVar(&SSOIncremental, "incremental", false, "Only add the profiles of new accounts and roles, leaving the existing profiles untouched")
+	awsSSOnCmd.Flags().BoolVar(&SSOOffline, "offline", false, "Rewrite ~/.aws/config from the accounts and roles cached by the last online run")
+	awsSSOnCmd.Flags().BoolVar(&SSODeviceCode, "use-device-code", false, "Sign in with a device code even when a browser is available, e.g. to approve on another machine")
 }
 
 func awsSSOCommand(cmd *cobra.Command, args []string) {
-	fmt.Println("AWS sso")
-	ctx := context.Background()
-
-	if err := controllers.AWSSSOLogin(ctx, SSORegion, SSOStartURL, true); err != nil {
+	if _, err := requireStringFlag(cmd, "start-url", "AWS SSO start URL", animation.InputOptions{
+		Placeholder: "https://my-org.awsapps.com/start",
+		Validate:    animation.ValidateSSOStartURL,
+	}); err != nil {
 		fmt.Println("Error:", err)
 		return
 	}
+
+	runBootstrap(context.Background(), "ark aws sso", &controllers.ProfileBootstrapper{
+		SSORegion: SSORegion,
+		StartURL:  SSOStartURL,
+		Offline:   SSOOffline,
+		SSOOptions: controllers.SSOOptions{
+			AssumeYes:   AssumeYes,
+			Force:       SSOForce,
+			DiffOnly:    SSODiffOnly,
+			Incremental: SSOIncremental,
+			DeviceCode:  SSODeviceCode,
+		},
+	})
 }
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"context"
+	"path/filepath"
 	"testing"
 
 	"github.com/spf13/cobra"
@@ -200,3 +201,13 @@ func TestAWSCommandFlags(t *testing.T) {
 	require.NotNil(t, defaultFlag)
 	assert.Equal(t, "set-default", defaultFlag.Name)
 }
+
+func TestFirstRunGuide(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config")
+	t.Setenv("AWS_CONFIG_FILE", configPath)
+
+	guide := firstRunGuide()
+	assert.Contains(t, guide, configPath+" doesn't exist")
+	assert.Contains(t, guide, "ark bootstrap --start-url")
+	assert.NotContains(t, guide, "No profiles found")
+}
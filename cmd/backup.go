@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/andresgarcia29/ark-cli/audit"
+	"github.com/andresgarcia29/ark-cli/lib/animation"
+	"github.com/andresgarcia29/ark-cli/paths"
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	services_backup "github.com/andresgarcia29/ark-cli/services/backup"
+	"github.com/spf13/cobra"
+)
+
+var (
+	backupCmd = &cobra.Command{
+		Use:   "backup",
+		Short: "Move ark's config, state and history to another machine",
+		Long: `Export ark's config, state and history into one file and import it on another machine, so switching
+laptops doesn't mean setting ark up again. The backup is encrypted with AES-256-GCM when ARK_BACKUP_KEY holds a
+base64 32-byte key (openssl rand -base64 32); keep the key somewhere both machines can read.`,
+	}
+
+	backupExportCmd = &cobra.Command{
+		Use:   "export <file>",
+		Short: "Write ark's config, state and history to a backup file",
+		Long: `Write ark's config directory (config.yaml, cluster_profiles.yaml), its state (e.g. the previous context)
+and the audit log to a backup file. With --include-tokens, the SSO tokens and client registrations ark cached in
+~/.aws/sso/cache are added too, so the new machine doesn't sign in again; they require ARK_BACKUP_KEY, since a token
+gives access to every account of the portal. Caches are left out: ark rebuilds them.`,
+		Args: cobra.ExactArgs(1),
+		Run:  backupExport,
+	}
+
+	backupImportCmd = &cobra.Command{
+		Use:   "import <file>",
+		Short: "Restore a backup written by ark backup export",
+		Long: `Restore the files of a backup written by ark backup export to ark's directories on this machine, and its
+tokens to ~/.aws/sso/cache. Files that would be replaced are listed and confirmed first; --dry-run only lists them.
+Encrypted backups are decrypted with the key in ARK_BACKUP_KEY.`,
+		Args: cobra.ExactArgs(1),
+		Run:  backupImport,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(backupCmd)
+	backupCmd.AddCommand(backupExportCmd)
+	backupCmd.AddCommand(backupImportCmd)
+	backupExportCmd.Flags().Bool("include-tokens", false, "Also back up the SSO tokens ark cached (requires ARK_BACKUP_KEY)")
+	backupImportCmd.Flags().Bool("dry-run", false, "List what would be restored without writing it")
+}
+
+func backupExport(cmd *cobra.Command, args []string) {
+	includeTokens, _ := cmd.Flags().GetBool("include-tokens")
+
+	files, encrypted, err := exportBackup(args[0], includeTokens)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	if encrypted {
+		fmt.Printf("✓ Wrote %d file(s) to %s, encrypted with %s\n", files, args[0], services_backup.KeyEnvVar)
+		return
+	}
+	fmt.Printf("✓ Wrote %d file(s) to %s\n", files, args[0])
+	fmt.Printf("Warning: the backup is not encrypted and may hold webhooks and tokens of ark's config: set %s to encrypt it\n", services_backup.KeyEnvVar)
+}
+
+// exportBackup writes the backup to target, returning how many files it holds and whether it is encrypted
+func exportBackup(target string, includeTokens bool) (int, bool, error) {
+	key, err := services_backup.Key()
+	if err != nil {
+		return 0, false, err
+	}
+	if includeTokens && key == nil {
+		return 0, false, fmt.Errorf("--include-tokens requires %s: SSO tokens are only exported encrypted", services_backup.KeyEnvVar)
+	}
+	loc, err := backupLocations()
+	if err != nil {
+		return 0, false, err
+	}
+
+	var tokenFiles []string
+	if includeTokens {
+		if tokenFiles, err = services_aws.CachedTokenFiles(); err != nil {
+			return 0, false, err
+		}
+	}
+	files, err := services_backup.Collect(loc, tokenFiles)
+	if err != nil {
+		return 0, false, err
+	}
+	if len(files) == 0 {
+		return 0, false, errors.New("nothing to back up: ark has no config, state or history on this machine")
+	}
+	data, err := services_backup.Pack(files, key, time.Now())
+	if err != nil {
+		return 0, false, err
+	}
+
+	if err := os.WriteFile(target, data, 0600); err != nil {
+		return 0, false, fmt.Errorf("failed to write %s: %w", target, err)
+	}
+	return len(files), key != nil, nil
+}
+
+func backupImport(cmd *cobra.Command, args []string) {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	restores, err := planBackupImport(args[0])
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	fmt.Print(formatBackupPlan(restores))
+
+	pending, replaced := 0, 0
+	for _, restore := range restores {
+		if !restore.Unchanged {
+			pending++
+		}
+		if restore.Replaces {
+			replaced++
+		}
+	}
+	if dryRun || pending == 0 {
+		if pending == 0 {
+			fmt.Println("Nothing to restore: every file is already up to date")
+		}
+		return
+	}
+
+	if replaced > 0 {
+		confirmed, err := confirmAction(fmt.Sprintf("Replace %d existing file(s)?", replaced), animation.ConfirmOptions{
+			Details:     []string{"The files marked ~ above are overwritten with the backup's version"},
+			Destructive: true,
+		})
+		if err != nil {
+			fmt.Println("Error:", err)
+			return
+		}
+		if !confirmed {
+			fmt.Println("Aborted: nothing was restored")
+			return
+		}
+	}
+
+	if err := services_backup.Apply(restores); err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	fmt.Printf("✓ Restored %d file(s) from %s\n", pending, args[0])
+}
+
+// planBackupImport reads a backup and maps its files to this machine
+func planBackupImport(source string) ([]services_backup.Restore, error) {
+	data, err := os.ReadFile(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", source, err)
+	}
+	key, err := services_backup.Key()
+	if err != nil {
+		return nil, err
+	}
+	files, err := services_backup.Unpack(data, key)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := backupLocations()
+	if err != nil {
+		return nil, err
+	}
+	return services_backup.Plan(files, loc)
+}
+
+// backupLocations returns where ark keeps the files of a backup on this machine
+func backupLocations() (services_backup.Locations, error) {
+	dirs, err := paths.Get()
+	if err != nil {
+		return services_backup.Locations{}, err
+	}
+	auditLog, err := audit.LogPath()
+	if err != nil {
+		return services_backup.Locations{}, err
+	}
+	ssoCache, err := services_aws.SSOCacheDir()
+	if err != nil {
+		return services_backup.Locations{}, err
+	}
+	return services_backup.Locations{Dirs: dirs, AuditLog: auditLog, SSOCache: ssoCache}, nil
+}
+
+// formatBackupPlan lists the files of a backup: + new, ~ replacing a different file, = already up to date
+func formatBackupPlan(restores []services_backup.Restore) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d file(s) in the backup:\n", len(restores))
+	for _, restore := range restores {
+		mark := "+"
+		switch {
+		case restore.Unchanged:
+			mark = "="
+		case restore.Replaces:
+			mark = "~"
+		}
+		fmt.Fprintf(&b, "  %s %s\n", mark, restore.Path)
+	}
+	return b.String()
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"testing"
+
+	services_backup "github.com/andresgarcia29/ark-cli/services/backup"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatBackupPlan(t *testing.T) {
+	plan := formatBackupPlan([]services_backup.Restore{
+		{Path: "/home/dev/.config/ark/config.yaml", Replaces: true},
+		{Path: "/home/dev/.local/state/ark/kubernetes-previous-context", Unchanged: true},
+		{Path: "/home/dev/.aws/sso/cache/ark-token.json"},
+	})
+	assert.Equal(t, `3 file(s) in the backup:
+  ~ /home/dev/.config/ark/config.yaml
+  = /home/dev/.local/state/ark/kubernetes-previous-context
+  + /home/dev/.aws/sso/cache/ark-token.json
+`, plan)
+}
+
+func TestExportBackupRequiresKeyForTokens(t *testing.T) {
+	t.Setenv(services_backup.KeyEnvVar, "")
+	_, _, err := exportBackup(t.TempDir()+"/backup.tar.enc", true)
+	assert.EqualError(t, err, "--include-tokens requires ARK_BACKUP_KEY: SSO tokens are only exported encrypted")
+}
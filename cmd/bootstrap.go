@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	controllers "github.com/andresgarcia29/ark-cli/controllers/aws"
+	"github.com/andresgarcia29/ark-cli/lib/animation"
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	"github.com/spf13/cobra"
+)
+
+var (
+	bootstrapCmd = &cobra.Command{
+		Use:   "bootstrap",
+		Short: "Write a profile to ~/.aws/config for every account and role of an SSO portal",
+		Long: `Sign in to an AWS SSO portal, list its accounts and roles and write a profile for each one to ~/.aws/config.
+--accounts and --roles limit the profiles written, and --name-template names them, e.g. '{{.AccountName}}-{{.RoleName}}'
+(fields: AccountID, AccountName, RoleName). With --tags-from, the accounts' tags are read from AWS Organizations, written
+above each profile and cached, and --tags selects accounts by tag. The changes are listed before anything is written.`,
+		Run: bootstrap,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(bootstrapCmd)
+	addBootstrapFlags(bootstrapCmd)
+}
+
+// addBootstrapFlags registers the flags read by bootstrap
+func addBootstrapFlags(cmd *cobra.Command) {
+	cmd.Flags().String("start-url", "", "AWS SSO start URL (prompted when missing)")
+	cmd.Flags().String("region", "us-east-1", "AWS SSO region")
+	cmd.Flags().Bool("force", false, "Re-authenticate even when the cached SSO token is still valid")
+	cmd.Flags().Bool("offline", false, "Use the accounts and roles cached by the last online run instead of calling AWS")
+	cmd.Flags().Bool("diff-only", false, "Show the profiles ~/.aws/config would gain, change and lose, without writing it")
+	cmd.Flags().Bool("incremental", false, "Only add the profiles of new accounts and roles, leaving the existing profiles untouched")
+	cmd.Flags().Bool("use-device-code", false, "Sign in with a device code even when a browser is available, e.g. to approve on another machine")
+	cmd.Flags().StringSlice("accounts", nil, "Only write profiles for accounts whose name or ID matches these glob patterns, e.g. 'prod-*'")
+	cmd.Flags().StringSlice("roles", nil, "Only write profiles for roles matching these glob patterns, e.g. 'ReadOnly*'")
+	cmd.Flags().String("name-template", "", "Template for the profile names (default: account-role)")
+	cmd.Flags().String("tags-from", "", "Read account tags from AWS Organizations with this portal role, <account-id>/<role>, e.g. 111111111111/OrgReadOnly")
+	cmd.Flags().StringSlice("tags", nil, "Only write profiles for accounts with these tags, key=value with glob values, e.g. env=prod")
+}
+
+func bootstrap(cmd *cobra.Command, args []string) {
+	startURL, err := requireStringFlag(cmd, "start-url", "AWS SSO start URL", animation.InputOptions{
+		Placeholder: "https://my-org.awsapps.com/start",
+		Validate:    animation.ValidateSSOStartURL,
+	})
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	region, _ := cmd.Flags().GetString("region")
+	offline, _ := cmd.Flags().GetBool("offline")
+	force, _ := cmd.Flags().GetBool("force")
+	diffOnly, _ := cmd.Flags().GetBool("diff-only")
+	incremental, _ := cmd.Flags().GetBool("incremental")
+	deviceCode, _ := cmd.Flags().GetBool("use-device-code")
+	accounts, _ := cmd.Flags().GetStringSlice("accounts")
+	roles, _ := cmd.Flags().GetStringSlice("roles")
+	nameTemplate, _ := cmd.Flags().GetString("name-template")
+	tagsFrom, _ := cmd.Flags().GetString("tags-from")
+	tagFilters, _ := cmd.Flags().GetStringSlice("tags")
+
+	tags, err := services_aws.ParseTagFilter(tagFilters)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	var tagSource services_aws.TagSource
+	if tagsFrom != "" {
+		if tagSource, err = services_aws.ParseTagSource(tagsFrom); err != nil {
+			fmt.Println("Error:", err)
+			return
+		}
+	}
+
+	runBootstrap(context.Background(), "ark bootstrap", &controllers.ProfileBootstrapper{
+		SSORegion:    region,
+		StartURL:     startURL,
+		Offline:      offline,
+		Filter:       services_aws.ProfileFilter{Accounts: accounts, Roles: roles, Tags: tags},
+		NameTemplate: nameTemplate,
+		TagsFrom:     tagSource,
+		SSOOptions: controllers.SSOOptions{
+			AssumeYes:   AssumeYes,
+			Force:       force,
+			DiffOnly:    diffOnly,
+			Incremental: incremental,
+			DeviceCode:  deviceCode,
+		},
+	})
+}
+
+// runBootstrap checks ~/.aws/config can be written and runs a bootstrap, notifying the result of online runs
+func runBootstrap(ctx context.Context, command string, bootstrapper *controllers.ProfileBootstrapper) {
+	// --diff-only only reads ~/.aws/config
+	if !bootstrapper.DiffOnly {
+		if err := preflightWrites(services_aws.ConfigFilePath); err != nil {
+			fmt.Println("Error:", err)
+			return
+		}
+	}
+
+	err := bootstrapper.Run(ctx)
+	if !bootstrapper.Offline {
+		notifyScanResult(ctx, command, err)
+	}
+	if err != nil {
+		fmt.Println("Error:", err)
+	}
+}
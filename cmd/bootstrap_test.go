@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBootstrapCommandFlags(t *testing.T) {
+	for _, name := range []string{"start-url", "region", "force", "offline", "diff-only", "incremental", "accounts", "roles", "name-template"} {
+		assert.NotNil(t, bootstrapCmd.Flags().Lookup(name), name)
+	}
+	assert.Equal(t, "us-east-1", bootstrapCmd.Flags().Lookup("region").DefValue)
+}
+
+func TestBootstrapOfflineWithFilters(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	previous := AssumeYes
+	AssumeYes = true
+	t.Cleanup(func() { AssumeYes = previous })
+
+	startURL := "https://example.awsapps.com/start"
+	require.NoError(t, services_aws.SaveProfileCache(startURL, []services_aws.AWSProfile{
+		{AccountID: "111111111111", AccountName: "dev", RoleName: "AdministratorAccess"},
+		{AccountID: "222222222222", AccountName: "prod", RoleName: "ReadOnlyAccess"},
+	}))
+
+	cmd := &cobra.Command{Use: "bootstrap"}
+	addBootstrapFlags(cmd)
+	require.NoError(t, cmd.Flags().Set("start-url", startURL))
+	require.NoError(t, cmd.Flags().Set("offline", "true"))
+	require.NoError(t, cmd.Flags().Set("accounts", "prod"))
+	require.NoError(t, cmd.Flags().Set("name-template", "{{.AccountName}}"))
+	bootstrap(cmd, nil)
+
+	profile, err := services_aws.ReadProfileFromConfig("prod")
+	require.NoError(t, err)
+	require.NotNil(t, profile)
+	assert.Equal(t, "222222222222", profile.AccountID)
+
+	data, err := os.ReadFile(filepath.Join(home, ".aws", "config"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "111111111111", "filtered out accounts get no profile")
+}
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	ark_config "github.com/andresgarcia29/ark-cli/config"
+	"github.com/andresgarcia29/ark-cli/lib/animation"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// secretKeys are hidden by `ark config view` unless --show-secrets is set
+var secretKeys = []string{"notifications.slack.token", "notifications.webhook", "aws.break_glass.webhook"}
+
+var (
+	arkConfigCmd = &cobra.Command{
+		Use:   "config",
+		Short: "View and change ark's own configuration",
+		Long:  `View and change ark's configuration file (config.yaml, or $ARK_CONFIG), without editing YAML by hand.`,
+	}
+
+	arkConfigViewCmd = &cobra.Command{
+		Use:   "view [prefix]",
+		Short: "Print the effective configuration and where each value comes from",
+		Long: `Print every setting with its effective value and its source: the built-in default, the config file,
+or an ARK_* environment variable (e.g. ARK_AWS_MAX_ATTEMPTS for aws.max_attempts), which overrides the file.
+A prefix such as 'aws' or 'kubernetes' limits the keys shown.`,
+		Args: cobra.MaximumNArgs(1),
+		Run:  arkConfigView,
+	}
+
+	arkConfigSetCmd = &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Set one key in the config file",
+		Long: `Set one key in the config file, creating the file when needed and keeping its comments. The value is
+checked against the key's type first. Values are YAML, e.g.:
+  ark config set aws.max_attempts 8
+  ark config set aws.timeout 45s
+  ark config set aws.break_glass.roles '[AdminBreakGlass]'`,
+		Args: cobra.ExactArgs(2),
+		Run:  arkConfigSet,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(arkConfigCmd)
+	arkConfigCmd.AddCommand(arkConfigViewCmd)
+	arkConfigCmd.AddCommand(arkConfigSetCmd)
+	arkConfigViewCmd.Flags().Bool("show-secrets", false, "Show tokens and webhook URLs instead of hiding them")
+	addTableFlags(arkConfigViewCmd, "")
+}
+
+func arkConfigView(cmd *cobra.Command, args []string) {
+	showSecrets, _ := cmd.Flags().GetBool("show-secrets")
+	prefix := ""
+	if len(args) == 1 {
+		prefix = args[0]
+	}
+
+	settings, err := ark_config.Effective()
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	table := buildConfigTable(settings, prefix, showSecrets)
+	if len(table.Rows) == 0 {
+		fmt.Printf("No settings match %q\n", prefix)
+		return
+	}
+
+	if path, err := ark_config.ConfigPath(); err == nil {
+		fmt.Printf("Config file: %s\n\n", path)
+	}
+	output, err := renderTable(cmd, table)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	fmt.Print(output)
+}
+
+func arkConfigSet(cmd *cobra.Command, args []string) {
+	key, value := args[0], args[1]
+
+	path, err := ark_config.Set(key, value)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	fmt.Printf("✓ Set %s in %s\n", key, path)
+	if env := ark_config.EnvVar(key); os.Getenv(env) != "" {
+		fmt.Printf("⚠️  %s is set and overrides this value\n", env)
+	}
+}
+
+// buildConfigTable lays out the settings whose key starts with prefix
+func buildConfigTable(settings []ark_config.Setting, prefix string, showSecrets bool) *animation.Table {
+	table := animation.NewTable("Key", "Value", "Source")
+	for _, setting := range settings {
+		if prefix != "" && setting.Key != prefix && !strings.HasPrefix(setting.Key, strings.TrimSuffix(prefix, ".")+".") {
+			continue
+		}
+		value := formatSettingValue(setting.Value)
+		if !showSecrets && value != "" && slices.Contains(secretKeys, setting.Key) {
+			value = "••••••••"
+		}
+		table.AddRow(setting.Key, value, string(setting.Source))
+	}
+	return table
+}
+
+// formatSettingValue prints a value the way it would be written in the config file
+func formatSettingValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case time.Duration:
+		return v.String()
+	}
+
+	rv := reflect.ValueOf(value)
+	if (rv.Kind() == reflect.Slice || rv.Kind() == reflect.Map) && rv.Len() == 0 {
+		return ""
+	}
+	var node yaml.Node
+	if err := node.Encode(value); err != nil {
+		return fmt.Sprint(value)
+	}
+	node.Style = yaml.FlowStyle
+	out, err := yaml.Marshal(&node)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return strings.TrimSpace(string(out))
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	ark_config "github.com/andresgarcia29/ark-cli/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildConfigTable(t *testing.T) {
+	settings := []ark_config.Setting{
+		{Key: "aws.timeout", Value: 45 * time.Second, Source: ark_config.SourceFile},
+		{Key: "aws.max_attempts", Value: 8, Source: ark_config.SourceEnv},
+		{Key: "aws.least_privilege.admin_patterns", Value: []string{"admin", "poweruser"}, Source: ark_config.SourceDefault},
+		{Key: "aws.role_descriptions", Value: map[string]string(nil), Source: ark_config.SourceDefault},
+		{Key: "aws.break_glass.webhook", Value: "https://hooks.example.com/secret", Source: ark_config.SourceFile},
+		{Key: "notifications.slack.token", Value: "", Source: ark_config.SourceDefault},
+		{Key: "kubernetes.context_alias", Value: "{{.Cluster}}", Source: ark_config.SourceDefault},
+	}
+
+	table := buildConfigTable(settings, "", false)
+	assert.Equal(t, 2, table.ColumnIndex("Source"))
+	require.Len(t, table.Rows, 7)
+	assert.Equal(t, []string{"aws.timeout", "45s", "file"}, table.Rows[0])
+	assert.Equal(t, []string{"aws.max_attempts", "8", "env"}, table.Rows[1])
+	assert.Equal(t, []string{"aws.least_privilege.admin_patterns", "[admin, poweruser]", "default"}, table.Rows[2])
+	assert.Equal(t, []string{"aws.role_descriptions", "", "default"}, table.Rows[3])
+	assert.Equal(t, []string{"aws.break_glass.webhook", "••••••••", "file"}, table.Rows[4], "secrets are hidden")
+	assert.Equal(t, []string{"notifications.slack.token", "", "default"}, table.Rows[5], "unset secrets show as empty")
+	assert.Equal(t, []string{"kubernetes.context_alias", "{{.Cluster}}", "default"}, table.Rows[6])
+
+	table = buildConfigTable(settings, "aws.break_glass", true)
+	assert.Equal(t, [][]string{{"aws.break_glass.webhook", "https://hooks.example.com/secret", "file"}}, table.Rows)
+
+	// Prefixes match whole key segments
+	assert.Empty(t, buildConfigTable(settings, "aws.break", false).Rows)
+	assert.Len(t, buildConfigTable(settings, "aws.", false).Rows, 5)
+	assert.Len(t, buildConfigTable(settings, "aws.timeout", false).Rows, 1)
+}
+
+func TestFormatSettingValue(t *testing.T) {
+	assert.Equal(t, "{admin: Full access, dev: Read only}", formatSettingValue(map[string]string{"dev": "Read only", "admin": "Full access"}))
+	assert.Equal(t, "true", formatSettingValue(true))
+	assert.Equal(t, "1m30s", formatSettingValue(90*time.Second))
+	assert.Equal(t, "", formatSettingValue([]string{}))
+}
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	ark_config "github.com/andresgarcia29/ark-cli/config"
+	controllers "github.com/andresgarcia29/ark-cli/controllers/aws"
+	"github.com/andresgarcia29/ark-cli/lib/animation"
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+)
+
+// confirmAction asks for confirmation unless --yes was given
+func confirmAction(question string, options animation.ConfirmOptions) (bool, error) {
+	if AssumeYes {
+		return true, nil
+	}
+	return animation.Confirm(question, options)
+}
+
+// confirmDefaultCredentialsOverwrite guards the [default] profile when it holds long-lived keys
+// Returns false when the user declines; temporary credentials written by ark are overwritten silently
+func confirmDefaultCredentialsOverwrite() (bool, error) {
+	static, err := services_aws.HasStaticDefaultCredentials()
+	if err != nil {
+		return false, err
+	}
+	if !static {
+		return true, nil
+	}
+
+	return confirmAction(
+		"The [default] profile in ~/.aws/credentials has long-lived keys. Overwrite them?",
+		animation.ConfirmOptions{
+			Details:     []string{"They will be replaced by temporary credentials; keep a copy if you still need them"},
+			Destructive: true,
+		},
+	)
+}
+
+// guardDefaultCredentials runs confirmDefaultCredentialsOverwrite and reports why the command stops
+// It returns true when it is safe to write the [default] profile
+func guardDefaultCredentials() bool {
+	confirmed, err := confirmDefaultCredentialsOverwrite()
+	if err != nil {
+		fmt.Printf("❌ Error: %v\n", err)
+		return false
+	}
+	if !confirmed {
+		fmt.Println("❌ Aborted: default credentials were not modified")
+		return false
+	}
+	return true
+}
+
+// confirmLargeScan asks before scanning more account×region combinations than threshold, so a typo'd
+// --regions or --role-prefixs doesn't fan out over the whole organization; threshold 0 never asks
+func confirmLargeScan(plan services_aws.ScanPlan, threshold int) (bool, error) {
+	if threshold <= 0 || plan.Targets() <= threshold {
+		return true, nil
+	}
+
+	return confirmAction(
+		fmt.Sprintf("Scan %d account×region combinations (more than %d)?", plan.Targets(), threshold),
+		animation.ConfirmOptions{
+			Details: []string{
+				fmt.Sprintf("%d account(s) in %d region(s): %s", plan.Accounts, len(plan.Regions), strings.Join(plan.Regions, ", ")),
+				fmt.Sprintf("At least %d AWS API calls, plus one per cluster found", plan.EstimatedAPICalls()),
+				"Narrow --regions or --role-prefixs, or raise aws.scan_confirm_threshold in the ark config",
+			},
+		},
+	)
+}
+
+// guardLargeScan plans the scan of every account and runs confirmLargeScan, reporting why the command stops
+// It returns true when the scan may go ahead; a Resource Explorer search is one call, so it never asks
+func guardLargeScan(discovery services_aws.DiscoveryBackend, regions, rolePrefixs []string, roleARN string) bool {
+	plan, err := services_aws.PlanClusterScan(regions, rolePrefixs, roleARN)
+	if err != nil {
+		printProfilesError(err)
+		return false
+	}
+	if discovery == services_aws.DiscoveryResourceExplorer {
+		return true
+	}
+	confirmed, err := confirmLargeScan(plan, ark_config.Get().AWS.ScanConfirmThreshold)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return false
+	}
+	if !confirmed {
+		fmt.Println("Aborted: no accounts were scanned")
+		return false
+	}
+	return guardScanTokens(plan)
+}
+
+// guardScanTokens warns about the SSO tokens that would expire before the scan of plan finishes and offers
+// to sign in to their portal again first; with --yes or without a terminal it only warns
+// It returns false when signing in again fails
+func guardScanTokens(plan services_aws.ScanPlan) bool {
+	now := time.Now()
+	for _, token := range controllers.TokensExpiringDuringScan(plan, now, services_aws.TokenExpiry) {
+		fmt.Println(describeExpiringToken(token, plan, now))
+		if AssumeYes || !animation.IsInteractive() {
+			fmt.Printf("💡 Run `ark aws sso --start-url %s --region %s --force` first to avoid sign-in failures mid-scan\n", token.StartURL, token.Region)
+			continue
+		}
+
+		refresh, err := animation.Confirm(fmt.Sprintf("Sign in to %s again before scanning?", token.StartURL), animation.ConfirmOptions{
+			Details: []string{"Otherwise the accounts scanned after the token expires fail to sign in"},
+		})
+		if err != nil {
+			fmt.Println("Error:", err)
+			return false
+		}
+		if !refresh {
+			continue
+		}
+		if err := controllers.AWSSSOLogin(context.Background(), token.Region, token.StartURL, controllers.SSOOptions{Force: true}); err != nil {
+			fmt.Println("Error:", err)
+			return false
+		}
+	}
+	return true
+}
+
+// describeExpiringToken explains why token won't last through the scan of plan
+func describeExpiringToken(token controllers.ExpiringToken, plan services_aws.ScanPlan, now time.Time) string {
+	switch {
+	case token.ExpiresAt.IsZero():
+		return fmt.Sprintf("⚠️  No SSO token is cached for %s (%s): accounts signing in through it will fail", token.StartURL, token.Region)
+	case services_aws.ValidFor(token.ExpiresAt, now) <= 0:
+		return fmt.Sprintf("⚠️  The SSO token of %s expired at %s", token.StartURL, token.ExpiresAt.Local().Format(time.Kitchen))
+	default:
+		return fmt.Sprintf("⚠️  The SSO token of %s expires in %s, before a scan of %d account(s) could finish (about %s)",
+			token.StartURL, token.ExpiresAt.Sub(now).Round(time.Minute), plan.Accounts, plan.EstimatedDuration().Round(time.Minute))
+	}
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	controllers "github.com/andresgarcia29/ark-cli/controllers/aws"
+	"github.com/andresgarcia29/ark-cli/lib/animation"
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfirmActionAssumeYes(t *testing.T) {
+	original := AssumeYes
+	defer func() { AssumeYes = original }()
+
+	AssumeYes = true
+	confirmed, err := confirmAction("Delete everything?", animation.ConfirmOptions{Destructive: true})
+	require.NoError(t, err)
+	assert.True(t, confirmed)
+
+	assert.NotNil(t, rootCmd.PersistentFlags().Lookup("yes"))
+}
+
+func TestConfirmDefaultCredentialsOverwrite(t *testing.T) {
+	original := AssumeYes
+	defer func() { AssumeYes = original }()
+	AssumeYes = false
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	// Nothing to protect: no prompt needed
+	confirmed, err := confirmDefaultCredentialsOverwrite()
+	require.NoError(t, err)
+	assert.True(t, confirmed)
+
+	// Long-lived keys are only overwritten after confirming, or with --yes
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".aws"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".aws", "credentials"), []byte("[default]\naws_access_key_id = AKIA\n"), 0600))
+	AssumeYes = true
+	confirmed, err = confirmDefaultCredentialsOverwrite()
+	require.NoError(t, err)
+	assert.True(t, confirmed)
+}
+
+func TestConfirmLargeScan(t *testing.T) {
+	original := AssumeYes
+	defer func() { AssumeYes = original }()
+	AssumeYes = false
+
+	plan := services_aws.ScanPlan{Accounts: 20, Regions: []string{"us-east-1", "us-west-2", "eu-west-1"}}
+	assert.Equal(t, 60, plan.Targets())
+	assert.Equal(t, 80, plan.EstimatedAPICalls())
+
+	// Small scans and a disabled threshold never prompt
+	for _, threshold := range []int{60, 100, 0} {
+		confirmed, err := confirmLargeScan(plan, threshold)
+		require.NoError(t, err)
+		assert.True(t, confirmed, "threshold %d", threshold)
+	}
+
+	// Larger scans need confirmation, given here by --yes
+	AssumeYes = true
+	confirmed, err := confirmLargeScan(plan, 50)
+	require.NoError(t, err)
+	assert.True(t, confirmed)
+}
+
+func TestDescribeExpiringToken(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	portal := services_aws.SSOPortal{StartURL: "https://my-org.awsapps.com/start", Region: "us-east-1"}
+	plan := services_aws.ScanPlan{Accounts: 1000, Regions: []string{"us-east-1"}, Portals: []services_aws.SSOPortal{portal}}
+
+	assert.Equal(t, "⚠️  The SSO token of https://my-org.awsapps.com/start expires in 4m0s, before a scan of 1000 account(s) could finish (about 5m0s)",
+		describeExpiringToken(controllers.ExpiringToken{SSOPortal: portal, ExpiresAt: now.Add(4 * time.Minute)}, plan, now))
+	assert.Contains(t, describeExpiringToken(controllers.ExpiringToken{SSOPortal: portal, ExpiresAt: now.Add(-time.Minute)}, plan, now), "expired at")
+	assert.Contains(t, describeExpiringToken(controllers.ExpiringToken{SSOPortal: portal}, plan, now), "No SSO token is cached")
+}
+
+func TestGuardScanTokens(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	original := AssumeYes
+	defer func() { AssumeYes = original }()
+	AssumeYes = true
+
+	// Without a terminal or with --yes, an expiring token is only a warning
+	plan := services_aws.ScanPlan{Accounts: 2, Regions: []string{"us-east-1"},
+		Portals: []services_aws.SSOPortal{{StartURL: "https://my-org.awsapps.com/start", Region: "us-east-1"}}}
+	assert.True(t, guardScanTokens(plan))
+}
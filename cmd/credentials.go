@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	controllers "github.com/andresgarcia29/ark-cli/controllers/aws"
+	"github.com/andresgarcia29/ark-cli/lib/animation"
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	"github.com/spf13/cobra"
+)
+
+var (
+	credentialsCmd = &cobra.Command{
+		Use:   "credentials",
+		Short: "Manage the entries ark writes to the AWS credentials file",
+		Long:  `Manage the temporary role credentials ark writes to ~/.aws/credentials (or $AWS_SHARED_CREDENTIALS_FILE).`,
+	}
+
+	credentialsSyncCmd = &cobra.Command{
+		Use:   "sync",
+		Short: "Write credentials for every SSO profile in one run",
+		Long: `Fetch role credentials for every SSO profile in ~/.aws/config in parallel and write them all to the
+credentials file, for tools that can't use SSO profiles directly. Requires a valid SSO session (ark aws sso).`,
+		Run: credentialsSync,
+	}
+
+	credentialsCheckCmd = &cobra.Command{
+		Use:   "check",
+		Short: "Check which profiles can get credentials right now",
+		Long: `Call sts:GetCallerIdentity with every profile in ~/.aws/config in parallel, the way the AWS CLI and SDKs
+resolve it, and report which profiles work, which need a login and which are misconfigured: a quick health sweep
+before an incident call. Nothing is written. Break-glass roles are skipped. Exits with status 1 when a profile
+doesn't work.`,
+		Run: credentialsCheck,
+	}
+
+	credentialsPruneCmd = &cobra.Command{
+		Use:   "prune",
+		Short: "Remove expired entries from the credentials file",
+		Long: `Remove the entries ark wrote to the credentials file whose expiration has passed. Only entries marked
+"# managed by ark" are considered: static access keys and entries written by other tools, such as aws-vault or
+saml2aws, are never removed. ark also prunes whenever it writes credentials.`,
+		Run: credentialsPrune,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(credentialsCmd)
+	credentialsCmd.AddCommand(credentialsSyncCmd)
+	credentialsCmd.AddCommand(credentialsPruneCmd)
+	credentialsCmd.AddCommand(credentialsCheckCmd)
+	credentialsCheckCmd.Flags().StringSlice("profiles", nil, "Only check profiles matching these glob patterns, e.g. 'prod-*' (default: all profiles)")
+	addTableFlags(credentialsCheckCmd, "")
+	credentialsPruneCmd.Flags().Bool("dry-run", false, "List the expired entries without removing them")
+	credentialsSyncCmd.Flags().StringSlice("profiles", nil, "Only sync profiles matching these glob patterns, e.g. 'prod-*' (default: all SSO profiles)")
+}
+
+func credentialsSync(cmd *cobra.Command, args []string) {
+	patterns, _ := cmd.Flags().GetStringSlice("profiles")
+
+	if err := preflightWrites(services_aws.CredentialsFilePath); err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	if err := controllers.SyncCredentials(context.Background(), patterns); err != nil {
+		printProfilesError(err)
+	}
+}
+
+func credentialsCheck(cmd *cobra.Command, args []string) {
+	patterns, _ := cmd.Flags().GetStringSlice("profiles")
+
+	checks, err := controllers.CheckCredentials(context.Background(), patterns)
+	if err != nil {
+		printProfilesError(err)
+		return
+	}
+
+	fmt.Println()
+	output, err := renderTable(cmd, buildCredentialCheckTable(checks))
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	fmt.Print(output)
+
+	summary, hints := summarizeCredentialChecks(checks)
+	fmt.Println()
+	fmt.Println(summary)
+	if len(hints) == 0 {
+		return
+	}
+	for _, hint := range hints {
+		fmt.Println("💡", hint)
+	}
+	os.Exit(1)
+}
+
+// credentialHealthLabels are shown in the Status column
+var credentialHealthLabels = map[services_aws.CredentialHealth]string{
+	services_aws.CredentialHealthOK:            "✓ ok",
+	services_aws.CredentialHealthNeedsLogin:    "✗ needs login",
+	services_aws.CredentialHealthMisconfigured: "✗ misconfigured",
+	services_aws.CredentialHealthUnknown:       "? failed",
+}
+
+// buildCredentialCheckTable lays out one row per profile, with who AWS sees or why the check failed
+func buildCredentialCheckTable(checks []services_aws.CredentialCheck) *animation.Table {
+	table := animation.NewTable("Profile", "Type", "Status", "Identity")
+	for _, check := range checks {
+		identity := "-"
+		switch {
+		case check.Identity != nil:
+			identity = check.Identity.ARN
+		case check.Err != nil:
+			identity = check.Err.Error()
+		}
+		table.AddRow(check.Profile.ProfileName, string(check.Profile.ProfileType), credentialHealthLabels[check.Health], identity)
+	}
+	return table
+}
+
+// summarizeCredentialChecks counts the profiles per outcome and suggests a fix for each kind of failure
+func summarizeCredentialChecks(checks []services_aws.CredentialCheck) (string, []string) {
+	counts := map[services_aws.CredentialHealth]int{}
+	for _, check := range checks {
+		counts[check.Health]++
+	}
+	summary := fmt.Sprintf("%d/%d profile(s) work", counts[services_aws.CredentialHealthOK], len(checks))
+	if counts[services_aws.CredentialHealthOK] == len(checks) {
+		return "✅ " + summary, nil
+	}
+
+	var hints []string
+	if n := counts[services_aws.CredentialHealthNeedsLogin]; n > 0 {
+		hints = append(hints, fmt.Sprintf("%d profile(s) need a login: run ark aws sso for their portal, or log in to their source profile", n))
+	}
+	if n := counts[services_aws.CredentialHealthMisconfigured]; n > 0 {
+		hints = append(hints, fmt.Sprintf("%d profile(s) are misconfigured: check their settings in ~/.aws/config, and that the role is still assigned to you", n))
+	}
+	if n := counts[services_aws.CredentialHealthUnknown]; n > 0 {
+		hints = append(hints, fmt.Sprintf("%d profile(s) could not be checked: run ark doctor network if AWS can't be reached", n))
+	}
+	return "⚠️  " + summary, hints
+}
+
+func credentialsPrune(cmd *cobra.Command, args []string) {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	if !dryRun {
+		if err := preflightWrites(services_aws.CredentialsFilePath); err != nil {
+			fmt.Println("Error:", err)
+			return
+		}
+	}
+
+	pruned, err := services_aws.PruneCredentialsFile(dryRun)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	if len(pruned) == 0 {
+		fmt.Println("No expired credentials found")
+		return
+	}
+
+	verb := "Removed"
+	if dryRun {
+		verb = "Would remove"
+	}
+	fmt.Printf("%s %d expired profile(s):\n", verb, len(pruned))
+	for _, profile := range pruned {
+		fmt.Printf("  - %s\n", profile)
+	}
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarizeCredentialChecks(t *testing.T) {
+	ok := services_aws.CredentialCheck{
+		Profile:  services_aws.ProfileConfig{ProfileName: "dev-readonly", ProfileType: services_aws.ProfileTypeSSO},
+		Health:   services_aws.CredentialHealthOK,
+		Identity: &services_aws.CallerIdentity{ARN: "arn:aws:sts::111111111111:assumed-role/ReadOnly/ark"},
+	}
+	summary, hints := summarizeCredentialChecks([]services_aws.CredentialCheck{ok})
+	assert.Equal(t, "✅ 1/1 profile(s) work", summary)
+	assert.Empty(t, hints)
+
+	checks := []services_aws.CredentialCheck{
+		ok,
+		{Profile: services_aws.ProfileConfig{ProfileName: "prod-admin"}, Health: services_aws.CredentialHealthNeedsLogin, Err: errors.New("no valid SSO session")},
+		{Profile: services_aws.ProfileConfig{ProfileName: "prod-deploy"}, Health: services_aws.CredentialHealthMisconfigured, Err: errors.New("source_profile is required")},
+		{Profile: services_aws.ProfileConfig{ProfileName: "prod-audit"}, Health: services_aws.CredentialHealthMisconfigured, Err: errors.New("ForbiddenException")},
+	}
+	summary, hints = summarizeCredentialChecks(checks)
+	assert.Equal(t, "⚠️  1/4 profile(s) work", summary)
+	require.Len(t, hints, 2)
+	assert.Contains(t, hints[0], "1 profile(s) need a login")
+	assert.Contains(t, hints[1], "2 profile(s) are misconfigured")
+
+	rendered, err := buildCredentialCheckTable(checks).RenderPage(1, 0)
+	require.NoError(t, err)
+	assert.Contains(t, rendered, "arn:aws:sts::111111111111:assumed-role/ReadOnly/ark")
+	assert.Contains(t, rendered, "✗ needs login")
+	assert.Contains(t, rendered, "source_profile is required")
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/andresgarcia29/ark-cli/lib/animation"
+	services_kubernetes "github.com/andresgarcia29/ark-cli/services/kubernetes"
+	"github.com/spf13/cobra"
+)
+
+var (
+	ctxCmd = &cobra.Command{
+		Use:   "ctx [- | query]",
+		Short: "Switch Kubernetes contexts, like kubectx",
+		Long: `Switch Kubernetes contexts. Without arguments the interactive cluster selector is shown;
+"ark ctx -" switches back to the context that was active before the last switch made by ark.
+
+With a query, a context matching it exactly, by substring or fuzzily ("pdeu" for "prod-eu") is
+switched to right away when it is the only match; several matches open the selector filtered by the query.`,
+		Args: cobra.MaximumNArgs(1),
+		Run:  ctxCommand,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(ctxCmd)
+}
+
+func ctxCommand(cmd *cobra.Command, args []string) {
+	if len(args) == 0 {
+		kubernetes(cmd, args)
+		return
+	}
+
+	ctx := context.Background()
+	if args[0] == "-" {
+		previous, err := services_kubernetes.PreviousContext()
+		if err != nil {
+			fmt.Println("Error:", err)
+			return
+		}
+		switchToCluster(ctx, &services_kubernetes.ClusterContext{Name: previous})
+		return
+	}
+
+	contexts, err := services_kubernetes.GetClusterContexts()
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	selected, err := selectContextByQuery(contexts, args[0])
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	switchToCluster(ctx, selected)
+}
+
+// selectContextByQuery returns the only context matching query, or lets the user pick among several matches
+// Without a terminal, several matches are an error listing them
+func selectContextByQuery(contexts []services_kubernetes.ClusterContext, query string) (*services_kubernetes.ClusterContext, error) {
+	matches := services_kubernetes.MatchContexts(contexts, query)
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no context matches %q", query)
+	case 1:
+		return &matches[0], nil
+	}
+
+	if !animation.IsInteractive() {
+		names := make([]string, 0, len(matches))
+		for _, match := range matches {
+			names = append(names, match.Name)
+		}
+		return nil, fmt.Errorf("%q matches %d contexts: %s", query, len(matches), strings.Join(names, ", "))
+	}
+	return animation.SelectCluster(contexts, query)
+}
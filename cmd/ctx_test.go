@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"testing"
+
+	services_kubernetes "github.com/andresgarcia29/ark-cli/services/kubernetes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCtxCommandArgs(t *testing.T) {
+	require.NoError(t, ctxCmd.Args(ctxCmd, nil))
+	require.NoError(t, ctxCmd.Args(ctxCmd, []string{"-"}))
+	require.NoError(t, ctxCmd.Args(ctxCmd, []string{"prod"}))
+	assert.Error(t, ctxCmd.Args(ctxCmd, []string{"prod", "dev"}))
+
+	// A lone dash reaches the command as an argument, not as a flag
+	require.NoError(t, ctxCmd.ParseFlags([]string{"-"}))
+	assert.Equal(t, []string{"-"}, ctxCmd.Flags().Args())
+}
+
+func TestSelectContextByQuery(t *testing.T) {
+	contexts := []services_kubernetes.ClusterContext{{Name: "prod"}, {Name: "prod-eu"}, {Name: "dev"}}
+
+	selected, err := selectContextByQuery(contexts, "prod")
+	require.NoError(t, err)
+	assert.Equal(t, "prod", selected.Name)
+
+	selected, err = selectContextByQuery(contexts, "pdeu")
+	require.NoError(t, err)
+	assert.Equal(t, "prod-eu", selected.Name)
+
+	_, err = selectContextByQuery(contexts, "qa")
+	assert.ErrorContains(t, err, `no context matches "qa"`)
+
+	// Tests run without a terminal: several matches can't be narrowed down interactively
+	_, err = selectContextByQuery(contexts, "pro")
+	assert.ErrorContains(t, err, "matches 2 contexts: prod, prod-eu")
+}
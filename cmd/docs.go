@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+)
+
+var (
+	docsCmd = &cobra.Command{
+		Use:    "docs",
+		Short:  "Generate man pages or Markdown reference docs for every command",
+		Long:   `Generate a man page (section 1) or a Markdown page per command, with its flags and examples, for packaging and the docs site.`,
+		Hidden: true,
+		Args:   cobra.NoArgs,
+		Run:    docs,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(docsCmd)
+	docsCmd.Flags().String("format", "man", "Output format: man or markdown")
+	docsCmd.Flags().String("dir", "docs", "Directory to write the pages to")
+}
+
+func docs(cmd *cobra.Command, args []string) {
+	format, _ := cmd.Flags().GetString("format")
+	dir, _ := cmd.Flags().GetString("dir")
+
+	if err := generateDocs(cmd.Root(), format, dir); err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	fmt.Printf("✓ Wrote the %s pages to %s\n", format, dir)
+}
+
+// generateDocs writes the pages of root and its visible subcommands to dir
+// The generation date is left out, so the pages only change when the commands do
+func generateDocs(root *cobra.Command, format, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	addExamples(root)
+	root.DisableAutoGenTag = true
+
+	switch format {
+	case "man":
+		return doc.GenManTree(root, &doc.GenManHeader{Title: "ARK", Section: "1", Source: "ark " + Version}, dir)
+	case "markdown":
+		return doc.GenMarkdownTree(root, dir)
+	default:
+		return fmt.Errorf("unknown format %q (expected man or markdown)", format)
+	}
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandsHaveHelp(t *testing.T) {
+	addExamples(rootCmd)
+
+	var walk func(cmd *cobra.Command)
+	walk = func(cmd *cobra.Command) {
+		if cmd.Hidden || cmd.Name() == "help" || cmd.Name() == "completion" {
+			return
+		}
+		assert.NotEmpty(t, cmd.Short, "%s has no Short", cmd.CommandPath())
+		assert.NotEmpty(t, cmd.Long, "%s has no Long", cmd.CommandPath())
+		if cmd.Runnable() {
+			assert.NotEmpty(t, cmd.Example, "%s has no examples in commandExamples", cmd.CommandPath())
+		}
+		for _, sub := range cmd.Commands() {
+			walk(sub)
+		}
+	}
+	walk(rootCmd)
+
+	// Every example belongs to a command, so renamed commands don't leave stale ones behind
+	for path := range commandExamples {
+		found := path == rootCmd.CommandPath()
+		if !found {
+			sub, _, err := rootCmd.Find(strings.Fields(path)[1:])
+			found = err == nil && sub.CommandPath() == path
+		}
+		assert.True(t, found, "commandExamples has examples for unknown command %q", path)
+	}
+}
+
+func TestGenerateDocs(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "man")
+	require.NoError(t, generateDocs(rootCmd, "man", dir))
+
+	page, err := os.ReadFile(filepath.Join(dir, "ark-aws-sso.1"))
+	require.NoError(t, err)
+	assert.Contains(t, string(page), "ark aws sso --start-url https://my-org.awsapps.com/start --diff-only")
+	assert.NoFileExists(t, filepath.Join(dir, "ark-docs.1"), "hidden commands have no page")
+
+	dir = filepath.Join(t.TempDir(), "markdown")
+	require.NoError(t, generateDocs(rootCmd, "markdown", dir))
+	page, err = os.ReadFile(filepath.Join(dir, "ark_kubernetes_setup.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(page), "ark kubernetes setup --offline")
+	assert.NotContains(t, string(page), "Auto generated", "pages don't change with the generation date")
+
+	assert.ErrorContains(t, generateDocs(rootCmd, "html", t.TempDir()), `unknown format "html"`)
+}
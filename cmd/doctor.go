@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"slices"
+	"time"
+
+	controllers "github.com/andresgarcia29/ark-cli/controllers/aws"
+	"github.com/andresgarcia29/ark-cli/lib/animation"
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	"github.com/spf13/cobra"
+)
+
+var (
+	doctorCmd = &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose problems with ark's environment",
+		Long:  `Diagnose problems with the environment ark runs in, such as a network that can't reach AWS.`,
+	}
+
+	doctorNetworkCmd = &cobra.Command{
+		Use:   "network",
+		Short: "Check that the AWS endpoints ark calls can be reached",
+		Long: `Resolve and connect to the SSO OIDC and SSO portal endpoints of the SSO regions and the STS and EKS
+endpoints of the regions, through the same proxy (aws.proxy or HTTPS_PROXY), CA bundle and endpoint
+overrides as ark's AWS calls. Use it when a login or a scan hangs. The regions default to the sso_region
+and region of the profiles in ~/.aws/config. Exits with status 1 when an endpoint can't be reached.`,
+		Run: doctorNetwork,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+	doctorCmd.AddCommand(doctorNetworkCmd)
+	doctorNetworkCmd.Flags().StringSlice("sso-regions", nil, "Regions of the SSO OIDC and portal endpoints (default: sso_region of the profiles, or us-east-1)")
+	doctorNetworkCmd.Flags().StringSlice("regions", nil, "Regions of the STS and EKS endpoints (default: region of the profiles, or us-east-1)")
+	doctorNetworkCmd.Flags().Duration("timeout", 10*time.Second, "Time each endpoint has to answer")
+	addTableFlags(doctorNetworkCmd, "")
+}
+
+func doctorNetwork(cmd *cobra.Command, args []string) {
+	ssoRegions, _ := cmd.Flags().GetStringSlice("sso-regions")
+	regions, _ := cmd.Flags().GetStringSlice("regions")
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+
+	if timeout <= 0 {
+		fmt.Println("Error: --timeout must be positive")
+		return
+	}
+
+	if len(ssoRegions) == 0 || len(regions) == 0 {
+		configuredSSO, configured, err := services_aws.ConfiguredRegions()
+		if err != nil {
+			fmt.Println("Error:", err)
+			return
+		}
+		if len(ssoRegions) == 0 {
+			ssoRegions = configuredSSO
+		}
+		if len(regions) == 0 {
+			regions = configured
+		}
+	}
+	if len(ssoRegions) == 0 {
+		ssoRegions = []string{"us-east-1"}
+	}
+	if len(regions) == 0 {
+		regions = []string{"us-east-1"}
+	}
+
+	var results []services_aws.NetworkCheck
+	err := animation.ShowStatus(context.Background(), "Checking AWS endpoints", func(ctx context.Context, status func(string)) error {
+		checker, err := services_aws.NewNetworkChecker(ctx, timeout)
+		if err != nil {
+			return err
+		}
+		endpoints := services_aws.NetworkEndpoints(ssoRegions, regions)
+		status(fmt.Sprintf("Checking %d endpoint(s)", len(endpoints)))
+		results = controllers.CheckNetwork(ctx, endpoints, checker.Check)
+		return nil
+	})
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	fmt.Println(describeProxies(results))
+	fmt.Println()
+	output, err := renderTable(cmd, buildNetworkTable(results))
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	fmt.Print(output)
+
+	hints := networkHints(results)
+	if len(hints) == 0 {
+		fmt.Println("\n✅ Every endpoint is reachable")
+		return
+	}
+	fmt.Println()
+	for _, hint := range hints {
+		fmt.Println("💡", hint)
+	}
+	os.Exit(1)
+}
+
+// describeProxies says which proxies the checks went through
+func describeProxies(results []services_aws.NetworkCheck) string {
+	var proxies []string
+	direct := false
+	for _, result := range results {
+		if result.Proxy == "" {
+			direct = true
+		} else if !slices.Contains(proxies, result.Proxy) {
+			proxies = append(proxies, result.Proxy)
+		}
+	}
+	switch {
+	case len(proxies) == 0:
+		return "Proxy: none (set aws.proxy or HTTPS_PROXY to use one)"
+	case direct:
+		return fmt.Sprintf("Proxy: %s (some endpoints bypass it, see NO_PROXY)", proxies[0])
+	default:
+		return fmt.Sprintf("Proxy: %s", proxies[0])
+	}
+}
+
+// buildNetworkTable lays out one row per endpoint
+func buildNetworkTable(results []services_aws.NetworkCheck) *animation.Table {
+	table := animation.NewTable("Service", "Region", "Endpoint", "DNS", "TLS", "Status")
+	for _, result := range results {
+		dns := formatMilliseconds(result.DNS)
+		if result.DNSErr != nil {
+			dns = "✗ failed"
+			if result.Proxy != "" {
+				dns = "via proxy"
+			}
+		}
+		tls := "-"
+		if result.TLS > 0 {
+			tls = formatMilliseconds(result.TLS)
+		}
+		status := fmt.Sprintf("✓ HTTP %d", result.Status)
+		if !result.OK() {
+			status = "✗ " + result.Err.Error()
+		}
+		table.AddRow(result.Service, result.Region, result.URL, dns, tls, status)
+	}
+	return table
+}
+
+// formatMilliseconds right-aligns a duration in milliseconds, so sorting the column as text keeps it numeric
+func formatMilliseconds(d time.Duration) string {
+	return fmt.Sprintf("%5d ms", d.Round(time.Millisecond).Milliseconds())
+}
+
+// networkHints suggests a fix for each kind of failure among results
+func networkHints(results []services_aws.NetworkCheck) []string {
+	var dns, certificate, timeout, other bool
+	for _, result := range results {
+		if result.OK() {
+			continue
+		}
+		var unknownAuthority x509.UnknownAuthorityError
+		var netErr net.Error
+		switch {
+		case result.DNSErr != nil && result.Proxy == "":
+			dns = true
+		case errors.As(result.Err, &unknownAuthority):
+			certificate = true
+		case errors.Is(result.Err, context.DeadlineExceeded) || errors.As(result.Err, &netErr) && netErr.Timeout():
+			timeout = true
+		default:
+			other = true
+		}
+	}
+
+	var hints []string
+	if dns {
+		hints = append(hints, "Some hosts don't resolve: check your DNS and VPN, or set aws.proxy if the network requires a proxy")
+	}
+	if certificate {
+		hints = append(hints, "A certificate isn't trusted, usually because a proxy inspects TLS: set aws.ca_bundle to its CA certificate")
+	}
+	if timeout {
+		hints = append(hints, "Some endpoints didn't answer in time, which is what makes a login hang: a firewall may block them, set aws.proxy or HTTPS_PROXY if outbound traffic must go through a proxy")
+	}
+	if other {
+		hints = append(hints, "Some endpoints refused the connection: check the proxy and the overrides in aws.endpoints")
+	}
+	return hints
+}
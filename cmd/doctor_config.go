@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	"github.com/spf13/cobra"
+)
+
+var (
+	doctorConfigCmd = &cobra.Command{
+		Use:   "config",
+		Short: "Find duplicate and shadowed profiles in the AWS config files",
+		Long: `Check ~/.aws/config and the files layered over it (ark.d/*.conf, then custom_config) for profiles
+defined in more than one file, where the last file silently wins as a whole and the keys the definitions
+disagree on are listed, profiles defined twice in the same file, and profiles whose source_profile is in
+none of the AWS config or credentials files. Exits with status 1 when an issue is found.`,
+		Args: cobra.NoArgs,
+		Run:  doctorConfig,
+	}
+)
+
+func init() {
+	doctorCmd.AddCommand(doctorConfigCmd)
+}
+
+func doctorConfig(cmd *cobra.Command, args []string) {
+	issues, err := services_aws.LintConfig()
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	if len(issues) == 0 {
+		fmt.Println("✅ No duplicate, shadowed or dangling profiles in the AWS config files")
+		return
+	}
+
+	fmt.Print(formatConfigIssues(issues))
+	fmt.Println("\n💡 Remove the definitions that aren't read, or move the keys you need to the file that is")
+	os.Exit(1)
+}
+
+// formatConfigIssues lists the issues, each with the keys its definitions disagree on
+func formatConfigIssues(issues []services_aws.ConfigIssue) string {
+	var s strings.Builder
+	for _, issue := range issues {
+		fmt.Fprintf(&s, "⚠️  %s\n", issue.Summary())
+		if issue.Kind != services_aws.ConfigIssueShadowed {
+			continue
+		}
+		for _, detail := range issue.Details {
+			fmt.Fprintf(&s, "      %s\n", detail)
+		}
+	}
+	return s.String()
+}
@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"testing"
+
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatConfigIssues(t *testing.T) {
+	output := formatConfigIssues([]services_aws.ConfigIssue{
+		{
+			Kind:    services_aws.ConfigIssueShadowed,
+			Profile: "dev",
+			Files:   []string{"/home/me/.aws/config", "/home/me/.aws/custom_config"},
+			Details: []string{"sso_role_name: ReadOnly (config) vs Admin (custom_config)"},
+		},
+		{Kind: services_aws.ConfigIssueMissingSource, Profile: "ci", Files: []string{"/home/me/.aws/config"}, Details: []string{"gone"}},
+	})
+
+	assert.Equal(t, "⚠️  profile dev is defined in config, custom_config; only custom_config is read\n"+
+		"      sso_role_name: ReadOnly (config) vs Admin (custom_config)\n"+
+		"⚠️  profile ci uses source_profile gone, which is not in any AWS config or credentials file\n", output)
+}
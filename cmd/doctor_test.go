@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNetworkHints(t *testing.T) {
+	endpoint := services_aws.NetworkEndpoint{Service: "sts", Region: "us-east-1", URL: "https://sts.us-east-1.amazonaws.com"}
+	dnsErr := errors.New("no such host")
+
+	assert.Empty(t, networkHints([]services_aws.NetworkCheck{{NetworkEndpoint: endpoint, Status: 403}}))
+
+	hints := networkHints([]services_aws.NetworkCheck{
+		{NetworkEndpoint: endpoint, DNSErr: dnsErr, Err: dnsErr},
+		{NetworkEndpoint: endpoint, Err: fmt.Errorf("Get: %w", x509.UnknownAuthorityError{})},
+		{NetworkEndpoint: endpoint, Err: fmt.Errorf("Get: %w", context.DeadlineExceeded)},
+	})
+	assert.Len(t, hints, 3)
+	assert.Contains(t, hints[0], "DNS")
+	assert.Contains(t, hints[1], "aws.ca_bundle")
+	assert.Contains(t, hints[2], "hang")
+
+	// Behind a proxy the local DNS failing is expected; the request failing is what matters
+	hints = networkHints([]services_aws.NetworkCheck{
+		{NetworkEndpoint: endpoint, Proxy: "http://proxy.internal:3128", DNSErr: dnsErr, Err: errors.New("proxyconnect tcp: connection refused")},
+	})
+	assert.Len(t, hints, 1)
+	assert.Contains(t, hints[0], "refused")
+}
+
+func TestDescribeProxies(t *testing.T) {
+	direct := services_aws.NetworkCheck{}
+	proxied := services_aws.NetworkCheck{Proxy: "http://proxy.internal:3128"}
+
+	assert.Contains(t, describeProxies([]services_aws.NetworkCheck{direct}), "Proxy: none")
+	assert.Equal(t, "Proxy: http://proxy.internal:3128", describeProxies([]services_aws.NetworkCheck{proxied}))
+	assert.Contains(t, describeProxies([]services_aws.NetworkCheck{proxied, direct}), "NO_PROXY")
+}
+
+func TestBuildNetworkTable(t *testing.T) {
+	endpoint := services_aws.NetworkEndpoint{Service: "sts", Region: "us-east-1", URL: "https://sts.us-east-1.amazonaws.com"}
+	table := buildNetworkTable([]services_aws.NetworkCheck{
+		{NetworkEndpoint: endpoint, DNS: 12 * time.Millisecond, TLS: 40 * time.Millisecond, Status: 403},
+		{NetworkEndpoint: endpoint, Proxy: "http://proxy.internal:3128", DNSErr: errors.New("no such host"), Err: errors.New("timeout")},
+	})
+
+	assert.Equal(t, []string{"sts", "us-east-1", "https://sts.us-east-1.amazonaws.com", "   12 ms", "   40 ms", "✓ HTTP 403"}, table.Rows[0])
+	assert.Equal(t, []string{"sts", "us-east-1", "https://sts.us-east-1.amazonaws.com", "via proxy", "-", "✗ timeout"}, table.Rows[1])
+}
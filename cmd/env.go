@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	services_environment "github.com/andresgarcia29/ark-cli/services/environment"
+	services_kubernetes "github.com/andresgarcia29/ark-cli/services/kubernetes"
+	"github.com/spf13/cobra"
+)
+
+var (
+	envCmd = &cobra.Command{
+		Use:   "env",
+		Short: "Export the AWS/Kubernetes environment declared by a .ark file",
+		Long: `Find the nearest .ark or .ark.yaml file from the current directory and print the
+shell statements exporting its profile, region, cluster and kubeconfig.
+
+Example .ark.yaml:
+  profile: payments-prod-readonly
+  region: us-east-1
+  cluster: payments-prod
+  kubeconfig: ./kubeconfig
+
+A .ark file is only exported once it was allowed with ark env allow, and again after every change,
+since a cloned repository could otherwise point KUBECONFIG or AWS_PROFILE anywhere.
+
+Example usage:
+  eval "$(ark env)"                 # Export the variables for the current directory
+  eval "$(ark env --hook zsh)"      # Install a hook that updates them on every cd`,
+		Run: envCommand,
+	}
+
+	envAllowCmd = &cobra.Command{
+		Use:   "allow [file]",
+		Short: "Trust a .ark file so ark env exports it",
+		Long: `Trust the nearest .ark or .ark.yaml file from the current directory, or the given one, so ark env and
+its hook export it. The contents are recorded: a file that changes afterwards must be allowed again.`,
+		Args: cobra.MaximumNArgs(1),
+		Run:  envAllow,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(envCmd)
+	envCmd.AddCommand(envAllowCmd)
+	envCmd.Flags().String("shell", "", "Shell syntax to render (bash, zsh, fish); defaults to $SHELL")
+	envCmd.Flags().String("hook", "", "Print a shell hook that runs `ark env` on directory change (bash, zsh, fish)")
+	envCmd.Flags().Bool("switch-context", false, "Also switch kubectl to the cluster declared in the .ark file")
+}
+
+func envCommand(cmd *cobra.Command, args []string) {
+	shell, _ := cmd.Flags().GetString("shell")
+	hook, _ := cmd.Flags().GetString("hook")
+	switchContext, _ := cmd.Flags().GetBool("switch-context")
+
+	if hook != "" {
+		script, err := services_environment.RenderHook(hook)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			return
+		}
+		fmt.Print(script)
+		return
+	}
+
+	if shell == "" {
+		shell = detectShell()
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Failed to get current directory: %v\n", err)
+		return
+	}
+
+	env, err := renderProjectEnvironment(shell, cwd, os.LookupEnv)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return
+	}
+	fmt.Print(env.Output)
+	if env.Untrusted != nil {
+		// stderr, so the hook's eval never runs it
+		fmt.Fprintf(os.Stderr, "ark: %v, nothing exported: review it, then run `ark env allow`\n", env.Untrusted)
+	}
+
+	if project := env.Project; switchContext && project != nil && project.Cluster != "" {
+		if err := services_kubernetes.SwitchToContext(project.Cluster); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to switch to cluster %s: %v\n", project.Cluster, err)
+		}
+	}
+}
+
+// projectEnvironment is what ark env prints for a directory
+type projectEnvironment struct {
+	// Output holds the statements moving the shell into the directory's environment
+	Output string
+	// Project is the exported project, nil outside projects and for untrusted ones
+	Project *services_environment.ProjectConfig
+	// Untrusted is set when the directory's .ark file was not exported because it isn't allowed as it is
+	Untrusted error
+}
+
+// renderProjectEnvironment renders the statements needed to move the shell into dir's environment
+// lookupEnv reads the shell's environment: ARK_ENV_FILE is the .ark file currently exported, and the values
+// saved when entering the project are restored when leaving it
+func renderProjectEnvironment(shell, dir string, lookupEnv func(string) (string, bool)) (projectEnvironment, error) {
+	var env projectEnvironment
+	activeFile, _ := lookupEnv("ARK_ENV_FILE")
+	project, err := services_environment.LoadProjectForDir(dir)
+	if err != nil {
+		return env, err
+	}
+	if project != nil {
+		if err := services_environment.CheckProjectTrust(project); err != nil {
+			if !errors.Is(err, services_environment.ErrProjectNotAllowed) && !errors.Is(err, services_environment.ErrProjectChanged) {
+				return env, err
+			}
+			// An untrusted file is handled like no file at all: what ark exported before is removed
+			env.Untrusted = err
+			project = nil
+		}
+	}
+
+	if project == nil {
+		// Only touch variables when ark exported them in the first place
+		if activeFile == "" {
+			return env, nil
+		}
+		env.Output, err = services_environment.RenderRestore(shell, nil, true, lookupEnv)
+		return env, err
+	}
+
+	vars := project.EnvVars()
+	switch {
+	case activeFile == "":
+		// Entering a project: keep what the user had set, to put it back when leaving
+		env.Output, err = services_environment.RenderSavePrevious(shell, lookupEnv)
+	case activeFile != project.Path:
+		// Switching between projects: restore the variables the new project doesn't declare
+		declared := make([]string, len(vars))
+		for i, v := range vars {
+			declared[i] = v.Name
+		}
+		env.Output, err = services_environment.RenderRestore(shell, declared, false, lookupEnv)
+	}
+	if err != nil {
+		return env, err
+	}
+
+	exports, err := services_environment.RenderExports(shell, vars)
+	if err != nil {
+		return env, err
+	}
+	env.Output += exports
+	env.Project = project
+	return env, nil
+}
+
+func envAllow(cmd *cobra.Command, args []string) {
+	var path string
+	if len(args) == 1 {
+		path = args[0]
+	} else {
+		cwd, err := os.Getwd()
+		if err != nil {
+			fmt.Println("Error:", err)
+			return
+		}
+		if path, err = services_environment.FindProjectFile(cwd); err != nil {
+			fmt.Println("Error:", err)
+			return
+		}
+		if path == "" {
+			fmt.Println("Error: no .ark or .ark.yaml file in this directory or its parents")
+			return
+		}
+	}
+
+	project, err := services_environment.AllowProjectFile(path)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	fmt.Printf("✓ Allowed %s: ark env exports it until it changes\n", project.Path)
+}
+
+// detectShell returns the shell name from $SHELL, defaulting to bash
+func detectShell() string {
+	if shell := filepath.Base(os.Getenv("SHELL")); shell == "zsh" || shell == "fish" {
+		return shell
+	}
+	return "bash"
+}
@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	services_environment "github.com/andresgarcia29/ark-cli/services/environment"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderProjectEnvironment(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_STATE_HOME", "")
+	root := t.TempDir()
+	project := filepath.Join(root, "project")
+	outside := filepath.Join(root, "outside")
+	require.NoError(t, os.MkdirAll(project, 0700))
+	require.NoError(t, os.MkdirAll(outside, 0700))
+	projectFile := filepath.Join(project, ".ark")
+	require.NoError(t, os.WriteFile(projectFile, []byte("profile: dev\nregion: us-west-2\n"), 0600))
+	_, err := services_environment.AllowProjectFile(projectFile)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		dir        string
+		activeFile string
+		contains   []string
+		empty      bool
+	}{
+		{
+			name:     "entering a project exports its variables",
+			dir:      project,
+			contains: []string{"export AWS_PROFILE='dev'", "export AWS_REGION='us-west-2'", "export ARK_ENV_FILE="},
+		},
+		{
+			name:       "leaving a project unsets variables",
+			dir:        outside,
+			activeFile: projectFile,
+			contains:   []string{"unset AWS_PROFILE", "unset ARK_ENV_FILE"},
+		},
+		{
+			name:  "outside any project without active file prints nothing",
+			dir:   outside,
+			empty: true,
+		},
+		{
+			name:       "switching projects unsets what the new one doesn't declare",
+			dir:        project,
+			activeFile: "/other/.ark",
+			contains:   []string{"unset KUBECONFIG", "export AWS_PROFILE='dev'"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env, err := renderProjectEnvironment("bash", tt.dir, lookupIn(map[string]string{"ARK_ENV_FILE": tt.activeFile}))
+			require.NoError(t, err)
+			assert.NoError(t, env.Untrusted)
+			if tt.empty {
+				assert.Empty(t, env.Output)
+			}
+			for _, expected := range tt.contains {
+				assert.Contains(t, env.Output, expected)
+			}
+		})
+	}
+}
+
+func TestRenderProjectEnvironmentUntrusted(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_STATE_HOME", "")
+	project := t.TempDir()
+	projectFile := filepath.Join(project, ".ark")
+	require.NoError(t, os.WriteFile(projectFile, []byte("profile: dev\nkubeconfig: ./evil.yaml\n"), 0600))
+
+	// A cloned repository's .ark file exports nothing until it is allowed
+	env, err := renderProjectEnvironment("bash", project, lookupIn(nil))
+	require.NoError(t, err)
+	assert.Empty(t, env.Output)
+	assert.Nil(t, env.Project)
+	assert.ErrorIs(t, env.Untrusted, services_environment.ErrProjectNotAllowed)
+
+	_, err = services_environment.AllowProjectFile(projectFile)
+	require.NoError(t, err)
+	env, err = renderProjectEnvironment("bash", project, lookupIn(nil))
+	require.NoError(t, err)
+	assert.Contains(t, env.Output, "export KUBECONFIG=")
+
+	// Once changed, the file is dropped like when leaving the project
+	require.NoError(t, os.WriteFile(projectFile, []byte("profile: prod\nkubeconfig: ./evil.yaml\n"), 0600))
+	env, err = renderProjectEnvironment("bash", project, lookupIn(map[string]string{"ARK_ENV_FILE": projectFile}))
+	require.NoError(t, err)
+	assert.ErrorIs(t, env.Untrusted, services_environment.ErrProjectChanged)
+	assert.Contains(t, env.Output, "unset KUBECONFIG")
+	assert.NotContains(t, env.Output, "export")
+}
+
+func TestRenderProjectEnvironmentRestoresPreviousValues(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_STATE_HOME", "")
+	root := t.TempDir()
+	dev := filepath.Join(root, "dev")
+	prod := filepath.Join(root, "prod")
+	outside := filepath.Join(root, "outside")
+	for dir, contents := range map[string]string{dev: "profile: dev\nregion: us-west-2\n", prod: "profile: prod\nkubeconfig: ./kubeconfig\n"} {
+		require.NoError(t, os.MkdirAll(dir, 0700))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, ".ark"), []byte(contents), 0600))
+		_, err := services_environment.AllowProjectFile(filepath.Join(dir, ".ark"))
+		require.NoError(t, err)
+	}
+	require.NoError(t, os.MkdirAll(outside, 0700))
+
+	// The user had a profile and a kubeconfig of their own, and no region
+	shellEnv := map[string]string{"AWS_PROFILE": "personal", "KUBECONFIG": "/home/me/.kube/config"}
+	cd := func(dir string) {
+		env, err := renderProjectEnvironment("bash", dir, lookupIn(shellEnv))
+		require.NoError(t, err)
+		applyBashOutput(t, shellEnv, env.Output)
+	}
+
+	cd(dev)
+	assert.Equal(t, "dev", shellEnv["AWS_PROFILE"])
+	assert.Equal(t, "us-west-2", shellEnv["AWS_REGION"])
+	assert.Equal(t, "/home/me/.kube/config", shellEnv["KUBECONFIG"])
+
+	cd(prod)
+	assert.Equal(t, "prod", shellEnv["AWS_PROFILE"])
+	assert.Equal(t, filepath.Join(prod, "kubeconfig"), shellEnv["KUBECONFIG"])
+	assert.NotContains(t, shellEnv, "AWS_REGION", "dev's region is not kept in prod")
+
+	cd(outside)
+	assert.Equal(t, map[string]string{"AWS_PROFILE": "personal", "KUBECONFIG": "/home/me/.kube/config"}, shellEnv,
+		"leaving restores what was set and unsets the rest, saved values included")
+}
+
+// lookupIn returns an os.LookupEnv reading env, where empty values count as unset
+func lookupIn(env map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok && value != ""
+	}
+}
+
+// applyBashOutput applies the export and unset statements of ark env to env
+func applyBashOutput(t *testing.T, env map[string]string, output string) {
+	t.Helper()
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		if name, ok := strings.CutPrefix(line, "unset "); ok {
+			delete(env, name)
+			continue
+		}
+		assignment, ok := strings.CutPrefix(line, "export ")
+		require.True(t, ok, "unexpected statement %q", line)
+		name, value, _ := strings.Cut(assignment, "=")
+		env[name] = strings.Trim(value, "'")
+	}
+}
+
+func TestDetectShell(t *testing.T) {
+	t.Setenv("SHELL", "/usr/bin/zsh")
+	assert.Equal(t, "zsh", detectShell())
+
+	t.Setenv("SHELL", "/bin/sh")
+	assert.Equal(t, "bash", detectShell())
+}
@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// commandExamples are shown in the help and man page of each command, keyed by command path
+// Keeping them in one place makes missing examples easy to spot (see TestCommandsHaveHelp)
+var commandExamples = map[string][]string{
+	"ark": {
+		"# Sign in to the SSO portal and write a profile per account and role",
+		"ark bootstrap --start-url https://my-org.awsapps.com/start",
+		"# Add the EKS clusters of every account to kubeconfig, then switch between them",
+		"ark kubernetes setup --regions us-east-1,eu-west-1",
+		"ark ctx prod",
+	},
+	"ark accounts export": {
+		"ark accounts export --format aws-nuke --accounts 'sandbox-*' --regions us-east-1,eu-west-1 > nuke-config.yaml",
+		"ark accounts export --format steampipe --roles ReadOnlyAccess --output ~/.steampipe/config/aws.spc",
+	},
+	"ark audit export": {
+		"ark audit export --since 168h",
+		"ark audit export --format csv --output audit.csv",
+	},
+	"ark aws": {
+		"ark aws",
+	},
+	"ark aws login": {
+		"ark aws login --profile dev-readonly --set-default",
+		"ark aws login --account 111111111111 --role ReadOnlyAccess --write-profile",
+		`ark aws login --profile prod-breakglass --break-glass --justification "INC-1234 outage"`,
+	},
+	"ark aws profiles": {
+		"ark aws profiles",
+		"ark aws profiles --sort account --page-size 20",
+		"ark aws profiles --tags env=prod --format json",
+	},
+	"ark aws profiles generate-assume": {
+		"ark aws profiles generate-assume --from roles.txt --source-profile hub-admin",
+		"ark aws profiles generate-assume --from roles.txt --source-profile hub-admin --name-template '{{.AccountName}}-{{.RoleName}}' --diff-only",
+	},
+	"ark aws sso": {
+		"ark aws sso --start-url https://my-org.awsapps.com/start --region eu-west-1",
+		"ark aws sso --start-url https://my-org.awsapps.com/start --diff-only",
+		"ark aws sso --start-url https://my-org.awsapps.com/start --offline",
+		"ark aws sso --start-url https://my-org.awsapps.com/start --use-device-code",
+	},
+	"ark backup export": {
+		"ARK_BACKUP_KEY=$(op read op://private/ark/backup-key) ark backup export ark-backup.tar.enc --include-tokens",
+		"ark backup export ark-backup.tar.gz",
+	},
+	"ark backup import": {
+		"ARK_BACKUP_KEY=$(op read op://private/ark/backup-key) ark backup import ark-backup.tar.enc --dry-run",
+		"ark backup import ark-backup.tar.gz --yes",
+	},
+	"ark bootstrap": {
+		"ark bootstrap --start-url https://my-org.awsapps.com/start",
+		`ark bootstrap --start-url https://my-org.awsapps.com/start --accounts 'prod-*' --roles 'ReadOnly*' --name-template '{{.AccountName}}-ro'`,
+		"ark bootstrap --start-url https://my-org.awsapps.com/start --tags-from 111111111111/OrgReadOnly --tags env=prod",
+	},
+	"ark config set": {
+		"ark config set aws.max_attempts 8",
+		"ark config set aws.timeout 45s",
+		"ark config set aws.break_glass.roles '[AdminBreakGlass]'",
+	},
+	"ark config view": {
+		"ark config view",
+		"ark config view kubernetes",
+	},
+	"ark credentials check": {
+		"ark credentials check",
+		"ark credentials check --profiles 'prod-*' --sort status",
+	},
+	"ark credentials prune": {
+		"ark credentials prune --dry-run",
+	},
+	"ark credentials sync": {
+		"ark credentials sync",
+		"ark credentials sync --profiles 'prod-*'",
+	},
+	"ark ctx": {
+		"ark ctx",
+		"ark ctx prod-eu",
+		"ark ctx -",
+	},
+	"ark doctor": {
+		"ark doctor network",
+		"ark doctor config",
+	},
+	"ark doctor config": {
+		"ark doctor config",
+	},
+	"ark doctor network": {
+		"ark doctor network",
+		"ark doctor network --sso-regions eu-west-1 --regions us-east-1,eu-west-1 --timeout 5s",
+	},
+	"ark env": {
+		`eval "$(ark env)"`,
+		`eval "$(ark env --hook zsh)"`,
+	},
+	"ark env allow": {
+		"ark env allow",
+		"ark env allow ~/src/payments/.ark.yaml",
+	},
+	"ark init": {
+		"ark init --org mycorp --source https://platform.example.com/ark/mycorp.yaml --public-key <base64-key>",
+		"ark init --org mycorp --source s3://mycorp-ark/manifest.yaml.enc --public-key <base64-key>",
+		"ark init --org mycorp --diff-only",
+	},
+	"ark init seal": {
+		"ark init seal mycorp.yaml --org mycorp --private-key ark-manifest.pem",
+		"ARK_MANIFEST_KEY=<base64-key> ark init seal mycorp.yaml --org mycorp --private-key ark-manifest.pem --encrypt",
+	},
+	"ark kubernetes": {
+		"ark kubernetes",
+		"ark k8s",
+	},
+	"ark kubernetes access grant": {
+		"ark kubernetes access grant --cluster-name prod --region us-east-1 --principal arn:aws:iam::111111111111:role/Developers --policy view",
+		"ark kubernetes access grant --cluster-name prod --region us-east-1 --principal arn:aws:iam::111111111111:role/Team --policy edit --namespaces team-a,team-b",
+	},
+	"ark kubernetes access legacy": {
+		"ark kubernetes access legacy --cluster-name prod --region us-east-1",
+	},
+	"ark kubernetes access list": {
+		"ark kubernetes access list --cluster-name prod --region us-east-1 --profile prod-admin",
+	},
+	"ark kubernetes access revoke": {
+		"ark kubernetes access revoke --cluster-name prod --region us-east-1 --principal arn:aws:iam::111111111111:role/Developers --policy view",
+		"ark kubernetes access revoke --cluster-name prod --region us-east-1 --principal arn:aws:iam::111111111111:role/Developers",
+	},
+	"ark kubernetes addons": {
+		"ark kubernetes addons --regions us-east-1 --outdated",
+	},
+	"ark kubernetes adopt": {
+		"ark kubernetes adopt --dry-run",
+		"ark kubernetes adopt --kubeconfig-path ~/.kube/config",
+	},
+	"ark kubernetes current": {
+		"ark kubernetes current",
+		"ark kubernetes current --kubeconfig-path ~/.kube/ark.yaml",
+	},
+	"ark kubernetes diagnose": {
+		"ark kubernetes diagnose",
+	},
+	"ark kubernetes list": {
+		"ark kubernetes list",
+		"ark kubernetes list --kubeconfig-path ~/.kube/ark.yaml",
+	},
+	"ark kubernetes nodegroups": {
+		"ark kubernetes nodegroups --profile prod-readonly --regions us-east-1,eu-west-1",
+	},
+	"ark kubernetes rename": {
+		"ark kubernetes rename --dry-run",
+		"ark kubernetes rename --template '{{.Cluster}}-{{.Region}}'",
+	},
+	"ark kubernetes setup": {
+		"ark kubernetes setup --regions us-east-1,eu-west-1",
+		"ark kubernetes setup --role-prefixs ReadOnly --regions us-east-1 --clean",
+		"ark kubernetes setup --offline",
+		"ark kubernetes setup --regions us-east-1 --details",
+		"ark kubernetes setup --writer native --verify",
+		"ark kubernetes setup --regions us-east-1 --cluster-profiles ~/cluster_profiles.yaml",
+	},
+	"ark kubernetes token": {
+		"ark kubernetes token --cluster-name prod --region us-east-1 --profile prod-readonly",
+	},
+	"ark kubernetes versions": {
+		"ark kubernetes versions --regions us-east-1",
+		"ark kubernetes versions --regions us-east-1 --format json",
+		"ark kubernetes versions --regions us-east-1,eu-west-1 --discovery resource-explorer",
+	},
+	"ark regions": {
+		"ark regions --profile dev-readonly",
+		"ark regions --latency",
+	},
+	"ark shell": {
+		"ark shell --profile dev-readonly --cluster dev-main",
+	},
+	"ark status": {
+		"ark status",
+		"ark status --format starship",
+	},
+	"ark switch": {
+		"ark switch --account 222222222222 --role ReadOnlyAccess",
+		"ark switch --account 222222222222 --role Admin --set-default=false",
+	},
+	"ark uninstall-data": {
+		"ark uninstall-data --dry-run",
+		"ark uninstall-data --yes",
+	},
+	"ark terraform providers": {
+		"ark terraform providers --accounts 'payments-*' --role AdministratorAccess > providers.tf",
+		"ark terraform providers --accounts 111111111111,222222222222 --region eu-west-1 --output providers.tf",
+	},
+	"ark version": {
+		"ark version",
+	},
+	"ark warm": {
+		"ark warm --preset incident",
+		"ark warm --preset incident --yes --kubeconfig-path ~/.kube/incident",
+	},
+	"ark workspace": {
+		"ark workspace use",
+		"ark workspace list",
+	},
+	"ark workspace list": {
+		"ark workspace list",
+	},
+	"ark workspace use": {
+		"ark workspace use",
+		"ark workspace use payments-prod",
+	},
+}
+
+// addExamples fills the Example of cmd and its subcommands from commandExamples, indented like cobra's help
+func addExamples(cmd *cobra.Command) {
+	if examples, ok := commandExamples[cmd.CommandPath()]; ok && cmd.Example == "" {
+		cmd.Example = "  " + strings.Join(examples, "\n  ")
+	}
+	for _, sub := range cmd.Commands() {
+		addExamples(sub)
+	}
+}
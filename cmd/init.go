@@ -0,0 +1,240 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	ark_config "github.com/andresgarcia29/ark-cli/config"
+	controllers "github.com/andresgarcia29/ark-cli/controllers/aws"
+	"github.com/andresgarcia29/ark-cli/lib/animation"
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	services_manifest "github.com/andresgarcia29/ark-cli/services/manifest"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	initCmd = &cobra.Command{
+		Use:   "init",
+		Short: "Configure ark from your organization's signed bootstrap manifest",
+		Long: `Fetch the bootstrap manifest an organization publishes, check its signature and apply it: the ark config
+settings it lists are written, and ark bootstrap writes the profiles of its SSO portal, accounts and preferred roles
+with its naming template. Every laptop initialized from the same manifest ends up configured the same way.
+
+The manifest is read from an https:// URL, s3://<bucket>/<key> (with the default AWS credentials) or
+git+<repository>#<path>, and its Ed25519 signature from the same location with .sig appended. Manifests encrypted by
+ark init seal --encrypt are decrypted with the key in ARK_MANIFEST_KEY. --source and --public-key are saved under
+orgs.<name> in the ark config, so later runs only need --org.`,
+		Args: cobra.NoArgs,
+		Run:  initOrg,
+	}
+
+	initSealCmd = &cobra.Command{
+		Use:   "seal <manifest>",
+		Short: "Sign, and optionally encrypt, a bootstrap manifest for ark init",
+		Long: `Check that a bootstrap manifest parses, then sign it with an Ed25519 private key in PEM (PKCS #8) form, as
+written by openssl genpkey -algorithm ed25519. The signature is written next to the output with .sig appended, and
+the public key to configure with ark init --public-key is printed. With --encrypt, the manifest is first sealed with
+AES-256-GCM under the base64 key in ARK_MANIFEST_KEY, and only the encrypted file is signed.`,
+		Args: cobra.ExactArgs(1),
+		Run:  initSeal,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(initCmd)
+	initCmd.Flags().String("org", "", "Organization whose manifest to apply")
+	initCmd.Flags().String("source", "", "Location of the manifest: https:// URL, s3://<bucket>/<key> or git+<repository>#<path> (default: orgs.<org>.source in the ark config)")
+	initCmd.Flags().String("public-key", "", "Base64 Ed25519 key the manifest is signed with (default: orgs.<org>.public_key in the ark config)")
+	initCmd.Flags().Bool("force", false, "Re-authenticate even when the cached SSO token is still valid")
+	initCmd.Flags().Bool("use-device-code", false, "Sign in with a device code even when a browser is available, e.g. to approve on another machine")
+	initCmd.Flags().Bool("diff-only", false, "Show the manifest and the profiles ~/.aws/config would gain, change and lose, without writing anything")
+
+	initCmd.AddCommand(initSealCmd)
+	initSealCmd.Flags().String("org", "", "Organization the manifest is for")
+	initSealCmd.Flags().String("private-key", "", "PEM file with the Ed25519 private key")
+	initSealCmd.Flags().Bool("encrypt", false, "Encrypt the manifest with the key in ARK_MANIFEST_KEY before signing it")
+	initSealCmd.Flags().String("out", "", "File the published manifest is written to (default: <manifest>.enc with --encrypt, else the manifest itself)")
+	if err := initSealCmd.MarkFlagRequired("org"); err != nil {
+		panic(err)
+	}
+	if err := initSealCmd.MarkFlagRequired("private-key"); err != nil {
+		panic(err)
+	}
+}
+
+func initOrg(cmd *cobra.Command, args []string) {
+	org, err := requireStringFlag(cmd, "org", "Organization", animation.InputOptions{Placeholder: "mycorp"})
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	sourceFlag, _ := cmd.Flags().GetString("source")
+	publicKeyFlag, _ := cmd.Flags().GetString("public-key")
+	force, _ := cmd.Flags().GetBool("force")
+	diffOnly, _ := cmd.Flags().GetBool("diff-only")
+	deviceCode, _ := cmd.Flags().GetBool("use-device-code")
+
+	cfg, err := ark_config.Load()
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	orgConfig, err := resolveOrg(cfg.Orgs, org, sourceFlag, publicKeyFlag)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	ctx := context.Background()
+	manifest, err := services_manifest.Load(ctx, services_manifest.Source{Org: org, Location: orgConfig.Source, PublicKey: orgConfig.PublicKey})
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	fmt.Printf("✓ Manifest of %s verified (%s)\n", org, orgConfig.Source)
+
+	if diffOnly {
+		for _, line := range manifest.Summary() {
+			fmt.Printf("  %s\n", line)
+		}
+	} else {
+		if err := preflightWrites(ark_config.ConfigPath, services_aws.ConfigFilePath); err != nil {
+			fmt.Println("Error:", err)
+			return
+		}
+		confirmed, err := confirmAction(fmt.Sprintf("Configure ark for %s?", org), animation.ConfirmOptions{Details: manifest.Summary()})
+		if err != nil {
+			fmt.Println("Error:", err)
+			return
+		}
+		if !confirmed {
+			fmt.Println("Aborted: nothing was written")
+			return
+		}
+		if err := applyManifestSettings(manifest); err != nil {
+			fmt.Println("Error:", err)
+			return
+		}
+		if err := saveOrg(cfg.Orgs, org, orgConfig); err != nil {
+			fmt.Println("Error:", err)
+			return
+		}
+	}
+
+	runBootstrap(ctx, "ark init", &controllers.ProfileBootstrapper{
+		SSORegion:    manifest.SSO.Region,
+		StartURL:     manifest.SSO.StartURL,
+		Filter:       services_aws.ProfileFilter{Accounts: manifest.Bootstrap.Accounts, Roles: manifest.Bootstrap.Roles},
+		NameTemplate: manifest.Bootstrap.NameTemplate,
+		SSOOptions: controllers.SSOOptions{
+			AssumeYes:  AssumeYes,
+			Force:      force,
+			DiffOnly:   diffOnly,
+			DeviceCode: deviceCode,
+		},
+	})
+	if command := manifest.KubernetesSetupCommand(); command != "" && !diffOnly {
+		fmt.Printf("\nNext, write the kubeconfig contexts of %s with:\n  %s\n", org, command)
+	}
+}
+
+// resolveOrg returns where the manifest of org is published, the flags overriding the ark config
+func resolveOrg(orgs map[string]ark_config.OrgConfig, org, source, publicKey string) (ark_config.OrgConfig, error) {
+	resolved := orgs[org]
+	if source != "" {
+		resolved.Source = source
+	}
+	if publicKey != "" {
+		resolved.PublicKey = publicKey
+	}
+	if resolved.Source == "" {
+		return resolved, fmt.Errorf("no manifest source for org %s: pass --source, or set orgs.%s in the ark config", org, org)
+	}
+	if resolved.PublicKey == "" {
+		return resolved, fmt.Errorf("no public key for org %s: pass --public-key, or set orgs.%s in the ark config", org, org)
+	}
+	return resolved, nil
+}
+
+// applyManifestSettings writes the ark config settings of the manifest, checked like `ark config set`
+func applyManifestSettings(manifest *services_manifest.Manifest) error {
+	for _, key := range manifest.SettingKeys() {
+		if _, err := ark_config.Set(key, manifest.Settings[key]); err != nil {
+			return fmt.Errorf("manifest setting %s: %w", key, err)
+		}
+		fmt.Printf("✓ Set %s\n", key)
+	}
+	return nil
+}
+
+// saveOrg records where the manifest of org is published, so later runs only need --org
+func saveOrg(orgs map[string]ark_config.OrgConfig, org string, orgConfig ark_config.OrgConfig) error {
+	if orgs[org] == orgConfig {
+		return nil
+	}
+	updated := map[string]ark_config.OrgConfig{org: orgConfig}
+	for name, existing := range orgs {
+		if name != org {
+			updated[name] = existing
+		}
+	}
+	value, err := yaml.Marshal(updated)
+	if err != nil {
+		return err
+	}
+	_, err = ark_config.Set("orgs", string(value))
+	return err
+}
+
+func initSeal(cmd *cobra.Command, args []string) {
+	org, _ := cmd.Flags().GetString("org")
+	privateKeyPath, _ := cmd.Flags().GetString("private-key")
+	encrypt, _ := cmd.Flags().GetBool("encrypt")
+	out, _ := cmd.Flags().GetString("out")
+
+	publicKey, err := sealManifest(args[0], out, org, privateKeyPath, encrypt)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	fmt.Printf("Public key for ark init --public-key: %s\n", publicKey)
+}
+
+// sealManifest checks a manifest parses, encrypts it when asked and signs what is published, returning the public key
+func sealManifest(manifestPath, out, org, privateKeyPath string, encrypt bool) (string, error) {
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return "", err
+	}
+	if _, err := services_manifest.Parse(data, org); err != nil {
+		return "", err
+	}
+	privateKey, err := services_manifest.ReadPrivateKey(privateKeyPath)
+	if err != nil {
+		return "", err
+	}
+
+	if out == "" {
+		out = manifestPath
+		if encrypt {
+			out += ".enc"
+		}
+	}
+	if encrypt {
+		if data, err = services_manifest.Encrypt(data, org); err != nil {
+			return "", err
+		}
+	}
+	if out != manifestPath || encrypt {
+		if err := os.WriteFile(out, data, 0644); err != nil {
+			return "", err
+		}
+	}
+	if err := os.WriteFile(out+".sig", services_manifest.Sign(data, privateKey), 0644); err != nil {
+		return "", err
+	}
+	fmt.Printf("✓ Wrote %s and %s.sig\n", out, out)
+	return services_manifest.PublicKey(privateKey), nil
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	ark_config "github.com/andresgarcia29/ark-cli/config"
+	services_manifest "github.com/andresgarcia29/ark-cli/services/manifest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const initTestManifest = `org: mycorp
+sso:
+  start_url: https://mycorp.awsapps.com/start
+settings:
+  aws.max_attempts: "8"
+  kubernetes.context_alias: "{{.Cluster}}-{{.Region}}"
+`
+
+func TestResolveOrg(t *testing.T) {
+	orgs := map[string]ark_config.OrgConfig{"mycorp": {Source: "s3://mycorp-ark/manifest.yaml", PublicKey: "saved"}}
+
+	resolved, err := resolveOrg(orgs, "mycorp", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, orgs["mycorp"], resolved)
+
+	resolved, err = resolveOrg(orgs, "mycorp", "https://platform.example.com/mycorp.yaml", "")
+	require.NoError(t, err)
+	assert.Equal(t, ark_config.OrgConfig{Source: "https://platform.example.com/mycorp.yaml", PublicKey: "saved"}, resolved)
+
+	_, err = resolveOrg(orgs, "othercorp", "", "key")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no manifest source for org othercorp")
+
+	_, err = resolveOrg(nil, "othercorp", "https://platform.example.com/othercorp.yaml", "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no public key for org othercorp")
+}
+
+func TestApplyManifestSettingsAndSaveOrg(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv(ark_config.ConfigPathEnvVar, configPath)
+	require.NoError(t, os.WriteFile(configPath, []byte("orgs:\n  othercorp:\n    source: https://other.example.com/ark.yaml\n    public_key: other\n"), 0600))
+
+	manifest, err := services_manifest.Parse([]byte(initTestManifest), "mycorp")
+	require.NoError(t, err)
+	require.NoError(t, applyManifestSettings(manifest))
+
+	cfg, err := ark_config.Load()
+	require.NoError(t, err)
+	assert.Equal(t, 8, cfg.AWS.MaxAttempts)
+	assert.Equal(t, "{{.Cluster}}-{{.Region}}", cfg.Kubernetes.ContextAlias)
+
+	mycorp := ark_config.OrgConfig{Source: "s3://mycorp-ark/manifest.yaml", PublicKey: "key"}
+	require.NoError(t, saveOrg(cfg.Orgs, "mycorp", mycorp))
+	cfg, err = ark_config.Load()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]ark_config.OrgConfig{
+		"mycorp":    mycorp,
+		"othercorp": {Source: "https://other.example.com/ark.yaml", PublicKey: "other"},
+	}, cfg.Orgs)
+	assert.Equal(t, 8, cfg.AWS.MaxAttempts, "saving the org keeps the other settings")
+
+	// A key ark doesn't know stops the manifest before bootstrap runs
+	manifest.Settings = map[string]string{"aws.bogus": "1"}
+	err = applyManifestSettings(manifest)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "manifest setting aws.bogus")
+}
+
+func TestSealManifest(t *testing.T) {
+	dir := t.TempDir()
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+	keyPath := filepath.Join(dir, "ark-manifest.pem")
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600))
+	manifestPath := filepath.Join(dir, "mycorp.yaml")
+	require.NoError(t, os.WriteFile(manifestPath, []byte(initTestManifest), 0600))
+
+	// Signed in place, then loaded back like ark init does
+	publicKey, err := sealManifest(manifestPath, "", "mycorp", keyPath, false)
+	require.NoError(t, err)
+	manifest, err := services_manifest.Load(context.Background(), services_manifest.Source{Org: "mycorp", Location: manifestPath, PublicKey: publicKey})
+	require.NoError(t, err)
+	assert.Equal(t, "https://mycorp.awsapps.com/start", manifest.SSO.StartURL)
+
+	// Encrypted to <manifest>.enc, leaving the plain manifest alone
+	key := make([]byte, 32)
+	_, err = rand.Read(key)
+	require.NoError(t, err)
+	t.Setenv(services_manifest.KeyEnvVar, base64.StdEncoding.EncodeToString(key))
+	_, err = sealManifest(manifestPath, "", "mycorp", keyPath, true)
+	require.NoError(t, err)
+	encrypted, err := os.ReadFile(manifestPath + ".enc")
+	require.NoError(t, err)
+	assert.True(t, services_manifest.IsEncrypted(encrypted))
+	manifest, err = services_manifest.Load(context.Background(), services_manifest.Source{Org: "mycorp", Location: manifestPath + ".enc", PublicKey: publicKey})
+	require.NoError(t, err)
+	assert.Equal(t, "8", manifest.Settings["aws.max_attempts"])
+
+	// A manifest for another org is never signed
+	_, err = sealManifest(manifestPath, "", "othercorp", keyPath, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `the manifest is for org "mycorp"`)
+}
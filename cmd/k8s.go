@@ -16,9 +16,11 @@ var (
 	kubernetesCmd = &cobra.Command{
 		Use:     "kubernetes",
 		Aliases: []string{"k8s", "eks"},
-		Short:   "Kubernetes cluster operations",
-		Long:    `Kubernetes cluster operations - List and switch between cluster contexts`,
-		Run:     kubernetes,
+		Short:   "Pick a Kubernetes context and switch to it",
+		Long: `Pick a context of the kubeconfig in an interactive selector and make it current, assuming the AWS role
+of the context first when ark wrote it. The subcommands discover the EKS clusters of every account, keep the
+kubeconfig contexts up to date and manage cluster access and tokens.`,
+		Run: kubernetes,
 	}
 )
 
@@ -50,6 +52,11 @@ func kubernetes(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	switchToCluster(ctx, selectedCluster)
+}
+
+// switchToCluster assumes the AWS role of a context, when it has one, and makes it the current context
+func switchToCluster(ctx context.Context, selectedCluster *services_kubernetes.ClusterContext) {
 	// Show selected cluster information
 	fmt.Printf("\n✅ Selected cluster: %s", selectedCluster.Name)
 	if selectedCluster.Current {
@@ -113,6 +120,11 @@ func assumeRoleForCluster(ctx context.Context, cluster *services_kubernetes.Clus
 		return fmt.Errorf("error resolving SSO configuration for profile %s: %w", cluster.Profile, err)
 	}
 
+	// The cluster's profile becomes the default one
+	if !guardDefaultCredentials() {
+		return fmt.Errorf("default credentials were not modified")
+	}
+
 	// Perform login with the profile using retry
 	if err := controllers.AttemptLoginWithRetry(ctx, cluster.Profile, true, ssoRegion, ssoStartURL); err != nil {
 		return fmt.Errorf("failed to login with profile %s: %w", cluster.Profile, err)
@@ -0,0 +1,313 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	controllers_k8s "github.com/andresgarcia29/ark-cli/controllers/kubernetes"
+	"github.com/andresgarcia29/ark-cli/lib/animation"
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	services_kubernetes "github.com/andresgarcia29/ark-cli/services/kubernetes"
+	"github.com/spf13/cobra"
+)
+
+var (
+	kubernetesAccessCmd = &cobra.Command{
+		Use:   "access",
+		Short: "Manage cluster access for IAM principals with EKS access entries",
+		Long: `List, grant and revoke the access of IAM principals to an EKS cluster through the EKS access entries API,
+instead of editing the aws-auth ConfigMap. Policies can be given as cluster-admin, admin, edit, view,
+an AmazonEKS* policy name or a policy ARN.`,
+	}
+
+	kubernetesAccessListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List the access entries of a cluster with their policies",
+		Long:  `List the IAM principals with an access entry on the cluster, with their Kubernetes groups and the access policies associated with them, cluster-wide or per namespace.`,
+		Run:   kubernetesAccessList,
+	}
+
+	kubernetesAccessGrantCmd = &cobra.Command{
+		Use:   "grant",
+		Short: "Grant an IAM principal an access policy on a cluster",
+		Long:  `Create the access entry of an IAM principal when it has none, then associate an access policy, cluster-wide or for some namespaces.`,
+		Run:   kubernetesAccessGrant,
+	}
+
+	kubernetesAccessRevokeCmd = &cobra.Command{
+		Use:   "revoke",
+		Short: "Revoke an access policy, or all access, from an IAM principal",
+		Long:  `Remove an access policy from an IAM principal, or its whole access entry when no policy is given.`,
+		Run:   kubernetesAccessRevoke,
+	}
+
+	kubernetesAccessLegacyCmd = &cobra.Command{
+		Use:   "legacy",
+		Short: "Show the aws-auth ConfigMap mappings of a cluster, flagging IAM principals that no longer exist",
+		Long: `Read the aws-auth ConfigMap of clusters that don't use access entries yet and list its role, user and account mappings.
+Roles and users of the cluster's account are looked up in IAM and flagged when they no longer exist.
+Nothing is changed in the cluster.`,
+		Run: kubernetesAccessLegacy,
+	}
+)
+
+func init() {
+	kubernetesCmd.AddCommand(kubernetesAccessCmd)
+	kubernetesAccessCmd.AddCommand(kubernetesAccessListCmd, kubernetesAccessGrantCmd, kubernetesAccessRevokeCmd, kubernetesAccessLegacyCmd)
+
+	kubernetesAccessCmd.PersistentFlags().String("cluster-name", "", "EKS cluster name (required)")
+	kubernetesAccessCmd.PersistentFlags().String("region", "", "AWS region of the cluster (required)")
+	kubernetesAccessCmd.PersistentFlags().String("profile", "", "AWS profile allowed to manage the cluster's access entries")
+	if err := kubernetesAccessCmd.MarkPersistentFlagRequired("cluster-name"); err != nil {
+		panic(err)
+	}
+	if err := kubernetesAccessCmd.MarkPersistentFlagRequired("region"); err != nil {
+		panic(err)
+	}
+	addTableFlags(kubernetesAccessListCmd, "principal")
+	addTableFlags(kubernetesAccessLegacyCmd, "")
+
+	kubernetesAccessGrantCmd.Flags().String("principal", "", "IAM role or user ARN to grant access to (required)")
+	kubernetesAccessGrantCmd.Flags().String("policy", "", "Access policy: cluster-admin, admin, edit, view, an AmazonEKS* policy name or ARN (required)")
+	kubernetesAccessGrantCmd.Flags().StringSlice("namespaces", nil, "Limit the policy to these namespaces (default: cluster-wide)")
+	kubernetesAccessGrantCmd.Flags().StringSlice("groups", nil, "Kubernetes groups of the access entry, when it is created")
+	kubernetesAccessRevokeCmd.Flags().String("principal", "", "IAM role or user ARN to revoke access from (required)")
+	kubernetesAccessRevokeCmd.Flags().String("policy", "", "Access policy to remove (default: delete the whole access entry)")
+	for _, cmd := range []*cobra.Command{kubernetesAccessGrantCmd, kubernetesAccessRevokeCmd} {
+		if err := cmd.MarkFlagRequired("principal"); err != nil {
+			panic(err)
+		}
+	}
+	if err := kubernetesAccessGrantCmd.MarkFlagRequired("policy"); err != nil {
+		panic(err)
+	}
+}
+
+// accessManager creates the EKS client for the cluster flags of the access commands
+func accessManager(ctx context.Context, cmd *cobra.Command) (services_aws.AccessManager, string, error) {
+	clusterName, _ := cmd.Flags().GetString("cluster-name")
+	region, _ := cmd.Flags().GetString("region")
+	profile, _ := cmd.Flags().GetString("profile")
+
+	manager, err := services_aws.NewAccessManager(ctx, region, profile)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create EKS client: %w", err)
+	}
+	return manager, clusterName, nil
+}
+
+func kubernetesAccessList(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	manager, clusterName, err := accessManager(ctx, cmd)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	entries, err := services_aws.ListClusterAccess(ctx, manager, clusterName)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	if len(entries) == 0 {
+		fmt.Printf("No access entries in %s\n", clusterName)
+		return
+	}
+
+	output, err := renderTable(cmd, buildAccessTable(entries))
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	fmt.Print(output)
+}
+
+func kubernetesAccessGrant(cmd *cobra.Command, args []string) {
+	principal, _ := cmd.Flags().GetString("principal")
+	policy, _ := cmd.Flags().GetString("policy")
+	namespaces, _ := cmd.Flags().GetStringSlice("namespaces")
+	groups, _ := cmd.Flags().GetStringSlice("groups")
+
+	ctx := context.Background()
+	manager, clusterName, err := accessManager(ctx, cmd)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	granted, err := grantAccess(ctx, manager, clusterName, services_aws.AccessGrant{
+		PrincipalARN:     principal,
+		Policy:           policy,
+		Namespaces:       namespaces,
+		KubernetesGroups: groups,
+	})
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	if granted {
+		fmt.Printf("✓ Granted %s on %s to %s\n", policy, clusterName, principal)
+	}
+}
+
+func kubernetesAccessRevoke(cmd *cobra.Command, args []string) {
+	principal, _ := cmd.Flags().GetString("principal")
+	policy, _ := cmd.Flags().GetString("policy")
+
+	ctx := context.Background()
+	manager, clusterName, err := accessManager(ctx, cmd)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	revoked, err := revokeAccess(ctx, manager, clusterName, principal, policy)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	if revoked {
+		fmt.Printf("✓ Revoked access of %s on %s\n", principal, clusterName)
+	}
+}
+
+func kubernetesAccessLegacy(cmd *cobra.Command, args []string) {
+	clusterName, _ := cmd.Flags().GetString("cluster-name")
+	region, _ := cmd.Flags().GetString("region")
+	profile, _ := cmd.Flags().GetString("profile")
+
+	var checks []controllers_k8s.AWSAuthCheck
+	err := animation.ShowStatus(context.Background(), "Reading the aws-auth ConfigMap of "+clusterName, func(ctx context.Context, status func(string)) error {
+		cluster := services_aws.EKSCluster{Name: clusterName, Region: region, Profile: profile}
+		mappings, err := controllers_k8s.ReadAWSAuth(ctx, services_aws.NewClusterLister, &cluster)
+		if err != nil {
+			return err
+		}
+
+		status(fmt.Sprintf("Looking up %d mapping(s) in IAM", len(mappings)))
+		checker, err := services_aws.NewPrincipalChecker(ctx, profile)
+		if err != nil {
+			return fmt.Errorf("failed to create IAM client: %w", err)
+		}
+		checks = controllers_k8s.CheckAWSAuthMappings(ctx, checker, controllers_k8s.ClusterAccountID(cluster.ARN), mappings)
+		return nil
+	})
+	if errors.Is(err, services_kubernetes.ErrNoAWSAuth) {
+		fmt.Printf("%s has no aws-auth ConfigMap, use `ark kubernetes access list` for its access entries\n", clusterName)
+		return
+	}
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	if len(checks) == 0 {
+		fmt.Printf("The aws-auth ConfigMap of %s has no mappings\n", clusterName)
+		return
+	}
+
+	output, err := renderTable(cmd, buildAWSAuthTable(checks))
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	fmt.Print(output)
+
+	missing := 0
+	for _, check := range checks {
+		if check.Status == controllers_k8s.AWSAuthPrincipalMissing {
+			missing++
+		}
+	}
+	if missing > 0 {
+		fmt.Printf("\n⚠ %d mapping(s) point to IAM principals that no longer exist\n", missing)
+	}
+}
+
+// grantAccess confirms and applies a grant, returning false when the user declines
+func grantAccess(ctx context.Context, manager services_aws.AccessManager, clusterName string, grant services_aws.AccessGrant) (bool, error) {
+	policyARN, err := services_aws.AccessPolicyARN(grant.Policy)
+	if err != nil {
+		return false, err
+	}
+
+	scope := services_aws.EKSAccessPolicy{PolicyARN: policyARN, Namespaces: grant.Namespaces}.Scope()
+	confirmed, err := confirmAction(
+		fmt.Sprintf("Grant %s on %s to %s?", grant.Policy, clusterName, grant.PrincipalARN),
+		animation.ConfirmOptions{Details: []string{"Policy: " + policyARN, "Scope: " + scope}},
+	)
+	if err != nil {
+		return false, err
+	}
+	if !confirmed {
+		fmt.Println("Aborted: access was not changed")
+		return false, nil
+	}
+
+	return true, services_aws.GrantClusterAccess(ctx, manager, clusterName, grant)
+}
+
+// revokeAccess confirms and removes a policy, or the whole access entry when policy is empty
+// It returns false when the user declines
+func revokeAccess(ctx context.Context, manager services_aws.AccessManager, clusterName, principalARN, policy string) (bool, error) {
+	question := fmt.Sprintf("Delete the access entry of %s on %s, with all its policies?", principalARN, clusterName)
+	if policy != "" {
+		policyARN, err := services_aws.AccessPolicyARN(policy)
+		if err != nil {
+			return false, err
+		}
+		question = fmt.Sprintf("Remove %s from %s on %s?", policyARN, principalARN, clusterName)
+	}
+
+	confirmed, err := confirmAction(question, animation.ConfirmOptions{Destructive: true})
+	if err != nil {
+		return false, err
+	}
+	if !confirmed {
+		fmt.Println("Aborted: access was not changed")
+		return false, nil
+	}
+
+	return true, services_aws.RevokeClusterAccess(ctx, manager, clusterName, principalARN, policy)
+}
+
+// buildAccessTable lays out access entries with one row per associated policy
+func buildAccessTable(entries []services_aws.EKSAccessEntry) *animation.Table {
+	table := animation.NewTable("Principal", "Type", "Username", "Groups", "Policy", "Scope")
+	table.Columns[0].MaxWidth = 70
+	for _, entry := range entries {
+		groups := strings.Join(entry.KubernetesGroups, ",")
+		if len(entry.Policies) == 0 {
+			table.AddRow(entry.PrincipalARN, entry.Type, entry.Username, groups, "", "")
+			continue
+		}
+		for _, policy := range entry.Policies {
+			table.AddRow(entry.PrincipalARN, entry.Type, entry.Username, groups,
+				services_aws.AccessPolicyName(policy.PolicyARN), policy.Scope())
+		}
+	}
+	return table
+}
+
+// buildAWSAuthTable lays out aws-auth mappings with the IAM status of their principals
+func buildAWSAuthTable(checks []controllers_k8s.AWSAuthCheck) *animation.Table {
+	table := animation.NewTable("Kind", "ARN", "Username", "Groups", "IAM")
+	table.Columns[1].MaxWidth = 70
+	table.Columns[4].MaxWidth = 60
+	for _, check := range checks {
+		iam := ""
+		switch check.Status {
+		case controllers_k8s.AWSAuthPrincipalExists:
+			iam = "✓ exists"
+		case controllers_k8s.AWSAuthPrincipalMissing:
+			iam = "✗ missing"
+		case controllers_k8s.AWSAuthOtherAccount:
+			iam = "– other account"
+		case controllers_k8s.AWSAuthCheckFailed:
+			iam = "? " + check.Err.Error()
+		}
+		table.AddRow(check.Mapping.Kind, check.Mapping.ARN, check.Mapping.Username, strings.Join(check.Mapping.Groups, ","), iam)
+	}
+	return table
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	controllers_k8s "github.com/andresgarcia29/ark-cli/controllers/kubernetes"
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	services_kubernetes "github.com/andresgarcia29/ark-cli/services/kubernetes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingAccessManager records the access changes made by the commands
+type recordingAccessManager struct {
+	services_aws.AccessManager
+	calls []string
+}
+
+func (r *recordingAccessManager) CreateAccessEntry(ctx context.Context, clusterName, principalARN string, kubernetesGroups []string) error {
+	r.calls = append(r.calls, "create")
+	return nil
+}
+
+func (r *recordingAccessManager) AssociateAccessPolicy(ctx context.Context, clusterName, principalARN string, policy services_aws.EKSAccessPolicy) error {
+	r.calls = append(r.calls, "associate "+policy.Scope())
+	return nil
+}
+
+func (r *recordingAccessManager) DeleteAccessEntry(ctx context.Context, clusterName, principalARN string) error {
+	r.calls = append(r.calls, "delete")
+	return nil
+}
+
+func TestGrantAndRevokeAccess(t *testing.T) {
+	original := AssumeYes
+	defer func() { AssumeYes = original }()
+	AssumeYes = true
+
+	manager := &recordingAccessManager{}
+	granted, err := grantAccess(context.Background(), manager, "prod", services_aws.AccessGrant{
+		PrincipalARN: "arn:aws:iam::111111111111:role/Dev",
+		Policy:       "edit",
+		Namespaces:   []string{"team-a"},
+	})
+	require.NoError(t, err)
+	assert.True(t, granted)
+
+	revoked, err := revokeAccess(context.Background(), manager, "prod", "arn:aws:iam::111111111111:role/Dev", "")
+	require.NoError(t, err)
+	assert.True(t, revoked)
+	assert.Equal(t, []string{"create", "associate namespaces: team-a", "delete"}, manager.calls)
+
+	// Unknown policies are refused before anything is asked or changed
+	_, err = grantAccess(context.Background(), manager, "prod", services_aws.AccessGrant{PrincipalARN: "arn:aws:iam::111111111111:role/Dev", Policy: "root"})
+	assert.ErrorContains(t, err, "unknown access policy")
+	assert.Len(t, manager.calls, 3)
+}
+
+func TestBuildAccessTable(t *testing.T) {
+	table := buildAccessTable([]services_aws.EKSAccessEntry{
+		{
+			PrincipalARN:     "arn:aws:iam::111111111111:role/Platform",
+			Type:             "STANDARD",
+			Username:         "platform",
+			KubernetesGroups: []string{"ops"},
+			Policies: []services_aws.EKSAccessPolicy{
+				{PolicyARN: "arn:aws:eks::aws:cluster-access-policy/AmazonEKSClusterAdminPolicy"},
+				{PolicyARN: "arn:aws:eks::aws:cluster-access-policy/AmazonEKSEditPolicy", Namespaces: []string{"a", "b"}},
+			},
+		},
+		{PrincipalARN: "arn:aws:iam::111111111111:role/Nodes", Type: "EC2_LINUX"},
+	})
+	assert.Equal(t, [][]string{
+		{"arn:aws:iam::111111111111:role/Platform", "STANDARD", "platform", "ops", "AmazonEKSClusterAdminPolicy", "cluster"},
+		{"arn:aws:iam::111111111111:role/Platform", "STANDARD", "platform", "ops", "AmazonEKSEditPolicy", "namespaces: a,b"},
+		{"arn:aws:iam::111111111111:role/Nodes", "EC2_LINUX", "", "", "", ""},
+	}, table.Rows)
+}
+
+func TestBuildAWSAuthTable(t *testing.T) {
+	table := buildAWSAuthTable([]controllers_k8s.AWSAuthCheck{
+		{Mapping: services_kubernetes.AWSAuthMapping{Kind: "role", ARN: "arn:aws:iam::111111111111:role/Nodes", Username: "system:node:{{EC2PrivateDNSName}}", Groups: []string{"system:bootstrappers", "system:nodes"}}, Status: controllers_k8s.AWSAuthPrincipalExists},
+		{Mapping: services_kubernetes.AWSAuthMapping{Kind: "user", ARN: "arn:aws:iam::111111111111:user/bob", Username: "bob"}, Status: controllers_k8s.AWSAuthPrincipalMissing},
+		{Mapping: services_kubernetes.AWSAuthMapping{Kind: "account", ARN: "222222222222"}, Status: controllers_k8s.AWSAuthNotChecked},
+	})
+	assert.Equal(t, [][]string{
+		{"role", "arn:aws:iam::111111111111:role/Nodes", "system:node:{{EC2PrivateDNSName}}", "system:bootstrappers,system:nodes", "✓ exists"},
+		{"user", "arn:aws:iam::111111111111:user/bob", "bob", "", "✗ missing"},
+		{"account", "222222222222", "", "", ""},
+	}, table.Rows)
+}
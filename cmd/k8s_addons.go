@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	controllers_k8s "github.com/andresgarcia29/ark-cli/controllers/kubernetes"
+	"github.com/andresgarcia29/ark-cli/lib/animation"
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	"github.com/spf13/cobra"
+)
+
+var (
+	kubernetesAddonsCmd = &cobra.Command{
+		Use:   "addons",
+		Short: "List the EKS add-ons of every cluster with their latest versions",
+		Long: `Discover the EKS clusters of every account, like setup does, and list their installed add-ons
+(vpc-cni, coredns, kube-proxy, CSI drivers...) with the installed version and the latest one
+available for the cluster's Kubernetes version. Clusters are read in parallel.`,
+		Run: kubernetesAddons,
+	}
+)
+
+func init() {
+	kubernetesCmd.AddCommand(kubernetesAddonsCmd)
+	kubernetesAddonsCmd.Flags().StringSlice("regions", []string{"us-west-2"}, "List of AWS regions to scan")
+	kubernetesAddonsCmd.Flags().StringSlice("role-prefixs", []string{"readonly", "read-only"}, "Role prefixs to scan")
+	kubernetesAddonsCmd.Flags().String("role-arn", "", "Specific Role ARN to use for authentication (mutually exclusive with role-prefixs)")
+	kubernetesAddonsCmd.Flags().String("discovery", "", "How clusters are found: list-clusters (every account and region) or resource-explorer (one search of the Resource Explorer aggregator index) (default: kubernetes.discovery in the ark config)")
+	kubernetesAddonsCmd.Flags().Bool("outdated", false, "Only list add-ons with a newer version available")
+	addTableFlags(kubernetesAddonsCmd, "cluster")
+}
+
+func kubernetesAddons(cmd *cobra.Command, args []string) {
+	regions, _ := cmd.Flags().GetStringSlice("regions")
+	outdated, _ := cmd.Flags().GetBool("outdated")
+	rolePrefixs, roleARN, err := discoveryRoles(cmd)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	discovery, err := discoveryBackend(cmd)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	if !guardLargeScan(discovery, regions, rolePrefixs, roleARN) {
+		return
+	}
+
+	var inventories []controllers_k8s.ClusterAddons
+	err = animation.ShowStatus(context.Background(), "Fetching EKS clusters from all accounts", func(ctx context.Context, status func(string)) error {
+		clusters, err := services_aws.DiscoverClusters(ctx, discovery, regions, rolePrefixs, roleARN)
+		if err != nil {
+			return fmt.Errorf("failed to get clusters: %w", err)
+		}
+		status(fmt.Sprintf("Reading the add-ons of %d cluster(s)", len(clusters)))
+		inventories = controllers_k8s.CollectClusterAddons(ctx, services_aws.NewAddonLister, clusters)
+		return nil
+	})
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	if len(inventories) == 0 {
+		fmt.Println("No EKS clusters found in any account")
+		return
+	}
+
+	output, err := renderTable(cmd, buildAddonsTable(inventories, outdated))
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	fmt.Print(output)
+
+	updates, failed := 0, 0
+	for _, inventory := range inventories {
+		if inventory.Err != nil {
+			failed++
+			fmt.Printf("✗ %s (%s, %s): %v\n", inventory.Cluster.Name, inventory.Cluster.AccountID, inventory.Cluster.Region, inventory.Err)
+			continue
+		}
+		for _, addon := range inventory.Addons {
+			if addon.UpdateAvailable() {
+				updates++
+			}
+		}
+	}
+	fmt.Printf("\n%d add-on update(s) available across %d cluster(s)", updates, len(inventories)-failed)
+	if failed > 0 {
+		fmt.Printf(", %d cluster(s) could not be read", failed)
+	}
+	fmt.Println()
+}
+
+// buildAddonsTable lays out the add-ons of each cluster, flagging those with a newer version available
+// With outdated, only those are listed
+func buildAddonsTable(inventories []controllers_k8s.ClusterAddons, outdated bool) *animation.Table {
+	table := animation.NewTable("Account", "Region", "Cluster", "Add-on", "Version", "Latest", "Status", "Update")
+	for _, inventory := range inventories {
+		for _, addon := range inventory.Addons {
+			update := ""
+			if addon.UpdateAvailable() {
+				update = "⬆ available"
+			} else if outdated {
+				continue
+			}
+			table.AddRow(
+				inventory.Cluster.AccountID,
+				inventory.Cluster.Region,
+				inventory.Cluster.Name,
+				addon.Name,
+				addon.Version,
+				addon.LatestVersion,
+				addon.Status,
+				update,
+			)
+		}
+	}
+	return table
+}
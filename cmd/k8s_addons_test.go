@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	controllers_k8s "github.com/andresgarcia29/ark-cli/controllers/kubernetes"
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildAddonsTable(t *testing.T) {
+	inventories := []controllers_k8s.ClusterAddons{
+		{
+			Cluster: services_aws.EKSCluster{Name: "api", Region: "us-west-2", AccountID: "111111111111"},
+			Addons: []services_aws.EKSAddon{
+				{Name: "vpc-cni", Version: "v1.18.1-eksbuild.1", LatestVersion: "v1.18.3-eksbuild.2", Status: "ACTIVE"},
+				{Name: "coredns", Version: "v1.11.1-eksbuild.9", LatestVersion: "v1.11.1-eksbuild.9", Status: "ACTIVE"},
+			},
+		},
+		{Cluster: services_aws.EKSCluster{Name: "locked"}, Err: errors.New("access denied")},
+	}
+
+	table := buildAddonsTable(inventories, false)
+	assert.Equal(t, [][]string{
+		{"111111111111", "us-west-2", "api", "vpc-cni", "v1.18.1-eksbuild.1", "v1.18.3-eksbuild.2", "ACTIVE", "⬆ available"},
+		{"111111111111", "us-west-2", "api", "coredns", "v1.11.1-eksbuild.9", "v1.11.1-eksbuild.9", "ACTIVE", ""},
+	}, table.Rows)
+
+	table = buildAddonsTable(inventories, true)
+	assert.Len(t, table.Rows, 1)
+	assert.Equal(t, "vpc-cni", table.Rows[0][3])
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/andresgarcia29/ark-cli/lib/animation"
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	services_kubernetes "github.com/andresgarcia29/ark-cli/services/kubernetes"
+	"github.com/spf13/cobra"
+)
+
+var (
+	kubernetesAdoptCmd = &cobra.Command{
+		Use:   "adopt",
+		Short: "Mark EKS contexts written by the AWS CLI as managed by ark",
+		Long: `Find the contexts of EKS clusters that ark didn't write, e.g. with aws eks update-kubeconfig, and mark
+them with the ark extension so rename, the setup summary and uninstall-data handle them like the contexts
+written by ark kubernetes setup. The account of contexts whose cluster entry isn't an EKS ARN is looked up
+in the cluster cache of the last setup; contexts it can't be found for are listed and left alone.`,
+		Args: cobra.NoArgs,
+		Run:  kubernetesAdopt,
+	}
+)
+
+func init() {
+	kubernetesCmd.AddCommand(kubernetesAdoptCmd)
+	kubernetesAdoptCmd.Flags().String("kubeconfig-path", "", "Kubeconfig to update (default: the file ark setup writes to)")
+	kubernetesAdoptCmd.Flags().Bool("dry-run", false, "List the contexts that would be adopted without marking them")
+}
+
+func kubernetesAdopt(cmd *cobra.Command, args []string) {
+	kubeconfigPath, _ := cmd.Flags().GetString("kubeconfig-path")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	kubeconfigPath, err := services_kubernetes.ResolveKubeconfigPath(kubeconfigPath)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	// Without a cluster cache, only the contexts named after an EKS ARN can be adopted
+	var clusters []services_aws.EKSCluster
+	if cache, err := services_aws.LoadClusterCache(); err == nil {
+		clusters = cache.Clusters
+	}
+
+	adopted, err := adoptContexts(kubeconfigPath, clusters, time.Now(), dryRun)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	if adopted > 0 && !dryRun {
+		fmt.Printf("✓ Adopted %d context(s) in %s\n", adopted, kubeconfigPath)
+	}
+}
+
+// planAdoptions completes the account of each candidate from the cluster cache, splitting the candidates
+// into the ones that can be adopted and the ones whose account is unknown
+func planAdoptions(candidates []services_kubernetes.AdoptionCandidate, clusters []services_aws.EKSCluster) (adoptable, unmatched []services_kubernetes.AdoptionCandidate) {
+	for _, candidate := range candidates {
+		if candidate.Metadata.AccountID == "" {
+			cluster, ok := findCachedCluster(clusters, candidate.Metadata.Cluster, candidate.Metadata.Region, "")
+			if !ok || cluster.AccountID == "" {
+				unmatched = append(unmatched, candidate)
+				continue
+			}
+			candidate.Metadata.AccountID = cluster.AccountID
+		}
+		adoptable = append(adoptable, candidate)
+	}
+	return adoptable, unmatched
+}
+
+// adoptContexts marks the adoptable contexts of a kubeconfig as managed by ark after confirmation, returning
+// how many were marked
+func adoptContexts(kubeconfigPath string, clusters []services_aws.EKSCluster, now time.Time, dryRun bool) (int, error) {
+	kubeconfig, err := services_kubernetes.LoadKubeconfig(kubeconfigPath)
+	if err != nil {
+		return 0, err
+	}
+
+	adoptable, unmatched := planAdoptions(kubeconfig.AdoptionCandidates(), clusters)
+	if len(unmatched) > 0 {
+		fmt.Printf("%d context(s) left alone, their cluster isn't in the cluster cache (run `ark kubernetes setup` first):\n", len(unmatched))
+		for _, candidate := range unmatched {
+			fmt.Printf("  - %s (%s in %s)\n", candidate.Context, candidate.Metadata.Cluster, candidate.Metadata.Region)
+		}
+	}
+	if len(adoptable) == 0 {
+		if len(unmatched) == 0 {
+			fmt.Println("No EKS contexts to adopt: every one is already managed by ark")
+		}
+		return 0, nil
+	}
+
+	details := make([]string, 0, len(adoptable))
+	for _, candidate := range adoptable {
+		details = append(details, fmt.Sprintf("%s → %s in %s (%s)",
+			candidate.Context, candidate.Metadata.Cluster, candidate.Metadata.Region, candidate.Metadata.AccountID))
+	}
+	if dryRun {
+		fmt.Printf("%d context(s) would be adopted:\n", len(adoptable))
+		for _, detail := range details {
+			fmt.Printf("  - %s\n", detail)
+		}
+		return len(adoptable), nil
+	}
+
+	if err := preflightWrites(func() (string, error) { return kubeconfigPath, nil }); err != nil {
+		return 0, err
+	}
+	confirmed, err := confirmAction(
+		fmt.Sprintf("Mark %d context(s) in %s as managed by ark?", len(adoptable), kubeconfigPath),
+		animation.ConfirmOptions{Details: details},
+	)
+	if err != nil {
+		return 0, err
+	}
+	if !confirmed {
+		fmt.Println("Aborted: kubeconfig was not modified")
+		return 0, nil
+	}
+
+	for _, candidate := range adoptable {
+		metadata := candidate.Metadata
+		metadata.DiscoveredAt = now
+		kubeconfig.MarkManaged(candidate.Context, metadata)
+	}
+	if err := services_kubernetes.SaveKubeconfig(kubeconfigPath, kubeconfig); err != nil {
+		return 0, err
+	}
+	return len(adoptable), nil
+}
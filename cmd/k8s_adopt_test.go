@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	services_kubernetes "github.com/andresgarcia29/ark-cli/services/kubernetes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanAdoptions(t *testing.T) {
+	clusters := []services_aws.EKSCluster{
+		{Name: "data", Region: "us-west-2", AccountID: "333333333333"},
+		{Name: "shared", Region: "us-east-1", AccountID: "111111111111"},
+		{Name: "shared", Region: "us-east-1", AccountID: "222222222222"},
+	}
+	candidates := []services_kubernetes.AdoptionCandidate{
+		{Context: "dev", Metadata: services_kubernetes.ArkMetadata{AccountID: "444444444444", Region: "eu-west-1", Cluster: "dev"}},
+		{Context: "data", Metadata: services_kubernetes.ArkMetadata{Region: "us-west-2", Cluster: "data"}},
+		{Context: "shared", Metadata: services_kubernetes.ArkMetadata{Region: "us-east-1", Cluster: "shared"}},
+		{Context: "gone", Metadata: services_kubernetes.ArkMetadata{Region: "us-east-1", Cluster: "gone"}},
+	}
+
+	adoptable, unmatched := planAdoptions(candidates, clusters)
+	assert.Equal(t, []services_kubernetes.AdoptionCandidate{
+		{Context: "dev", Metadata: services_kubernetes.ArkMetadata{AccountID: "444444444444", Region: "eu-west-1", Cluster: "dev"}},
+		{Context: "data", Metadata: services_kubernetes.ArkMetadata{AccountID: "333333333333", Region: "us-west-2", Cluster: "data"}},
+	}, adoptable)
+	assert.Equal(t, []string{"shared", "gone"}, []string{unmatched[0].Context, unmatched[1].Context},
+		"clusters in several accounts or missing from the cache are left alone")
+}
+
+func TestAdoptContexts(t *testing.T) {
+	original := AssumeYes
+	defer func() { AssumeYes = original }()
+	AssumeYes = true
+
+	path := filepath.Join(t.TempDir(), "config")
+	arn := "arn:aws:eks:eu-west-1:222222222222:cluster/dev"
+	kubeconfig := services_kubernetes.NewKubeconfig()
+	kubeconfig.UpsertCluster(services_kubernetes.NamedCluster{Name: arn, Cluster: services_kubernetes.KubeCluster{Server: "https://dev.example.com"}})
+	kubeconfig.UpsertContext(services_kubernetes.NamedContext{Name: arn, Context: services_kubernetes.KubeContext{Cluster: arn, User: arn}})
+	require.NoError(t, services_kubernetes.SaveKubeconfig(path, kubeconfig))
+
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	adopted, err := adoptContexts(path, nil, now, true)
+	require.NoError(t, err)
+	assert.Equal(t, 1, adopted)
+	unchanged, err := services_kubernetes.LoadKubeconfig(path)
+	require.NoError(t, err)
+	assert.Len(t, unchanged.AdoptionCandidates(), 1, "dry run leaves the file alone")
+
+	adopted, err = adoptContexts(path, nil, now, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, adopted)
+	updated, err := services_kubernetes.LoadKubeconfig(path)
+	require.NoError(t, err)
+	metadata, ok := updated.FindContext(arn).Context.Extensions.Ark()
+	require.True(t, ok)
+	assert.Equal(t, services_kubernetes.ArkMetadata{AccountID: "222222222222", Region: "eu-west-1", Cluster: "dev", DiscoveredAt: now}, metadata)
+	_, ok = updated.FindCluster(arn).Cluster.Extensions.Ark()
+	assert.True(t, ok, "the cluster entry is marked too")
+	assert.Empty(t, updated.AdoptionCandidates())
+
+	for _, name := range []string{"kubeconfig-path", "dry-run"} {
+		assert.NotNil(t, kubernetesAdoptCmd.Flags().Lookup(name), name)
+	}
+}
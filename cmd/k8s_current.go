@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	services_kubernetes "github.com/andresgarcia29/ark-cli/services/kubernetes"
+	"github.com/spf13/cobra"
+)
+
+var (
+	kubernetesCurrentCmd = &cobra.Command{
+		Use:   "current",
+		Short: "Show the current context with its cluster, profile and credentials",
+		Long: `Show the current kube context, the ARN of the EKS cluster behind it, the AWS profile and region its
+user authenticates with, and whether that profile's credentials are still valid. Everything comes from the
+kubeconfig and ark's caches (credentials file, SSO token cache, cluster cache of the last setup), so nothing
+is asked to AWS or the cluster.`,
+		Args: cobra.NoArgs,
+		Run:  kubernetesCurrent,
+	}
+)
+
+func init() {
+	kubernetesCmd.AddCommand(kubernetesCurrentCmd)
+	kubernetesCurrentCmd.Flags().String("kubeconfig-path", "", "Path to kubeconfig (default: the files of KUBECONFIG merged like kubectl, or ~/.kube/config)")
+}
+
+// currentContextInfo gathers what ark knows locally about the current context
+type currentContextInfo struct {
+	Context services_kubernetes.ClusterContext
+	// Credentials is nil when the profile is unknown to the AWS files or the context has no profile
+	Credentials *services_aws.CredentialStatus
+	// SessionExpiry is when the cached SSO token of an SSO profile expires, zero otherwise
+	SessionExpiry time.Time
+}
+
+func kubernetesCurrent(cmd *cobra.Command, args []string) {
+	kubeconfigPath, _ := cmd.Flags().GetString("kubeconfig-path")
+
+	var kubeconfig *services_kubernetes.Kubeconfig
+	var err error
+	if kubeconfigPath == "" {
+		kubeconfig, err = services_kubernetes.LoadMergedKubeconfig()
+	} else {
+		kubeconfig, err = services_kubernetes.LoadKubeconfig(kubeconfigPath)
+	}
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	var info *currentContextInfo
+	for _, clusterContext := range kubeconfig.ClusterContexts() {
+		if clusterContext.Current {
+			info = &currentContextInfo{Context: clusterContext}
+			break
+		}
+	}
+	if info == nil {
+		fmt.Println("No current context (use `ark ctx` to pick one)")
+		return
+	}
+
+	// Contexts whose cluster entry isn't an EKS ARN (written by hand or other tools) only have the cluster name
+	if info.Context.ClusterARN == "" && info.Context.ClusterName != "" {
+		if cache, err := services_aws.LoadClusterCache(); err == nil {
+			fillFromClusterCache(&info.Context, cache.Clusters)
+		}
+	}
+
+	if info.Context.Profile != "" {
+		info.Credentials, err = services_aws.ReadCredentialStatus(info.Context.Profile)
+		if err != nil {
+			fmt.Println("Error:", err)
+			return
+		}
+		// A profile missing from the AWS files has no SSO session to look for either
+		if info.Credentials != nil {
+			if profile, err := services_aws.ReadProfileFromConfig(info.Context.Profile); err == nil && profile.StartURL != "" {
+				info.SessionExpiry, _ = services_aws.TokenExpiry(profile.StartURL, profile.SSORegion)
+			}
+		}
+	}
+
+	fmt.Print(formatCurrentContext(info, time.Now()))
+}
+
+// fillFromClusterCache completes the ARN and account of a context from the cluster cached under its name and region
+func fillFromClusterCache(clusterContext *services_kubernetes.ClusterContext, clusters []services_aws.EKSCluster) {
+	cluster, ok := findCachedCluster(clusters, clusterContext.ClusterName, clusterContext.Region, clusterContext.AccountID)
+	if !ok || cluster.ARN == "" {
+		return
+	}
+	clusterContext.ClusterARN = cluster.ARN
+	clusterContext.AccountID = cluster.AccountID
+}
+
+// findCachedCluster returns the cached cluster with a name and region, in accountID when it is known
+// Clusters of the same name and region in several accounts can't be told apart without the account
+func findCachedCluster(clusters []services_aws.EKSCluster, name, region, accountID string) (services_aws.EKSCluster, bool) {
+	var found []services_aws.EKSCluster
+	for _, cluster := range clusters {
+		if cluster.Name != name || cluster.Region != region {
+			continue
+		}
+		if accountID != "" && cluster.AccountID != accountID {
+			continue
+		}
+		found = append(found, cluster)
+	}
+	if len(found) != 1 {
+		return services_aws.EKSCluster{}, false
+	}
+	return found[0], true
+}
+
+// formatCurrentContext renders the current context for people, one line per item
+func formatCurrentContext(info *currentContextInfo, now time.Time) string {
+	orUnknown := func(value string) string {
+		if value == "" {
+			return "unknown"
+		}
+		return value
+	}
+
+	var s strings.Builder
+	fmt.Fprintf(&s, "Context:      %s\n", info.Context.Name)
+	fmt.Fprintf(&s, "Cluster ARN:  %s\n", orUnknown(info.Context.ClusterARN))
+	fmt.Fprintf(&s, "Profile:      %s\n", orUnknown(info.Context.Profile))
+	fmt.Fprintf(&s, "Region:       %s\n", orUnknown(info.Context.Region))
+	fmt.Fprintf(&s, "Credentials:  %s\n", describeCredentialValidity(info, now))
+	return s.String()
+}
+
+// describeCredentialValidity tells whether the context's profile can authenticate right now, from the
+// expiry of its credentials or, for SSO profiles without any, of the SSO session they are minted from
+func describeCredentialValidity(info *currentContextInfo, now time.Time) string {
+	switch {
+	case info.Context.Profile == "":
+		return "unknown (the context's user has no AWS profile)"
+	case info.Credentials == nil:
+		return fmt.Sprintf("missing (profile %s is not in the AWS config)", info.Context.Profile)
+	case !info.Credentials.Expiration.IsZero():
+		return describeExpiry(info.Credentials.Expiration, now)
+	case !info.SessionExpiry.IsZero():
+		return "SSO session " + describeExpiry(info.SessionExpiry, now)
+	default:
+		return "no expiry recorded (static keys, or not logged in with ark)"
+	}
+}
+
+// describeExpiry renders an expiry as "valid for mm:ss (until 15:04)" or "expired at 15:04", which includes
+// the last aws.clock_skew before the expiration
+func describeExpiry(expiration, now time.Time) string {
+	if services_aws.ValidFor(expiration, now) <= 0 {
+		return fmt.Sprintf("expired at %s (run `ark aws login`)", expiration.Local().Format("15:04"))
+	}
+	return fmt.Sprintf("valid for %s (until %s)", formatRemaining(expiration.Sub(now)), expiration.Local().Format("15:04"))
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	services_kubernetes "github.com/andresgarcia29/ark-cli/services/kubernetes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatCurrentContext(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	until := now.Add(30 * time.Minute).Local().Format("15:04")
+	info := &currentContextInfo{
+		Context: services_kubernetes.ClusterContext{
+			Name:       "prod",
+			Profile:    "prod-readonly",
+			Region:     "us-east-1",
+			ClusterARN: "arn:aws:eks:us-east-1:111111111111:cluster/prod",
+		},
+		Credentials: &services_aws.CredentialStatus{Profile: "prod-readonly", Expiration: now.Add(30 * time.Minute)},
+	}
+
+	assert.Equal(t, "Context:      prod\n"+
+		"Cluster ARN:  arn:aws:eks:us-east-1:111111111111:cluster/prod\n"+
+		"Profile:      prod-readonly\n"+
+		"Region:       us-east-1\n"+
+		"Credentials:  valid for 30:00 (until "+until+")\n", formatCurrentContext(info, now))
+
+	assert.Equal(t, "Context:      kind\nCluster ARN:  unknown\nProfile:      unknown\nRegion:       unknown\n"+
+		"Credentials:  unknown (the context's user has no AWS profile)\n",
+		formatCurrentContext(&currentContextInfo{Context: services_kubernetes.ClusterContext{Name: "kind"}}, now))
+}
+
+func TestDescribeCredentialValidity(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	withProfile := func(credentials *services_aws.CredentialStatus, sessionExpiry time.Time) *currentContextInfo {
+		return &currentContextInfo{
+			Context:       services_kubernetes.ClusterContext{Name: "dev", Profile: "dev"},
+			Credentials:   credentials,
+			SessionExpiry: sessionExpiry,
+		}
+	}
+
+	assert.Equal(t, "missing (profile dev is not in the AWS config)", describeCredentialValidity(withProfile(nil, time.Time{}), now))
+	assert.Equal(t, "expired at "+now.Add(-time.Minute).Local().Format("15:04")+" (run `ark aws login`)",
+		describeCredentialValidity(withProfile(&services_aws.CredentialStatus{Expiration: now.Add(-time.Minute)}, time.Time{}), now))
+	assert.Contains(t, describeCredentialValidity(withProfile(&services_aws.CredentialStatus{}, now.Add(time.Hour)), now),
+		"SSO session valid for 60:00", "SSO profiles without credentials fall back to the SSO session")
+	assert.Equal(t, "no expiry recorded (static keys, or not logged in with ark)",
+		describeCredentialValidity(withProfile(&services_aws.CredentialStatus{}, time.Time{}), now))
+}
+
+func TestFillFromClusterCache(t *testing.T) {
+	clusters := []services_aws.EKSCluster{
+		{Name: "api", Region: "us-east-1", AccountID: "111111111111", ARN: "arn:aws:eks:us-east-1:111111111111:cluster/api"},
+		{Name: "api", Region: "eu-west-1", AccountID: "222222222222", ARN: "arn:aws:eks:eu-west-1:222222222222:cluster/api"},
+	}
+
+	clusterContext := services_kubernetes.ClusterContext{Name: "api", ClusterName: "api", Region: "eu-west-1"}
+	fillFromClusterCache(&clusterContext, clusters)
+	assert.Equal(t, "arn:aws:eks:eu-west-1:222222222222:cluster/api", clusterContext.ClusterARN)
+	assert.Equal(t, "222222222222", clusterContext.AccountID)
+
+	unknown := services_kubernetes.ClusterContext{Name: "api", ClusterName: "api", Region: "us-east-1", AccountID: "333333333333"}
+	fillFromClusterCache(&unknown, clusters)
+	assert.Empty(t, unknown.ClusterARN, "a cluster of another account is not matched")
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/andresgarcia29/ark-cli/lib/animation"
+	services_kubernetes "github.com/andresgarcia29/ark-cli/services/kubernetes"
+	"github.com/spf13/cobra"
+)
+
+var (
+	kubernetesListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List the contexts in kubeconfig",
+		Long:  `List every context in kubeconfig with the AWS profile, region and cluster it points to.`,
+		Run:   kubernetesList,
+	}
+)
+
+func init() {
+	kubernetesCmd.AddCommand(kubernetesListCmd)
+	kubernetesListCmd.Flags().String("kubeconfig-path", "", "Path to kubeconfig (default: the files of KUBECONFIG merged like kubectl, or ~/.kube/config)")
+	addTableFlags(kubernetesListCmd, "name")
+}
+
+func kubernetesList(cmd *cobra.Command, args []string) {
+	kubeconfigPath, _ := cmd.Flags().GetString("kubeconfig-path")
+
+	var kubeconfig *services_kubernetes.Kubeconfig
+	var err error
+	if kubeconfigPath == "" {
+		kubeconfig, err = services_kubernetes.LoadMergedKubeconfig()
+	} else {
+		kubeconfig, err = services_kubernetes.LoadKubeconfig(kubeconfigPath)
+	}
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	contexts := kubeconfig.ClusterContexts()
+	if len(contexts) == 0 {
+		fmt.Println("No contexts found in kubeconfig")
+		return
+	}
+
+	output, err := renderTable(cmd, buildContextsTable(contexts))
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	fmt.Print(output)
+}
+
+// buildContextsTable lays out kubeconfig contexts as a table, marking the current one
+func buildContextsTable(contexts []services_kubernetes.ClusterContext) *animation.Table {
+	table := animation.NewTable("Current", "Name", "Cluster", "Region", "Profile")
+	table.Columns[1].MaxWidth = 60
+	for _, clusterContext := range contexts {
+		current := ""
+		if clusterContext.Current {
+			current = "*"
+		}
+		table.AddRow(current, clusterContext.Name, clusterContext.ClusterName, clusterContext.Region, clusterContext.Profile)
+	}
+	return table
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"testing"
+
+	services_kubernetes "github.com/andresgarcia29/ark-cli/services/kubernetes"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildContextsTable(t *testing.T) {
+	table := buildContextsTable([]services_kubernetes.ClusterContext{
+		{Name: "prod", Current: true, ClusterName: "prod-cluster", Region: "us-east-1", Profile: "prod"},
+		{Name: "kind"},
+	})
+
+	require.Len(t, table.Rows, 2)
+	assert.Equal(t, []string{"*", "prod", "prod-cluster", "us-east-1", "prod"}, table.Rows[0])
+	assert.Equal(t, []string{"", "kind", "", "", ""}, table.Rows[1])
+}
+
+func TestRenderTable(t *testing.T) {
+	newCmd := func(args ...string) *cobra.Command {
+		cmd := &cobra.Command{Use: "list"}
+		addTableFlags(cmd, "name")
+		require.NoError(t, cmd.ParseFlags(args))
+		return cmd
+	}
+	table := buildContextsTable([]services_kubernetes.ClusterContext{{Name: "b"}, {Name: "a"}, {Name: "c"}})
+
+	output, err := renderTable(newCmd("--page-size", "2"), table)
+	require.NoError(t, err)
+	assert.Equal(t, "a", table.Rows[0][1])
+	assert.Contains(t, output, "Page 1/2 (3 rows)")
+
+	_, err = renderTable(newCmd("--desc"), table)
+	require.NoError(t, err)
+	assert.Equal(t, "c", table.Rows[0][1])
+
+	_, err = renderTable(newCmd("--sort", "bogus"), table)
+	assert.Error(t, err)
+
+	for _, name := range []string{"sort", "desc", "page", "page-size", "kubeconfig-path"} {
+		assert.NotNil(t, kubernetesListCmd.Flags().Lookup(name), name)
+	}
+	assert.NotNil(t, awsProfilesCmd.Flags().Lookup("sort"))
+}
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/andresgarcia29/ark-cli/lib/animation"
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	"github.com/spf13/cobra"
+)
+
+var (
+	kubernetesNodegroupsCmd = &cobra.Command{
+		Use:   "nodegroups",
+		Short: "List the nodegroups and Fargate profiles of every cluster in an account",
+		Long: `List the managed nodegroups of every EKS cluster an AWS profile can reach, with their scaling
+configuration, AMI type and Kubernetes version, followed by the Fargate profiles.
+Nodegroups running an older version than their control plane are reported at the end.`,
+		Run: kubernetesNodegroups,
+	}
+)
+
+func init() {
+	kubernetesCmd.AddCommand(kubernetesNodegroupsCmd)
+	kubernetesNodegroupsCmd.Flags().String("profile", "", "AWS profile of the account to inspect (prompted when missing)")
+	kubernetesNodegroupsCmd.Flags().StringSlice("regions", []string{"us-west-2"}, "List of AWS regions to scan")
+	addTableFlags(kubernetesNodegroupsCmd, "cluster")
+}
+
+func kubernetesNodegroups(cmd *cobra.Command, args []string) {
+	profileName, err := requireStringFlag(cmd, "profile", "AWS profile", animation.InputOptions{})
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	regions, _ := cmd.Flags().GetStringSlice("regions")
+
+	// The account ID only labels the clusters, so profiles without one (static keys) still work
+	accountID := ""
+	if profile, err := services_aws.ReadProfileFromConfig(profileName); err == nil {
+		accountID = profile.AccountID
+	}
+
+	var computes []services_aws.ClusterCompute
+	err = animation.ShowStatus(context.Background(), "Listing nodegroups and Fargate profiles", func(ctx context.Context, status func(string)) error {
+		var err error
+		computes, err = services_aws.GetAccountCompute(ctx, services_aws.NewComputeLister, profileName, accountID, regions)
+		return err
+	})
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	if len(computes) == 0 {
+		fmt.Printf("No EKS clusters found in %s\n", strings.Join(regions, ", "))
+		return
+	}
+
+	output, err := renderTable(cmd, buildNodegroupsTable(computes))
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	fmt.Print(output)
+
+	if fargate := buildFargateProfilesTable(computes); len(fargate.Rows) > 0 {
+		fmt.Println("\nFargate profiles:")
+		fmt.Print(fargate.Render())
+	}
+
+	if warnings := versionSkewWarnings(computes); len(warnings) > 0 {
+		fmt.Println("\nNodegroups behind their control plane:")
+		for _, warning := range warnings {
+			fmt.Printf("  ⚠ %s\n", warning)
+		}
+	}
+}
+
+// buildNodegroupsTable lays out the nodegroups of each cluster, with scaling as min/desired/max
+func buildNodegroupsTable(computes []services_aws.ClusterCompute) *animation.Table {
+	table := animation.NewTable("Cluster", "Region", "Nodegroup", "Status", "AMI", "Capacity", "Instances", "Scaling", "Version", "Skew")
+	for _, compute := range computes {
+		for _, nodegroup := range compute.Nodegroups {
+			table.AddRow(
+				compute.Cluster.Name,
+				compute.Cluster.Region,
+				nodegroup.Name,
+				nodegroup.Status,
+				nodegroup.AMIType,
+				nodegroup.CapacityType,
+				strings.Join(nodegroup.InstanceTypes, ","),
+				fmt.Sprintf("%d/%d/%d", nodegroup.MinSize, nodegroup.DesiredSize, nodegroup.MaxSize),
+				nodegroup.Version,
+				formatVersionSkew(compute.Cluster.Version, nodegroup.Version),
+			)
+		}
+	}
+	return table
+}
+
+// buildFargateProfilesTable lays out the Fargate profiles of each cluster with the namespaces they select
+func buildFargateProfilesTable(computes []services_aws.ClusterCompute) *animation.Table {
+	table := animation.NewTable("Cluster", "Region", "Profile", "Status", "Namespaces")
+	for _, compute := range computes {
+		for _, profile := range compute.FargateProfiles {
+			table.AddRow(compute.Cluster.Name, compute.Cluster.Region, profile.Name, profile.Status, strings.Join(profile.Namespaces, ","))
+		}
+	}
+	return table
+}
+
+// formatVersionSkew shows how far a nodegroup is behind its control plane, empty when it isn't
+func formatVersionSkew(controlPlane, nodegroup string) string {
+	skew, err := services_aws.VersionSkew(controlPlane, nodegroup)
+	switch {
+	case err != nil:
+		return "?"
+	case skew == 0:
+		return ""
+	case skew < 0:
+		return fmt.Sprintf("%d ahead", -skew)
+	default:
+		return fmt.Sprintf("%d behind", skew)
+	}
+}
+
+// versionSkewWarnings describes each nodegroup running an older minor version than its control plane
+func versionSkewWarnings(computes []services_aws.ClusterCompute) []string {
+	var warnings []string
+	for _, compute := range computes {
+		for _, nodegroup := range compute.Nodegroups {
+			skew, err := services_aws.VersionSkew(compute.Cluster.Version, nodegroup.Version)
+			if err != nil || skew <= 0 {
+				continue
+			}
+			warnings = append(warnings, fmt.Sprintf("%s/%s (%s): nodes on %s, control plane on %s (%d minor version(s) behind)",
+				compute.Cluster.Name, nodegroup.Name, compute.Cluster.Region, nodegroup.Version, compute.Cluster.Version, skew))
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"testing"
+
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testClusterComputes() []services_aws.ClusterCompute {
+	return []services_aws.ClusterCompute{
+		{
+			Cluster: services_aws.EKSCluster{Name: "api", Region: "us-west-2", Version: "1.30"},
+			Nodegroups: []services_aws.EKSNodegroup{
+				{Name: "default", Status: "ACTIVE", AMIType: "AL2023_x86_64_STANDARD", CapacityType: "ON_DEMAND", InstanceTypes: []string{"m6i.large", "m6a.large"}, Version: "1.30", MinSize: 1, DesiredSize: 2, MaxSize: 4},
+				{Name: "legacy", Status: "ACTIVE", AMIType: "AL2_x86_64", CapacityType: "SPOT", Version: "1.28"},
+			},
+		},
+		{
+			Cluster:         services_aws.EKSCluster{Name: "batch", Region: "eu-west-1", Version: "1.29"},
+			FargateProfiles: []services_aws.EKSFargateProfile{{Name: "jobs", Status: "ACTIVE", Namespaces: []string{"jobs", "cron"}}},
+		},
+	}
+}
+
+func TestBuildNodegroupsTable(t *testing.T) {
+	table := buildNodegroupsTable(testClusterComputes())
+	require.Len(t, table.Rows, 2)
+	assert.Equal(t, []string{"api", "us-west-2", "default", "ACTIVE", "AL2023_x86_64_STANDARD", "ON_DEMAND", "m6i.large,m6a.large", "1/2/4", "1.30", ""}, table.Rows[0])
+	assert.Equal(t, "2 behind", table.Rows[1][9])
+
+	fargate := buildFargateProfilesTable(testClusterComputes())
+	assert.Equal(t, [][]string{{"batch", "eu-west-1", "jobs", "ACTIVE", "jobs,cron"}}, fargate.Rows)
+}
+
+func TestFormatVersionSkew(t *testing.T) {
+	assert.Equal(t, "", formatVersionSkew("1.30", "1.30"))
+	assert.Equal(t, "1 behind", formatVersionSkew("1.30", "1.29"))
+	assert.Equal(t, "1 ahead", formatVersionSkew("1.29", "1.30"))
+	assert.Equal(t, "?", formatVersionSkew("", "1.30"))
+}
+
+func TestVersionSkewWarnings(t *testing.T) {
+	assert.Equal(t, []string{
+		"api/legacy (us-west-2): nodes on 1.28, control plane on 1.30 (2 minor version(s) behind)",
+	}, versionSkewWarnings(testClusterComputes()))
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"fmt"
+
+	ark_config "github.com/andresgarcia29/ark-cli/config"
+	"github.com/andresgarcia29/ark-cli/lib/animation"
+	services_kubernetes "github.com/andresgarcia29/ark-cli/services/kubernetes"
+	"github.com/spf13/cobra"
+)
+
+var (
+	kubernetesRenameCmd = &cobra.Command{
+		Use:   "rename",
+		Short: "Rename ark-managed contexts after the context alias template",
+		Long: `Apply the kubernetes.context_alias template of the ark config to the contexts written by ark setup,
+e.g. after changing the template. Other contexts are left untouched.`,
+		Run: kubernetesRename,
+	}
+)
+
+func init() {
+	kubernetesCmd.AddCommand(kubernetesRenameCmd)
+	kubernetesRenameCmd.Flags().String("kubeconfig-path", "", "Kubeconfig to update (default: the file ark setup writes to)")
+	kubernetesRenameCmd.Flags().String("template", "", "Alias template to apply instead of kubernetes.context_alias, e.g. '{{.Cluster}}-{{.Region}}'")
+	kubernetesRenameCmd.Flags().Bool("dry-run", false, "List the renames without writing them")
+}
+
+func kubernetesRename(cmd *cobra.Command, args []string) {
+	kubeconfigPath, _ := cmd.Flags().GetString("kubeconfig-path")
+	aliasTemplate, _ := cmd.Flags().GetString("template")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	if aliasTemplate == "" {
+		aliasTemplate = ark_config.Get().Kubernetes.ContextAlias
+	}
+
+	kubeconfigPath, err := services_kubernetes.ResolveKubeconfigPath(kubeconfigPath)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	renamed, err := renameContexts(kubeconfigPath, aliasTemplate, dryRun)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	if renamed > 0 && !dryRun {
+		fmt.Printf("✓ Renamed %d context(s) in %s\n", renamed, kubeconfigPath)
+	}
+}
+
+// renameContexts renames the ark-managed contexts of a kubeconfig after confirmation, returning how many changed
+func renameContexts(kubeconfigPath, aliasTemplate string, dryRun bool) (int, error) {
+	kubeconfig, err := services_kubernetes.LoadKubeconfig(kubeconfigPath)
+	if err != nil {
+		return 0, err
+	}
+
+	renames, err := kubeconfig.PlanContextRenames(aliasTemplate)
+	if err != nil {
+		return 0, err
+	}
+	if len(renames) == 0 {
+		fmt.Println("All ark-managed contexts already match the alias template")
+		return 0, nil
+	}
+
+	details := make([]string, 0, len(renames))
+	for _, rename := range renames {
+		details = append(details, fmt.Sprintf("%s → %s", rename.From, rename.To))
+	}
+	if dryRun {
+		fmt.Printf("%d context(s) would be renamed:\n", len(renames))
+		for _, detail := range details {
+			fmt.Printf("  - %s\n", detail)
+		}
+		return len(renames), nil
+	}
+
+	if err := preflightWrites(func() (string, error) { return kubeconfigPath, nil }); err != nil {
+		return 0, err
+	}
+	confirmed, err := confirmAction(
+		fmt.Sprintf("Rename %d context(s) in %s?", len(renames), kubeconfigPath),
+		animation.ConfirmOptions{Details: details},
+	)
+	if err != nil {
+		return 0, err
+	}
+	if !confirmed {
+		fmt.Println("Aborted: kubeconfig was not modified")
+		return 0, nil
+	}
+
+	kubeconfig.RenameContexts(renames)
+	if err := services_kubernetes.SaveKubeconfig(kubeconfigPath, kubeconfig); err != nil {
+		return 0, err
+	}
+	return len(renames), nil
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	services_kubernetes "github.com/andresgarcia29/ark-cli/services/kubernetes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenameContexts(t *testing.T) {
+	original := AssumeYes
+	defer func() { AssumeYes = original }()
+	AssumeYes = true
+
+	path := filepath.Join(t.TempDir(), "config")
+	kubeconfig := services_kubernetes.NewKubeconfig()
+	kubeconfig.UpsertEKSContext(services_kubernetes.EKSContextOptions{
+		Alias:       "prod",
+		ClusterName: "prod",
+		ARN:         "arn:aws:eks:us-west-2:111111111111:cluster/prod",
+		Region:      "us-west-2",
+	})
+	require.NoError(t, services_kubernetes.SaveKubeconfig(path, kubeconfig))
+
+	renamed, err := renameContexts(path, "{{.Cluster}}-{{.AccountID}}", true)
+	require.NoError(t, err)
+	assert.Equal(t, 1, renamed)
+	unchanged, err := services_kubernetes.LoadKubeconfig(path)
+	require.NoError(t, err)
+	assert.NotNil(t, unchanged.FindContext("prod"), "dry run leaves the file alone")
+
+	renamed, err = renameContexts(path, "{{.Cluster}}-{{.AccountID}}", false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, renamed)
+	updated, err := services_kubernetes.LoadKubeconfig(path)
+	require.NoError(t, err)
+	assert.NotNil(t, updated.FindContext("prod-111111111111"))
+	assert.Nil(t, updated.FindContext("prod"))
+
+	for _, name := range []string{"kubeconfig-path", "template", "dry-run"} {
+		assert.NotNil(t, kubernetesRenameCmd.Flags().Lookup(name), name)
+	}
+}
@@ -3,7 +3,9 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"time"
 
+	ark_config "github.com/andresgarcia29/ark-cli/config"
 	controllers_k8s "github.com/andresgarcia29/ark-cli/controllers/kubernetes"
 	"github.com/andresgarcia29/ark-cli/lib/animation"
 	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
@@ -14,9 +16,10 @@ import (
 var (
 	kubernetesSetupCmd = &cobra.Command{
 		Use:   "setup",
-		Short: "Setup and configure EKS clusters in kubeconfig",
-		Long:  `Setup and configure EKS clusters in kubeconfig by fetching clusters from all AWS accounts and updating the kubeconfig file.`,
-		Run:   kubernetesSetup,
+		Short: "Add the EKS clusters of every account to kubeconfig",
+		Long: `Scan every account of ~/.aws/config, one profile per account, for EKS clusters in --regions and write a kubeconfig
+context for each one. The contexts to add, update and remove are listed and confirmed before the kubeconfig is written.`,
+		Run: kubernetesSetup,
 	}
 )
 
@@ -24,30 +27,59 @@ func init() {
 	kubernetesCmd.AddCommand(kubernetesSetupCmd)
 	kubernetesSetupCmd.Flags().StringSlice("regions", []string{"us-west-2"}, "List of AWS regions to scan")
 	kubernetesSetupCmd.Flags().Bool("clean", true, "Clean kubeconfig before configuring")
-	kubernetesSetupCmd.Flags().String("kubeconfig-path", "~/.kube/config", "Path to kubeconfig")
+	kubernetesSetupCmd.Flags().String("kubeconfig-path", "", "Kubeconfig to write (default: kubernetes.kubeconfig_file in the ark config, else the first existing file of KUBECONFIG, else ~/.kube/config)")
 	kubernetesSetupCmd.Flags().StringSlice("role-prefixs", []string{"readonly", "read-only"}, "Role prefixs to scan")
 	kubernetesSetupCmd.Flags().String("replace-profile", "", "Replace profile in kubeconfig")
 	kubernetesSetupCmd.Flags().String("role-arn", "", "Specific Role ARN to use for authentication (mutually exclusive with role-prefixs)")
+	kubernetesSetupCmd.Flags().String("discovery", "", "How clusters are found: list-clusters (every account and region) or resource-explorer (one search of the Resource Explorer aggregator index) (default: kubernetes.discovery in the ark config)")
+	kubernetesSetupCmd.Flags().String("writer", "", "Kubeconfig writer: aws-cli (calls `aws eks update-kubeconfig`) or native (defaults to kubernetes.writer in the ark config)")
+	kubernetesSetupCmd.Flags().Bool("native", false, "Write contexts directly instead of calling `aws eks update-kubeconfig`")
+	kubernetesSetupCmd.Flags().MarkDeprecated("native", "use --writer native instead")
+	kubernetesSetupCmd.Flags().String("auth-mode", string(services_kubernetes.AuthModeExec), "User credentials for native contexts: exec (ark token) or static (embedded short-lived token)")
+	kubernetesSetupCmd.Flags().String("cluster-profiles", "", "File mapping clusters to the profile their contexts use (default: kubernetes.cluster_profiles in the ark config, else cluster_profiles.yaml in the ark config directory)")
+	kubernetesSetupCmd.Flags().Bool("details", false, "Describe every cluster before writing (endpoint, certificate, version) and cache the details for --offline; slower, one DescribeCluster call per cluster")
+	kubernetesSetupCmd.Flags().Bool("verify", false, "After writing, load every new context and read from its API server, reporting contexts denied by RBAC apart from ones that fail to authenticate")
+	kubernetesSetupCmd.Flags().Bool("offline", false, "Use the clusters cached by the last online setup instead of scanning AWS (requires --writer native)")
 }
 
-// ConfigureAllEKSClusters is the complete flow to configure all EKS clusters
-func ConfigureAllEKSClusters(ctx context.Context, regions []string, cleanKubeconfig bool, kubeconfigPath string, rolePrefixs []string, replaceProfile string, roleARN string) error {
-	// Step 1: Clean kubeconfig if required
-	if cleanKubeconfig {
-		fmt.Println("🧹 Cleaning kubeconfig...")
-		if err := services_kubernetes.CleanKubeconfig(kubeconfigPath); err != nil {
-			return fmt.Errorf("failed to clean kubeconfig: %w", err)
-		}
-		fmt.Println()
-	}
+// KubernetesSetupOptions groups the settings of the EKS configuration flow
+type KubernetesSetupOptions struct {
+	Regions         []string
+	CleanKubeconfig bool
+	KubeconfigPath  string
+	RolePrefixs     []string
+	ReplaceProfile  string
+	RoleARN         string
+	Discovery       services_aws.DiscoveryBackend
+	// Writer selects the kubeconfig engine; AuthMode only applies to the native writer
+	Writer   controllers_k8s.WriterKind
+	AuthMode services_kubernetes.AuthMode
+	// Offline reads the cluster list from the discovery cache instead of scanning AWS
+	Offline bool
+	// Details describes every cluster after discovery; without it, only the native writer describes the clusters it writes
+	Details bool
+	// Verify reads from every written context afterwards, to tell RBAC denials from authentication failures
+	Verify bool
+	// ClusterProfiles maps clusters to a profile other than the one that discovered them; ReplaceProfile wins over it
+	ClusterProfiles *services_kubernetes.ClusterProfiles
+}
 
-	// Step 2: Get all clusters from all accounts with a spinner
+// ConfigureAllEKSClusters is the complete flow to configure all EKS clusters
+// Nothing is written until the context changes, grouped by account and region, are confirmed (or --yes is set)
+func ConfigureAllEKSClusters(ctx context.Context, opts KubernetesSetupOptions) error {
+	// Step 1: Get all clusters from all accounts with a spinner, or from the cache when offline
 	var clusters []services_aws.EKSCluster
-	err := animation.ShowSpinner("Fetching EKS clusters from all accounts", func() error {
-		var err error
-		clusters, err = services_aws.GetClustersFromAllAccounts(ctx, regions, rolePrefixs, roleARN)
-		return err
-	})
+	var err error
+	discoveredAt := time.Now()
+	if opts.Offline {
+		clusters, discoveredAt, err = cachedClusters(opts.Regions)
+	} else {
+		err = animation.ShowStatus(ctx, "Fetching EKS clusters from all accounts", func(ctx context.Context, status func(string)) error {
+			var err error
+			clusters, err = services_aws.DiscoverClusters(ctx, opts.Discovery, opts.Regions, opts.RolePrefixs, opts.RoleARN)
+			return err
+		})
+	}
 
 	if err != nil {
 		return fmt.Errorf("failed to get clusters: %w", err)
@@ -58,55 +90,300 @@ func ConfigureAllEKSClusters(ctx context.Context, regions []string, cleanKubecon
 		return nil
 	}
 
-	fmt.Printf("\n✓ Total clusters found: %d\n", len(clusters))
+	fmt.Printf("\n✓ Total clusters found: %d\n\n", len(clusters))
 
-	// Show clusters summary per account
-	accountClusters := make(map[string]int)
-	for _, cluster := range clusters {
-		accountClusters[cluster.AccountID]++
+	if opts.Details && !opts.Offline {
+		failed := 0
+		err := animation.ShowStatus(ctx, fmt.Sprintf("Describing %d clusters", len(clusters)), func(ctx context.Context, status func(string)) error {
+			clusters, failed = controllers_k8s.DescribeClusters(ctx, services_aws.NewClusterLister, clusters)
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to describe clusters: %w", err)
+		}
+		if failed > 0 {
+			fmt.Printf("Warning: %d cluster(s) could not be described and are written without details\n", failed)
+		}
 	}
-	fmt.Println("\nClusters by account:")
-	for accountID, count := range accountClusters {
-		fmt.Printf("  - Account %s: %d cluster(s)\n", accountID, count)
+
+	if mapped := controllers_k8s.MapClusterProfiles(clusters, opts.ClusterProfiles); mapped > 0 {
+		fmt.Printf("🔀 %d cluster(s) mapped to another profile by the cluster profiles file\n", mapped)
+		warnUnknownProfiles(clusters)
+		fmt.Println()
 	}
 
-	fmt.Println()
+	// Step 2: Confirm the context changes before touching the kubeconfig
+	confirmed, err := confirmKubeconfigPlan(opts, clusters)
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		fmt.Println("Aborted: kubeconfig was not modified")
+		return nil
+	}
 
-	// Step 3: Configure kubeconfig for all clusters with progress bar
-	if err := controllers_k8s.UpdateKubeconfigWithProgress(clusters, replaceProfile); err != nil {
+	// Step 3: Clean kubeconfig if required
+	if opts.CleanKubeconfig {
+		fmt.Println("🧹 Cleaning kubeconfig...")
+		if err := services_kubernetes.CleanKubeconfig(opts.KubeconfigPath); err != nil {
+			return fmt.Errorf("failed to clean kubeconfig: %w", err)
+		}
+		fmt.Println()
+	}
+
+	// Step 4: Configure kubeconfig for all clusters with progress bar
+	// The writer is created after cleaning, since the native one loads the kubeconfig up front
+	writer, err := controllers_k8s.NewKubeconfigWriter(opts.Writer, controllers_k8s.KubeconfigWriterOptions{
+		KubeconfigPath: opts.KubeconfigPath,
+		ReplaceProfile: opts.ReplaceProfile,
+		AuthMode:       opts.AuthMode,
+		DiscoveredAt:   discoveredAt,
+	})
+	if err != nil {
+		return err
+	}
+	if err := controllers_k8s.UpdateKubeconfigWithWriter(ctx, writer, clusters); err != nil {
 		return fmt.Errorf("failed to update kubeconfig: %w", err)
 	}
 
+	// Cache the discovery, with the details described by --details or the native writer, for --offline
+	if !opts.Offline {
+		if err := services_aws.SaveClusterCache(clusters); err != nil {
+			fmt.Printf("Warning: failed to cache clusters: %v\n", err)
+		}
+	}
+
+	// Step 5: Verify the written contexts if required
+	if opts.Verify {
+		var results []services_kubernetes.ContextVerification
+		err := animation.ShowStatus(ctx, "Verifying written contexts", func(ctx context.Context, status func(string)) error {
+			var err error
+			results, err = controllers_k8s.VerifyWrittenContexts(ctx, opts.KubeconfigPath, clusters, opts.ReplaceProfile)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to verify contexts: %w", err)
+		}
+		controllers_k8s.PrintContextVerification(results)
+	}
+
 	return nil
 }
 
+// confirmKubeconfigPlan asks before adding, rewriting or removing contexts, showing the changes per account and region
+func confirmKubeconfigPlan(opts KubernetesSetupOptions, clusters []services_aws.EKSCluster) (bool, error) {
+	existing, err := services_kubernetes.LoadKubeconfig(opts.KubeconfigPath)
+	if err != nil {
+		return false, err
+	}
+
+	// The summary is printed rather than attached to the prompt so it is also shown with --yes
+	plan := controllers_k8s.PlanKubeconfigChanges(existing, clusters, opts.CleanKubeconfig, opts.ReplaceProfile)
+	fmt.Println("Kubeconfig changes by account / region:")
+	for _, line := range plan.Summary() {
+		fmt.Printf("  - %s\n", line)
+	}
+	fmt.Println()
+
+	var details []string
+	if opts.CleanKubeconfig && len(existing.Contexts) > 0 {
+		details = append(details, fmt.Sprintf("%s is cleaned first (a .backup copy is kept)", opts.KubeconfigPath))
+	}
+
+	return confirmAction(
+		fmt.Sprintf("Update %s? %d context(s) added, %d updated, %d removed.",
+			opts.KubeconfigPath,
+			plan.Count(controllers_k8s.ContextAdded),
+			plan.Count(controllers_k8s.ContextUpdated),
+			plan.Count(controllers_k8s.ContextRemoved),
+		),
+		animation.ConfirmOptions{
+			Details:     details,
+			Destructive: plan.Count(controllers_k8s.ContextRemoved) > 0,
+		},
+	)
+}
+
+// warnUnknownProfiles warns about profiles that clusters were mapped to but ~/.aws/config doesn't define
+func warnUnknownProfiles(clusters []services_aws.EKSCluster) {
+	warned := make(map[string]bool)
+	for _, cluster := range clusters {
+		if warned[cluster.Profile] {
+			continue
+		}
+		if _, err := services_aws.ReadProfileFromConfig(cluster.Profile); err != nil {
+			warned[cluster.Profile] = true
+			fmt.Printf("Warning: profile %q is not in the AWS config, contexts using it won't authenticate\n", cluster.Profile)
+		}
+	}
+}
+
+// cachedClusters returns the cached clusters of the given regions that can be written without AWS calls,
+// and when they were discovered
+func cachedClusters(regions []string) ([]services_aws.EKSCluster, time.Time, error) {
+	cache, err := services_aws.LoadClusterCache()
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	fmt.Printf("📦 Offline: using clusters cached on %s\n", cache.UpdatedAt.Format("2006-01-02 15:04"))
+
+	wanted := make(map[string]bool, len(regions))
+	for _, region := range regions {
+		wanted[region] = true
+	}
+
+	var clusters []services_aws.EKSCluster
+	missingDetails := 0
+	for _, cluster := range cache.Clusters {
+		if len(wanted) > 0 && !wanted[cluster.Region] {
+			continue
+		}
+		if cluster.Endpoint == "" {
+			missingDetails++
+			continue
+		}
+		clusters = append(clusters, cluster)
+	}
+	if missingDetails > 0 {
+		fmt.Printf("Warning: %d cached cluster(s) have no endpoint details (run setup online with --details or --writer native to cache them)\n", missingDetails)
+	}
+	return clusters, cache.UpdatedAt, nil
+}
+
 func kubernetesSetup(cmd *cobra.Command, args []string) {
 	regions, _ := cmd.Flags().GetStringSlice("regions")
 	cleanConfig, _ := cmd.Flags().GetBool("clean")
 	kubeconfigPath, _ := cmd.Flags().GetString("kubeconfig-path")
 	replaceProfile, _ := cmd.Flags().GetString("replace-profile")
-	rolePrefixs, _ := cmd.Flags().GetStringSlice("role-prefixs")
-	roleARN, _ := cmd.Flags().GetString("role-arn")
+	writerName, _ := cmd.Flags().GetString("writer")
+	native, _ := cmd.Flags().GetBool("native")
+	authMode, _ := cmd.Flags().GetString("auth-mode")
+	offline, _ := cmd.Flags().GetBool("offline")
+	details, _ := cmd.Flags().GetBool("details")
+	verify, _ := cmd.Flags().GetBool("verify")
+	clusterProfilesPath, _ := cmd.Flags().GetString("cluster-profiles")
 
 	ctx := context.Background()
 
-	// Validate flags exclusivity
-	if cmd.Flags().Changed("role-prefixs") && cmd.Flags().Changed("role-arn") {
-		fmt.Println("Error: --role-prefixs and --role-arn are mutually exclusive")
+	rolePrefixs, roleARN, err := discoveryRoles(cmd)
+	if err != nil {
+		fmt.Println("Error:", err)
 		return
 	}
-
-	// If role-arn is provided, we don't use prefixes
-	if roleARN != "" {
-		rolePrefixs = nil
-	} else if !cmd.Flags().Changed("role-prefixs") {
-		// Only use defaults if the flag hasn't changed and there is no ARN
+	discovery, err := discoveryBackend(cmd)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	if roleARN == "" && !cmd.Flags().Changed("role-prefixs") {
 		fmt.Println("No role prefixs or ARN provided, using default prefixs: readonly, read-only")
-		rolePrefixs = []string{"readonly", "read-only"}
 	}
 
-	if err := ConfigureAllEKSClusters(ctx, regions, cleanConfig, kubeconfigPath, rolePrefixs, replaceProfile, roleARN); err != nil {
+	mode := services_kubernetes.AuthMode(authMode)
+	if mode != services_kubernetes.AuthModeExec && mode != services_kubernetes.AuthModeStatic {
+		fmt.Printf("Error: invalid --auth-mode %q (expected exec or static)\n", authMode)
+		return
+	}
+
+	writer, err := resolveKubeconfigWriter(writerName, native)
+	if err != nil {
 		fmt.Println("Error:", err)
 		return
 	}
+	if mode == services_kubernetes.AuthModeStatic && writer != controllers_k8s.WriterNative {
+		fmt.Println("Error: --auth-mode static requires --writer native")
+		return
+	}
+	if offline && (writer != controllers_k8s.WriterNative || mode != services_kubernetes.AuthModeExec) {
+		fmt.Println("Error: --offline requires --writer native with --auth-mode exec")
+		return
+	}
+
+	// With a KUBECONFIG list, contexts go to a single file that kubectl merges with the others
+	kubeconfigPath, err = services_kubernetes.ResolveKubeconfigPath(kubeconfigPath)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	if paths, err := services_kubernetes.KubeconfigPaths(); err == nil && len(paths) > 1 {
+		fmt.Printf("📄 KUBECONFIG lists %d files, writing contexts to %s\n", len(paths), kubeconfigPath)
+	}
+
+	// Read before scanning, so a broken mapping file doesn't waste a scan
+	clusterProfiles, err := services_kubernetes.LoadClusterProfiles(clusterProfilesPath)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	if err := preflightWrites(func() (string, error) { return kubeconfigPath, nil }); err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	if !offline && !guardLargeScan(discovery, regions, rolePrefixs, roleARN) {
+		return
+	}
+
+	opts := KubernetesSetupOptions{
+		Regions:         regions,
+		CleanKubeconfig: cleanConfig,
+		KubeconfigPath:  kubeconfigPath,
+		RolePrefixs:     rolePrefixs,
+		ReplaceProfile:  replaceProfile,
+		RoleARN:         roleARN,
+		Discovery:       discovery,
+		Writer:          writer,
+		AuthMode:        mode,
+		Offline:         offline,
+		Details:         details,
+		Verify:          verify,
+		ClusterProfiles: clusterProfiles,
+	}
+
+	err = ConfigureAllEKSClusters(ctx, opts)
+	if !offline {
+		notifyScanResult(ctx, "ark kubernetes setup", err)
+	}
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+}
+
+// resolveKubeconfigWriter picks the writer from --writer, the deprecated --native flag or the ark config
+func resolveKubeconfigWriter(flagValue string, native bool) (controllers_k8s.WriterKind, error) {
+	if flagValue != "" {
+		return controllers_k8s.ParseWriterKind(flagValue)
+	}
+	if native {
+		return controllers_k8s.WriterNative, nil
+	}
+	return controllers_k8s.ParseWriterKind(ark_config.Get().Kubernetes.Writer)
+}
+
+// discoveryBackend reads --discovery of the commands scanning every account, defaulting to kubernetes.discovery
+func discoveryBackend(cmd *cobra.Command) (services_aws.DiscoveryBackend, error) {
+	name, _ := cmd.Flags().GetString("discovery")
+	if name == "" {
+		name = ark_config.Get().Kubernetes.Discovery
+	}
+	return services_aws.ParseDiscoveryBackend(name)
+}
+
+// discoveryRoles reads the mutually exclusive --role-prefixs and --role-arn flags of the commands scanning every account
+// A role ARN disables the prefixes; without either, the default read-only prefixes are used
+func discoveryRoles(cmd *cobra.Command) (rolePrefixs []string, roleARN string, err error) {
+	if cmd.Flags().Changed("role-prefixs") && cmd.Flags().Changed("role-arn") {
+		return nil, "", fmt.Errorf("--role-prefixs and --role-arn are mutually exclusive")
+	}
+
+	roleARN, _ = cmd.Flags().GetString("role-arn")
+	if roleARN != "" {
+		return nil, roleARN, nil
+	}
+	if !cmd.Flags().Changed("role-prefixs") {
+		return []string{"readonly", "read-only"}, "", nil
+	}
+	rolePrefixs, _ = cmd.Flags().GetStringSlice("role-prefixs")
+	return rolePrefixs, "", nil
 }
@@ -1,10 +1,19 @@
 package cmd
 
 import (
+	"context"
+	"path/filepath"
 	"testing"
+	"time"
 
+	ark_config "github.com/andresgarcia29/ark-cli/config"
+	controllers_k8s "github.com/andresgarcia29/ark-cli/controllers/kubernetes"
+	"github.com/andresgarcia29/ark-cli/lib/animation"
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	services_kubernetes "github.com/andresgarcia29/ark-cli/services/kubernetes"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestKubernetesSetupCommandFlags(t *testing.T) {
@@ -57,3 +66,105 @@ func TestKubernetesSetupCommandFlags(t *testing.T) {
 		})
 	}
 }
+
+func TestResolveKubeconfigWriter(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv(ark_config.ConfigPathEnvVar, configPath)
+
+	writer, err := resolveKubeconfigWriter("native", false)
+	require.NoError(t, err)
+	assert.Equal(t, controllers_k8s.WriterNative, writer)
+
+	writer, err = resolveKubeconfigWriter("aws-cli", true)
+	require.NoError(t, err)
+	assert.Equal(t, controllers_k8s.WriterAWSCLI, writer, "--writer wins over --native")
+
+	writer, err = resolveKubeconfigWriter("", true)
+	require.NoError(t, err)
+	assert.Equal(t, controllers_k8s.WriterNative, writer)
+
+	_, err = resolveKubeconfigWriter("bogus", false)
+	assert.Error(t, err)
+}
+
+func TestCachedClusters(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	_, _, err := cachedClusters([]string{"us-west-2"})
+	assert.ErrorIs(t, err, services_aws.ErrNoDiscoveryCache)
+
+	require.NoError(t, services_aws.SaveClusterCache([]services_aws.EKSCluster{
+		{Name: "dev", Region: "us-west-2", AccountID: "111111111111", Endpoint: "https://dev.eks"},
+		{Name: "eu", Region: "eu-west-1", AccountID: "111111111111", Endpoint: "https://eu.eks"},
+		{Name: "undescribed", Region: "us-west-2", AccountID: "222222222222"},
+	}))
+
+	clusters, discoveredAt, err := cachedClusters([]string{"us-west-2"})
+	require.NoError(t, err)
+	require.Len(t, clusters, 1, "other regions and clusters without details are left out")
+	assert.Equal(t, "dev", clusters[0].Name)
+	assert.WithinDuration(t, time.Now(), discoveredAt, time.Minute, "clusters were discovered when the cache was saved")
+}
+
+func TestConfigureAllEKSClustersConfirmsChanges(t *testing.T) {
+	original := AssumeYes
+	defer func() { AssumeYes = original }()
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	require.NoError(t, services_aws.SaveClusterCache([]services_aws.EKSCluster{
+		{Name: "dev", Region: "us-west-2", AccountID: "111111111111", ARN: "arn:aws:eks:us-west-2:111111111111:cluster/dev", Endpoint: "https://dev.eks"},
+	}))
+
+	kubeconfigPath := filepath.Join(home, ".kube", "config")
+	opts := KubernetesSetupOptions{
+		Regions:        []string{"us-west-2"},
+		KubeconfigPath: kubeconfigPath,
+		Writer:         controllers_k8s.WriterNative,
+		AuthMode:       services_kubernetes.AuthModeExec,
+		Offline:        true,
+	}
+
+	// Without a terminal or --yes nothing is written
+	AssumeYes = false
+	err := ConfigureAllEKSClusters(context.Background(), opts)
+	assert.ErrorIs(t, err, animation.ErrConfirmationRequired)
+	assert.NoFileExists(t, kubeconfigPath)
+
+	AssumeYes = true
+	require.NoError(t, ConfigureAllEKSClusters(context.Background(), opts))
+	kubeconfig, err := services_kubernetes.LoadKubeconfig(kubeconfigPath)
+	require.NoError(t, err)
+	require.NotNil(t, kubeconfig.FindContext("dev"))
+	metadata, ok := kubeconfig.FindContext("dev").Context.Extensions.Ark()
+	require.True(t, ok, "written contexts are marked as managed by ark")
+	assert.Equal(t, "111111111111", metadata.AccountID)
+	assert.False(t, metadata.DiscoveredAt.IsZero())
+}
+
+func TestDiscoveryRoles(t *testing.T) {
+	newCmd := func(args ...string) *cobra.Command {
+		cmd := &cobra.Command{}
+		cmd.Flags().StringSlice("role-prefixs", []string{"readonly", "read-only"}, "")
+		cmd.Flags().String("role-arn", "", "")
+		require.NoError(t, cmd.Flags().Parse(args))
+		return cmd
+	}
+
+	prefixes, roleARN, err := discoveryRoles(newCmd())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"readonly", "read-only"}, prefixes)
+	assert.Empty(t, roleARN)
+
+	prefixes, _, err = discoveryRoles(newCmd("--role-prefixs", "platform"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"platform"}, prefixes)
+
+	prefixes, roleARN, err = discoveryRoles(newCmd("--role-arn", "arn:aws:iam::111111111111:role/ReadOnly"))
+	require.NoError(t, err)
+	assert.Nil(t, prefixes)
+	assert.Equal(t, "arn:aws:iam::111111111111:role/ReadOnly", roleARN)
+
+	_, _, err = discoveryRoles(newCmd("--role-prefixs", "platform", "--role-arn", "arn:aws:iam::111111111111:role/ReadOnly"))
+	assert.ErrorContains(t, err, "mutually exclusive")
+}
@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/andresgarcia29/ark-cli/audit"
+	ark_config "github.com/andresgarcia29/ark-cli/config"
+	"github.com/andresgarcia29/ark-cli/notify"
+	"github.com/andresgarcia29/ark-cli/paths"
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	"github.com/spf13/cobra"
+)
+
+var (
+	kubernetesTokenCmd = &cobra.Command{
+		Use:   "token",
+		Short: "Print an EKS authentication token as an ExecCredential",
+		Long: `Generate a bearer token for an EKS cluster and print it as a client.authentication.k8s.io
+ExecCredential. This is the command referenced by the exec blocks ark writes into kubeconfig.`,
+		Run: kubernetesToken,
+	}
+)
+
+func init() {
+	kubernetesCmd.AddCommand(kubernetesTokenCmd)
+	kubernetesTokenCmd.Flags().String("cluster-name", "", "EKS cluster name (required)")
+	kubernetesTokenCmd.Flags().String("region", "", "AWS region of the cluster (required)")
+	kubernetesTokenCmd.Flags().String("profile", "", "AWS profile used to sign the token")
+	if err := kubernetesTokenCmd.MarkFlagRequired("cluster-name"); err != nil {
+		panic(err)
+	}
+	if err := kubernetesTokenCmd.MarkFlagRequired("region"); err != nil {
+		panic(err)
+	}
+}
+
+// sessionExpiryWarning is how long before the SSO session expires kubectl users get a desktop notification
+const sessionExpiryWarning = 15 * time.Minute
+
+// execCredential is the response format expected by kubectl exec credential plugins
+type execCredential struct {
+	APIVersion string               `json:"apiVersion"`
+	Kind       string               `json:"kind"`
+	Spec       struct{}             `json:"spec"`
+	Status     execCredentialStatus `json:"status"`
+}
+
+type execCredentialStatus struct {
+	ExpirationTimestamp string `json:"expirationTimestamp"`
+	Token               string `json:"token"`
+}
+
+func kubernetesToken(cmd *cobra.Command, args []string) {
+	clusterName, _ := cmd.Flags().GetString("cluster-name")
+	region, _ := cmd.Flags().GetString("region")
+	profile, _ := cmd.Flags().GetString("profile")
+
+	token, err := services_aws.GetEKSToken(context.Background(), clusterName, region, profile)
+	if err != nil {
+		// kubectl shows stderr to the user, stdout must stay valid JSON
+		fmt.Fprintf(os.Stderr, "ark: failed to get token for cluster %s: %v\n", clusterName, err)
+		os.Exit(1)
+	}
+
+	audit.Record(audit.Event{
+		Event:      audit.EventExec,
+		Profile:    profile,
+		Cluster:    clusterName,
+		Expiration: token.Expiration,
+	})
+
+	notifySessionExpiry(profile)
+
+	output, err := renderExecCredential(token)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ark: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(output)
+}
+
+// notifySessionExpiry shows a desktop notification, once per session, when the SSO session behind profile is about to expire
+// kubectl runs this command in the background, so nothing printed here would reach the user in time
+func notifySessionExpiry(profile string) {
+	if profile == "" || !ark_config.Get().Notifications.Desktop {
+		return
+	}
+
+	ssoRegion, startURL, err := services_aws.ResolveSSOConfiguration(profile)
+	if err != nil || startURL == "" {
+		return
+	}
+	cached, err := services_aws.ReadTokenFromCache(startURL, ssoRegion)
+	if err != nil {
+		return
+	}
+	expiresAt, err := time.Parse(time.RFC3339, cached.ExpiresAt)
+	if err != nil {
+		return
+	}
+	cacheDir, err := paths.CacheDir()
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+	if !claimExpiryNotice(filepath.Join(cacheDir, "session-expiry-notice"), expiresAt, now) {
+		return
+	}
+	notify.Desktop("ark", fmt.Sprintf("The AWS SSO session of %s expires in %s. Run `ark aws sso` to renew it.",
+		profile, expiresAt.Sub(now).Round(time.Minute)))
+}
+
+// claimExpiryNotice reports whether a session expiring at expiresAt is due for its notice
+// The notified expiration is kept in path, so each session is announced only once
+func claimExpiryNotice(path string, expiresAt, now time.Time) bool {
+	if services_aws.ValidFor(expiresAt, now) > sessionExpiryWarning {
+		return false
+	}
+
+	marker := expiresAt.UTC().Format(time.RFC3339)
+	if data, err := os.ReadFile(path); err == nil && string(data) == marker {
+		return false
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return false
+	}
+	return os.WriteFile(path, []byte(marker), 0600) == nil
+}
+
+// renderExecCredential serializes a token as an ExecCredential JSON document
+func renderExecCredential(token *services_aws.EKSToken) (string, error) {
+	credential := execCredential{
+		APIVersion: "client.authentication.k8s.io/v1beta1",
+		Kind:       "ExecCredential",
+		Status: execCredentialStatus{
+			ExpirationTimestamp: token.Expiration.UTC().Format(time.RFC3339),
+			Token:               token.Token,
+		},
+	}
+
+	data, err := json.Marshal(credential)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal exec credential: %w", err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderExecCredential(t *testing.T) {
+	expiration := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	output, err := renderExecCredential(&services_aws.EKSToken{
+		Token:      "k8s-aws-v1.token",
+		Expiration: expiration,
+	})
+	require.NoError(t, err)
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(output), &decoded))
+
+	assert.Equal(t, "ExecCredential", decoded["kind"])
+	assert.Equal(t, "client.authentication.k8s.io/v1beta1", decoded["apiVersion"])
+	status := decoded["status"].(map[string]interface{})
+	assert.Equal(t, "k8s-aws-v1.token", status["token"])
+	assert.Equal(t, "2025-01-02T03:04:05Z", status["expirationTimestamp"])
+}
+
+func TestKubernetesTokenCommandFlags(t *testing.T) {
+	for _, name := range []string{"cluster-name", "region", "profile"} {
+		assert.NotNil(t, kubernetesTokenCmd.Flags().Lookup(name), name)
+	}
+	assert.NotNil(t, kubernetesSetupCmd.Flags().Lookup("writer"))
+	assert.Equal(t, "exec", kubernetesSetupCmd.Flags().Lookup("auth-mode").DefValue)
+}
+
+func TestClaimExpiryNotice(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache", "session-expiry-notice")
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.False(t, claimExpiryNotice(path, now.Add(time.Hour), now), "too early to warn")
+	assert.True(t, claimExpiryNotice(path, now.Add(10*time.Minute), now))
+	assert.False(t, claimExpiryNotice(path, now.Add(10*time.Minute), now.Add(time.Minute)), "already notified")
+	assert.True(t, claimExpiryNotice(path, now.Add(8*time.Hour), now.Add(7*time.Hour+50*time.Minute)), "a renewed session gets its own notice")
+}
@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	controllers_k8s "github.com/andresgarcia29/ark-cli/controllers/kubernetes"
+	"github.com/andresgarcia29/ark-cli/lib/animation"
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	"github.com/spf13/cobra"
+)
+
+var (
+	kubernetesVersionsCmd = &cobra.Command{
+		Use:   "versions",
+		Short: "Report the control plane versions of every EKS cluster",
+		Long: `Discover the EKS clusters of every account, like setup does, and report their control plane versions.
+Clusters more than --max-skew minor versions behind the newest version found (or --reference) are flagged.
+Use --format json to feed platform dashboards.`,
+		Run: kubernetesVersions,
+	}
+)
+
+func init() {
+	kubernetesCmd.AddCommand(kubernetesVersionsCmd)
+	kubernetesVersionsCmd.Flags().StringSlice("regions", []string{"us-west-2"}, "List of AWS regions to scan")
+	kubernetesVersionsCmd.Flags().StringSlice("role-prefixs", []string{"readonly", "read-only"}, "Role prefixs to scan")
+	kubernetesVersionsCmd.Flags().String("role-arn", "", "Specific Role ARN to use for authentication (mutually exclusive with role-prefixs)")
+	kubernetesVersionsCmd.Flags().String("discovery", "", "How clusters are found: list-clusters (every account and region) or resource-explorer (one search of the Resource Explorer aggregator index) (default: kubernetes.discovery in the ark config)")
+	kubernetesVersionsCmd.Flags().Int("max-skew", 2, "Minor versions a cluster may be behind before it is flagged")
+	kubernetesVersionsCmd.Flags().String("reference", "", "Version to compare clusters to, e.g. 1.31 (default: the newest version found)")
+	kubernetesVersionsCmd.Flags().String("format", "table", "Output format: table or json")
+	addTableFlags(kubernetesVersionsCmd, "")
+}
+
+func kubernetesVersions(cmd *cobra.Command, args []string) {
+	regions, _ := cmd.Flags().GetStringSlice("regions")
+	maxSkew, _ := cmd.Flags().GetInt("max-skew")
+	reference, _ := cmd.Flags().GetString("reference")
+	format, _ := cmd.Flags().GetString("format")
+
+	if format != "table" && format != "json" {
+		fmt.Printf("Error: unknown format %q (expected table or json)\n", format)
+		return
+	}
+	if reference != "" {
+		if _, err := services_aws.KubernetesMinorVersion(reference); err != nil {
+			fmt.Println("Error: invalid --reference:", err)
+			return
+		}
+	}
+	rolePrefixs, roleARN, err := discoveryRoles(cmd)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	discovery, err := discoveryBackend(cmd)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	if !guardLargeScan(discovery, regions, rolePrefixs, roleARN) {
+		return
+	}
+
+	var versions []controllers_k8s.ClusterVersion
+	collect := func(ctx context.Context, status func(string)) error {
+		clusters, err := services_aws.DiscoverClusters(ctx, discovery, regions, rolePrefixs, roleARN)
+		if err != nil {
+			return fmt.Errorf("failed to get clusters: %w", err)
+		}
+		status(fmt.Sprintf("Describing %d cluster(s)", len(clusters)))
+		versions = controllers_k8s.DescribeClusterVersions(ctx, services_aws.NewClusterLister, clusters)
+		return nil
+	}
+	if err := animation.ShowStatus(context.Background(), "Fetching EKS clusters from all accounts", collect); err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	report := controllers_k8s.BuildVersionReport(versions, reference, maxSkew)
+	if format == "json" {
+		if err := writeVersionReportJSON(cmd.OutOrStdout(), report); err != nil {
+			fmt.Println("Error:", err)
+		}
+		return
+	}
+
+	if len(report.Clusters) == 0 {
+		fmt.Println("No EKS clusters found in any account")
+		return
+	}
+	output, err := renderTable(cmd, buildVersionsTable(report))
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	fmt.Print(output)
+	fmt.Println()
+	for _, line := range versionReportSummary(report) {
+		fmt.Println(line)
+	}
+}
+
+// writeVersionReportJSON writes the report as indented JSON
+func writeVersionReportJSON(w io.Writer, report controllers_k8s.VersionReport) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+// buildVersionsTable lays out the clusters of a version report, flagging the outdated ones
+func buildVersionsTable(report controllers_k8s.VersionReport) *animation.Table {
+	table := animation.NewTable("Account", "Region", "Cluster", "Version", "Behind", "Status")
+	for _, cluster := range report.Clusters {
+		status := ""
+		switch {
+		case cluster.Error != "":
+			status = "✗ " + cluster.Error
+		case cluster.Outdated:
+			status = "⚠ outdated"
+		}
+		behind := ""
+		if cluster.Version != "" {
+			behind = fmt.Sprint(cluster.Behind)
+		}
+		table.AddRow(cluster.AccountID, cluster.Region, cluster.Cluster, cluster.Version, behind, status)
+	}
+	table.Columns[5].MaxWidth = 60
+	return table
+}
+
+// versionReportSummary describes the version distribution and how many clusters are outdated
+func versionReportSummary(report controllers_k8s.VersionReport) []string {
+	versions := make([]string, 0, len(report.Versions))
+	for version := range report.Versions {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		skew, err := services_aws.VersionSkew(versions[i], versions[j])
+		if err != nil {
+			return versions[i] > versions[j]
+		}
+		return skew > 0
+	})
+	counts := make([]string, 0, len(versions))
+	for _, version := range versions {
+		counts = append(counts, fmt.Sprintf("%s × %d", version, report.Versions[version]))
+	}
+
+	lines := []string{fmt.Sprintf("Versions: %s", strings.Join(counts, ", "))}
+	if report.Reference != "" {
+		lines = append(lines, fmt.Sprintf("%d cluster(s) more than %d minor version(s) behind %s", report.Outdated, report.MaxSkew, report.Reference))
+	}
+	return lines
+}
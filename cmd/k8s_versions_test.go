@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	controllers_k8s "github.com/andresgarcia29/ark-cli/controllers/kubernetes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testVersionReport() controllers_k8s.VersionReport {
+	return controllers_k8s.BuildVersionReport([]controllers_k8s.ClusterVersion{
+		{AccountID: "111111111111", Region: "us-west-2", Cluster: "api", Version: "1.30"},
+		{AccountID: "111111111111", Region: "us-west-2", Cluster: "web", Version: "1.30"},
+		{AccountID: "222222222222", Region: "eu-west-1", Cluster: "batch", Version: "1.27"},
+		{AccountID: "333333333333", Region: "us-west-2", Cluster: "locked", Error: "access denied"},
+	}, "", 2)
+}
+
+func TestBuildVersionsTable(t *testing.T) {
+	table := buildVersionsTable(testVersionReport())
+	assert.Equal(t, [][]string{
+		{"222222222222", "eu-west-1", "batch", "1.27", "3", "⚠ outdated"},
+		{"111111111111", "us-west-2", "api", "1.30", "0", ""},
+		{"111111111111", "us-west-2", "web", "1.30", "0", ""},
+		{"333333333333", "us-west-2", "locked", "", "", "✗ access denied"},
+	}, table.Rows)
+}
+
+func TestVersionReportSummary(t *testing.T) {
+	assert.Equal(t, []string{
+		"Versions: 1.30 × 2, 1.27 × 1",
+		"1 cluster(s) more than 2 minor version(s) behind 1.30",
+	}, versionReportSummary(testVersionReport()))
+}
+
+func TestWriteVersionReportJSON(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, writeVersionReportJSON(&out, testVersionReport()))
+
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	assert.Equal(t, "1.30", decoded["reference_version"])
+	assert.Equal(t, float64(1), decoded["outdated"])
+	clusters := decoded["clusters"].([]any)
+	require.Len(t, clusters, 4)
+	assert.Equal(t, map[string]any{
+		"account_id":            "222222222222",
+		"region":                "eu-west-1",
+		"cluster":               "batch",
+		"version":               "1.27",
+		"minor_versions_behind": float64(3),
+		"outdated":              true,
+	}, clusters[0])
+}
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/andresgarcia29/ark-cli/notify"
+)
+
+// notifyScanResult reports the outcome of a multi-account scan to the configured notifiers
+func notifyScanResult(ctx context.Context, command string, err error) {
+	notify.Send(ctx, scanMessage(command, time.Since(runStartedAt), err))
+}
+
+// scanMessage describes how a scan ended
+func scanMessage(command string, elapsed time.Duration, err error) notify.Message {
+	elapsed = elapsed.Round(time.Second)
+	if err != nil {
+		return notify.Message{
+			Event: notify.EventScanFailed,
+			Text:  fmt.Sprintf("❌ `%s` failed after %s: %v", command, elapsed, err),
+		}
+	}
+	return notify.Message{
+		Event: notify.EventScanFinished,
+		Text:  fmt.Sprintf("✅ `%s` finished in %s", command, elapsed),
+	}
+}
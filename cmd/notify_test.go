@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/andresgarcia29/ark-cli/notify"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScanMessage(t *testing.T) {
+	message := scanMessage("ark kubernetes setup", 3*time.Minute+2400*time.Millisecond, nil)
+	assert.Equal(t, notify.EventScanFinished, message.Event)
+	assert.Equal(t, "✅ `ark kubernetes setup` finished in 3m2s", message.Text)
+
+	message = scanMessage("ark aws sso", 90*time.Second, errors.New("token expired"))
+	assert.Equal(t, notify.EventScanFailed, message.Event)
+	assert.Equal(t, "❌ `ark aws sso` failed after 1m30s: token expired", message.Text)
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/andresgarcia29/ark-cli/lib"
+	"github.com/andresgarcia29/ark-cli/lib/animation"
+)
+
+// readOnlyHomeGuidance explains how to run ark when the default files can't be written
+const readOnlyHomeGuidance = `💡 On read-only or managed home directories, point ark at writable files:
+   --aws-config / --aws-credentials (or AWS_CONFIG_FILE / AWS_SHARED_CREDENTIALS_FILE) for AWS,
+   --kubeconfig-path for kubeconfig`
+
+// preflightWrites checks the files a command is about to write, given their path resolvers, before it does any work
+// Files are expected at 0600 in 0700 directories. Wrong modes are fixed after confirmation (or with --yes);
+// files that can't be written at all stop the command
+func preflightWrites(paths ...func() (string, error)) error {
+	var resolved []lib.WriteTarget
+	for _, resolve := range paths {
+		path, err := resolve()
+		if err != nil {
+			return err
+		}
+		resolved = append(resolved, lib.WriteTarget{Path: path, FileMode: 0600, DirMode: 0700})
+	}
+
+	issues := lib.CheckWriteTargets(resolved)
+	if len(issues) == 0 {
+		return nil
+	}
+
+	var fixable []lib.PermissionIssue
+	var blocking []string
+	for _, issue := range issues {
+		if issue.Fixable {
+			fixable = append(fixable, issue)
+		} else {
+			blocking = append(blocking, "  - "+issue.String())
+		}
+	}
+	if len(blocking) > 0 {
+		return fmt.Errorf("cannot write the files this command needs:\n%s\n%s", strings.Join(blocking, "\n"), readOnlyHomeGuidance)
+	}
+
+	details := make([]string, 0, len(fixable))
+	for _, issue := range fixable {
+		details = append(details, issue.String())
+	}
+	confirmed, err := confirmAction("Fix the permissions of these files?", animation.ConfirmOptions{Details: details})
+	if err != nil && !errors.Is(err, animation.ErrConfirmationRequired) {
+		return err
+	}
+	if confirmed {
+		return lib.FixPermissions(fixable)
+	}
+
+	// Declined (or no terminal): files that are only too open still work, unwritable ones don't
+	for _, issue := range fixable {
+		if errors.Is(issue.Err, lib.ErrNotWritable) {
+			return fmt.Errorf("cannot write %s: %v (fix it with chmod %04o or rerun with --yes)", issue.Path, issue.Err, issue.Want.Perm())
+		}
+		fmt.Printf("⚠️  Warning: %s\n", issue)
+	}
+	return nil
+}
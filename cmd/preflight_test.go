@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreflightWritesFixesWithAssumeYes(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("modes are not checked on Windows")
+	}
+	original := AssumeYes
+	defer func() { AssumeYes = original }()
+	AssumeYes = true
+
+	path := filepath.Join(t.TempDir(), "credentials")
+	require.NoError(t, os.WriteFile(path, nil, 0644))
+	require.NoError(t, os.Chmod(path, 0644))
+
+	require.NoError(t, preflightWrites(func() (string, error) { return path, nil }))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestPreflightWritesReadOnlyHome(t *testing.T) {
+	if runtime.GOOS == "windows" || os.Getuid() == 0 {
+		t.Skip("root can write read-only directories")
+	}
+
+	home := t.TempDir()
+	require.NoError(t, os.Chmod(home, 0500))
+	t.Cleanup(func() { os.Chmod(home, 0700) })
+
+	err := preflightWrites(func() (string, error) { return filepath.Join(home, ".kube", "config"), nil })
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), home)
+	assert.Contains(t, err.Error(), "--kubeconfig-path")
+}
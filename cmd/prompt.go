@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/andresgarcia29/ark-cli/lib/animation"
+	"github.com/spf13/cobra"
+)
+
+// requireStringFlag returns a required flag, prompting for it when it is missing
+// Values passed on the command line go through the same validator as prompted ones
+// Without a terminal it fails with an error naming the flag, so scripts get a precise message
+// Commands run by other programs (e.g. `ark kubernetes token`) keep cobra's MarkFlagRequired instead
+func requireStringFlag(cmd *cobra.Command, name, label string, options animation.InputOptions) (string, error) {
+	value, err := cmd.Flags().GetString(name)
+	if err != nil {
+		return "", err
+	}
+	if value != "" {
+		if options.Validate != nil {
+			if err := options.Validate(value); err != nil {
+				return "", fmt.Errorf("invalid --%s: %w", name, err)
+			}
+		}
+		return value, nil
+	}
+
+	if !animation.IsInteractive() {
+		return "", fmt.Errorf("required flag --%s not set", name)
+	}
+
+	value, err = animation.PromptInput(label, options)
+	if err != nil {
+		return "", fmt.Errorf("--%s: %w", name, err)
+	}
+	if value == "" {
+		return "", fmt.Errorf("required flag --%s not set", name)
+	}
+
+	// Keep the flag in sync so later lookups see the prompted value
+	if err := cmd.Flags().Set(name, value); err != nil {
+		return "", err
+	}
+	return value, nil
+}
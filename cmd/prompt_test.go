@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/andresgarcia29/ark-cli/lib/animation"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequireStringFlag(t *testing.T) {
+	cmd := &cobra.Command{Use: "test"}
+	cmd.Flags().String("profile", "", "AWS profile")
+
+	require.NoError(t, cmd.Flags().Set("profile", "dev"))
+	value, err := requireStringFlag(cmd, "profile", "AWS profile", animation.InputOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "dev", value)
+
+	_, err = requireStringFlag(cmd, "missing", "Missing", animation.InputOptions{})
+	assert.Error(t, err, "unknown flags are reported")
+
+	_, err = requireStringFlag(cmd, "profile", "AWS profile", animation.InputOptions{Validate: animation.ValidateAccountID})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid --profile")
+}
+
+func TestRequireStringFlagWithoutTerminal(t *testing.T) {
+	if animation.IsInteractive() {
+		t.Skip("stdin is a terminal")
+	}
+
+	cmd := &cobra.Command{Use: "test"}
+	cmd.Flags().String("start-url", "", "AWS SSO start URL")
+
+	_, err := requireStringFlag(cmd, "start-url", "AWS SSO start URL", animation.InputOptions{})
+	require.Error(t, err)
+	assert.Equal(t, "required flag --start-url not set", err.Error())
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	controllers "github.com/andresgarcia29/ark-cli/controllers/aws"
+	"github.com/andresgarcia29/ark-cli/lib/animation"
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	"github.com/spf13/cobra"
+)
+
+var (
+	regionsCmd = &cobra.Command{
+		Use:   "regions",
+		Short: "List the AWS regions enabled for the account",
+		Long: `List the regions enabled for the account of --profile (default: AWS_PROFILE or the default profile).
+With --latency, the STS endpoint of every region is probed and the regions are sorted fastest first,
+which helps pick --regions for setup and the region of new profiles.`,
+		Run: regions,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(regionsCmd)
+	regionsCmd.Flags().String("profile", "", "AWS profile of the account (default: AWS_PROFILE or the default profile)")
+	regionsCmd.Flags().Bool("latency", false, "Probe the STS endpoint of every region and sort the regions by round trip")
+	regionsCmd.Flags().Int("attempts", 3, "Round trips per region with --latency; the fastest is kept")
+	addTableFlags(regionsCmd, "")
+}
+
+func regions(cmd *cobra.Command, args []string) {
+	profile, _ := cmd.Flags().GetString("profile")
+	latency, _ := cmd.Flags().GetBool("latency")
+	attempts, _ := cmd.Flags().GetInt("attempts")
+
+	if attempts < 1 {
+		fmt.Println("Error: --attempts must be at least 1")
+		return
+	}
+
+	var results []controllers.RegionLatency
+	err := animation.ShowStatus(context.Background(), "Listing enabled regions", func(ctx context.Context, status func(string)) error {
+		lister, err := services_aws.NewRegionLister(ctx, profile)
+		if err != nil {
+			return err
+		}
+		enabled, err := lister.ListRegions(ctx)
+		if err != nil {
+			return err
+		}
+		if !latency {
+			for _, region := range enabled {
+				results = append(results, controllers.RegionLatency{Region: region})
+			}
+			return nil
+		}
+
+		prober, err := services_aws.NewLatencyProber(attempts)
+		if err != nil {
+			return err
+		}
+		status(fmt.Sprintf("Probing %d region(s)", len(enabled)))
+		results = controllers.ProbeRegions(ctx, enabled, prober.Probe)
+		return nil
+	})
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	if len(results) == 0 {
+		fmt.Println("No enabled regions found")
+		return
+	}
+	output, err := renderTable(cmd, buildRegionsTable(results, latency))
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	fmt.Print(output)
+}
+
+// buildRegionsTable lays out the regions, with their latency when it was probed
+func buildRegionsTable(results []controllers.RegionLatency, latency bool) *animation.Table {
+	headers := []string{"Region", "Enabled"}
+	if latency {
+		headers = append(headers, "Latency")
+	}
+	table := animation.NewTable(headers...)
+	for _, result := range results {
+		row := []string{result.Name, regionEnabled(result.OptInStatus)}
+		if latency {
+			row = append(row, formatLatency(result))
+		}
+		table.AddRow(row...)
+	}
+	return table
+}
+
+// regionEnabled describes why a region is enabled
+func regionEnabled(optInStatus string) string {
+	if optInStatus == "opted-in" {
+		return "opted in"
+	}
+	return "default"
+}
+
+// formatLatency right-aligns the round trip in milliseconds, so sorting the column as text keeps it numeric
+func formatLatency(result controllers.RegionLatency) string {
+	if result.Err != nil {
+		return "✗ unreachable"
+	}
+	return fmt.Sprintf("%5d ms", result.Latency.Round(time.Millisecond).Milliseconds())
+}
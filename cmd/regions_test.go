@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	controllers "github.com/andresgarcia29/ark-cli/controllers/aws"
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildRegionsTable(t *testing.T) {
+	results := []controllers.RegionLatency{
+		{Region: services_aws.Region{Name: "us-east-1", OptInStatus: "opt-in-not-required"}, Latency: 23400 * time.Microsecond},
+		{Region: services_aws.Region{Name: "ap-east-1", OptInStatus: "opted-in"}, Latency: 180 * time.Millisecond},
+		{Region: services_aws.Region{Name: "me-south-1", OptInStatus: "opted-in"}, Err: errors.New("timeout")},
+	}
+
+	t.Run("without latency", func(t *testing.T) {
+		table := buildRegionsTable(results, false)
+		assert.Equal(t, [][]string{
+			{"us-east-1", "default"},
+			{"ap-east-1", "opted in"},
+			{"me-south-1", "opted in"},
+		}, table.Rows)
+	})
+
+	t.Run("with latency", func(t *testing.T) {
+		table := buildRegionsTable(results, true)
+		assert.Equal(t, [][]string{
+			{"us-east-1", "default", "   23 ms"},
+			{"ap-east-1", "opted in", "  180 ms"},
+			{"me-south-1", "opted in", "✗ unreachable"},
+		}, table.Rows)
+
+		assert.NoError(t, table.SortBy(table.ColumnIndex("latency"), true))
+		assert.Equal(t, "me-south-1", table.Rows[0][0])
+		assert.Equal(t, "ap-east-1", table.Rows[1][0], "latencies sort numerically")
+	})
+}
@@ -1,38 +1,136 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"time"
 
+	ark_config "github.com/andresgarcia29/ark-cli/config"
+	"github.com/andresgarcia29/ark-cli/lib"
+	"github.com/andresgarcia29/ark-cli/lib/animation"
 	"github.com/andresgarcia29/ark-cli/logs"
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var (
-	LogLevel bool
+	LogLevel           bool
+	AssumeYes          bool
+	Verbose            bool
+	AWSConfigFile      string
+	AWSCredentialsFile string
+	ProgressMode       string
+	Timeout            time.Duration
+	Parallelism        int
+	PrintURLOnly       bool
+
+	runStartedAt time.Time
 
 	rootCmd = &cobra.Command{
 		Use:   "ark",
-		Short: "A powerful CLI tool for various operations",
-		Long: `ark is a modern CLI application built with Cobra and Go.
-It provides a clean and efficient way to interact with various services and perform common tasks.
-
-Example usage:
-  ark aws          # AWS related operations
-  ark kubernetes   # Kubernetes, aliases: k8s, eks
-  ark version      # Show version information
-  ark --help       # Show help information`,
+		Short: "Sign in to AWS SSO and manage EKS contexts across every account",
+		Long: `ark signs in to AWS SSO, writes a profile for every account and role of the portal, discovers the EKS
+clusters of every account and keeps them in kubeconfig, so switching accounts and clusters is one command.
+
+Common commands:
+  ark bootstrap    # Write a profile per account and role of an SSO portal
+  ark aws          # Pick an AWS profile and log in
+  ark kubernetes   # Pick a Kubernetes context and switch to it, aliases: k8s, eks
+  ark ctx [query]  # Switch Kubernetes context (- for the previous one)
+  ark env          # Export the environment declared by a .ark file
+  ark config view  # Show ark's configuration
+  ark version      # Show version information`,
 		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			runStartedAt = time.Now()
+			if err := applyFlagEnv(cmd, os.Getenv); err != nil {
+				fmt.Println("Error:", err)
+				os.Exit(1)
+			}
+			if err := animation.SetProgressMode(ProgressMode); err != nil {
+				fmt.Println("Error:", err)
+				os.Exit(1)
+			}
+			if err := lib.SetParallelOverrides(Timeout, Parallelism); err != nil {
+				fmt.Println("Error:", err)
+				os.Exit(1)
+			}
 			initializeLogger()
+			applyAWSFileOverrides()
+			lib.ConfigureBrowser(ark_config.Get().BrowserCommand, PrintURLOnly)
+		},
+		PersistentPostRun: func(cmd *cobra.Command, args []string) {
+			if Verbose {
+				printAPICallStats(time.Since(runStartedAt))
+			}
 		},
 	}
 )
 
 func init() {
 	rootCmd.PersistentFlags().BoolVarP(&LogLevel, "debug", "d", false, "Set the log level to debug")
+	rootCmd.PersistentFlags().BoolVarP(&AssumeYes, "yes", "y", false, "Skip confirmation prompts (for automation)")
+	rootCmd.PersistentFlags().BoolVarP(&Verbose, "verbose", "v", false, "Print AWS API call statistics when the command finishes")
+	rootCmd.PersistentFlags().StringVar(&AWSConfigFile, "aws-config", "", "AWS config file to use instead of ~/.aws/config (default: $AWS_CONFIG_FILE)")
+	rootCmd.PersistentFlags().StringVar(&AWSCredentialsFile, "aws-credentials", "", "AWS credentials file to use instead of ~/.aws/credentials (default: $AWS_SHARED_CREDENTIALS_FILE)")
+	rootCmd.PersistentFlags().StringVar(&ProgressMode, "progress", "", "Progress output: tui, plain (one line per event) or json (JSON lines) (default: tui in a terminal, plain otherwise)")
+	rootCmd.PersistentFlags().DurationVar(&Timeout, "timeout", 0, "Time each parallel phase (account scans, credential fetches) has to finish (default: the phase's own budget)")
+	rootCmd.PersistentFlags().IntVar(&Parallelism, "parallelism", 0, "How many accounts or regions each parallel phase works on at once (default: the phase's own limit)")
+	rootCmd.PersistentFlags().BoolVar(&PrintURLOnly, "print-url-only", false, "Print sign-in URLs instead of opening a browser, to approve them on another device (e.g. on a remote machine)")
+
+	services_aws.Version = Version
+}
+
+// flagEnvVars are the environment variables read for flags not given on the command line, in order
+// It is an allowlist: variables ark exports itself, such as ARK_CLUSTER from ark env, must never reach a flag,
+// and neither may switches like --yes or --debug that change what a command does
+var flagEnvVars = map[string][]string{
+	"start-url":       {"ARK_START_URL", "ARK_SSO_START_URL"},
+	"sso-region":      {"ARK_SSO_REGION"},
+	"regions":         {"ARK_REGIONS"},
+	"role-prefixs":    {"ARK_ROLE_PREFIXS"},
+	"kubeconfig-path": {"ARK_KUBECONFIG_PATH"},
+	"progress":        {"ARK_PROGRESS"},
+	"parallelism":     {"ARK_PARALLELISM"},
+	"print-url-only":  {"ARK_PRINT_URL_ONLY"},
+}
+
+// applyFlagEnv fills the flags not given on the command line from their variables in flagEnvVars,
+// so containers and CI runs can configure ark without flags or files. Flags given explicitly always win
+func applyFlagEnv(cmd *cobra.Command, getenv func(string) string) error {
+	var errs []error
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if flag.Changed {
+			return
+		}
+		for _, env := range flagEnvVars[flag.Name] {
+			value := getenv(env)
+			if value == "" {
+				continue
+			}
+			if err := cmd.Flags().Set(flag.Name, value); err != nil {
+				errs = append(errs, fmt.Errorf("invalid %s: %w", env, err))
+			}
+			return
+		}
+	})
+	return errors.Join(errs...)
 }
 
 func Execute() {
+	addExamples(rootCmd)
+
+	// Expand user-defined aliases before cobra looks up the command
+	if len(os.Args) > 1 && !isBuiltinCommand(rootCmd, os.Args[1]) {
+		args, err := expandAlias(rootCmd, os.Args[1:], ark_config.Get().Aliases)
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		rootCmd.SetArgs(args)
+	}
+
 	// First, execute the command to parse flags
 	err := rootCmd.Execute()
 	if err != nil {
@@ -42,25 +140,45 @@ func Execute() {
 
 // initializeLogger initializes the logger with the current LogLevel setting
 func initializeLogger() {
-	logLevelName := "error"
 	if LogLevel {
-		fmt.Printf("Setting log level to debug\n")
-		logLevelName = "debug"
+		fmt.Fprintln(os.Stderr, "Setting log level to debug")
 	}
 
-	if err := logs.InitLogger(logs.LogConfig{
-		Level:      logLevelName,
-		Format:     "console",
-		OutputPath: "stdout",
-	}); err != nil {
-		fmt.Printf("Failed to initialize logger: %v\n", err)
+	if err := logs.InitLogger(loggerConfig(LogLevel)); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize logger: %v\n", err)
 		os.Exit(1)
 	}
 
 	// Verify logger is working
 	logger := logs.GetLogger()
 	if logger == nil {
-		fmt.Printf("Failed to get logger instance\n")
+		fmt.Fprintf(os.Stderr, "Failed to get logger instance\n")
 		os.Exit(1)
 	}
 }
+
+// loggerConfig returns the logger configuration of the command
+// Logs go to stderr: stdout carries what commands print for other programs, e.g. the ExecCredential that
+// kubectl parses from ark kubernetes token, or the JSON of --format json
+func loggerConfig(debug bool) logs.LogConfig {
+	level := "error"
+	if debug {
+		level = "debug"
+	}
+	return logs.LogConfig{
+		Level:      level,
+		Format:     "console",
+		OutputPath: "stderr",
+	}
+}
+
+// applyAWSFileOverrides exports --aws-config and --aws-credentials through the standard AWS variables,
+// so ark, the AWS SDK and the aws CLI commands started by ark all read the same files
+func applyAWSFileOverrides() {
+	if AWSConfigFile != "" {
+		os.Setenv(services_aws.ConfigFileEnv, AWSConfigFile)
+	}
+	if AWSCredentialsFile != "" {
+		os.Setenv(services_aws.CredentialsFileEnv, AWSCredentialsFile)
+	}
+}
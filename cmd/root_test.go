@@ -1,8 +1,10 @@
 package cmd
 
 import (
+	"os"
 	"testing"
 
+	services_environment "github.com/andresgarcia29/ark-cli/services/environment"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -152,6 +154,23 @@ func TestRootCommandFlags(t *testing.T) {
 	assert.Equal(t, "Set the log level to debug", debugFlag.Usage)
 }
 
+func TestApplyAWSFileOverrides(t *testing.T) {
+	t.Setenv("AWS_CONFIG_FILE", "/from/env/config")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", "/from/env/credentials")
+	defer func() { AWSConfigFile, AWSCredentialsFile = "", "" }()
+
+	// Without flags the environment is left alone
+	AWSConfigFile, AWSCredentialsFile = "", ""
+	applyAWSFileOverrides()
+	assert.Equal(t, "/from/env/config", os.Getenv("AWS_CONFIG_FILE"))
+	assert.Equal(t, "/from/env/credentials", os.Getenv("AWS_SHARED_CREDENTIALS_FILE"))
+
+	AWSConfigFile, AWSCredentialsFile = "/from/flag/config", "/from/flag/credentials"
+	applyAWSFileOverrides()
+	assert.Equal(t, "/from/flag/config", os.Getenv("AWS_CONFIG_FILE"))
+	assert.Equal(t, "/from/flag/credentials", os.Getenv("AWS_SHARED_CREDENTIALS_FILE"))
+}
+
 func TestRootCommandSubcommands(t *testing.T) {
 	// Test that subcommands are properly added
 	// This is more of an integration test, but useful to verify structure
@@ -166,3 +185,69 @@ func TestRootCommandSubcommands(t *testing.T) {
 	// The exact number depends on what's initialized, but we expect some commands
 	assert.GreaterOrEqual(t, len(subcommands), 0)
 }
+
+func TestApplyFlagEnv(t *testing.T) {
+	var assumeYes bool
+	newCmd := func() *cobra.Command {
+		cmd := &cobra.Command{Use: "sso", Run: func(*cobra.Command, []string) {}}
+		cmd.Flags().String("start-url", "", "")
+		cmd.Flags().String("region", "us-east-1", "")
+		cmd.Flags().StringSlice("regions", nil, "")
+		cmd.Flags().Int("parallelism", 0, "")
+		cmd.Flags().BoolVarP(&assumeYes, "yes", "y", false, "")
+		return cmd
+	}
+	env := map[string]string{
+		"ARK_SSO_START_URL": "https://alias.awsapps.com/start",
+		"ARK_REGION":        "eu-west-1",
+		"ARK_REGIONS":       "us-east-1,eu-west-1",
+		"ARK_YES":           "true",
+	}
+	getenv := func(name string) string { return env[name] }
+
+	cmd := newCmd()
+	require.NoError(t, cmd.ParseFlags([]string{"--regions", "us-west-2"}))
+	require.NoError(t, applyFlagEnv(cmd, getenv))
+	startURL, _ := cmd.Flags().GetString("start-url")
+	region, _ := cmd.Flags().GetString("region")
+	regions, _ := cmd.Flags().GetStringSlice("regions")
+	parallelism, _ := cmd.Flags().GetInt("parallelism")
+	assert.Equal(t, "https://alias.awsapps.com/start", startURL, "aliases are read too")
+	assert.Equal(t, []string{"us-west-2"}, regions, "flags on the command line win")
+	assert.Equal(t, "us-east-1", region, "flags missing from the allowlist ignore ARK_<FLAG>")
+	assert.Equal(t, 0, parallelism, "flags without a variable keep their default")
+	assert.False(t, assumeYes, "ARK_YES never skips confirmations")
+
+	// ARK_START_URL wins over the alias
+	env["ARK_START_URL"] = "https://primary.awsapps.com/start"
+	cmd = newCmd()
+	require.NoError(t, applyFlagEnv(cmd, getenv))
+	startURL, _ = cmd.Flags().GetString("start-url")
+	assert.Equal(t, "https://primary.awsapps.com/start", startURL)
+
+	env["ARK_PARALLELISM"] = "many"
+	assert.ErrorContains(t, applyFlagEnv(newCmd(), getenv), "invalid ARK_PARALLELISM")
+}
+
+func TestApplyFlagEnvIgnoresArkEnvExports(t *testing.T) {
+	project := &services_environment.ProjectConfig{Profile: "prod", Region: "eu-west-1", Cluster: "prod-eks", Path: "/repo/.ark"}
+	env := map[string]string{}
+	for _, v := range project.EnvVars() {
+		env[v.Name] = v.Value
+	}
+	require.Equal(t, "prod-eks", env["ARK_CLUSTER"], "ark env exports ARK_CLUSTER")
+
+	cmd := &cobra.Command{Use: "shell", Run: func(*cobra.Command, []string) {}}
+	cmd.Flags().AddFlagSet(shellCmd.Flags())
+	require.NoError(t, applyFlagEnv(cmd, func(name string) string { return env[name] }))
+	for _, name := range []string{"cluster", "profile", "region", "kubeconfig", "shell"} {
+		assert.False(t, cmd.Flags().Changed(name), "--%s is not set by ark env's variables", name)
+	}
+}
+
+func TestLoggerConfig(t *testing.T) {
+	// kubectl parses the stdout of ark kubernetes token as an ExecCredential, so logs must never go there
+	assert.Equal(t, "stderr", loggerConfig(true).OutputPath)
+	assert.Equal(t, "debug", loggerConfig(true).Level)
+	assert.Equal(t, "error", loggerConfig(false).Level)
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	services_environment "github.com/andresgarcia29/ark-cli/services/environment"
+	"github.com/spf13/cobra"
+)
+
+var (
+	shellCmd = &cobra.Command{
+		Use:   "shell",
+		Short: "Start a subshell with an AWS profile and Kubernetes context injected",
+		Long: `Start a subshell with AWS_PROFILE, AWS_REGION and an isolated KUBECONFIG injected, and a
+prompt marker showing the active profile and cluster. Exiting the subshell restores the original
+environment. Values default to the nearest .ark file when flags are omitted.
+
+Example usage:
+  ark shell --profile prod-readonly --cluster prod
+  ark shell                         # Use the .ark file of the current directory`,
+		Run: shellCommand,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(shellCmd)
+	shellCmd.Flags().String("profile", "", "AWS profile to export in the subshell")
+	shellCmd.Flags().String("region", "", "AWS region to export in the subshell")
+	shellCmd.Flags().String("cluster", "", "Kubernetes context to select in the subshell")
+	shellCmd.Flags().String("kubeconfig", "", "Kubeconfig to copy into the subshell (default: current KUBECONFIG)")
+	shellCmd.Flags().String("shell", "", "Shell binary to start (default: $SHELL)")
+}
+
+func shellCommand(cmd *cobra.Command, args []string) {
+	opts := services_environment.ShellOptions{}
+	opts.Profile, _ = cmd.Flags().GetString("profile")
+	opts.Region, _ = cmd.Flags().GetString("region")
+	opts.Cluster, _ = cmd.Flags().GetString("cluster")
+	opts.Kubeconfig, _ = cmd.Flags().GetString("kubeconfig")
+	opts.Shell, _ = cmd.Flags().GetString("shell")
+
+	if cwd, err := os.Getwd(); err == nil {
+		project, err := services_environment.LoadProjectForDir(cwd)
+		if err == nil && project != nil {
+			if trustErr := services_environment.CheckProjectTrust(project); trustErr != nil {
+				fmt.Printf("⚠️  Ignoring project file: %v (run `ark env allow` to use it)\n", trustErr)
+				project = nil
+			}
+		}
+		if err != nil {
+			fmt.Printf("⚠️  Ignoring project file: %v\n", err)
+		} else {
+			applyProjectDefaults(&opts, project)
+		}
+	}
+
+	if level := services_environment.CurrentShellLevel(); level > 0 {
+		fmt.Printf("⚠️  Already inside an ark shell (level %d), starting a nested session\n", level)
+	}
+
+	session, err := services_environment.PrepareShellSession(opts)
+	if err != nil {
+		fmt.Printf("❌ Failed to prepare shell: %v\n", err)
+		return
+	}
+	defer session.Cleanup()
+
+	fmt.Printf("🐚 Starting ark shell (level %d). Type 'exit' to return.\n", session.Level)
+
+	shell := exec.Command(session.Path, session.Args...)
+	shell.Env = session.Env
+	shell.Stdin = os.Stdin
+	shell.Stdout = os.Stdout
+	shell.Stderr = os.Stderr
+
+	if err := shell.Run(); err != nil {
+		// A non-zero exit from the last command typed in the shell is not an ark failure
+		if _, ok := err.(*exec.ExitError); !ok {
+			fmt.Printf("❌ Shell failed: %v\n", err)
+			return
+		}
+	}
+
+	fmt.Println("👋 Left ark shell, environment restored")
+}
+
+// applyProjectDefaults fills unset shell options from a .ark project file
+func applyProjectDefaults(opts *services_environment.ShellOptions, project *services_environment.ProjectConfig) {
+	if project == nil {
+		return
+	}
+	if opts.Profile == "" {
+		opts.Profile = project.Profile
+	}
+	if opts.Region == "" {
+		opts.Region = project.Region
+	}
+	if opts.Cluster == "" {
+		opts.Cluster = project.Cluster
+	}
+	if opts.Kubeconfig == "" {
+		opts.Kubeconfig = project.Kubeconfig
+	}
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	ark_config "github.com/andresgarcia29/ark-cli/config"
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	services_kubernetes "github.com/andresgarcia29/ark-cli/services/kubernetes"
+	"github.com/spf13/cobra"
+)
+
+var (
+	statusCmd = &cobra.Command{
+		Use:   "status",
+		Short: "Show the active AWS profile, its expiry and the kube context",
+		Long: `Show the AWS profile in use (AWS_PROFILE, or the profile ark last copied to [default]), its account,
+when its credentials expire and the current kube context. Everything is read from local files and caches,
+never from AWS or kubectl, so it is fast enough for a shell prompt: --format starship prints a single line
+for starship or powerlevel10k, and prints nothing rather than an error.`,
+		Args: cobra.NoArgs,
+		Run:  statusCommand,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+	statusCmd.Flags().String("format", "text", "Output format: text, or starship for a single prompt line")
+}
+
+func statusCommand(cmd *cobra.Command, args []string) {
+	format, _ := cmd.Flags().GetString("format")
+	if format != "text" && format != "starship" {
+		fmt.Printf("Error: unknown format %q (text or starship)\n", format)
+		return
+	}
+
+	profileName := os.Getenv("AWS_PROFILE")
+	if profileName == "" {
+		profileName = "default"
+	}
+	status, err := services_aws.ReadCredentialStatus(profileName)
+	if err != nil && format == "text" {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	skew := ark_config.Get().AWS.ClockSkew
+	kubeContext := ""
+	if kubeconfig, err := services_kubernetes.LoadMergedKubeconfig(); err == nil {
+		kubeContext = kubeconfig.CurrentContext
+	}
+
+	if format == "starship" {
+		if line := formatStatusLine(status, kubeContext, skew); line != "" {
+			fmt.Println(line)
+		}
+		return
+	}
+	fmt.Print(formatStatusText(status, kubeContext, skew))
+}
+
+// formatStatusLine renders the prompt segment, e.g. "payments-prod-readonly (payments-prod) 42:10 ⎈ payments-prod"
+func formatStatusLine(status *services_aws.CredentialStatus, kubeContext string, skew time.Duration) string {
+	var parts []string
+	if status != nil {
+		parts = append(parts, status.Profile)
+		if status.AccountName != "" {
+			parts = append(parts, "("+status.AccountName+")")
+		}
+		if !status.Expiration.IsZero() {
+			parts = append(parts, formatRemaining(statusRemaining(status.Expiration, skew)))
+		}
+	}
+	if kubeContext != "" {
+		parts = append(parts, "⎈ "+kubeContext)
+	}
+	return strings.Join(parts, " ")
+}
+
+// formatStatusText renders the status for people, one line per item
+func formatStatusText(status *services_aws.CredentialStatus, kubeContext string, skew time.Duration) string {
+	var s strings.Builder
+	if status == nil {
+		s.WriteString("Profile:  none (run `ark aws` to log in)\n")
+	} else {
+		fmt.Fprintf(&s, "Profile:  %s\n", status.Profile)
+		switch {
+		case status.AccountName != "":
+			fmt.Fprintf(&s, "Account:  %s (%s)\n", status.AccountName, status.AccountID)
+		case status.AccountID != "":
+			fmt.Fprintf(&s, "Account:  %s\n", status.AccountID)
+		}
+		if !status.Expiration.IsZero() {
+			fmt.Fprintf(&s, "Expires:  %s (%s)\n", formatRemaining(statusRemaining(status.Expiration, skew)), status.Expiration.Local().Format("15:04"))
+		}
+	}
+	if kubeContext == "" {
+		kubeContext = "none"
+	}
+	fmt.Fprintf(&s, "Context:  %s\n", kubeContext)
+	return s.String()
+}
+
+// statusRemaining returns the time left until expiration, zero within its last aws.clock_skew, when ark already
+// treats the credentials as expired
+func statusRemaining(expiration time.Time, skew time.Duration) time.Duration {
+	creds := &services_aws.Credentials{Expiration: expiration.UnixMilli()}
+	if creds.IsExpired(skew) {
+		return 0
+	}
+	return creds.TimeRemaining()
+}
+
+// formatRemaining renders the time left as mm:ss, minutes going past 59 for long sessions, or "expired"
+func formatRemaining(remaining time.Duration) string {
+	if remaining <= 0 {
+		return "expired"
+	}
+	seconds := int(remaining.Seconds())
+	return fmt.Sprintf("%02d:%02d", seconds/60, seconds%60)
+}
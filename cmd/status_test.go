@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatStatusLine(t *testing.T) {
+	status := &services_aws.CredentialStatus{
+		Profile:     "payments-prod-readonly",
+		AccountID:   "222222222222",
+		AccountName: "payments-prod",
+		// Half a second of margin for the time the test takes, remaining seconds being rounded down
+		Expiration: time.Now().Add(42*time.Minute + 10*time.Second + 500*time.Millisecond),
+	}
+
+	assert.Equal(t, "payments-prod-readonly (payments-prod) 42:10 ⎈ payments-prod", formatStatusLine(status, "payments-prod", time.Minute))
+	assert.Equal(t, "static", formatStatusLine(&services_aws.CredentialStatus{Profile: "static"}, "", time.Minute))
+	assert.Equal(t, "⎈ dev", formatStatusLine(nil, "dev", time.Minute))
+	assert.Empty(t, formatStatusLine(nil, "", time.Minute), "nothing to show prints nothing")
+}
+
+func TestFormatStatusText(t *testing.T) {
+	now := time.Now()
+	status := &services_aws.CredentialStatus{Profile: "legacy", AccountID: "333333333333", Expiration: now.Add(-time.Minute)}
+
+	assert.Equal(t, "Profile:  legacy\nAccount:  333333333333\nExpires:  expired ("+now.Add(-time.Minute).Local().Format("15:04")+")\nContext:  dev\n",
+		formatStatusText(status, "dev", time.Minute))
+	assert.Equal(t, "Profile:  none (run `ark aws` to log in)\nContext:  none\n", formatStatusText(nil, "", time.Minute))
+
+	// Within aws.clock_skew of the expiration, credentials show as expired, as ark prunes and replaces them
+	status = &services_aws.CredentialStatus{Profile: "legacy", Expiration: now.Add(30 * time.Second)}
+	assert.Contains(t, formatStatusText(status, "", time.Minute), "Expires:  expired")
+	assert.NotContains(t, formatStatusText(status, "", 0), "Expires:  expired")
+}
+
+func TestFormatRemaining(t *testing.T) {
+	assert.Equal(t, "05:07", formatRemaining(5*time.Minute+7*time.Second))
+	assert.Equal(t, "90:00", formatRemaining(90*time.Minute), "minutes go past 59")
+	assert.Equal(t, "expired", formatRemaining(0))
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	controllers "github.com/andresgarcia29/ark-cli/controllers/aws"
+	"github.com/andresgarcia29/ark-cli/lib/animation"
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	"github.com/spf13/cobra"
+)
+
+var (
+	switchCmd = &cobra.Command{
+		Use:   "switch",
+		Short: "Switch to another account and role with the cached SSO token",
+		Long: `Switch to another account and role of an SSO portal you are signed in to, without a browser: the
+credentials come from the cached SSO token and are written under the profile of the account and role (or
+the name ark aws sso would give it) and, unless --set-default=false, to the [default] profile.
+Fails when the portal has no valid cached token; sign in with ark aws sso first.`,
+		Run: switchCommand,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(switchCmd)
+	switchCmd.Flags().String("account", "", "AWS account ID to switch to (prompted when missing)")
+	switchCmd.Flags().String("role", "", "SSO role (permission set) to switch to (prompted when missing)")
+	switchCmd.Flags().String("start-url", "", "AWS SSO start URL when no profile has the account and role (default: the one of the SSO profiles)")
+	switchCmd.Flags().String("sso-region", "", "AWS SSO region when no profile has the account and role (default: the one of the SSO profiles, or us-east-1)")
+	switchCmd.Flags().Bool("set-default", true, "Also write the credentials to the [default] profile")
+}
+
+func switchCommand(cmd *cobra.Command, args []string) {
+	var err error
+	sw := controllers.AccountSwitch{}
+	sw.AccountID, err = requireStringFlag(cmd, "account", "AWS account ID", animation.InputOptions{Placeholder: "111111111111"})
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	sw.RoleName, err = requireStringFlag(cmd, "role", "SSO role", animation.InputOptions{Placeholder: "ReadOnlyAccess"})
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	sw.SetAsDefault, _ = cmd.Flags().GetBool("set-default")
+	startURL, _ := cmd.Flags().GetString("start-url")
+	ssoRegion, _ := cmd.Flags().GetString("sso-region")
+
+	portals, err := services_aws.ConfiguredSSOPortals()
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	// Without a portal the account and role must have a profile, which names its own portal
+	if portal, err := chooseSSOPortal(startURL, ssoRegion, portals); err == nil {
+		sw.Portal = portal
+	}
+
+	if sw.SetAsDefault && !guardDefaultCredentials() {
+		return
+	}
+	if err := preflightWrites(services_aws.CredentialsFilePath); err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	profileName, err := controllers.SwitchAccount(context.Background(), sw)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	fmt.Printf("✓ Switched to account %s with role %s (profile '%s')\n", sw.AccountID, sw.RoleName, profileName)
+	fmt.Println(switchEnvHint(profileName, sw.SetAsDefault, os.Getenv("AWS_PROFILE")))
+}
+
+// switchEnvHint tells how the shell picks up the new credentials: AWS_PROFILE wins over [default]
+func switchEnvHint(profileName string, setAsDefault bool, awsProfile string) string {
+	switch {
+	case awsProfile == profileName:
+		return "The credentials of AWS_PROFILE were refreshed"
+	case setAsDefault && awsProfile == "":
+		return "Commands without a profile now use these credentials"
+	default:
+		return fmt.Sprintf("💡 In this shell: export AWS_PROFILE=%s", profileName)
+	}
+}
@@ -0,0 +1,15 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSwitchEnvHint(t *testing.T) {
+	assert.Equal(t, "The credentials of AWS_PROFILE were refreshed", switchEnvHint("dev-readonly", true, "dev-readonly"))
+	assert.Equal(t, "Commands without a profile now use these credentials", switchEnvHint("dev-readonly", true, ""))
+	// AWS_PROFILE wins over [default], so it has to change too
+	assert.Equal(t, "💡 In this shell: export AWS_PROFILE=dev-readonly", switchEnvHint("dev-readonly", true, "prod-readonly"))
+	assert.Equal(t, "💡 In this shell: export AWS_PROFILE=dev-readonly", switchEnvHint("dev-readonly", false, ""))
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/andresgarcia29/ark-cli/lib/animation"
+	"github.com/spf13/cobra"
+)
+
+// addTableFlags registers the sorting and pagination flags shared by listing commands
+func addTableFlags(cmd *cobra.Command, defaultSort string) {
+	cmd.Flags().String("sort", defaultSort, "Column to sort by")
+	cmd.Flags().Bool("desc", false, "Sort in descending order")
+	cmd.Flags().Int("page", 1, "Page to show")
+	cmd.Flags().Int("page-size", 0, "Rows per page (0 shows every row)")
+}
+
+// renderTable sorts and paginates a table according to the flags registered by addTableFlags
+func renderTable(cmd *cobra.Command, table *animation.Table) (string, error) {
+	sortBy, _ := cmd.Flags().GetString("sort")
+	descending, _ := cmd.Flags().GetBool("desc")
+	page, _ := cmd.Flags().GetInt("page")
+	pageSize, _ := cmd.Flags().GetInt("page-size")
+
+	if sortBy != "" {
+		column := table.ColumnIndex(sortBy)
+		if column < 0 {
+			return "", fmt.Errorf("unknown sort column %q", sortBy)
+		}
+		if err := table.SortBy(column, descending); err != nil {
+			return "", err
+		}
+	}
+
+	return table.RenderPage(page, pageSize)
+}
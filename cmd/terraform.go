@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	"github.com/spf13/cobra"
+)
+
+var (
+	terraformCmd = &cobra.Command{
+		Use:     "terraform",
+		Aliases: []string{"tofu"},
+		Short:   "Wire Terraform and OpenTofu to ark's profiles",
+		Long:    `Generate Terraform and OpenTofu configuration from the profiles of ~/.aws/config, so IaC uses the same profile names as ark.`,
+	}
+
+	terraformProvidersCmd = &cobra.Command{
+		Use:   "providers",
+		Short: "Print an aliased aws provider block per account",
+		Long: `Print an aws provider block with an alias, profile and region for every account of ~/.aws/config matching
+--accounts, one profile per account. When an account has several matching profiles, pick the role with --role.
+Each block sets allowed_account_ids, so Terraform refuses to run if the profile signs in to another account.
+Accounts are matched by ID, or by the name cached by ark aws sso.`,
+		Args: cobra.NoArgs,
+		Run:  terraformProviders,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(terraformCmd)
+	terraformCmd.AddCommand(terraformProvidersCmd)
+	terraformProvidersCmd.Flags().StringSlice("accounts", nil, "Accounts to generate providers for, by ID or name, with globs, e.g. 111111111111,'payments-*' (default: every account)")
+	terraformProvidersCmd.Flags().StringSlice("role", nil, "Role names whose profile each provider uses, with globs, e.g. 'Admin*' (needed when an account has several profiles)")
+	terraformProvidersCmd.Flags().String("region", "", "Region of every provider (default: the region of each profile, else us-east-1)")
+	terraformProvidersCmd.Flags().String("output", "", "File to write the providers to, e.g. providers.tf (default: stdout)")
+}
+
+func terraformProviders(cmd *cobra.Command, args []string) {
+	accounts, _ := cmd.Flags().GetStringSlice("accounts")
+	roles, _ := cmd.Flags().GetStringSlice("role")
+	region, _ := cmd.Flags().GetString("region")
+	output, _ := cmd.Flags().GetString("output")
+
+	// Errors go to stderr, so `ark terraform providers > providers.tf` never writes one into the file
+	profiles, err := services_aws.ReadAllProfilesFromConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+	providers, err := services_aws.TerraformProviders(profiles, accounts, roles, region)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+	if len(providers) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: no profiles match --accounts and --role")
+		os.Exit(1)
+	}
+
+	hcl := services_aws.RenderTerraformProviders(providers)
+	if output == "" {
+		fmt.Print(hcl)
+		return
+	}
+	if err := os.WriteFile(output, []byte(hcl), 0644); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+	fmt.Printf("✓ Wrote %d provider(s) to %s\n", len(providers), output)
+}
@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/andresgarcia29/ark-cli/lib/animation"
+	"github.com/andresgarcia29/ark-cli/paths"
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	services_kubernetes "github.com/andresgarcia29/ark-cli/services/kubernetes"
+	"github.com/spf13/cobra"
+)
+
+var (
+	uninstallDataCmd = &cobra.Command{
+		Use:   "uninstall-data",
+		Short: "Remove everything ark wrote on this machine",
+		Long: `Remove what ark wrote on this machine, to offboard it cleanly: the kubeconfig contexts written by
+ark kubernetes setup (marked with the ark extension), the AWS config profiles and credentials marked
+"# managed by ark", the SSO tokens ark cached in ~/.aws/sso/cache, and ark's config, cache, state and log
+directories. Contexts, profiles and tokens written by hand or by other tools are left alone. Everything is
+listed and confirmed before anything is removed; --dry-run only lists it.`,
+		Args: cobra.NoArgs,
+		Run:  uninstallData,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(uninstallDataCmd)
+	uninstallDataCmd.Flags().Bool("dry-run", false, "List what would be removed without removing it")
+}
+
+// uninstallPlan is everything ark wrote and uninstall-data removes
+type uninstallPlan struct {
+	// Contexts maps each kubeconfig file to the ark contexts in it
+	Contexts    map[string][]string
+	Profiles    []string
+	Credentials []string
+	TokenFiles  []string
+	Directories []string
+}
+
+// empty reports whether ark left nothing to remove
+func (p uninstallPlan) empty() bool {
+	return len(p.Contexts) == 0 && len(p.Profiles) == 0 && len(p.Credentials) == 0 &&
+		len(p.TokenFiles) == 0 && len(p.Directories) == 0
+}
+
+func uninstallData(cmd *cobra.Command, args []string) {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	kubeconfigPaths, err := uninstallKubeconfigPaths()
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	plan, err := runUninstall(kubeconfigPaths, true)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	if plan.empty() {
+		fmt.Println("Nothing to remove: ark left no data on this machine")
+		return
+	}
+
+	fmt.Print(formatUninstallPlan(plan))
+	if dryRun {
+		return
+	}
+
+	confirmed, err := confirmAction("Remove everything listed above?", animation.ConfirmOptions{
+		Details:     []string{"This can't be undone; AWS profiles have to be bootstrapped and contexts set up again"},
+		Destructive: true,
+	})
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	if !confirmed {
+		fmt.Println("Aborted: nothing was removed")
+		return
+	}
+
+	if _, err := runUninstall(kubeconfigPaths, false); err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	fmt.Println("✓ ark's data was removed; the ark binary itself is left for your package manager")
+}
+
+// runUninstall lists, or removes unless dryRun, everything ark wrote
+// Directories go last, so a failure earlier leaves the caches the other steps may still need
+func runUninstall(kubeconfigPaths []string, dryRun bool) (uninstallPlan, error) {
+	plan := uninstallPlan{Contexts: make(map[string][]string)}
+	for _, path := range kubeconfigPaths {
+		removed, err := services_kubernetes.RemoveManagedContextsFromFile(path, dryRun)
+		if err != nil {
+			return plan, err
+		}
+		if len(removed) > 0 {
+			plan.Contexts[path] = removed
+		}
+	}
+
+	var err error
+	if plan.Profiles, err = services_aws.RemoveArkProfiles(dryRun); err != nil {
+		return plan, err
+	}
+	if plan.Credentials, err = services_aws.RemoveArkCredentials(dryRun); err != nil {
+		return plan, err
+	}
+	if plan.TokenFiles, err = services_aws.RemoveCachedTokens(dryRun); err != nil {
+		return plan, err
+	}
+
+	dirs, err := paths.Get()
+	if err != nil {
+		return plan, err
+	}
+	plan.Directories = arkDirectories(dirs)
+	if dryRun {
+		return plan, nil
+	}
+	for _, dir := range plan.Directories {
+		if err := os.RemoveAll(dir); err != nil {
+			return plan, fmt.Errorf("failed to remove %s: %w", dir, err)
+		}
+	}
+	return plan, nil
+}
+
+// uninstallKubeconfigPaths returns every kubeconfig ark may have written contexts to: the files of KUBECONFIG
+// (or ~/.kube/config) and the file setup writes to
+func uninstallKubeconfigPaths() ([]string, error) {
+	kubeconfigPaths, err := services_kubernetes.KubeconfigPaths()
+	if err != nil {
+		return nil, err
+	}
+	target, err := services_kubernetes.DefaultKubeconfigPath()
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range kubeconfigPaths {
+		if path == target {
+			return kubeconfigPaths, nil
+		}
+	}
+	return append(kubeconfigPaths, target), nil
+}
+
+// arkDirectories returns ark's directories that exist, leaving out the ones inside another
+// (the legacy ~/.ark holds the cache and state directories)
+func arkDirectories(dirs paths.Dirs) []string {
+	var existing []string
+	for _, dir := range []string{dirs.Config, dirs.Cache, dirs.State, dirs.Logs} {
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			continue
+		}
+		nested := false
+		for _, other := range []string{dirs.Config, dirs.Cache, dirs.State, dirs.Logs} {
+			if other != dir && strings.HasPrefix(dir, other+string(filepath.Separator)) {
+				nested = true
+			}
+		}
+		if !nested && !slices.Contains(existing, dir) {
+			existing = append(existing, dir)
+		}
+	}
+	return existing
+}
+
+// formatUninstallPlan lists what uninstall-data removes, one group per kind
+func formatUninstallPlan(plan uninstallPlan) string {
+	var s strings.Builder
+	kubeconfigPaths := make([]string, 0, len(plan.Contexts))
+	for path := range plan.Contexts {
+		kubeconfigPaths = append(kubeconfigPaths, path)
+	}
+	sort.Strings(kubeconfigPaths)
+	for _, path := range kubeconfigPaths {
+		fmt.Fprintf(&s, "Kubeconfig contexts in %s:\n", path)
+		writeItems(&s, plan.Contexts[path])
+	}
+	if len(plan.Profiles) > 0 {
+		s.WriteString("AWS config profiles:\n")
+		writeItems(&s, plan.Profiles)
+	}
+	if len(plan.Credentials) > 0 {
+		s.WriteString("AWS credentials:\n")
+		writeItems(&s, plan.Credentials)
+	}
+	if len(plan.TokenFiles) > 0 {
+		s.WriteString("Cached SSO tokens:\n")
+		writeItems(&s, plan.TokenFiles)
+	}
+	if len(plan.Directories) > 0 {
+		s.WriteString("ark directories:\n")
+		writeItems(&s, plan.Directories)
+	}
+	return s.String()
+}
+
+// writeItems writes one "  - item" line per item
+func writeItems(s *strings.Builder, items []string) {
+	for _, item := range items {
+		fmt.Fprintf(s, "  - %s\n", item)
+	}
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/andresgarcia29/ark-cli/paths"
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	services_kubernetes "github.com/andresgarcia29/ark-cli/services/kubernetes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunUninstall(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, ".cache"))
+	t.Setenv("XDG_STATE_HOME", filepath.Join(home, ".local", "state"))
+	t.Setenv(services_aws.ConfigFileEnv, filepath.Join(home, ".aws", "config"))
+	t.Setenv(services_aws.CredentialsFileEnv, filepath.Join(home, ".aws", "credentials"))
+
+	kubeconfigPath := filepath.Join(home, ".kube", "config")
+	kubeconfig := services_kubernetes.NewKubeconfig()
+	kubeconfig.UpsertEKSContext(services_kubernetes.EKSContextOptions{Alias: "prod", ClusterName: "prod", Region: "us-east-1"})
+	kubeconfig.UpsertContext(services_kubernetes.NamedContext{Name: "kind", Context: services_kubernetes.KubeContext{Cluster: "kind"}})
+	require.NoError(t, services_kubernetes.SaveKubeconfig(kubeconfigPath, kubeconfig))
+
+	client := &services_aws.SSOClient{Region: "us-east-1", StartURL: "https://example.awsapps.com/start"}
+	_, err := client.AddProfile("dev-readonly", services_aws.AWSProfile{AccountID: "111111111111", RoleName: "ReadOnly"})
+	require.NoError(t, err)
+	dirs, err := paths.Get()
+	require.NoError(t, err)
+	require.NoError(t, services_aws.SaveClusterCache([]services_aws.EKSCluster{{Name: "prod"}}))
+
+	plan, err := runUninstall([]string{kubeconfigPath}, true)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{kubeconfigPath: {"prod"}}, plan.Contexts)
+	assert.Equal(t, []string{"dev-readonly"}, plan.Profiles)
+	assert.Equal(t, []string{dirs.Cache}, plan.Directories)
+	assert.DirExists(t, dirs.Cache, "a dry run removes nothing")
+
+	output := formatUninstallPlan(plan)
+	assert.Contains(t, output, "Kubeconfig contexts in "+kubeconfigPath+":\n  - prod\n")
+	assert.Contains(t, output, "AWS config profiles:\n  - dev-readonly\n")
+
+	_, err = runUninstall([]string{kubeconfigPath}, false)
+	require.NoError(t, err)
+	assert.NoDirExists(t, dirs.Cache)
+	remaining, err := services_kubernetes.LoadKubeconfig(kubeconfigPath)
+	require.NoError(t, err)
+	assert.Nil(t, remaining.FindContext("prod"))
+	assert.NotNil(t, remaining.FindContext("kind"), "contexts ark didn't write are kept")
+
+	plan, err = runUninstall([]string{kubeconfigPath}, true)
+	require.NoError(t, err)
+	assert.True(t, plan.empty(), "nothing is left after removal")
+}
+
+func TestArkDirectories(t *testing.T) {
+	legacy := filepath.Join(t.TempDir(), ".ark")
+	require.NoError(t, os.MkdirAll(filepath.Join(legacy, "cache"), 0700))
+
+	dirs := paths.Dirs{Config: legacy, Cache: filepath.Join(legacy, "cache"), State: filepath.Join(legacy, "state"), Logs: legacy}
+	assert.Equal(t, []string{legacy}, arkDirectories(dirs), "directories inside another and missing ones are left out")
+}
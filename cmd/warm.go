@@ -0,0 +1,296 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	ark_config "github.com/andresgarcia29/ark-cli/config"
+	controllers "github.com/andresgarcia29/ark-cli/controllers/aws"
+	controllers_k8s "github.com/andresgarcia29/ark-cli/controllers/kubernetes"
+	"github.com/andresgarcia29/ark-cli/lib/animation"
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	services_kubernetes "github.com/andresgarcia29/ark-cli/services/kubernetes"
+	"github.com/spf13/cobra"
+)
+
+var (
+	warmCmd = &cobra.Command{
+		Use:   "warm",
+		Short: "Get the profiles and clusters of a preset ready in one run, e.g. when paged",
+		Long: `Sign in to the SSO portals of a preset's profiles, refresh their credentials, write kubeconfig contexts
+for the EKS clusters of their accounts and check that every profile and context works: one command to get ready
+when paged. Presets are declared under presets in ark's config. Contexts are added or updated, never removed.
+Break-glass roles are skipped. Exits with status 1 when a profile or context doesn't work.
+
+Example config.yaml:
+  presets:
+    incident:
+      profiles: ["prod-*-readonly", "shared-services-*"]
+      regions: [us-east-1, eu-west-1]
+      clusters: ["payments-*", "checkout"]`,
+		Args: cobra.NoArgs,
+		Run:  warm,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(warmCmd)
+	warmCmd.Flags().String("preset", "", "Preset of ark's config to warm, e.g. incident")
+	warmCmd.Flags().String("kubeconfig-path", "", "Kubeconfig to write (default: kubernetes.kubeconfig_file in the ark config, else the first existing file of KUBECONFIG, else ~/.kube/config)")
+	if err := warmCmd.MarkFlagRequired("preset"); err != nil {
+		panic(err)
+	}
+}
+
+func warm(cmd *cobra.Command, args []string) {
+	name, _ := cmd.Flags().GetString("preset")
+	kubeconfigPath, _ := cmd.Flags().GetString("kubeconfig-path")
+
+	preset, err := lookupPreset(ark_config.Get().Presets, name)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	writes := []func() (string, error){services_aws.CredentialsFilePath}
+	if len(preset.Regions) > 0 {
+		if kubeconfigPath, err = services_kubernetes.ResolveKubeconfigPath(kubeconfigPath); err != nil {
+			fmt.Println("Error:", err)
+			return
+		}
+		writes = append(writes, func() (string, error) { return kubeconfigPath, nil })
+	}
+	if err := preflightWrites(writes...); err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	ctx := context.Background()
+	err = warmPreset(ctx, name, preset, kubeconfigPath)
+	notifyScanResult(ctx, "ark warm --preset "+name, err)
+	if err != nil {
+		fmt.Printf("\n❌ %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("\n🎉 Preset %s is warm\n", name)
+}
+
+// lookupPreset returns the preset called name, checking it lists profiles and valid patterns
+func lookupPreset(presets map[string]ark_config.PresetConfig, name string) (ark_config.PresetConfig, error) {
+	if len(presets) == 0 {
+		return ark_config.PresetConfig{}, errors.New("no presets configured: declare them under presets in ark's config (see `ark warm --help`)")
+	}
+	preset, ok := presets[name]
+	if !ok {
+		return preset, fmt.Errorf("unknown preset %q (presets: %s)", name, strings.Join(slices.Sorted(maps.Keys(presets)), ", "))
+	}
+	if len(preset.Profiles) == 0 {
+		return preset, fmt.Errorf("preset %s lists no profiles", name)
+	}
+	for _, pattern := range preset.Clusters {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return preset, fmt.Errorf("invalid cluster pattern %q of preset %s: %w", pattern, name, err)
+		}
+	}
+	return preset, nil
+}
+
+// warmPreset signs in, refreshes credentials, writes contexts and verifies them. Failures of single profiles or
+// clusters don't stop the next steps, so as much as possible is ready; they are counted in the returned error
+func warmPreset(ctx context.Context, name string, preset ark_config.PresetConfig, kubeconfigPath string) error {
+	all, err := services_aws.ReadAllProfilesFromConfig()
+	if err != nil {
+		return err
+	}
+	profiles, err := services_aws.FilterProfiles(all, preset.Profiles)
+	if err != nil {
+		return err
+	}
+	profiles = controllers.WithoutBreakGlass(profiles, ark_config.Get().AWS.BreakGlass)
+	if len(profiles) == 0 {
+		return fmt.Errorf("no profiles of ~/.aws/config match preset %s (%s)", name, strings.Join(preset.Profiles, ", "))
+	}
+	fmt.Printf("🔥 Warming preset %s: %d profile(s)\n\n", name, len(profiles))
+
+	// Step 1: Sign in to every portal up front, so the parallel steps never wait on a browser
+	for _, portal := range services_aws.SSOPortalsOf(profiles) {
+		if err := controllers.AWSSSOLogin(ctx, portal.Region, portal.StartURL, controllers.SSOOptions{AssumeYes: AssumeYes}); err != nil {
+			return fmt.Errorf("failed to sign in to %s: %w", portal.StartURL, err)
+		}
+	}
+
+	// Step 2: Refresh the credentials of every profile
+	if refreshWarmCredentials(ctx, preset, profiles) > 0 {
+		fmt.Println("Warning: continuing with the profiles that could be refreshed")
+	}
+
+	// Step 3: Write the contexts of the clusters of the preset's accounts
+	var clusters []services_aws.EKSCluster
+	if len(preset.Regions) > 0 {
+		if clusters, err = writeWarmContexts(ctx, preset, profiles, kubeconfigPath); err != nil {
+			return err
+		}
+	}
+
+	// Step 4: Check that what was warmed works
+	return verifyWarmPreset(ctx, profiles, clusters, kubeconfigPath)
+}
+
+// refreshWarmCredentials writes fresh credentials for the profiles, SSO ones in one parallel sync, and returns
+// how many failed
+func refreshWarmCredentials(ctx context.Context, preset ark_config.PresetConfig, profiles []services_aws.ProfileConfig) int {
+	failed := 0
+	if slices.ContainsFunc(profiles, func(p services_aws.ProfileConfig) bool { return p.ProfileType == services_aws.ProfileTypeSSO }) {
+		if err := controllers.SyncCredentials(ctx, preset.Profiles); err != nil {
+			fmt.Println("❌", err)
+			failed++
+		}
+	}
+	for _, profile := range profiles {
+		if profile.ProfileType == services_aws.ProfileTypeSSO {
+			continue
+		}
+		if err := services_aws.LoginWithProfile(ctx, profile.ProfileName, false); err != nil {
+			fmt.Printf("  ❌ %s: %v\n", profile.ProfileName, err)
+			failed++
+			continue
+		}
+		fmt.Printf("  ✓ %s\n", profile.ProfileName)
+	}
+	return failed
+}
+
+// writeWarmContexts scans the accounts of the profiles for clusters in the preset's regions and adds or updates
+// the contexts of the clusters the preset names, keeping every other context
+func writeWarmContexts(ctx context.Context, preset ark_config.PresetConfig, profiles []services_aws.ProfileConfig, kubeconfigPath string) ([]services_aws.EKSCluster, error) {
+	var clusters []services_aws.EKSCluster
+	discoveredAt := time.Now()
+	err := animation.ShowStatus(ctx, "Fetching the EKS clusters of the preset", func(ctx context.Context, status func(string)) error {
+		var err error
+		clusters, err = services_aws.GetClustersForProfiles(ctx, warmScanProfiles(profiles), preset.Regions)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get clusters: %w", err)
+	}
+	clusters = filterWarmClusters(clusters, preset.Clusters)
+	if len(clusters) == 0 {
+		fmt.Println("\nNo EKS clusters of the preset found")
+		return nil, nil
+	}
+	fmt.Printf("\n✓ Clusters found: %d\n\n", len(clusters))
+
+	opts := KubernetesSetupOptions{KubeconfigPath: kubeconfigPath}
+	confirmed, err := confirmKubeconfigPlan(opts, clusters)
+	if err != nil {
+		return nil, err
+	}
+	if !confirmed {
+		fmt.Println("Skipped: kubeconfig was not modified")
+		return nil, nil
+	}
+
+	kind, err := resolveKubeconfigWriter("", false)
+	if err != nil {
+		return nil, err
+	}
+	writer, err := controllers_k8s.NewKubeconfigWriter(kind, controllers_k8s.KubeconfigWriterOptions{
+		KubeconfigPath: kubeconfigPath,
+		AuthMode:       services_kubernetes.AuthModeExec,
+		DiscoveredAt:   discoveredAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := controllers_k8s.UpdateKubeconfigWithWriter(ctx, writer, clusters); err != nil {
+		return nil, fmt.Errorf("failed to update kubeconfig: %w", err)
+	}
+	return clusters, nil
+}
+
+// warmScanProfiles picks the profile each account is scanned with: the first one by name, since profiles are sorted
+func warmScanProfiles(profiles []services_aws.ProfileConfig) map[string]services_aws.ProfileConfig {
+	selected := make(map[string]services_aws.ProfileConfig)
+	for _, profile := range profiles {
+		accountID, _ := services_aws.ProfileAccountAndRole(profile)
+		if _, ok := selected[accountID]; accountID == "" || ok {
+			continue
+		}
+		selected[accountID] = profile
+	}
+	return selected
+}
+
+// filterWarmClusters keeps the clusters whose name matches one of patterns, all of them without patterns
+func filterWarmClusters(clusters []services_aws.EKSCluster, patterns []string) []services_aws.EKSCluster {
+	if len(patterns) == 0 {
+		return clusters
+	}
+	var kept []services_aws.EKSCluster
+	for _, cluster := range clusters {
+		if slices.ContainsFunc(patterns, func(pattern string) bool {
+			matched, _ := path.Match(pattern, cluster.Name)
+			return matched
+		}) {
+			kept = append(kept, cluster)
+		}
+	}
+	return kept
+}
+
+// verifyWarmPreset checks every profile with sts:GetCallerIdentity and reads from every written context
+func verifyWarmPreset(ctx context.Context, profiles []services_aws.ProfileConfig, clusters []services_aws.EKSCluster, kubeconfigPath string) error {
+	var checks []services_aws.CredentialCheck
+	var contexts []services_kubernetes.ContextVerification
+	err := animation.ShowStatus(ctx, "Verifying access", func(ctx context.Context, status func(string)) error {
+		checks = services_aws.CheckCredentials(ctx, profiles, services_aws.NewIdentityChecker, nil)
+		if len(clusters) == 0 {
+			return nil
+		}
+		var err error
+		contexts, err = controllers_k8s.VerifyWrittenContexts(ctx, kubeconfigPath, clusters, "")
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to verify access: %w", err)
+	}
+
+	fmt.Println()
+	output, err := buildCredentialCheckTable(checks).RenderPage(1, 0)
+	if err != nil {
+		return err
+	}
+	fmt.Print(output)
+	if len(contexts) > 0 {
+		fmt.Println()
+		controllers_k8s.PrintContextVerification(contexts)
+	}
+
+	return warmFailures(checks, contexts)
+}
+
+// warmFailures counts the profiles and contexts that don't work, nil when everything does
+func warmFailures(checks []services_aws.CredentialCheck, contexts []services_kubernetes.ContextVerification) error {
+	profiles, clusters := 0, 0
+	for _, check := range checks {
+		if check.Health != services_aws.CredentialHealthOK {
+			profiles++
+		}
+	}
+	for _, result := range contexts {
+		if result.State != services_kubernetes.ContextWorking {
+			clusters++
+		}
+	}
+	if profiles == 0 && clusters == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d profile(s) and %d context(s) don't work (see above, and `ark credentials check`)", profiles, clusters)
+}
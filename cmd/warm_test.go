@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	ark_config "github.com/andresgarcia29/ark-cli/config"
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	services_kubernetes "github.com/andresgarcia29/ark-cli/services/kubernetes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupPreset(t *testing.T) {
+	presets := map[string]ark_config.PresetConfig{
+		"incident": {Profiles: []string{"prod-*"}, Regions: []string{"us-east-1"}},
+		"empty":    {Regions: []string{"us-east-1"}},
+		"broken":   {Profiles: []string{"prod-*"}, Clusters: []string{"payments-["}},
+	}
+
+	preset, err := lookupPreset(presets, "incident")
+	require.NoError(t, err)
+	assert.Equal(t, presets["incident"], preset)
+
+	_, err = lookupPreset(presets, "oncall")
+	assert.EqualError(t, err, `unknown preset "oncall" (presets: broken, empty, incident)`)
+	_, err = lookupPreset(presets, "empty")
+	assert.EqualError(t, err, "preset empty lists no profiles")
+	_, err = lookupPreset(presets, "broken")
+	assert.ErrorContains(t, err, `invalid cluster pattern "payments-["`)
+	_, err = lookupPreset(nil, "incident")
+	assert.ErrorContains(t, err, "no presets configured")
+}
+
+func TestWarmScanProfiles(t *testing.T) {
+	profiles := []services_aws.ProfileConfig{
+		{ProfileName: "prod-admin", ProfileType: services_aws.ProfileTypeSSO, AccountID: "111111111111", RoleName: "Admin"},
+		{ProfileName: "prod-readonly", ProfileType: services_aws.ProfileTypeSSO, AccountID: "111111111111", RoleName: "ReadOnly"},
+		{ProfileName: "shared-deploy", ProfileType: services_aws.ProfileTypeAssumeRole, RoleARN: "arn:aws:iam::222222222222:role/Deploy"},
+		{ProfileName: "unknown", ProfileType: services_aws.ProfileTypeAssumeRole, RoleARN: "not-an-arn"},
+	}
+
+	selected := warmScanProfiles(profiles)
+	assert.Len(t, selected, 2)
+	assert.Equal(t, "prod-admin", selected["111111111111"].ProfileName)
+	assert.Equal(t, "shared-deploy", selected["222222222222"].ProfileName)
+}
+
+func TestFilterWarmClusters(t *testing.T) {
+	clusters := []services_aws.EKSCluster{{Name: "payments-prod"}, {Name: "checkout"}, {Name: "sandbox"}}
+
+	assert.Equal(t, clusters, filterWarmClusters(clusters, nil))
+	kept := filterWarmClusters(clusters, []string{"payments-*", "checkout"})
+	assert.Equal(t, []services_aws.EKSCluster{{Name: "payments-prod"}, {Name: "checkout"}}, kept)
+}
+
+func TestWarmFailures(t *testing.T) {
+	ok := services_aws.CredentialCheck{Health: services_aws.CredentialHealthOK}
+	working := services_kubernetes.ContextVerification{Context: "payments-prod", State: services_kubernetes.ContextWorking}
+	assert.NoError(t, warmFailures([]services_aws.CredentialCheck{ok}, []services_kubernetes.ContextVerification{working}))
+
+	err := warmFailures(
+		[]services_aws.CredentialCheck{ok, {Health: services_aws.CredentialHealthNeedsLogin, Err: errors.New("expired")}},
+		[]services_kubernetes.ContextVerification{working, {Context: "checkout", State: services_kubernetes.ContextUnauthorized}},
+	)
+	assert.EqualError(t, err, "1 profile(s) and 1 context(s) don't work (see above, and `ark credentials check`)")
+}
@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	ark_config "github.com/andresgarcia29/ark-cli/config"
+	controllers "github.com/andresgarcia29/ark-cli/controllers/aws"
+	"github.com/andresgarcia29/ark-cli/lib/animation"
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	services_kubernetes "github.com/andresgarcia29/ark-cli/services/kubernetes"
+	"github.com/spf13/cobra"
+)
+
+var (
+	workspaceCmd = &cobra.Command{
+		Use:   "workspace",
+		Short: "Switch AWS profile, region, kube context and namespace together",
+		Long: `Workspaces are named bundles of an AWS profile, a default region, a kube context and a namespace,
+declared under workspaces in ark's config and applied together by ark workspace use.
+
+Example config.yaml:
+  workspaces:
+    payments-prod:
+      profile: payments-prod-readonly
+      region: us-east-1
+      context: payments-prod
+      namespace: payments`,
+	}
+
+	workspaceListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List the workspaces of ark's config",
+		Long:  `List the workspaces declared under workspaces in ark's config with the profile, region, context and namespace each applies.`,
+		Args:  cobra.NoArgs,
+		Run:   workspaceList,
+	}
+
+	workspaceUseCmd = &cobra.Command{
+		Use:   "use [name]",
+		Short: "Apply a workspace, chosen from a list when no name is given",
+		Long: `Apply a workspace: log in with its profile and make it the default one, write its region to the
+[default] profile of the AWS config, switch to its kube context and set the namespace of that context.
+Fields the workspace leaves empty are not touched. Without a name, the workspace is chosen from a list.`,
+		Args: cobra.MaximumNArgs(1),
+		Run:  workspaceUse,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(workspaceCmd)
+	workspaceCmd.AddCommand(workspaceListCmd)
+	workspaceCmd.AddCommand(workspaceUseCmd)
+	addTableFlags(workspaceListCmd, "")
+}
+
+func workspaceList(cmd *cobra.Command, args []string) {
+	workspaces := ark_config.Get().Workspaces
+	if len(workspaces) == 0 {
+		fmt.Println(errNoWorkspaces)
+		return
+	}
+	output, err := renderTable(cmd, buildWorkspaceTable(workspaces))
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	fmt.Print(output)
+}
+
+func workspaceUse(cmd *cobra.Command, args []string) {
+	workspaces := ark_config.Get().Workspaces
+	name, err := chooseWorkspace(args, workspaces)
+	if errors.Is(err, animation.ErrInputCancelled) {
+		return
+	}
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	if err := applyWorkspace(context.Background(), name, workspaces[name]); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return
+	}
+	fmt.Printf("🎉 Workspace %s is active\n", name)
+	for _, hint := range workspaceEnvHints(workspaces[name], os.Getenv) {
+		fmt.Println(hint)
+	}
+}
+
+// errNoWorkspaces explains where workspaces come from
+var errNoWorkspaces = errors.New("no workspaces configured: declare them under workspaces in ark's config (see `ark workspace --help`)")
+
+// chooseWorkspace returns the workspace named by args, or asks for one when there is no argument
+func chooseWorkspace(args []string, workspaces map[string]ark_config.WorkspaceConfig) (string, error) {
+	if len(workspaces) == 0 {
+		return "", errNoWorkspaces
+	}
+	names := slices.Sorted(maps.Keys(workspaces))
+	if len(args) == 1 {
+		if _, ok := workspaces[args[0]]; !ok {
+			return "", fmt.Errorf("unknown workspace %q (workspaces: %s)", args[0], strings.Join(names, ", "))
+		}
+		return args[0], nil
+	}
+
+	options := make([]animation.SelectOption, 0, len(names))
+	for _, name := range names {
+		options = append(options, animation.SelectOption{Label: name, Description: describeWorkspace(workspaces[name])})
+	}
+	index, err := animation.Select("Workspace", options)
+	if errors.Is(err, animation.ErrSelectionRequired) {
+		return "", fmt.Errorf("no workspace given and no terminal to choose one: run `ark workspace use <name>`")
+	}
+	if err != nil {
+		return "", err
+	}
+	return names[index], nil
+}
+
+// applyWorkspace applies the fields a workspace sets, AWS first so a failed login leaves kubectl untouched
+func applyWorkspace(ctx context.Context, name string, ws ark_config.WorkspaceConfig) error {
+	if ws == (ark_config.WorkspaceConfig{}) {
+		return fmt.Errorf("workspace %s sets nothing", name)
+	}
+
+	var writes []func() (string, error)
+	if ws.Profile != "" {
+		writes = append(writes, services_aws.CredentialsFilePath)
+	}
+	if ws.Region != "" {
+		writes = append(writes, services_aws.ConfigFilePath)
+	}
+	if err := preflightWrites(writes...); err != nil {
+		return err
+	}
+
+	if ws.Profile != "" {
+		if err := loginWorkspaceProfile(ctx, ws.Profile); err != nil {
+			return err
+		}
+		fmt.Printf("✓ Logged in with profile %s\n", ws.Profile)
+	}
+	if ws.Region != "" {
+		if err := services_aws.SetDefaultRegion(ws.Region); err != nil {
+			return err
+		}
+		fmt.Printf("✓ Default region set to %s\n", ws.Region)
+	}
+	if ws.Context != "" {
+		if err := services_kubernetes.SwitchToContext(ws.Context); err != nil {
+			return err
+		}
+		fmt.Printf("✓ Switched to context %s\n", ws.Context)
+	}
+	if ws.Namespace != "" {
+		if err := services_kubernetes.SetContextNamespace(ws.Context, ws.Namespace); err != nil {
+			return err
+		}
+		fmt.Printf("✓ Namespace set to %s\n", ws.Namespace)
+	}
+	return nil
+}
+
+// loginWorkspaceProfile logs in with a profile and makes it the default one, like the `ark aws` selector
+func loginWorkspaceProfile(ctx context.Context, profileName string) error {
+	profile, err := services_aws.ReadProfileFromConfig(profileName)
+	if err != nil {
+		return err
+	}
+	ssoRegion, ssoStartURL, err := services_aws.ResolveSSOConfiguration(profileName)
+	if err != nil {
+		return fmt.Errorf("failed to resolve SSO configuration: %w", err)
+	}
+	if !guardDefaultCredentials() {
+		return fmt.Errorf("the [default] credentials were kept")
+	}
+	if err := authorizeSelectedBreakGlass(ctx, profile); err != nil {
+		return err
+	}
+	return controllers.AttemptLoginWithRetry(ctx, profileName, true, ssoRegion, ssoStartURL)
+}
+
+// describeWorkspace summarizes what a workspace applies, e.g. "payments-prod-readonly · us-east-1 · payments-prod/payments"
+func describeWorkspace(ws ark_config.WorkspaceConfig) string {
+	var parts []string
+	for _, part := range []string{ws.Profile, ws.Region} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	switch {
+	case ws.Context != "" && ws.Namespace != "":
+		parts = append(parts, ws.Context+"/"+ws.Namespace)
+	case ws.Context != "":
+		parts = append(parts, ws.Context)
+	case ws.Namespace != "":
+		parts = append(parts, "namespace "+ws.Namespace)
+	}
+	return strings.Join(parts, " · ")
+}
+
+// buildWorkspaceTable lists the workspaces by name
+func buildWorkspaceTable(workspaces map[string]ark_config.WorkspaceConfig) *animation.Table {
+	table := animation.NewTable("Name", "Profile", "Region", "Context", "Namespace")
+	for _, name := range slices.Sorted(maps.Keys(workspaces)) {
+		ws := workspaces[name]
+		table.AddRow(name, ws.Profile, ws.Region, ws.Context, ws.Namespace)
+	}
+	return table
+}
+
+// workspaceEnvHints warns about environment variables of this shell that override what the workspace set
+func workspaceEnvHints(ws ark_config.WorkspaceConfig, getenv func(string) string) []string {
+	var hints []string
+	if awsProfile := getenv("AWS_PROFILE"); ws.Profile != "" && awsProfile != "" && awsProfile != ws.Profile {
+		hints = append(hints, fmt.Sprintf("💡 AWS_PROFILE=%s overrides the default profile in this shell: export AWS_PROFILE=%s", awsProfile, ws.Profile))
+	}
+	for _, name := range []string{"AWS_REGION", "AWS_DEFAULT_REGION"} {
+		if region := getenv(name); ws.Region != "" && region != "" && region != ws.Region {
+			hints = append(hints, fmt.Sprintf("💡 %s=%s overrides the default region in this shell: export %s=%s", name, region, name, ws.Region))
+		}
+	}
+	return hints
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	ark_config "github.com/andresgarcia29/ark-cli/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testWorkspaces = map[string]ark_config.WorkspaceConfig{
+	"payments-prod": {Profile: "payments-prod-readonly", Region: "us-east-1", Context: "payments-prod", Namespace: "payments"},
+	"sandbox":       {Context: "sandbox"},
+}
+
+func TestChooseWorkspace(t *testing.T) {
+	name, err := chooseWorkspace([]string{"sandbox"}, testWorkspaces)
+	require.NoError(t, err)
+	assert.Equal(t, "sandbox", name)
+
+	_, err = chooseWorkspace([]string{"payments"}, testWorkspaces)
+	assert.EqualError(t, err, `unknown workspace "payments" (workspaces: payments-prod, sandbox)`)
+
+	_, err = chooseWorkspace([]string{"sandbox"}, nil)
+	assert.ErrorIs(t, err, errNoWorkspaces)
+
+	// Tests have no terminal to choose from
+	_, err = chooseWorkspace(nil, testWorkspaces)
+	assert.ErrorContains(t, err, "ark workspace use <name>")
+}
+
+func TestApplyEmptyWorkspace(t *testing.T) {
+	err := applyWorkspace(context.Background(), "empty", ark_config.WorkspaceConfig{})
+	assert.EqualError(t, err, "workspace empty sets nothing")
+}
+
+func TestDescribeWorkspace(t *testing.T) {
+	assert.Equal(t, "payments-prod-readonly · us-east-1 · payments-prod/payments", describeWorkspace(testWorkspaces["payments-prod"]))
+	assert.Equal(t, "sandbox", describeWorkspace(testWorkspaces["sandbox"]))
+	assert.Equal(t, "us-east-1 · namespace payments", describeWorkspace(ark_config.WorkspaceConfig{Region: "us-east-1", Namespace: "payments"}))
+}
+
+func TestBuildWorkspaceTable(t *testing.T) {
+	table := buildWorkspaceTable(testWorkspaces)
+	require.Len(t, table.Rows, 2)
+	assert.Equal(t, []string{"payments-prod", "payments-prod-readonly", "us-east-1", "payments-prod", "payments"}, table.Rows[0])
+	assert.Equal(t, []string{"sandbox", "", "", "sandbox", ""}, table.Rows[1])
+}
+
+func TestWorkspaceEnvHints(t *testing.T) {
+	env := map[string]string{"AWS_PROFILE": "dev-admin", "AWS_REGION": "eu-west-1", "AWS_DEFAULT_REGION": "us-east-1"}
+	hints := workspaceEnvHints(testWorkspaces["payments-prod"], func(key string) string { return env[key] })
+	assert.Equal(t, []string{
+		"💡 AWS_PROFILE=dev-admin overrides the default profile in this shell: export AWS_PROFILE=payments-prod-readonly",
+		"💡 AWS_REGION=eu-west-1 overrides the default region in this shell: export AWS_REGION=us-east-1",
+	}, hints)
+
+	assert.Empty(t, workspaceEnvHints(testWorkspaces["sandbox"], func(key string) string { return env[key] }),
+		"a workspace without profile and region has nothing overridden")
+}
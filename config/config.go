@@ -0,0 +1,287 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/andresgarcia29/ark-cli/logs"
+	"github.com/andresgarcia29/ark-cli/paths"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// ConfigPathEnvVar overrides the location of ark's configuration file
+	ConfigPathEnvVar = "ARK_CONFIG"
+	// DefaultSessionName attributes assumed-role sessions to the local user in CloudTrail
+	DefaultSessionName = "{{.User}}@ark-{{.Version}}"
+	// DefaultContextAlias names kubeconfig contexts after their EKS cluster, like `aws eks update-kubeconfig --alias`
+	DefaultContextAlias = "{{.Cluster}}"
+)
+
+var (
+	globalConfig     *Config
+	globalConfigOnce sync.Once
+)
+
+// Config is ark's own configuration, stored in config.yaml in the config directory of paths
+type Config struct {
+	AWS        AWSConfig        `yaml:"aws"`
+	Kubernetes KubernetesConfig `yaml:"kubernetes"`
+	Audit      AuditConfig      `yaml:"audit"`
+	// Notifications posts the outcome of long operations to a webhook or Slack
+	Notifications NotificationsConfig `yaml:"notifications"`
+	Cache         CacheConfig         `yaml:"cache"`
+	// Aliases maps a name to the arguments it runs, like git aliases,
+	// e.g. prod-k8s: "kubernetes setup --role-prefixs prod --regions us-east-1"
+	Aliases map[string]string `yaml:"aliases"`
+	// Workspaces names bundles of AWS profile, region, kube context and namespace applied together by
+	// `ark workspace use`, e.g. payments-prod: {profile: payments-prod-readonly, context: payments-prod}
+	Workspaces map[string]WorkspaceConfig `yaml:"workspaces"`
+	// Orgs locates the bootstrap manifest of each organization for `ark init --org <name>`
+	Orgs map[string]OrgConfig `yaml:"orgs"`
+	// Presets names the profiles and clusters `ark warm --preset <name>` gets ready in one run,
+	// e.g. incident: {profiles: ["prod-*-readonly"], regions: [us-east-1]}
+	Presets map[string]PresetConfig `yaml:"presets"`
+	// BrowserCommand opens sign-in pages instead of $BROWSER or the default browser, with %s replaced by the URL
+	// or the URL appended, e.g. google-chrome --profile-directory="Profile 2"
+	BrowserCommand string `yaml:"browser_command"`
+}
+
+// PresetConfig is what `ark warm` logs in to, refreshes and verifies
+type PresetConfig struct {
+	// Profiles are glob patterns of the profiles whose credentials are refreshed, e.g. prod-*-readonly
+	Profiles []string `yaml:"profiles"`
+	// Regions are scanned for the EKS clusters of the profiles; without regions no context is written
+	Regions []string `yaml:"regions"`
+	// Clusters are glob patterns of the clusters to write contexts for (default: every cluster found)
+	Clusters []string `yaml:"clusters"`
+}
+
+// OrgConfig locates an organization's signed bootstrap manifest
+type OrgConfig struct {
+	// Source is an https:// URL, s3://bucket/key or git+https://host/repo.git#path of the manifest
+	Source string `yaml:"source"`
+	// PublicKey is the base64 Ed25519 key the manifest's signature is checked with
+	PublicKey string `yaml:"public_key"`
+}
+
+// WorkspaceConfig is what `ark workspace use` applies; empty fields are left as they are
+type WorkspaceConfig struct {
+	// Profile is the AWS profile logged in to and set as the default one
+	Profile string `yaml:"profile"`
+	// Region is written as the region of the [default] profile
+	Region string `yaml:"region"`
+	// Context is the kube context switched to
+	Context string `yaml:"context"`
+	// Namespace is set as the namespace of the context
+	Namespace string `yaml:"namespace"`
+}
+
+// AWSConfig configures how ark talks to AWS
+type AWSConfig struct {
+	// SessionName is the text/template used for RoleSessionName when assuming roles
+	// Available fields: {{.User}}, {{.Hostname}} and {{.Version}}
+	SessionName string `yaml:"session_name"`
+	// Endpoints overrides API endpoints per service (sso, sso_oidc, sts, eks, iam, ec2, organizations,
+	// resource_explorer), e.g. for a corporate gateway
+	Endpoints map[string]string `yaml:"endpoints"`
+	// Proxy is the HTTP(S) proxy for AWS API calls; HTTPS_PROXY/HTTP_PROXY apply when empty
+	Proxy string `yaml:"proxy"`
+	// CABundle is a PEM file with extra certificate authorities; AWS_CA_BUNDLE applies when empty
+	CABundle string `yaml:"ca_bundle"`
+	// Timeout bounds every AWS API request, e.g. "30s"
+	Timeout time.Duration `yaml:"timeout"`
+	// ClockSkew is how long before their expiration SSO tokens and credentials are treated as expired, so a clock
+	// running behind AWS's doesn't send ones AWS already refuses, e.g. "1m"; 0 uses them until the last second
+	ClockSkew time.Duration `yaml:"clock_skew"`
+	// MaxAttempts is the number of attempts per AWS API call, retries included
+	MaxAttempts int `yaml:"max_attempts"`
+	// DeprecationPolicy is the path or http(s) URL of a team-published list of deprecated accounts and roles
+	DeprecationPolicy string `yaml:"deprecation_policy"`
+	// RoleDescriptions maps role (permission set) names to a hint shown next to them, e.g.
+	// AdminBreakGlass: "Emergency access, paged". The SSO portal API only returns role names
+	RoleDescriptions map[string]string `yaml:"role_descriptions"`
+	// LeastPrivilege suggests a read-only role when an admin role is selected
+	LeastPrivilege LeastPrivilegeConfig `yaml:"least_privilege"`
+	// BreakGlass tags emergency roles that require --break-glass and a justification
+	BreakGlass BreakGlassConfig `yaml:"break_glass"`
+	// ScanConfirmThreshold is the number of account×region combinations above which scans of every
+	// account ask for confirmation (or --yes); 0 never asks
+	ScanConfirmThreshold int `yaml:"scan_confirm_threshold"`
+}
+
+// BreakGlassConfig configures emergency access roles
+type BreakGlassConfig struct {
+	// Roles lists role (permission set) names, matched case-insensitively
+	Roles []string `yaml:"roles"`
+	// Webhook is an http(s) URL the break-glass audit event is POSTed to as JSON, e.g. a chat or paging hook
+	Webhook string `yaml:"webhook"`
+}
+
+// LeastPrivilegeConfig configures the nudge towards read-only roles
+// Patterns are matched case-insensitively as substrings of the role name
+type LeastPrivilegeConfig struct {
+	Enabled          bool     `yaml:"enabled"`
+	AdminPatterns    []string `yaml:"admin_patterns"`
+	ReadOnlyPatterns []string `yaml:"readonly_patterns"`
+}
+
+// Endpoint returns the endpoint override for a service, or "" when the SDK default applies
+func (c AWSConfig) Endpoint(service string) string {
+	return c.Endpoints[service]
+}
+
+// KubernetesConfig configures kubeconfig management
+type KubernetesConfig struct {
+	// Writer selects how contexts are written: "aws-cli" or "native"
+	Writer string `yaml:"writer"`
+	// KubeconfigFile is the file ark writes contexts to when no --kubeconfig-path is given
+	// (default: the first existing file of KUBECONFIG, like kubectl, or ~/.kube/config)
+	KubeconfigFile string `yaml:"kubeconfig_file"`
+	// ContextAlias is the text/template used to name the contexts ark writes
+	// Available fields: {{.Cluster}}, {{.Region}}, {{.AccountID}} and {{.Profile}}
+	ContextAlias string `yaml:"context_alias"`
+	// ClusterProfiles is the file mapping clusters to the profile their contexts use
+	// (default: cluster_profiles.yaml in ark's config directory)
+	ClusterProfiles string `yaml:"cluster_profiles"`
+	// Discovery selects how the clusters of every account are found: "list-clusters" or "resource-explorer"
+	Discovery string `yaml:"discovery"`
+	// ResourceExplorer locates the aggregator index searched by resource-explorer discovery
+	ResourceExplorer ResourceExplorerConfig `yaml:"resource_explorer"`
+}
+
+// ResourceExplorerConfig locates the AWS Resource Explorer index that sees the clusters of every account
+type ResourceExplorerConfig struct {
+	// Profile signs the search, e.g. a read-only role of the account holding the aggregator index
+	Profile string `yaml:"profile"`
+	// Region is the region of the aggregator index (default: the profile's region)
+	Region string `yaml:"region"`
+	// ViewARN is the view to search (default: the default view of the region)
+	ViewARN string `yaml:"view_arn"`
+}
+
+// AuditConfig configures the local audit log of issued credentials
+type AuditConfig struct {
+	// Enabled turns on the audit log; it is off by default
+	Enabled bool `yaml:"enabled"`
+	// Path is the JSON lines file events are appended to (default: audit.log in the log directory of paths)
+	Path string `yaml:"path"`
+}
+
+// CacheConfig configures the local cache of discovered accounts, roles, clusters and contexts
+type CacheConfig struct {
+	// Encrypt seals cache files with AES-256-GCM, keyed by a secret kept in the keychain of the operating system
+	Encrypt bool `yaml:"encrypt"`
+}
+
+// NotificationsConfig configures where ark reports long operations and auth events; everything is off by default
+type NotificationsConfig struct {
+	// Webhook is an http(s) URL messages are POSTed to as JSON; Slack incoming webhooks work as is
+	Webhook string `yaml:"webhook"`
+	// Slack posts messages with chat.postMessage
+	Slack SlackConfig `yaml:"slack"`
+	// Desktop shows native notifications for auth events, e.g. when the SSO session is about to expire
+	Desktop bool `yaml:"desktop"`
+}
+
+// SlackConfig identifies the bot and channel used for Slack notifications
+type SlackConfig struct {
+	Token   string `yaml:"token"`
+	Channel string `yaml:"channel"`
+}
+
+// DefaultConfig returns the configuration used when no file exists
+func DefaultConfig() *Config {
+	return &Config{
+		AWS: AWSConfig{
+			SessionName: DefaultSessionName,
+			Timeout:     30 * time.Second,
+			ClockSkew:   time.Minute,
+			MaxAttempts: 5,
+			// Enough for a handful of regions across a mid-sized organization
+			ScanConfirmThreshold: 50,
+			LeastPrivilege: LeastPrivilegeConfig{
+				Enabled:          true,
+				AdminPatterns:    []string{"admin", "poweruser"},
+				ReadOnlyPatterns: []string{"readonly", "read-only", "viewonly"},
+			},
+		},
+		Kubernetes: KubernetesConfig{
+			Writer:       "aws-cli",
+			ContextAlias: DefaultContextAlias,
+			Discovery:    "list-clusters",
+		},
+	}
+}
+
+// ConfigPath returns the path of the configuration file, honoring ARK_CONFIG
+func ConfigPath() (string, error) {
+	if path := os.Getenv(ConfigPathEnvVar); path != "" {
+		return path, nil
+	}
+	dir, err := paths.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.yaml"), nil
+}
+
+// Load reads the configuration file on top of the defaults, then applies ARK_* environment variables
+// A missing file is not an error: the defaults are returned
+func Load() (*Config, error) {
+	path, err := ConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	cfg, _, err := load(path, os.Getenv)
+	return cfg, err
+}
+
+// load reads the configuration like Load, also returning the source of every key that isn't a default
+func load(path string, getenv func(string) string) (*Config, map[string]Source, error) {
+	logger := logs.GetLogger()
+	cfg := DefaultConfig()
+	sources := make(map[string]Source)
+
+	data, err := os.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		logger.Debugw("No ark config file found, using defaults", "path", path)
+	case err != nil:
+		return nil, nil, fmt.Errorf("failed to read ark config: %w", err)
+	default:
+		if err := yaml.Unmarshal(data, cfg); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse ark config %s: %w", path, err)
+		}
+		for _, key := range fileKeys(data) {
+			sources[key] = SourceFile
+		}
+		logger.Debugw("Ark config loaded", "path", path)
+	}
+
+	envKeys, err := applyEnv(cfg, getenv)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid ark config environment variable %w", err)
+	}
+	for _, key := range envKeys {
+		sources[key] = SourceEnv
+	}
+	return cfg, sources, nil
+}
+
+// Get returns the process-wide configuration, loading it on first use
+// If the file cannot be read, a warning is logged and defaults are used
+func Get() *Config {
+	globalConfigOnce.Do(func() {
+		cfg, err := Load()
+		if err != nil {
+			logs.GetLogger().Warnw("Failed to load ark config, using defaults", "error", err)
+			cfg = DefaultConfig()
+		}
+		globalConfig = cfg
+	})
+	return globalConfig
+}
@@ -0,0 +1,178 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(ConfigPathEnvVar, "")
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "xdg"))
+
+	path, err := ConfigPath()
+	require.NoError(t, err)
+	if runtime.GOOS != "windows" {
+		assert.Equal(t, filepath.Join(home, "xdg", "ark", "config.yaml"), path)
+	}
+
+	// The ~/.ark directory of earlier versions keeps being used
+	require.NoError(t, os.Mkdir(filepath.Join(home, ".ark"), 0700))
+	path, err = ConfigPath()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, ".ark", "config.yaml"), path)
+
+	t.Setenv(ConfigPathEnvVar, "/etc/ark.yaml")
+	path, err = ConfigPath()
+	require.NoError(t, err)
+	assert.Equal(t, "/etc/ark.yaml", path)
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     *string
+		expectError bool
+		validate    func(t *testing.T, cfg *Config)
+	}{
+		{
+			name: "missing file returns defaults",
+			validate: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, DefaultConfig(), cfg)
+			},
+		},
+		{
+			name:    "file overrides defaults",
+			content: strPtr("kubernetes:\n  writer: native\n"),
+			validate: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, "native", cfg.Kubernetes.Writer)
+				assert.Equal(t, DefaultSessionName, cfg.AWS.SessionName, "unset values keep their defaults")
+			},
+		},
+		{
+			name:    "kubeconfig file",
+			content: strPtr("kubernetes:\n  kubeconfig_file: ~/.kube/ark.yaml\n"),
+			validate: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, "~/.kube/ark.yaml", cfg.Kubernetes.KubeconfigFile)
+				assert.Equal(t, "aws-cli", cfg.Kubernetes.Writer)
+			},
+		},
+		{
+			name:    "custom session name",
+			content: strPtr("aws:\n  session_name: \"{{.User}}-ci\"\n"),
+			validate: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, "{{.User}}-ci", cfg.AWS.SessionName)
+			},
+		},
+		{
+			name:    "aws network settings",
+			content: strPtr("aws:\n  proxy: http://proxy.internal:3128\n  ca_bundle: /etc/ssl/corp.pem\n  endpoints:\n    sts: https://sts.gateway.internal\n"),
+			validate: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, "http://proxy.internal:3128", cfg.AWS.Proxy)
+				assert.Equal(t, "/etc/ssl/corp.pem", cfg.AWS.CABundle)
+				assert.Equal(t, "https://sts.gateway.internal", cfg.AWS.Endpoint("sts"))
+				assert.Empty(t, cfg.AWS.Endpoint("eks"))
+			},
+		},
+		{
+			name:    "clock skew",
+			content: strPtr("aws:\n  clock_skew: 2m\n"),
+			validate: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, 2*time.Minute, cfg.AWS.ClockSkew)
+				assert.Equal(t, time.Minute, DefaultConfig().AWS.ClockSkew)
+			},
+		},
+		{
+			name:    "least privilege can be disabled",
+			content: strPtr("aws:\n  least_privilege:\n    enabled: false\n"),
+			validate: func(t *testing.T, cfg *Config) {
+				assert.False(t, cfg.AWS.LeastPrivilege.Enabled)
+				assert.Equal(t, []string{"admin", "poweruser"}, cfg.AWS.LeastPrivilege.AdminPatterns, "unset patterns keep their defaults")
+			},
+		},
+		{
+			name:    "break-glass roles",
+			content: strPtr("aws:\n  break_glass:\n    roles: [AdminBreakGlass]\n    webhook: https://hooks.example.com/break-glass\n"),
+			validate: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, []string{"AdminBreakGlass"}, cfg.AWS.BreakGlass.Roles)
+				assert.Equal(t, "https://hooks.example.com/break-glass", cfg.AWS.BreakGlass.Webhook)
+			},
+		},
+		{
+			name:    "notifications",
+			content: strPtr("notifications:\n  webhook: https://hooks.example.com/ark\n  slack:\n    token: xoxb-test\n    channel: \"#platform\"\n"),
+			validate: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, "https://hooks.example.com/ark", cfg.Notifications.Webhook)
+				assert.Equal(t, SlackConfig{Token: "xoxb-test", Channel: "#platform"}, cfg.Notifications.Slack)
+			},
+		},
+		{
+			name:    "workspaces",
+			content: strPtr("workspaces:\n  payments-prod:\n    profile: payments-prod-readonly\n    region: us-east-1\n    context: payments-prod\n    namespace: payments\n"),
+			validate: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, map[string]WorkspaceConfig{
+					"payments-prod": {Profile: "payments-prod-readonly", Region: "us-east-1", Context: "payments-prod", Namespace: "payments"},
+				}, cfg.Workspaces)
+			},
+		},
+		{
+			name:    "presets",
+			content: strPtr("presets:\n  incident:\n    profiles: [\"prod-*-readonly\"]\n    regions: [us-east-1, eu-west-1]\n    clusters: [\"payments-*\"]\n"),
+			validate: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, map[string]PresetConfig{
+					"incident": {Profiles: []string{"prod-*-readonly"}, Regions: []string{"us-east-1", "eu-west-1"}, Clusters: []string{"payments-*"}},
+				}, cfg.Presets)
+			},
+		},
+		{
+			name:    "browser command",
+			content: strPtr("browser_command: google-chrome --profile-directory=\"Profile 2\"\n"),
+			validate: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, `google-chrome --profile-directory="Profile 2"`, cfg.BrowserCommand)
+			},
+		},
+		{
+			name:    "orgs",
+			content: strPtr("orgs:\n  mycorp:\n    source: s3://mycorp-ark/manifest.yaml\n    public_key: MCowBQYDK2VwAyEA\n"),
+			validate: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, map[string]OrgConfig{
+					"mycorp": {Source: "s3://mycorp-ark/manifest.yaml", PublicKey: "MCowBQYDK2VwAyEA"},
+				}, cfg.Orgs)
+			},
+		},
+		{
+			name:        "invalid yaml",
+			content:     strPtr("kubernetes: [\n"),
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			t.Setenv(ConfigPathEnvVar, path)
+			if tt.content != nil {
+				require.NoError(t, os.WriteFile(path, []byte(*tt.content), 0600))
+			}
+
+			cfg, err := Load()
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			tt.validate(t, cfg)
+		})
+	}
+}
+
+func strPtr(s string) *string {
+	return &s
+}
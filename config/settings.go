@@ -0,0 +1,261 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Source tells where the effective value of a setting comes from
+type Source string
+
+const (
+	SourceDefault Source = "default"
+	SourceFile    Source = "file"
+	SourceEnv     Source = "env"
+)
+
+// Setting is the effective value of one configuration key
+type Setting struct {
+	// Key is the dotted YAML path, e.g. aws.max_attempts
+	Key    string
+	Value  any
+	Source Source
+}
+
+// field is a settable leaf of Config: a scalar, a list or a map
+type field struct {
+	key   string
+	value reflect.Value
+}
+
+// EnvVar returns the environment variable overriding a key, e.g. ARK_AWS_MAX_ATTEMPTS for aws.max_attempts
+func EnvVar(key string) string {
+	return "ARK_" + strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(key))
+}
+
+// Keys lists every configuration key, in the order of the Config struct
+func Keys() []string {
+	var keys []string
+	for _, f := range fields(DefaultConfig()) {
+		keys = append(keys, f.key)
+	}
+	return keys
+}
+
+// Effective returns every setting with its value and where it comes from: the file overrides the defaults,
+// and ARK_* environment variables override the file
+func Effective() ([]Setting, error) {
+	path, err := ConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	cfg, sources, err := load(path, os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+
+	var settings []Setting
+	for _, f := range fields(cfg) {
+		source := sources[f.key]
+		if source == "" {
+			source = SourceDefault
+		}
+		settings = append(settings, Setting{Key: f.key, Value: f.value.Interface(), Source: source})
+	}
+	return settings, nil
+}
+
+// Set writes one key to the configuration file and returns the file's path
+// The value is YAML, e.g. 10, 45s, true or [a, b]; text settings take it literally
+func Set(key, value string) (string, error) {
+	path, err := ConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return path, set(path, key, value)
+}
+
+// set writes key to the file at path, keeping its other settings and comments
+func set(path, key, value string) error {
+	f, ok := lookupField(DefaultConfig(), key)
+	if !ok {
+		return fmt.Errorf("unknown key %q (run `ark config view` to list the keys)", key)
+	}
+	valueNode, err := parseValue(f, value)
+	if err != nil {
+		return err
+	}
+
+	var doc yaml.Node
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read ark config: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse ark config %s: %w", path, err)
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return fmt.Errorf("ark config %s is not a mapping", path)
+	}
+
+	mapping := doc.Content[0]
+	parts := strings.Split(key, ".")
+	for _, part := range parts[:len(parts)-1] {
+		mapping = childMapping(mapping, part)
+	}
+	setMappingValue(mapping, parts[len(parts)-1], valueNode)
+
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return fmt.Errorf("failed to encode ark config: %w", err)
+	}
+	// Never write a file Load would refuse
+	if err := yaml.Unmarshal(out.Bytes(), DefaultConfig()); err != nil {
+		return fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	return os.WriteFile(path, out.Bytes(), 0600)
+}
+
+// parseValue turns the text given for a field into a YAML node, checking it fits the field's type
+func parseValue(f field, value string) (*yaml.Node, error) {
+	node := &yaml.Node{}
+	if f.value.Kind() == reflect.String {
+		// Templates such as {{.Cluster}} would otherwise parse as YAML mappings
+		if err := node.Encode(value); err != nil {
+			return nil, err
+		}
+		return node, nil
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(value), &doc); err != nil || len(doc.Content) == 0 {
+		return nil, fmt.Errorf("invalid value for %s: %q is not valid YAML", f.key, value)
+	}
+	node = doc.Content[0]
+	if err := node.Decode(reflect.New(f.value.Type()).Interface()); err != nil {
+		return nil, fmt.Errorf("invalid value for %s: %w", f.key, err)
+	}
+	return node, nil
+}
+
+// childMapping returns the mapping under key, creating it (or replacing a non-mapping value) as needed
+func childMapping(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			if mapping.Content[i+1].Kind != yaml.MappingNode {
+				mapping.Content[i+1] = &yaml.Node{Kind: yaml.MappingNode}
+			}
+			return mapping.Content[i+1]
+		}
+	}
+	child := &yaml.Node{Kind: yaml.MappingNode}
+	setMappingValue(mapping, key, child)
+	return child
+}
+
+// setMappingValue replaces the value of key in a mapping, appending the key when it's missing
+func setMappingValue(mapping *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			// Keep the comments written next to the old value
+			value.LineComment = mapping.Content[i+1].LineComment
+			mapping.Content[i+1] = value
+			return
+		}
+	}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+}
+
+// applyEnv overrides the fields of cfg that have an ARK_* environment variable set, returning their keys
+func applyEnv(cfg *Config, getenv func(string) string) ([]string, error) {
+	var keys []string
+	for _, f := range fields(cfg) {
+		value := getenv(EnvVar(f.key))
+		if value == "" {
+			continue
+		}
+		node, err := parseValue(f, value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", EnvVar(f.key), err)
+		}
+		if err := node.Decode(f.value.Addr().Interface()); err != nil {
+			return nil, fmt.Errorf("%s: %w", EnvVar(f.key), err)
+		}
+		keys = append(keys, f.key)
+	}
+	return keys, nil
+}
+
+// fileKeys lists the keys a config file sets
+func fileKeys(data []byte) []string {
+	var doc yaml.Node
+	if yaml.Unmarshal(data, &doc) != nil || len(doc.Content) == 0 {
+		return nil
+	}
+	known := Keys()
+	var keys []string
+	var walk func(node *yaml.Node, prefix string)
+	walk = func(node *yaml.Node, prefix string) {
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := prefix + node.Content[i].Value
+			if slices.Contains(known, key) {
+				keys = append(keys, key)
+				continue
+			}
+			walk(node.Content[i+1], key+".")
+		}
+	}
+	walk(doc.Content[0], "")
+	return keys
+}
+
+// lookupField finds the field of a key
+func lookupField(cfg *Config, key string) (field, bool) {
+	for _, f := range fields(cfg) {
+		if f.key == key {
+			return f, true
+		}
+	}
+	return field{}, false
+}
+
+// fields lists the leaves of cfg with their dotted YAML keys
+func fields(cfg *Config) []field {
+	var out []field
+	var walk func(v reflect.Value, prefix string)
+	walk = func(v reflect.Value, prefix string) {
+		for i := 0; i < v.NumField(); i++ {
+			name, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("yaml"), ",")
+			if name == "" || name == "-" {
+				continue
+			}
+			value := v.Field(i)
+			if value.Kind() == reflect.Struct {
+				walk(value, prefix+name+".")
+				continue
+			}
+			out = append(out, field{key: prefix + name, value: value})
+		}
+	}
+	walk(reflect.ValueOf(cfg).Elem(), "")
+	return out
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeysAndEnvVar(t *testing.T) {
+	keys := Keys()
+	assert.Contains(t, keys, "aws.max_attempts")
+	assert.Contains(t, keys, "aws.least_privilege.admin_patterns")
+	assert.Contains(t, keys, "notifications.slack.token")
+	assert.NotContains(t, keys, "aws.least_privilege", "only leaves are keys")
+
+	assert.Equal(t, "ARK_AWS_MAX_ATTEMPTS", EnvVar("aws.max_attempts"))
+	assert.Equal(t, "ARK_NOTIFICATIONS_SLACK_TOKEN", EnvVar("notifications.slack.token"))
+}
+
+func TestLoadSources(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("aws:\n  timeout: 45s\n  max_attempts: 3\nkubernetes:\n  writer: native\n"), 0600))
+	env := map[string]string{
+		"ARK_AWS_MAX_ATTEMPTS":          "8",
+		"ARK_KUBERNETES_CONTEXT_ALIAS":  "{{.Cluster}}-{{.Region}}",
+		"ARK_AWS_BREAK_GLASS_ROLES":     "[AdminBreakGlass, Emergency]",
+		"ARK_NOTIFICATIONS_SLACK_TOKEN": "",
+	}
+
+	cfg, sources, err := load(path, func(name string) string { return env[name] })
+	require.NoError(t, err)
+	assert.Equal(t, 45*time.Second, cfg.AWS.Timeout)
+	assert.Equal(t, 8, cfg.AWS.MaxAttempts, "the environment overrides the file")
+	assert.Equal(t, "{{.Cluster}}-{{.Region}}", cfg.Kubernetes.ContextAlias, "text is taken literally")
+	assert.Equal(t, []string{"AdminBreakGlass", "Emergency"}, cfg.AWS.BreakGlass.Roles)
+	assert.Equal(t, map[string]Source{
+		"aws.timeout":              SourceFile,
+		"aws.max_attempts":         SourceEnv,
+		"kubernetes.writer":        SourceFile,
+		"kubernetes.context_alias": SourceEnv,
+		"aws.break_glass.roles":    SourceEnv,
+	}, sources)
+
+	env["ARK_AWS_TIMEOUT"] = "soon"
+	_, _, err = load(path, func(name string) string { return env[name] })
+	assert.ErrorContains(t, err, "ARK_AWS_TIMEOUT")
+}
+
+func TestSet(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ark", "config.yaml")
+
+	// The file and its directory are created on first use
+	require.NoError(t, set(path, "aws.timeout", "45s"))
+	require.NoError(t, set(path, "kubernetes.context_alias", "{{.Cluster}}"))
+	require.NoError(t, set(path, "aws.least_privilege.admin_patterns", "[admin, owner]"))
+
+	cfg, sources, err := load(path, func(string) string { return "" })
+	require.NoError(t, err)
+	assert.Equal(t, 45*time.Second, cfg.AWS.Timeout)
+	assert.Equal(t, "{{.Cluster}}", cfg.Kubernetes.ContextAlias)
+	assert.Equal(t, []string{"admin", "owner"}, cfg.AWS.LeastPrivilege.AdminPatterns)
+	assert.True(t, cfg.AWS.LeastPrivilege.Enabled, "sibling keys keep their defaults")
+	assert.Equal(t, SourceFile, sources["aws.least_privilege.admin_patterns"])
+
+	// Comments and other settings are kept, and the value is replaced in place
+	require.NoError(t, os.WriteFile(path, []byte("# team settings\naws:\n  max_attempts: 3 # flaky VPN\n  proxy: http://proxy:3128\n"), 0600))
+	require.NoError(t, set(path, "aws.max_attempts", "8"))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "# team settings\naws:\n  max_attempts: 8 # flaky VPN\n  proxy: http://proxy:3128\n", string(data))
+
+	assert.ErrorContains(t, set(path, "aws.max_attempts", "lots"), "invalid value for aws.max_attempts")
+	assert.ErrorContains(t, set(path, "aws.timeout", "[1, 2]"), "invalid value for aws.timeout")
+	assert.ErrorContains(t, set(path, "aws.parallelism", "4"), `unknown key "aws.parallelism"`)
+	assert.ErrorContains(t, set(path, "aws", "{}"), `unknown key "aws"`)
+
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "max_attempts: 8", "rejected values leave the file alone")
+}
+
+func TestEffective(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv(ConfigPathEnvVar, path)
+	t.Setenv("ARK_AUDIT_ENABLED", "true")
+	require.NoError(t, os.WriteFile(path, []byte("cache:\n  encrypt: true\n"), 0600))
+
+	settings, err := Effective()
+	require.NoError(t, err)
+	require.Len(t, settings, len(Keys()))
+	byKey := map[string]Setting{}
+	for _, setting := range settings {
+		byKey[setting.Key] = setting
+	}
+	assert.Equal(t, Setting{Key: "cache.encrypt", Value: true, Source: SourceFile}, byKey["cache.encrypt"])
+	assert.Equal(t, Setting{Key: "audit.enabled", Value: true, Source: SourceEnv}, byKey["audit.enabled"])
+	assert.Equal(t, Setting{Key: "aws.max_attempts", Value: 5, Source: SourceDefault}, byKey["aws.max_attempts"])
+}
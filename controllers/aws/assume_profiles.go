@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"fmt"
+
+	"github.com/andresgarcia29/ark-cli/lib/animation"
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+)
+
+// AssumeRoleProfileGenerator writes an assume-role profile to ~/.aws/config for every role of a role ARN list
+type AssumeRoleProfileGenerator struct {
+	Targets []services_aws.AssumeRoleTarget
+	// SourceProfile is the profile whose credentials assume the roles; it must be in the AWS config
+	SourceProfile string
+	// Region is written to the profiles when set
+	Region string
+	// NameTemplate names the profiles (fields of services_aws.ProfileNameData); empty keeps the account-role names
+	NameTemplate string
+	// AssumeYes skips the confirmation of profiles that would change
+	AssumeYes bool
+	// DiffOnly shows what would change in ~/.aws/config without writing it
+	DiffOnly bool
+}
+
+// Run names the profiles, shows what changes in ~/.aws/config, confirms the profiles it would update and writes them
+func (g *AssumeRoleProfileGenerator) Run() error {
+	if _, err := services_aws.ReadProfileFromConfig(g.SourceProfile); err != nil {
+		return fmt.Errorf("source profile %s is not in the AWS config: %w", g.SourceProfile, err)
+	}
+
+	profiles, err := services_aws.NameAssumeRoleProfiles(g.Targets, g.SourceProfile, g.Region, g.NameTemplate)
+	if err != nil {
+		return err
+	}
+	diff, err := services_aws.DiffAssumeRoleProfiles(profiles)
+	if err != nil {
+		return err
+	}
+	for _, line := range profileDiffLines(diff) {
+		fmt.Println(line)
+	}
+	if diff.Empty() {
+		return nil
+	}
+	if g.DiffOnly {
+		fmt.Println("\n--diff-only: ~/.aws/config was not modified")
+		return nil
+	}
+
+	if len(diff.Updated) > 0 && !g.AssumeYes {
+		names := make([]string, 0, len(diff.Updated))
+		for _, change := range diff.Updated {
+			names = append(names, change.Name)
+		}
+		fmt.Println()
+		confirmed, err := animation.Confirm(
+			fmt.Sprintf("Writing ~/.aws/config will update %d existing profile(s). Continue?", len(diff.Updated)),
+			animation.ConfirmOptions{Details: names},
+		)
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			return fmt.Errorf("aborted: ~/.aws/config was not modified")
+		}
+	}
+
+	if err := services_aws.WriteAssumeRoleProfiles(profiles); err != nil {
+		return err
+	}
+	fmt.Printf("✓ Wrote %d assume-role profile(s) using %s\n", len(diff.Added)+len(diff.Updated), g.SourceProfile)
+	return nil
+}
@@ -0,0 +1,52 @@
+package controllers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssumeRoleProfileGenerator(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	configPath := filepath.Join(home, ".aws", "config")
+	require.NoError(t, os.MkdirAll(filepath.Dir(configPath), 0700))
+	require.NoError(t, os.WriteFile(configPath, []byte(`[profile hub]
+sso_start_url = https://example.awsapps.com/start
+sso_region = us-east-1
+sso_account_id = 111111111111
+sso_role_name = Admin
+`), 0600))
+
+	targets, err := services_aws.ParseRoleList("arn:aws:iam::222222222222:role/Deploy prod\narn:aws:iam::333333333333:role/ReadOnly\n")
+	require.NoError(t, err)
+
+	generator := &AssumeRoleProfileGenerator{Targets: targets, SourceProfile: "hub", DiffOnly: true}
+	require.NoError(t, generator.Run())
+	existing, err := services_aws.ReadAllProfilesFromConfig()
+	require.NoError(t, err)
+	assert.Len(t, existing, 1, "--diff-only leaves the config alone")
+
+	generator.DiffOnly = false
+	require.NoError(t, generator.Run())
+	existing, err = services_aws.ReadAllProfilesFromConfig()
+	require.NoError(t, err)
+	roles := map[string]string{}
+	for _, profile := range existing {
+		if profile.ProfileType == services_aws.ProfileTypeAssumeRole {
+			roles[profile.ProfileName] = profile.RoleARN + " via " + profile.SourceProfile
+		}
+	}
+	assert.Equal(t, map[string]string{
+		"prod-deploy":           "arn:aws:iam::222222222222:role/Deploy via hub",
+		"333333333333-readonly": "arn:aws:iam::333333333333:role/ReadOnly via hub",
+	}, roles)
+
+	generator.SourceProfile = "missing"
+	assert.ErrorContains(t, generator.Run(), "source profile missing is not in the AWS config")
+}
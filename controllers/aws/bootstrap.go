@@ -0,0 +1,261 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/andresgarcia29/ark-cli/lib/animation"
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+)
+
+// ProfileBootstrapper writes a profile to ~/.aws/config for every account and role of an SSO portal
+type ProfileBootstrapper struct {
+	SSORegion string
+	StartURL  string
+	// Offline uses the accounts and roles cached by the last online run instead of calling AWS
+	Offline bool
+	// Filter limits the accounts and roles that get a profile
+	Filter services_aws.ProfileFilter
+	// NameTemplate names the profiles (fields of services_aws.ProfileNameData); empty keeps the account-role names
+	NameTemplate string
+	// TagsFrom is the role reading the accounts' tags from AWS Organizations; the zero value doesn't read them
+	TagsFrom services_aws.TagSource
+	SSOOptions
+	// Stages replace the steps that reach outside ark; the zero value uses the real ones
+	Stages SSOStages
+}
+
+// Run discovers the accounts and roles, selects and names their profiles, shows what changes and writes ~/.aws/config
+func (b *ProfileBootstrapper) Run(ctx context.Context) error {
+	if err := b.validate(); err != nil {
+		return err
+	}
+
+	client, profiles, err := b.discover(ctx)
+	if err != nil {
+		return err
+	}
+
+	selected, err := b.selectProfiles(profiles)
+	if err != nil {
+		return err
+	}
+
+	// Write the profiles, confirming the ones that would be dropped
+	if err := writeSSOProfiles(client, selected, b.SSOOptions); err != nil {
+		return err
+	}
+
+	fmt.Println("\n🎉 AWS SSO sso completed!")
+	return nil
+}
+
+// validate checks the filters and the name template before anyone is asked to sign in
+func (b *ProfileBootstrapper) validate() error {
+	if _, err := services_aws.FilterAWSProfiles(nil, b.Filter); err != nil {
+		return err
+	}
+	if b.NameTemplate != "" {
+		if _, err := services_aws.RenderProfileName(b.NameTemplate, services_aws.ProfileNameData{
+			AccountID: "111111111111", AccountName: "account", RoleName: "role",
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// discover lists every account and role of the portal, from AWS or, offline, from the profile cache
+// Online runs refresh the cache with everything found, before filtering
+func (b *ProfileBootstrapper) discover(ctx context.Context) (services_aws.SSOPortalClient, []services_aws.AWSProfile, error) {
+	stages := b.Stages.withDefaults()
+
+	if b.Offline {
+		// No AWS call is made, so it works without network access
+		cache, err := services_aws.LoadProfileCache(b.StartURL)
+		if err != nil {
+			return nil, nil, err
+		}
+		fmt.Printf("📦 Offline: using %d profiles cached on %s\n", len(cache.Profiles), cache.UpdatedAt.Format("2006-01-02 15:04"))
+
+		client, err := stages.NewClient(ctx, b.SSORegion, b.StartURL)
+		if err != nil {
+			return nil, nil, err
+		}
+		return client, cache.Profiles, nil
+	}
+
+	client, accessToken, err := stages.Session(ctx, b.SSORegion, b.StartURL, b.SSOOptions)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Get all accounts and roles
+	fmt.Println()
+	profiles, err := stages.FetchProfiles(ctx, client, accessToken)
+	if err != nil {
+		fmt.Println("Error getting profiles:", err)
+		return nil, nil, err
+	}
+	fmt.Printf("✓ Found %d profiles\n", len(profiles))
+
+	if b.TagsFrom != (services_aws.TagSource{}) {
+		tags, err := stages.FetchTags(ctx, client, accessToken, b.TagsFrom, accountIDs(profiles))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read account tags: %w", err)
+		}
+		profiles = services_aws.ApplyAccountTags(profiles, tags)
+		fmt.Printf("✓ Read the tags of %d account(s)\n", len(tags))
+	}
+
+	if err := services_aws.SaveProfileCache(b.StartURL, profiles); err != nil {
+		fmt.Printf("Warning: failed to cache profiles: %v\n", err)
+	}
+	return client, profiles, nil
+}
+
+// selectProfiles applies the filters and the name template
+// A filter matching nothing is an error: writing no profiles would remove every existing one
+func (b *ProfileBootstrapper) selectProfiles(profiles []services_aws.AWSProfile) ([]services_aws.AWSProfile, error) {
+	selected, err := services_aws.FilterAWSProfiles(profiles, b.Filter)
+	if err != nil {
+		return nil, err
+	}
+	if !b.Filter.Empty() {
+		if len(selected) == 0 && len(b.Filter.Tags) > 0 && !slices.ContainsFunc(profiles, func(p services_aws.AWSProfile) bool { return p.Tags != nil }) {
+			return nil, errors.New("no account tags were read; add --tags-from to read them from AWS Organizations")
+		}
+		if len(selected) == 0 {
+			return nil, errors.New("no account and role matches the filters; ~/.aws/config was not modified")
+		}
+		fmt.Printf("✓ %d of %d profiles match the filters\n", len(selected), len(profiles))
+	}
+
+	if b.NameTemplate == "" {
+		return selected, nil
+	}
+	return services_aws.NameProfiles(selected, b.NameTemplate)
+}
+
+// accountIDs lists the accounts of profiles once each, in order
+func accountIDs(profiles []services_aws.AWSProfile) []string {
+	var ids []string
+	for _, profile := range profiles {
+		if !slices.Contains(ids, profile.AccountID) {
+			ids = append(ids, profile.AccountID)
+		}
+	}
+	return ids
+}
+
+// fetchAccountTags signs in to the tag source role through the portal and reads the tags of every account
+// behind a status spinner
+func fetchAccountTags(ctx context.Context, provider services_aws.CredentialProvider, accessToken string, source services_aws.TagSource, accountIDs []string) (services_aws.AccountTags, error) {
+	var tags services_aws.AccountTags
+	err := animation.ShowStatus(ctx, fmt.Sprintf("Reading the tags of %d account(s)", len(accountIDs)), func(ctx context.Context, status func(string)) error {
+		creds, err := provider.GetRoleCredentials(ctx, accessToken, source.AccountID, source.RoleName)
+		if err != nil {
+			return fmt.Errorf("failed to sign in to %s/%s: %w", source.AccountID, source.RoleName, err)
+		}
+		client, err := services_aws.NewOrganizationsClient(ctx, creds)
+		if err != nil {
+			return err
+		}
+		tags, err = services_aws.CollectAccountTags(ctx, client, accountIDs)
+		return err
+	})
+	return tags, err
+}
+
+// fetchProfiles collects every account+role profile behind a status spinner
+func fetchProfiles(ctx context.Context, lister services_aws.ProfileLister, accessToken string) ([]services_aws.AWSProfile, error) {
+	var profiles []services_aws.AWSProfile
+	err := animation.ShowStatus(ctx, "Fetching accounts and roles", func(ctx context.Context, status func(string)) error {
+		var err error
+		profiles, err = services_aws.CollectProfiles(ctx, lister, accessToken, status)
+		return err
+	})
+	return profiles, err
+}
+
+// writeSSOProfiles shows what writing ~/.aws/config would change, confirms the profiles it would remove and writes it
+// With opts.DiffOnly the changes are only shown; with opts.Incremental only the new profiles are added
+func writeSSOProfiles(client services_aws.ProfileConfigWriter, profiles []services_aws.AWSProfile, opts SSOOptions) error {
+	diff, err := client.DiffConfigFile(profiles)
+	if err != nil {
+		fmt.Println("Error reading existing profiles:", err)
+		return err
+	}
+	if opts.Incremental {
+		diff = diff.AddedOnly()
+	}
+	fmt.Println()
+	for _, line := range profileDiffLines(diff) {
+		fmt.Println(line)
+	}
+	if opts.DiffOnly {
+		fmt.Println("\n--diff-only: ~/.aws/config was not modified")
+		return nil
+	}
+
+	if opts.Incremental {
+		if diff.Empty() {
+			return nil
+		}
+		fmt.Println("\nAdding new profiles to ~/.aws/config...")
+		added, err := client.AppendNewProfiles(profiles)
+		if err != nil {
+			fmt.Println("Error writing config file:", err)
+			return err
+		}
+		fmt.Printf("✓ Added %d profile(s), existing profiles were left untouched\n", len(added))
+		return nil
+	}
+
+	if len(diff.Removed) > 0 && !opts.AssumeYes {
+		fmt.Println()
+		confirmed, err := animation.Confirm(
+			fmt.Sprintf("Writing ~/.aws/config will remove %d existing profile(s). Continue?", len(diff.Removed)),
+			animation.ConfirmOptions{Details: diff.Removed, Destructive: true},
+		)
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			return fmt.Errorf("aborted: ~/.aws/config was not modified")
+		}
+	}
+
+	fmt.Println("\nWriting profiles to ~/.aws/config...")
+	if err := client.WriteConfigFile(profiles); err != nil {
+		fmt.Println("Error writing config file:", err)
+		return err
+	}
+	fmt.Println("✓ Config file updated successfully")
+	return nil
+}
+
+// profileDiffLines describes a ProfileDiff: + added, ~ updated with its changed settings, - removed
+func profileDiffLines(diff services_aws.ProfileDiff) []string {
+	if diff.Empty() {
+		return []string{fmt.Sprintf("No changes to ~/.aws/config (%d profile(s) up to date)", diff.Unchanged)}
+	}
+
+	lines := []string{fmt.Sprintf("Changes to ~/.aws/config: %d to add, %d to update, %d to remove, %d unchanged",
+		len(diff.Added), len(diff.Updated), len(diff.Removed), diff.Unchanged)}
+	for _, name := range diff.Added {
+		lines = append(lines, "  + "+name)
+	}
+	for _, change := range diff.Updated {
+		lines = append(lines, "  ~ "+change.Name)
+		for _, setting := range change.Changes {
+			lines = append(lines, "      "+setting)
+		}
+	}
+	for _, name := range diff.Removed {
+		lines = append(lines, "  - "+name)
+	}
+	return lines
+}
@@ -0,0 +1,214 @@
+package controllers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfileBootstrapperOffline(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	startURL := "https://example.awsapps.com/start"
+	require.NoError(t, services_aws.SaveProfileCache(startURL, []services_aws.AWSProfile{
+		{AccountID: "111111111111", AccountName: "dev", RoleName: "ReadOnlyAccess"},
+		{AccountID: "111111111111", AccountName: "dev", RoleName: "AdministratorAccess"},
+		{AccountID: "222222222222", AccountName: "prod", RoleName: "ReadOnlyAccess"},
+	}))
+
+	bootstrapper := &ProfileBootstrapper{
+		SSORegion:    "us-east-1",
+		StartURL:     startURL,
+		Offline:      true,
+		Filter:       services_aws.ProfileFilter{Roles: []string{"readonly*"}},
+		NameTemplate: "{{.AccountName}}-ro",
+		SSOOptions:   SSOOptions{AssumeYes: true},
+	}
+	require.NoError(t, bootstrapper.Run(context.Background()))
+
+	existing, err := services_aws.ReadAllProfilesFromConfig()
+	require.NoError(t, err)
+	names := map[string]string{}
+	for _, profile := range existing {
+		names[profile.ProfileName] = profile.RoleName
+	}
+	assert.Equal(t, map[string]string{"dev-ro": "ReadOnlyAccess", "prod-ro": "ReadOnlyAccess"}, names)
+
+	bootstrapper.Filter = services_aws.ProfileFilter{Accounts: []string{"staging"}}
+	assert.ErrorContains(t, bootstrapper.Run(context.Background()), "no account and role matches the filters")
+}
+
+func TestProfileBootstrapperValidate(t *testing.T) {
+	bootstrapper := &ProfileBootstrapper{Filter: services_aws.ProfileFilter{Accounts: []string{"[prod"}}}
+	assert.ErrorContains(t, bootstrapper.validate(), "invalid filter pattern")
+
+	bootstrapper = &ProfileBootstrapper{NameTemplate: "{{.Account}}"}
+	assert.ErrorContains(t, bootstrapper.validate(), "invalid profile name template")
+
+	bootstrapper = &ProfileBootstrapper{NameTemplate: "{{.AccountName}}-{{.RoleName}}"}
+	assert.NoError(t, bootstrapper.validate())
+}
+
+func TestWriteSSOProfilesDiffOnly(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	config := "[profile legacy]\nrole_arn = arn:aws:iam::222222222222:role/Legacy\nsource_profile = dev-readonly\n"
+	configPath := filepath.Join(home, ".aws", "config")
+	require.NoError(t, os.MkdirAll(filepath.Dir(configPath), 0700))
+	require.NoError(t, os.WriteFile(configPath, []byte(config), 0600))
+
+	client := &services_aws.SSOClient{StartURL: "https://example.awsapps.com/start", Region: "us-east-1"}
+	profiles := []services_aws.AWSProfile{{AccountID: "111111111111", AccountName: "dev", RoleName: "ReadOnly"}}
+
+	// Removing legacy needs a confirmation, but --diff-only never gets that far
+	require.NoError(t, writeSSOProfiles(client, profiles, SSOOptions{DiffOnly: true}))
+	data, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, config, string(data))
+}
+
+func TestWriteSSOProfilesIncremental(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	config := "[profile legacy]\nrole_arn = arn:aws:iam::222222222222:role/Legacy\nsource_profile = dev-readonly\n"
+	configPath := filepath.Join(home, ".aws", "config")
+	require.NoError(t, os.MkdirAll(filepath.Dir(configPath), 0700))
+	require.NoError(t, os.WriteFile(configPath, []byte(config), 0600))
+
+	client := &services_aws.SSOClient{StartURL: "https://example.awsapps.com/start", Region: "us-east-1"}
+	profiles := []services_aws.AWSProfile{{AccountID: "111111111111", AccountName: "dev", RoleName: "ReadOnly"}}
+
+	// Nothing is removed, so there is nothing to confirm
+	require.NoError(t, writeSSOProfiles(client, profiles, SSOOptions{Incremental: true}))
+
+	existing, err := services_aws.ReadAllProfilesFromConfig()
+	require.NoError(t, err)
+	var names []string
+	for _, profile := range existing {
+		names = append(names, profile.ProfileName)
+	}
+	assert.ElementsMatch(t, []string{"legacy", "dev-readonly"}, names)
+}
+
+func TestProfileDiffLines(t *testing.T) {
+	assert.Equal(t, []string{"No changes to ~/.aws/config (3 profile(s) up to date)"},
+		profileDiffLines(services_aws.ProfileDiff{Unchanged: 3}))
+
+	lines := profileDiffLines(services_aws.ProfileDiff{
+		Added:     []string{"sandbox-admin"},
+		Updated:   []services_aws.ProfileChange{{Name: "prod-admin", Changes: []string{"sso_role_name: Admin → AdminAccess"}}},
+		Removed:   []string{"legacy"},
+		Unchanged: 2,
+	})
+	assert.Equal(t, []string{
+		"Changes to ~/.aws/config: 1 to add, 1 to update, 1 to remove, 2 unchanged",
+		"  + sandbox-admin",
+		"  ~ prod-admin",
+		"      sso_role_name: Admin → AdminAccess",
+		"  - legacy",
+	}, lines)
+}
+
+func TestProfileBootstrapperOnline(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	startURL := "https://example.awsapps.com/start"
+	portal := newFakeSSOPortal("us-east-1", startURL)
+	portal.accounts = []services_aws.Account{{AccountID: "111111111111", AccountName: "dev"}}
+	portal.roles = map[string][]services_aws.Role{"111111111111": {{RoleName: "ReadOnlyAccess"}}}
+
+	bootstrapper := &ProfileBootstrapper{
+		SSORegion: "us-east-1",
+		StartURL:  startURL,
+		Stages: SSOStages{
+			NewClient: func(ctx context.Context, region, startURL string) (services_aws.SSOPortalClient, error) {
+				return portal, nil
+			},
+			CachedToken:      func(string, string, time.Time) (*services_aws.CachedToken, bool) { return nil, false },
+			OpenBrowser:      func(string) error { return nil },
+			BrowserAvailable: func() bool { return false },
+			FetchProfiles: func(ctx context.Context, lister services_aws.ProfileLister, accessToken string) ([]services_aws.AWSProfile, error) {
+				assert.Equal(t, "fresh-token", accessToken)
+				return services_aws.CollectProfiles(ctx, lister, accessToken, func(string) {})
+			},
+		},
+	}
+	require.NoError(t, bootstrapper.Run(context.Background()))
+
+	profile, err := services_aws.ReadProfileFromConfig("dev-readonlyaccess")
+	require.NoError(t, err)
+	require.NotNil(t, profile)
+	assert.Equal(t, "111111111111", profile.AccountID)
+
+	cache, err := services_aws.LoadProfileCache(startURL)
+	require.NoError(t, err)
+	assert.Len(t, cache.Profiles, 1, "online runs refresh the offline cache")
+}
+
+func TestProfileBootstrapperAccountTags(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	startURL := "https://example.awsapps.com/start"
+	portal := newFakeSSOPortal("us-east-1", startURL)
+	portal.accounts = []services_aws.Account{
+		{AccountID: "111111111111", AccountName: "dev"},
+		{AccountID: "222222222222", AccountName: "prod"},
+	}
+	portal.roles = map[string][]services_aws.Role{
+		"111111111111": {{RoleName: "ReadOnly"}},
+		"222222222222": {{RoleName: "ReadOnly"}},
+	}
+
+	bootstrapper := &ProfileBootstrapper{
+		SSORegion: "us-east-1",
+		StartURL:  startURL,
+		Filter:    services_aws.ProfileFilter{Tags: map[string]string{"env": "prod"}},
+		TagsFrom:  services_aws.TagSource{AccountID: "999999999999", RoleName: "OrgReadOnly"},
+		Stages: SSOStages{
+			NewClient: func(ctx context.Context, region, startURL string) (services_aws.SSOPortalClient, error) {
+				return portal, nil
+			},
+			CachedToken:      func(string, string, time.Time) (*services_aws.CachedToken, bool) { return nil, false },
+			OpenBrowser:      func(string) error { return nil },
+			BrowserAvailable: func() bool { return false },
+			FetchProfiles: func(ctx context.Context, lister services_aws.ProfileLister, accessToken string) ([]services_aws.AWSProfile, error) {
+				return services_aws.CollectProfiles(ctx, lister, accessToken, func(string) {})
+			},
+			FetchTags: func(ctx context.Context, provider services_aws.CredentialProvider, accessToken string, source services_aws.TagSource, accountIDs []string) (services_aws.AccountTags, error) {
+				assert.Equal(t, "OrgReadOnly", source.RoleName)
+				assert.ElementsMatch(t, []string{"111111111111", "222222222222"}, accountIDs)
+				return services_aws.AccountTags{
+					"111111111111": {"env": "dev"},
+					"222222222222": {"env": "prod"},
+				}, nil
+			},
+		},
+	}
+	require.NoError(t, bootstrapper.Run(context.Background()))
+
+	profiles, err := services_aws.ReadAllProfilesFromConfig()
+	require.NoError(t, err)
+	require.Len(t, profiles, 1, "only the accounts matching the tags get a profile")
+	assert.Equal(t, "prod-readonly", profiles[0].ProfileName)
+	assert.Equal(t, map[string]string{"env": "prod"}, profiles[0].Tags)
+
+	cache, err := services_aws.LoadProfileCache(startURL)
+	require.NoError(t, err)
+	require.Len(t, cache.Profiles, 2)
+	assert.NotNil(t, cache.Profiles[0].Tags, "tags are cached for offline runs")
+}
+
+func TestProfileBootstrapperTagFilterWithoutTags(t *testing.T) {
+	bootstrapper := &ProfileBootstrapper{Filter: services_aws.ProfileFilter{Tags: map[string]string{"env": "prod"}}}
+	_, err := bootstrapper.selectProfiles([]services_aws.AWSProfile{{AccountID: "111111111111", RoleName: "ReadOnly"}})
+	assert.ErrorContains(t, err, "--tags-from")
+}
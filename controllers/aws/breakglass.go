@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/andresgarcia29/ark-cli/audit"
+	ark_config "github.com/andresgarcia29/ark-cli/config"
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+)
+
+// AuthorizeBreakGlass gates logins to the roles tagged in aws.break_glass.roles
+// Tagged roles need breakGlass and a justification. The access is written to the audit log, even with
+// audit disabled, and posted to aws.break_glass.webhook before any credentials are issued
+func AuthorizeBreakGlass(ctx context.Context, profileName string, breakGlass bool, justification string) error {
+	profile, err := services_aws.ReadProfileFromConfig(profileName)
+	if err != nil {
+		return err
+	}
+	return authorizeBreakGlass(ctx, *profile, breakGlass, justification, ark_config.Get().AWS.BreakGlass, audit.Append)
+}
+
+func authorizeBreakGlass(ctx context.Context, profile services_aws.ProfileConfig, breakGlass bool, justification string, settings ark_config.BreakGlassConfig, record func(audit.Event) error) error {
+	accountID, roleName := services_aws.ProfileAccountAndRole(profile)
+	if !services_aws.IsBreakGlassRole(profile, settings) {
+		if breakGlass {
+			fmt.Printf("ℹ️  %s is not a break-glass role, --break-glass has no effect\n", roleName)
+		}
+		return nil
+	}
+
+	if !breakGlass {
+		return fmt.Errorf("profile %s uses the break-glass role %s: rerun with --break-glass and a --justification", profile.ProfileName, roleName)
+	}
+	justification = strings.TrimSpace(justification)
+	if justification == "" {
+		return fmt.Errorf("a justification is required for break-glass access to %s", profile.ProfileName)
+	}
+
+	// The same timestamp goes to the log and the webhook
+	event := audit.Event{
+		Time:          time.Now().UTC(),
+		Event:         audit.EventBreakGlass,
+		Profile:       profile.ProfileName,
+		AccountID:     accountID,
+		Role:          roleName,
+		Justification: justification,
+	}
+	if err := record(event); err != nil {
+		return fmt.Errorf("failed to record break-glass access: %w", err)
+	}
+
+	// The local record is what compliance relies on, so an unreachable webhook doesn't block an emergency
+	if settings.Webhook != "" {
+		if err := audit.Notify(ctx, settings.Webhook, event); err != nil {
+			fmt.Printf("⚠️  Warning: failed to notify the break-glass webhook: %v\n", err)
+		}
+	}
+
+	fmt.Printf("🚨 Break-glass access to %s recorded\n", profile.ProfileName)
+	return nil
+}
@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/andresgarcia29/ark-cli/audit"
+	ark_config "github.com/andresgarcia29/ark-cli/config"
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthorizeBreakGlass(t *testing.T) {
+	breakGlassProfile := services_aws.ProfileConfig{ProfileName: "prod-emergency", AccountID: "222222222222", RoleName: "AdminBreakGlass"}
+	regularProfile := services_aws.ProfileConfig{ProfileName: "prod-readonly", AccountID: "222222222222", RoleName: "ReadOnly"}
+	settings := ark_config.BreakGlassConfig{Roles: []string{"AdminBreakGlass"}}
+
+	tests := []struct {
+		name          string
+		profile       services_aws.ProfileConfig
+		breakGlass    bool
+		justification string
+		recordErr     error
+		wantErr       string
+		wantRecorded  bool
+	}{
+		{
+			name:    "regular role needs nothing",
+			profile: regularProfile,
+		},
+		{
+			name:       "flag on a regular role is ignored",
+			profile:    regularProfile,
+			breakGlass: true,
+		},
+		{
+			name:    "break-glass role without the flag",
+			profile: breakGlassProfile,
+			wantErr: "rerun with --break-glass",
+		},
+		{
+			name:          "break-glass role with a blank justification",
+			profile:       breakGlassProfile,
+			breakGlass:    true,
+			justification: "   ",
+			wantErr:       "justification is required",
+		},
+		{
+			name:          "audit log cannot be written",
+			profile:       breakGlassProfile,
+			breakGlass:    true,
+			justification: "INC-1234",
+			recordErr:     errors.New("disk full"),
+			wantErr:       "failed to record break-glass access: disk full",
+			wantRecorded:  true,
+		},
+		{
+			name:          "break-glass role with a justification",
+			profile:       breakGlassProfile,
+			breakGlass:    true,
+			justification: " INC-1234 ",
+			wantRecorded:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var recorded []audit.Event
+			record := func(event audit.Event) error {
+				recorded = append(recorded, event)
+				return tt.recordErr
+			}
+
+			err := authorizeBreakGlass(context.Background(), tt.profile, tt.breakGlass, tt.justification, settings, record)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			if !tt.wantRecorded {
+				assert.Empty(t, recorded)
+				return
+			}
+			require.Len(t, recorded, 1)
+			assert.Equal(t, audit.EventBreakGlass, recorded[0].Event)
+			assert.Equal(t, "222222222222", recorded[0].AccountID)
+			assert.Equal(t, "AdminBreakGlass", recorded[0].Role)
+			assert.Equal(t, "INC-1234", recorded[0].Justification)
+		})
+	}
+}
+
+func TestAuthorizeBreakGlassWebhook(t *testing.T) {
+	var received audit.Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer server.Close()
+
+	profile := services_aws.ProfileConfig{ProfileName: "prod-emergency", RoleARN: "arn:aws:iam::222222222222:role/AdminBreakGlass"}
+	settings := ark_config.BreakGlassConfig{Roles: []string{"AdminBreakGlass"}, Webhook: server.URL}
+	record := func(audit.Event) error { return nil }
+
+	require.NoError(t, authorizeBreakGlass(context.Background(), profile, true, "INC-1234", settings, record))
+	assert.Equal(t, audit.EventBreakGlass, received.Event)
+	assert.Equal(t, "prod-emergency", received.Profile)
+	assert.Equal(t, "INC-1234", received.Justification)
+
+	// An unreachable webhook only warns
+	server.Close()
+	assert.NoError(t, authorizeBreakGlass(context.Background(), profile, true, "INC-1234", settings, record))
+}
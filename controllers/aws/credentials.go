@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	ark_config "github.com/andresgarcia29/ark-cli/config"
+	"github.com/andresgarcia29/ark-cli/lib/animation"
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+)
+
+// SyncCredentials writes role credentials for every SSO profile matching patterns to the credentials file,
+// for tools that can't read SSO profiles. Break-glass roles are skipped: they need `ark aws login --break-glass`
+func SyncCredentials(ctx context.Context, patterns []string) error {
+	profiles, err := services_aws.ReadAllProfilesFromConfig()
+	if err != nil {
+		return err
+	}
+	selected, err := services_aws.FilterSSOProfiles(profiles, patterns)
+	if err != nil {
+		return err
+	}
+	selected = WithoutBreakGlass(selected, ark_config.Get().AWS.BreakGlass)
+	if len(selected) == 0 {
+		return fmt.Errorf("no SSO profiles to sync")
+	}
+
+	fmt.Printf("🔐 Fetching credentials for %d profile(s)...\n", len(selected))
+	var results []services_aws.CredentialsResult
+	err = animation.ShowDetailedProgressBar(len(selected), func(update func(animation.ProgressUpdate)) error {
+		results = services_aws.FetchSSOCredentials(ctx, selected, services_aws.NewCredentialProvider, func(result services_aws.CredentialsResult) {
+			update(animation.ProgressUpdate{
+				Item:     result.Profile.ProfileName,
+				Account:  result.Profile.AccountID,
+				Phase:    "get-role-credentials",
+				Duration: result.Duration,
+				Err:      result.Err,
+			})
+		})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	written, err := services_aws.SaveSyncedCredentials(results)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("\n✓ Wrote credentials for %d/%d profile(s)\n", written, len(selected))
+
+	failed := len(selected) - written
+	if failed > 0 {
+		for _, result := range results {
+			if result.Err != nil {
+				fmt.Printf("  ❌ %s: %v\n", result.Profile.ProfileName, result.Err)
+			}
+		}
+		return fmt.Errorf("%d profile(s) could not be synced", failed)
+	}
+	return nil
+}
+
+// CheckCredentials checks with sts:GetCallerIdentity that every profile matching patterns can get credentials,
+// telling the working profiles from those that need a login and those that are misconfigured. Break-glass roles
+// are skipped: using them is an emergency, not a health check
+func CheckCredentials(ctx context.Context, patterns []string) ([]services_aws.CredentialCheck, error) {
+	profiles, err := services_aws.ReadAllProfilesFromConfig()
+	if err != nil {
+		return nil, err
+	}
+	selected, err := services_aws.FilterProfiles(profiles, patterns)
+	if err != nil {
+		return nil, err
+	}
+	selected = WithoutBreakGlass(selected, ark_config.Get().AWS.BreakGlass)
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no profiles to check")
+	}
+
+	fmt.Printf("🩺 Checking credentials of %d profile(s)...\n", len(selected))
+	var checks []services_aws.CredentialCheck
+	err = animation.ShowDetailedProgressBar(len(selected), func(update func(animation.ProgressUpdate)) error {
+		checks = services_aws.CheckCredentials(ctx, selected, services_aws.NewIdentityChecker, func(check services_aws.CredentialCheck) {
+			update(animation.ProgressUpdate{
+				Item:     check.Profile.ProfileName,
+				Account:  check.Profile.AccountID,
+				Phase:    "get-caller-identity",
+				Duration: check.Duration,
+				Err:      check.Err,
+			})
+		})
+		return nil
+	})
+	return checks, err
+}
+
+// WithoutBreakGlass drops the profiles using break-glass roles, telling the user about each one
+func WithoutBreakGlass(profiles []services_aws.ProfileConfig, settings ark_config.BreakGlassConfig) []services_aws.ProfileConfig {
+	var kept []services_aws.ProfileConfig
+	for _, profile := range profiles {
+		if services_aws.IsBreakGlassRole(profile, settings) {
+			fmt.Printf("⏭️  Skipping %s: break-glass roles need `ark aws login --break-glass`\n", profile.ProfileName)
+			continue
+		}
+		kept = append(kept, profile)
+	}
+	return kept
+}
@@ -0,0 +1,21 @@
+package controllers
+
+import (
+	"testing"
+
+	ark_config "github.com/andresgarcia29/ark-cli/config"
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithoutBreakGlass(t *testing.T) {
+	profiles := []services_aws.ProfileConfig{
+		{ProfileName: "prod-readonly", RoleName: "ReadOnly"},
+		{ProfileName: "prod-emergency", RoleName: "AdminBreakGlass"},
+	}
+
+	kept := WithoutBreakGlass(profiles, ark_config.BreakGlassConfig{Roles: []string{"AdminBreakGlass"}})
+	assert.Equal(t, profiles[:1], kept)
+
+	assert.Equal(t, profiles, WithoutBreakGlass(profiles, ark_config.BreakGlassConfig{}))
+}
@@ -4,18 +4,27 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/andresgarcia29/ark-cli/audit"
+	ark_config "github.com/andresgarcia29/ark-cli/config"
 	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
 )
 
 // AttemptLoginWithRetry handles login with automatic retry
 func AttemptLoginWithRetry(ctx context.Context, profileName string, setAsDefault bool, ssoRegion string, ssoStartURL string) error {
+	warnIfDeprecated(ctx, profileName)
+
 	// First login attempt
 	if err := services_aws.LoginWithProfile(ctx, profileName, setAsDefault); err != nil {
+		// Profiles without SSO (e.g. web identity) can't be fixed by an SSO login
+		if ssoStartURL == "" {
+			return err
+		}
+
 		fmt.Printf("❌ Login failed: %v\n", err)
 		fmt.Println("🔄 Attempting SSO login...")
 
-		// Perform SSO login
-		if ssoErr := AWSSSOLogin(ctx, ssoRegion, ssoStartURL, false); ssoErr != nil {
+		// Perform SSO login, forcing a new token: the cached one may be valid on paper but revoked
+		if ssoErr := AWSSSOLogin(ctx, ssoRegion, ssoStartURL, SSOOptions{Force: true}); ssoErr != nil {
 			return fmt.Errorf("SSO login failed: %v", ssoErr)
 		}
 
@@ -29,3 +38,95 @@ func AttemptLoginWithRetry(ctx context.Context, profileName string, setAsDefault
 
 	return nil
 }
+
+// warnIfDeprecated prints the team's deprecation notice for a profile, if any
+// Login still proceeds: the policy only informs during account migrations
+func warnIfDeprecated(ctx context.Context, profileName string) {
+	profile, err := services_aws.ReadProfileFromConfig(profileName)
+	if err != nil {
+		return
+	}
+	if deprecation, ok := services_aws.CurrentDeprecationPolicy(ctx).Lookup(*profile); ok {
+		fmt.Printf("⚠️  Warning: %s\n", deprecation.Warning(profileName))
+	}
+}
+
+// AccountRoleLogin describes a login to an account and role that has no profile in ~/.aws/config yet
+type AccountRoleLogin struct {
+	SSORegion string
+	StartURL  string
+	AccountID string
+	RoleName  string
+	// ProfileName names the credentials, and the profile when WriteProfile is set
+	// It defaults to the name `ark aws sso` would give the profile
+	ProfileName   string
+	WriteProfile  bool
+	SetAsDefault  bool
+	BreakGlass    bool
+	Justification string
+}
+
+// LoginWithAccountRole resolves an account and role through the SSO portal and writes the role's credentials
+// It runs an SSO login first when no token is cached, and returns the profile name the credentials were written under
+func LoginWithAccountRole(ctx context.Context, login AccountRoleLogin) (string, error) {
+	accessToken, err := ssoAccessToken(ctx, login.SSORegion, login.StartURL)
+	if err != nil {
+		return "", err
+	}
+
+	client, err := services_aws.NewSSOClient(ctx, login.SSORegion, login.StartURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to create SSO client: %w", err)
+	}
+	profile, err := services_aws.ResolveAccountRole(ctx, client, accessToken, login.AccountID, login.RoleName)
+	if err != nil {
+		return "", err
+	}
+
+	profileName := login.ProfileName
+	if profileName == "" {
+		profileName = services_aws.ProfileNameFor(*profile)
+	}
+	profileConfig := services_aws.ProfileConfig{
+		ProfileName: profileName,
+		ProfileType: services_aws.ProfileTypeSSO,
+		AccountID:   profile.AccountID,
+		RoleName:    profile.RoleName,
+	}
+	if err := authorizeBreakGlass(ctx, profileConfig, login.BreakGlass, login.Justification, ark_config.Get().AWS.BreakGlass, audit.Append); err != nil {
+		return "", err
+	}
+
+	creds, err := client.GetRoleCredentials(ctx, accessToken, profile.AccountID, profile.RoleName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get role credentials: %w", err)
+	}
+
+	if login.WriteProfile {
+		if _, err := client.AddProfile(profileName, *profile); err != nil {
+			return "", err
+		}
+		fmt.Printf("✓ Profile %s added to the AWS config file\n", profileName)
+	}
+	if err := services_aws.WriteAccountRoleCredentials(profileName, *profile, creds, login.SetAsDefault); err != nil {
+		return "", err
+	}
+	return profileName, nil
+}
+
+// ssoAccessToken returns the cached SSO token of a portal, running an SSO login when there is none
+func ssoAccessToken(ctx context.Context, ssoRegion, ssoStartURL string) (string, error) {
+	if cached, err := services_aws.ReadTokenFromCache(ssoStartURL, ssoRegion); err == nil {
+		return cached.AccessToken, nil
+	}
+
+	fmt.Println("🔄 No valid SSO session, attempting SSO login...")
+	if err := AWSSSOLogin(ctx, ssoRegion, ssoStartURL, SSOOptions{}); err != nil {
+		return "", fmt.Errorf("SSO login failed: %v", err)
+	}
+	cached, err := services_aws.ReadTokenFromCache(ssoStartURL, ssoRegion)
+	if err != nil {
+		return "", err
+	}
+	return cached.AccessToken, nil
+}
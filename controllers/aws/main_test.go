@@ -0,0 +1,25 @@
+package controllers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	ark_config "github.com/andresgarcia29/ark-cli/config"
+)
+
+// TestMain isolates the tests from the developer's ark config: ark_config.Get loads it once per process, so
+// settings like aws.clock_skew would otherwise change what the SSO token reuse tests see
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "ark-controllers-aws-")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv(ark_config.ConfigPathEnvVar, filepath.Join(dir, "config.yaml"))
+	os.Unsetenv(ark_config.EnvVar("aws.clock_skew"))
+
+	code := m.Run()
+
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
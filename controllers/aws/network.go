@@ -0,0 +1,28 @@
+package controllers
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/andresgarcia29/ark-cli/lib"
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+)
+
+// CheckNetwork checks every endpoint in parallel and returns the results in the order of endpoints
+func CheckNetwork(ctx context.Context, endpoints []services_aws.NetworkEndpoint, check func(ctx context.Context, endpoint services_aws.NetworkEndpoint) services_aws.NetworkCheck) []services_aws.NetworkCheck {
+	keys := make([]string, len(endpoints))
+	for i := range endpoints {
+		keys[i] = strconv.Itoa(i)
+	}
+
+	results := make([]services_aws.NetworkCheck, len(endpoints))
+	lib.ProcessAccountsInParallel(ctx, keys, lib.DefaultParallelConfig(),
+		func(ctx context.Context, key string) (bool, error) {
+			i, _ := strconv.Atoi(key)
+			results[i] = check(ctx, endpoints[i])
+			// An unreachable endpoint is the answer, not a reason to check it again
+			return results[i].OK(), nil
+		},
+	)
+	return results
+}
@@ -0,0 +1,37 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckNetwork(t *testing.T) {
+	endpoints := []services_aws.NetworkEndpoint{
+		{Service: "sso_oidc", Region: "us-east-1", URL: "https://oidc.us-east-1.amazonaws.com"},
+		{Service: "sts", Region: "us-east-1", URL: "https://sts.us-east-1.amazonaws.com"},
+		{Service: "eks", Region: "us-east-1", URL: "https://eks.us-east-1.amazonaws.com"},
+	}
+	var calls atomic.Int32
+	check := func(ctx context.Context, endpoint services_aws.NetworkEndpoint) services_aws.NetworkCheck {
+		calls.Add(1)
+		if endpoint.Service == "eks" {
+			return services_aws.NetworkCheck{NetworkEndpoint: endpoint, Err: errors.New("timeout")}
+		}
+		return services_aws.NetworkCheck{NetworkEndpoint: endpoint, Status: 403}
+	}
+
+	results := CheckNetwork(context.Background(), endpoints, check)
+	require.Len(t, results, 3)
+	for i, result := range results {
+		assert.Equal(t, endpoints[i], result.NetworkEndpoint)
+	}
+	assert.True(t, results[0].OK())
+	assert.False(t, results[2].OK())
+	assert.Equal(t, int32(3), calls.Load(), "unreachable endpoints are not checked again")
+}
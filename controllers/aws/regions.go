@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/andresgarcia29/ark-cli/lib"
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+)
+
+// RegionLatency is a region enabled for the account with the round trip to its STS endpoint
+type RegionLatency struct {
+	services_aws.Region
+	Latency time.Duration
+	Err     error
+}
+
+// ProbeRegions measures the round trip to the STS endpoint of every region in parallel
+// Regions are returned fastest first, with the ones that could not be reached last
+func ProbeRegions(ctx context.Context, regions []services_aws.Region, probe func(ctx context.Context, endpoint string) (time.Duration, error)) []RegionLatency {
+	byName := make(map[string]services_aws.Region, len(regions))
+	names := make([]string, 0, len(regions))
+	for _, region := range regions {
+		byName[region.Name] = region
+		names = append(names, region.Name)
+	}
+
+	var mu sync.Mutex
+	results := make([]RegionLatency, 0, len(regions))
+	lib.ProcessAccountsInParallel(ctx, names, lib.DefaultParallelConfig(),
+		func(ctx context.Context, name string) (time.Duration, error) {
+			latency, err := probe(ctx, services_aws.STSEndpoint(name))
+			mu.Lock()
+			results = append(results, RegionLatency{Region: byName[name], Latency: latency, Err: err})
+			mu.Unlock()
+			if err != nil {
+				// A slow or unreachable region is the answer, not a reason to probe it again
+				return 0, lib.Permanent(err)
+			}
+			return latency, nil
+		},
+	)
+
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if (a.Err == nil) != (b.Err == nil) {
+			return a.Err == nil
+		}
+		if a.Latency != b.Latency {
+			return a.Latency < b.Latency
+		}
+		return a.Name < b.Name
+	})
+	return results
+}
@@ -0,0 +1,48 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProbeRegions(t *testing.T) {
+	regions := []services_aws.Region{
+		{Name: "ap-southeast-2", OptInStatus: "opt-in-not-required"},
+		{Name: "eu-south-2", OptInStatus: "opted-in"},
+		{Name: "us-east-1", OptInStatus: "opt-in-not-required"},
+		{Name: "us-west-2", OptInStatus: "opt-in-not-required"},
+	}
+	latencies := map[string]time.Duration{
+		"https://sts.ap-southeast-2.amazonaws.com": 180 * time.Millisecond,
+		"https://sts.us-east-1.amazonaws.com":      20 * time.Millisecond,
+		"https://sts.us-west-2.amazonaws.com":      70 * time.Millisecond,
+	}
+	calls := 0
+	probe := func(ctx context.Context, endpoint string) (time.Duration, error) {
+		calls++
+		if latency, ok := latencies[endpoint]; ok {
+			return latency, nil
+		}
+		return 0, errors.New("timeout")
+	}
+
+	results := ProbeRegions(context.Background(), regions, probe)
+	require.Len(t, results, 4)
+
+	var order []string
+	for _, result := range results {
+		order = append(order, result.Name)
+	}
+	assert.Equal(t, "us-east-1,us-west-2,ap-southeast-2,eu-south-2", strings.Join(order, ","), "fastest first, unreachable last")
+	assert.Equal(t, 20*time.Millisecond, results[0].Latency)
+	assert.EqualError(t, results[3].Err, "timeout")
+	assert.Equal(t, "opted-in", results[3].OptInStatus)
+	assert.Equal(t, 4, calls, "unreachable regions are not probed again")
+}
@@ -2,37 +2,338 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/andresgarcia29/ark-cli/lib"
+	"github.com/andresgarcia29/ark-cli/lib/animation"
+	"github.com/andresgarcia29/ark-cli/notify"
 	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
 )
 
-func AWSSSOLogin(ctx context.Context, SSORegion string, SSOStartURL string, boostraping bool) error {
+// minReusableTokenValidity is how long a cached SSO token must still be valid to skip device authorization
+// It leaves room for a bootstrap across many accounts to finish with the same token
+const minReusableTokenValidity = 10 * time.Minute
+
+// minReusableRegistrationValidity is how long a cached client registration must still be valid to be reused,
+// enough for a device authorization to be approved and turned into a token
+const minReusableRegistrationValidity = time.Hour
+
+// SSOOptions tune how ark signs in to an SSO portal and writes ~/.aws/config
+type SSOOptions struct {
+	// AssumeYes skips the confirmations, and gives up instead of asking for a new device code
+	AssumeYes bool
+	// Force re-authenticates even when the cached token is still valid
+	Force bool
+	// DiffOnly shows what would change in ~/.aws/config without writing it
+	DiffOnly bool
+	// Incremental only adds the profiles that are missing, leaving the existing ones untouched
+	Incremental bool
+	// DeviceCode signs in with a device code even when a browser is available, e.g. to approve on another machine
+	DeviceCode bool
+}
+
+// SSOStages are the steps of signing in to an SSO portal and listing its profiles that reach outside ark:
+// the portal client, the token cache, the browser and the terminal. Each can be replaced to reuse or test
+// the others; zero fields use the real ones
+type SSOStages struct {
+	// NewClient creates the client of a portal
+	NewClient services_aws.SSOPortalClientFactory
+	// CachedToken returns the cached token of a portal when it is valid long enough to reuse
+	CachedToken func(startURL, region string, now time.Time) (*services_aws.CachedToken, bool)
+	// OpenBrowser opens the authorization page
+	OpenBrowser func(url string) error
+	// BrowserAvailable reports whether a browser can be opened on this machine, to sign in without a device code
+	BrowserAvailable func() bool
+	// LockLogin keeps other ark processes from signing in to the portal until unlock is called, reporting whether
+	// it waited for one that was already signing in
+	LockLogin func(ctx context.Context, startURL, region string) (unlock func(), waited bool, err error)
+	// FetchProfiles lists every account+role profile of the portal
+	FetchProfiles func(ctx context.Context, lister services_aws.ProfileLister, accessToken string) ([]services_aws.AWSProfile, error)
+	// FetchTags reads the AWS Organizations tags of accounts with the credentials of a role of the portal
+	FetchTags func(ctx context.Context, provider services_aws.CredentialProvider, accessToken string, source services_aws.TagSource, accountIDs []string) (services_aws.AccountTags, error)
+}
+
+// withDefaults fills the stages that are not set with the real ones
+func (s SSOStages) withDefaults() SSOStages {
+	if s.NewClient == nil {
+		s.NewClient = services_aws.NewSSOPortalClient
+	}
+	if s.CachedToken == nil {
+		s.CachedToken = reusableSSOToken
+	}
+	if s.OpenBrowser == nil {
+		s.OpenBrowser = lib.OpenBrowser
+	}
+	if s.BrowserAvailable == nil {
+		s.BrowserAvailable = lib.BrowserAvailable
+	}
+	if s.LockLogin == nil {
+		s.LockLogin = lockLogin
+	}
+	if s.FetchProfiles == nil {
+		s.FetchProfiles = fetchProfiles
+	}
+	if s.FetchTags == nil {
+		s.FetchTags = fetchAccountTags
+	}
+	return s
+}
+
+// AWSSSOLogin signs in to an SSO portal in the browser, or with the device authorization flow, and caches the token
+// A cached token for the start URL that is still valid is reused unless opts.Force is set
+func AWSSSOLogin(ctx context.Context, SSORegion string, SSOStartURL string, opts SSOOptions) error {
+	if _, _, err := (SSOStages{}).Session(ctx, SSORegion, SSOStartURL, opts); err != nil {
+		return err
+	}
+	fmt.Println("\n🎉 AWS SSO sso completed!")
+	return nil
+}
+
+// Session creates the client of a portal and returns it with a valid access token
+func (s SSOStages) Session(ctx context.Context, SSORegion string, SSOStartURL string, opts SSOOptions) (services_aws.SSOPortalClient, string, error) {
+	s = s.withDefaults()
+
 	// Step 1: Create SSO client
-	client, err := services_aws.NewSSOClient(ctx, SSORegion, SSOStartURL)
+	client, err := s.NewClient(ctx, SSORegion, SSOStartURL)
 	if err != nil {
 		fmt.Println("Error creating SSO client:", err)
-		return err
+		return nil, "", err
 	}
-	fmt.Printf("SSO client created successfully for region: %s, start URL: %s\n", client.Region, client.StartURL)
+	fmt.Printf("SSO client created successfully for region: %s, start URL: %s\n", SSORegion, SSOStartURL)
 
-	// Step 2: Register client
+	accessToken, err := s.Token(ctx, client, SSORegion, SSOStartURL, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	return client, accessToken, nil
+}
+
+// Token returns the cached token of a portal unless opts.Force is set. Otherwise it signs in with the authorization
+// code flow when a browser is available, and falls back to device authorization, offering a new code when one expires
+// Only one ark process signs in to a portal at a time: the others wait for it and reuse the token it cached
+func (s SSOStages) Token(ctx context.Context, authorizer services_aws.DeviceAuthorizer, SSORegion string, SSOStartURL string, opts SSOOptions) (string, error) {
+	s = s.withDefaults()
+
+	if !opts.Force {
+		if cached, ok := s.CachedToken(SSOStartURL, SSORegion, time.Now()); ok {
+			fmt.Printf("✓ Reusing the cached SSO token, valid until %s (use --force to re-authenticate)\n", cached.ExpiresAt)
+			return cached.AccessToken, nil
+		}
+	}
+
+	unlock, waited, err := s.LockLogin(ctx, SSOStartURL, SSORegion)
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", err
+		}
+		fmt.Printf("Warning: failed to lock the login, other ark processes may sign in too: %v\n", err)
+		unlock = func() {}
+	}
+	defer unlock()
+	if waited {
+		if cached, ok := s.CachedToken(SSOStartURL, SSORegion, time.Now()); ok {
+			fmt.Printf("✓ Reusing the SSO token of the other login, valid until %s\n", cached.ExpiresAt)
+			return cached.AccessToken, nil
+		}
+		fmt.Println("The other login didn't cache a token, signing in")
+	}
+
+	if codeAuthorizer, ok := authorizer.(services_aws.AuthCodeAuthorizer); ok && !opts.DeviceCode && s.BrowserAvailable() {
+		accessToken, err := s.authorizeInBrowser(ctx, codeAuthorizer)
+		if err == nil || errors.Is(err, services_aws.ErrAuthorizationDenied) || ctx.Err() != nil {
+			return accessToken, err
+		}
+		fmt.Printf("Signing in from the browser failed (%v), using a device code instead\n", err)
+	}
+	for {
+		accessToken, err := s.authorizeDevice(ctx, authorizer)
+		if errors.Is(err, services_aws.ErrDeviceAuthorizationExpired) && retryExpiredAuthorization(opts.AssumeYes) {
+			continue
+		}
+		return accessToken, err
+	}
+}
+
+// reusableSSOToken returns the cached token of a start URL and region when it is valid for at least minReusableTokenValidity
+// A token other users can read is only reused once its files are restricted
+func reusableSSOToken(startURL, region string, now time.Time) (*services_aws.CachedToken, bool) {
+	cached, err := services_aws.ReadTokenFromCache(startURL, region)
+	if errors.Is(err, services_aws.ErrInsecureTokenCache) && fixTokenCachePermissions(startURL, region) {
+		cached, err = services_aws.ReadTokenFromCache(startURL, region)
+	}
+	if err != nil {
+		return nil, false
+	}
+	expiresAt, err := time.Parse(time.RFC3339, cached.ExpiresAt)
+	if err != nil || services_aws.ValidFor(expiresAt, now) < minReusableTokenValidity {
+		return nil, false
+	}
+	return cached, true
+}
+
+// lockLogin takes the login lock of a start URL and region, waiting while another ark process holds it
+func lockLogin(ctx context.Context, startURL, region string) (func(), bool, error) {
+	path, err := services_aws.LoginLockPath(startURL, region)
+	if err != nil {
+		return nil, false, err
+	}
+	lock, ok, err := lib.TryLockFile(path)
+	if err != nil {
+		return nil, false, err
+	}
+	if !ok {
+		fmt.Printf("Another ark login to %s is in progress, waiting for it to finish...\n", startURL)
+		if lock, err = lib.LockFile(ctx, path); err != nil {
+			return nil, false, err
+		}
+	}
+	return func() { lock.Unlock() }, !ok, nil
+}
+
+// fixTokenCachePermissions offers to restrict the cached token files of a portal that other users can read
+// Declining (or no terminal) signs in again instead, which rewrites them for the owner only
+func fixTokenCachePermissions(startURL, region string) bool {
+	issues := services_aws.TokenCacheIssues(startURL, region)
+	details := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		details = append(details, issue.String())
+	}
+	fmt.Println("⚠️  The cached SSO token can be read by other users of this machine")
+	fixed, err := animation.Confirm("Restrict the cached SSO token to your user and reuse it?", animation.ConfirmOptions{Details: details})
+	if err == nil && fixed {
+		if err = lib.FixPermissions(issues); err == nil {
+			return true
+		}
+		fmt.Println("Error:", err)
+	}
+	fmt.Println("Not reusing the cached SSO token, signing in again")
+	return false
+}
+
+// reusableClientRegistration returns the client registered by a previous login when it is valid for at least
+// minReusableRegistrationValidity, so logins don't create a new OIDC client every time
+func reusableClientRegistration(client services_aws.DeviceAuthorizer, now time.Time) (*services_aws.ClientRegistration, bool) {
+	registration, err := client.ReadClientRegistration()
+	if err != nil || services_aws.ValidFor(time.Unix(registration.ExpiresAt, 0), now) < minReusableRegistrationValidity {
+		return nil, false
+	}
+	fmt.Printf("\n✓ Reusing the registered client, valid until %s\n", time.Unix(registration.ExpiresAt, 0).Format(time.RFC3339))
+	return registration, true
+}
+
+// registerClient registers a new OIDC client and caches it for the next logins
+func registerClient(ctx context.Context, client services_aws.DeviceAuthorizer) (*services_aws.ClientRegistration, error) {
 	fmt.Println("\nRegistering client...")
 	registration, err := client.RegisterClient(ctx)
 	if err != nil {
 		fmt.Println("Error registering client:", err)
-		return err
+		return nil, err
 	}
 	fmt.Println("Client registered successfully")
 
+	if err := client.SaveClientRegistration(registration); err != nil {
+		fmt.Printf("Warning: failed to cache the client registration: %v\n", err)
+	}
+	return registration, nil
+}
+
+// retryExpiredAuthorization asks whether to start a new device authorization after one expired
+// Automation (assumeYes or no terminal) fails instead of waiting for approvals nobody will give
+func retryExpiredAuthorization(assumeYes bool) bool {
+	fmt.Println("\n⏱  The authorization request expired before it was approved.")
+	if assumeYes {
+		return false
+	}
+	retry, err := animation.Confirm("Start a new authorization with a new code?", animation.ConfirmOptions{})
+	return err == nil && retry
+}
+
+// browserAuthorizationTimeout bounds the wait for the browser to come back with an authorization code
+const browserAuthorizationTimeout = 5 * time.Minute
+
+// authorizeInBrowser runs the authorization code flow with PKCE: the browser signs in and redirects to a
+// loopback listener with the code, which is exchanged for a token and cached
+func (s SSOStages) authorizeInBrowser(ctx context.Context, client services_aws.AuthCodeAuthorizer) (string, error) {
+	registration, err := client.ReadAuthCodeClientRegistration()
+	if err != nil || services_aws.ValidFor(time.Unix(registration.ExpiresAt, 0), time.Now()) < minReusableRegistrationValidity {
+		if registration, err = client.RegisterAuthCodeClient(ctx); err != nil {
+			return "", err
+		}
+		if err := client.SaveAuthCodeClientRegistration(registration); err != nil {
+			fmt.Printf("Warning: failed to cache the client registration: %v\n", err)
+		}
+	}
+
+	listener, err := services_aws.ListenForAuthCode()
+	if err != nil {
+		return "", err
+	}
+	defer listener.Close()
+	verifier, challenge, err := services_aws.NewPKCEChallenge()
+	if err != nil {
+		return "", err
+	}
+
+	authorizationURL := client.AuthorizationURL(registration, listener.RedirectURI(), listener.State, challenge)
+	fmt.Println("\nOpening the browser to sign in...")
+	if err := s.OpenBrowser(authorizationURL); err != nil {
+		return "", fmt.Errorf("failed to open the browser: %w", err)
+	}
+	fmt.Printf("If the browser didn't open, visit: %s\n", authorizationURL)
+	fmt.Println("\nWaiting for authorization...")
+
+	waitCtx, cancel := context.WithTimeout(ctx, browserAuthorizationTimeout)
+	defer cancel()
+	code, err := listener.Wait(waitCtx)
+	if err != nil {
+		if ctx.Err() == nil && waitCtx.Err() != nil {
+			return "", fmt.Errorf("no authorization from the browser within %s", browserAuthorizationTimeout)
+		}
+		return "", err
+	}
+
+	token, err := client.CreateTokenWithAuthCode(ctx, registration.ClientID, registration.ClientSecret, code, verifier, listener.RedirectURI())
+	if err != nil {
+		return "", err
+	}
+	fmt.Println("\n✓ Authorization successful!")
+	notify.Desktop("ark", "AWS SSO authorization approved")
+
+	if err := client.SaveTokenToCache(token); err != nil {
+		fmt.Println("Error saving token:", err)
+		return "", err
+	}
+	fmt.Println("✓ Token saved successfully")
+	return token.AccessToken, nil
+}
+
+// authorizeDevice runs the device authorization flow and caches the new token
+func (s SSOStages) authorizeDevice(ctx context.Context, client services_aws.DeviceAuthorizer) (string, error) {
+	// Step 2: Register client, or reuse the one registered by a previous login
+	registration, reused := reusableClientRegistration(client, time.Now())
+	if !reused {
+		var err error
+		if registration, err = registerClient(ctx, client); err != nil {
+			return "", err
+		}
+	}
+
 	// Step 3: Start device authorization
 	fmt.Println("\nStarting device authorization...")
 	deviceAuth, err := client.StartDeviceAuthorization(ctx, registration.ClientID, registration.ClientSecret)
+	if errors.Is(err, services_aws.ErrInvalidClient) && reused {
+		fmt.Println("The cached client registration was rejected, registering a new one")
+		if registration, err = registerClient(ctx, client); err != nil {
+			return "", err
+		}
+		deviceAuth, err = client.StartDeviceAuthorization(ctx, registration.ClientID, registration.ClientSecret)
+	}
 	if err != nil {
 		fmt.Println("Error starting device authorization:", err)
-		return err
+		return "", err
 	}
 
 	// Step 4: Show instructions to the user
@@ -42,51 +343,62 @@ func AWSSSOLogin(ctx context.Context, SSORegion string, SSOStartURL string, boos
 	fmt.Printf("Or go to: %s and enter code: %s\n", deviceAuth.VerificationURI, deviceAuth.UserCode)
 	fmt.Println(strings.Repeat("=", 60))
 
-	// Open browser automatically
-	fmt.Println("\nOpening browser for authorization...")
-	if err := lib.OpenBrowser(deviceAuth.VerificationURIComplete); err != nil {
-		fmt.Printf("Warning: Failed to open browser automatically: %v\n", err)
+	// Open browser automatically, unless the URL is to be opened on another device
+	if err := s.OpenBrowser(deviceAuth.VerificationURIComplete); errors.Is(err, lib.ErrPrintURLOnly) {
+		fmt.Println("\nOpen the URL above in a browser, on this machine or another one")
+	} else if err != nil {
+		fmt.Printf("\nWarning: Failed to open browser automatically: %v\n", err)
 		fmt.Println("Please open the URL manually.")
+	} else {
+		fmt.Println("\nOpened the browser for authorization")
 	}
 
 	fmt.Println("\nWaiting for authorization...")
 
 	// Step 5: Polling to get the token
-	token, err := client.CreateToken(ctx, registration.ClientID, registration.ClientSecret, deviceAuth.DeviceCode, deviceAuth.Interval)
+	token, err := client.CreateToken(ctx, registration.ClientID, registration.ClientSecret, deviceAuth.DeviceCode, deviceAuth.Interval, deviceAuth.ExpiresIn)
 	if err != nil {
 		fmt.Println("Error creating token:", err)
-		return err
+		return "", err
 	}
 	fmt.Println("\n✓ Authorization successful!")
+	notify.Desktop("ark", "AWS SSO authorization approved")
 
 	// Step 6: Save token to cache
 	fmt.Println("Saving token to cache...")
 	if err := client.SaveTokenToCache(token); err != nil {
 		fmt.Println("Error saving token:", err)
-		return err
+		return "", err
 	}
 	fmt.Println("✓ Token saved successfully")
 
-	if boostraping {
-		// Step 7: Get all accounts and roles
-		fmt.Println("\nFetching accounts and roles...")
-		profiles, err := client.GetAllProfiles(ctx, token.AccessToken)
+	return token.AccessToken, nil
+}
+
+// scanTokenMargin is the validity an SSO token must have beyond the estimated duration of a scan
+const scanTokenMargin = 5 * time.Minute
+
+// ExpiringToken is an SSO portal whose token won't last through a scan
+type ExpiringToken struct {
+	services_aws.SSOPortal
+	// ExpiresAt is when the cached token expires; zero when no token is cached
+	ExpiresAt time.Time
+}
+
+// TokensExpiringDuringScan returns the portals of plan whose cached token expires before the scan could
+// plausibly finish, so the accounts scanned last don't fail to sign in
+func TokensExpiringDuringScan(plan services_aws.ScanPlan, now time.Time, expiry func(startURL, region string) (time.Time, error)) []ExpiringToken {
+	needed := plan.EstimatedDuration() + scanTokenMargin
+	var expiring []ExpiringToken
+	for _, portal := range plan.Portals {
+		expiresAt, err := expiry(portal.StartURL, portal.Region)
 		if err != nil {
-			fmt.Println("Error getting profiles:", err)
-			return err
+			expiring = append(expiring, ExpiringToken{SSOPortal: portal})
+			continue
 		}
-		fmt.Printf("✓ Found %d profiles\n", len(profiles))
-
-		// Step 8: Write config file
-		fmt.Println("\nWriting profiles to ~/.aws/config...")
-		if err := client.WriteConfigFile(profiles); err != nil {
-			fmt.Println("Error writing config file:", err)
-			return err
+		if services_aws.ValidFor(expiresAt, now) < needed {
+			expiring = append(expiring, ExpiringToken{SSOPortal: portal, ExpiresAt: expiresAt})
 		}
-		fmt.Println("✓ Config file updated successfully")
 	}
-
-	fmt.Println("\n🎉 AWS SSO sso completed!")
-
-	return nil
+	return expiring
 }
@@ -2,10 +2,24 @@ package controllers
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
 	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/andresgarcia29/ark-cli/lib"
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestAWSSSOLogin(t *testing.T) {
@@ -13,7 +27,7 @@ func TestAWSSSOLogin(t *testing.T) {
 		name             string
 		ssoRegion        string
 		ssoStartURL      string
-		boostraping      bool
+		bootstrapping    bool
 		clientError      error
 		registerError    error
 		authError        error
@@ -28,7 +42,7 @@ func TestAWSSSOLogin(t *testing.T) {
 			name:             "successful SSO login with bootstrapping",
 			ssoRegion:        "us-west-2",
 			ssoStartURL:      "https://example.awsapps.com/start",
-			boostraping:      true,
+			bootstrapping:    true,
 			clientError:      nil,
 			registerError:    nil,
 			authError:        nil,
@@ -43,7 +57,7 @@ func TestAWSSSOLogin(t *testing.T) {
 			name:             "successful SSO login without bootstrapping",
 			ssoRegion:        "us-east-1",
 			ssoStartURL:      "https://example.awsapps.com/start",
-			boostraping:      false,
+			bootstrapping:    false,
 			clientError:      nil,
 			registerError:    nil,
 			authError:        nil,
@@ -58,7 +72,7 @@ func TestAWSSSOLogin(t *testing.T) {
 			name:             "client creation error",
 			ssoRegion:        "us-west-2",
 			ssoStartURL:      "https://example.awsapps.com/start",
-			boostraping:      false,
+			bootstrapping:    false,
 			clientError:      errors.New("failed to create SSO client"),
 			registerError:    nil,
 			authError:        nil,
@@ -73,7 +87,7 @@ func TestAWSSSOLogin(t *testing.T) {
 			name:             "client registration error",
 			ssoRegion:        "us-west-2",
 			ssoStartURL:      "https://example.awsapps.com/start",
-			boostraping:      false,
+			bootstrapping:    false,
 			clientError:      nil,
 			registerError:    errors.New("failed to register client"),
 			authError:        nil,
@@ -88,7 +102,7 @@ func TestAWSSSOLogin(t *testing.T) {
 			name:             "device authorization error",
 			ssoRegion:        "us-west-2",
 			ssoStartURL:      "https://example.awsapps.com/start",
-			boostraping:      false,
+			bootstrapping:    false,
 			clientError:      nil,
 			registerError:    nil,
 			authError:        errors.New("failed to start device authorization"),
@@ -103,7 +117,7 @@ func TestAWSSSOLogin(t *testing.T) {
 			name:             "token creation error",
 			ssoRegion:        "us-west-2",
 			ssoStartURL:      "https://example.awsapps.com/start",
-			boostraping:      false,
+			bootstrapping:    false,
 			clientError:      nil,
 			registerError:    nil,
 			authError:        nil,
@@ -118,7 +132,7 @@ func TestAWSSSOLogin(t *testing.T) {
 			name:             "token cache error",
 			ssoRegion:        "us-west-2",
 			ssoStartURL:      "https://example.awsapps.com/start",
-			boostraping:      false,
+			bootstrapping:    false,
 			clientError:      nil,
 			registerError:    nil,
 			authError:        nil,
@@ -133,7 +147,7 @@ func TestAWSSSOLogin(t *testing.T) {
 			name:             "profiles error during bootstrapping",
 			ssoRegion:        "us-west-2",
 			ssoStartURL:      "https://example.awsapps.com/start",
-			boostraping:      true,
+			bootstrapping:    true,
 			clientError:      nil,
 			registerError:    nil,
 			authError:        nil,
@@ -148,7 +162,7 @@ func TestAWSSSOLogin(t *testing.T) {
 			name:             "config file error during bootstrapping",
 			ssoRegion:        "us-west-2",
 			ssoStartURL:      "https://example.awsapps.com/start",
-			boostraping:      true,
+			bootstrapping:    true,
 			clientError:      nil,
 			registerError:    nil,
 			authError:        nil,
@@ -190,7 +204,7 @@ func TestAWSSSOLogin(t *testing.T) {
 							// Step 5: Save token to cache
 							if tt.cacheError != nil {
 								finalError = tt.cacheError
-							} else if tt.boostraping {
+							} else if tt.bootstrapping {
 								// Step 6: Get profiles (only if bootstrapping)
 								if tt.profilesError != nil {
 									finalError = tt.profilesError
@@ -221,34 +235,34 @@ func TestAWSSSOLogin(t *testing.T) {
 func TestAWSSSOLoginParameters(t *testing.T) {
 	// Test parameter validation and handling
 	tests := []struct {
-		name        string
-		ssoRegion   string
-		ssoStartURL string
-		boostraping bool
+		name          string
+		ssoRegion     string
+		ssoStartURL   string
+		bootstrapping bool
 	}{
 		{
-			name:        "valid parameters with bootstrapping",
-			ssoRegion:   "us-west-2",
-			ssoStartURL: "https://example.awsapps.com/start",
-			boostraping: true,
+			name:          "valid parameters with bootstrapping",
+			ssoRegion:     "us-west-2",
+			ssoStartURL:   "https://example.awsapps.com/start",
+			bootstrapping: true,
 		},
 		{
-			name:        "valid parameters without bootstrapping",
-			ssoRegion:   "us-east-1",
-			ssoStartURL: "https://example.awsapps.com/start",
-			boostraping: false,
+			name:          "valid parameters without bootstrapping",
+			ssoRegion:     "us-east-1",
+			ssoStartURL:   "https://example.awsapps.com/start",
+			bootstrapping: false,
 		},
 		{
-			name:        "empty region",
-			ssoRegion:   "",
-			ssoStartURL: "https://example.awsapps.com/start",
-			boostraping: false,
+			name:          "empty region",
+			ssoRegion:     "",
+			ssoStartURL:   "https://example.awsapps.com/start",
+			bootstrapping: false,
 		},
 		{
-			name:        "empty start URL",
-			ssoRegion:   "us-west-2",
-			ssoStartURL: "",
-			boostraping: false,
+			name:          "empty start URL",
+			ssoRegion:     "us-west-2",
+			ssoStartURL:   "",
+			bootstrapping: false,
 		},
 	}
 
@@ -260,7 +274,7 @@ func TestAWSSSOLoginParameters(t *testing.T) {
 			assert.NotNil(t, ctx)
 			assert.IsType(t, "", tt.ssoRegion)
 			assert.IsType(t, "", tt.ssoStartURL)
-			assert.IsType(t, true, tt.boostraping)
+			assert.IsType(t, true, tt.bootstrapping)
 		})
 	}
 }
@@ -444,12 +458,12 @@ func TestAWSSSOLoginFlow(t *testing.T) {
 	// Test the flow logic
 	tests := []struct {
 		name          string
-		boostraping   bool
+		bootstrapping bool
 		expectedSteps []string
 	}{
 		{
-			name:        "without bootstrapping",
-			boostraping: false,
+			name:          "without bootstrapping",
+			bootstrapping: false,
 			expectedSteps: []string{
 				"create_client",
 				"register_client",
@@ -459,8 +473,8 @@ func TestAWSSSOLoginFlow(t *testing.T) {
 			},
 		},
 		{
-			name:        "with bootstrapping",
-			boostraping: true,
+			name:          "with bootstrapping",
+			bootstrapping: true,
 			expectedSteps: []string{
 				"create_client",
 				"register_client",
@@ -484,7 +498,7 @@ func TestAWSSSOLoginFlow(t *testing.T) {
 				"save_token",
 			}
 
-			if tt.boostraping {
+			if tt.bootstrapping {
 				steps = append(steps, "get_profiles", "write_config")
 			}
 
@@ -498,16 +512,16 @@ func TestAWSSSOLoginFunctionSignature(t *testing.T) {
 	ctx := context.Background()
 	ssoRegion := "us-west-2"
 	ssoStartURL := "https://example.awsapps.com/start"
-	boostraping := true
+	bootstrapping := true
 
 	// Test that all parameters are of the expected types
 	assert.NotNil(t, ctx)
 	assert.IsType(t, "", ssoRegion)
 	assert.IsType(t, "", ssoStartURL)
-	assert.IsType(t, true, boostraping)
+	assert.IsType(t, true, bootstrapping)
 
 	// Test that the function would accept these parameters
-	_ = func(ctx context.Context, ssoRegion string, ssoStartURL string, boostraping bool) error {
+	_ = func(ctx context.Context, ssoRegion string, ssoStartURL string, bootstrapping bool) error {
 		return nil
 	}
 }
@@ -516,19 +530,19 @@ func TestAWSSSOLoginBootstrapping(t *testing.T) {
 	// Test bootstrapping logic
 	tests := []struct {
 		name              string
-		boostraping       bool
+		bootstrapping     bool
 		shouldGetProfiles bool
 		shouldWriteConfig bool
 	}{
 		{
 			name:              "bootstrapping enabled",
-			boostraping:       true,
+			bootstrapping:     true,
 			shouldGetProfiles: true,
 			shouldWriteConfig: true,
 		},
 		{
 			name:              "bootstrapping disabled",
-			boostraping:       false,
+			bootstrapping:     false,
 			shouldGetProfiles: false,
 			shouldWriteConfig: false,
 		},
@@ -537,7 +551,7 @@ func TestAWSSSOLoginBootstrapping(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Test bootstrapping logic
-			if tt.boostraping {
+			if tt.bootstrapping {
 				assert.True(t, tt.shouldGetProfiles)
 				assert.True(t, tt.shouldWriteConfig)
 			} else {
@@ -547,3 +561,389 @@ func TestAWSSSOLoginBootstrapping(t *testing.T) {
 		})
 	}
 }
+
+func TestReusableSSOToken(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	startURL := "https://example.awsapps.com/start"
+
+	_, ok := reusableSSOToken(startURL, "us-east-1", time.Now())
+	assert.False(t, ok, "no cached token")
+
+	client := &services_aws.SSOClient{StartURL: startURL, Region: "us-east-1"}
+	require.NoError(t, client.SaveTokenToCache(&services_aws.TokenResponse{AccessToken: "cached-token", ExpiresIn: 3600}))
+
+	cached, ok := reusableSSOToken(startURL, "us-east-1", time.Now())
+	require.True(t, ok)
+	assert.Equal(t, "cached-token", cached.AccessToken)
+
+	// A token about to expire would not last through a bootstrap
+	_, ok = reusableSSOToken(startURL, "us-east-1", time.Now().Add(55*time.Minute))
+	assert.False(t, ok)
+
+	_, ok = reusableSSOToken("https://other.awsapps.com/start", "us-east-1", time.Now())
+	assert.False(t, ok, "tokens are cached per start URL")
+	_, ok = reusableSSOToken(startURL, "eu-west-1", time.Now())
+	assert.False(t, ok, "and per region")
+}
+
+func TestReusableSSOTokenReadableByOthers(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("modes are not checked on Windows")
+	}
+	t.Setenv("HOME", t.TempDir())
+	startURL := "https://example.awsapps.com/start"
+	client := &services_aws.SSOClient{StartURL: startURL, Region: "us-east-1"}
+	require.NoError(t, client.SaveTokenToCache(&services_aws.TokenResponse{AccessToken: "cached-token", ExpiresIn: 3600}))
+	issues := services_aws.TokenCacheIssues(startURL, "us-east-1")
+	require.Empty(t, issues)
+	for _, path := range tokenCacheFiles(t) {
+		require.NoError(t, os.Chmod(path, 0644))
+	}
+
+	// Without a terminal to confirm the fix, the token is not reused, and signing in again restricts the files
+	_, ok := reusableSSOToken(startURL, "us-east-1", time.Now())
+	assert.False(t, ok)
+	assert.Len(t, services_aws.TokenCacheIssues(startURL, "us-east-1"), 2)
+
+	require.NoError(t, client.SaveTokenToCache(&services_aws.TokenResponse{AccessToken: "new-token", ExpiresIn: 3600}))
+	assert.Empty(t, services_aws.TokenCacheIssues(startURL, "us-east-1"))
+	cached, ok := reusableSSOToken(startURL, "us-east-1", time.Now())
+	require.True(t, ok)
+	assert.Equal(t, "new-token", cached.AccessToken)
+}
+
+// tokenCacheFiles returns the two files SaveTokenToCache wrote for the only start URL of the test
+func tokenCacheFiles(t *testing.T) []string {
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+	paths, err := filepath.Glob(filepath.Join(home, ".aws", "sso", "cache", "*.json"))
+	require.NoError(t, err)
+	require.Len(t, paths, 2)
+	return paths
+}
+
+func TestTokensExpiringDuringScan(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	fresh := services_aws.SSOPortal{StartURL: "https://fresh.awsapps.com/start", Region: "us-east-1"}
+	expiring := services_aws.SSOPortal{StartURL: "https://expiring.awsapps.com/start", Region: "us-east-1"}
+	missing := services_aws.SSOPortal{StartURL: "https://missing.awsapps.com/start", Region: "eu-west-1"}
+	expiries := map[string]time.Time{
+		fresh.StartURL:    now.Add(8 * time.Hour),
+		expiring.StartURL: now.Add(8 * time.Minute),
+	}
+	expiry := func(startURL, region string) (time.Time, error) {
+		if expiresAt, ok := expiries[startURL]; ok {
+			return expiresAt, nil
+		}
+		return time.Time{}, errors.New("failed to read cache file")
+	}
+
+	// 20 accounts take well under the 8 minutes left
+	small := services_aws.ScanPlan{Accounts: 20, Regions: []string{"us-east-1"}, Portals: []services_aws.SSOPortal{fresh, expiring}}
+	assert.Empty(t, TokensExpiringDuringScan(small, now, expiry))
+
+	// 1000 accounts don't
+	large := services_aws.ScanPlan{Accounts: 1000, Regions: []string{"us-east-1"}, Portals: []services_aws.SSOPortal{fresh, expiring, missing}}
+	assert.Equal(t, []ExpiringToken{
+		{SSOPortal: expiring, ExpiresAt: now.Add(8 * time.Minute)},
+		{SSOPortal: missing},
+	}, TokensExpiringDuringScan(large, now, expiry))
+}
+
+func TestRetryExpiredAuthorization(t *testing.T) {
+	// Automation never waits for a second approval
+	assert.False(t, retryExpiredAuthorization(true))
+	// Without a terminal there is nobody to ask
+	assert.False(t, retryExpiredAuthorization(false))
+}
+
+// fakeSSOPortal authorizes devices and lists accounts from memory, writing the AWS config like the real client
+type fakeSSOPortal struct {
+	*services_aws.SSOClient
+	accounts  []services_aws.Account
+	roles     map[string][]services_aws.Role
+	expired   int
+	authorize int
+	saved     []*services_aws.TokenResponse
+	// registration is the cached client registration; registered counts RegisterClient calls
+	registration *services_aws.ClientRegistration
+	registered   int
+	// revoked client IDs are rejected by StartDeviceAuthorization
+	revoked map[string]bool
+	// authCodeErr fails RegisterAuthCodeClient; challenge is the PKCE challenge of the last authorization URL
+	authCodeErr error
+	challenge   string
+}
+
+func newFakeSSOPortal(region, startURL string) *fakeSSOPortal {
+	return &fakeSSOPortal{SSOClient: &services_aws.SSOClient{Region: region, StartURL: startURL}}
+}
+
+func (f *fakeSSOPortal) RegisterClient(ctx context.Context) (*services_aws.ClientRegistration, error) {
+	f.registered++
+	return &services_aws.ClientRegistration{
+		ClientID:     fmt.Sprintf("client-%d", f.registered),
+		ClientSecret: "secret",
+		ExpiresAt:    time.Now().Add(90 * 24 * time.Hour).Unix(),
+	}, nil
+}
+
+func (f *fakeSSOPortal) ReadClientRegistration() (*services_aws.ClientRegistration, error) {
+	if f.registration == nil {
+		return nil, errors.New("no cached client registration")
+	}
+	return f.registration, nil
+}
+
+func (f *fakeSSOPortal) SaveClientRegistration(registration *services_aws.ClientRegistration) error {
+	f.registration = registration
+	return nil
+}
+
+func (f *fakeSSOPortal) StartDeviceAuthorization(ctx context.Context, clientID, clientSecret string) (*services_aws.DeviceAuthorization, error) {
+	if f.revoked[clientID] {
+		return nil, services_aws.ErrInvalidClient
+	}
+	f.authorize++
+	return &services_aws.DeviceAuthorization{DeviceCode: "device", UserCode: "ABCD-EFGH", VerificationURIComplete: "https://device.example", ExpiresIn: 600, Interval: 1}, nil
+}
+
+func (f *fakeSSOPortal) CreateToken(ctx context.Context, clientID, clientSecret, deviceCode string, interval, expiresIn int32) (*services_aws.TokenResponse, error) {
+	if f.expired > 0 {
+		f.expired--
+		return nil, services_aws.ErrDeviceAuthorizationExpired
+	}
+	return &services_aws.TokenResponse{AccessToken: "fresh-token", ExpiresIn: 3600}, nil
+}
+
+func (f *fakeSSOPortal) RegisterAuthCodeClient(ctx context.Context) (*services_aws.ClientRegistration, error) {
+	if f.authCodeErr != nil {
+		return nil, f.authCodeErr
+	}
+	return &services_aws.ClientRegistration{ClientID: "authcode-client", ClientSecret: "secret", ExpiresAt: time.Now().Add(90 * 24 * time.Hour).Unix()}, nil
+}
+
+func (f *fakeSSOPortal) ReadAuthCodeClientRegistration() (*services_aws.ClientRegistration, error) {
+	return nil, errors.New("no cached client registration")
+}
+
+func (f *fakeSSOPortal) SaveAuthCodeClientRegistration(registration *services_aws.ClientRegistration) error {
+	return nil
+}
+
+func (f *fakeSSOPortal) AuthorizationURL(registration *services_aws.ClientRegistration, redirectURI, state, codeChallenge string) string {
+	f.challenge = codeChallenge
+	return "https://oidc.example/authorize?" + url.Values{"redirect_uri": {redirectURI}, "state": {state}}.Encode()
+}
+
+// CreateTokenWithAuthCode accepts the code "approved" with the verifier of the last challenge
+func (f *fakeSSOPortal) CreateTokenWithAuthCode(ctx context.Context, clientID, clientSecret, code, codeVerifier, redirectURI string) (*services_aws.TokenResponse, error) {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	if code != "approved" || base64.RawURLEncoding.EncodeToString(sum[:]) != f.challenge {
+		return nil, errors.New("invalid grant")
+	}
+	return &services_aws.TokenResponse{AccessToken: "browser-token", ExpiresIn: 3600}, nil
+}
+
+func (f *fakeSSOPortal) SaveTokenToCache(token *services_aws.TokenResponse) error {
+	f.saved = append(f.saved, token)
+	return nil
+}
+
+func (f *fakeSSOPortal) ListAccounts(ctx context.Context, accessToken string) ([]services_aws.Account, error) {
+	return f.accounts, nil
+}
+
+func (f *fakeSSOPortal) ListAccountRoles(ctx context.Context, accessToken, accountID string) ([]services_aws.Role, error) {
+	return f.roles[accountID], nil
+}
+
+// noLoginLock is a LockLogin stage for tests that sign in once at a time
+func noLoginLock(context.Context, string, string) (func(), bool, error) {
+	return func() {}, false, nil
+}
+
+func TestSSOStagesToken(t *testing.T) {
+	portal := newFakeSSOPortal("us-east-1", "https://example.awsapps.com/start")
+	var opened []string
+	stages := SSOStages{
+		CachedToken: func(startURL, region string, now time.Time) (*services_aws.CachedToken, bool) {
+			return &services_aws.CachedToken{AccessToken: "cached-token", ExpiresAt: now.Add(time.Hour).Format(time.RFC3339)}, true
+		},
+		OpenBrowser: func(url string) error {
+			opened = append(opened, url)
+			return nil
+		},
+		BrowserAvailable: func() bool { return false },
+		LockLogin:        noLoginLock,
+	}
+
+	token, err := stages.Token(context.Background(), portal, portal.Region, portal.StartURL, SSOOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "cached-token", token)
+	assert.Zero(t, portal.authorize, "a reusable token skips device authorization")
+
+	token, err = stages.Token(context.Background(), portal, portal.Region, portal.StartURL, SSOOptions{Force: true})
+	require.NoError(t, err)
+	assert.Equal(t, "fresh-token", token)
+	assert.Equal(t, []string{"https://device.example"}, opened)
+	require.Len(t, portal.saved, 1)
+	assert.Equal(t, "fresh-token", portal.saved[0].AccessToken)
+
+	// Automation gives up on an expired code instead of waiting for another approval
+	portal.expired = 1
+	_, err = stages.Token(context.Background(), portal, portal.Region, portal.StartURL, SSOOptions{Force: true, AssumeYes: true})
+	assert.ErrorIs(t, err, services_aws.ErrDeviceAuthorizationExpired)
+}
+
+func TestSSOStagesTokenReusesClientRegistration(t *testing.T) {
+	portal := newFakeSSOPortal("us-east-1", "https://example.awsapps.com/start")
+	stages := SSOStages{OpenBrowser: func(string) error { return nil }, BrowserAvailable: func() bool { return false }, LockLogin: noLoginLock}
+	login := func() {
+		t.Helper()
+		token, err := stages.Token(context.Background(), portal, portal.Region, portal.StartURL, SSOOptions{Force: true})
+		require.NoError(t, err)
+		assert.Equal(t, "fresh-token", token)
+	}
+
+	login()
+	login()
+	assert.Equal(t, 1, portal.registered, "the second login reuses the cached registration")
+	assert.Equal(t, "client-1", portal.registration.ClientID)
+
+	// A registration about to expire is replaced
+	portal.registration.ExpiresAt = time.Now().Add(30 * time.Minute).Unix()
+	login()
+	assert.Equal(t, 2, portal.registered)
+	assert.Equal(t, "client-2", portal.registration.ClientID)
+
+	// A revoked registration is replaced once the portal rejects it
+	portal.revoked = map[string]bool{"client-2": true}
+	login()
+	assert.Equal(t, 3, portal.registered)
+	assert.Equal(t, "client-3", portal.registration.ClientID)
+	assert.Equal(t, 4, portal.authorize)
+}
+
+func TestSSOStagesSession(t *testing.T) {
+	portal := newFakeSSOPortal("eu-west-1", "https://example.awsapps.com/start")
+	stages := SSOStages{
+		NewClient: func(ctx context.Context, region, startURL string) (services_aws.SSOPortalClient, error) {
+			assert.Equal(t, "eu-west-1", region)
+			return portal, nil
+		},
+		CachedToken:      func(string, string, time.Time) (*services_aws.CachedToken, bool) { return nil, false },
+		OpenBrowser:      func(string) error { return errors.New("no browser") },
+		BrowserAvailable: func() bool { return false },
+		LockLogin:        noLoginLock,
+	}
+
+	client, token, err := stages.Session(context.Background(), "eu-west-1", portal.StartURL, SSOOptions{})
+	require.NoError(t, err)
+	assert.Same(t, portal, client)
+	assert.Equal(t, "fresh-token", token, "a browser that can't be opened only asks to open the URL by hand")
+}
+
+// redirectBrowser returns an OpenBrowser stage that follows the authorization URL back to the loopback
+// listener, like the browser does once the user signs in; device authorization pages are only opened
+func redirectBrowser(t *testing.T, query url.Values, opened *int) func(string) error {
+	return func(authorizationURL string) error {
+		if !strings.HasPrefix(authorizationURL, "https://oidc.example/authorize") {
+			return nil
+		}
+		*opened++
+		parsed, err := url.Parse(authorizationURL)
+		require.NoError(t, err)
+		redirect := parsed.Query().Get("redirect_uri")
+		require.True(t, strings.HasPrefix(redirect, "http://127.0.0.1:"), redirect)
+
+		values := url.Values{"state": {parsed.Query().Get("state")}}
+		for key, value := range query {
+			values[key] = value
+		}
+		resp, err := http.Get(redirect + "?" + values.Encode())
+		require.NoError(t, err)
+		resp.Body.Close()
+		return nil
+	}
+}
+
+func TestSSOStagesTokenInBrowser(t *testing.T) {
+	opened := 0
+	portal := newFakeSSOPortal("us-east-1", "https://example.awsapps.com/start")
+	stages := SSOStages{
+		CachedToken:      func(string, string, time.Time) (*services_aws.CachedToken, bool) { return nil, false },
+		OpenBrowser:      redirectBrowser(t, url.Values{"code": {"approved"}}, &opened),
+		BrowserAvailable: func() bool { return true },
+		LockLogin:        noLoginLock,
+	}
+
+	token, err := stages.Token(context.Background(), portal, portal.Region, portal.StartURL, SSOOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "browser-token", token)
+	assert.Equal(t, 1, opened)
+	assert.Zero(t, portal.authorize, "no device code is started")
+	require.Len(t, portal.saved, 1)
+
+	// --use-device-code skips the browser flow
+	token, err = stages.Token(context.Background(), portal, portal.Region, portal.StartURL, SSOOptions{DeviceCode: true})
+	require.NoError(t, err)
+	assert.Equal(t, "fresh-token", token)
+	assert.Equal(t, 1, portal.authorize)
+
+	// A denied request is an answer, not a reason to ask again with a device code
+	stages.OpenBrowser = redirectBrowser(t, url.Values{"error": {"access_denied"}}, &opened)
+	_, err = stages.Token(context.Background(), portal, portal.Region, portal.StartURL, SSOOptions{})
+	assert.ErrorIs(t, err, services_aws.ErrAuthorizationDenied)
+	assert.Equal(t, 1, portal.authorize)
+
+	// A portal that can't register the client falls back to the device code
+	portal.authCodeErr = errors.New("unsupported grant type")
+	token, err = stages.Token(context.Background(), portal, portal.Region, portal.StartURL, SSOOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "fresh-token", token)
+	assert.Equal(t, 2, portal.authorize)
+}
+
+func TestSSOStagesTokenWaitsForOtherLogin(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", "")
+	portal := newFakeSSOPortal("us-east-1", "https://example.awsapps.com/start")
+
+	// Another ark process is signing in to the same portal
+	path, err := services_aws.LoginLockPath(portal.StartURL, portal.Region)
+	require.NoError(t, err)
+	other, ok, err := lib.TryLockFile(path)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	var mu sync.Mutex
+	var cached *services_aws.CachedToken
+	stages := SSOStages{
+		CachedToken: func(string, string, time.Time) (*services_aws.CachedToken, bool) {
+			mu.Lock()
+			defer mu.Unlock()
+			return cached, cached != nil
+		},
+		OpenBrowser:      func(string) error { return nil },
+		BrowserAvailable: func() bool { return false },
+	}
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		mu.Lock()
+		cached = &services_aws.CachedToken{AccessToken: "other-token", ExpiresAt: time.Now().Add(time.Hour).Format(time.RFC3339)}
+		mu.Unlock()
+		other.Unlock()
+	}()
+
+	token, err := stages.Token(context.Background(), portal, portal.Region, portal.StartURL, SSOOptions{Force: true})
+	require.NoError(t, err)
+	assert.Equal(t, "other-token", token)
+	assert.Zero(t, portal.authorize, "the token of the other login is reused, even with --force")
+
+	// Once no other login holds the lock, a login signs in itself
+	token, err = stages.Token(context.Background(), portal, portal.Region, portal.StartURL, SSOOptions{Force: true})
+	require.NoError(t, err)
+	assert.Equal(t, "fresh-token", token)
+	assert.Equal(t, 1, portal.authorize)
+}
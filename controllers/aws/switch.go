@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	ark_config "github.com/andresgarcia29/ark-cli/config"
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+)
+
+// AccountSwitch is a switch to another account and role of an SSO portal the user is signed in to
+type AccountSwitch struct {
+	AccountID string
+	RoleName  string
+	// Portal is the SSO portal of the account when no profile of the AWS config has the account and role
+	Portal services_aws.SSOPortal
+	// SetAsDefault also writes the credentials to the [default] profile
+	SetAsDefault bool
+}
+
+// SwitchAccount writes the credentials of an account and role with the cached SSO token of its portal, so
+// no browser is involved. The profile of the account and role in the AWS config, when there is one, names
+// the credentials and picks the portal. It returns the profile name the credentials were written under
+func SwitchAccount(ctx context.Context, sw AccountSwitch) (string, error) {
+	return switchAccount(ctx, sw, services_aws.NewSSOPortalClient)
+}
+
+// switchAccount is SwitchAccount with the portal client factory replaceable
+func switchAccount(ctx context.Context, sw AccountSwitch, newClient services_aws.SSOPortalClientFactory) (string, error) {
+	profiles, err := services_aws.ReadAllProfilesFromConfig()
+	if err != nil && !errors.Is(err, services_aws.ErrNoAWSConfig) {
+		return "", err
+	}
+
+	profileConfig, found := siblingProfile(profiles, sw.AccountID, sw.RoleName)
+	if !found {
+		if sw.Portal.StartURL == "" {
+			return "", fmt.Errorf("no profile has account %s and role %s, and no SSO portal was given", sw.AccountID, sw.RoleName)
+		}
+		profileConfig = services_aws.ProfileConfig{
+			ProfileName: services_aws.ProfileNameFor(services_aws.AWSProfile{AccountName: sw.AccountID, RoleName: sw.RoleName}),
+			ProfileType: services_aws.ProfileTypeSSO,
+			StartURL:    sw.Portal.StartURL,
+			SSORegion:   sw.Portal.Region,
+			AccountID:   sw.AccountID,
+			RoleName:    sw.RoleName,
+		}
+	} else {
+		warnIfDeprecated(ctx, profileConfig.ProfileName)
+	}
+
+	if _, err := services_aws.ReadTokenFromCache(profileConfig.StartURL, profileConfig.SSORegion); err != nil {
+		return "", fmt.Errorf("no valid SSO token for %s (%v): sign in with `ark aws sso --start-url %s --region %s` first",
+			profileConfig.StartURL, err, profileConfig.StartURL, profileConfig.SSORegion)
+	}
+	// Break-glass access needs a justification in the audit log, which a quick switch doesn't ask for
+	if services_aws.IsBreakGlassRole(profileConfig, ark_config.Get().AWS.BreakGlass) {
+		return "", fmt.Errorf("%s is a break-glass role: use `ark aws login --account %s --role %s --break-glass` instead",
+			profileConfig.RoleName, profileConfig.AccountID, profileConfig.RoleName)
+	}
+
+	client, err := newClient(ctx, profileConfig.SSORegion, profileConfig.StartURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to create SSO client: %w", err)
+	}
+	creds, err := services_aws.SSORoleCredentials(ctx, client, &profileConfig)
+	if err != nil {
+		return "", err
+	}
+
+	profile := services_aws.AWSProfile{AccountID: profileConfig.AccountID, RoleName: profileConfig.RoleName}
+	if err := services_aws.WriteAccountRoleCredentials(profileConfig.ProfileName, profile, creds, sw.SetAsDefault); err != nil {
+		return "", err
+	}
+	return profileConfig.ProfileName, nil
+}
+
+// siblingProfile finds the SSO profile of an account and role, the role compared without case
+func siblingProfile(profiles []services_aws.ProfileConfig, accountID, roleName string) (services_aws.ProfileConfig, bool) {
+	for _, profile := range profiles {
+		if profile.ProfileType == services_aws.ProfileTypeSSO && profile.AccountID == accountID && strings.EqualFold(profile.RoleName, roleName) {
+			return profile, true
+		}
+	}
+	return services_aws.ProfileConfig{}, false
+}
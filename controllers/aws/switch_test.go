@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// credentialsPortal hands out role credentials and records what they were asked for
+type credentialsPortal struct {
+	*fakeSSOPortal
+	requests []string
+}
+
+func (p *credentialsPortal) GetRoleCredentials(ctx context.Context, accessToken, accountID, roleName string) (*services_aws.Credentials, error) {
+	p.requests = append(p.requests, accessToken+" "+accountID+" "+roleName)
+	return &services_aws.Credentials{AccessKeyID: "ASIA" + accountID, SecretAccessKey: "secret", SessionToken: "session", Expiration: 1893456000000}, nil
+}
+
+func TestSwitchAccount(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("AWS_CONFIG_FILE", "")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", "")
+	startURL := "https://my-org.awsapps.com/start"
+
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".aws"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".aws", "config"), []byte(`[profile dev-readonly]
+sso_start_url = `+startURL+`
+sso_region = eu-west-1
+sso_account_id = 111111111111
+sso_role_name = ReadOnly
+`), 0600))
+
+	portal := &credentialsPortal{fakeSSOPortal: newFakeSSOPortal("eu-west-1", startURL)}
+	newClient := func(ctx context.Context, region, startURL string) (services_aws.SSOPortalClient, error) {
+		return portal, nil
+	}
+
+	// Without a cached token nothing is asked of the portal: signing in would need a browser
+	_, err := switchAccount(context.Background(), AccountSwitch{AccountID: "111111111111", RoleName: "ReadOnly"}, newClient)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no valid SSO token for "+startURL)
+	assert.Empty(t, portal.requests)
+
+	client := &services_aws.SSOClient{StartURL: startURL, Region: "eu-west-1"}
+	require.NoError(t, client.SaveTokenToCache(&services_aws.TokenResponse{AccessToken: "cached-token", ExpiresIn: 3600}))
+
+	// The sibling profile names the credentials and picks the portal
+	profileName, err := switchAccount(context.Background(), AccountSwitch{AccountID: "111111111111", RoleName: "readonly", SetAsDefault: true}, newClient)
+	require.NoError(t, err)
+	assert.Equal(t, "dev-readonly", profileName)
+	assert.Equal(t, []string{"cached-token 111111111111 ReadOnly"}, portal.requests)
+
+	credentials, err := os.ReadFile(filepath.Join(home, ".aws", "credentials"))
+	require.NoError(t, err)
+	assert.Contains(t, string(credentials), "[dev-readonly]")
+	assert.Contains(t, string(credentials), "[default]")
+	assert.Contains(t, string(credentials), "ASIA111111111111")
+
+	// Accounts without a profile need the portal
+	_, err = switchAccount(context.Background(), AccountSwitch{AccountID: "222222222222", RoleName: "Admin"}, newClient)
+	assert.ErrorContains(t, err, "no profile has account 222222222222 and role Admin")
+
+	profileName, err = switchAccount(context.Background(), AccountSwitch{
+		AccountID: "222222222222",
+		RoleName:  "Admin",
+		Portal:    services_aws.SSOPortal{StartURL: startURL, Region: "eu-west-1"},
+	}, newClient)
+	require.NoError(t, err)
+	assert.Equal(t, "222222222222-admin", profileName)
+	assert.Equal(t, "cached-token 222222222222 Admin", portal.requests[1])
+}
+
+func TestSiblingProfile(t *testing.T) {
+	profiles := []services_aws.ProfileConfig{
+		{ProfileName: "dev-deploy", ProfileType: services_aws.ProfileTypeAssumeRole, AccountID: "111111111111", RoleName: "ReadOnly"},
+		{ProfileName: "dev-readonly", ProfileType: services_aws.ProfileTypeSSO, AccountID: "111111111111", RoleName: "ReadOnly"},
+	}
+
+	profile, ok := siblingProfile(profiles, "111111111111", "READONLY")
+	require.True(t, ok)
+	assert.Equal(t, "dev-readonly", profile.ProfileName)
+
+	_, ok = siblingProfile(profiles, "111111111111", "Admin")
+	assert.False(t, ok)
+}
@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"context"
+	"sync"
+
+	"github.com/andresgarcia29/ark-cli/lib"
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+)
+
+// ClusterAddons is the add-on inventory of one cluster, or the error that prevented reading it
+type ClusterAddons struct {
+	Cluster services_aws.EKSCluster
+	Addons  []services_aws.EKSAddon
+	Err     error
+}
+
+// CollectClusterAddons reads the add-ons of every cluster in parallel, across accounts and regions
+// The latest versions are looked up once per region, add-on and Kubernetes version for the whole run
+func CollectClusterAddons(ctx context.Context, newLister services_aws.AddonListerFactory, clusters []services_aws.EKSCluster) []ClusterAddons {
+	pool := lib.NewWorkerPool(describeWorkers)
+	latest := &services_aws.AddonVersionCache{}
+	inventories := make([]ClusterAddons, len(clusters))
+
+	var wg sync.WaitGroup
+	for i, cluster := range clusters {
+		wg.Add(1)
+		go func(i int, cluster services_aws.EKSCluster) {
+			defer wg.Done()
+
+			inventory := ClusterAddons{Cluster: cluster}
+			inventory.Err = pool.Execute(ctx, func() error {
+				lister, err := newLister(ctx, cluster.Region, cluster.Profile)
+				if err != nil {
+					return err
+				}
+				inventory.Addons, err = services_aws.GetClusterAddons(ctx, lister, cluster, latest)
+				return err
+			})
+			inventories[i] = inventory
+		}(i, cluster)
+	}
+	wg.Wait()
+
+	return inventories
+}
@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// addonLister reports a single vpc-cni add-on on every cluster
+type addonLister struct {
+	versionLister
+}
+
+func (a *addonLister) ListAddons(ctx context.Context, clusterName string) ([]string, error) {
+	return []string{"vpc-cni"}, nil
+}
+
+func (a *addonLister) DescribeAddon(ctx context.Context, clusterName, name string) (*services_aws.EKSAddon, error) {
+	return &services_aws.EKSAddon{Cluster: clusterName, Name: name, Version: "v1.18.1-eksbuild.1"}, nil
+}
+
+func (a *addonLister) LatestAddonVersion(ctx context.Context, name, kubernetesVersion string) (string, error) {
+	return "v1.18.3-eksbuild.2", nil
+}
+
+func TestCollectClusterAddons(t *testing.T) {
+	lister := &addonLister{versionLister{versions: map[string]string{"api": "1.30", "web": "1.29"}}}
+	clusters := []services_aws.EKSCluster{
+		{Name: "api", Region: "us-west-2", AccountID: "111111111111", Profile: "dev"},
+		{Name: "web", Region: "eu-west-1", AccountID: "222222222222", Profile: "prod"},
+		{Name: "locked", Region: "us-west-2", AccountID: "333333333333", Profile: "locked"},
+	}
+
+	inventories := CollectClusterAddons(context.Background(), func(ctx context.Context, region, profile string) (services_aws.AddonLister, error) {
+		if profile == "locked" {
+			return nil, errors.New("no credentials")
+		}
+		return lister, nil
+	}, clusters)
+
+	require.Len(t, inventories, 3)
+	assert.Equal(t, clusters[0], inventories[0].Cluster)
+	require.Len(t, inventories[0].Addons, 1)
+	assert.True(t, inventories[0].Addons[0].UpdateAvailable())
+	require.NoError(t, inventories[1].Err)
+	assert.EqualError(t, inventories[2].Err, "no credentials")
+}
@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	"github.com/andresgarcia29/ark-cli/services/aws/arn"
+	services_kubernetes "github.com/andresgarcia29/ark-cli/services/kubernetes"
+)
+
+// AWSAuthStatus says what IAM knows about the principal of an aws-auth mapping
+type AWSAuthStatus string
+
+const (
+	AWSAuthPrincipalExists  AWSAuthStatus = "exists"
+	AWSAuthPrincipalMissing AWSAuthStatus = "missing"
+	// AWSAuthOtherAccount principals live in another account, which the cluster's profile can't look into
+	AWSAuthOtherAccount AWSAuthStatus = "other-account"
+	// AWSAuthNotChecked is used for mapAccounts entries, which map whole accounts
+	AWSAuthNotChecked  AWSAuthStatus = "not-checked"
+	AWSAuthCheckFailed AWSAuthStatus = "check-failed"
+)
+
+// AWSAuthCheck is an aws-auth mapping with the IAM status of its principal
+type AWSAuthCheck struct {
+	Mapping services_kubernetes.AWSAuthMapping
+	Status  AWSAuthStatus
+	Err     error
+}
+
+// ReadAWSAuth describes a cluster and reads its aws-auth mappings with a token of the cluster's profile
+func ReadAWSAuth(ctx context.Context, newLister services_aws.ClusterListerFactory, cluster *services_aws.EKSCluster) ([]services_kubernetes.AWSAuthMapping, error) {
+	if err := services_aws.DescribeClusterWith(ctx, newLister, cluster); err != nil {
+		return nil, err
+	}
+
+	token, err := services_aws.GetEKSToken(ctx, cluster.Name, cluster.Region, cluster.Profile)
+	if err != nil {
+		return nil, err
+	}
+	clientset, err := services_kubernetes.NewEKSClientset(cluster.Endpoint, cluster.CertificateAuthorityData, token.Token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kubernetes client for %s: %w", cluster.Name, err)
+	}
+	return services_kubernetes.GetAWSAuth(ctx, clientset)
+}
+
+// CheckAWSAuthMappings looks up the IAM principal of every mapping of accountID, flagging the ones that no longer exist
+func CheckAWSAuthMappings(ctx context.Context, checker services_aws.PrincipalChecker, accountID string, mappings []services_kubernetes.AWSAuthMapping) []AWSAuthCheck {
+	checks := make([]AWSAuthCheck, 0, len(mappings))
+	for _, mapping := range mappings {
+		check := AWSAuthCheck{Mapping: mapping}
+		switch principal, err := services_aws.ParseIAMPrincipal(mapping.ARN); {
+		case mapping.Kind == "account":
+			check.Status = AWSAuthNotChecked
+		case err != nil:
+			check.Status, check.Err = AWSAuthCheckFailed, err
+		case principal.AccountID != accountID:
+			check.Status = AWSAuthOtherAccount
+		default:
+			exists, err := checker.PrincipalExists(ctx, mapping.ARN)
+			switch {
+			case err != nil:
+				check.Status, check.Err = AWSAuthCheckFailed, err
+			case exists:
+				check.Status = AWSAuthPrincipalExists
+			default:
+				check.Status = AWSAuthPrincipalMissing
+			}
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// ClusterAccountID extracts the account ID from a cluster ARN such as arn:aws:eks:us-west-2:123456789012:cluster/prod
+func ClusterAccountID(clusterARN string) string {
+	return arn.AccountID(clusterARN)
+}
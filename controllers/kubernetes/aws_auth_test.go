@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	services_kubernetes "github.com/andresgarcia29/ark-cli/services/kubernetes"
+	"github.com/stretchr/testify/assert"
+)
+
+// principalSet is a PrincipalChecker over a fixed set of existing principals
+type principalSet struct {
+	existing map[string]bool
+	err      error
+	checked  []string
+}
+
+func (p *principalSet) PrincipalExists(ctx context.Context, principalARN string) (bool, error) {
+	p.checked = append(p.checked, principalARN)
+	return p.existing[principalARN], p.err
+}
+
+func TestCheckAWSAuthMappings(t *testing.T) {
+	checker := &principalSet{existing: map[string]bool{"arn:aws:iam::111111111111:role/Nodes": true}}
+	mappings := []services_kubernetes.AWSAuthMapping{
+		{Kind: "role", ARN: "arn:aws:iam::111111111111:role/Nodes"},
+		{Kind: "role", ARN: "arn:aws:iam::111111111111:role/Deleted"},
+		{Kind: "role", ARN: "arn:aws:iam::222222222222:role/Shared"},
+		{Kind: "user", ARN: "alice"},
+		{Kind: "account", ARN: "333333333333"},
+	}
+
+	checks := CheckAWSAuthMappings(context.Background(), checker, "111111111111", mappings)
+	var statuses []AWSAuthStatus
+	for _, check := range checks {
+		statuses = append(statuses, check.Status)
+	}
+	assert.Equal(t, []AWSAuthStatus{
+		AWSAuthPrincipalExists, AWSAuthPrincipalMissing, AWSAuthOtherAccount, AWSAuthCheckFailed, AWSAuthNotChecked,
+	}, statuses)
+	// Only principals of the cluster's account are looked up
+	assert.Equal(t, []string{"arn:aws:iam::111111111111:role/Nodes", "arn:aws:iam::111111111111:role/Deleted"}, checker.checked)
+
+	checker.err = errors.New("access denied")
+	checks = CheckAWSAuthMappings(context.Background(), checker, "111111111111", mappings[:1])
+	assert.Equal(t, AWSAuthCheckFailed, checks[0].Status)
+	assert.ErrorContains(t, checks[0].Err, "access denied")
+}
+
+func TestClusterAccountID(t *testing.T) {
+	assert.Equal(t, "111111111111", ClusterAccountID("arn:aws:eks:us-west-2:111111111111:cluster/prod"))
+	assert.Equal(t, "", ClusterAccountID(""))
+}
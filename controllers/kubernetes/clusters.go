@@ -1,42 +1,59 @@
 package controllers
 
 import (
-	"bytes"
+	"context"
 	"fmt"
 	"os/exec"
+	"sync"
+	"time"
 
+	"github.com/andresgarcia29/ark-cli/lib"
 	"github.com/andresgarcia29/ark-cli/lib/animation"
 	"github.com/andresgarcia29/ark-cli/logs"
 	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	services_kubernetes "github.com/andresgarcia29/ark-cli/services/kubernetes"
 )
 
 // UpdateKubeconfigForCluster executes aws eks update-kubeconfig for a specific cluster
 func UpdateKubeconfigForCluster(cluster services_aws.EKSCluster, replaceProfile string) error {
+	return updateKubeconfigForCluster(cluster, replaceProfile, "")
+}
+
+// updateKubeconfigForCluster runs aws eks update-kubeconfig, writing to kubeconfigPath when set
+func updateKubeconfigForCluster(cluster services_aws.EKSCluster, replaceProfile, kubeconfigPath string) error {
 	if replaceProfile != "" {
 		cluster.Profile = replaceProfile
 	}
 
-	cmd := exec.Command(
-		"aws",
+	args := []string{
 		"eks",
 		"update-kubeconfig",
 		"--name", cluster.Name,
 		"--region", cluster.Region,
 		"--profile", cluster.Profile,
-		"--alias", cluster.Name,
-	)
-
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+		"--alias", clusterContextAlias(cluster),
+	}
+	if kubeconfigPath != "" {
+		args = append(args, "--kubeconfig", kubeconfigPath)
+	}
 
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to update kubeconfig for cluster %s: %w\nStderr: %s", cluster.Name, err, stderr.String())
+	if err := lib.RunCommand(exec.Command("aws", args...)); err != nil {
+		return fmt.Errorf("failed to update kubeconfig for cluster %s: %w", cluster.Name, err)
 	}
 
 	return nil
 }
 
+// clusterContextAlias names the context of a cluster after the kubernetes.context_alias template
+func clusterContextAlias(cluster services_aws.EKSCluster) string {
+	return services_kubernetes.ContextAlias(services_kubernetes.ContextAliasData{
+		Cluster:   cluster.Name,
+		Region:    cluster.Region,
+		AccountID: cluster.AccountID,
+		Profile:   cluster.Profile,
+	})
+}
+
 // UpdateKubeconfigForAllClusters updates kubeconfig for all clusters
 func UpdateKubeconfigForAllClusters(clusters []services_aws.EKSCluster, replaceProfile string) error {
 	logger := logs.GetLogger()
@@ -48,52 +65,76 @@ func UpdateKubeconfigForAllClusters(clusters []services_aws.EKSCluster, replaceP
 
 	logger.Infof("Configuring %d cluster(s)", len(clusters))
 
-	var errors []error
-	successCount := 0
-
-	for _, cluster := range clusters {
-		logger.Infof("Configuring cluster: %s (account: %s, region: %s)",
-			cluster.Name, cluster.AccountID, cluster.Region)
+	writer, err := NewKubeconfigWriter(WriterAWSCLI, KubeconfigWriterOptions{ReplaceProfile: replaceProfile})
+	if err != nil {
+		return err
+	}
 
-		if err := UpdateKubeconfigForCluster(cluster, replaceProfile); err != nil {
-			logger.Errorw("Error configuring cluster",
-				"cluster", cluster.Name,
-				"account", cluster.AccountID,
-				"region", cluster.Region,
-				"error", err)
-			errors = append(errors, fmt.Errorf("cluster %s: %w", cluster.Name, err))
-		} else {
+	results := writeClusters(context.Background(), writer, clusters, func(result ClusterResult) {
+		switch result.State {
+		case ClusterConfigured:
 			logger.Infow("Cluster configured successfully",
-				"cluster", cluster.Name,
-				"account", cluster.AccountID,
-				"region", cluster.Region)
-			successCount++
+				"cluster", result.Cluster.Name,
+				"account", result.Cluster.AccountID,
+				"region", result.Cluster.Region,
+				"attempts", result.Attempts)
+		case ClusterSkipped:
+			logger.Warnw("Skipping cluster",
+				"cluster", result.Cluster.Name,
+				"account", result.Cluster.AccountID,
+				"region", result.Cluster.Region,
+				"error", result.Err)
+		default:
+			logger.Errorw("Error configuring cluster",
+				"cluster", result.Cluster.Name,
+				"account", result.Cluster.AccountID,
+				"region", result.Cluster.Region,
+				"attempts", result.Attempts,
+				"error", result.Err)
 		}
+	})
+
+	// Write every configured cluster to the kubeconfig at once
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("failed to write kubeconfig: %w", err)
 	}
 
 	// Report final statistics
+	summary := summarizeClusterResults(results)
 	logger.Infow("Configuration completed",
-		"successful", successCount,
-		"failed", len(errors),
+		"successful", summary[ClusterConfigured],
+		"skipped", summary[ClusterSkipped],
+		"failed", summary[ClusterFailed],
 		"total", len(clusters))
 
-	if len(errors) > 0 {
-		logger.Warn("Errors found during configuration:")
-		for _, err := range errors {
-			logger.Warnf("  - %v", err)
+	if summary[ClusterFailed]+summary[ClusterSkipped] > 0 {
+		logger.Warn("Clusters not configured:")
+		for _, result := range results {
+			if result.State != ClusterConfigured {
+				logger.Warnf("  - %s (%s): %v", result.Cluster.Name, result.State, result.Err)
+			}
 		}
 	}
 
 	// We only consider the operation as failed if ALL clusters failed
-	if len(errors) > 0 && successCount == 0 {
-		return fmt.Errorf("configuration failed for all %d clusters", len(errors))
+	if summary[ClusterFailed] > 0 && summary[ClusterConfigured] == 0 {
+		return fmt.Errorf("configuration failed for all %d clusters", summary[ClusterFailed])
 	}
 
 	return nil
 }
 
-// UpdateKubeconfigWithProgress updates kubeconfig for all clusters with a progress bar
+// UpdateKubeconfigWithProgress updates kubeconfig for all clusters with a progress bar using the AWS CLI
 func UpdateKubeconfigWithProgress(clusters []services_aws.EKSCluster, replaceProfile string) error {
+	writer, err := NewKubeconfigWriter(WriterAWSCLI, KubeconfigWriterOptions{ReplaceProfile: replaceProfile})
+	if err != nil {
+		return err
+	}
+	return UpdateKubeconfigWithWriter(context.Background(), writer, clusters)
+}
+
+// UpdateKubeconfigWithWriter updates kubeconfig for all clusters through the given writer with a progress bar
+func UpdateKubeconfigWithWriter(ctx context.Context, writer KubeconfigWriter, clusters []services_aws.EKSCluster) error {
 	if len(clusters) == 0 {
 		fmt.Println("No clusters to configure")
 		return nil
@@ -101,42 +142,118 @@ func UpdateKubeconfigWithProgress(clusters []services_aws.EKSCluster, replacePro
 
 	// Variable para almacenar errores
 	var finalError error
+	var results []ClusterResult
 
 	// Usar la barra de progreso
-	err := animation.ShowProgressBar(len(clusters), func(update func(item string, err error)) error {
-		var errors []error
-
-		for _, cluster := range clusters {
-			// Configurar el cluster
-			clusterName := fmt.Sprintf("%s (%s)", cluster.Name, cluster.Region)
-			err := UpdateKubeconfigForCluster(cluster, replaceProfile)
-
+	err := animation.ShowDetailedProgressBar(len(clusters), func(update func(animation.ProgressUpdate)) error {
+		results = writeClusters(ctx, writer, clusters, func(result ClusterResult) {
 			// Actualizar el progreso
-			update(clusterName, err)
-
-			// Guardar error si existe
-			if err != nil {
-				errors = append(errors, fmt.Errorf("cluster %s: %w", cluster.Name, err))
-			}
-		}
+			update(animation.ProgressUpdate{
+				Item:     result.Cluster.Name,
+				Account:  result.Cluster.AccountID,
+				Region:   result.Cluster.Region,
+				Phase:    string(result.State),
+				Duration: result.Duration,
+				Err:      result.Err,
+			})
+		})
 
-		// Si hay errores pero no todos fallaron, no retornar error
+		// Los clusters omitidos no cuentan como fallos
 		// Solo retornar error si TODOS fallaron
-		if len(errors) > 0 && len(errors) == len(clusters) {
-			finalError = fmt.Errorf("configuration failed for all %d clusters", len(errors))
+		summary := summarizeClusterResults(results)
+		if summary[ClusterFailed] > 0 && summary[ClusterConfigured] == 0 {
+			finalError = fmt.Errorf("configuration failed for all %d clusters", summary[ClusterFailed])
 			return finalError
 		}
 
-		if len(errors) > 0 {
-			finalError = fmt.Errorf("some clusters failed to configure (%d/%d)", len(errors), len(clusters))
+		if summary[ClusterFailed] > 0 {
+			finalError = fmt.Errorf("some clusters failed to configure (%d/%d)", summary[ClusterFailed], len(clusters))
 		}
 
 		return nil
 	})
 
+	// Persist the buffered changes in a single write (also releases temporary files)
+	if flushErr := writer.Flush(); flushErr != nil && err == nil {
+		return flushErr
+	}
+
+	PrintClusterReport(results)
+
 	if err != nil {
 		return err
 	}
 
 	return finalError
 }
+
+// writeClusters writes all clusters through the writer in parallel, calling done after each one
+// Transient failures are retried; permanent ones (e.g. the cluster was deleted mid-scan) are skipped
+// Results are returned in the order of clusters; the caller is responsible for calling Flush
+func writeClusters(ctx context.Context, writer KubeconfigWriter, clusters []services_aws.EKSCluster, done func(result ClusterResult)) []ClusterResult {
+	pool := lib.NewWorkerPool(clusterRetryConfig.MaxWorkers)
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		results = make([]ClusterResult, len(clusters))
+	)
+
+	for i, cluster := range clusters {
+		wg.Add(1)
+		go func(i int, cluster services_aws.EKSCluster) {
+			defer wg.Done()
+
+			result := ClusterResult{Cluster: cluster, State: ClusterConfigured}
+			start := time.Now()
+			err := pool.Execute(ctx, func() error {
+				// Only time the work itself, not the wait for a free worker
+				start = time.Now()
+				return lib.ExecuteWithRetry(ctx, clusterRetryConfig, func() error {
+					result.Attempts++
+					err := writer.WriteCluster(ctx, cluster)
+					if isSkippableClusterError(err) {
+						return lib.Permanent(err)
+					}
+					return err
+				})
+			})
+
+			result.Duration = time.Since(start)
+
+			switch {
+			case err == nil:
+			case isSkippableClusterError(err):
+				result.State = ClusterSkipped
+				result.Err = err
+			default:
+				result.State = ClusterFailed
+				result.Err = err
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			results[i] = result
+			done(result)
+		}(i, cluster)
+	}
+
+	wg.Wait()
+	return results
+}
+
+// MapClusterProfiles points clusters at the profile their mapping rule gives, e.g. a hub account role for a
+// cluster shared through AWS RAM, and returns how many were remapped
+func MapClusterProfiles(clusters []services_aws.EKSCluster, profiles *services_kubernetes.ClusterProfiles) int {
+	mapped := 0
+	for i, cluster := range clusters {
+		profile, ok := profiles.ProfileFor(cluster.Name, cluster.AccountID, cluster.Region)
+		if !ok || profile == cluster.Profile {
+			continue
+		}
+		logs.GetLogger().Debugw("Mapping cluster to profile", "cluster", cluster.Name, "from", cluster.Profile, "to", profile)
+		clusters[i].Profile = profile
+		mapped++
+	}
+	return mapped
+}
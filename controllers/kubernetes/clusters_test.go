@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	services_kubernetes "github.com/andresgarcia29/ark-cli/services/kubernetes"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -613,3 +614,21 @@ func TestUpdateKubeconfigWithProgressFunctionSignature(t *testing.T) {
 		return nil
 	}
 }
+
+func TestMapClusterProfiles(t *testing.T) {
+	profiles := &services_kubernetes.ClusterProfiles{Clusters: []services_kubernetes.ClusterProfileRule{
+		{Cluster: "shared-*", Profile: "hub-readonly"},
+	}}
+	clusters := []services_aws.EKSCluster{
+		{Name: "shared-prod", Region: "us-east-1", AccountID: "222222222222", Profile: "spoke-readonly"},
+		{Name: "dev", Region: "us-east-1", AccountID: "111111111111", Profile: "dev-readonly"},
+		{Name: "shared-dev", Region: "us-east-1", AccountID: "111111111111", Profile: "hub-readonly"},
+	}
+
+	assert.Equal(t, 1, MapClusterProfiles(clusters, profiles))
+	assert.Equal(t, "hub-readonly", clusters[0].Profile)
+	assert.Equal(t, "dev-readonly", clusters[1].Profile)
+	assert.Equal(t, "hub-readonly", clusters[2].Profile)
+
+	assert.Equal(t, 0, MapClusterProfiles(clusters, nil))
+}
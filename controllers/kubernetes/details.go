@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	"context"
+	"sync"
+
+	"github.com/andresgarcia29/ark-cli/lib"
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+)
+
+// describeConfig paces the DescribeCluster calls of --details, which all go to EKS
+var describeConfig = lib.ServiceConfig(lib.ServiceEKS)
+
+// DescribeClusters fills the endpoint, certificate, ARN and version of every cluster, one DescribeCluster call
+// each. It is the slow part of a setup, so it only runs with --details: discovery alone is enough to write
+// contexts. Clusters that can't be described are returned as they were, and counted in failed
+func DescribeClusters(ctx context.Context, newLister services_aws.ClusterListerFactory, clusters []services_aws.EKSCluster) (described []services_aws.EKSCluster, failed int) {
+	pool := lib.NewWorkerPool(describeConfig.MaxWorkers)
+	limiter := lib.NewRateLimiter(describeConfig.RateLimitDelay)
+	described = make([]services_aws.EKSCluster, len(clusters))
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i, cluster := range clusters {
+		wg.Add(1)
+		go func(i int, cluster services_aws.EKSCluster) {
+			defer wg.Done()
+
+			err := pool.Execute(ctx, func() error {
+				return lib.ExecuteWithRetry(ctx, describeConfig, func() error {
+					if err := limiter.Wait(ctx); err != nil {
+						return err
+					}
+					detailed := cluster
+					if err := services_aws.DescribeClusterWith(ctx, newLister, &detailed); err != nil {
+						if isSkippableClusterError(err) {
+							return lib.Permanent(err)
+						}
+						return err
+					}
+					cluster = detailed
+					return nil
+				})
+			})
+			if err != nil {
+				mu.Lock()
+				failed++
+				mu.Unlock()
+			}
+			described[i] = cluster
+		}(i, cluster)
+	}
+	wg.Wait()
+
+	return described, failed
+}
@@ -0,0 +1,60 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// detailsLister describes clusters by name; "locked" is denied and "flaky" fails once before answering
+type detailsLister struct {
+	flakyCalls atomic.Int32
+}
+
+func (d *detailsLister) ListClusters(ctx context.Context) ([]string, error) {
+	return nil, nil
+}
+
+func (d *detailsLister) DescribeCluster(ctx context.Context, cluster *services_aws.EKSCluster) error {
+	switch cluster.Name {
+	case "locked":
+		return errors.New("AccessDeniedException: not authorized")
+	case "flaky":
+		if d.flakyCalls.Add(1) == 1 {
+			return errors.New("ThrottlingException: rate exceeded")
+		}
+	}
+	cluster.Endpoint = "https://" + cluster.Name + ".eks.amazonaws.com"
+	cluster.Version = "1.30"
+	return nil
+}
+
+func TestDescribeClusters(t *testing.T) {
+	original := describeConfig
+	describeConfig.RetryDelay = time.Millisecond
+	describeConfig.RateLimitDelay = 0
+	defer func() { describeConfig = original }()
+
+	lister := &detailsLister{}
+	clusters := []services_aws.EKSCluster{
+		{Name: "api", Region: "us-west-2", AccountID: "111111111111"},
+		{Name: "locked", Region: "us-west-2", AccountID: "222222222222"},
+		{Name: "flaky", Region: "eu-west-1", AccountID: "333333333333"},
+	}
+
+	described, failed := DescribeClusters(context.Background(), func(ctx context.Context, region, profile string) (services_aws.ClusterLister, error) {
+		return lister, nil
+	}, clusters)
+	require.Len(t, described, 3)
+	assert.Equal(t, 1, failed)
+	assert.Equal(t, "https://api.eks.amazonaws.com", described[0].Endpoint)
+	assert.Equal(t, clusters[1], described[1], "clusters that can't be described are kept bare")
+	assert.Equal(t, "1.30", described[2].Version, "throttling is retried")
+	assert.Empty(t, clusters[0].Endpoint, "the input is left untouched")
+}
@@ -0,0 +1,97 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	services_kubernetes "github.com/andresgarcia29/ark-cli/services/kubernetes"
+)
+
+// nativeWriter keeps the kubeconfig in memory and writes it once on Flush
+type nativeWriter struct {
+	opts KubeconfigWriterOptions
+
+	mu         sync.Mutex
+	kubeconfig *services_kubernetes.Kubeconfig
+}
+
+func newNativeWriter(opts KubeconfigWriterOptions) (*nativeWriter, error) {
+	if opts.AuthMode == "" {
+		opts.AuthMode = services_kubernetes.AuthModeExec
+	}
+	if opts.NewClusterLister == nil {
+		opts.NewClusterLister = services_aws.NewClusterLister
+	}
+
+	kubeconfig, err := services_kubernetes.LoadKubeconfig(opts.KubeconfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+
+	return &nativeWriter{opts: opts, kubeconfig: kubeconfig}, nil
+}
+
+func (w *nativeWriter) Kind() WriterKind {
+	return WriterNative
+}
+
+func (w *nativeWriter) WriteCluster(ctx context.Context, cluster services_aws.EKSCluster) error {
+	contextOpts, err := buildEKSContextOptions(ctx, cluster, w.opts)
+	if err != nil {
+		return fmt.Errorf("failed to update kubeconfig for cluster %s: %w", cluster.Name, err)
+	}
+
+	w.mu.Lock()
+	w.kubeconfig.UpsertEKSContext(contextOpts)
+	w.mu.Unlock()
+	return nil
+}
+
+func (w *nativeWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := services_kubernetes.SaveKubeconfig(w.opts.KubeconfigPath, w.kubeconfig); err != nil {
+		return fmt.Errorf("failed to save kubeconfig: %w", err)
+	}
+	return nil
+}
+
+// buildEKSContextOptions describes the cluster (if needed) and builds the kubeconfig entry for it
+func buildEKSContextOptions(ctx context.Context, cluster services_aws.EKSCluster, opts KubeconfigWriterOptions) (services_kubernetes.EKSContextOptions, error) {
+	if opts.ReplaceProfile != "" {
+		cluster.Profile = opts.ReplaceProfile
+	}
+
+	if cluster.Endpoint == "" {
+		if err := services_aws.DescribeClusterWith(ctx, opts.NewClusterLister, &cluster); err != nil {
+			return services_kubernetes.EKSContextOptions{}, err
+		}
+	}
+
+	contextOpts := services_kubernetes.EKSContextOptions{
+		Alias:                    clusterContextAlias(cluster),
+		ClusterName:              cluster.Name,
+		ARN:                      cluster.ARN,
+		Endpoint:                 cluster.Endpoint,
+		CertificateAuthorityData: cluster.CertificateAuthorityData,
+		Region:                   cluster.Region,
+		AccountID:                cluster.AccountID,
+		Profile:                  cluster.Profile,
+		DiscoveredAt:             opts.DiscoveredAt,
+		AuthMode:                 opts.AuthMode,
+		ExecCommand:              opts.ExecCommand,
+	}
+
+	if opts.AuthMode == services_kubernetes.AuthModeStatic {
+		token, err := services_aws.GetEKSToken(ctx, cluster.Name, cluster.Region, cluster.Profile)
+		if err != nil {
+			return services_kubernetes.EKSContextOptions{}, err
+		}
+		contextOpts.StaticToken = token.Token
+	}
+
+	return contextOpts, nil
+}
@@ -0,0 +1,137 @@
+package controllers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	services_kubernetes "github.com/andresgarcia29/ark-cli/services/kubernetes"
+)
+
+// ContextAction is what a setup run does to one kubeconfig context
+type ContextAction string
+
+const (
+	ContextAdded   ContextAction = "added"
+	ContextUpdated ContextAction = "updated"
+	ContextRemoved ContextAction = "removed"
+)
+
+// ContextChange is a context a setup run adds, rewrites or removes
+type ContextChange struct {
+	Context   string
+	AccountID string
+	Region    string
+	Action    ContextAction
+}
+
+// KubeconfigPlan lists the context changes of a setup run before the kubeconfig is touched
+type KubeconfigPlan struct {
+	Changes []ContextChange
+}
+
+// PlanKubeconfigChanges compares the kubeconfig with the clusters about to be written
+// Contexts are named by the alias template; with clean, every existing context that is not rewritten is removed
+func PlanKubeconfigChanges(existing *services_kubernetes.Kubeconfig, clusters []services_aws.EKSCluster, clean bool, replaceProfile string) KubeconfigPlan {
+	var plan KubeconfigPlan
+	written := make(map[string]bool, len(clusters))
+	for _, cluster := range clusters {
+		if replaceProfile != "" {
+			cluster.Profile = replaceProfile
+		}
+		alias := clusterContextAlias(cluster)
+		if written[alias] {
+			continue
+		}
+		written[alias] = true
+
+		action := ContextAdded
+		if existing.FindContext(alias) != nil {
+			action = ContextUpdated
+		}
+		plan.Changes = append(plan.Changes, ContextChange{
+			Context:   alias,
+			AccountID: cluster.AccountID,
+			Region:    cluster.Region,
+			Action:    action,
+		})
+	}
+
+	if clean {
+		for _, entry := range existing.Contexts {
+			if written[entry.Name] {
+				continue
+			}
+			accountID, region := contextLocation(existing, entry)
+			plan.Changes = append(plan.Changes, ContextChange{
+				Context:   entry.Name,
+				AccountID: accountID,
+				Region:    region,
+				Action:    ContextRemoved,
+			})
+		}
+	}
+	return plan
+}
+
+// contextLocation returns the account and region of an existing context
+// They come from the cluster ARN of contexts written by ark, or the exec block for the region
+func contextLocation(kubeconfig *services_kubernetes.Kubeconfig, entry services_kubernetes.NamedContext) (accountID, region string) {
+	if data, ok := kubeconfig.ManagedContextData(entry); ok {
+		return data.AccountID, data.Region
+	}
+	for _, clusterContext := range kubeconfig.ClusterContexts() {
+		if clusterContext.Name == entry.Name {
+			return "", clusterContext.Region
+		}
+	}
+	return "", ""
+}
+
+// Count returns how many changes have the given action
+func (p KubeconfigPlan) Count(action ContextAction) int {
+	count := 0
+	for _, change := range p.Changes {
+		if change.Action == action {
+			count++
+		}
+	}
+	return count
+}
+
+// Summary describes the changes grouped by account and region, one sorted line per group
+func (p KubeconfigPlan) Summary() []string {
+	type group struct {
+		accountID, region string
+	}
+	counts := make(map[group]map[ContextAction]int)
+	for _, change := range p.Changes {
+		key := group{accountID: change.AccountID, region: change.Region}
+		if counts[key] == nil {
+			counts[key] = make(map[ContextAction]int)
+		}
+		counts[key][change.Action]++
+	}
+
+	lines := make([]string, 0, len(counts))
+	for key, actions := range counts {
+		accountID, region := key.accountID, key.region
+		if accountID == "" {
+			accountID = "unknown account"
+		}
+		if region == "" {
+			region = "unknown region"
+		}
+
+		var parts []string
+		for _, action := range []ContextAction{ContextAdded, ContextUpdated, ContextRemoved} {
+			if actions[action] > 0 {
+				parts = append(parts, fmt.Sprintf("%d %s", actions[action], action))
+			}
+		}
+		lines = append(lines, fmt.Sprintf("%s / %s: %s", accountID, region, strings.Join(parts, ", ")))
+	}
+	sort.Strings(lines)
+	return lines
+}
@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"testing"
+
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	services_kubernetes "github.com/andresgarcia29/ark-cli/services/kubernetes"
+	"github.com/stretchr/testify/assert"
+)
+
+func planTestKubeconfig() *services_kubernetes.Kubeconfig {
+	kubeconfig := services_kubernetes.NewKubeconfig()
+	kubeconfig.UpsertEKSContext(services_kubernetes.EKSContextOptions{
+		Alias:       "prod",
+		ClusterName: "prod",
+		ARN:         "arn:aws:eks:us-west-2:111111111111:cluster/prod",
+		Region:      "us-west-2",
+		Profile:     "prod-readonly",
+	})
+	kubeconfig.UpsertEKSContext(services_kubernetes.EKSContextOptions{
+		Alias:       "legacy",
+		ClusterName: "legacy",
+		ARN:         "arn:aws:eks:eu-west-1:222222222222:cluster/legacy",
+		Region:      "eu-west-1",
+	})
+	kubeconfig.UpsertContext(services_kubernetes.NamedContext{
+		Name:    "minikube",
+		Context: services_kubernetes.KubeContext{Cluster: "minikube", User: "minikube"},
+	})
+	return kubeconfig
+}
+
+func TestPlanKubeconfigChanges(t *testing.T) {
+	clusters := []services_aws.EKSCluster{
+		{Name: "prod", AccountID: "111111111111", Region: "us-west-2"},
+		{Name: "staging", AccountID: "111111111111", Region: "us-west-2"},
+		{Name: "data", AccountID: "111111111111", Region: "us-east-1"},
+	}
+
+	t.Run("without clean", func(t *testing.T) {
+		plan := PlanKubeconfigChanges(planTestKubeconfig(), clusters, false, "")
+
+		assert.Equal(t, 2, plan.Count(ContextAdded))
+		assert.Equal(t, 1, plan.Count(ContextUpdated))
+		assert.Equal(t, 0, plan.Count(ContextRemoved))
+		assert.Equal(t, []string{
+			"111111111111 / us-east-1: 1 added",
+			"111111111111 / us-west-2: 1 added, 1 updated",
+		}, plan.Summary())
+	})
+
+	t.Run("with clean", func(t *testing.T) {
+		plan := PlanKubeconfigChanges(planTestKubeconfig(), clusters, true, "")
+
+		assert.Equal(t, 2, plan.Count(ContextRemoved))
+		assert.Equal(t, []string{
+			"111111111111 / us-east-1: 1 added",
+			"111111111111 / us-west-2: 1 added, 1 updated",
+			"222222222222 / eu-west-1: 1 removed",
+			"unknown account / unknown region: 1 removed",
+		}, plan.Summary())
+	})
+}
+
+func TestPlanKubeconfigChangesDuplicateNames(t *testing.T) {
+	// Two accounts with a cluster of the same name end up in one context
+	clusters := []services_aws.EKSCluster{
+		{Name: "main", AccountID: "111111111111", Region: "us-west-2"},
+		{Name: "main", AccountID: "222222222222", Region: "us-west-2"},
+	}
+
+	plan := PlanKubeconfigChanges(services_kubernetes.NewKubeconfig(), clusters, false, "")
+	assert.Len(t, plan.Changes, 1)
+	assert.Equal(t, 1, plan.Count(ContextAdded))
+}
@@ -0,0 +1,143 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andresgarcia29/ark-cli/lib"
+	"github.com/andresgarcia29/ark-cli/lib/animation"
+	"github.com/andresgarcia29/ark-cli/logs"
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+)
+
+// ClusterState is the outcome of configuring a single cluster
+type ClusterState string
+
+const (
+	// ClusterConfigured means the cluster was written to the kubeconfig
+	ClusterConfigured ClusterState = "configured"
+	// ClusterSkipped means the cluster can't be configured (deleted or not accessible) and was left out
+	ClusterSkipped ClusterState = "skipped"
+	// ClusterFailed means the cluster kept failing after all retries
+	ClusterFailed ClusterState = "failed"
+)
+
+// ClusterResult is the final status of a cluster after configuration
+type ClusterResult struct {
+	Cluster  services_aws.EKSCluster
+	State    ClusterState
+	Attempts int
+	Duration time.Duration
+	Err      error
+}
+
+// clusterRetryConfig controls how transient failures are retried per cluster
+var clusterRetryConfig = lib.DefaultParallelConfig()
+
+// skippableErrorCodes are AWS errors that won't go away by retrying
+// They are matched as text because the AWS CLI only reports them on stderr
+var skippableErrorCodes = []string{
+	"ResourceNotFoundException",
+	"AccessDeniedException",
+	"UnrecognizedClientException",
+}
+
+// isSkippableClusterError reports whether a cluster error is permanent and the cluster should be skipped
+func isSkippableClusterError(err error) bool {
+	if err == nil {
+		return false
+	}
+	message := err.Error()
+	for _, code := range skippableErrorCodes {
+		if strings.Contains(message, code) {
+			return true
+		}
+	}
+	return false
+}
+
+// summarizeClusterResults counts the results per state
+func summarizeClusterResults(results []ClusterResult) map[ClusterState]int {
+	summary := make(map[ClusterState]int)
+	for _, result := range results {
+		summary[result.State]++
+	}
+	return summary
+}
+
+// PrintClusterReport prints the final status of every cluster
+func PrintClusterReport(results []ClusterResult) {
+	if len(results) == 0 {
+		return
+	}
+
+	fmt.Println("\nCluster status:")
+	fmt.Print(buildClusterReportTable(results).Render())
+	// The table only shows the cause; the full error, with the command line that failed, is kept for --debug
+	for _, result := range results {
+		if result.Err != nil {
+			logs.GetLogger().Debugw("Cluster not configured", "cluster", result.Cluster.Name, "state", result.State, "error", result.Err.Error())
+		}
+	}
+
+	summary := summarizeClusterResults(results)
+	fmt.Printf("\nConfigured: %d, skipped: %d, failed: %d\n",
+		summary[ClusterConfigured], summary[ClusterSkipped], summary[ClusterFailed])
+
+	if calls := services_aws.SummarizeAPICalls(); calls.Calls > 0 {
+		fmt.Printf("AWS API: %d calls, %d retries, %d errors (use --verbose for details)\n",
+			calls.Calls, calls.Attempts-calls.Calls, calls.Errors)
+	}
+}
+
+// buildClusterReportTable lays out the cluster results as a table
+func buildClusterReportTable(results []ClusterResult) *animation.Table {
+	table := animation.NewTable("Cluster", "Account", "Region", "Status", "Attempts", "Error")
+	table.Columns[5].MaxWidth = 80
+	for _, result := range results {
+		errorMessage := ""
+		if result.Err != nil {
+			errorMessage = clusterErrorCause(result.Err)
+		}
+		table.AddRow(
+			result.Cluster.Name,
+			result.Cluster.AccountID,
+			result.Cluster.Region,
+			string(result.State),
+			strconv.Itoa(result.Attempts),
+			errorMessage,
+		)
+	}
+	return table
+}
+
+// clusterErrorCause returns what went wrong for a cluster, as a single line that fits a table cell
+// For a failed command that is what it printed on stderr rather than its command line, which only says what ran;
+// for other errors, the last non-empty line, where wrapped errors end with their cause
+func clusterErrorCause(err error) string {
+	var commandErr *lib.CommandError
+	if errors.As(err, &commandErr) {
+		if cause := lastNonEmptyLine(commandErr.Stderr); cause != "" {
+			return cause
+		}
+		if commandErr.ExitCode > 0 {
+			return fmt.Sprintf("exited with status %d", commandErr.ExitCode)
+		}
+		return lastNonEmptyLine(commandErr.Err.Error())
+	}
+	return lastNonEmptyLine(err.Error())
+}
+
+// lastNonEmptyLine returns the last line of text that isn't blank, trimmed
+func lastNonEmptyLine(text string) string {
+	lines := strings.Split(text, "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if line := strings.TrimSpace(lines[i]); line != "" {
+			return line
+		}
+	}
+	return ""
+}
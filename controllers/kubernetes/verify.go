@@ -0,0 +1,108 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andresgarcia29/ark-cli/lib"
+	"github.com/andresgarcia29/ark-cli/lib/animation"
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	services_kubernetes "github.com/andresgarcia29/ark-cli/services/kubernetes"
+)
+
+// verifyTimeout bounds each request of a context verification, so an unreachable private endpoint doesn't
+// hold up the report
+var verifyTimeout = 15 * time.Second
+
+// verifyContext is replaced in tests to avoid real API servers
+var verifyContext = services_kubernetes.VerifyContext
+
+// VerifyWrittenContexts checks that the contexts written for the clusters work, in parallel, by loading each one
+// from the kubeconfig and reading from its API server. Contexts the setup didn't end up writing (skipped or
+// failed clusters) are left out. Results are in the order of clusters
+func VerifyWrittenContexts(ctx context.Context, kubeconfigPath string, clusters []services_aws.EKSCluster, replaceProfile string) ([]services_kubernetes.ContextVerification, error) {
+	kubeconfig, err := services_kubernetes.LoadKubeconfig(kubeconfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var contexts []string
+	seen := make(map[string]bool, len(clusters))
+	for _, cluster := range clusters {
+		if replaceProfile != "" {
+			cluster.Profile = replaceProfile
+		}
+		alias := clusterContextAlias(cluster)
+		if seen[alias] || kubeconfig.FindContext(alias) == nil {
+			continue
+		}
+		seen[alias] = true
+		contexts = append(contexts, alias)
+	}
+
+	pool := lib.NewWorkerPool(clusterRetryConfig.MaxWorkers)
+	results := make([]services_kubernetes.ContextVerification, len(contexts))
+	var wg sync.WaitGroup
+	for i, name := range contexts {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			results[i] = services_kubernetes.ContextVerification{Context: name, State: services_kubernetes.ContextUnreachable}
+			err := pool.Execute(ctx, func() error {
+				results[i] = verifyContext(ctx, kubeconfigPath, name, verifyTimeout)
+				return nil
+			})
+			if err != nil {
+				results[i].Err = err
+			}
+		}(i, name)
+	}
+	wg.Wait()
+
+	return results, nil
+}
+
+// PrintContextVerification prints the outcome of every verified context, keeping credentials that authenticate
+// but lack RBAC apart from ones the cluster rejects
+func PrintContextVerification(results []services_kubernetes.ContextVerification) {
+	if len(results) == 0 {
+		return
+	}
+
+	fmt.Println("\nContext verification:")
+	fmt.Print(buildVerificationTable(results).Render())
+
+	summary := make(map[services_kubernetes.ContextState]int)
+	for _, result := range results {
+		summary[result.State]++
+	}
+	fmt.Printf("\nWorking: %d, forbidden by RBAC: %d, unauthorized: %d, unreachable: %d\n",
+		summary[services_kubernetes.ContextWorking],
+		summary[services_kubernetes.ContextForbidden],
+		summary[services_kubernetes.ContextUnauthorized],
+		summary[services_kubernetes.ContextUnreachable])
+	if summary[services_kubernetes.ContextForbidden] > 0 {
+		fmt.Println("Forbidden contexts authenticate: the role needs an access entry or RBAC binding on the cluster")
+	}
+	if summary[services_kubernetes.ContextUnauthorized] > 0 {
+		fmt.Println("Unauthorized contexts are rejected by the cluster: check the profile's credentials (ark aws) or the cluster's authentication mode")
+	}
+}
+
+// buildVerificationTable lays out the verified contexts as a table
+func buildVerificationTable(results []services_kubernetes.ContextVerification) *animation.Table {
+	table := animation.NewTable("Context", "Result", "Error")
+	table.Columns[2].MaxWidth = 80
+	for _, result := range results {
+		errorMessage := ""
+		if result.Err != nil {
+			// Only the first line fits in a table cell
+			errorMessage = strings.SplitN(result.Err.Error(), "\n", 2)[0]
+		}
+		table.AddRow(result.Context, string(result.State), errorMessage)
+	}
+	return table
+}
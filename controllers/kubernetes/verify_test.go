@@ -0,0 +1,61 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	services_kubernetes "github.com/andresgarcia29/ark-cli/services/kubernetes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyWrittenContexts(t *testing.T) {
+	kubeconfigPath := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, services_kubernetes.SaveKubeconfig(kubeconfigPath, planTestKubeconfig()))
+
+	original := verifyContext
+	defer func() { verifyContext = original }()
+	var mu sync.Mutex
+	var verified []string
+	verifyContext = func(ctx context.Context, path, name string, timeout time.Duration) services_kubernetes.ContextVerification {
+		mu.Lock()
+		verified = append(verified, name)
+		mu.Unlock()
+		assert.Equal(t, kubeconfigPath, path)
+		if name == "legacy" {
+			return services_kubernetes.ContextVerification{Context: name, State: services_kubernetes.ContextForbidden, Err: errors.New("forbidden")}
+		}
+		return services_kubernetes.ContextVerification{Context: name, State: services_kubernetes.ContextWorking}
+	}
+
+	results, err := VerifyWrittenContexts(context.Background(), kubeconfigPath, []services_aws.EKSCluster{
+		{Name: "prod", AccountID: "111111111111", Region: "us-west-2"},
+		{Name: "staging", AccountID: "111111111111", Region: "us-west-2"},
+		{Name: "legacy", AccountID: "222222222222", Region: "eu-west-1"},
+		{Name: "prod", AccountID: "111111111111", Region: "us-west-2"},
+	}, "")
+	require.NoError(t, err)
+
+	require.Len(t, results, 2, "contexts that weren't written are not verified, and each context once")
+	assert.Equal(t, "prod", results[0].Context)
+	assert.Equal(t, services_kubernetes.ContextWorking, results[0].State)
+	assert.Equal(t, "legacy", results[1].Context)
+	assert.Equal(t, services_kubernetes.ContextForbidden, results[1].State)
+	assert.ElementsMatch(t, []string{"prod", "legacy"}, verified)
+}
+
+func TestBuildVerificationTable(t *testing.T) {
+	table := buildVerificationTable([]services_kubernetes.ContextVerification{
+		{Context: "prod", State: services_kubernetes.ContextWorking},
+		{Context: "legacy", State: services_kubernetes.ContextUnauthorized, Err: errors.New("Unauthorized\nsecond line")},
+	})
+	rendered := table.Render()
+	assert.Contains(t, rendered, "unauthorized")
+	assert.Contains(t, rendered, "Unauthorized")
+	assert.NotContains(t, rendered, "second line", "only the first line of an error is shown")
+}
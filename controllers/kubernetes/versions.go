@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/andresgarcia29/ark-cli/lib"
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+)
+
+// ClusterVersion is the control plane version of a discovered cluster
+type ClusterVersion struct {
+	AccountID string `json:"account_id"`
+	Region    string `json:"region"`
+	Cluster   string `json:"cluster"`
+	Version   string `json:"version,omitempty"`
+	// Behind counts the minor versions between the cluster and the report reference
+	Behind   int    `json:"minor_versions_behind"`
+	Outdated bool   `json:"outdated"`
+	Error    string `json:"error,omitempty"`
+}
+
+// VersionReport aggregates control plane versions across the fleet
+type VersionReport struct {
+	// Reference is the version clusters are compared to: the newest one found unless set explicitly
+	Reference string `json:"reference_version"`
+	MaxSkew   int    `json:"max_skew"`
+	// Versions counts the clusters running each version
+	Versions map[string]int   `json:"versions"`
+	Outdated int              `json:"outdated"`
+	Clusters []ClusterVersion `json:"clusters"`
+}
+
+// describeWorkers bounds the concurrent DescribeCluster calls of a version report
+const describeWorkers = 10
+
+// DescribeClusterVersions describes every cluster to read its control plane version
+// Clusters that can't be described are kept with their error instead of failing the report
+func DescribeClusterVersions(ctx context.Context, newLister services_aws.ClusterListerFactory, clusters []services_aws.EKSCluster) []ClusterVersion {
+	pool := lib.NewWorkerPool(describeWorkers)
+	versions := make([]ClusterVersion, len(clusters))
+
+	var wg sync.WaitGroup
+	for i, cluster := range clusters {
+		wg.Add(1)
+		go func(i int, cluster services_aws.EKSCluster) {
+			defer wg.Done()
+
+			version := ClusterVersion{AccountID: cluster.AccountID, Region: cluster.Region, Cluster: cluster.Name}
+			err := pool.Execute(ctx, func() error {
+				return services_aws.DescribeClusterWith(ctx, newLister, &cluster)
+			})
+			if err != nil {
+				version.Error = err.Error()
+			} else {
+				version.Version = cluster.Version
+			}
+			versions[i] = version
+		}(i, cluster)
+	}
+	wg.Wait()
+
+	return versions
+}
+
+// BuildVersionReport compares each cluster to reference, or to the newest version found when reference is empty,
+// and flags those more than maxSkew minor versions behind
+// Clusters are sorted oldest first, then by account, region and name
+func BuildVersionReport(versions []ClusterVersion, reference string, maxSkew int) VersionReport {
+	if reference == "" {
+		reference = newestVersion(versions)
+	}
+	report := VersionReport{Reference: reference, MaxSkew: maxSkew, Versions: make(map[string]int)}
+
+	for _, version := range versions {
+		if version.Version != "" {
+			report.Versions[version.Version]++
+			if behind, err := services_aws.VersionSkew(report.Reference, version.Version); err == nil {
+				version.Behind = behind
+				version.Outdated = behind > maxSkew
+			}
+		}
+		if version.Outdated {
+			report.Outdated++
+		}
+		report.Clusters = append(report.Clusters, version)
+	}
+
+	sort.SliceStable(report.Clusters, func(i, j int) bool {
+		a, b := report.Clusters[i], report.Clusters[j]
+		if a.Behind != b.Behind {
+			return a.Behind > b.Behind
+		}
+		if a.AccountID != b.AccountID {
+			return a.AccountID < b.AccountID
+		}
+		if a.Region != b.Region {
+			return a.Region < b.Region
+		}
+		return a.Cluster < b.Cluster
+	})
+	return report
+}
+
+// newestVersion returns the most recent Kubernetes version among the described clusters
+func newestVersion(versions []ClusterVersion) string {
+	newest, newestMinor := "", -1
+	for _, version := range versions {
+		if minor, err := services_aws.KubernetesMinorVersion(version.Version); err == nil && minor > newestMinor {
+			newest, newestMinor = version.Version, minor
+		}
+	}
+	return newest
+}
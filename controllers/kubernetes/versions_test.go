@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// versionLister reports the version of each cluster by name, failing for unknown ones
+type versionLister struct {
+	versions map[string]string
+}
+
+func (v *versionLister) ListClusters(ctx context.Context) ([]string, error) {
+	return nil, nil
+}
+
+func (v *versionLister) DescribeCluster(ctx context.Context, cluster *services_aws.EKSCluster) error {
+	version, ok := v.versions[cluster.Name]
+	if !ok {
+		return errors.New("access denied")
+	}
+	cluster.Version = version
+	return nil
+}
+
+func TestDescribeClusterVersions(t *testing.T) {
+	lister := &versionLister{versions: map[string]string{"api": "1.30", "batch": "1.27"}}
+	clusters := []services_aws.EKSCluster{
+		{Name: "api", Region: "us-west-2", AccountID: "111111111111"},
+		{Name: "batch", Region: "eu-west-1", AccountID: "222222222222"},
+		{Name: "locked", Region: "us-west-2", AccountID: "333333333333"},
+	}
+
+	versions := DescribeClusterVersions(context.Background(), func(ctx context.Context, region, profile string) (services_aws.ClusterLister, error) {
+		return lister, nil
+	}, clusters)
+	require.Len(t, versions, 3)
+	assert.Equal(t, ClusterVersion{AccountID: "111111111111", Region: "us-west-2", Cluster: "api", Version: "1.30"}, versions[0])
+	assert.Equal(t, "1.27", versions[1].Version)
+	assert.Equal(t, "", versions[2].Version)
+	assert.Contains(t, versions[2].Error, "access denied")
+}
+
+func TestBuildVersionReport(t *testing.T) {
+	versions := []ClusterVersion{
+		{AccountID: "111111111111", Region: "us-west-2", Cluster: "api", Version: "1.30"},
+		{AccountID: "222222222222", Region: "eu-west-1", Cluster: "batch", Version: "1.27"},
+		{AccountID: "111111111111", Region: "us-west-2", Cluster: "web", Version: "1.29"},
+		{AccountID: "333333333333", Region: "us-west-2", Cluster: "locked", Error: "access denied"},
+	}
+
+	report := BuildVersionReport(versions, "", 2)
+	assert.Equal(t, "1.30", report.Reference)
+	assert.Equal(t, map[string]int{"1.30": 1, "1.29": 1, "1.27": 1}, report.Versions)
+	assert.Equal(t, 1, report.Outdated)
+
+	var names []string
+	for _, cluster := range report.Clusters {
+		names = append(names, cluster.Cluster)
+	}
+	// Oldest first, then by account, region and name
+	assert.Equal(t, []string{"batch", "web", "api", "locked"}, names)
+	assert.Equal(t, 3, report.Clusters[0].Behind)
+	assert.True(t, report.Clusters[0].Outdated)
+	assert.False(t, report.Clusters[1].Outdated)
+
+	// An explicit reference replaces the newest version found
+	report = BuildVersionReport(versions, "1.31", 1)
+	assert.Equal(t, "1.31", report.Reference)
+	assert.Equal(t, 2, report.Outdated)
+}
+
+func TestNewestVersion(t *testing.T) {
+	assert.Equal(t, "1.30", newestVersion([]ClusterVersion{{Version: "1.9"}, {Version: "1.30"}, {Version: "1.29"}, {Version: "bogus"}}))
+	assert.Equal(t, "", newestVersion([]ClusterVersion{{Error: "access denied"}}))
+}
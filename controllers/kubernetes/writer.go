@@ -0,0 +1,165 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	services_kubernetes "github.com/andresgarcia29/ark-cli/services/kubernetes"
+)
+
+// WriterKind identifies a KubeconfigWriter implementation
+type WriterKind string
+
+const (
+	// WriterAWSCLI shells out to `aws eks update-kubeconfig` for every cluster
+	WriterAWSCLI WriterKind = "aws-cli"
+	// WriterNative writes kubeconfig entries directly without the AWS CLI
+	WriterNative WriterKind = "native"
+)
+
+// KubeconfigWriter writes EKS cluster contexts into a kubeconfig
+// WriteCluster buffers changes and is safe for concurrent use; Flush writes them once all clusters are done
+type KubeconfigWriter interface {
+	Kind() WriterKind
+	WriteCluster(ctx context.Context, cluster services_aws.EKSCluster) error
+	Flush() error
+}
+
+// KubeconfigWriterOptions configures the writer created by NewKubeconfigWriter
+type KubeconfigWriterOptions struct {
+	KubeconfigPath string
+	ReplaceProfile string
+	// DiscoveredAt is recorded in the ark extension of every context written (default: when the writer is created)
+	DiscoveredAt time.Time
+	// AuthMode, ExecCommand and NewClusterLister only apply to the native writer
+	AuthMode    services_kubernetes.AuthMode
+	ExecCommand string
+	// NewClusterLister creates the client used to describe clusters (default: services_aws.NewClusterLister)
+	NewClusterLister services_aws.ClusterListerFactory
+}
+
+// ParseWriterKind validates a writer name coming from a flag or the ark config
+func ParseWriterKind(value string) (WriterKind, error) {
+	switch WriterKind(value) {
+	case WriterAWSCLI, WriterNative:
+		return WriterKind(value), nil
+	case "":
+		return WriterAWSCLI, nil
+	default:
+		return "", fmt.Errorf("unknown kubeconfig writer %q (expected %s or %s)", value, WriterAWSCLI, WriterNative)
+	}
+}
+
+// NewKubeconfigWriter creates the writer for the given kind
+func NewKubeconfigWriter(kind WriterKind, opts KubeconfigWriterOptions) (KubeconfigWriter, error) {
+	if opts.DiscoveredAt.IsZero() {
+		opts.DiscoveredAt = time.Now()
+	}
+	switch kind {
+	case WriterAWSCLI, "":
+		if opts.AuthMode == services_kubernetes.AuthModeStatic {
+			return nil, fmt.Errorf("static auth mode requires the %s writer", WriterNative)
+		}
+		return newAWSCLIWriter(opts)
+	case WriterNative:
+		return newNativeWriter(opts)
+	default:
+		return nil, fmt.Errorf("unknown kubeconfig writer %q", kind)
+	}
+}
+
+// awsCLIWriter matches the AWS CLI behavior exactly by delegating to it
+// Every cluster is written to its own fragment so the CLI can run in parallel;
+// Flush merges the fragments into the target kubeconfig with a single write, marking them as managed by ark
+type awsCLIWriter struct {
+	replaceProfile string
+	kubeconfigPath string
+	discoveredAt   time.Time
+	tempDir        string
+
+	mu        sync.Mutex
+	fragments map[string]services_aws.EKSCluster
+}
+
+func newAWSCLIWriter(opts KubeconfigWriterOptions) (*awsCLIWriter, error) {
+	tempDir, err := os.MkdirTemp("", "ark-kubeconfig-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+
+	return &awsCLIWriter{
+		replaceProfile: opts.ReplaceProfile,
+		kubeconfigPath: opts.KubeconfigPath,
+		discoveredAt:   opts.DiscoveredAt,
+		tempDir:        tempDir,
+		fragments:      make(map[string]services_aws.EKSCluster),
+	}, nil
+}
+
+func (w *awsCLIWriter) Kind() WriterKind {
+	return WriterAWSCLI
+}
+
+func (w *awsCLIWriter) WriteCluster(ctx context.Context, cluster services_aws.EKSCluster) error {
+	fragment := filepath.Join(w.tempDir, fmt.Sprintf("%s-%s-%s.yaml", cluster.AccountID, cluster.Region, cluster.Name))
+	if err := updateKubeconfigForCluster(cluster, w.replaceProfile, fragment); err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	w.fragments[fragment] = cluster
+	w.mu.Unlock()
+	return nil
+}
+
+// Flush merges every fragment written by the AWS CLI into the kubeconfig
+func (w *awsCLIWriter) Flush() error {
+	defer os.RemoveAll(w.tempDir)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.fragments) == 0 {
+		return nil
+	}
+
+	kubeconfig, err := services_kubernetes.LoadKubeconfig(w.kubeconfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+
+	// Sorted so the resulting file does not depend on completion order
+	paths := make([]string, 0, len(w.fragments))
+	for fragment := range w.fragments {
+		paths = append(paths, fragment)
+	}
+	sort.Strings(paths)
+	for _, fragment := range paths {
+		partial, err := services_kubernetes.LoadKubeconfig(fragment)
+		if err != nil {
+			return fmt.Errorf("failed to load kubeconfig fragment: %w", err)
+		}
+		cluster := w.fragments[fragment]
+		for _, entry := range partial.Contexts {
+			partial.MarkManaged(entry.Name, services_kubernetes.ArkMetadata{
+				AccountID:    cluster.AccountID,
+				Region:       cluster.Region,
+				Cluster:      cluster.Name,
+				DiscoveredAt: w.discoveredAt,
+			})
+		}
+		kubeconfig.Merge(partial)
+	}
+
+	if err := services_kubernetes.SaveKubeconfig(w.kubeconfigPath, kubeconfig); err != nil {
+		return fmt.Errorf("failed to save kubeconfig: %w", err)
+	}
+	w.fragments = nil
+	return nil
+}
@@ -0,0 +1,301 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andresgarcia29/ark-cli/lib"
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	services_kubernetes "github.com/andresgarcia29/ark-cli/services/kubernetes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWriterKind(t *testing.T) {
+	tests := []struct {
+		name          string
+		value         string
+		expected      WriterKind
+		expectedError bool
+	}{
+		{name: "empty defaults to aws-cli", value: "", expected: WriterAWSCLI},
+		{name: "aws-cli", value: "aws-cli", expected: WriterAWSCLI},
+		{name: "native", value: "native", expected: WriterNative},
+		{name: "unknown", value: "client-go", expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kind, err := ParseWriterKind(tt.value)
+			if tt.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, kind)
+		})
+	}
+}
+
+func TestNewKubeconfigWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+
+	writer, err := NewKubeconfigWriter(WriterAWSCLI, KubeconfigWriterOptions{KubeconfigPath: path})
+	require.NoError(t, err)
+	assert.Equal(t, WriterAWSCLI, writer.Kind())
+	require.NoError(t, writer.Flush())
+
+	writer, err = NewKubeconfigWriter(WriterNative, KubeconfigWriterOptions{KubeconfigPath: path})
+	require.NoError(t, err)
+	assert.Equal(t, WriterNative, writer.Kind())
+
+	_, err = NewKubeconfigWriter(WriterAWSCLI, KubeconfigWriterOptions{
+		KubeconfigPath: path,
+		AuthMode:       services_kubernetes.AuthModeStatic,
+	})
+	assert.Error(t, err)
+
+	_, err = NewKubeconfigWriter("unknown", KubeconfigWriterOptions{KubeconfigPath: path})
+	assert.Error(t, err)
+}
+
+func TestNativeWriterFlush(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+
+	writer, err := NewKubeconfigWriter(WriterNative, KubeconfigWriterOptions{
+		KubeconfigPath: path,
+		ReplaceProfile: "replaced",
+	})
+	require.NoError(t, err)
+
+	// Endpoint is preset so the writer does not need to describe the cluster
+	cluster := services_aws.EKSCluster{
+		Name:                     "test-cluster",
+		Region:                   "us-west-2",
+		Profile:                  "original",
+		ARN:                      "arn:aws:eks:us-west-2:123456789012:cluster/test-cluster",
+		Endpoint:                 "https://example.eks.amazonaws.com",
+		CertificateAuthorityData: "Y2E=",
+	}
+	require.NoError(t, writer.WriteCluster(context.Background(), cluster))
+
+	// Nothing is persisted until Flush
+	kubeconfig, err := services_kubernetes.LoadKubeconfig(path)
+	require.NoError(t, err)
+	assert.Empty(t, kubeconfig.Contexts)
+
+	require.NoError(t, writer.Flush())
+
+	kubeconfig, err = services_kubernetes.LoadKubeconfig(path)
+	require.NoError(t, err)
+	require.Len(t, kubeconfig.Contexts, 1)
+	require.Len(t, kubeconfig.Users, 1)
+	assert.Equal(t, "https://example.eks.amazonaws.com", kubeconfig.Clusters[0].Cluster.Server)
+	assert.Contains(t, kubeconfig.Users[0].User.Exec.Args, "replaced")
+}
+
+// fakeClusterLister describes clusters without calling EKS
+type fakeClusterLister struct {
+	endpoint  string
+	err       error
+	described []string
+}
+
+func (f *fakeClusterLister) ListClusters(ctx context.Context) ([]string, error) {
+	return nil, nil
+}
+
+func (f *fakeClusterLister) DescribeCluster(ctx context.Context, cluster *services_aws.EKSCluster) error {
+	f.described = append(f.described, cluster.Name)
+	if f.err != nil {
+		return f.err
+	}
+	cluster.Endpoint = f.endpoint
+	cluster.CertificateAuthorityData = "Y2E="
+	return nil
+}
+
+func TestNativeWriterDescribesWithClusterLister(t *testing.T) {
+	lister := &fakeClusterLister{endpoint: "https://described.eks.amazonaws.com"}
+	var gotRegion, gotProfile string
+
+	path := filepath.Join(t.TempDir(), "config")
+	writer, err := NewKubeconfigWriter(WriterNative, KubeconfigWriterOptions{
+		KubeconfigPath: path,
+		NewClusterLister: func(ctx context.Context, region, profile string) (services_aws.ClusterLister, error) {
+			gotRegion, gotProfile = region, profile
+			return lister, nil
+		},
+	})
+	require.NoError(t, err)
+
+	cluster := services_aws.EKSCluster{Name: "test-cluster", Region: "eu-west-1", Profile: "dev"}
+	require.NoError(t, writer.WriteCluster(context.Background(), cluster))
+	require.NoError(t, writer.Flush())
+
+	assert.Equal(t, []string{"test-cluster"}, lister.described)
+	assert.Equal(t, "eu-west-1", gotRegion)
+	assert.Equal(t, "dev", gotProfile)
+
+	kubeconfig, err := services_kubernetes.LoadKubeconfig(path)
+	require.NoError(t, err)
+	require.Len(t, kubeconfig.Clusters, 1)
+	assert.Equal(t, "https://described.eks.amazonaws.com", kubeconfig.Clusters[0].Cluster.Server)
+
+	lister.err = errors.New("access denied")
+	err = writer.WriteCluster(context.Background(), services_aws.EKSCluster{Name: "broken", Region: "eu-west-1"})
+	assert.ErrorContains(t, err, "access denied")
+}
+
+func TestAWSCLIWriterFlushMergesFragments(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+
+	existing := services_kubernetes.NewKubeconfig()
+	existing.UpsertContext(services_kubernetes.NamedContext{Name: "existing"})
+	require.NoError(t, services_kubernetes.SaveKubeconfig(path, existing))
+
+	discoveredAt := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	writer, err := newAWSCLIWriter(KubeconfigWriterOptions{KubeconfigPath: path, DiscoveredAt: discoveredAt})
+	require.NoError(t, err)
+
+	// Simulate the fragments `aws eks update-kubeconfig --kubeconfig` would leave behind
+	for _, name := range []string{"b", "a"} {
+		fragment := services_kubernetes.NewKubeconfig()
+		fragment.UpsertCluster(services_kubernetes.NamedCluster{Name: "cluster-" + name})
+		fragment.UpsertContext(services_kubernetes.NamedContext{Name: name, Context: services_kubernetes.KubeContext{Cluster: "cluster-" + name}})
+		fragment.CurrentContext = name
+		fragmentPath := filepath.Join(writer.tempDir, name+".yaml")
+		require.NoError(t, services_kubernetes.SaveKubeconfig(fragmentPath, fragment))
+		writer.fragments[fragmentPath] = services_aws.EKSCluster{Name: name, Region: "us-west-2", AccountID: "111111111111"}
+	}
+
+	require.NoError(t, writer.Flush())
+
+	kubeconfig, err := services_kubernetes.LoadKubeconfig(path)
+	require.NoError(t, err)
+	assert.Len(t, kubeconfig.Contexts, 3)
+	assert.Equal(t, "b", kubeconfig.CurrentContext, "fragments are merged in sorted order")
+	assert.NoDirExists(t, writer.tempDir)
+
+	metadata, ok := kubeconfig.FindContext("a").Context.Extensions.Ark()
+	require.True(t, ok, "written contexts are marked as managed by ark")
+	assert.Equal(t, services_kubernetes.ArkMetadata{AccountID: "111111111111", Region: "us-west-2", Cluster: "a", DiscoveredAt: discoveredAt}, metadata)
+	_, ok = kubeconfig.FindCluster("cluster-a").Cluster.Extensions.Ark()
+	assert.True(t, ok)
+	_, ok = kubeconfig.FindContext("existing").Context.Extensions.Ark()
+	assert.False(t, ok, "contexts ark didn't write are left unmarked")
+}
+
+// recordingWriter is a KubeconfigWriter that records the clusters it receives
+type recordingWriter struct {
+	mu       sync.Mutex
+	written  []string
+	attempts map[string]int
+	// failures maps a cluster name to the errors returned on its first attempts
+	failures map[string][]error
+}
+
+func (w *recordingWriter) Kind() WriterKind { return WriterNative }
+
+func (w *recordingWriter) WriteCluster(ctx context.Context, cluster services_aws.EKSCluster) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	attempt := w.attempts[cluster.Name]
+	w.attempts[cluster.Name]++
+	if attempt < len(w.failures[cluster.Name]) {
+		return w.failures[cluster.Name][attempt]
+	}
+	w.written = append(w.written, cluster.Name)
+	return nil
+}
+
+func (w *recordingWriter) Flush() error { return nil }
+
+func TestWriteClusters(t *testing.T) {
+	original := clusterRetryConfig
+	clusterRetryConfig.RetryDelay = time.Millisecond
+	defer func() { clusterRetryConfig = original }()
+
+	var clusters []services_aws.EKSCluster
+	for i := 0; i < 50; i++ {
+		clusters = append(clusters, services_aws.EKSCluster{Name: fmt.Sprintf("cluster-%d", i), Region: "us-west-2"})
+	}
+
+	transient := errors.New("connection reset by peer")
+	notFound := errors.New("An error occurred (ResourceNotFoundException) when calling the DescribeCluster operation: No cluster found")
+	writer := &recordingWriter{
+		attempts: map[string]int{},
+		failures: map[string][]error{
+			"cluster-3": {transient},
+			"cluster-5": {notFound},
+			"cluster-7": {transient, transient, transient, transient},
+		},
+	}
+
+	done := 0
+	results := writeClusters(context.Background(), writer, clusters, func(result ClusterResult) {
+		done++
+	})
+
+	assert.Equal(t, len(clusters), done)
+	require.Len(t, results, len(clusters))
+	assert.Len(t, writer.written, len(clusters)-2)
+
+	assert.Equal(t, ClusterConfigured, results[0].State)
+	assert.Equal(t, 1, results[0].Attempts)
+
+	assert.Equal(t, ClusterConfigured, results[3].State, "transient errors are retried")
+	assert.Equal(t, 2, results[3].Attempts)
+
+	assert.Equal(t, ClusterSkipped, results[5].State, "missing clusters are skipped without retrying")
+	assert.Equal(t, 1, results[5].Attempts)
+
+	assert.Equal(t, ClusterFailed, results[7].State)
+	assert.Equal(t, clusterRetryConfig.MaxRetries+1, results[7].Attempts)
+	assert.ErrorIs(t, results[7].Err, transient)
+
+	summary := summarizeClusterResults(results)
+	assert.Equal(t, len(clusters)-2, summary[ClusterConfigured])
+	assert.Equal(t, 1, summary[ClusterSkipped])
+	assert.Equal(t, 1, summary[ClusterFailed])
+}
+
+func TestIsSkippableClusterError(t *testing.T) {
+	assert.False(t, isSkippableClusterError(nil))
+	assert.False(t, isSkippableClusterError(errors.New("timeout")))
+	assert.True(t, isSkippableClusterError(errors.New("ResourceNotFoundException: No cluster found for name: test")))
+	assert.True(t, isSkippableClusterError(fmt.Errorf("wrapped: %w", errors.New("AccessDeniedException"))))
+}
+
+func TestBuildClusterReportTable(t *testing.T) {
+	table := buildClusterReportTable([]ClusterResult{
+		{Cluster: services_aws.EKSCluster{Name: "ok", AccountID: "1", Region: "us-west-2"}, State: ClusterConfigured, Attempts: 1},
+		{Cluster: services_aws.EKSCluster{Name: "gone"}, State: ClusterSkipped, Attempts: 1, Err: errors.New("describe failed\nnot found\n")},
+		{Cluster: services_aws.EKSCluster{Name: "denied"}, State: ClusterSkipped, Attempts: 1, Err: fmt.Errorf("failed to update kubeconfig: %w", &lib.CommandError{
+			Args:     []string{"aws", "eks", "update-kubeconfig", "--name", "denied"},
+			ExitCode: 254,
+			Stderr:   "\nAn error occurred (AccessDeniedException) when calling the DescribeCluster operation: denied\n",
+			Err:      errors.New("exit status 254"),
+		})},
+		{Cluster: services_aws.EKSCluster{Name: "silent"}, State: ClusterFailed, Attempts: 3, Err: &lib.CommandError{
+			Args: []string{"aws", "eks", "update-kubeconfig"}, ExitCode: 1, Err: errors.New("exit status 1"),
+		}},
+		{Cluster: services_aws.EKSCluster{Name: "missing"}, State: ClusterFailed, Attempts: 1, Err: &lib.CommandError{
+			Args: []string{"aws"}, ExitCode: -1, Err: errors.New(`exec: "aws": executable file not found in $PATH`),
+		}},
+	})
+
+	require.Len(t, table.Rows, 5)
+	assert.Equal(t, []string{"ok", "1", "us-west-2", "configured", "1", ""}, table.Rows[0])
+	assert.Equal(t, "not found", table.Rows[1][5], "the last line holds the cause")
+	assert.Equal(t, "An error occurred (AccessDeniedException) when calling the DescribeCluster operation: denied", table.Rows[2][5],
+		"a failed command shows its stderr, not its command line")
+	assert.Equal(t, "exited with status 1", table.Rows[3][5])
+	assert.Equal(t, `exec: "aws": executable file not found in $PATH`, table.Rows[4][5])
+}
@@ -0,0 +1,3 @@
+// Package e2e holds end-to-end tests that run ark's login and kubeconfig flows against internal/fakeaws
+// They only build with the e2e tag: go test -tags e2e ./e2e/...
+package e2e
@@ -0,0 +1,49 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	controllers_k8s "github.com/andresgarcia29/ark-cli/controllers/kubernetes"
+	"github.com/andresgarcia29/ark-cli/internal/fakeaws"
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	services_kubernetes "github.com/andresgarcia29/ark-cli/services/kubernetes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKubeconfigSetupWithNativeWriter(t *testing.T) {
+	bootstrapSSO(t)
+	ctx := context.Background()
+
+	clusters, err := services_aws.GetClustersFromAllAccounts(ctx, []string{region}, []string{"readonly"}, "")
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(clusters))
+	for _, cluster := range clusters {
+		names = append(names, cluster.Name)
+	}
+	sort.Strings(names)
+	assert.Equal(t, []string{"dev-main", "prod-main"}, names, "only clusters of the scanned region are found")
+
+	kubeconfigPath := filepath.Join(t.TempDir(), "config")
+	writer, err := controllers_k8s.NewKubeconfigWriter(controllers_k8s.WriterNative, controllers_k8s.KubeconfigWriterOptions{
+		KubeconfigPath: kubeconfigPath,
+		AuthMode:       services_kubernetes.AuthModeExec,
+	})
+	require.NoError(t, err)
+	require.NoError(t, controllers_k8s.UpdateKubeconfigWithWriter(ctx, writer, clusters))
+
+	kubeconfig, err := services_kubernetes.LoadKubeconfig(kubeconfigPath)
+	require.NoError(t, err)
+	require.Len(t, kubeconfig.Clusters, 2)
+
+	prod := kubeconfig.FindCluster(fakeaws.ClusterARN(fakeaws.Cluster{AccountID: "222222222222", Region: region, Name: "prod-main"}))
+	require.NotNil(t, prod)
+	assert.Equal(t, "https://prod-main.us-west-2.eks.fake", prod.Cluster.Server)
+	assert.Equal(t, 2, server.Calls("eks:DescribeCluster"))
+}
@@ -0,0 +1,55 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/andresgarcia29/ark-cli/internal/fakeaws"
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+)
+
+const (
+	startURL = "https://fake-org.awsapps.com/start"
+	region   = "us-west-2"
+)
+
+var server *fakeaws.Server
+
+// TestMain isolates the tests from the real AWS configuration and starts the fake server
+func TestMain(m *testing.M) {
+	home, err := os.MkdirTemp("", "ark-e2e-")
+	if err != nil {
+		panic(err)
+	}
+
+	os.Setenv("HOME", home)
+	// The SDK resolves its default file paths at init time, before HOME changes
+	os.Setenv("AWS_CONFIG_FILE", filepath.Join(home, ".aws", "config"))
+	os.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(home, ".aws", "credentials"))
+	os.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+	for _, name := range []string{"AWS_PROFILE", "AWS_REGION", "AWS_DEFAULT_REGION", "AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN", "ARK_CONFIG"} {
+		os.Unsetenv(name)
+	}
+
+	server = fakeaws.New(
+		[]fakeaws.Account{
+			{ID: "111111111111", Name: "dev", Email: "dev@example.com", Roles: []string{"AdministratorAccess", "ReadOnlyAccess"}},
+			{ID: "222222222222", Name: "prod", Email: "prod@example.com", Roles: []string{"ReadOnlyAccess"}},
+		},
+		[]fakeaws.Cluster{
+			{AccountID: "111111111111", Region: region, Name: "dev-main", Version: "1.31"},
+			{AccountID: "222222222222", Region: region, Name: "prod-main", Version: "1.30"},
+			{AccountID: "222222222222", Region: "eu-west-1", Name: "prod-eu", Version: "1.30"},
+		},
+	)
+	services_aws.SetTestEndpoint(server.URL)
+
+	code := m.Run()
+
+	server.Close()
+	os.RemoveAll(home)
+	os.Exit(code)
+}
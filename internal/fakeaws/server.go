@@ -0,0 +1,377 @@
+// Package fakeaws is a local stand-in for the AWS APIs ark uses (SSO OIDC, SSO, STS and EKS)
+// It lets end-to-end tests run the real login and kubeconfig flows without an AWS account
+package fakeaws
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// AccessToken is the SSO access token issued by the device flow
+	AccessToken = "fake-sso-access-token"
+	// accessKeyPrefix prefixes the account ID in issued access keys, so later signed calls reveal their account
+	accessKeyPrefix = "ASIAFAKE"
+)
+
+// Account is an SSO account with the roles the user can assume in it
+type Account struct {
+	ID    string
+	Name  string
+	Email string
+	Roles []string
+}
+
+// Cluster is an EKS cluster living in an account and region
+type Cluster struct {
+	AccountID string
+	Region    string
+	Name      string
+	Version   string
+}
+
+// Server serves the fake APIs on a single endpoint
+// Services are told apart by path (EKS, SSO, SSO OIDC) and by the form Action (STS)
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	accounts []Account
+	clusters []Cluster
+	calls    map[string]int
+}
+
+// credentialScope extracts the access key and region from a SigV4 Authorization header
+var credentialScope = regexp.MustCompile(`Credential=([^/]+)/\d{8}/([^/]+)/`)
+
+// New starts a fake AWS server with the given accounts and clusters
+func New(accounts []Account, clusters []Cluster) *Server {
+	s := &Server{
+		accounts: accounts,
+		clusters: clusters,
+		calls:    map[string]int{},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// Calls returns how many times an operation (e.g. "sso:GetRoleCredentials") was called
+func (s *Server) Calls(operation string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[operation]
+}
+
+func (s *Server) record(operation string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls[operation]++
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Path
+	switch {
+	case r.Method == http.MethodPost && path == "/client/register":
+		s.registerClient(w)
+	case r.Method == http.MethodPost && path == "/device_authorization":
+		s.startDeviceAuthorization(w)
+	case r.Method == http.MethodPost && path == "/token":
+		s.createToken(w)
+	case r.Method == http.MethodGet && path == "/assignment/accounts":
+		s.listAccounts(w, r)
+	case r.Method == http.MethodGet && path == "/assignment/roles":
+		s.listAccountRoles(w, r)
+	case r.Method == http.MethodGet && path == "/federation/credentials":
+		s.getRoleCredentials(w, r)
+	case r.Method == http.MethodGet && path == "/clusters":
+		s.listClusters(w, r)
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "/clusters/"):
+		s.describeCluster(w, r, strings.TrimPrefix(path, "/clusters/"))
+	case r.Method == http.MethodPost && path == "/":
+		s.sts(w, r)
+	default:
+		http.Error(w, fmt.Sprintf("fakeaws: unsupported request %s %s", r.Method, path), http.StatusNotImplemented)
+	}
+}
+
+// SSO OIDC
+
+func (s *Server) registerClient(w http.ResponseWriter) {
+	s.record("sso-oidc:RegisterClient")
+	writeJSON(w, http.StatusOK, map[string]any{
+		"clientId":              "fake-client-id",
+		"clientSecret":          "fake-client-secret",
+		"clientIdIssuedAt":      time.Now().Unix(),
+		"clientSecretExpiresAt": time.Now().Add(24 * time.Hour).Unix(),
+	})
+}
+
+func (s *Server) startDeviceAuthorization(w http.ResponseWriter) {
+	s.record("sso-oidc:StartDeviceAuthorization")
+	writeJSON(w, http.StatusOK, map[string]any{
+		"deviceCode":              "fake-device-code",
+		"userCode":                "FAKE-CODE",
+		"verificationUri":         "https://device.sso.fake/",
+		"verificationUriComplete": "https://device.sso.fake/?user_code=FAKE-CODE",
+		"expiresIn":               600,
+		"interval":                1,
+	})
+}
+
+// createToken approves the device authorization right away
+func (s *Server) createToken(w http.ResponseWriter) {
+	s.record("sso-oidc:CreateToken")
+	writeJSON(w, http.StatusOK, map[string]any{
+		"accessToken": AccessToken,
+		"tokenType":   "Bearer",
+		"expiresIn":   3600,
+	})
+}
+
+// SSO portal
+
+// authorized checks the SSO bearer token sent by the portal APIs
+func authorized(w http.ResponseWriter, r *http.Request) bool {
+	if r.Header.Get("x-amz-sso_bearer_token") != AccessToken {
+		writeError(w, http.StatusUnauthorized, "UnauthorizedException", "Session token not found or invalid")
+		return false
+	}
+	return true
+}
+
+func (s *Server) listAccounts(w http.ResponseWriter, r *http.Request) {
+	s.record("sso:ListAccounts")
+	if !authorized(w, r) {
+		return
+	}
+
+	accounts := make([]map[string]string, 0, len(s.accounts))
+	for _, account := range s.accounts {
+		accounts = append(accounts, map[string]string{
+			"accountId":    account.ID,
+			"accountName":  account.Name,
+			"emailAddress": account.Email,
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"accountList": accounts})
+}
+
+func (s *Server) listAccountRoles(w http.ResponseWriter, r *http.Request) {
+	s.record("sso:ListAccountRoles")
+	if !authorized(w, r) {
+		return
+	}
+
+	accountID := r.URL.Query().Get("account_id")
+	roles := []map[string]string{}
+	if account := s.account(accountID); account != nil {
+		for _, role := range account.Roles {
+			roles = append(roles, map[string]string{"roleName": role, "accountId": accountID})
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"roleList": roles})
+}
+
+func (s *Server) getRoleCredentials(w http.ResponseWriter, r *http.Request) {
+	s.record("sso:GetRoleCredentials")
+	if !authorized(w, r) {
+		return
+	}
+
+	accountID, roleName := r.URL.Query().Get("account_id"), r.URL.Query().Get("role_name")
+	account := s.account(accountID)
+	if account == nil || !contains(account.Roles, roleName) {
+		writeError(w, http.StatusForbidden, "ForbiddenException", "No access")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"roleCredentials": map[string]any{
+			"accessKeyId":     accessKeyPrefix + accountID,
+			"secretAccessKey": "fake-secret",
+			"sessionToken":    "fake-session-token",
+			"expiration":      time.Now().Add(time.Hour).UnixMilli(),
+		},
+	})
+}
+
+func (s *Server) account(id string) *Account {
+	for i := range s.accounts {
+		if s.accounts[i].ID == id {
+			return &s.accounts[i]
+		}
+	}
+	return nil
+}
+
+// EKS
+
+// signer returns the account and region of a SigV4-signed request
+func signer(r *http.Request) (accountID, region string) {
+	match := credentialScope.FindStringSubmatch(r.Header.Get("Authorization"))
+	if match == nil {
+		return "", ""
+	}
+	return strings.TrimPrefix(match[1], accessKeyPrefix), match[2]
+}
+
+func (s *Server) listClusters(w http.ResponseWriter, r *http.Request) {
+	s.record("eks:ListClusters")
+	accountID, region := signer(r)
+
+	names := []string{}
+	for _, cluster := range s.clusters {
+		if cluster.AccountID == accountID && cluster.Region == region {
+			names = append(names, cluster.Name)
+		}
+	}
+	sort.Strings(names)
+	writeJSON(w, http.StatusOK, map[string]any{"clusters": names})
+}
+
+func (s *Server) describeCluster(w http.ResponseWriter, r *http.Request, name string) {
+	s.record("eks:DescribeCluster")
+	accountID, region := signer(r)
+
+	for _, cluster := range s.clusters {
+		if cluster.AccountID != accountID || cluster.Region != region || cluster.Name != name {
+			continue
+		}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"cluster": map[string]any{
+				"name":     cluster.Name,
+				"arn":      ClusterARN(cluster),
+				"endpoint": fmt.Sprintf("https://%s.%s.eks.fake", cluster.Name, region),
+				"version":  cluster.Version,
+				"status":   "ACTIVE",
+				"certificateAuthority": map[string]string{
+					"data": "ZmFrZS1jYQ==",
+				},
+			},
+		})
+		return
+	}
+	writeError(w, http.StatusNotFound, "ResourceNotFoundException", fmt.Sprintf("No cluster found for name: %s.", name))
+}
+
+// ClusterARN returns the ARN the fake server reports for a cluster
+func ClusterARN(cluster Cluster) string {
+	return fmt.Sprintf("arn:aws:eks:%s:%s:cluster/%s", cluster.Region, cluster.AccountID, cluster.Name)
+}
+
+// STS
+
+type stsCredentials struct {
+	AccessKeyID     string `xml:"AccessKeyId"`
+	SecretAccessKey string `xml:"SecretAccessKey"`
+	SessionToken    string `xml:"SessionToken"`
+	Expiration      string `xml:"Expiration"`
+}
+
+type assumedRoleUser struct {
+	Arn           string `xml:"Arn"`
+	AssumedRoleID string `xml:"AssumedRoleId"`
+}
+
+type assumeRoleResult struct {
+	Credentials     stsCredentials  `xml:"Credentials"`
+	AssumedRoleUser assumedRoleUser `xml:"AssumedRoleUser"`
+}
+
+type callerIdentityResult struct {
+	Arn     string `xml:"Arn"`
+	UserID  string `xml:"UserId"`
+	Account string `xml:"Account"`
+}
+
+func (s *Server) sts(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	action := r.Form.Get("Action")
+	s.record("sts:" + action)
+
+	switch action {
+	case "AssumeRole", "AssumeRoleWithWebIdentity":
+		roleARN, sessionName := r.Form.Get("RoleArn"), r.Form.Get("RoleSessionName")
+		accountID := accountFromARN(roleARN)
+		writeSTS(w, action, assumeRoleResult{
+			Credentials: stsCredentials{
+				AccessKeyID:     accessKeyPrefix + accountID,
+				SecretAccessKey: "fake-secret",
+				SessionToken:    "fake-session-token",
+				Expiration:      time.Now().Add(time.Hour).UTC().Format(time.RFC3339),
+			},
+			AssumedRoleUser: assumedRoleUser{
+				Arn:           strings.Replace(roleARN, ":iam::", ":sts::", 1) + "/" + sessionName,
+				AssumedRoleID: "AROAFAKE:" + sessionName,
+			},
+		})
+	case "GetCallerIdentity":
+		accountID, _ := signer(r)
+		writeSTS(w, action, callerIdentityResult{
+			Arn:     fmt.Sprintf("arn:aws:sts::%s:assumed-role/fake/ark", accountID),
+			UserID:  "AROAFAKE:ark",
+			Account: accountID,
+		})
+	default:
+		http.Error(w, fmt.Sprintf("fakeaws: unsupported STS action %q", action), http.StatusNotImplemented)
+	}
+}
+
+// accountFromARN returns the account ID field of an ARN
+func accountFromARN(arn string) string {
+	parts := strings.Split(arn, ":")
+	if len(parts) < 5 {
+		return ""
+	}
+	return parts[4]
+}
+
+// writeSTS writes an awsquery response envelope: <ActionResponse><ActionResult>...</ActionResult></ActionResponse>
+func writeSTS(w http.ResponseWriter, action string, result any) {
+	body, err := xml.Marshal(result)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	// xml.Marshal names the root after the Go type; rename it to <ActionResult>
+	inner := body[strings.Index(string(body), ">")+1 : strings.LastIndex(string(body), "<")]
+
+	w.Header().Set("Content-Type", "text/xml")
+	fmt.Fprintf(w, `<%sResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/"><%sResult>%s</%sResult><ResponseMetadata><RequestId>fake</RequestId></ResponseMetadata></%sResponse>`,
+		action, action, inner, action, action)
+}
+
+// Helpers
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+// writeError writes a restJson error the SDK maps to a typed exception
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("x-amzn-errortype", code)
+	writeJSON(w, status, map[string]string{"message": message})
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package animation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/andresgarcia29/ark-cli/lib"
+	services_kubernetes "github.com/andresgarcia29/ark-cli/services/kubernetes"
+)
+
+// clusterQuery is a parsed selector search: field filters plus free text matched against the context name
+// e.g. "region:us-east-1 account:prod api" keeps the contexts of us-east-1, in an account whose ID or
+// profile contains "prod", with "api" in their name
+type clusterQuery struct {
+	name    string
+	region  string
+	account string
+}
+
+// parseClusterQuery splits the region: and account: filters from the free text of a search
+func parseClusterQuery(query string) clusterQuery {
+	var parsed clusterQuery
+	var words []string
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		switch {
+		case strings.HasPrefix(word, "region:"):
+			parsed.region = strings.TrimPrefix(word, "region:")
+		case strings.HasPrefix(word, "account:"):
+			parsed.account = strings.TrimPrefix(word, "account:")
+		default:
+			words = append(words, word)
+		}
+	}
+	parsed.name = strings.Join(words, " ")
+	return parsed
+}
+
+// match reports whether a cluster passes the filters, and whether its name contains the text rather
+// than only matching it fuzzily, so closer matches can be listed first
+func (q clusterQuery) match(cluster services_kubernetes.ClusterContext) (matched, contains bool) {
+	if q.region != "" && !strings.Contains(strings.ToLower(cluster.Region), q.region) {
+		return false, false
+	}
+	if q.account != "" &&
+		!strings.Contains(strings.ToLower(cluster.AccountID), q.account) &&
+		!strings.Contains(strings.ToLower(cluster.Profile), q.account) {
+		return false, false
+	}
+
+	name := strings.ToLower(cluster.Name)
+	if strings.Contains(name, q.name) {
+		return true, true
+	}
+	return lib.FuzzyMatch(q.name, name), false
+}
+
+// clusterGroup identifies the account and region a cluster is listed under in the grouped view
+type clusterGroup struct {
+	accountID string
+	region    string
+}
+
+func groupOf(cluster services_kubernetes.ClusterContext) clusterGroup {
+	return clusterGroup{accountID: cluster.AccountID, region: cluster.Region}
+}
+
+// String names the group in its header
+func (g clusterGroup) String() string {
+	if g.accountID == "" && g.region == "" {
+		return "Other contexts"
+	}
+	accountID, region := g.accountID, g.region
+	if accountID == "" {
+		accountID = "unknown account"
+	}
+	if region == "" {
+		region = "unknown region"
+	}
+	return fmt.Sprintf("%s · %s", accountID, region)
+}
+
+// sortByGroup orders clusters by account and region, keeping the order within each group
+// Contexts without any AWS details go last
+func sortByGroup(clusters []services_kubernetes.ClusterContext) {
+	sort.SliceStable(clusters, func(i, j int) bool {
+		a, b := groupOf(clusters[i]), groupOf(clusters[j])
+		aOther, bOther := a == clusterGroup{}, b == clusterGroup{}
+		if aOther != bOther {
+			return bOther
+		}
+		if a.accountID != b.accountID {
+			return a.accountID < b.accountID
+		}
+		return a.region < b.region
+	})
+}
+
+// groupCounts returns how many clusters each group holds
+func groupCounts(clusters []services_kubernetes.ClusterContext) map[clusterGroup]int {
+	counts := make(map[clusterGroup]int)
+	for _, cluster := range clusters {
+		counts[groupOf(cluster)]++
+	}
+	return counts
+}
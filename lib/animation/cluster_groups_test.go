@@ -0,0 +1,86 @@
+package animation
+
+import (
+	"testing"
+
+	services_kubernetes "github.com/andresgarcia29/ark-cli/services/kubernetes"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func groupTestClusters() []services_kubernetes.ClusterContext {
+	return []services_kubernetes.ClusterContext{
+		{Name: "kind"},
+		{Name: "prod-api", AccountID: "222222222222", Region: "us-east-1", Profile: "prod-readonly"},
+		{Name: "dev-api", AccountID: "111111111111", Region: "us-west-2", Profile: "dev-readonly"},
+		{Name: "prod-batch", AccountID: "222222222222", Region: "us-west-2", Profile: "prod-readonly"},
+		{Name: "dev-web", AccountID: "111111111111", Region: "us-west-2", Profile: "dev-readonly"},
+	}
+}
+
+func clusterNames(clusters []services_kubernetes.ClusterContext) []string {
+	names := make([]string, 0, len(clusters))
+	for _, cluster := range clusters {
+		names = append(names, cluster.Name)
+	}
+	return names
+}
+
+func TestParseClusterQuery(t *testing.T) {
+	assert.Equal(t, clusterQuery{name: "api", region: "us-east-1", account: "prod"}, parseClusterQuery("region:us-east-1 API account:prod"))
+	assert.Equal(t, clusterQuery{name: "prod web"}, parseClusterQuery("  prod   web "))
+	assert.Equal(t, clusterQuery{}, parseClusterQuery(""))
+}
+
+func TestClusterSelectorFilters(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{query: "region:us-east-1", expected: []string{"prod-api"}},
+		{query: "account:prod", expected: []string{"prod-api", "prod-batch"}},
+		{query: "account:1111 web", expected: []string{"dev-web"}},
+		{query: "region:us-west-2 api", expected: []string{"dev-api"}},
+		{query: "api", expected: []string{"prod-api", "dev-api"}},
+		{query: "region:eu", expected: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			model := initialClusterSelectorModel(groupTestClusters())
+			model.searchQuery = tt.query
+			model.filterClusters()
+			assert.Equal(t, tt.expected, clusterNames(model.filteredClusters))
+		})
+	}
+}
+
+func TestClusterSelectorGroupedView(t *testing.T) {
+	clusters := groupTestClusters()
+	model := initialClusterSelectorModel(clusters)
+
+	updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyCtrlG})
+	model = updated.(clusterSelectorModel)
+	require.True(t, model.grouped)
+	assert.Equal(t, []string{"dev-api", "dev-web", "prod-api", "prod-batch", "kind"}, clusterNames(model.filteredClusters))
+	assert.Equal(t, "kind", clusters[0].Name, "the original list is not reordered")
+
+	view := model.View()
+	assert.Contains(t, view, "▸ 111111111111 · us-west-2 (2)")
+	assert.Contains(t, view, "▸ 222222222222 · us-east-1 (1)")
+	assert.Contains(t, view, "▸ Other contexts (1)")
+
+	// Counts follow the filter
+	model.searchQuery = "account:1111 web"
+	model.filterClusters()
+	assert.Contains(t, model.View(), "▸ 111111111111 · us-west-2 (1)")
+
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyCtrlG})
+	assert.NotContains(t, updated.(clusterSelectorModel).View(), "▸")
+}
+
+func TestClusterGroupString(t *testing.T) {
+	assert.Equal(t, "Other contexts", clusterGroup{}.String())
+	assert.Equal(t, "unknown account · us-west-2", clusterGroup{region: "us-west-2"}.String())
+}
@@ -1,8 +1,10 @@
 package animation
 
 import (
+	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	services_kubernetes "github.com/andresgarcia29/ark-cli/services/kubernetes"
 	tea "github.com/charmbracelet/bubbletea"
@@ -30,6 +32,25 @@ type clusterSelectorModel struct {
 	selected         *services_kubernetes.ClusterContext
 	quitting         bool
 	searchMode       bool
+	grouped          bool // List clusters under account/region headers
+
+	// probe checks whether a context answers (nil disables probing); probed holds the results by
+	// context name as they arrive, a nil error meaning reachable
+	probe  func(name string) error
+	probed map[string]error
+}
+
+const (
+	// clusterProbeTimeout bounds each reachability probe so dead clusters are reported quickly
+	clusterProbeTimeout = 5 * time.Second
+	// maxConcurrentProbes limits the kubectl processes running at once on large kubeconfigs
+	maxConcurrentProbes = 8
+)
+
+// clusterProbeMsg carries the result of probing one context
+type clusterProbeMsg struct {
+	name string
+	err  error
 }
 
 // initialClusterSelectorModel creates the initial model for the selector
@@ -42,23 +63,54 @@ func initialClusterSelectorModel(clusters []services_kubernetes.ClusterContext)
 		visibleLines:     10, // Show maximum 10 clusters
 		searchQuery:      "",
 		searchMode:       true, // Start in search mode
+		probed:           make(map[string]error),
 	}
 }
 
 // Init implements the tea.Model Init method
+// Contexts are probed in the background once the list is shown
 func (m clusterSelectorModel) Init() tea.Cmd {
-	return nil
+	return m.probeClusters()
+}
+
+// probeClusters starts one probe per context, running a few at a time
+func (m clusterSelectorModel) probeClusters() tea.Cmd {
+	if m.probe == nil {
+		return nil
+	}
+
+	slots := make(chan struct{}, maxConcurrentProbes)
+	cmds := make([]tea.Cmd, 0, len(m.clusters))
+	for _, cluster := range m.clusters {
+		name := cluster.Name
+		cmds = append(cmds, func() tea.Msg {
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			return clusterProbeMsg{name: name, err: m.probe(name)}
+		})
+	}
+	return tea.Batch(cmds...)
 }
 
 // Update implements the tea.Model Update method
 func (m clusterSelectorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case clusterProbeMsg:
+		m.probed[msg.name] = msg.err
+		return m, nil
+
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "ctrl+c", "q":
 			m.quitting = true
 			return m, tea.Quit
 
+		case "ctrl+g":
+			// Toggle the view grouped by account and region
+			m.grouped = !m.grouped
+			m.filterClusters()
+			return m, nil
+
 		case "/":
 			// Activate search mode
 			m.searchMode = true
@@ -70,9 +122,7 @@ func (m clusterSelectorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				// Exit search mode
 				m.searchMode = false
 				m.searchQuery = ""
-				m.filteredClusters = m.clusters
-				m.cursor = 0
-				m.offset = 0
+				m.filterClusters()
 			} else {
 				m.quitting = true
 			}
@@ -83,9 +133,7 @@ func (m clusterSelectorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.searchMode {
 				m.searchMode = false
 				m.searchQuery = ""
-				m.filteredClusters = m.clusters
-				m.cursor = 0
-				m.offset = 0
+				m.filterClusters()
 			} else {
 				m.searchMode = true
 				m.searchQuery = ""
@@ -150,24 +198,26 @@ func (m clusterSelectorModel) getCurrentVisibleLines() int {
 	return min(m.visibleLines, len(m.filteredClusters))
 }
 
-// filterClusters filters clusters based on the search query
+// filterClusters filters clusters based on the search query, with region: and account: filters
+// Names containing the query come first, then fuzzy matches; the grouped view then sorts by group
 func (m *clusterSelectorModel) filterClusters() {
-	if m.searchQuery == "" {
-		m.filteredClusters = m.clusters
-		return
-	}
-
-	filtered := make([]services_kubernetes.ClusterContext, 0)
-	query := strings.ToLower(m.searchQuery)
+	query := parseClusterQuery(m.searchQuery)
 
+	var contains, fuzzy []services_kubernetes.ClusterContext
 	for _, cluster := range m.clusters {
-		// Search by cluster name
-		if strings.Contains(strings.ToLower(cluster.Name), query) {
-			filtered = append(filtered, cluster)
+		matched, exact := query.match(cluster)
+		switch {
+		case exact:
+			contains = append(contains, cluster)
+		case matched:
+			fuzzy = append(fuzzy, cluster)
 		}
 	}
 
-	m.filteredClusters = filtered
+	m.filteredClusters = append(contains, fuzzy...)
+	if m.grouped {
+		sortByGroup(m.filteredClusters)
+	}
 	// Reset cursor and offset when filtered clusters change
 	m.cursor = 0
 	m.offset = 0
@@ -189,6 +239,15 @@ func (m clusterSelectorModel) View() string {
 	s.WriteString(headerStyle.Render("🔍 Select a Kubernetes cluster context:"))
 	s.WriteString("\n\n")
 
+	// Current context, always visible even when filtered out or scrolled away
+	if current := m.currentContext(); current != "" {
+		currentStyle := lipgloss.NewStyle().
+			Foreground(lipgloss.Color("86")).
+			Bold(true)
+		s.WriteString(currentStyle.Render(fmt.Sprintf("%s Current context: %s", currentContextMarker, current)))
+		s.WriteString("\n\n")
+	}
+
 	// Search bar
 	if m.searchMode {
 		searchStyle := lipgloss.NewStyle().
@@ -211,9 +270,9 @@ func (m clusterSelectorModel) View() string {
 
 	var instructions string
 	if m.searchMode {
-		instructions = "Type to search • Enter to select • Tab to view all • Esc to quit"
+		instructions = "Type to search (region:, account: filter) • Enter to select • Tab to view all • Ctrl+G to group • Esc to quit"
 	} else {
-		instructions = "↑/↓ to navigate • / to search • Enter to select • q/esc to quit"
+		instructions = "↑/↓ to navigate • / to search • Enter to select • Ctrl+G to group • q/esc to quit"
 	}
 
 	s.WriteString(instructionsStyle.Render(instructions))
@@ -260,8 +319,21 @@ func (m clusterSelectorModel) View() string {
 	}
 
 	// Render clusters in the visible window
+	var counts map[clusterGroup]int
+	if m.grouped {
+		counts = groupCounts(m.filteredClusters)
+	}
+	groupStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true)
+
 	for i := startDisplay; i < endDisplay; i++ {
 		cluster := m.filteredClusters[i]
+
+		// Group header before the first cluster of each group, and at the top of the window
+		if m.grouped && (i == startDisplay || groupOf(m.filteredClusters[i-1]) != groupOf(cluster)) {
+			group := groupOf(cluster)
+			s.WriteString(groupStyle.Render(fmt.Sprintf("▸ %s (%d)", group, counts[group])))
+			s.WriteString("\n")
+		}
 		cursor := " "
 		if m.cursor == i {
 			cursor = ">"
@@ -305,8 +377,9 @@ func (m clusterSelectorModel) View() string {
 			description += fmt.Sprintf("Cluster: %s", displayInfo.ClusterName)
 		}
 
-		line := fmt.Sprintf("%s %s %s",
+		line := fmt.Sprintf("%s %s%s %s",
 			cursor,
+			m.probeMarker(cluster.Name),
 			nameStyle.Render(displayInfo.Name),
 			statusStyle.Render(displayInfo.Status),
 		)
@@ -314,6 +387,9 @@ func (m clusterSelectorModel) View() string {
 		if description != "" {
 			line += fmt.Sprintf(" - %s", lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render(description))
 		}
+		if err := m.probed[cluster.Name]; err != nil {
+			line += fmt.Sprintf(" %s", lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render(truncateProbeError(err)))
+		}
 
 		s.WriteString(line)
 		s.WriteString("\n")
@@ -331,11 +407,51 @@ func (m clusterSelectorModel) View() string {
 	return s.String()
 }
 
+// probeMarker shows whether a context answered its probe: ✓ reachable, ✗ failing, … still checking
+// Nothing is shown when probing is disabled
+func (m clusterSelectorModel) probeMarker(name string) string {
+	if m.probe == nil {
+		return ""
+	}
+	err, done := m.probed[name]
+	switch {
+	case !done:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render("…") + " "
+	case err != nil:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render("✗") + " "
+	default:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("46")).Render("✓") + " "
+	}
+}
+
+// truncateProbeError keeps probe failures short enough to fit next to the context
+func truncateProbeError(err error) string {
+	const maxLength = 60
+	message := err.Error()
+	if len([]rune(message)) > maxLength {
+		message = string([]rune(message)[:maxLength-1]) + "…"
+	}
+	return message
+}
+
+// currentContextMarker flags the current context in cluster selectors
+const currentContextMarker = "●"
+
+// currentContext returns the name of the current context, or "" when none is set
+func (m clusterSelectorModel) currentContext() string {
+	for _, cluster := range m.clusters {
+		if cluster.Current {
+			return cluster.Name
+		}
+	}
+	return ""
+}
+
 // formatClusterDisplay formats the cluster information for display
 func formatClusterDisplay(cluster services_kubernetes.ClusterContext) ClusterDisplayInfo {
 	status := ""
 	if cluster.Current {
-		status = "(current)"
+		status = currentContextMarker + " (current)"
 	}
 
 	return ClusterDisplayInfo{
@@ -356,12 +472,26 @@ func InteractiveClusterSelector() (*services_kubernetes.ClusterContext, error) {
 		return nil, fmt.Errorf("failed to get cluster contexts: %w", err)
 	}
 
+	return SelectCluster(clusters, "")
+}
+
+// SelectCluster shows the cluster selector over the given contexts, with the search pre-filled by query
+func SelectCluster(clusters []services_kubernetes.ClusterContext, query string) (*services_kubernetes.ClusterContext, error) {
 	if len(clusters) == 0 {
 		return nil, fmt.Errorf("no cluster contexts found in kubeconfig")
 	}
 
+	// Probes stop as soon as the selector closes
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
 	// Create and run the Bubble Tea program
 	model := initialClusterSelectorModel(clusters)
+	model.searchQuery = query
+	model.filterClusters()
+	model.probe = func(name string) error {
+		return services_kubernetes.ProbeContext(ctx, name, clusterProbeTimeout)
+	}
 	program := tea.NewProgram(model)
 
 	finalModel, err := program.Run()
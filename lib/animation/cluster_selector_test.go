@@ -0,0 +1,72 @@
+package animation
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	services_kubernetes "github.com/andresgarcia29/ark-cli/services/kubernetes"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClusterSelectorShowsCurrentContext(t *testing.T) {
+	model := initialClusterSelectorModel([]services_kubernetes.ClusterContext{
+		{Name: "dev"},
+		{Name: "prod", Current: true},
+	})
+	assert.Equal(t, "prod", model.currentContext())
+
+	// The current context stays visible when the search filters it out
+	model.searchQuery = "dev"
+	model.filterClusters()
+	view := model.View()
+	assert.Contains(t, view, "Current context: prod")
+	assert.NotContains(t, view, "(current)")
+
+	model = initialClusterSelectorModel([]services_kubernetes.ClusterContext{{Name: "dev"}})
+	assert.NotContains(t, model.View(), "Current context")
+}
+
+func TestFormatClusterDisplay(t *testing.T) {
+	assert.Equal(t, currentContextMarker+" (current)", formatClusterDisplay(services_kubernetes.ClusterContext{Name: "prod", Current: true}).Status)
+	assert.Empty(t, formatClusterDisplay(services_kubernetes.ClusterContext{Name: "dev"}).Status)
+}
+
+func TestClusterSelectorProbesContexts(t *testing.T) {
+	clusters := []services_kubernetes.ClusterContext{{Name: "dev"}, {Name: "prod"}}
+
+	// Probing is off unless a probe is set
+	model := initialClusterSelectorModel(clusters)
+	assert.Nil(t, model.Init())
+	assert.NotContains(t, model.View(), "…")
+
+	model.probe = func(name string) error {
+		if name == "prod" {
+			return errors.New("Unauthorized")
+		}
+		return nil
+	}
+	batch, ok := model.Init()().(tea.BatchMsg)
+	require.True(t, ok)
+	require.Len(t, batch, 2)
+	assert.Contains(t, model.View(), "…")
+
+	var updated tea.Model = model
+	for _, cmd := range batch {
+		updated, _ = updated.Update(cmd())
+	}
+	view := updated.View()
+	assert.Contains(t, view, "✓")
+	assert.Contains(t, view, "✗")
+	assert.Contains(t, view, "Unauthorized")
+	assert.NotContains(t, view, "…")
+}
+
+func TestTruncateProbeError(t *testing.T) {
+	assert.Equal(t, "timeout", truncateProbeError(errors.New("timeout")))
+	truncated := truncateProbeError(errors.New(strings.Repeat("x", 100)))
+	assert.Len(t, []rune(truncated), 60)
+	assert.True(t, strings.HasSuffix(truncated, "…"))
+}
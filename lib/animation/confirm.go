@@ -0,0 +1,133 @@
+package animation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ErrConfirmationRequired is returned when a confirmation can't be asked because there is no terminal
+var ErrConfirmationRequired = errors.New("confirmation required but no terminal is attached (use --yes to skip it)")
+
+// ConfirmOptions configures a confirmation prompt
+type ConfirmOptions struct {
+	// Details are shown below the question, e.g. the files or profiles affected
+	Details []string
+	// Destructive highlights the prompt in red and defaults the answer to "No"
+	Destructive bool
+}
+
+// confirmModel is a yes/no prompt
+type confirmModel struct {
+	question  string
+	options   ConfirmOptions
+	yes       bool // Currently highlighted answer
+	confirmed bool
+	done      bool
+}
+
+// newConfirmModel creates a prompt defaulting to "Yes" unless the action is destructive
+func newConfirmModel(question string, options ConfirmOptions) confirmModel {
+	return confirmModel{
+		question: question,
+		options:  options,
+		yes:      !options.Destructive,
+	}
+}
+
+// Init implements tea.Model
+func (m confirmModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model
+func (m confirmModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch keyMsg.String() {
+	case "y", "Y":
+		m.yes = true
+		m.confirmed = true
+		m.done = true
+		return m, tea.Quit
+	case "n", "N", "q", "esc", "ctrl+c":
+		m.yes = false
+		m.confirmed = false
+		m.done = true
+		return m, tea.Quit
+	case "left", "right", "h", "l", "tab":
+		m.yes = !m.yes
+	case "enter":
+		m.confirmed = m.yes
+		m.done = true
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// View implements tea.Model
+func (m confirmModel) View() string {
+	accent := lipgloss.Color("205")
+	if m.options.Destructive {
+		accent = lipgloss.Color("196")
+	}
+
+	questionStyle := lipgloss.NewStyle().Foreground(accent).Bold(true)
+	detailStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	selectedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("0")).Background(accent).Bold(true).Padding(0, 1)
+	optionStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Padding(0, 1)
+
+	if m.done {
+		answer := "No"
+		if m.confirmed {
+			answer = "Yes"
+		}
+		return fmt.Sprintf("%s %s\n", questionStyle.Render(m.question), answer)
+	}
+
+	var s strings.Builder
+	prefix := "? "
+	if m.options.Destructive {
+		prefix = "⚠ "
+	}
+	s.WriteString(questionStyle.Render(prefix + m.question))
+	s.WriteString("\n")
+	for _, detail := range m.options.Details {
+		s.WriteString(detailStyle.Render("  • " + detail))
+		s.WriteString("\n")
+	}
+	s.WriteString("\n")
+
+	yes, no := optionStyle.Render("Yes"), optionStyle.Render("No")
+	if m.yes {
+		yes = selectedStyle.Render("Yes")
+	} else {
+		no = selectedStyle.Render("No")
+	}
+	s.WriteString("  " + yes + "  " + no + "\n\n")
+	s.WriteString(detailStyle.Render("y/n to answer • ←/→ to choose • enter to confirm"))
+	s.WriteString("\n")
+
+	return s.String()
+}
+
+// Confirm asks a yes/no question and returns the answer
+// It fails with ErrConfirmationRequired when stdin is not a terminal
+func Confirm(question string, options ConfirmOptions) (bool, error) {
+	if !IsInteractive() {
+		return false, ErrConfirmationRequired
+	}
+
+	finalModel, err := tea.NewProgram(newConfirmModel(question, options)).Run()
+	if err != nil {
+		return false, fmt.Errorf("error running confirmation prompt: %w", err)
+	}
+
+	return finalModel.(confirmModel).confirmed, nil
+}
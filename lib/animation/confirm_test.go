@@ -0,0 +1,60 @@
+package animation
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfirmModelDefaults(t *testing.T) {
+	assert.True(t, newConfirmModel("Continue?", ConfirmOptions{}).yes)
+	assert.False(t, newConfirmModel("Delete?", ConfirmOptions{Destructive: true}).yes, "destructive prompts default to No")
+}
+
+func TestConfirmModelUpdate(t *testing.T) {
+	tests := []struct {
+		name        string
+		destructive bool
+		keys        []tea.KeyMsg
+		confirmed   bool
+	}{
+		{name: "y confirms", keys: []tea.KeyMsg{{Type: tea.KeyRunes, Runes: []rune{'y'}}}, confirmed: true},
+		{name: "n declines", keys: []tea.KeyMsg{{Type: tea.KeyRunes, Runes: []rune{'n'}}}, confirmed: false},
+		{name: "esc declines", keys: []tea.KeyMsg{{Type: tea.KeyEscape}}, confirmed: false},
+		{name: "enter accepts default", keys: []tea.KeyMsg{{Type: tea.KeyEnter}}, confirmed: true},
+		{name: "enter on destructive defaults to no", destructive: true, keys: []tea.KeyMsg{{Type: tea.KeyEnter}}, confirmed: false},
+		{name: "toggle then enter", destructive: true, keys: []tea.KeyMsg{{Type: tea.KeyLeft}, {Type: tea.KeyEnter}}, confirmed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var model tea.Model = newConfirmModel("Continue?", ConfirmOptions{Destructive: tt.destructive})
+			var cmd tea.Cmd
+			for _, key := range tt.keys {
+				model, cmd = model.Update(key)
+			}
+
+			final := model.(confirmModel)
+			assert.True(t, final.done)
+			assert.Equal(t, tt.confirmed, final.confirmed)
+			assert.NotNil(t, cmd)
+		})
+	}
+}
+
+func TestConfirmModelView(t *testing.T) {
+	model := newConfirmModel("Clean kubeconfig?", ConfirmOptions{
+		Destructive: true,
+		Details:     []string{"~/.kube/config"},
+	})
+
+	view := model.View()
+	assert.Contains(t, view, "⚠ Clean kubeconfig?")
+	assert.Contains(t, view, "• ~/.kube/config")
+	assert.Contains(t, view, "Yes")
+	assert.Contains(t, view, "No")
+
+	model.done = true
+	assert.Contains(t, model.View(), "Clean kubeconfig? No")
+}
@@ -0,0 +1,137 @@
+package animation
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/term"
+)
+
+// ErrInputCancelled is returned when the user leaves an input prompt without answering
+var ErrInputCancelled = errors.New("input cancelled")
+
+// IsInteractive reports whether stdin is a terminal, i.e. whether prompts can be shown
+func IsInteractive() bool {
+	return term.IsTerminal(os.Stdin.Fd())
+}
+
+// InputOptions configures a text input prompt
+type InputOptions struct {
+	Placeholder string
+	// Default is returned when the user submits an empty value
+	Default string
+	// Validate rejects a submitted value; the prompt stays open and shows the error
+	Validate Validator
+}
+
+// inputModel is a single-line text prompt
+type inputModel struct {
+	label     string
+	options   InputOptions
+	input     textinput.Model
+	value     string
+	err       error
+	done      bool
+	cancelled bool
+}
+
+// newInputModel creates a focused text prompt
+func newInputModel(label string, options InputOptions) inputModel {
+	input := textinput.New()
+	input.Placeholder = options.Placeholder
+	if input.Placeholder == "" {
+		input.Placeholder = options.Default
+	}
+	input.Focus()
+
+	return inputModel{
+		label:   label,
+		options: options,
+		input:   input,
+	}
+}
+
+// Init implements tea.Model
+func (m inputModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// Update implements tea.Model
+func (m inputModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "esc", "ctrl+c":
+			m.cancelled = true
+			m.done = true
+			return m, tea.Quit
+		case "enter":
+			value := strings.TrimSpace(m.input.Value())
+			if value == "" {
+				value = m.options.Default
+			}
+			if m.options.Validate != nil {
+				if err := m.options.Validate(value); err != nil {
+					m.err = err
+					return m, nil
+				}
+			}
+			m.value = value
+			m.done = true
+			return m, tea.Quit
+		}
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	if _, ok := msg.(tea.KeyMsg); ok {
+		m.err = nil
+	}
+	return m, cmd
+}
+
+// View implements tea.Model
+func (m inputModel) View() string {
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("205")).Bold(true)
+	hintStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+
+	if m.done {
+		if m.cancelled {
+			return ""
+		}
+		return fmt.Sprintf("%s %s\n", labelStyle.Render(m.label), m.value)
+	}
+
+	var s strings.Builder
+	s.WriteString(labelStyle.Render("? " + m.label))
+	s.WriteString("\n")
+	s.WriteString(m.input.View())
+	s.WriteString("\n")
+	if m.err != nil {
+		s.WriteString(errorStyle.Render("✗ " + m.err.Error()))
+		s.WriteString("\n")
+	}
+	s.WriteString("\n")
+	s.WriteString(hintStyle.Render("enter to submit • esc to cancel"))
+	s.WriteString("\n")
+	return s.String()
+}
+
+// PromptInput asks for a single line of text
+func PromptInput(label string, options InputOptions) (string, error) {
+	finalModel, err := tea.NewProgram(newInputModel(label, options)).Run()
+	if err != nil {
+		return "", fmt.Errorf("error running input prompt: %w", err)
+	}
+
+	result := finalModel.(inputModel)
+	if result.cancelled {
+		return "", ErrInputCancelled
+	}
+	return result.value, nil
+}
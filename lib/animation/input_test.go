@@ -0,0 +1,66 @@
+package animation
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+)
+
+func typeInput(model tea.Model, text string) tea.Model {
+	for _, r := range text {
+		model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	return model
+}
+
+func TestInputModelSubmit(t *testing.T) {
+	var model tea.Model = newInputModel("AWS profile", InputOptions{})
+	model = typeInput(model, " dev-admin ")
+	model, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	final := model.(inputModel)
+	assert.True(t, final.done)
+	assert.False(t, final.cancelled)
+	assert.Equal(t, "dev-admin", final.value)
+	assert.NotNil(t, cmd)
+	assert.Contains(t, final.View(), "AWS profile dev-admin")
+}
+
+func TestInputModelDefault(t *testing.T) {
+	var model tea.Model = newInputModel("Region", InputOptions{Default: "us-west-2"})
+	assert.Equal(t, "us-west-2", model.(inputModel).input.Placeholder)
+
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Equal(t, "us-west-2", model.(inputModel).value)
+}
+
+func TestInputModelCancel(t *testing.T) {
+	var model tea.Model = newInputModel("AWS profile", InputOptions{})
+	model = typeInput(model, "dev")
+	model, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEscape})
+
+	final := model.(inputModel)
+	assert.True(t, final.cancelled)
+	assert.NotNil(t, cmd)
+	assert.Empty(t, final.View())
+}
+
+func TestInputModelValidation(t *testing.T) {
+	var model tea.Model = newInputModel("Account ID", InputOptions{Validate: ValidateAccountID})
+	model = typeInput(model, "1234")
+	model, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	invalid := model.(inputModel)
+	assert.False(t, invalid.done, "invalid values keep the prompt open")
+	assert.Nil(t, cmd)
+	assert.Contains(t, invalid.View(), "not a valid AWS account ID")
+
+	model = typeInput(model, "56789012")
+	assert.Nil(t, model.(inputModel).err, "typing clears the error")
+
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	final := model.(inputModel)
+	assert.True(t, final.done)
+	assert.Equal(t, "123456789012", final.value)
+}
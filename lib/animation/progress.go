@@ -2,7 +2,10 @@ package animation
 
 import (
 	"fmt"
+	"os"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/charmbracelet/bubbles/progress"
 	tea "github.com/charmbracelet/bubbletea"
@@ -15,17 +18,63 @@ type ProgressModel struct {
 	total        int
 	current      int
 	currentItem  string
+	currentMsg   progressMsg
 	items        []string
 	errors       []string
+	failures     []progressMsg
+	showErrors   bool
 	quitting     bool
 	done         bool
 	successCount int
 }
 
+// ProgressUpdate describes a finished item with the context needed to diagnose failures
+type ProgressUpdate struct {
+	Item     string
+	Account  string
+	Region   string
+	Phase    string
+	Duration time.Duration
+	Err      error
+}
+
 // progressMsg is a message to update the progress
 type progressMsg struct {
-	item  string
-	error string
+	item     string
+	error    string
+	account  string
+	region   string
+	phase    string
+	duration time.Duration
+}
+
+// newProgressMsg converts a ProgressUpdate into the message consumed by the model
+func newProgressMsg(update ProgressUpdate) progressMsg {
+	msg := progressMsg{
+		item:     update.Item,
+		account:  update.Account,
+		region:   update.Region,
+		phase:    update.Phase,
+		duration: update.Duration,
+	}
+	if update.Err != nil {
+		msg.error = update.Err.Error()
+	}
+	return msg
+}
+
+// details renders the structured fields of the message, e.g. "123456789012 · us-west-2 · configured · 1.2s"
+func (msg progressMsg) details() string {
+	var parts []string
+	for _, part := range []string{msg.account, msg.region, msg.phase} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if msg.duration > 0 {
+		parts = append(parts, msg.duration.Round(100*time.Millisecond).String())
+	}
+	return strings.Join(parts, " · ")
 }
 
 // NewProgressModel creates a new progress bar model
@@ -58,14 +107,18 @@ func (m ProgressModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "q", "esc", "ctrl+c":
 			m.quitting = true
 			return m, tea.Quit
+		case "e":
+			m.showErrors = !m.showErrors
 		}
 		return m, nil
 
 	case progressMsg:
 		m.current++
 		m.currentItem = msg.item
+		m.currentMsg = msg
 		if msg.error != "" {
 			m.errors = append(m.errors, msg.error)
+			m.failures = append(m.failures, msg)
 		} else {
 			m.successCount++
 		}
@@ -128,7 +181,25 @@ func (m ProgressModel) View() string {
 			Foreground(lipgloss.Color("86")).
 			Bold(true)
 		s.WriteString(currentStyle.Render(fmt.Sprintf("⚡ Configuring: %s", m.currentItem)))
+		if details := m.currentMsg.details(); details != "" {
+			s.WriteString(counterStyle.Render(fmt.Sprintf("  %s", details)))
+		}
 		s.WriteString("\n\n")
+
+		if len(m.errors) > 0 {
+			failStyle := lipgloss.NewStyle().
+				Foreground(lipgloss.Color("196"))
+			hint := "press e to show details"
+			if m.showErrors {
+				hint = "press e to hide details"
+			}
+			s.WriteString(failStyle.Render(fmt.Sprintf("✗ %d failed", len(m.errors))))
+			s.WriteString(counterStyle.Render(fmt.Sprintf(" (%s)", hint)))
+			s.WriteString("\n\n")
+			if m.showErrors {
+				s.WriteString(m.errorLogView())
+			}
+		}
 	}
 
 	// Final summary
@@ -154,12 +225,21 @@ func (m ProgressModel) View() string {
 			s.WriteString(errorHeaderStyle.Render("Errors:"))
 			s.WriteString("\n")
 
+			if m.showErrors && len(m.failures) > 0 {
+				s.WriteString(m.errorLogView())
+				return s.String()
+			}
+
 			errorStyle := lipgloss.NewStyle().
 				Foreground(lipgloss.Color("240")).
 				Italic(true)
 
-			for _, err := range m.errors {
-				s.WriteString(errorStyle.Render(fmt.Sprintf("  • %s", err)))
+			for i, err := range m.errors {
+				line := summarizeError(err)
+				if i < len(m.failures) {
+					line = fmt.Sprintf("%s: %s", m.failures[i].item, line)
+				}
+				s.WriteString(errorStyle.Render(fmt.Sprintf("  • %s", line)))
 				s.WriteString("\n")
 			}
 		}
@@ -168,6 +248,46 @@ func (m ProgressModel) View() string {
 	return s.String()
 }
 
+// errorLogView renders every failed item with its context and full error message
+func (m ProgressModel) errorLogView() string {
+	var s strings.Builder
+
+	panelStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("196")).
+		Padding(0, 1)
+	itemStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("196")).
+		Bold(true)
+	detailStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("240"))
+
+	for i, failure := range m.failures {
+		if i > 0 {
+			s.WriteString("\n\n")
+		}
+		s.WriteString(itemStyle.Render(failure.item))
+		if details := failure.details(); details != "" {
+			s.WriteString(detailStyle.Render(fmt.Sprintf("  %s", details)))
+		}
+		s.WriteString("\n")
+		s.WriteString(failure.error)
+	}
+
+	return panelStyle.Render(s.String()) + "\n"
+}
+
+// summarizeError keeps the first line of an error, truncated so the summary fits on screen
+func summarizeError(err string) string {
+	const maxLength = 120
+
+	line := strings.TrimSpace(strings.SplitN(err, "\n", 2)[0])
+	if len(line) > maxLength {
+		line = line[:maxLength-3] + "..."
+	}
+	return line
+}
+
 // ProgressIncrement returns a command to increment the progress
 func ProgressIncrement(item string, err error) tea.Cmd {
 	return func() tea.Msg {
@@ -184,6 +304,22 @@ func ProgressIncrement(item string, err error) tea.Cmd {
 
 // ShowProgressBar shows a progress bar for multiple operations
 func ShowProgressBar(total int, fn func(update func(item string, err error)) error) error {
+	return ShowDetailedProgressBar(total, func(update func(ProgressUpdate)) error {
+		return fn(func(item string, err error) {
+			update(ProgressUpdate{Item: item, Err: err})
+		})
+	})
+}
+
+// ShowDetailedProgressBar shows a progress bar whose updates carry account, region, phase and duration
+// Failed items can be inspected with `e` while the operation is running. Outside a terminal, or with
+// SetProgressMode, the updates are printed as plain lines or JSON instead
+func ShowDetailedProgressBar(total int, fn func(update func(ProgressUpdate)) error) error {
+	return progressRenderers[resolveProgressMode()](total, fn)
+}
+
+// showTUIProgress runs fn under the bubbletea progress bar
+func showTUIProgress(total int, fn func(update func(ProgressUpdate)) error) error {
 	model := NewProgressModel(total)
 	p := tea.NewProgram(model)
 
@@ -191,16 +327,8 @@ func ShowProgressBar(total int, fn func(update func(item string, err error)) err
 	errChan := make(chan error, 1)
 
 	// Function to update the progress
-	updateProgress := func(item string, err error) {
-		p.Send(progressMsg{
-			item: item,
-			error: func() string {
-				if err != nil {
-					return err.Error()
-				}
-				return ""
-			}(),
-		})
+	updateProgress := func(update ProgressUpdate) {
+		p.Send(newProgressMsg(update))
 	}
 
 	// Execute the function in a goroutine
@@ -217,3 +345,24 @@ func ShowProgressBar(total int, fn func(update func(item string, err error)) err
 	// Get the function result
 	return <-errChan
 }
+
+// showPlainProgress prints one line per update on stderr when no terminal is attached (CI, tests)
+func showPlainProgress(total int, fn func(update func(ProgressUpdate)) error) error {
+	var mu sync.Mutex
+	done := 0
+	return fn(func(update ProgressUpdate) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		done++
+		msg := newProgressMsg(update)
+		line := fmt.Sprintf("[%d/%d] %s", done, total, msg.item)
+		if details := msg.details(); details != "" {
+			line += " (" + details + ")"
+		}
+		if msg.error != "" {
+			line += ": " + summarizeError(msg.error)
+		}
+		fmt.Fprintln(os.Stderr, line)
+	})
+}
@@ -0,0 +1,182 @@
+package animation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/x/term"
+)
+
+// ProgressMode selects how progress bars and status spinners are rendered
+type ProgressMode string
+
+const (
+	// ProgressAuto uses the TUI in a terminal and plain lines otherwise
+	ProgressAuto ProgressMode = ""
+	// ProgressTUI draws the bubbletea progress bar and spinner
+	ProgressTUI ProgressMode = "tui"
+	// ProgressPlain prints one line per event on stderr, for CI logs
+	ProgressPlain ProgressMode = "plain"
+	// ProgressJSON prints one JSON object per event on stderr, for tools following the run
+	ProgressJSON ProgressMode = "json"
+)
+
+// progressRenderer runs fn and reports the updates it sends
+type progressRenderer func(total int, fn func(update func(ProgressUpdate)) error) error
+
+// statusRenderer runs fn and reports the status messages it sends
+type statusRenderer func(ctx context.Context, message string, fn func(ctx context.Context, status func(message string)) error) error
+
+var (
+	progressMode = ProgressAuto
+
+	progressRenderers = map[ProgressMode]progressRenderer{
+		ProgressTUI:   showTUIProgress,
+		ProgressPlain: showPlainProgress,
+		ProgressJSON:  showJSONProgress,
+	}
+	statusRenderers = map[ProgressMode]statusRenderer{
+		ProgressTUI:   showTUIStatus,
+		ProgressPlain: showPlainStatus,
+		ProgressJSON:  showJSONStatus,
+	}
+)
+
+// SetProgressMode selects the renderer of progress bars and status spinners: "tui", "plain", "json",
+// or "" to pick the TUI only when stdin and stdout are terminals
+func SetProgressMode(mode string) error {
+	switch ProgressMode(mode) {
+	case ProgressAuto, ProgressTUI, ProgressPlain, ProgressJSON:
+		progressMode = ProgressMode(mode)
+		return nil
+	default:
+		return fmt.Errorf("invalid progress mode %q (expected tui, plain or json)", mode)
+	}
+}
+
+// resolveProgressMode returns the mode to render with, resolving ProgressAuto
+func resolveProgressMode() ProgressMode {
+	if progressMode != ProgressAuto {
+		return progressMode
+	}
+	if IsInteractive() && term.IsTerminal(os.Stdout.Fd()) {
+		return ProgressTUI
+	}
+	return ProgressPlain
+}
+
+// progressEvent is one line of the JSON progress output
+type progressEvent struct {
+	Time  time.Time `json:"time"`
+	Event string    `json:"event"`
+	// Done to Failed describe progress bars, Message describes status spinners
+	Done       int    `json:"done,omitempty"`
+	Total      int    `json:"total,omitempty"`
+	Item       string `json:"item,omitempty"`
+	Account    string `json:"account,omitempty"`
+	Region     string `json:"region,omitempty"`
+	Phase      string `json:"phase,omitempty"`
+	DurationMs int64  `json:"duration_ms,omitempty"`
+	Failed     int    `json:"failed,omitempty"`
+	Message    string `json:"message,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// jsonEmitter prints events as JSON lines on stderr, apart from the command's output; a mutex keeps lines from concurrent updates whole
+type jsonEmitter struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+func newJSONEmitter() *jsonEmitter {
+	return &jsonEmitter{encoder: json.NewEncoder(os.Stderr)}
+}
+
+func (e *jsonEmitter) emit(event progressEvent) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	event.Time = time.Now().UTC()
+	// Stderr errors can't be reported anywhere better
+	_ = e.encoder.Encode(event)
+}
+
+// showJSONProgress prints a start event, one progress event per update and a finish event with the failure count
+func showJSONProgress(total int, fn func(update func(ProgressUpdate)) error) error {
+	emitter := newJSONEmitter()
+	emitter.emit(progressEvent{Event: "start", Total: total})
+
+	var mu sync.Mutex
+	done, failed := 0, 0
+	err := fn(func(update ProgressUpdate) {
+		mu.Lock()
+		done++
+		event := progressEvent{
+			Event:      "progress",
+			Done:       done,
+			Total:      total,
+			Item:       update.Item,
+			Account:    update.Account,
+			Region:     update.Region,
+			Phase:      update.Phase,
+			DurationMs: update.Duration.Milliseconds(),
+		}
+		if update.Err != nil {
+			failed++
+			event.Error = update.Err.Error()
+		}
+		mu.Unlock()
+		emitter.emit(event)
+	})
+
+	finish := progressEvent{Event: "finish", Done: done, Total: total, Failed: failed}
+	if err != nil {
+		finish.Error = err.Error()
+	}
+	emitter.emit(finish)
+	return err
+}
+
+// showPlainStatus prints the message when fn starts, each status change, and the outcome with the elapsed time
+func showPlainStatus(ctx context.Context, message string, fn func(ctx context.Context, status func(message string)) error) error {
+	startedAt := time.Now()
+	fmt.Fprintf(os.Stderr, "… %s\n", message)
+	var mu sync.Mutex
+	err := fn(ctx, func(status string) {
+		mu.Lock()
+		defer mu.Unlock()
+		message = status
+		fmt.Fprintf(os.Stderr, "… %s\n", status)
+	})
+
+	mu.Lock()
+	defer mu.Unlock()
+	elapsed := time.Since(startedAt).Round(time.Second)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "✗ %s (%s): %s\n", message, elapsed, summarizeError(err.Error()))
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "✓ %s (%s)\n", message, elapsed)
+	return nil
+}
+
+// showJSONStatus prints a status event when fn starts and on each status change, then a done or failed event
+func showJSONStatus(ctx context.Context, message string, fn func(ctx context.Context, status func(message string)) error) error {
+	emitter := newJSONEmitter()
+	startedAt := time.Now()
+	emitter.emit(progressEvent{Event: "status", Message: message})
+	err := fn(ctx, func(status string) {
+		emitter.emit(progressEvent{Event: "status", Message: status})
+	})
+
+	event := progressEvent{Event: "done", DurationMs: time.Since(startedAt).Milliseconds()}
+	if err != nil {
+		event.Event = "failed"
+		event.Error = err.Error()
+	}
+	emitter.emit(event)
+	return err
+}
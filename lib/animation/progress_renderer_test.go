@@ -0,0 +1,159 @@
+package animation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// captureStderr returns what fn prints on stderr
+func captureStderr(t *testing.T, fn func()) string {
+	t.Helper()
+	return capture(t, &os.Stderr, fn)
+}
+
+// captureStdout returns what fn prints on stdout
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	return capture(t, &os.Stdout, fn)
+}
+
+// capture returns what fn writes to file, which it swaps for a pipe while fn runs
+func capture(t *testing.T, file **os.File, fn func()) string {
+	t.Helper()
+	reader, writer, err := os.Pipe()
+	require.NoError(t, err)
+	original := *file
+	*file = writer
+	defer func() { *file = original }()
+
+	fn()
+
+	writer.Close()
+	output, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	return string(output)
+}
+
+// decodeEvents parses JSON lines output, clearing the timestamps once checked
+func decodeEvents(t *testing.T, output string) []progressEvent {
+	t.Helper()
+	var events []progressEvent
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		var event progressEvent
+		require.NoError(t, json.Unmarshal([]byte(line), &event), line)
+		assert.False(t, event.Time.IsZero())
+		event.Time = time.Time{}
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestSetProgressMode(t *testing.T) {
+	defer func() { progressMode = ProgressAuto }()
+
+	for _, mode := range []string{"tui", "plain", "json", ""} {
+		require.NoError(t, SetProgressMode(mode))
+		assert.Equal(t, ProgressMode(mode), progressMode)
+	}
+	assert.ErrorContains(t, SetProgressMode("fancy"), `invalid progress mode "fancy"`)
+
+	require.NoError(t, SetProgressMode("json"))
+	assert.Equal(t, ProgressJSON, resolveProgressMode())
+	// Tests don't run in a terminal
+	require.NoError(t, SetProgressMode(""))
+	assert.Equal(t, ProgressPlain, resolveProgressMode())
+}
+
+func TestShowJSONProgress(t *testing.T) {
+	var err error
+	output := captureStderr(t, func() {
+		err = showJSONProgress(2, func(update func(ProgressUpdate)) error {
+			update(ProgressUpdate{Item: "dev-main", Account: "111111111111", Region: "us-west-2", Phase: "configured", Duration: 1500 * time.Millisecond})
+			update(ProgressUpdate{Item: "prod-main", Err: errors.New("access denied")})
+			return assert.AnError
+		})
+	})
+	assert.Equal(t, assert.AnError, err)
+
+	assert.Equal(t, []progressEvent{
+		{Event: "start", Total: 2},
+		{Event: "progress", Done: 1, Total: 2, Item: "dev-main", Account: "111111111111", Region: "us-west-2", Phase: "configured", DurationMs: 1500},
+		{Event: "progress", Done: 2, Total: 2, Item: "prod-main", Error: "access denied"},
+		{Event: "finish", Done: 2, Total: 2, Failed: 1, Error: assert.AnError.Error()},
+	}, decodeEvents(t, output))
+}
+
+func TestShowPlainStatus(t *testing.T) {
+	var err error
+	output := captureStderr(t, func() {
+		err = showPlainStatus(context.Background(), "Fetching clusters", func(ctx context.Context, status func(string)) error {
+			status("Fetching clusters (3/5 accounts)")
+			return nil
+		})
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "… Fetching clusters\n… Fetching clusters (3/5 accounts)\n✓ Fetching clusters (3/5 accounts) (0s)\n", output)
+
+	output = captureStderr(t, func() {
+		err = showPlainStatus(context.Background(), "Listing regions", func(ctx context.Context, status func(string)) error {
+			return errors.New("expired token\nrequest id: 123")
+		})
+	})
+	assert.EqualError(t, err, "expired token\nrequest id: 123")
+	assert.Equal(t, "… Listing regions\n✗ Listing regions (0s): expired token\n", output)
+}
+
+func TestShowJSONStatus(t *testing.T) {
+	var err error
+	output := captureStderr(t, func() {
+		err = showJSONStatus(context.Background(), "Listing regions", func(ctx context.Context, status func(string)) error {
+			status("Probing 17 regions")
+			return assert.AnError
+		})
+	})
+	assert.Equal(t, assert.AnError, err)
+
+	events := decodeEvents(t, output)
+	require.Len(t, events, 3)
+	assert.Equal(t, "status", events[0].Event)
+	assert.Equal(t, "Listing regions", events[0].Message)
+	assert.Equal(t, "Probing 17 regions", events[1].Message)
+	assert.Equal(t, "failed", events[2].Event)
+	assert.Equal(t, assert.AnError.Error(), events[2].Error)
+}
+
+func TestShowStatusWithoutTerminal(t *testing.T) {
+	// Outside a terminal ShowStatus prints plain lines instead of starting the spinner
+	output := captureStderr(t, func() {
+		require.NoError(t, ShowStatus(context.Background(), "Working", func(ctx context.Context, status func(string)) error { return nil }))
+	})
+	assert.Equal(t, "… Working\n✓ Working (0s)\n", output)
+}
+
+func TestShowSpinnerFollowsProgressMode(t *testing.T) {
+	require.NoError(t, SetProgressMode("json"))
+	defer SetProgressMode("")
+
+	// Progress goes to stderr, so nothing is mixed into the command's output
+	var stderr string
+	stdout := captureStdout(t, func() {
+		stderr = captureStderr(t, func() {
+			require.NoError(t, ShowSpinner("Loading", func() error { return nil }))
+		})
+	})
+	assert.Empty(t, stdout)
+
+	events := decodeEvents(t, stderr)
+	require.Len(t, events, 2)
+	assert.Equal(t, "Loading", events[0].Message)
+	assert.Equal(t, "done", events[1].Event)
+}
@@ -1,11 +1,17 @@
 package animation
 
 import (
+	"errors"
+	"io"
+	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/charmbracelet/bubbles/progress"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewProgressModel(t *testing.T) {
@@ -317,6 +323,27 @@ func TestShowProgressBar(t *testing.T) {
 	}
 }
 
+func TestShowPlainProgress(t *testing.T) {
+	reader, writer, err := os.Pipe()
+	require.NoError(t, err)
+	stderr := os.Stderr
+	os.Stderr = writer
+
+	err = showPlainProgress(2, func(update func(ProgressUpdate)) error {
+		update(ProgressUpdate{Item: "dev-main", Account: "111111111111", Region: "us-west-2"})
+		update(ProgressUpdate{Item: "prod-main", Err: errors.New("access denied\nrequest id: 123")})
+		return assert.AnError
+	})
+
+	writer.Close()
+	os.Stderr = stderr
+	output, readErr := io.ReadAll(reader)
+	require.NoError(t, readErr)
+
+	assert.Equal(t, assert.AnError, err)
+	assert.Equal(t, "[1/2] dev-main (111111111111 · us-west-2)\n[2/2] prod-main: access denied\n", string(output))
+}
+
 func TestProgressModelStruct(t *testing.T) {
 	// Test ProgressModel struct fields
 	model := ProgressModel{
@@ -494,3 +521,66 @@ func TestProgressModelErrorHandling(t *testing.T) {
 	assert.Equal(t, 1, model.successCount)
 	assert.Len(t, model.errors, 1) // Still has the previous error
 }
+
+func TestNewProgressMsg(t *testing.T) {
+	msg := newProgressMsg(ProgressUpdate{
+		Item:     "cluster-1",
+		Account:  "123456789012",
+		Region:   "us-west-2",
+		Phase:    "failed",
+		Duration: 1234 * time.Millisecond,
+		Err:      assert.AnError,
+	})
+
+	assert.Equal(t, "cluster-1", msg.item)
+	assert.Equal(t, assert.AnError.Error(), msg.error)
+	assert.Equal(t, "123456789012 · us-west-2 · failed · 1.2s", msg.details())
+	assert.Empty(t, progressMsg{item: "only-item"}.details())
+}
+
+func TestProgressModelErrorLogToggle(t *testing.T) {
+	model := NewProgressModel(3)
+	model.progress = progress.New(
+		progress.WithDefaultGradient(),
+		progress.WithWidth(50),
+		progress.WithoutPercentage(),
+	)
+
+	updatedModel, _ := model.Update(newProgressMsg(ProgressUpdate{
+		Item:    "cluster-1",
+		Account: "123456789012",
+		Region:  "us-west-2",
+		Err:     errors.New("first line\nsecond line"),
+	}))
+	model = updatedModel.(ProgressModel)
+	require.Len(t, model.failures, 1)
+
+	view := model.View()
+	assert.Contains(t, view, "✗ 1 failed")
+	assert.Contains(t, view, "press e to show details")
+	assert.NotContains(t, view, "second line")
+
+	updatedModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'e'}})
+	model = updatedModel.(ProgressModel)
+	assert.True(t, model.showErrors)
+	assert.False(t, model.quitting)
+
+	view = model.View()
+	assert.Contains(t, view, "press e to hide details")
+	assert.Contains(t, view, "second line")
+	assert.Contains(t, view, "123456789012 · us-west-2")
+
+	// The compact summary only keeps the first line of each error
+	model.done = true
+	model.showErrors = false
+	view = model.View()
+	assert.Contains(t, view, "• cluster-1: first line")
+	assert.NotContains(t, view, "second line")
+}
+
+func TestSummarizeError(t *testing.T) {
+	assert.Equal(t, "short", summarizeError("short\ndetails"))
+	long := strings.Repeat("x", 200)
+	assert.Len(t, summarizeError(long), 120)
+	assert.True(t, strings.HasSuffix(summarizeError(long), "..."))
+}
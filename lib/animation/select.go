@@ -0,0 +1,115 @@
+package animation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ErrSelectionRequired is returned when a choice can't be asked because there is no terminal
+var ErrSelectionRequired = errors.New("selection required but no terminal is attached")
+
+// SelectOption is one entry of a Select list
+type SelectOption struct {
+	Label string
+	// Description is shown dimmed after the label, e.g. what the option applies
+	Description string
+}
+
+// selectModel is a list of options picked with the arrow keys
+type selectModel struct {
+	title     string
+	options   []SelectOption
+	cursor    int
+	done      bool
+	cancelled bool
+}
+
+// Init implements tea.Model
+func (m selectModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model
+func (m selectModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch keyMsg.String() {
+	case "q", "esc", "ctrl+c":
+		m.cancelled = true
+		m.done = true
+		return m, tea.Quit
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(m.options)-1 {
+			m.cursor++
+		}
+	case "enter":
+		m.done = true
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// View implements tea.Model
+func (m selectModel) View() string {
+	titleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("205")).Bold(true)
+	selectedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("205")).Bold(true)
+	detailStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+
+	if m.done {
+		if m.cancelled {
+			return ""
+		}
+		return fmt.Sprintf("%s %s\n", titleStyle.Render(m.title), m.options[m.cursor].Label)
+	}
+
+	var s strings.Builder
+	s.WriteString(titleStyle.Render("? " + m.title))
+	s.WriteString("\n\n")
+	for i, option := range m.options {
+		line := "  " + option.Label
+		if i == m.cursor {
+			line = selectedStyle.Render("❯ " + option.Label)
+		}
+		if option.Description != "" {
+			line += "  " + detailStyle.Render(option.Description)
+		}
+		s.WriteString(line + "\n")
+	}
+	s.WriteString("\n")
+	s.WriteString(detailStyle.Render("↑/↓ to move • enter to select • esc to cancel"))
+	s.WriteString("\n")
+	return s.String()
+}
+
+// Select asks to pick one of options and returns its index
+// It fails with ErrSelectionRequired when stdin is not a terminal and ErrInputCancelled when left without a choice
+func Select(title string, options []SelectOption) (int, error) {
+	if len(options) == 0 {
+		return 0, errors.New("nothing to select")
+	}
+	if !IsInteractive() {
+		return 0, ErrSelectionRequired
+	}
+
+	finalModel, err := tea.NewProgram(selectModel{title: title, options: options}).Run()
+	if err != nil {
+		return 0, fmt.Errorf("error running selection prompt: %w", err)
+	}
+
+	result := finalModel.(selectModel)
+	if result.cancelled {
+		return 0, ErrInputCancelled
+	}
+	return result.cursor, nil
+}
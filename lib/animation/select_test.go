@@ -0,0 +1,67 @@
+package animation
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectModelUpdate(t *testing.T) {
+	options := []SelectOption{{Label: "dev"}, {Label: "staging"}, {Label: "prod"}}
+
+	tests := []struct {
+		name      string
+		keys      []tea.KeyMsg
+		cursor    int
+		cancelled bool
+	}{
+		{name: "enter picks the first option", keys: []tea.KeyMsg{{Type: tea.KeyEnter}}, cursor: 0},
+		{name: "down then enter", keys: []tea.KeyMsg{{Type: tea.KeyDown}, {Type: tea.KeyEnter}}, cursor: 1},
+		{name: "the cursor stops at the last option", keys: []tea.KeyMsg{{Type: tea.KeyDown}, {Type: tea.KeyDown}, {Type: tea.KeyDown}, {Type: tea.KeyEnter}}, cursor: 2},
+		{name: "vim keys", keys: []tea.KeyMsg{{Type: tea.KeyRunes, Runes: []rune{'j'}}, {Type: tea.KeyRunes, Runes: []rune{'j'}}, {Type: tea.KeyRunes, Runes: []rune{'k'}}, {Type: tea.KeyEnter}}, cursor: 1},
+		{name: "the cursor stops at the first option", keys: []tea.KeyMsg{{Type: tea.KeyUp}, {Type: tea.KeyEnter}}, cursor: 0},
+		{name: "esc cancels", keys: []tea.KeyMsg{{Type: tea.KeyEscape}}, cancelled: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var model tea.Model = selectModel{title: "Workspace", options: options}
+			var cmd tea.Cmd
+			for _, key := range tt.keys {
+				model, cmd = model.Update(key)
+			}
+
+			final := model.(selectModel)
+			assert.True(t, final.done)
+			assert.Equal(t, tt.cancelled, final.cancelled)
+			if !tt.cancelled {
+				assert.Equal(t, tt.cursor, final.cursor)
+			}
+			assert.NotNil(t, cmd)
+		})
+	}
+}
+
+func TestSelectModelView(t *testing.T) {
+	model := selectModel{title: "Workspace", options: []SelectOption{
+		{Label: "dev", Description: "dev-admin · us-east-1"},
+		{Label: "prod"},
+	}}
+
+	view := model.View()
+	assert.Contains(t, view, "Workspace")
+	assert.Contains(t, view, "❯ dev")
+	assert.Contains(t, view, "dev-admin · us-east-1")
+	assert.Contains(t, view, "  prod")
+
+	model.done = true
+	assert.Contains(t, model.View(), "dev")
+	model.cancelled = true
+	assert.Empty(t, model.View())
+}
+
+func TestSelectRequiresOptions(t *testing.T) {
+	_, err := Select("Workspace", nil)
+	assert.Error(t, err)
+}
@@ -1,9 +1,11 @@
 package animation
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
+	"github.com/andresgarcia29/ark-cli/logs"
 	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -17,6 +19,8 @@ type ProfileDisplayInfo struct {
 	AccountID   string
 	RoleName    string
 	Region      string
+	// Deprecation is set when the team's deprecation policy lists the profile's account or role
+	Deprecation string
 }
 
 // profileSelectorModel represents the model for the profile selector with Bubble Tea
@@ -30,6 +34,7 @@ type profileSelectorModel struct {
 	selected         *services_aws.ProfileConfig
 	quitting         bool
 	searchMode       bool
+	policy           *services_aws.DeprecationPolicy
 }
 
 // initialProfileSelectorModel creates the initial model for the selector
@@ -45,6 +50,32 @@ func initialProfileSelectorModel(profiles []services_aws.ProfileConfig) profileS
 	}
 }
 
+// profilesReloadedMsg carries the profiles re-read after ~/.aws/config changed
+type profilesReloadedMsg []services_aws.ProfileConfig
+
+// reloadProfiles swaps in the new profiles, keeping the search query and the highlighted profile
+func (m profileSelectorModel) reloadProfiles(profiles []services_aws.ProfileConfig) profileSelectorModel {
+	var current string
+	if m.cursor < len(m.filteredProfiles) {
+		current = m.filteredProfiles[m.cursor].ProfileName
+	}
+
+	m.profiles = profiles
+	m.filterProfiles()
+	m.cursor, m.offset = 0, 0
+
+	for i, profile := range m.filteredProfiles {
+		if profile.ProfileName == current {
+			m.cursor = i
+			if m.cursor >= m.offset+m.getCurrentVisibleLines() {
+				m.offset = m.cursor - m.getCurrentVisibleLines() + 1
+			}
+			break
+		}
+	}
+	return m
+}
+
 // Init implements the tea.Model Init method
 func (m profileSelectorModel) Init() tea.Cmd {
 	return nil
@@ -53,6 +84,9 @@ func (m profileSelectorModel) Init() tea.Cmd {
 // Update implements the tea.Model Update method
 func (m profileSelectorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case profilesReloadedMsg:
+		return m.reloadProfiles(msg), nil
+
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "ctrl+c", "q":
@@ -301,6 +335,9 @@ func (m profileSelectorModel) View() string {
 		}
 
 		displayInfo := formatProfileDisplay(profile)
+		if deprecation, ok := m.policy.Lookup(profile); ok {
+			displayInfo.Deprecation = deprecationLabel(deprecation)
+		}
 
 		// Style based on profile type
 		var nameStyle lipgloss.Style
@@ -313,6 +350,9 @@ func (m profileSelectorModel) View() string {
 		case services_aws.ProfileTypeAssumeRole:
 			nameStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
 			typeStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true)
+		case services_aws.ProfileTypeWebIdentity:
+			nameStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("141"))
+			typeStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("141")).Bold(true)
 		default:
 			nameStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
 			typeStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
@@ -331,6 +371,10 @@ func (m profileSelectorModel) View() string {
 			lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render(displayInfo.Description),
 		)
 
+		if displayInfo.Deprecation != "" {
+			line += " " + lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Render(displayInfo.Deprecation)
+		}
+
 		s.WriteString(line)
 		s.WriteString("\n")
 	}
@@ -347,6 +391,14 @@ func (m profileSelectorModel) View() string {
 	return s.String()
 }
 
+// deprecationLabel is the short marker shown next to a deprecated profile
+func deprecationLabel(deprecation services_aws.Deprecation) string {
+	if deprecation.Replacement != "" {
+		return fmt.Sprintf("⚠ deprecated → %s", deprecation.Replacement)
+	}
+	return "⚠ deprecated"
+}
+
 // formatProfileDisplay formats the profile information for display
 func formatProfileDisplay(profile services_aws.ProfileConfig) ProfileDisplayInfo {
 	var description string
@@ -357,25 +409,19 @@ func formatProfileDisplay(profile services_aws.ProfileConfig) ProfileDisplayInfo
 		accountID = profile.AccountID
 		roleName = profile.RoleName
 		description = fmt.Sprintf("SSO - Account: %s, Role: %s", accountID, roleName)
-	case services_aws.ProfileTypeAssumeRole:
-		// Extract account ID from ARN
-		if strings.Contains(profile.RoleARN, ":") {
-			parts := strings.Split(profile.RoleARN, ":")
-			if len(parts) >= 5 {
-				accountID = parts[4]
-			}
+	case services_aws.ProfileTypeAssumeRole, services_aws.ProfileTypeWebIdentity:
+		accountID, roleName = services_aws.ProfileAccountAndRole(profile)
+		kind := "Assume Role"
+		if profile.ProfileType == services_aws.ProfileTypeWebIdentity {
+			kind = "Web Identity"
 		}
-		// Extract role name from ARN
-		if strings.Contains(profile.RoleARN, "/") {
-			parts := strings.Split(profile.RoleARN, "/")
-			if len(parts) >= 2 {
-				roleName = parts[1]
-			}
-		}
-		description = fmt.Sprintf("Assume Role - Account: %s, Role: %s", accountID, roleName)
+		description = fmt.Sprintf("%s - Account: %s, Role: %s", kind, accountID, roleName)
 	default:
 		description = "Unknown profile type"
 	}
+	if hint := services_aws.RoleDescription(roleName); hint != "" {
+		description += " · " + hint
+	}
 
 	return ProfileDisplayInfo{
 		Name:        profile.ProfileName,
@@ -401,8 +447,26 @@ func InteractiveProfileSelector() (*services_aws.ProfileConfig, error) {
 
 	// Create and run the Bubble Tea program
 	model := initialProfileSelectorModel(profiles)
+	model.policy = services_aws.CurrentDeprecationPolicy(context.Background())
 	program := tea.NewProgram(model)
 
+	// Refresh the list when ~/.aws/config is edited or rewritten by `ark aws sso` in another terminal
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		err := services_aws.WatchConfig(ctx, func() {
+			profiles, err := services_aws.ReadAllProfilesFromConfig()
+			if err != nil {
+				logs.GetLogger().Warnw("Failed to reload profiles", "error", err)
+				return
+			}
+			program.Send(profilesReloadedMsg(profiles))
+		})
+		if err != nil {
+			logs.GetLogger().Debugw("Not watching AWS config for changes", "error", err)
+		}
+	}()
+
 	finalModel, err := program.Run()
 	if err != nil {
 		return nil, fmt.Errorf("error running profile selector: %w", err)
@@ -1,6 +1,7 @@
 package animation
 
 import (
+	"strings"
 	"testing"
 
 	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
@@ -344,6 +345,40 @@ func TestFormatProfileDisplay(t *testing.T) {
 				Region:      "us-east-1",
 			},
 		},
+		{
+			name: "Assume role profile with a role path",
+			profile: services_aws.ProfileConfig{
+				ProfileName:   "deploy",
+				ProfileType:   services_aws.ProfileTypeAssumeRole,
+				RoleARN:       "arn:aws:iam::987654321098:role/teams/platform/Deploy",
+				SourceProfile: "source-profile",
+			},
+			expected: ProfileDisplayInfo{
+				Name:        "deploy",
+				Type:        "assume_role",
+				Description: "Assume Role - Account: 987654321098, Role: Deploy",
+				AccountID:   "987654321098",
+				RoleName:    "Deploy",
+			},
+		},
+		{
+			name: "Web identity profile",
+			profile: services_aws.ProfileConfig{
+				ProfileName:          "ci",
+				ProfileType:          services_aws.ProfileTypeWebIdentity,
+				Region:               "us-west-2",
+				RoleARN:              "arn:aws:iam::123456789012:role/GitHubActions",
+				WebIdentityTokenFile: "/tmp/token",
+			},
+			expected: ProfileDisplayInfo{
+				Name:        "ci",
+				Type:        "web_identity",
+				Description: "Web Identity - Account: 123456789012, Role: GitHubActions",
+				AccountID:   "123456789012",
+				RoleName:    "GitHubActions",
+				Region:      "us-west-2",
+			},
+		},
 		{
 			name: "Unknown profile type",
 			profile: services_aws.ProfileConfig{
@@ -615,6 +650,63 @@ func TestProfileSelectorModelSelection(t *testing.T) {
 	assert.NotNil(t, cmd) // Should return tea.Quit
 }
 
+func TestProfileSelectorModelReloadProfiles(t *testing.T) {
+	profiles := []services_aws.ProfileConfig{
+		{ProfileName: "dev-admin"},
+		{ProfileName: "dev-readonly"},
+		{ProfileName: "prod-readonly"},
+	}
+
+	model := initialProfileSelectorModel(profiles)
+	model.searchQuery = "readonly"
+	model.filterProfiles()
+	model.cursor = 1 // prod-readonly
+
+	// A profile is added before the highlighted one and another one is removed
+	updatedModel, cmd := model.Update(profilesReloadedMsg{
+		{ProfileName: "dev-readonly"},
+		{ProfileName: "qa-readonly"},
+		{ProfileName: "prod-readonly"},
+	})
+	model = updatedModel.(profileSelectorModel)
+	assert.Nil(t, cmd)
+	assert.Equal(t, "readonly", model.searchQuery)
+	assert.Len(t, model.profiles, 3)
+	assert.Len(t, model.filteredProfiles, 3)
+	assert.Equal(t, "prod-readonly", model.filteredProfiles[model.cursor].ProfileName)
+
+	// The highlighted profile disappears: the cursor goes back to the top
+	updatedModel, _ = model.Update(profilesReloadedMsg{{ProfileName: "dev-readonly"}})
+	model = updatedModel.(profileSelectorModel)
+	assert.Equal(t, 0, model.cursor)
+	assert.Equal(t, 0, model.offset)
+}
+
+func TestProfileSelectorModelViewMarksDeprecatedProfiles(t *testing.T) {
+	profiles := []services_aws.ProfileConfig{
+		{ProfileName: "dev-admin", ProfileType: services_aws.ProfileTypeSSO, AccountID: "111111111111", RoleName: "AdministratorAccess"},
+		{ProfileName: "dev-readonly", ProfileType: services_aws.ProfileTypeSSO, AccountID: "111111111111", RoleName: "ReadOnlyAccess"},
+	}
+
+	model := initialProfileSelectorModel(profiles)
+	model.policy = &services_aws.DeprecationPolicy{Deprecations: []services_aws.Deprecation{
+		{AccountID: "111111111111", Role: "AdministratorAccess", Replacement: "dev-platform-admin"},
+	}}
+
+	lines := strings.Split(model.View(), "\n")
+	var adminLine, readonlyLine string
+	for _, line := range lines {
+		switch {
+		case strings.Contains(line, "dev-admin"):
+			adminLine = line
+		case strings.Contains(line, "dev-readonly"):
+			readonlyLine = line
+		}
+	}
+	assert.Contains(t, adminLine, "deprecated → dev-platform-admin")
+	assert.NotContains(t, readonlyLine, "deprecated")
+}
+
 func TestProfileSelectorModelStruct(t *testing.T) {
 	// Test profileSelectorModel struct fields
 	profiles := []services_aws.ProfileConfig{
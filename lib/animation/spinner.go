@@ -1,6 +1,7 @@
 package animation
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -11,10 +12,13 @@ import (
 
 // SpinnerModel represents the spinner model
 type SpinnerModel struct {
-	spinner  spinner.Model
-	message  string
-	quitting bool
-	done     bool
+	spinner    spinner.Model
+	message    string
+	startedAt  time.Time
+	cancelHint string
+	cancel     context.CancelFunc
+	quitting   bool
+	done       bool
 }
 
 // NewSpinnerModel creates a new spinner model
@@ -23,11 +27,21 @@ func NewSpinnerModel(message string) SpinnerModel {
 	s.Spinner = spinner.Dot
 	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
 	return SpinnerModel{
-		spinner: s,
-		message: message,
+		spinner:   s,
+		message:   message,
+		startedAt: time.Now(),
 	}
 }
 
+// NewStatusModel creates a spinner that shows the elapsed time and how to cancel
+// Pressing a quit key calls cancel so the running operation can stop
+func NewStatusModel(message string, cancel context.CancelFunc) SpinnerModel {
+	m := NewSpinnerModel(message)
+	m.cancel = cancel
+	m.cancelHint = "ctrl+c to cancel"
+	return m
+}
+
 // Init implements tea.Model
 func (m SpinnerModel) Init() tea.Cmd {
 	return m.spinner.Tick
@@ -40,10 +54,17 @@ func (m SpinnerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		switch msg.String() {
 		case "q", "esc", "ctrl+c":
 			m.quitting = true
+			if m.cancel != nil {
+				m.cancel()
+			}
 			return m, tea.Quit
 		}
 		return m, nil
 
+	case statusMsg:
+		m.message = string(msg)
+		return m, nil
+
 	case spinner.TickMsg:
 		var cmd tea.Cmd
 		m.spinner, cmd = m.spinner.Update(msg)
@@ -66,6 +87,9 @@ func (m SpinnerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 func (m SpinnerModel) View() string {
 	if m.done {
 		checkStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("86")).Bold(true)
+		if m.cancel != nil {
+			return checkStyle.Render(fmt.Sprintf("✓ %s (%s)\n", m.message, m.elapsed()))
+		}
 		return checkStyle.Render(fmt.Sprintf("✓ %s\n", m.message))
 	}
 
@@ -74,9 +98,26 @@ func (m SpinnerModel) View() string {
 	}
 
 	messageStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
-	return fmt.Sprintf("%s %s\n", m.spinner.View(), messageStyle.Render(m.message))
+	view := fmt.Sprintf("%s %s", m.spinner.View(), messageStyle.Render(m.message))
+	if !m.startedAt.IsZero() {
+		hintStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("238"))
+		details := m.elapsed()
+		if m.cancelHint != "" {
+			details += " · " + m.cancelHint
+		}
+		view += hintStyle.Render(fmt.Sprintf(" (%s)", details))
+	}
+	return view + "\n"
+}
+
+// elapsed returns the time since the spinner started, rounded for display
+func (m SpinnerModel) elapsed() string {
+	return time.Since(m.startedAt).Round(time.Second).String()
 }
 
+// statusMsg replaces the message shown next to the spinner
+type statusMsg string
+
 // doneMsg is a message to indicate that the spinner should terminate
 type doneMsg struct{}
 
@@ -86,7 +127,19 @@ func Done() tea.Msg {
 }
 
 // ShowSpinner shows a spinner while executing a function
+// Outside a terminal, or with SetProgressMode, the message is printed as plain lines or JSON instead
 func ShowSpinner(message string, fn func() error) error {
+	mode := resolveProgressMode()
+	if mode != ProgressTUI {
+		return statusRenderers[mode](context.Background(), message, func(context.Context, func(string)) error {
+			return fn()
+		})
+	}
+	return showTUISpinner(message, fn)
+}
+
+// showTUISpinner runs fn under the bubbletea spinner; unlike ShowStatus, fn can't be cancelled
+func showTUISpinner(message string, fn func() error) error {
 	p := tea.NewProgram(NewSpinnerModel(message))
 
 	// Channel to handle the function result
@@ -109,3 +162,41 @@ func ShowSpinner(message string, fn func() error) error {
 	// Get the function result
 	return <-errChan
 }
+
+// ShowStatus shows a spinner with the elapsed time while fn runs
+// fn can update the message through status; cancelling from the keyboard cancels its context.
+// Outside a terminal, or with SetProgressMode, the messages are printed as plain lines or JSON instead
+func ShowStatus(ctx context.Context, message string, fn func(ctx context.Context, status func(message string)) error) error {
+	return statusRenderers[resolveProgressMode()](ctx, message, fn)
+}
+
+// showTUIStatus runs fn under the bubbletea spinner
+func showTUIStatus(ctx context.Context, message string, fn func(ctx context.Context, status func(message string)) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	p := tea.NewProgram(NewStatusModel(message, cancel))
+
+	// Channel to handle the function result
+	errChan := make(chan error, 1)
+
+	// Execute the function in a goroutine
+	go func() {
+		err := fn(ctx, func(message string) {
+			p.Send(statusMsg(message))
+		})
+		errChan <- err
+		p.Send(Done())
+	}()
+
+	// Run the program (this will block until it finishes)
+	if _, err := p.Run(); err != nil {
+		return fmt.Errorf("error running spinner: %w", err)
+	}
+
+	// Get the function result (fn returns promptly once its context is cancelled)
+	if err := <-errChan; err != nil {
+		return err
+	}
+	return ctx.Err()
+}
@@ -344,3 +344,24 @@ func TestSpinnerTickCommand(t *testing.T) {
 	assert.False(t, updatedModel.(SpinnerModel).quitting)
 	assert.NotNil(t, newCmd) // Should return another tick command
 }
+
+func TestStatusModel(t *testing.T) {
+	cancelled := false
+	model := NewStatusModel("Listing accounts", func() { cancelled = true })
+	model.startedAt = time.Now().Add(-3 * time.Second)
+
+	view := model.View()
+	assert.Contains(t, view, "Listing accounts")
+	assert.Contains(t, view, "3s")
+	assert.Contains(t, view, "ctrl+c to cancel")
+
+	updatedModel, _ := model.Update(statusMsg("Fetching roles (1/2 accounts)"))
+	model = updatedModel.(SpinnerModel)
+	assert.Contains(t, model.View(), "Fetching roles (1/2 accounts)")
+
+	updatedModel, cmd := model.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	model = updatedModel.(SpinnerModel)
+	assert.True(t, cancelled)
+	assert.True(t, model.quitting)
+	assert.NotNil(t, cmd)
+}
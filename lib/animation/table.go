@@ -0,0 +1,196 @@
+package animation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Column describes a table column
+type Column struct {
+	Title string
+	// MaxWidth truncates the column values; 0 means no limit
+	MaxWidth int
+}
+
+// Table renders rows as aligned columns for non-interactive listings
+type Table struct {
+	Columns []Column
+	Rows    [][]string
+	// Width is the total width available; when set, the widest columns are truncated to fit
+	Width int
+}
+
+// NewTable creates a table with the given column titles
+func NewTable(titles ...string) *Table {
+	columns := make([]Column, len(titles))
+	for i, title := range titles {
+		columns[i] = Column{Title: title}
+	}
+	return &Table{Columns: columns}
+}
+
+// AddRow appends a row; missing values are rendered empty and extra values are ignored
+func (t *Table) AddRow(values ...string) {
+	row := make([]string, len(t.Columns))
+	copy(row, values)
+	t.Rows = append(t.Rows, row)
+}
+
+// ColumnIndex returns the index of the column with the given title (case-insensitive), or -1
+func (t *Table) ColumnIndex(title string) int {
+	for i, column := range t.Columns {
+		if strings.EqualFold(column.Title, title) {
+			return i
+		}
+	}
+	return -1
+}
+
+// SortBy sorts the rows by a column, keeping the original order of equal values
+func (t *Table) SortBy(column int, descending bool) error {
+	if column < 0 || column >= len(t.Columns) {
+		return fmt.Errorf("invalid sort column %d", column)
+	}
+	sort.SliceStable(t.Rows, func(i, j int) bool {
+		a, b := strings.ToLower(t.Rows[i][column]), strings.ToLower(t.Rows[j][column])
+		if descending {
+			return a > b
+		}
+		return a < b
+	})
+	return nil
+}
+
+// PageCount returns the number of pages for the given page size
+func (t *Table) PageCount(pageSize int) int {
+	if pageSize <= 0 || len(t.Rows) == 0 {
+		return 1
+	}
+	return (len(t.Rows) + pageSize - 1) / pageSize
+}
+
+// Render renders the whole table
+func (t *Table) Render() string {
+	return t.render(t.Rows)
+}
+
+// RenderPage renders one page (starting at 1) followed by a page footer
+// A pageSize of 0 renders every row
+func (t *Table) RenderPage(page, pageSize int) (string, error) {
+	if pageSize <= 0 {
+		return t.Render(), nil
+	}
+
+	pages := t.PageCount(pageSize)
+	if page < 1 || page > pages {
+		return "", fmt.Errorf("page %d out of range (1-%d)", page, pages)
+	}
+
+	start := (page - 1) * pageSize
+	end := start + pageSize
+	if end > len(t.Rows) {
+		end = len(t.Rows)
+	}
+
+	footerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	footer := footerStyle.Render(fmt.Sprintf("Page %d/%d (%d rows)", page, pages, len(t.Rows)))
+	return t.render(t.Rows[start:end]) + "\n" + footer + "\n", nil
+}
+
+func (t *Table) render(rows [][]string) string {
+	const gap = 2
+
+	widths := t.columnWidths(rows)
+
+	headerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("205")).Bold(true)
+
+	var s strings.Builder
+	for i, column := range t.Columns {
+		s.WriteString(headerStyle.Render(pad(truncate(column.Title, widths[i]), widths[i])))
+		if i < len(t.Columns)-1 {
+			s.WriteString(strings.Repeat(" ", gap))
+		}
+	}
+	s.WriteString("\n")
+
+	for _, row := range rows {
+		var line strings.Builder
+		for i := range t.Columns {
+			line.WriteString(pad(truncate(row[i], widths[i]), widths[i]))
+			if i < len(t.Columns)-1 {
+				line.WriteString(strings.Repeat(" ", gap))
+			}
+		}
+		s.WriteString(strings.TrimRight(line.String(), " "))
+		s.WriteString("\n")
+	}
+
+	return s.String()
+}
+
+// columnWidths computes the width of every column, honoring MaxWidth and the table Width
+func (t *Table) columnWidths(rows [][]string) []int {
+	const (
+		gap      = 2
+		minWidth = 4
+	)
+
+	widths := make([]int, len(t.Columns))
+	for i, column := range t.Columns {
+		widths[i] = lipgloss.Width(column.Title)
+		for _, row := range rows {
+			if w := lipgloss.Width(row[i]); w > widths[i] {
+				widths[i] = w
+			}
+		}
+		if column.MaxWidth > 0 && widths[i] > column.MaxWidth {
+			widths[i] = column.MaxWidth
+		}
+	}
+
+	if t.Width <= 0 {
+		return widths
+	}
+
+	// Shrink the widest column until the table fits
+	for {
+		total := gap * (len(widths) - 1)
+		widest := 0
+		for i, w := range widths {
+			total += w
+			if w > widths[widest] {
+				widest = i
+			}
+		}
+		if total <= t.Width || widths[widest] <= minWidth {
+			return widths
+		}
+		widths[widest]--
+	}
+}
+
+// truncate shortens value to width, marking the cut with an ellipsis
+func truncate(value string, width int) string {
+	if lipgloss.Width(value) <= width {
+		return value
+	}
+	runes := []rune(value)
+	if width <= 1 {
+		return string(runes[:width])
+	}
+	for len(runes) > 0 && lipgloss.Width(string(runes))+1 > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
+
+// pad right-pads value with spaces to width
+func pad(value string, width int) string {
+	if w := lipgloss.Width(value); w < width {
+		return value + strings.Repeat(" ", width-w)
+	}
+	return value
+}
@@ -0,0 +1,75 @@
+package animation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTable() *Table {
+	table := NewTable("Name", "Region")
+	table.AddRow("beta", "us-west-2")
+	table.AddRow("alpha", "eu-west-1")
+	table.AddRow("gamma")
+	return table
+}
+
+func TestTableRender(t *testing.T) {
+	table := newTestTable()
+	lines := strings.Split(strings.TrimRight(table.Render(), "\n"), "\n")
+
+	require.Len(t, lines, 4)
+	assert.Contains(t, lines[0], "Name")
+	assert.Contains(t, lines[0], "Region")
+	assert.Equal(t, "beta   us-west-2", lines[1])
+	assert.Equal(t, "gamma", lines[3], "missing values are empty and trailing spaces trimmed")
+}
+
+func TestTableSortBy(t *testing.T) {
+	table := newTestTable()
+
+	require.NoError(t, table.SortBy(table.ColumnIndex("name"), false))
+	assert.Equal(t, "alpha", table.Rows[0][0])
+
+	require.NoError(t, table.SortBy(0, true))
+	assert.Equal(t, "gamma", table.Rows[0][0])
+
+	assert.Error(t, table.SortBy(5, false))
+	assert.Equal(t, -1, table.ColumnIndex("missing"))
+}
+
+func TestTableRenderPage(t *testing.T) {
+	table := newTestTable()
+	assert.Equal(t, 2, table.PageCount(2))
+
+	output, err := table.RenderPage(2, 2)
+	require.NoError(t, err)
+	assert.Contains(t, output, "gamma")
+	assert.NotContains(t, output, "beta")
+	assert.Contains(t, output, "Page 2/2 (3 rows)")
+
+	_, err = table.RenderPage(3, 2)
+	assert.Error(t, err)
+
+	output, err = table.RenderPage(1, 0)
+	require.NoError(t, err)
+	assert.Contains(t, output, "beta")
+	assert.Contains(t, output, "gamma")
+}
+
+func TestTableTruncation(t *testing.T) {
+	table := NewTable("Name", "Region")
+	table.Columns[0].MaxWidth = 6
+	table.AddRow("a-very-long-cluster-name", "us-west-2")
+
+	assert.Contains(t, table.Render(), "a-ver…")
+
+	table = NewTable("Name", "Region")
+	table.Width = 20
+	table.AddRow("a-very-long-cluster-name", "us-west-2")
+	for _, line := range strings.Split(strings.TrimRight(table.Render(), "\n"), "\n") {
+		assert.LessOrEqual(t, len([]rune(line)), 20)
+	}
+}
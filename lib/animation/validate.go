@@ -0,0 +1,57 @@
+package animation
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/andresgarcia29/ark-cli/services/aws/arn"
+)
+
+// Validator checks a value entered in an input prompt
+type Validator func(value string) error
+
+// ValidateRequired rejects empty values
+func ValidateRequired(value string) error {
+	if strings.TrimSpace(value) == "" {
+		return fmt.Errorf("a value is required")
+	}
+	return nil
+}
+
+// ValidateSSOStartURL accepts https URLs such as https://my-org.awsapps.com/start
+func ValidateSSOStartURL(value string) error {
+	parsed, err := url.Parse(value)
+	if err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+		return fmt.Errorf("%q is not a valid SSO start URL (expected https://<org>.awsapps.com/start)", value)
+	}
+	return nil
+}
+
+// ValidateAccountID accepts 12-digit AWS account IDs
+func ValidateAccountID(value string) error {
+	if !arn.ValidAccountID(value) {
+		return fmt.Errorf("%q is not a valid AWS account ID (expected 12 digits)", value)
+	}
+	return nil
+}
+
+// ValidateRoleARN accepts IAM role ARNs such as arn:aws:iam::123456789012:role/Admin
+func ValidateRoleARN(value string) error {
+	if _, err := arn.ParseRole(value); err != nil {
+		return fmt.Errorf("%q is not a valid IAM role ARN (expected arn:aws:iam::<account>:role/<name>)", value)
+	}
+	return nil
+}
+
+// ValidateAll combines validators, returning the first error
+func ValidateAll(validators ...Validator) Validator {
+	return func(value string) error {
+		for _, validate := range validators {
+			if err := validate(value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,46 @@
+package animation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidators(t *testing.T) {
+	tests := []struct {
+		name     string
+		validate Validator
+		value    string
+		valid    bool
+	}{
+		{name: "required", validate: ValidateRequired, value: "x", valid: true},
+		{name: "required blank", validate: ValidateRequired, value: "  ", valid: false},
+		{name: "sso url", validate: ValidateSSOStartURL, value: "https://my-org.awsapps.com/start", valid: true},
+		{name: "sso url http", validate: ValidateSSOStartURL, value: "http://my-org.awsapps.com/start", valid: false},
+		{name: "sso url without scheme", validate: ValidateSSOStartURL, value: "my-org.awsapps.com/start", valid: false},
+		{name: "account id", validate: ValidateAccountID, value: "123456789012", valid: true},
+		{name: "account id short", validate: ValidateAccountID, value: "12345", valid: false},
+		{name: "account id letters", validate: ValidateAccountID, value: "12345678901a", valid: false},
+		{name: "role arn", validate: ValidateRoleARN, value: "arn:aws:iam::123456789012:role/Admin", valid: true},
+		{name: "role arn with path", validate: ValidateRoleARN, value: "arn:aws:iam::123456789012:role/teams/dev-admin", valid: true},
+		{name: "role arn govcloud", validate: ValidateRoleARN, value: "arn:aws-us-gov:iam::123456789012:role/Admin", valid: true},
+		{name: "user arn", validate: ValidateRoleARN, value: "arn:aws:iam::123456789012:user/alice", valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.validate(tt.value)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateAll(t *testing.T) {
+	validate := ValidateAll(ValidateRequired, ValidateAccountID)
+	assert.NoError(t, validate("123456789012"))
+	assert.EqualError(t, validate(""), "a value is required")
+}
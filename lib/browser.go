@@ -1,26 +1,196 @@
 package lib
 
 import (
+	"errors"
 	"fmt"
+	"os"
 	"os/exec"
 	"runtime"
+	"strings"
 )
 
-// OpenBrowser opens the specified URL in the default browser of the operating system
+// Browser shows the pages ark signs in with
+type Browser interface {
+	// Open shows url to the user
+	Open(url string) error
+	// Available reports whether a page opened here can redirect back to ark on this machine,
+	// which signing in without a device code needs
+	Available() bool
+}
+
+// ErrPrintURLOnly is returned by Open when --print-url-only is set: the user opens the printed URL themselves
+var ErrPrintURLOnly = errors.New("opening the browser is disabled by --print-url-only")
+
+// browser is the Browser of the command, set by ConfigureBrowser
+var browser Browser = systemBrowser{}
+
+// ConfigureBrowser picks how the command opens pages: not at all with --print-url-only, else the browser_command
+// of ark's config, else the commands of $BROWSER, else the default browser of the operating system
+func ConfigureBrowser(command string, printURLOnly bool) {
+	browser = newBrowser(command, os.Getenv("BROWSER"), printURLOnly)
+}
+
+// newBrowser is ConfigureBrowser with $BROWSER given
+func newBrowser(command, env string, printURLOnly bool) Browser {
+	switch {
+	case printURLOnly:
+		return printURLBrowser{}
+	case strings.TrimSpace(command) != "":
+		argv, err := splitCommandLine(command)
+		if err != nil {
+			err = fmt.Errorf("invalid browser_command %q: %w", command, err)
+		}
+		return commandBrowser{commands: [][]string{argv}, err: err}
+	case strings.TrimSpace(env) != "":
+		// Like xdg-open and Python's webbrowser, $BROWSER lists commands to try in order
+		var b commandBrowser
+		for _, entry := range strings.Split(env, string(os.PathListSeparator)) {
+			argv, err := splitCommandLine(entry)
+			if err != nil {
+				b.err = fmt.Errorf("invalid $BROWSER %q: %w", env, err)
+				break
+			}
+			if len(argv) > 0 {
+				b.commands = append(b.commands, argv)
+			}
+		}
+		if len(b.commands) > 0 || b.err != nil {
+			return b
+		}
+	}
+	return systemBrowser{}
+}
+
+// OpenBrowser opens the specified URL with the browser of the command
 func OpenBrowser(url string) error {
-	var err error
+	return browser.Open(url)
+}
 
+// BrowserAvailable reports whether the browser of the command can show a page to the user sitting at the terminal
+func BrowserAvailable() bool {
+	return browser.Available()
+}
+
+// systemBrowser is the default browser of the operating system
+type systemBrowser struct{}
+
+func (systemBrowser) Open(url string) error {
 	switch runtime.GOOS {
 	case "linux":
-		err = exec.Command("xdg-open", url).Start()
+		return StartCommand(exec.Command("xdg-open", url))
 	case "windows":
-		err = exec.Command("rundll32", "url.dll,FileProtocolHandler", url).Start()
+		return StartCommand(exec.Command("rundll32", "url.dll,FileProtocolHandler", url))
 	case "darwin":
-		err = exec.Command("open", url).Start()
+		return StartCommand(exec.Command("open", url))
 	default:
-		err = fmt.Errorf("unsupported platform: %s", runtime.GOOS)
+		return fmt.Errorf("unsupported platform: %s", runtime.GOOS)
+	}
+}
+
+// Available is never true over SSH, and on Linux only in a graphical session
+func (systemBrowser) Available() bool {
+	if overSSH() {
+		return false
 	}
+	switch runtime.GOOS {
+	case "darwin", "windows":
+		return true
+	case "linux":
+		return os.Getenv("DISPLAY") != "" || os.Getenv("WAYLAND_DISPLAY") != ""
+	}
+	return false
+}
 
-	return err
+// commandBrowser runs the first command that starts, with %s replaced by the URL or the URL appended,
+// e.g. google-chrome --profile-directory="Profile 2"
+type commandBrowser struct {
+	commands [][]string
+	err      error
 }
 
+func (b commandBrowser) Open(url string) error {
+	if b.err != nil {
+		return b.err
+	}
+	var errs []error
+	for _, command := range b.commands {
+		if err := StartCommand(exec.Command(command[0], browserArgs(command[1:], url)...)); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		return nil
+	}
+	return errors.Join(errs...)
+}
+
+// Available trusts the configured command, except over SSH where the page can't redirect back to ark
+func (b commandBrowser) Available() bool {
+	return b.err == nil && !overSSH()
+}
+
+// printURLBrowser opens nothing, for remote machines: the URL is printed and opened on any device
+type printURLBrowser struct{}
+
+func (printURLBrowser) Open(string) error { return ErrPrintURLOnly }
+
+func (printURLBrowser) Available() bool { return false }
+
+// browserArgs replaces %s in args with url, or appends url when no argument has %s
+func browserArgs(args []string, url string) []string {
+	replaced := make([]string, len(args))
+	found := false
+	for i, arg := range args {
+		if strings.Contains(arg, "%s") {
+			arg = strings.ReplaceAll(arg, "%s", url)
+			found = true
+		}
+		replaced[i] = arg
+	}
+	if !found {
+		replaced = append(replaced, url)
+	}
+	return replaced
+}
+
+// splitCommandLine splits a command into its arguments at spaces, keeping quoted parts together
+// Backslashes are kept as they are, so Windows paths need no escaping
+func splitCommandLine(command string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	var quote rune
+	for _, r := range command {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+// overSSH reports whether the terminal is an SSH session, where browsers open on the remote machine if at all
+func overSSH() bool {
+	return os.Getenv("SSH_CONNECTION") != "" || os.Getenv("SSH_TTY") != ""
+}
@@ -0,0 +1,73 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBrowser(t *testing.T) {
+	t.Setenv("SSH_CONNECTION", "")
+	t.Setenv("SSH_TTY", "")
+
+	assert.Equal(t, printURLBrowser{}, newBrowser("firefox", "chromium", true), "--print-url-only wins")
+	assert.ErrorIs(t, newBrowser("", "", true).Open("https://device.example"), ErrPrintURLOnly)
+	assert.False(t, newBrowser("", "", true).Available())
+
+	assert.Equal(t, commandBrowser{commands: [][]string{{"google-chrome", "--profile-directory=Profile 2"}}},
+		newBrowser(`google-chrome --profile-directory="Profile 2"`, "chromium", false), "browser_command wins over $BROWSER")
+	assert.Equal(t, commandBrowser{commands: [][]string{{"firefox", "-new-tab", "%s"}, {"chromium"}}},
+		newBrowser("", "firefox -new-tab %s"+string(os.PathListSeparator)+"chromium", false))
+	assert.Equal(t, systemBrowser{}, newBrowser(" ", "", false))
+
+	invalid := newBrowser(`chrome "--profile`, "", false)
+	assert.EqualError(t, invalid.Open("https://device.example"), "invalid browser_command \"chrome \\\"--profile\": unterminated \" quote")
+	assert.False(t, invalid.Available())
+
+	assert.True(t, newBrowser("firefox", "", false).Available())
+	t.Setenv("SSH_CONNECTION", "10.0.0.1 52000 10.0.0.2 22")
+	assert.False(t, newBrowser("firefox", "", false).Available(), "the page can't redirect back over SSH")
+}
+
+func TestCommandBrowserOpen(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake browser is a shell script")
+	}
+	dir := t.TempDir()
+	opened := filepath.Join(dir, "opened")
+	script := filepath.Join(dir, "fake-browser")
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\necho \"$@\" > "+opened+"\n"), 0755))
+
+	b := newBrowser("", "ark-no-such-browser"+string(os.PathListSeparator)+script+" --profile %s", false)
+	require.NoError(t, b.Open("https://device.example"))
+	assert.Eventually(t, func() bool {
+		data, err := os.ReadFile(opened)
+		return err == nil && strings.TrimSpace(string(data)) == "--profile https://device.example"
+	}, 5*time.Second, 10*time.Millisecond, "the first command that starts opens the URL")
+
+	assert.Error(t, newBrowser("ark-no-such-browser", "", false).Open("https://device.example"))
+}
+
+func TestBrowserArgs(t *testing.T) {
+	assert.Equal(t, []string{"--new-window", "https://device.example"}, browserArgs([]string{"--new-window"}, "https://device.example"))
+	assert.Equal(t, []string{"--app=https://device.example", "--incognito"}, browserArgs([]string{"--app=%s", "--incognito"}, "https://device.example"))
+}
+
+func TestSplitCommandLine(t *testing.T) {
+	args, err := splitCommandLine(`"C:\Program Files\Google\Chrome\Application\chrome.exe" --profile-directory='Profile 2'  %s`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{`C:\Program Files\Google\Chrome\Application\chrome.exe`, "--profile-directory=Profile 2", "%s"}, args)
+
+	args, err = splitCommandLine(`open -a "" `)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"open", "-a", ""}, args)
+
+	_, err = splitCommandLine(`chrome 'profile`)
+	assert.EqualError(t, err, "unterminated ' quote")
+}
@@ -0,0 +1,115 @@
+package lib
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/andresgarcia29/ark-cli/logs"
+)
+
+// CommandError is an external command that failed, with what is needed to debug it without running it again
+type CommandError struct {
+	Args     []string
+	Duration time.Duration
+	// ExitCode is -1 when the command could not start or was killed
+	ExitCode int
+	Stderr   string
+	Err      error
+}
+
+func (e *CommandError) Error() string {
+	var message string
+	if e.ExitCode > 0 {
+		message = fmt.Sprintf("`%s` exited with status %d after %s", CommandLine(e.Args), e.ExitCode, e.Duration.Round(time.Millisecond))
+	} else {
+		message = fmt.Sprintf("`%s` failed after %s: %v", CommandLine(e.Args), e.Duration.Round(time.Millisecond), e.Err)
+	}
+	if stderr := strings.TrimSpace(e.Stderr); stderr != "" {
+		message += "\nStderr: " + stderr
+	}
+	return message
+}
+
+func (e *CommandError) Unwrap() error { return e.Err }
+
+// RunCommand runs cmd and waits for it, logging its argv, duration, exit code and stderr at debug level
+// Stderr is captured even when cmd.Stderr is set, which still receives it; a failure is a *CommandError
+func RunCommand(cmd *exec.Cmd) error {
+	var stderr bytes.Buffer
+	if cmd.Stderr != nil {
+		cmd.Stderr = io.MultiWriter(cmd.Stderr, &stderr)
+	} else {
+		cmd.Stderr = &stderr
+	}
+
+	start := time.Now()
+	err := cmd.Run()
+	return commandResult(cmd, start, stderr.String(), err)
+}
+
+// StartCommand starts cmd without waiting for it, e.g. a browser, logging its argv at debug level
+func StartCommand(cmd *exec.Cmd) error {
+	start := time.Now()
+	err := cmd.Start()
+	if err != nil {
+		return commandResult(cmd, start, "", err)
+	}
+	logs.GetLogger().Debugw("Started external command", "args", redactArgs(cmd.Args), "pid", cmd.Process.Pid)
+	return nil
+}
+
+// commandResult logs how a command went and turns its error into a *CommandError
+func commandResult(cmd *exec.Cmd, start time.Time, stderr string, err error) error {
+	duration := time.Since(start)
+	exitCode := -1
+	if cmd.ProcessState != nil {
+		exitCode = cmd.ProcessState.ExitCode()
+	}
+	args := redactArgs(cmd.Args)
+	logs.GetLogger().Debugw("Ran external command", "args", args, "duration", duration, "exit_code", exitCode, "stderr", strings.TrimSpace(stderr))
+
+	if err == nil {
+		return nil
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(exitErr.Stderr) == 0 {
+		// Callers telling failures apart by what the tool printed still find it on the *exec.ExitError
+		exitErr.Stderr = []byte(stderr)
+	}
+	return &CommandError{Args: args, Duration: duration, ExitCode: exitCode, Stderr: stderr, Err: err}
+}
+
+// CommandLine joins argv as it would be typed, quoting the arguments a shell would split
+func CommandLine(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\n\"'`$\\") {
+			arg = fmt.Sprintf("%q", arg)
+		}
+		quoted[i] = arg
+	}
+	return strings.Join(quoted, " ")
+}
+
+// redactArgs hides the password of URLs, e.g. a token in the URL of a git repository, before argv is logged
+func redactArgs(args []string) []string {
+	redacted := make([]string, len(args))
+	for i, arg := range args {
+		redacted[i] = arg
+		if !strings.Contains(arg, "@") {
+			continue
+		}
+		if u, err := url.Parse(arg); err == nil && u.User != nil {
+			if _, ok := u.User.Password(); ok {
+				redacted[i] = u.Redacted()
+			}
+		}
+	}
+	return redacted
+}
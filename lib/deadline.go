@@ -0,0 +1,72 @@
+package lib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// parallelOverrides are the --timeout and --parallelism flags; zero keeps the value of each operation
+var parallelOverrides struct {
+	timeout    time.Duration
+	maxWorkers int
+}
+
+// SetParallelOverrides replaces the Timeout and MaxWorkers of every parallel operation of the command,
+// from the --timeout and --parallelism flags. Zero keeps each operation's own value
+func SetParallelOverrides(timeout time.Duration, maxWorkers int) error {
+	if timeout < 0 {
+		return fmt.Errorf("invalid --timeout %s: it must not be negative", timeout)
+	}
+	if maxWorkers < 0 {
+		return fmt.Errorf("invalid --parallelism %d: it must not be negative", maxWorkers)
+	}
+	parallelOverrides.timeout = timeout
+	parallelOverrides.maxWorkers = maxWorkers
+	return nil
+}
+
+// WithOverrides returns the configuration with the --timeout and --parallelism flags applied
+func (c ParallelConfig) WithOverrides() ParallelConfig {
+	if parallelOverrides.timeout > 0 {
+		c.Timeout = parallelOverrides.timeout
+	}
+	if parallelOverrides.maxWorkers > 0 {
+		c.MaxWorkers = parallelOverrides.maxWorkers
+	}
+	return c
+}
+
+// DeadlineError replaces context.DeadlineExceeded when a parallel operation runs out of its time budget,
+// naming the operation and the flags that change it
+type DeadlineError struct {
+	// Phase is what ran out of time, e.g. "the cluster scan"
+	Phase string
+	// Budget is the time it had
+	Budget time.Duration
+	Err    error
+}
+
+func (e *DeadlineError) Error() string {
+	return fmt.Sprintf("%s did not finish within %s (allow more time with --timeout, or run more at once with --parallelism): %v",
+		e.Phase, e.Budget, e.Err)
+}
+
+func (e *DeadlineError) Unwrap() error {
+	return e.Err
+}
+
+// ExplainDeadline turns err into a DeadlineError when the budget of config ran out while ctx, the caller's
+// context, still had time; cancellations and deadlines of the caller are returned as they are
+func ExplainDeadline(ctx context.Context, config ParallelConfig, err error) error {
+	var deadlineErr *DeadlineError
+	if !errors.Is(err, context.DeadlineExceeded) || ctx.Err() != nil || errors.As(err, &deadlineErr) {
+		return err
+	}
+	phase := config.Phase
+	if phase == "" {
+		phase = "the parallel operation"
+	}
+	return &DeadlineError{Phase: phase, Budget: config.Timeout, Err: err}
+}
@@ -0,0 +1,65 @@
+package lib
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetParallelOverrides(t *testing.T) {
+	t.Cleanup(func() { SetParallelOverrides(0, 0) })
+	config := ParallelConfig{MaxWorkers: 5, Timeout: time.Minute}
+
+	assert.Equal(t, config, config.WithOverrides())
+
+	require.NoError(t, SetParallelOverrides(15*time.Minute, 0))
+	assert.Equal(t, ParallelConfig{MaxWorkers: 5, Timeout: 15 * time.Minute}, config.WithOverrides())
+
+	require.NoError(t, SetParallelOverrides(0, 30))
+	assert.Equal(t, ParallelConfig{MaxWorkers: 30, Timeout: time.Minute}, config.WithOverrides())
+
+	assert.Error(t, SetParallelOverrides(-time.Second, 0))
+	assert.Error(t, SetParallelOverrides(0, -1))
+}
+
+func TestExplainDeadline(t *testing.T) {
+	config := ParallelConfig{Timeout: time.Minute, Phase: "the cluster scan"}
+
+	err := ExplainDeadline(context.Background(), config, context.DeadlineExceeded)
+	var deadlineErr *DeadlineError
+	require.ErrorAs(t, err, &deadlineErr)
+	assert.Equal(t, "the cluster scan", deadlineErr.Phase)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, err.Error(), "the cluster scan did not finish within 1m0s")
+	assert.Contains(t, err.Error(), "--timeout")
+
+	// Explained once only
+	assert.Same(t, err, ExplainDeadline(context.Background(), config, err))
+
+	// Other errors, and deadlines of the caller, are left alone
+	other := errors.New("access denied")
+	assert.Equal(t, other, ExplainDeadline(context.Background(), config, other))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+	assert.Equal(t, context.DeadlineExceeded, ExplainDeadline(ctx, config, context.DeadlineExceeded))
+}
+
+func TestProcessAccountsInParallelDeadline(t *testing.T) {
+	config := ParallelConfig{MaxWorkers: 1, Timeout: 20 * time.Millisecond, MaxRetries: 0, Phase: "the slow scan"}
+
+	results, errs := ProcessAccountsInParallel(context.Background(), []string{"111111111111"}, config,
+		func(ctx context.Context, accountID string) (string, error) {
+			<-ctx.Done()
+			return "", ctx.Err()
+		})
+	assert.Empty(t, results)
+	require.NotEmpty(t, errs)
+	var deadlineErr *DeadlineError
+	require.ErrorAs(t, errs[0], &deadlineErr)
+	assert.Equal(t, "the slow scan", deadlineErr.Phase)
+}
@@ -0,0 +1,68 @@
+package lib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// lockPollInterval is how often LockFile tries again while another process holds the lock
+const lockPollInterval = 200 * time.Millisecond
+
+// errLocked is returned by the platform lock when another process holds it
+var errLocked = errors.New("file is locked by another process")
+
+// FileLock is an exclusive lock on a file, held until Unlock or until the process exits,
+// so a crashed process never leaves a stale lock behind
+type FileLock struct {
+	file *os.File
+}
+
+// TryLockFile takes the lock on path, creating the file if needed, without waiting
+// It returns false when another process holds the lock
+func TryLockFile(path string) (*FileLock, bool, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, false, fmt.Errorf("failed to create lock directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to open lock file: %w", err)
+	}
+	if err := lockFile(file); err != nil {
+		file.Close()
+		if errors.Is(err, errLocked) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("failed to lock %s: %w", path, err)
+	}
+	return &FileLock{file: file}, true, nil
+}
+
+// LockFile takes the lock on path, waiting for the process holding it to release it or until ctx ends
+func LockFile(ctx context.Context, path string) (*FileLock, error) {
+	ticker := time.NewTicker(lockPollInterval)
+	defer ticker.Stop()
+	for {
+		lock, ok, err := TryLockFile(path)
+		if err != nil || ok {
+			return lock, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Unlock releases the lock
+func (l *FileLock) Unlock() error {
+	if err := unlockFile(l.file); err != nil {
+		l.file.Close()
+		return err
+	}
+	return l.file.Close()
+}
@@ -0,0 +1,50 @@
+package lib
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTryLockFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "locks", "login.lock")
+
+	lock, ok, err := TryLockFile(path)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// flock and LockFileEx locks belong to the open file, so a second open in the same process is refused too
+	_, ok, err = TryLockFile(path)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, lock.Unlock())
+	lock, ok, err = TryLockFile(path)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.NoError(t, lock.Unlock())
+}
+
+func TestLockFileWaits(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "login.lock")
+	held, ok, err := TryLockFile(path)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = LockFile(ctx, path)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		held.Unlock()
+	}()
+	lock, err := LockFile(context.Background(), path)
+	require.NoError(t, err)
+	require.NoError(t, lock.Unlock())
+}
@@ -0,0 +1,21 @@
+package lib
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// FuzzyMatch reports whether every character of query appears in candidate in order, ignoring case
+// e.g. "prdeu" matches "prod-eu-west-1"; an empty query matches everything
+func FuzzyMatch(query, candidate string) bool {
+	query = strings.ToLower(query)
+	candidate = strings.ToLower(candidate)
+	for _, r := range query {
+		i := strings.IndexRune(candidate, r)
+		if i < 0 {
+			return false
+		}
+		candidate = candidate[i+utf8.RuneLen(r):]
+	}
+	return true
+}
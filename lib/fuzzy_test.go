@@ -0,0 +1,29 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		query     string
+		candidate string
+		expected  bool
+	}{
+		{query: "", candidate: "prod", expected: true},
+		{query: "prod", candidate: "prod-eu-west-1", expected: true},
+		{query: "prdeu", candidate: "prod-eu-west-1", expected: true},
+		{query: "PROD", candidate: "prod", expected: true},
+		{query: "eup", candidate: "prod-eu-west-1", expected: false},
+		{query: "staging", candidate: "stage", expected: false},
+		{query: "ñ", candidate: "españa", expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query+"/"+tt.candidate, func(t *testing.T) {
+			assert.Equal(t, tt.expected, FuzzyMatch(tt.query, tt.candidate))
+		})
+	}
+}
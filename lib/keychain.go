@@ -0,0 +1,121 @@
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// ErrSecretNotFound is returned by ReadSecret when the keychain has no secret for the service and account
+var ErrSecretNotFound = errors.New("secret not found in the keychain")
+
+// windowsSecretNotFound is the exit code of the PowerShell scripts when the vault has no such credential
+const windowsSecretNotFound = 3
+
+// ReadSecret reads a secret from the keychain of the operating system
+// Linux needs secret-tool (libsecret); macOS and Windows use built-in tools
+func ReadSecret(service, account string) (string, error) {
+	cmd, err := keychainReadCommand(runtime.GOOS, service, account)
+	if err != nil {
+		return "", err
+	}
+
+	output, err := cmd.Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && secretNotFound(runtime.GOOS, exitErr) {
+		return "", ErrSecretNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("%s: %w: %s", cmd.Path, err, keychainStderr(err))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// StoreSecret stores a secret in the keychain of the operating system, replacing any previous one
+// The secret is passed on stdin, never as an argument other processes could see
+func StoreSecret(service, account, secret string) error {
+	cmd, err := keychainStoreCommand(runtime.GOOS, service, account, secret)
+	if err != nil {
+		return err
+	}
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %w: %s", cmd.Path, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// keychainReadCommand builds the command printing a secret for an operating system
+func keychainReadCommand(goos, service, account string) (*exec.Cmd, error) {
+	switch goos {
+	case "linux":
+		return exec.Command("secret-tool", "lookup", "service", service, "account", account), nil
+	case "darwin":
+		return exec.Command("security", "find-generic-password", "-s", service, "-a", account, "-w"), nil
+	case "windows":
+		script := fmt.Sprintf(`%s try { $c = $v.Retrieve(%s, %s) } catch { exit %d }; $c.RetrievePassword(); $c.Password`,
+			passwordVaultScript, powerShellString(service), powerShellString(account), windowsSecretNotFound)
+		return exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", script), nil
+	default:
+		return nil, fmt.Errorf("unsupported platform: %s", goos)
+	}
+}
+
+// keychainStoreCommand builds the command storing a secret for an operating system, with the secret on stdin
+func keychainStoreCommand(goos, service, account, secret string) (*exec.Cmd, error) {
+	var cmd *exec.Cmd
+	stdin := secret
+	switch goos {
+	case "linux":
+		cmd = exec.Command("secret-tool", "store", "--label", service+" "+account, "service", service, "account", account)
+	case "darwin":
+		// security -i reads commands from stdin; -U updates the item when it exists
+		cmd = exec.Command("security", "-i")
+		stdin = fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n", securityString(service), securityString(account), securityString(secret))
+	case "windows":
+		script := fmt.Sprintf(`%s $s = [Console]::In.ReadLine(); `+
+			`try { $v.Remove($v.Retrieve(%[2]s, %[3]s)) } catch {}; `+
+			`$v.Add((New-Object Windows.Security.Credentials.PasswordCredential(%[2]s, %[3]s, $s)))`,
+			passwordVaultScript, powerShellString(service), powerShellString(account))
+		cmd = exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", script)
+	default:
+		return nil, fmt.Errorf("unsupported platform: %s", goos)
+	}
+	cmd.Stdin = strings.NewReader(stdin)
+	return cmd, nil
+}
+
+// passwordVaultScript loads the Windows credential locker into $v
+const passwordVaultScript = `[void][Windows.Security.Credentials.PasswordVault, Windows.Security.Credentials, ContentType = WindowsRuntime]; ` +
+	`$v = New-Object Windows.Security.Credentials.PasswordVault;`
+
+// secretNotFound tells a missing secret apart from a keychain failure by the exit code of the tool
+// secret-tool exits with 1 and prints nothing for a missing secret, and with 1 and an error otherwise
+func secretNotFound(goos string, err *exec.ExitError) bool {
+	switch goos {
+	case "linux":
+		return err.ExitCode() == 1 && len(strings.TrimSpace(string(err.Stderr))) == 0
+	case "darwin":
+		// errSecItemNotFound
+		return err.ExitCode() == 44
+	case "windows":
+		return err.ExitCode() == windowsSecretNotFound
+	default:
+		return false
+	}
+}
+
+// keychainStderr returns what the keychain tool printed on stderr, if anything
+func keychainStderr(err error) string {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return strings.TrimSpace(string(exitErr.Stderr))
+	}
+	return ""
+}
+
+// securityString quotes a value for the command line read by security -i
+func securityString(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
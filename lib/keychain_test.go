@@ -0,0 +1,61 @@
+package lib
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeychainReadCommand(t *testing.T) {
+	cmd, err := keychainReadCommand("linux", "ark", "cache-key")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"secret-tool", "lookup", "service", "ark", "account", "cache-key"}, cmd.Args)
+
+	cmd, err = keychainReadCommand("darwin", "ark", "cache-key")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"security", "find-generic-password", "-s", "ark", "-a", "cache-key", "-w"}, cmd.Args)
+
+	cmd, err = keychainReadCommand("windows", "ark", "cache-key")
+	require.NoError(t, err)
+	assert.Contains(t, cmd.Args[len(cmd.Args)-1], `$v.Retrieve('ark', 'cache-key') } catch { exit 3 }`)
+
+	_, err = keychainReadCommand("plan9", "ark", "cache-key")
+	assert.EqualError(t, err, "unsupported platform: plan9")
+}
+
+func TestKeychainStoreCommand(t *testing.T) {
+	tests := []struct {
+		goos      string
+		wantArgs  []string
+		wantStdin string
+	}{
+		{
+			goos:      "linux",
+			wantArgs:  []string{"secret-tool", "store", "--label", "ark cache-key", "service", "ark", "account", "cache-key"},
+			wantStdin: "c2VjcmV0",
+		},
+		{
+			goos:      "darwin",
+			wantArgs:  []string{"security", "-i"},
+			wantStdin: "add-generic-password -U -s \"ark\" -a \"cache-key\" -w \"c2VjcmV0\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.goos, func(t *testing.T) {
+			cmd, err := keychainStoreCommand(tt.goos, "ark", "cache-key", "c2VjcmV0")
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantArgs, cmd.Args, "the secret is never an argument")
+			stdin, err := io.ReadAll(cmd.Stdin)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStdin, string(stdin))
+		})
+	}
+
+	cmd, err := keychainStoreCommand("windows", "ark", "cache-key", "c2VjcmV0")
+	require.NoError(t, err)
+	assert.NotContains(t, cmd.Args[len(cmd.Args)-1], "c2VjcmV0")
+	assert.Contains(t, cmd.Args[len(cmd.Args)-1], `PasswordCredential('ark', 'cache-key', $s)`)
+}
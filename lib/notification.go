@@ -0,0 +1,53 @@
+package lib
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// ShowDesktopNotification shows a native notification with the notifier of the operating system
+// Linux needs notify-send (libnotify); macOS and Windows use built-in tools
+func ShowDesktopNotification(title, message string) error {
+	cmd, err := desktopNotificationCommand(runtime.GOOS, title, message)
+	if err != nil {
+		return err
+	}
+
+	// The Windows balloon stays up while its PowerShell process sleeps, so don't wait for it
+	if runtime.GOOS == "windows" {
+		return StartCommand(cmd)
+	}
+	return RunCommand(cmd)
+}
+
+// desktopNotificationCommand builds the notifier command for an operating system
+func desktopNotificationCommand(goos, title, message string) (*exec.Cmd, error) {
+	switch goos {
+	case "linux":
+		return exec.Command("notify-send", "--app-name=ark", title, message), nil
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(message), appleScriptString(title))
+		return exec.Command("osascript", "-e", script), nil
+	case "windows":
+		script := fmt.Sprintf(`Add-Type -AssemblyName System.Windows.Forms; `+
+			`$n = New-Object System.Windows.Forms.NotifyIcon; `+
+			`$n.Icon = [System.Drawing.SystemIcons]::Information; $n.Visible = $true; `+
+			`$n.ShowBalloonTip(5000, %s, %s, 'Info'); Start-Sleep -Seconds 6; $n.Dispose()`,
+			powerShellString(title), powerShellString(message))
+		return exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", script), nil
+	default:
+		return nil, fmt.Errorf("unsupported platform: %s", goos)
+	}
+}
+
+// appleScriptString quotes a value as an AppleScript string literal
+func appleScriptString(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+// powerShellString quotes a value as a single-quoted PowerShell string, where only ' needs escaping
+func powerShellString(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
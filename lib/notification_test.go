@@ -0,0 +1,40 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDesktopNotificationCommand(t *testing.T) {
+	tests := []struct {
+		goos     string
+		wantArgs []string
+	}{
+		{
+			goos:     "linux",
+			wantArgs: []string{"notify-send", "--app-name=ark", "ark", `Session "prod" expires in 5m`},
+		},
+		{
+			goos:     "darwin",
+			wantArgs: []string{"osascript", "-e", `display notification "Session \"prod\" expires in 5m" with title "ark"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.goos, func(t *testing.T) {
+			cmd, err := desktopNotificationCommand(tt.goos, "ark", `Session "prod" expires in 5m`)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantArgs, cmd.Args)
+		})
+	}
+
+	cmd, err := desktopNotificationCommand("windows", "ark", "it's approved")
+	require.NoError(t, err)
+	assert.Equal(t, "powershell", cmd.Args[0])
+	assert.Contains(t, cmd.Args[len(cmd.Args)-1], `ShowBalloonTip(5000, 'ark', 'it''s approved', 'Info')`)
+
+	_, err = desktopNotificationCommand("plan9", "ark", "hello")
+	assert.EqualError(t, err, "unsupported platform: plan9")
+}
@@ -2,6 +2,7 @@ package lib
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -29,6 +30,9 @@ type ParallelConfig struct {
 
 	// RetryDelay defines how long to wait between retries
 	RetryDelay time.Duration
+
+	// Phase names the operation in the error returned when Timeout runs out, e.g. "the cluster scan"
+	Phase string
 }
 
 // DefaultParallelConfig returns a default configuration optimized for AWS
@@ -64,6 +68,82 @@ func AggressiveConfig() ParallelConfig {
 	}
 }
 
+// SSOPortalConfig returns the configuration for calls to the SSO portal API (ListAccountRoles, GetRoleCredentials)
+// The portal throttles per access token, so tasks start at a steady 10 per second; the SDK retries throttled calls
+func SSOPortalConfig() ParallelConfig {
+	return ParallelConfig{
+		MaxWorkers:     10,                     // Enough in-flight calls to keep up with the start rate
+		Timeout:        10 * time.Minute,       // Organizations with hundreds of accounts
+		RateLimitDelay: 100 * time.Millisecond, // 10 calls per second
+		MaxRetries:     2,                      // Few retries, the SDK already retries throttling
+		RetryDelay:     1 * time.Second,        // Time for the throttling to clear
+		Phase:          "the SSO portal calls",
+	}
+}
+
+// Service identifies the AWS API a parallel operation mostly calls, so it can be paced by that API's limits
+type Service string
+
+const (
+	// ServiceSSO is the SSO portal API (ListAccountRoles, GetRoleCredentials), throttled per access token
+	ServiceSSO Service = "sso"
+	// ServiceSTS is AssumeRole, with a large per-account quota shared by every caller of the source account
+	ServiceSTS Service = "sts"
+	// ServiceEKS is the EKS control plane API (ListClusters, DescribeCluster), about 10 calls per second per account and region
+	ServiceEKS Service = "eks"
+	// ServiceOrganizations is the Organizations API, a few calls per second for the whole organization
+	ServiceOrganizations Service = "organizations"
+	// ServiceResourceExplorer is the Resource Explorer Search API
+	ServiceResourceExplorer Service = "resource-explorer"
+)
+
+// ServiceConfig returns the configuration tuned for the throttling limits of service
+// Unknown services get ConservativeConfig
+func ServiceConfig(service Service) ParallelConfig {
+	switch service {
+	case ServiceSSO:
+		return SSOPortalConfig()
+	case ServiceSTS:
+		return ParallelConfig{
+			MaxWorkers:     20,                    // STS answers quickly and allows hundreds of calls per second
+			Timeout:        5 * time.Minute,       // Role sessions are fast to create
+			RateLimitDelay: 20 * time.Millisecond, // 50 calls per second, leaving room for other callers of the account
+			MaxRetries:     3,                     // Throttling clears quickly
+			RetryDelay:     1 * time.Second,       // Time for the throttling to clear
+			Phase:          "the role sessions",
+		}
+	case ServiceEKS:
+		return ParallelConfig{
+			MaxWorkers:     10,                     // Each account and region has its own quota
+			Timeout:        10 * time.Minute,       // Scans of many accounts and regions
+			RateLimitDelay: 100 * time.Millisecond, // 10 calls per second, the limit of one account and region
+			MaxRetries:     3,                      // Throttling and transient network errors
+			RetryDelay:     2 * time.Second,        // The EKS bucket refills slowly
+			Phase:          "the EKS calls",
+		}
+	case ServiceOrganizations:
+		return ParallelConfig{
+			MaxWorkers:     2,                      // The quota is shared by the whole organization
+			Timeout:        10 * time.Minute,       // Organizations with hundreds of accounts
+			RateLimitDelay: 250 * time.Millisecond, // 4 calls per second
+			MaxRetries:     5,                      // Organizations throttles readily
+			RetryDelay:     2 * time.Second,        // Time for the throttling to clear
+			Phase:          "the AWS Organizations calls",
+		}
+	case ServiceResourceExplorer:
+		return ParallelConfig{
+			MaxWorkers:     1,                      // Searches are paginated, one page at a time
+			Timeout:        5 * time.Minute,        // One search covers every account
+			RateLimitDelay: 500 * time.Millisecond, // Search allows a few calls per second
+			MaxRetries:     5,                      // Retries are cheap next to a full scan
+			RetryDelay:     2 * time.Second,        // Time for the throttling to clear
+			Phase:          "the Resource Explorer search",
+		}
+	default:
+		return ConservativeConfig()
+	}
+}
+
 // WorkerPool represents a worker pool for executing tasks in parallel
 type WorkerPool struct {
 	// maxWorkers controls how many goroutines can execute simultaneously
@@ -167,6 +247,15 @@ func ExecuteWithRetry(ctx context.Context, config ParallelConfig, operation func
 			return nil
 		}
 
+		// Permanent errors will fail the same way again, so stop retrying
+		var permanent *PermanentError
+		if errors.As(err, &permanent) {
+			logger.Debugw("Operation failed with a permanent error, not retrying",
+				"attempt", attempt+1,
+				"error", permanent.Err)
+			return permanent.Err
+		}
+
 		// Save the error to report it if all attempts fail
 		lastErr = err
 
@@ -185,6 +274,27 @@ func ExecuteWithRetry(ctx context.Context, config ParallelConfig, operation func
 	return fmt.Errorf("operation failed after %d attempts: %w", config.MaxRetries+1, lastErr)
 }
 
+// PermanentError marks an error that ExecuteWithRetry must not retry
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// Permanent wraps err so ExecuteWithRetry returns it immediately instead of retrying
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &PermanentError{Err: err}
+}
+
 // RateLimiter controls the execution rate of operations
 type RateLimiter struct {
 	// delay is the wait time between operations
@@ -253,6 +363,8 @@ func ProcessAccountsInParallel[T any](
 	config ParallelConfig,
 	processor func(ctx context.Context, accountID string) (T, error),
 ) (map[string]T, []error) {
+	// --timeout and --parallelism apply to every parallel operation
+	config = config.WithOverrides()
 
 	// Create a context with timeout for the entire operation
 	// If the operation takes longer than the configured timeout, it will be cancelled automatically
@@ -377,13 +489,19 @@ func ProcessAccountsInParallel[T any](
 	for result := range resultChan {
 		if result.Error != nil {
 			// If there was an error, add it to the error list
-			errors = append(errors, fmt.Errorf("account %s: %w", result.AccountID, result.Error))
+			// Accounts cut off by the time budget say which budget ran out and how to raise it
+			errors = append(errors, fmt.Errorf("account %s: %w", result.AccountID, ExplainDeadline(ctx, config, result.Error)))
 		} else {
 			// If successful, add the result to the map
 			results[result.AccountID] = result.Data.(T)
 		}
 	}
 
+	// Accounts still waiting for a worker when the budget ran out send no result at all
+	if missing := len(accounts) - len(results) - len(errors); missing > 0 && timeoutCtx.Err() != nil {
+		errors = append(errors, fmt.Errorf("%d account(s) not processed: %w", missing, ExplainDeadline(ctx, config, timeoutCtx.Err())))
+	}
+
 	logger.Infow("Parallel processing completed",
 		"successful", len(results),
 		"errors", len(errors))
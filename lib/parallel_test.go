@@ -39,6 +39,35 @@ func TestAggressiveConfig(t *testing.T) {
 	assert.Equal(t, 500*time.Millisecond, config.RetryDelay)
 }
 
+func TestSSOPortalConfig(t *testing.T) {
+	config := SSOPortalConfig()
+
+	assert.Equal(t, 10, config.MaxWorkers)
+	assert.Equal(t, 10*time.Minute, config.Timeout)
+	assert.Equal(t, 100*time.Millisecond, config.RateLimitDelay)
+	assert.Equal(t, 2, config.MaxRetries)
+	assert.Equal(t, 1*time.Second, config.RetryDelay)
+}
+
+func TestServiceConfig(t *testing.T) {
+	assert.Equal(t, SSOPortalConfig(), ServiceConfig(ServiceSSO))
+	assert.Equal(t, ConservativeConfig(), ServiceConfig(Service("unknown")))
+
+	sts := ServiceConfig(ServiceSTS)
+	eks := ServiceConfig(ServiceEKS)
+	organizations := ServiceConfig(ServiceOrganizations)
+	assert.Equal(t, 100*time.Millisecond, eks.RateLimitDelay)
+	assert.Less(t, sts.RateLimitDelay, eks.RateLimitDelay, "STS allows more calls per second than EKS")
+	assert.Greater(t, organizations.RateLimitDelay, eks.RateLimitDelay, "Organizations allows fewer calls per second than EKS")
+
+	for _, service := range []Service{ServiceSSO, ServiceSTS, ServiceEKS, ServiceOrganizations, ServiceResourceExplorer} {
+		config := ServiceConfig(service)
+		assert.Positive(t, config.MaxWorkers, service)
+		assert.Positive(t, config.Timeout, service)
+		assert.Positive(t, config.MaxRetries, service)
+	}
+}
+
 func TestNewWorkerPool(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -236,6 +265,21 @@ func TestExecuteWithRetry(t *testing.T) {
 	}
 }
 
+func TestExecuteWithRetryPermanentError(t *testing.T) {
+	config := ParallelConfig{MaxRetries: 3, RetryDelay: 1 * time.Millisecond}
+	notFound := errors.New("not found")
+	attempts := 0
+
+	err := ExecuteWithRetry(context.Background(), config, func() error {
+		attempts++
+		return Permanent(notFound)
+	})
+
+	assert.Equal(t, 1, attempts)
+	assert.Equal(t, notFound, err)
+	assert.Nil(t, Permanent(nil))
+}
+
 func TestExecuteWithRetryContextCancellation(t *testing.T) {
 	config := ParallelConfig{MaxRetries: 5, RetryDelay: 100 * time.Millisecond}
 	ctx, cancel := context.WithCancel(context.Background())
@@ -0,0 +1,180 @@
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// WriteTarget is a file ark is about to write, and the modes it should have
+type WriteTarget struct {
+	Path string
+	// FileMode is the expected mode of the file, e.g. 0600 for credentials
+	FileMode fs.FileMode
+	// DirMode is the expected mode of the directory holding it, e.g. 0700 for ~/.aws
+	DirMode fs.FileMode
+}
+
+// PermissionIssue is a problem found on a write target
+type PermissionIssue struct {
+	// Path is the file or directory with the problem
+	Path string
+	// Mode is the current mode and Want the expected one; both are zero when Fixable is false
+	Mode fs.FileMode
+	Want fs.FileMode
+	// Fixable is true when a chmod to Want solves the issue
+	Fixable bool
+	// Err explains what is wrong
+	Err error
+}
+
+// String describes the issue in one line
+func (i PermissionIssue) String() string {
+	if i.Fixable {
+		return fmt.Sprintf("%s: %v (mode %04o, expected %04o)", i.Path, i.Err, i.Mode.Perm(), i.Want.Perm())
+	}
+	return fmt.Sprintf("%s: %v", i.Path, i.Err)
+}
+
+// Permission problems reported by CheckWriteTargets
+var (
+	ErrTooPermissive = errors.New("readable by other users")
+	ErrNotWritable   = errors.New("not writable")
+)
+
+// CheckWriteTargets verifies that every target can be written and that existing files and directories
+// are not more open than expected. Nothing is modified
+func CheckWriteTargets(targets []WriteTarget) []PermissionIssue {
+	var issues []PermissionIssue
+	seen := make(map[string]bool)
+	add := func(issue *PermissionIssue) {
+		if issue != nil && !seen[issue.Path] {
+			seen[issue.Path] = true
+			issues = append(issues, *issue)
+		}
+	}
+
+	for _, target := range targets {
+		dir := filepath.Dir(target.Path)
+		if _, err := os.Stat(dir); err == nil {
+			add(checkMode(dir, target.DirMode))
+			add(checkDirWritable(dir))
+		} else {
+			// The directory will be created: its closest existing parent must accept it
+			add(checkDirWritable(existingParent(dir)))
+			continue
+		}
+
+		if _, err := os.Stat(target.Path); err == nil {
+			add(checkMode(target.Path, target.FileMode))
+			add(checkFileWritable(target.Path))
+		}
+	}
+	return issues
+}
+
+// FixPermissions applies the expected modes of the fixable issues
+func FixPermissions(issues []PermissionIssue) error {
+	for _, issue := range issues {
+		if !issue.Fixable {
+			continue
+		}
+		if err := os.Chmod(issue.Path, issue.Want); err != nil {
+			return fmt.Errorf("failed to fix permissions of %s: %w", issue.Path, err)
+		}
+	}
+	return nil
+}
+
+// CheckPrivateFile reports a file holding secrets that users other than its owner can access
+// Windows has no such mode bits, so nothing is reported there
+func CheckPrivateFile(path string) *PermissionIssue {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil
+	}
+	if mode := info.Mode().Perm(); mode&0077 != 0 {
+		return &PermissionIssue{Path: path, Mode: mode, Want: 0600, Fixable: isOwnedByCurrentUser(info), Err: ErrTooPermissive}
+	}
+	return nil
+}
+
+// WritePrivateFile writes a file only its owner can read, restricting an existing file that was more open
+func WritePrivateFile(path string, data []byte) error {
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return err
+	}
+	// WriteFile keeps the mode of a file that already exists
+	return os.Chmod(path, 0600)
+}
+
+// checkMode reports a path whose mode grants more than want, or lacks owner write
+// Windows has no such mode bits, so only writability is checked there
+func checkMode(path string, want fs.FileMode) *PermissionIssue {
+	if want == 0 || runtime.GOOS == "windows" {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil
+	}
+
+	mode := info.Mode().Perm()
+	switch {
+	case mode&0200 == 0:
+		return &PermissionIssue{Path: path, Mode: mode, Want: want, Fixable: isOwnedByCurrentUser(info), Err: ErrNotWritable}
+	case mode&^want != 0:
+		return &PermissionIssue{Path: path, Mode: mode, Want: want, Fixable: isOwnedByCurrentUser(info), Err: ErrTooPermissive}
+	}
+	return nil
+}
+
+// checkDirWritable creates and removes a temporary file, which also catches read-only file systems and ACLs
+func checkDirWritable(dir string) *PermissionIssue {
+	file, err := os.CreateTemp(dir, ".ark-write-check-*")
+	if err != nil {
+		return &PermissionIssue{Path: dir, Err: fmt.Errorf("%w: %v", ErrNotWritable, unwrapPathError(err))}
+	}
+	file.Close()
+	os.Remove(file.Name())
+	return nil
+}
+
+// checkFileWritable opens a file for writing without truncating it
+func checkFileWritable(path string) *PermissionIssue {
+	file, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return &PermissionIssue{Path: path, Err: fmt.Errorf("%w: %v", ErrNotWritable, unwrapPathError(err))}
+	}
+	file.Close()
+	return nil
+}
+
+// existingParent returns the closest ancestor of dir that exists
+func existingParent(dir string) string {
+	for {
+		if _, err := os.Stat(dir); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dir
+		}
+		dir = parent
+	}
+}
+
+// unwrapPathError drops the operation and path of an *fs.PathError, which the issue already names
+func unwrapPathError(err error) error {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		return pathErr.Err
+	}
+	return err
+}
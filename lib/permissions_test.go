@@ -0,0 +1,119 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckWriteTargetsTooPermissive(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("modes are not checked on Windows")
+	}
+
+	dir := filepath.Join(t.TempDir(), ".aws")
+	require.NoError(t, os.Mkdir(dir, 0755))
+	path := filepath.Join(dir, "credentials")
+	require.NoError(t, os.WriteFile(path, []byte("[default]\n"), 0644))
+	require.NoError(t, os.Chmod(dir, 0755))
+	require.NoError(t, os.Chmod(path, 0644))
+
+	issues := CheckWriteTargets([]WriteTarget{{Path: path, FileMode: 0600, DirMode: 0700}})
+	require.Len(t, issues, 2)
+	assert.Equal(t, dir, issues[0].Path)
+	assert.Equal(t, path, issues[1].Path)
+	for _, issue := range issues {
+		assert.True(t, issue.Fixable)
+		assert.ErrorIs(t, issue.Err, ErrTooPermissive)
+	}
+	assert.Contains(t, issues[1].String(), "mode 0644, expected 0600")
+
+	require.NoError(t, FixPermissions(issues))
+	assert.Empty(t, CheckWriteTargets([]WriteTarget{{Path: path, FileMode: 0600, DirMode: 0700}}))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestCheckWriteTargetsReadOnlyFile(t *testing.T) {
+	if runtime.GOOS == "windows" || os.Getuid() == 0 {
+		t.Skip("root can write read-only files")
+	}
+
+	path := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, os.WriteFile(path, []byte("apiVersion: v1\n"), 0400))
+
+	issues := CheckWriteTargets([]WriteTarget{{Path: path, FileMode: 0600}})
+	require.NotEmpty(t, issues)
+	assert.True(t, issues[0].Fixable)
+	assert.ErrorIs(t, issues[0].Err, ErrNotWritable)
+	assert.Equal(t, os.FileMode(0600), issues[0].Want)
+}
+
+func TestCheckWriteTargetsReadOnlyDirectory(t *testing.T) {
+	if runtime.GOOS == "windows" || os.Getuid() == 0 {
+		t.Skip("root can write read-only directories")
+	}
+
+	home := t.TempDir()
+	require.NoError(t, os.Chmod(home, 0500))
+	t.Cleanup(func() { os.Chmod(home, 0700) })
+
+	// ~/.aws does not exist yet and can't be created
+	issues := CheckWriteTargets([]WriteTarget{{Path: filepath.Join(home, ".aws", "config"), FileMode: 0600, DirMode: 0700}})
+	require.Len(t, issues, 1)
+	assert.Equal(t, home, issues[0].Path)
+	assert.False(t, issues[0].Fixable)
+	assert.ErrorIs(t, issues[0].Err, ErrNotWritable)
+}
+
+func TestCheckWriteTargetsMissingFile(t *testing.T) {
+	home := t.TempDir()
+
+	// Neither the directory nor the file exist: the writable parent is enough
+	assert.Empty(t, CheckWriteTargets([]WriteTarget{{Path: filepath.Join(home, ".kube", "config"), FileMode: 0600, DirMode: 0700}}))
+}
+
+func TestCheckPrivateFile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("modes are not checked on Windows")
+	}
+
+	path := filepath.Join(t.TempDir(), "token.json")
+	assert.Nil(t, CheckPrivateFile(path), "a missing file has nothing to leak")
+
+	require.NoError(t, WritePrivateFile(path, []byte("{}")))
+	assert.Nil(t, CheckPrivateFile(path))
+
+	for _, mode := range []os.FileMode{0644, 0640, 0606, 0400 | 0004} {
+		require.NoError(t, os.Chmod(path, mode))
+		issue := CheckPrivateFile(path)
+		require.NotNil(t, issue, "mode %04o", mode)
+		assert.ErrorIs(t, issue.Err, ErrTooPermissive)
+		assert.Equal(t, mode, issue.Mode)
+		assert.Equal(t, os.FileMode(0600), issue.Want)
+		assert.True(t, issue.Fixable)
+	}
+
+	// A read-only file of its owner is private
+	require.NoError(t, os.Chmod(path, 0400))
+	assert.Nil(t, CheckPrivateFile(path))
+
+	// Rewriting restricts a file that was more open
+	require.NoError(t, os.Chmod(path, 0644))
+	require.NoError(t, WritePrivateFile(path, []byte("{}")))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestExistingParent(t *testing.T) {
+	home := t.TempDir()
+	assert.Equal(t, home, existingParent(filepath.Join(home, "a", "b", "c")))
+	assert.Equal(t, home, existingParent(home))
+}
@@ -0,0 +1,15 @@
+//go:build !windows
+
+package lib
+
+import (
+	"io/fs"
+	"os"
+	"syscall"
+)
+
+// isOwnedByCurrentUser reports whether chmod on the file can succeed without privileges
+func isOwnedByCurrentUser(info fs.FileInfo) bool {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	return ok && int(stat.Uid) == os.Getuid()
+}
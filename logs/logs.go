@@ -23,11 +23,12 @@ type LogConfig struct {
 }
 
 // DefaultLogConfig returns a default logging configuration
+// Logs go to stderr, keeping stdout for what commands print for other programs
 func DefaultLogConfig() LogConfig {
 	return LogConfig{
 		Level:      "info",
 		Format:     "console",
-		OutputPath: "stdout",
+		OutputPath: "stderr",
 	}
 }
 
@@ -0,0 +1,170 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	ark_config "github.com/andresgarcia29/ark-cli/config"
+	"github.com/andresgarcia29/ark-cli/lib"
+	"github.com/andresgarcia29/ark-cli/logs"
+)
+
+// Kinds of notified events
+const (
+	// EventScanFinished is sent when a multi-account scan (ark aws sso, ark kubernetes setup) completes
+	EventScanFinished = "scan.finished"
+	// EventScanFailed is sent when a multi-account scan stops with an error
+	EventScanFailed = "scan.failed"
+)
+
+// slackPostMessageURL is the Slack Web API method used by the Slack notifier
+const slackPostMessageURL = "https://slack.com/api/chat.postMessage"
+
+// maxResponseSize bounds how much of a notification response is read
+const maxResponseSize = 64 << 10
+
+// Message is one notification
+// Text is a complete human-readable sentence, so chat integrations can show it as is
+type Message struct {
+	Time     time.Time `json:"time"`
+	Event    string    `json:"event"`
+	Hostname string    `json:"hostname"`
+	Text     string    `json:"text"`
+}
+
+// Notifier delivers messages to one destination
+type Notifier interface {
+	Notify(ctx context.Context, message Message) error
+}
+
+// Webhook POSTs messages as JSON to a URL
+type Webhook struct {
+	URL    string
+	Client *http.Client
+}
+
+// Notify implements Notifier
+func (w *Webhook) Notify(ctx context.Context, message Message) error {
+	resp, err := postJSON(ctx, w.Client, w.URL, "", message)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseSize))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("POST %s: %s", w.URL, resp.Status)
+	}
+	return nil
+}
+
+// Slack posts messages to a channel with a bot token
+type Slack struct {
+	Token   string
+	Channel string
+	Client  *http.Client
+	// APIURL overrides the chat.postMessage endpoint, for tests
+	APIURL string
+}
+
+// Notify implements Notifier
+func (s *Slack) Notify(ctx context.Context, message Message) error {
+	url := s.APIURL
+	if url == "" {
+		url = slackPostMessageURL
+	}
+
+	resp, err := postJSON(ctx, s.Client, url, s.Token, map[string]string{
+		"channel": s.Channel,
+		"text":    message.Text,
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Slack answers 200 with ok=false for API errors such as an invalid token or channel
+	var result struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(&result); err != nil {
+		return fmt.Errorf("slack: %s: %w", resp.Status, err)
+	}
+	if !result.OK {
+		return fmt.Errorf("slack: %s", result.Error)
+	}
+	return nil
+}
+
+// FromConfig returns the notifiers configured in the notifications section
+func FromConfig(settings ark_config.NotificationsConfig, client *http.Client) []Notifier {
+	var notifiers []Notifier
+	if settings.Webhook != "" {
+		notifiers = append(notifiers, &Webhook{URL: settings.Webhook, Client: client})
+	}
+	if settings.Slack.Token != "" && settings.Slack.Channel != "" {
+		notifiers = append(notifiers, &Slack{Token: settings.Slack.Token, Channel: settings.Slack.Channel, Client: client})
+	}
+	return notifiers
+}
+
+// Send delivers a message to every configured notifier
+// Failures are logged, never returned, so a notification can't fail the operation it reports
+func Send(ctx context.Context, message Message) {
+	notifiers := FromConfig(ark_config.Get().Notifications, &http.Client{Timeout: ark_config.Get().AWS.Timeout})
+	if len(notifiers) == 0 {
+		return
+	}
+
+	if message.Time.IsZero() {
+		message.Time = time.Now().UTC()
+	}
+	if message.Hostname == "" {
+		message.Hostname, _ = os.Hostname()
+	}
+	for _, notifier := range notifiers {
+		if err := notifier.Notify(ctx, message); err != nil {
+			logs.GetLogger().Warnw("Failed to send notification", "event", message.Event, "error", err)
+		}
+	}
+}
+
+// Desktop shows a native notification when notifications.desktop is set
+// It is meant for events a user may miss while the terminal is in the background; failures are only logged
+func Desktop(title, text string) {
+	if !ark_config.Get().Notifications.Desktop {
+		return
+	}
+	if err := lib.ShowDesktopNotification(title, text); err != nil {
+		logs.GetLogger().Warnw("Failed to show desktop notification", "title", title, "error", err)
+	}
+}
+
+// postJSON POSTs body as JSON, with a bearer token when one is given
+func postJSON(ctx context.Context, client *http.Client, url, token string, body any) (*http.Response, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return client.Do(req)
+}
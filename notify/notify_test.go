@@ -0,0 +1,82 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	ark_config "github.com/andresgarcia29/ark-cli/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookNotify(t *testing.T) {
+	var received Message
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Empty(t, r.Header.Get("Authorization"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer server.Close()
+
+	webhook := &Webhook{URL: server.URL}
+	require.NoError(t, webhook.Notify(context.Background(), Message{Event: EventScanFinished, Text: "done"}))
+	assert.Equal(t, EventScanFinished, received.Event)
+	assert.Equal(t, "done", received.Text)
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	assert.ErrorContains(t, (&Webhook{URL: failing.URL}).Notify(context.Background(), Message{}), "500")
+}
+
+func TestSlackNotify(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		wantErr  string
+	}{
+		{name: "posted", response: `{"ok":true}`},
+		{name: "api error", response: `{"ok":false,"error":"channel_not_found"}`, wantErr: "slack: channel_not_found"},
+		{name: "not json", response: `<html>`, wantErr: "slack: 200 OK"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body map[string]string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "Bearer xoxb-test", r.Header.Get("Authorization"))
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+				_, _ = w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			slack := &Slack{Token: "xoxb-test", Channel: "#platform", APIURL: server.URL}
+			err := slack.Notify(context.Background(), Message{Text: "scan finished"})
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, map[string]string{"channel": "#platform", "text": "scan finished"}, body)
+		})
+	}
+}
+
+func TestFromConfig(t *testing.T) {
+	assert.Empty(t, FromConfig(ark_config.NotificationsConfig{}, nil))
+
+	// A Slack token without a channel has nowhere to post
+	assert.Empty(t, FromConfig(ark_config.NotificationsConfig{Slack: ark_config.SlackConfig{Token: "xoxb-test"}}, nil))
+
+	notifiers := FromConfig(ark_config.NotificationsConfig{
+		Webhook: "https://hooks.example.com/ark",
+		Slack:   ark_config.SlackConfig{Token: "xoxb-test", Channel: "#platform"},
+	}, nil)
+	require.Len(t, notifiers, 2)
+	assert.IsType(t, &Webhook{}, notifiers[0])
+	assert.IsType(t, &Slack{}, notifiers[1])
+}
@@ -0,0 +1,117 @@
+package paths
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// appName names ark's directories inside the platform base directories
+const appName = "ark"
+
+// Dirs are the directories ark keeps its own files in
+type Dirs struct {
+	// Config holds config.yaml
+	Config string
+	// Cache holds data that can be rebuilt by calling AWS or reading kubeconfigs again
+	Cache string
+	// State holds what ark remembers between runs, e.g. the previous context
+	State string
+	// Logs holds the audit log
+	Logs string
+}
+
+// Get resolves ark's directories for the current platform
+// A ~/.ark directory created by earlier versions keeps being used, with its cache and state subdirectories,
+// so upgrading never loses the config or the audit log
+func Get() (Dirs, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return Dirs{}, fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return resolve(runtime.GOOS, home, os.Getenv), nil
+}
+
+// ConfigDir returns the directory of ark's config file
+func ConfigDir() (string, error) {
+	dirs, err := Get()
+	return dirs.Config, err
+}
+
+// CacheDir returns the directory where ark caches discovery data
+func CacheDir() (string, error) {
+	dirs, err := Get()
+	return dirs.Cache, err
+}
+
+// StateDir returns the directory where ark remembers state between runs
+func StateDir() (string, error) {
+	dirs, err := Get()
+	return dirs.State, err
+}
+
+// LogDir returns the directory of ark's log files
+func LogDir() (string, error) {
+	dirs, err := Get()
+	return dirs.Logs, err
+}
+
+// resolve picks the directories of a platform:
+//   - Linux and other Unix systems follow the XDG base directories (~/.config, ~/.cache and ~/.local/state by default),
+//     with logs in the state directory as the spec suggests
+//   - macOS uses ~/Library/Application Support, ~/Library/Caches and ~/Library/Logs, unless XDG variables are set
+//   - Windows uses %APPDATA% for config and state and %LOCALAPPDATA% for the cache and logs
+func resolve(goos, home string, getenv func(string) string) Dirs {
+	legacy := filepath.Join(home, "."+appName)
+	if info, err := os.Stat(legacy); err == nil && info.IsDir() {
+		return Dirs{Config: legacy, Cache: filepath.Join(legacy, "cache"), State: filepath.Join(legacy, "state"), Logs: legacy}
+	}
+
+	// xdg returns $name/ark, or fallback/ark when the variable is unset or not absolute, as the spec requires
+	xdg := func(name, fallback string) string {
+		if dir := getenv(name); filepath.IsAbs(dir) {
+			return filepath.Join(dir, appName)
+		}
+		return filepath.Join(fallback, appName)
+	}
+
+	switch goos {
+	case "windows":
+		roaming := getenv("APPDATA")
+		if roaming == "" {
+			roaming = filepath.Join(home, "AppData", "Roaming")
+		}
+		local := getenv("LOCALAPPDATA")
+		if local == "" {
+			local = filepath.Join(home, "AppData", "Local")
+		}
+		return Dirs{
+			Config: filepath.Join(roaming, appName),
+			Cache:  filepath.Join(local, appName, "cache"),
+			State:  filepath.Join(roaming, appName, "state"),
+			Logs:   filepath.Join(local, appName, "logs"),
+		}
+	case "darwin":
+		support := filepath.Join(home, "Library", "Application Support")
+		dirs := Dirs{
+			Config: xdg("XDG_CONFIG_HOME", support),
+			Cache:  xdg("XDG_CACHE_HOME", filepath.Join(home, "Library", "Caches")),
+			State:  filepath.Join(support, appName, "state"),
+			Logs:   filepath.Join(home, "Library", "Logs", appName),
+		}
+		if filepath.IsAbs(getenv("XDG_STATE_HOME")) {
+			dirs.State = xdg("XDG_STATE_HOME", "")
+			dirs.Logs = dirs.State
+		}
+		return dirs
+	default:
+		state := xdg("XDG_STATE_HOME", filepath.Join(home, ".local", "state"))
+		return Dirs{
+			Config: xdg("XDG_CONFIG_HOME", filepath.Join(home, ".config")),
+			Cache:  xdg("XDG_CACHE_HOME", filepath.Join(home, ".cache")),
+			State:  state,
+			Logs:   state,
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package paths
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolve(t *testing.T) {
+	home := t.TempDir()
+	noEnv := func(string) string { return "" }
+	env := func(values map[string]string) func(string) string {
+		return func(name string) string { return values[name] }
+	}
+
+	tests := []struct {
+		name     string
+		goos     string
+		getenv   func(string) string
+		expected Dirs
+	}{
+		{
+			name:   "linux defaults",
+			goos:   "linux",
+			getenv: noEnv,
+			expected: Dirs{
+				Config: filepath.Join(home, ".config", "ark"),
+				Cache:  filepath.Join(home, ".cache", "ark"),
+				State:  filepath.Join(home, ".local", "state", "ark"),
+				Logs:   filepath.Join(home, ".local", "state", "ark"),
+			},
+		},
+		{
+			name: "linux xdg variables",
+			goos: "linux",
+			getenv: env(map[string]string{
+				"XDG_CONFIG_HOME": "/xdg/config",
+				"XDG_CACHE_HOME":  "/xdg/cache",
+				// Relative paths are invalid per the spec and ignored
+				"XDG_STATE_HOME": "relative/state",
+			}),
+			expected: Dirs{
+				Config: filepath.Join("/xdg/config", "ark"),
+				Cache:  filepath.Join("/xdg/cache", "ark"),
+				State:  filepath.Join(home, ".local", "state", "ark"),
+				Logs:   filepath.Join(home, ".local", "state", "ark"),
+			},
+		},
+		{
+			name:   "macos defaults",
+			goos:   "darwin",
+			getenv: noEnv,
+			expected: Dirs{
+				Config: filepath.Join(home, "Library", "Application Support", "ark"),
+				Cache:  filepath.Join(home, "Library", "Caches", "ark"),
+				State:  filepath.Join(home, "Library", "Application Support", "ark", "state"),
+				Logs:   filepath.Join(home, "Library", "Logs", "ark"),
+			},
+		},
+		{
+			name:   "macos xdg variables",
+			goos:   "darwin",
+			getenv: env(map[string]string{"XDG_STATE_HOME": "/xdg/state"}),
+			expected: Dirs{
+				Config: filepath.Join(home, "Library", "Application Support", "ark"),
+				Cache:  filepath.Join(home, "Library", "Caches", "ark"),
+				State:  filepath.Join("/xdg/state", "ark"),
+				Logs:   filepath.Join("/xdg/state", "ark"),
+			},
+		},
+		{
+			name:   "windows",
+			goos:   "windows",
+			getenv: env(map[string]string{"APPDATA": "/appdata/roaming", "LOCALAPPDATA": "/appdata/local"}),
+			expected: Dirs{
+				Config: filepath.Join("/appdata/roaming", "ark"),
+				Cache:  filepath.Join("/appdata/local", "ark", "cache"),
+				State:  filepath.Join("/appdata/roaming", "ark", "state"),
+				Logs:   filepath.Join("/appdata/local", "ark", "logs"),
+			},
+		},
+		{
+			name:   "windows without variables",
+			goos:   "windows",
+			getenv: noEnv,
+			expected: Dirs{
+				Config: filepath.Join(home, "AppData", "Roaming", "ark"),
+				Cache:  filepath.Join(home, "AppData", "Local", "ark", "cache"),
+				State:  filepath.Join(home, "AppData", "Roaming", "ark", "state"),
+				Logs:   filepath.Join(home, "AppData", "Local", "ark", "logs"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, resolve(tt.goos, home, tt.getenv))
+		})
+	}
+}
+
+func TestResolveLegacyDir(t *testing.T) {
+	home := t.TempDir()
+
+	// A .ark project file in the home directory is not the legacy directory
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".ark"), []byte("profile: dev\n"), 0600))
+	assert.Equal(t, filepath.Join(home, ".config", "ark"), resolve("linux", home, func(string) string { return "" }).Config)
+	require.NoError(t, os.Remove(filepath.Join(home, ".ark")))
+
+	require.NoError(t, os.Mkdir(filepath.Join(home, ".ark"), 0700))
+	expected := Dirs{
+		Config: filepath.Join(home, ".ark"),
+		Cache:  filepath.Join(home, ".ark", "cache"),
+		State:  filepath.Join(home, ".ark", "state"),
+		Logs:   filepath.Join(home, ".ark"),
+	}
+	for _, goos := range []string{"linux", "darwin", "windows"} {
+		assert.Equal(t, expected, resolve(goos, home, func(string) string { return "/xdg" }), goos)
+	}
+}
+
+func TestGet(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, "xdg-cache"))
+
+	cache, err := CacheDir()
+	require.NoError(t, err)
+	if runtime.GOOS != "windows" {
+		assert.Equal(t, filepath.Join(home, "xdg-cache", "ark"), cache)
+	}
+}
@@ -0,0 +1,182 @@
+package services_aws
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// AccountExportFormat is a tool `ark accounts export` writes configuration for
+type AccountExportFormat string
+
+const (
+	// AccountExportAWSNuke is the accounts, regions and blocklist of an aws-nuke config file
+	AccountExportAWSNuke AccountExportFormat = "aws-nuke"
+	// AccountExportSteampipe is a connection per account for the Steampipe AWS plugin, plus an aggregator
+	AccountExportSteampipe AccountExportFormat = "steampipe"
+)
+
+// ParseAccountExportFormat validates the value of --format
+func ParseAccountExportFormat(value string) (AccountExportFormat, error) {
+	switch format := AccountExportFormat(value); format {
+	case AccountExportAWSNuke, AccountExportSteampipe:
+		return format, nil
+	}
+	return "", fmt.Errorf("unknown format %q (expected aws-nuke or steampipe)", value)
+}
+
+// AccountExport is what an export is made of: the accounts and roles of a portal, already filtered
+type AccountExport struct {
+	StartURL string
+	Profiles []AWSProfile
+	Regions  []string
+	// Blocklist are globs of the account IDs or names aws-nuke must never touch; matching accounts are
+	// only listed in its blocklist
+	Blocklist []string
+	// ProfileNames are the names of the profiles in the AWS config, keyed by "<account ID>/<role>";
+	// other roles get the name bootstrap would give them
+	ProfileNames map[string]string
+}
+
+// ExportAccounts renders the configuration of format for the accounts of export
+func ExportAccounts(format AccountExportFormat, export AccountExport) (string, error) {
+	for _, pattern := range export.Blocklist {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return "", fmt.Errorf("invalid blocklist pattern %q: %w", pattern, err)
+		}
+	}
+	switch format {
+	case AccountExportAWSNuke:
+		return exportAWSNuke(export), nil
+	case AccountExportSteampipe:
+		return exportSteampipe(export)
+	}
+	return "", fmt.Errorf("unknown format %q (expected aws-nuke or steampipe)", format)
+}
+
+// exportAccounts lists each account of the profiles once, sorted by ID
+func exportAccounts(profiles []AWSProfile) []AWSProfile {
+	var accounts []AWSProfile
+	for _, profile := range profiles {
+		if !slices.ContainsFunc(accounts, func(a AWSProfile) bool { return a.AccountID == profile.AccountID }) {
+			accounts = append(accounts, profile)
+		}
+	}
+	slices.SortFunc(accounts, func(a, b AWSProfile) int { return strings.Compare(a.AccountID, b.AccountID) })
+	return accounts
+}
+
+// exportAWSNuke writes the regions, blocklist and accounts of an aws-nuke (v3) config file
+// aws-nuke refuses to run with an empty blocklist, which is left for the user to fill rather than guessed
+func exportAWSNuke(export AccountExport) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# aws-nuke accounts generated by `ark accounts export` from %s\n", export.StartURL)
+	b.WriteString("# Review the blocklist: aws-nuke deletes every resource of the accounts it runs against\n")
+
+	b.WriteString("regions:\n  - global\n")
+	for _, region := range export.Regions {
+		fmt.Fprintf(&b, "  - %s\n", region)
+	}
+
+	var blocked, allowed []AWSProfile
+	for _, account := range exportAccounts(export.Profiles) {
+		if matchesAnyGlobFold(account.AccountID, export.Blocklist) || matchesAnyGlobFold(account.AccountName, export.Blocklist) {
+			blocked = append(blocked, account)
+		} else {
+			allowed = append(allowed, account)
+		}
+	}
+
+	if len(blocked) == 0 {
+		b.WriteString("blocklist: [] # add the accounts that must never be nuked, e.g. production\n")
+	} else {
+		b.WriteString("blocklist:\n")
+		for _, account := range blocked {
+			fmt.Fprintf(&b, "  - %q # %s\n", account.AccountID, account.AccountName)
+		}
+	}
+
+	if len(allowed) == 0 {
+		b.WriteString("accounts: {}\n")
+		return b.String()
+	}
+	b.WriteString("accounts:\n")
+	for _, account := range allowed {
+		fmt.Fprintf(&b, "  %q: {} # %s\n", account.AccountID, account.AccountName)
+	}
+	return b.String()
+}
+
+// exportSteampipe writes a connection per account signing in with the profile of its role, and an aggregator
+// querying them all. An account with several roles is an error: the role the queries run as must be chosen
+func exportSteampipe(export AccountExport) (string, error) {
+	byAccount := make(map[string][]AWSProfile)
+	for _, profile := range export.Profiles {
+		byAccount[profile.AccountID] = append(byAccount[profile.AccountID], profile)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Steampipe connections generated by `ark accounts export` from %s\n", export.StartURL)
+	regions := make([]string, len(export.Regions))
+	for i, region := range export.Regions {
+		regions[i] = fmt.Sprintf("%q", region)
+	}
+
+	names := map[string]string{"aws_all": "the aggregator"}
+	for _, account := range exportAccounts(export.Profiles) {
+		roles := byAccount[account.AccountID]
+		if len(roles) > 1 {
+			roleNames := make([]string, len(roles))
+			for i, role := range roles {
+				roleNames[i] = role.RoleName
+			}
+			slices.Sort(roleNames)
+			return "", fmt.Errorf("account %s (%s) has %d roles (%s): pick one with --roles", account.AccountID, account.AccountName, len(roles), strings.Join(roleNames, ", "))
+		}
+
+		name := steampipeConnectionName(account)
+		if other, ok := names[name]; ok {
+			return "", fmt.Errorf("accounts %s and %s would both be connection %s", other, account.AccountID, name)
+		}
+		names[name] = account.AccountID
+
+		profileName := export.ProfileNames[account.AccountID+"/"+account.RoleName]
+		if profileName == "" {
+			profileName = ProfileNameFor(account)
+		}
+		fmt.Fprintf(&b, "\n# %s (%s)\n", account.AccountName, account.AccountID)
+		fmt.Fprintf(&b, "connection %q {\n", name)
+		b.WriteString("  plugin  = \"aws\"\n")
+		fmt.Fprintf(&b, "  profile = %q\n", profileName)
+		fmt.Fprintf(&b, "  regions = [%s]\n", strings.Join(regions, ", "))
+		b.WriteString("}\n")
+	}
+
+	b.WriteString("\n# Queries every account at once\n")
+	b.WriteString("connection \"aws_all\" {\n")
+	b.WriteString("  plugin      = \"aws\"\n")
+	b.WriteString("  type        = \"aggregator\"\n")
+	b.WriteString("  connections = [\"aws_*\"]\n")
+	b.WriteString("}\n")
+	return b.String(), nil
+}
+
+// steampipeConnectionName names the connection of an account aws_<account name>, in the lowercase letters,
+// digits and underscores Steampipe allows
+func steampipeConnectionName(account AWSProfile) string {
+	name := account.AccountName
+	if name == "" {
+		name = account.AccountID
+	}
+	var b strings.Builder
+	b.WriteString("aws_")
+	for _, r := range strings.ToLower(name) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
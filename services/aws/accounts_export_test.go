@@ -0,0 +1,102 @@
+package services_aws
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAccountExportFormat(t *testing.T) {
+	format, err := ParseAccountExportFormat("steampipe")
+	require.NoError(t, err)
+	assert.Equal(t, AccountExportSteampipe, format)
+
+	_, err = ParseAccountExportFormat("cloudquery")
+	assert.EqualError(t, err, `unknown format "cloudquery" (expected aws-nuke or steampipe)`)
+}
+
+func TestExportAccountsAWSNuke(t *testing.T) {
+	export := AccountExport{
+		StartURL: "https://example.awsapps.com/start",
+		Profiles: []AWSProfile{
+			{AccountID: "222222222222", AccountName: "sandbox", RoleName: "AdministratorAccess"},
+			{AccountID: "111111111111", AccountName: "prod", RoleName: "ReadOnlyAccess"},
+			{AccountID: "222222222222", AccountName: "sandbox", RoleName: "ReadOnlyAccess"},
+		},
+		Regions:   []string{"us-east-1", "eu-west-1"},
+		Blocklist: []string{"PROD*"},
+	}
+	exported, err := ExportAccounts(AccountExportAWSNuke, export)
+	require.NoError(t, err)
+	assert.Equal(t, `# aws-nuke accounts generated by `+"`ark accounts export`"+` from https://example.awsapps.com/start
+# Review the blocklist: aws-nuke deletes every resource of the accounts it runs against
+regions:
+  - global
+  - us-east-1
+  - eu-west-1
+blocklist:
+  - "111111111111" # prod
+accounts:
+  "222222222222": {} # sandbox
+`, exported)
+
+	// Without a blocklist, it is left for the user to fill
+	export.Blocklist = nil
+	exported, err = ExportAccounts(AccountExportAWSNuke, export)
+	require.NoError(t, err)
+	assert.Contains(t, exported, "blocklist: [] # add the accounts that must never be nuked")
+	assert.Contains(t, exported, `"111111111111": {} # prod`)
+
+	export.Blocklist = []string{"["}
+	_, err = ExportAccounts(AccountExportAWSNuke, export)
+	assert.ErrorContains(t, err, `invalid blocklist pattern "["`)
+}
+
+func TestExportAccountsSteampipe(t *testing.T) {
+	export := AccountExport{
+		StartURL: "https://example.awsapps.com/start",
+		Profiles: []AWSProfile{
+			{AccountID: "111111111111", AccountName: "Payments-Prod", RoleName: "ReadOnlyAccess"},
+			{AccountID: "222222222222", AccountName: "sandbox", RoleName: "ReadOnlyAccess"},
+		},
+		Regions:      []string{"us-east-1", "eu-west-1"},
+		ProfileNames: map[string]string{"111111111111/ReadOnlyAccess": "payments-prod-ro"},
+	}
+	exported, err := ExportAccounts(AccountExportSteampipe, export)
+	require.NoError(t, err)
+	assert.Equal(t, `# Steampipe connections generated by `+"`ark accounts export`"+` from https://example.awsapps.com/start
+
+# Payments-Prod (111111111111)
+connection "aws_payments_prod" {
+  plugin  = "aws"
+  profile = "payments-prod-ro"
+  regions = ["us-east-1", "eu-west-1"]
+}
+
+# sandbox (222222222222)
+connection "aws_sandbox" {
+  plugin  = "aws"
+  profile = "`+ProfileNameFor(export.Profiles[1])+`"
+  regions = ["us-east-1", "eu-west-1"]
+}
+
+# Queries every account at once
+connection "aws_all" {
+  plugin      = "aws"
+  type        = "aggregator"
+  connections = ["aws_*"]
+}
+`, exported)
+
+	export.Profiles = append(export.Profiles, AWSProfile{AccountID: "222222222222", AccountName: "sandbox", RoleName: "AdministratorAccess"})
+	_, err = ExportAccounts(AccountExportSteampipe, export)
+	assert.EqualError(t, err, "account 222222222222 (sandbox) has 2 roles (AdministratorAccess, ReadOnlyAccess): pick one with --roles")
+
+	export.Profiles = []AWSProfile{
+		{AccountID: "111111111111", AccountName: "payments.prod", RoleName: "ReadOnlyAccess"},
+		{AccountID: "222222222222", AccountName: "payments-prod", RoleName: "ReadOnlyAccess"},
+	}
+	_, err = ExportAccounts(AccountExportSteampipe, export)
+	assert.EqualError(t, err, "accounts 111111111111 and 222222222222 would both be connection aws_payments_prod")
+}
@@ -0,0 +1,118 @@
+// Package arn parses and validates the ARNs ark reads from profiles, role lists, flags and kubeconfigs
+package arn
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	accountIDPattern = regexp.MustCompile(`^\d{12}$`)
+	// IAM role names and paths allow letters, digits and +=,.@_-
+	roleNamePattern = regexp.MustCompile(`^[\w+=,.@-]+$`)
+)
+
+// ARN is an Amazon Resource Name, arn:<partition>:<service>:<region>:<account>:<resource>
+type ARN struct {
+	Partition string
+	Service   string
+	Region    string
+	AccountID string
+	Resource  string
+}
+
+// String returns the ARN as text
+func (a ARN) String() string {
+	return strings.Join([]string{"arn", a.Partition, a.Service, a.Region, a.AccountID, a.Resource}, ":")
+}
+
+// Parse splits an ARN into its fields; only the shape is checked, the resource may contain colons
+func Parse(text string) (ARN, error) {
+	parts := strings.SplitN(text, ":", 6)
+	if len(parts) != 6 || parts[0] != "arn" || !strings.HasPrefix(parts[1], "aws") || parts[2] == "" || parts[5] == "" {
+		return ARN{}, fmt.Errorf("%q is not an ARN (expected arn:aws:<service>:<region>:<account>:<resource>)", text)
+	}
+	return ARN{Partition: parts[1], Service: parts[2], Region: parts[3], AccountID: parts[4], Resource: parts[5]}, nil
+}
+
+// AccountID returns the account of an ARN, empty when text isn't one
+func AccountID(text string) string {
+	parsed, err := Parse(text)
+	if err != nil {
+		return ""
+	}
+	return parsed.AccountID
+}
+
+// RoleName returns the name of an IAM role ARN without its path, empty when the resource isn't a role
+// Only the shape is read, so it also reads the odd role_arn of a hand-written profile; ParseRole validates
+func (a ARN) RoleName() string {
+	resource, ok := strings.CutPrefix(a.Resource, "role/")
+	if a.Service != "iam" || !ok {
+		return ""
+	}
+	return resource[strings.LastIndex(resource, "/")+1:]
+}
+
+// ValidAccountID reports whether id is a 12-digit AWS account ID
+func ValidAccountID(id string) bool {
+	return accountIDPattern.MatchString(id)
+}
+
+// Role is an IAM role ARN, arn:aws:iam::<account>:role/[path/]<name>
+type Role struct {
+	ARN
+	// Path is the role's IAM path, "/" unless the role was created under one such as /teams/platform/
+	Path string
+	Name string
+}
+
+// ParseRole parses and validates an IAM role ARN
+func ParseRole(text string) (Role, error) {
+	parsed, err := Parse(text)
+	if err != nil || parsed.Service != "iam" || parsed.Region != "" {
+		return Role{}, fmt.Errorf("%q is not a valid IAM role ARN (expected arn:aws:iam::<account>:role/<name>)", text)
+	}
+	resource, ok := strings.CutPrefix(parsed.Resource, "role/")
+	if !ok {
+		return Role{}, fmt.Errorf("%q is not a valid IAM role ARN (expected arn:aws:iam::<account>:role/<name>)", text)
+	}
+	if !ValidAccountID(parsed.AccountID) {
+		return Role{}, fmt.Errorf("%q is not a valid IAM role ARN: account ID %q is not 12 digits", text, parsed.AccountID)
+	}
+
+	role := Role{ARN: parsed, Path: "/", Name: resource}
+	if i := strings.LastIndex(resource, "/"); i >= 0 {
+		role.Path = "/" + resource[:i+1]
+		role.Name = resource[i+1:]
+		for _, segment := range strings.Split(resource[:i], "/") {
+			if !roleNamePattern.MatchString(segment) {
+				return Role{}, fmt.Errorf("%q is not a valid IAM role ARN: invalid path %q", text, role.Path)
+			}
+		}
+	}
+	if !roleNamePattern.MatchString(role.Name) {
+		return Role{}, fmt.Errorf("%q is not a valid IAM role ARN: invalid role name %q", text, role.Name)
+	}
+	return role, nil
+}
+
+// Cluster is an EKS cluster ARN, arn:aws:eks:<region>:<account>:cluster/<name>
+type Cluster struct {
+	ARN
+	Name string
+}
+
+// ParseCluster parses an EKS cluster ARN
+func ParseCluster(text string) (Cluster, error) {
+	parsed, err := Parse(text)
+	if err != nil || parsed.Service != "eks" {
+		return Cluster{}, fmt.Errorf("%q is not an EKS cluster ARN (expected arn:aws:eks:<region>:<account>:cluster/<name>)", text)
+	}
+	name, ok := strings.CutPrefix(parsed.Resource, "cluster/")
+	if !ok || name == "" || parsed.Region == "" || parsed.AccountID == "" {
+		return Cluster{}, fmt.Errorf("%q is not an EKS cluster ARN (expected arn:aws:eks:<region>:<account>:cluster/<name>)", text)
+	}
+	return Cluster{ARN: parsed, Name: name}, nil
+}
@@ -0,0 +1,110 @@
+package arn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	parsed, err := Parse("arn:aws:eks:us-east-1:111111111111:cluster/prod")
+	require.NoError(t, err)
+	assert.Equal(t, ARN{Partition: "aws", Service: "eks", Region: "us-east-1", AccountID: "111111111111", Resource: "cluster/prod"}, parsed)
+	assert.Equal(t, "arn:aws:eks:us-east-1:111111111111:cluster/prod", parsed.String())
+
+	parsed, err = Parse("arn:aws:logs:us-east-1:111111111111:log-group:/aws/eks/prod:*")
+	require.NoError(t, err)
+	assert.Equal(t, "log-group:/aws/eks/prod:*", parsed.Resource, "the resource keeps its colons")
+
+	parsed, err = Parse("arn:aws-us-gov:iam::111111111111:role/Admin")
+	require.NoError(t, err)
+	assert.Equal(t, "aws-us-gov", parsed.Partition)
+
+	for _, text := range []string{"", "not-an-arn", "arn:aws:iam::111111111111", "arn:gcp:iam::111111111111:role/Admin", "arn:aws::us-east-1:111111111111:x", "arn:aws:iam::111111111111:"} {
+		_, err := Parse(text)
+		assert.Error(t, err, text)
+	}
+}
+
+func TestAccountID(t *testing.T) {
+	assert.Equal(t, "111111111111", AccountID("arn:aws:eks:us-west-2:111111111111:cluster/prod"))
+	assert.Empty(t, AccountID(""))
+	assert.Empty(t, AccountID("prod"))
+
+	assert.True(t, ValidAccountID("111111111111"))
+	for _, id := range []string{"", "1111", "11111111111a", "1111111111111"} {
+		assert.False(t, ValidAccountID(id), id)
+	}
+}
+
+func TestParseRole(t *testing.T) {
+	tests := []struct {
+		text string
+		path string
+		name string
+	}{
+		{text: "arn:aws:iam::111111111111:role/Admin", path: "/", name: "Admin"},
+		{text: "arn:aws:iam::111111111111:role/teams/platform/Deploy", path: "/teams/platform/", name: "Deploy"},
+		{text: "arn:aws:iam::111111111111:role/service-role/AWS+EKS=Role,v1.0@org_x", path: "/service-role/", name: "AWS+EKS=Role,v1.0@org_x"},
+		{text: "arn:aws-cn:iam::111111111111:role/Admin", path: "/", name: "Admin"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			role, err := ParseRole(tt.text)
+			require.NoError(t, err)
+			assert.Equal(t, "111111111111", role.AccountID)
+			assert.Equal(t, tt.path, role.Path)
+			assert.Equal(t, tt.name, role.Name)
+			assert.Equal(t, tt.text, role.String())
+		})
+	}
+
+	invalid := map[string]string{
+		"arn:aws:iam::111111111111:user/alice":           "not a valid IAM role ARN",
+		"arn:aws:sts::111111111111:assumed-role/Admin/x": "not a valid IAM role ARN",
+		"arn:aws:iam:us-east-1:111111111111:role/Admin":  "not a valid IAM role ARN",
+		"arn:aws:iam::1111:role/Admin":                   "account ID \"1111\" is not 12 digits",
+		"arn:aws:iam::111111111111:role/":                "invalid role name",
+		"arn:aws:iam::111111111111:role/Ad min":          "invalid role name",
+		"arn:aws:iam::111111111111:role/team a/Admin":    "invalid path",
+		"arn:aws:iam::111111111111:role/teams//Admin":    "invalid path",
+		"Admin": "not a valid IAM role ARN",
+	}
+	for text, message := range invalid {
+		_, err := ParseRole(text)
+		assert.ErrorContains(t, err, message, text)
+	}
+}
+
+func TestRoleName(t *testing.T) {
+	for text, name := range map[string]string{
+		"arn:aws:iam::111111111111:role/Admin":         "Admin",
+		"arn:aws:iam::789:role/path/to/Deploy":         "Deploy",
+		"arn:aws:iam::111111111111:user/alice":         "",
+		"arn:aws:sts::111111111111:assumed-role/Admin": "",
+	} {
+		parsed, err := Parse(text)
+		require.NoError(t, err)
+		assert.Equal(t, name, parsed.RoleName(), text)
+	}
+}
+
+func TestParseCluster(t *testing.T) {
+	cluster, err := ParseCluster("arn:aws-cn:eks:cn-north-1:111111111111:cluster/prod-cn")
+	require.NoError(t, err)
+	assert.Equal(t, "prod-cn", cluster.Name)
+	assert.Equal(t, "cn-north-1", cluster.Region)
+	assert.Equal(t, "111111111111", cluster.AccountID)
+
+	for _, text := range []string{
+		"arn:aws:eks:us-east-1:111111111111:nodegroup/prod/ng/abc",
+		"arn:aws:ec2:us-east-1:111111111111:cluster/prod",
+		"arn:aws:eks:us-east-1:111111111111:cluster/",
+		"arn:aws:eks::111111111111:cluster/prod",
+		"prod",
+	} {
+		_, err := ParseCluster(text)
+		assert.Error(t, err, text)
+	}
+}
@@ -0,0 +1,181 @@
+package services_aws
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/andresgarcia29/ark-cli/services/aws/arn"
+)
+
+// AssumeRoleTarget is a role listed for assume-role profiles, from a line "<role-arn> [account-name]"
+type AssumeRoleTarget struct {
+	RoleARN     string
+	AccountID   string
+	AccountName string
+	RoleName    string
+}
+
+// AssumeRoleProfile is an assume-role profile to write to the AWS config
+type AssumeRoleProfile struct {
+	Name          string
+	Target        AssumeRoleTarget
+	SourceProfile string
+	// Region is written only when set, otherwise the profile uses the default region
+	Region string
+}
+
+// ParseRoleList parses a list of role ARNs, one per line with an optional account name after it
+// Blank lines and lines starting with # are skipped; a role listed twice is kept once
+func ParseRoleList(data string) ([]AssumeRoleTarget, error) {
+	var targets []AssumeRoleTarget
+	seen := make(map[string]bool)
+	for number, line := range strings.Split(data, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if len(fields) > 2 {
+			return nil, fmt.Errorf("line %d: expected a role ARN and an optional account name, got %q", number+1, strings.TrimSpace(line))
+		}
+
+		role, err := arn.ParseRole(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", number+1, err)
+		}
+		if seen[fields[0]] {
+			continue
+		}
+		seen[fields[0]] = true
+
+		target := AssumeRoleTarget{RoleARN: fields[0], AccountID: role.AccountID, RoleName: role.Name}
+		if len(fields) == 2 {
+			target.AccountName = fields[1]
+		}
+		targets = append(targets, target)
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no role ARNs in the list")
+	}
+	return targets, nil
+}
+
+// NameAssumeRoleProfiles names a profile for every target from a profile name template (fields of
+// ProfileNameData); an empty template names them account-role, after the account name when listed, else its ID
+// Templates that give two roles the same name are rejected, since one would overwrite the other
+func NameAssumeRoleProfiles(targets []AssumeRoleTarget, sourceProfile, region, text string) ([]AssumeRoleProfile, error) {
+	profiles := make([]AssumeRoleProfile, 0, len(targets))
+	owners := make(map[string]string, len(targets))
+	for _, target := range targets {
+		var name string
+		if text == "" {
+			accountName := target.AccountName
+			if accountName == "" {
+				accountName = target.AccountID
+			}
+			name = generateProfileName(accountName, target.RoleName)
+		} else {
+			var err error
+			name, err = RenderProfileName(text, ProfileNameData{
+				AccountID:   target.AccountID,
+				AccountName: target.AccountName,
+				RoleName:    target.RoleName,
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+		if previous, ok := owners[name]; ok {
+			return nil, fmt.Errorf("%s and %s would both be named %s; add fields such as {{.AccountID}} to the template",
+				previous, target.RoleARN, name)
+		}
+		if name == sourceProfile {
+			return nil, fmt.Errorf("%s would be named %s, like its source profile", target.RoleARN, name)
+		}
+		owners[name] = target.RoleARN
+		profiles = append(profiles, AssumeRoleProfile{Name: name, Target: target, SourceProfile: sourceProfile, Region: region})
+	}
+	return profiles, nil
+}
+
+// DiffAssumeRoleProfiles compares the assume-role profiles with the ones in ~/.aws/config; nothing is removed
+// Profiles of the same name that aren't assume-role profiles are refused rather than turned into one
+func DiffAssumeRoleProfiles(profiles []AssumeRoleProfile) (ProfileDiff, error) {
+	var diff ProfileDiff
+
+	configPath, err := ConfigFilePath()
+	if err != nil {
+		return diff, err
+	}
+	existing := map[string]ProfileConfig{}
+	data, err := os.ReadFile(configPath)
+	if err != nil && !os.IsNotExist(err) {
+		return diff, fmt.Errorf("failed to read config file: %w", err)
+	}
+	if err == nil {
+		parsed, err := parseAllProfilesFromConfigData(data)
+		if err != nil {
+			return diff, fmt.Errorf("failed to parse config file: %w", err)
+		}
+		for _, profile := range parsed {
+			existing[profile.ProfileName] = profile
+		}
+	}
+
+	for _, profile := range profiles {
+		current, ok := existing[profile.Name]
+		if !ok {
+			diff.Added = append(diff.Added, profile.Name)
+			continue
+		}
+		if current.ProfileType != ProfileTypeAssumeRole {
+			return diff, fmt.Errorf("profile %s already exists and is not an assume-role profile (type: %s); use --name-template to pick other names",
+				profile.Name, current.ProfileType)
+		}
+		var changes []string
+		settings := []struct{ key, old, new string }{
+			{"role_arn", current.RoleARN, profile.Target.RoleARN},
+			{"source_profile", current.SourceProfile, profile.SourceProfile},
+		}
+		if profile.Region != "" {
+			settings = append(settings, struct{ key, old, new string }{"region", current.Region, profile.Region})
+		}
+		for _, setting := range settings {
+			if setting.old != setting.new {
+				changes = append(changes, fmt.Sprintf("%s: %s → %s", setting.key, valueOrNone(setting.old), setting.new))
+			}
+		}
+		if len(changes) == 0 {
+			diff.Unchanged++
+			continue
+		}
+		diff.Updated = append(diff.Updated, ProfileChange{Name: profile.Name, Changes: changes})
+	}
+
+	slices.Sort(diff.Added)
+	slices.SortFunc(diff.Updated, func(a, b ProfileChange) int { return strings.Compare(a.Name, b.Name) })
+	return diff, nil
+}
+
+// WriteAssumeRoleProfiles writes the assume-role profiles to the AWS config file, marked as written by ark
+// Keys added by hand (output, duration_seconds...) and the other profiles are kept as they are
+func WriteAssumeRoleProfiles(profiles []AssumeRoleProfile) error {
+	configPath, doc, err := readConfigDocument()
+	if err != nil {
+		return err
+	}
+	for _, profile := range profiles {
+		section := doc.upsert("profile "+profile.Name, false)
+		section.setComment(arkManagedComment, arkManagedComment)
+		section.set("role_arn", profile.Target.RoleARN)
+		section.set("source_profile", profile.SourceProfile)
+		if profile.Region != "" {
+			section.set("region", profile.Region)
+		}
+	}
+	if err := writeFileAtomic(configPath, []byte(doc.String()), 0600); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
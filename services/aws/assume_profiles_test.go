@@ -0,0 +1,112 @@
+package services_aws
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRoleList(t *testing.T) {
+	targets, err := ParseRoleList(`# Roles published by the platform team
+arn:aws:iam::111111111111:role/ReadOnly dev
+
+arn:aws:iam::222222222222:role/teams/platform/Deploy
+arn:aws:iam::111111111111:role/ReadOnly dev
+`)
+	require.NoError(t, err)
+	assert.Equal(t, []AssumeRoleTarget{
+		{RoleARN: "arn:aws:iam::111111111111:role/ReadOnly", AccountID: "111111111111", AccountName: "dev", RoleName: "ReadOnly"},
+		{RoleARN: "arn:aws:iam::222222222222:role/teams/platform/Deploy", AccountID: "222222222222", RoleName: "Deploy"},
+	}, targets)
+
+	for _, list := range []string{
+		"arn:aws:iam::111111111111:user/alice",
+		"arn:aws:iam::1111:role/ReadOnly",
+		"arn:aws:iam::111111111111:role/",
+		"arn:aws:iam::111111111111:role/ReadOnly dev extra",
+		"# only comments",
+	} {
+		_, err := ParseRoleList(list)
+		assert.Error(t, err, list)
+	}
+}
+
+func TestNameAssumeRoleProfiles(t *testing.T) {
+	targets := []AssumeRoleTarget{
+		{RoleARN: "arn:aws:iam::111111111111:role/ReadOnly", AccountID: "111111111111", AccountName: "dev", RoleName: "ReadOnly"},
+		{RoleARN: "arn:aws:iam::222222222222:role/Deploy", AccountID: "222222222222", RoleName: "Deploy"},
+	}
+
+	profiles, err := NameAssumeRoleProfiles(targets, "hub", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, "dev-readonly", profiles[0].Name)
+	assert.Equal(t, "222222222222-deploy", profiles[1].Name, "the account ID stands in for a missing account name")
+	assert.Equal(t, "hub", profiles[1].SourceProfile)
+
+	profiles, err = NameAssumeRoleProfiles(targets, "hub", "eu-west-1", "x-{{.AccountID}}-{{.RoleName}}")
+	require.NoError(t, err)
+	assert.Equal(t, "x-111111111111-readonly", profiles[0].Name)
+	assert.Equal(t, "eu-west-1", profiles[0].Region)
+
+	_, err = NameAssumeRoleProfiles(targets, "hub", "", "assumed")
+	assert.ErrorContains(t, err, "would both be named assumed")
+	_, err = NameAssumeRoleProfiles(targets, "dev-readonly", "", "")
+	assert.ErrorContains(t, err, "like its source profile")
+}
+
+func TestWriteAssumeRoleProfiles(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	config := `[profile hub]
+sso_start_url = https://example.awsapps.com/start
+sso_region = us-east-1
+sso_account_id = 111111111111
+sso_role_name = Admin
+
+[profile dev-readonly]
+role_arn = arn:aws:iam::111111111111:role/Old
+source_profile = hub
+duration_seconds = 3600
+`
+	configPath := filepath.Join(home, ".aws", "config")
+	require.NoError(t, os.MkdirAll(filepath.Dir(configPath), 0700))
+	require.NoError(t, os.WriteFile(configPath, []byte(config), 0600))
+
+	profiles, err := NameAssumeRoleProfiles([]AssumeRoleTarget{
+		{RoleARN: "arn:aws:iam::111111111111:role/ReadOnly", AccountID: "111111111111", AccountName: "dev", RoleName: "ReadOnly"},
+		{RoleARN: "arn:aws:iam::222222222222:role/Deploy", AccountID: "222222222222", AccountName: "prod", RoleName: "Deploy"},
+	}, "hub", "", "")
+	require.NoError(t, err)
+
+	diff, err := DiffAssumeRoleProfiles(profiles)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"prod-deploy"}, diff.Added)
+	assert.Equal(t, []ProfileChange{{Name: "dev-readonly", Changes: []string{
+		"role_arn: arn:aws:iam::111111111111:role/Old → arn:aws:iam::111111111111:role/ReadOnly",
+	}}}, diff.Updated)
+
+	require.NoError(t, WriteAssumeRoleProfiles(profiles))
+	data, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	doc := parseINIDocument(string(data))
+	updated := doc.section("profile dev-readonly")
+	require.NotNil(t, updated)
+	roleARN, _ := updated.get("role_arn")
+	assert.Equal(t, "arn:aws:iam::111111111111:role/ReadOnly", roleARN)
+	duration, _ := updated.get("duration_seconds")
+	assert.Equal(t, "3600", duration, "keys added by hand are kept")
+	assert.True(t, doc.section("profile prod-deploy").hasComment(arkManagedComment))
+	assert.NotNil(t, doc.section("profile hub"))
+
+	diff, err = DiffAssumeRoleProfiles(profiles)
+	require.NoError(t, err)
+	assert.True(t, diff.Empty())
+	assert.Equal(t, 2, diff.Unchanged)
+
+	_, err = DiffAssumeRoleProfiles([]AssumeRoleProfile{{Name: "hub", Target: profiles[0].Target, SourceProfile: "dev-readonly"}})
+	assert.ErrorContains(t, err, "is not an assume-role profile (type: sso)")
+}
@@ -0,0 +1,113 @@
+package services_aws
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+// ProfileFilter selects the accounts and roles a bootstrap writes profiles for
+// Patterns are path.Match globs compared without case; an empty list selects everything
+type ProfileFilter struct {
+	// Accounts match the account name or ID, e.g. "prod-*" or "1111*"
+	Accounts []string
+	// Roles match the role name, e.g. "ReadOnly*"
+	Roles []string
+	// Tags match the account's tags, key to value glob, e.g. env: prod; every key must match
+	Tags map[string]string
+}
+
+// Empty reports whether the filter selects everything
+func (f ProfileFilter) Empty() bool {
+	return len(f.Accounts) == 0 && len(f.Roles) == 0 && len(f.Tags) == 0
+}
+
+// FilterAWSProfiles returns the profiles whose account and role match filter, keeping their order
+func FilterAWSProfiles(profiles []AWSProfile, filter ProfileFilter) ([]AWSProfile, error) {
+	for _, pattern := range slices.Concat(filter.Accounts, filter.Roles, slices.Collect(maps.Values(filter.Tags))) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid filter pattern %q: %w", pattern, err)
+		}
+	}
+
+	var selected []AWSProfile
+	for _, profile := range profiles {
+		if len(filter.Accounts) > 0 && !matchesAnyGlobFold(profile.AccountName, filter.Accounts) && !matchesAnyGlobFold(profile.AccountID, filter.Accounts) {
+			continue
+		}
+		if len(filter.Roles) > 0 && !matchesAnyGlobFold(profile.RoleName, filter.Roles) {
+			continue
+		}
+		if !MatchesTags(profile.Tags, filter.Tags) {
+			continue
+		}
+		selected = append(selected, profile)
+	}
+	return selected, nil
+}
+
+// matchesAnyGlobFold is matchesAnyGlob ignoring case
+func matchesAnyGlobFold(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(strings.ToLower(pattern), strings.ToLower(name)); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// ProfileNameData holds the fields available to profile name templates
+type ProfileNameData struct {
+	AccountID   string
+	AccountName string
+	RoleName    string
+}
+
+// RenderProfileName executes a profile name template; the result is sanitized like the default names
+// (lowercase letters, numbers and hyphens)
+func RenderProfileName(text string, data ProfileNameData) (string, error) {
+	tmpl, err := template.New("profile_name").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid profile name template %q: %w", text, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("invalid profile name template %q: %w", text, err)
+	}
+
+	name := sanitizeProfileName(buf.String())
+	if name == "" {
+		return "", fmt.Errorf("profile name template %q renders an empty name for %s/%s", text, data.AccountName, data.RoleName)
+	}
+	return name, nil
+}
+
+// NameProfiles sets the name of every profile from a profile name template
+// Templates that give two profiles the same name are rejected, since one would overwrite the other
+func NameProfiles(profiles []AWSProfile, text string) ([]AWSProfile, error) {
+	named := make([]AWSProfile, 0, len(profiles))
+	owners := make(map[string]AWSProfile, len(profiles))
+	for _, profile := range profiles {
+		name, err := RenderProfileName(text, ProfileNameData{
+			AccountID:   profile.AccountID,
+			AccountName: profile.AccountName,
+			RoleName:    profile.RoleName,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if previous, ok := owners[name]; ok {
+			return nil, fmt.Errorf("%s/%s and %s/%s would both be named %s; add fields such as {{.AccountID}} to the template",
+				previous.AccountName, previous.RoleName, profile.AccountName, profile.RoleName, name)
+		}
+		owners[name] = profile
+		profile.ProfileName = name
+		named = append(named, profile)
+	}
+	return named, nil
+}
@@ -0,0 +1,84 @@
+package services_aws
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterAWSProfiles(t *testing.T) {
+	profiles := []AWSProfile{
+		{AccountID: "111111111111", AccountName: "Dev", RoleName: "AdministratorAccess"},
+		{AccountID: "111111111111", AccountName: "Dev", RoleName: "ReadOnlyAccess"},
+		{AccountID: "222222222222", AccountName: "prod-core", RoleName: "ReadOnlyAccess"},
+	}
+
+	selected, err := FilterAWSProfiles(profiles, ProfileFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, profiles, selected, "no filter selects everything")
+
+	selected, err = FilterAWSProfiles(profiles, ProfileFilter{Accounts: []string{"prod-*"}})
+	require.NoError(t, err)
+	assert.Equal(t, profiles[2:], selected)
+
+	selected, err = FilterAWSProfiles(profiles, ProfileFilter{Accounts: []string{"1111*"}, Roles: []string{"readonly*"}})
+	require.NoError(t, err)
+	assert.Equal(t, profiles[1:2], selected, "accounts match by ID too, and patterns ignore case")
+
+	_, err = FilterAWSProfiles(profiles, ProfileFilter{Roles: []string{"[admin"}})
+	assert.ErrorContains(t, err, `invalid filter pattern "[admin"`)
+}
+
+func TestFilterAWSProfilesByTags(t *testing.T) {
+	profiles := []AWSProfile{
+		{AccountID: "111111111111", AccountName: "dev", RoleName: "ReadOnly", Tags: map[string]string{"env": "dev"}},
+		{AccountID: "222222222222", AccountName: "prod", RoleName: "ReadOnly", Tags: map[string]string{"env": "prod", "team": "payments"}},
+		{AccountID: "333333333333", AccountName: "untagged", RoleName: "ReadOnly"},
+	}
+
+	selected, err := FilterAWSProfiles(profiles, ProfileFilter{Tags: map[string]string{"env": "PROD"}})
+	require.NoError(t, err)
+	assert.Equal(t, profiles[1:2], selected)
+
+	selected, err = FilterAWSProfiles(profiles, ProfileFilter{Roles: []string{"ReadOnly"}, Tags: map[string]string{"env": "*"}})
+	require.NoError(t, err)
+	assert.Equal(t, profiles[:2], selected, "accounts without tags never match a tag filter")
+	assert.True(t, ProfileFilter{}.Empty())
+	assert.False(t, ProfileFilter{Tags: map[string]string{"env": "prod"}}.Empty())
+}
+
+func TestRenderProfileName(t *testing.T) {
+	data := ProfileNameData{AccountID: "111111111111", AccountName: "Dev Account", RoleName: "ReadOnly_Access"}
+
+	name, err := RenderProfileName("{{.AccountName}}-{{.RoleName}}", data)
+	require.NoError(t, err)
+	assert.Equal(t, generateProfileName(data.AccountName, data.RoleName), name, "the default template keeps today's names")
+
+	name, err = RenderProfileName("{{.AccountID}}/{{.RoleName}}", data)
+	require.NoError(t, err)
+	assert.Equal(t, "111111111111readonly-access", name)
+
+	_, err = RenderProfileName("{{.Account}}", data)
+	assert.ErrorContains(t, err, "invalid profile name template")
+
+	_, err = RenderProfileName("{{if false}}x{{end}}", data)
+	assert.ErrorContains(t, err, "renders an empty name")
+}
+
+func TestNameProfiles(t *testing.T) {
+	profiles := []AWSProfile{
+		{AccountID: "111111111111", AccountName: "dev", RoleName: "ReadOnlyAccess"},
+		{AccountID: "222222222222", AccountName: "prod", RoleName: "ReadOnlyAccess"},
+	}
+
+	named, err := NameProfiles(profiles, "{{.AccountName}}")
+	require.NoError(t, err)
+	assert.Equal(t, "dev", ProfileNameFor(named[0]))
+	assert.Equal(t, "prod", ProfileNameFor(named[1]))
+	assert.Empty(t, profiles[0].ProfileName, "the input is not modified")
+	assert.Equal(t, "dev-readonlyaccess", ProfileNameFor(profiles[0]))
+
+	_, err = NameProfiles(profiles, "{{.RoleName}}")
+	assert.ErrorContains(t, err, "dev/ReadOnlyAccess and prod/ReadOnlyAccess would both be named readonlyaccess")
+}
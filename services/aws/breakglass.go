@@ -0,0 +1,21 @@
+package services_aws
+
+import (
+	"strings"
+
+	ark_config "github.com/andresgarcia29/ark-cli/config"
+)
+
+// IsBreakGlassRole reports whether a profile uses one of the roles tagged in aws.break_glass.roles
+func IsBreakGlassRole(profile ProfileConfig, settings ark_config.BreakGlassConfig) bool {
+	_, roleName := ProfileAccountAndRole(profile)
+	if roleName == "" {
+		return false
+	}
+	for _, role := range settings.Roles {
+		if strings.EqualFold(role, roleName) {
+			return true
+		}
+	}
+	return false
+}
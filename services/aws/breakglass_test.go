@@ -0,0 +1,47 @@
+package services_aws
+
+import (
+	"testing"
+
+	ark_config "github.com/andresgarcia29/ark-cli/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsBreakGlassRole(t *testing.T) {
+	settings := ark_config.BreakGlassConfig{Roles: []string{"AdminBreakGlass"}}
+
+	tests := []struct {
+		name    string
+		profile ProfileConfig
+		want    bool
+	}{
+		{
+			name:    "sso role",
+			profile: ProfileConfig{AccountID: "111111111111", RoleName: "AdminBreakGlass"},
+			want:    true,
+		},
+		{
+			name:    "role arn, different case",
+			profile: ProfileConfig{RoleARN: "arn:aws:iam::111111111111:role/emergency/adminbreakglass"},
+			want:    true,
+		},
+		{
+			name:    "other role",
+			profile: ProfileConfig{AccountID: "111111111111", RoleName: "Admin"},
+			want:    false,
+		},
+		{
+			name:    "no role",
+			profile: ProfileConfig{},
+			want:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsBreakGlassRole(tt.profile, settings))
+		})
+	}
+
+	assert.False(t, IsBreakGlassRole(ProfileConfig{RoleName: "AdminBreakGlass"}, ark_config.BreakGlassConfig{}))
+}
@@ -4,30 +4,38 @@ import (
 	"crypto/sha1"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
+
+	"github.com/andresgarcia29/ark-cli/lib"
+	"github.com/andresgarcia29/ark-cli/paths"
 )
 
+// ErrTokenMismatch is returned by ReadTokenFromCache when the cached token belongs to another start URL or region
+var ErrTokenMismatch = errors.New("cached SSO token belongs to another SSO organization")
+
+// ErrInsecureTokenCache is returned by ReadTokenFromCache when other users can read the cached token file
+// The token is not used until the file is restricted to its owner, or a new sign-in rewrites it
+var ErrInsecureTokenCache = errors.New("cached SSO token is readable by other users")
+
 // SaveTokenToCache saves the access token in ~/.aws/sso/cache/
+// ark reads its own file, keyed by start URL and region; the file keyed by start URL alone is also written
+// because the AWS SDKs and CLI read it for the sso_start_url profiles of ~/.aws/config
 func (s *SSOClient) SaveTokenToCache(token *TokenResponse) error {
-	homeDir, err := os.UserHomeDir()
+	cacheDir, err := SSOCacheDir()
 	if err != nil {
-		return fmt.Errorf("failed to get home directory: %w", err)
+		return err
 	}
 
-	cacheDir := filepath.Join(homeDir, ".aws", "sso", "cache")
-
 	// Create directory if it doesn't exist
 	if err := os.MkdirAll(cacheDir, 0700); err != nil {
 		return fmt.Errorf("failed to create cache directory: %w", err)
 	}
 
-	// Generate file name (SHA1 hash of the start URL)
-	fileName := generateCacheFileName(s.StartURL)
-	filePath := filepath.Join(cacheDir, fileName)
-
 	// Calculate expiration time
 	expiresAt := time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
 
@@ -44,50 +52,211 @@ func (s *SSOClient) SaveTokenToCache(token *TokenResponse) error {
 		return fmt.Errorf("failed to marshal token: %w", err)
 	}
 
-	// Save file with restrictive permissions
-	if err := os.WriteFile(filePath, data, 0600); err != nil {
-		return fmt.Errorf("failed to write cache file: %w", err)
+	// Save files with restrictive permissions, also restricting files another tool wrote more openly
+	for _, fileName := range tokenCacheFileNames(s.StartURL, s.Region) {
+		if err := lib.WritePrivateFile(filepath.Join(cacheDir, fileName), data); err != nil {
+			return fmt.Errorf("failed to write cache file: %w", err)
+		}
 	}
 
 	return nil
 }
 
+// SSOCacheDir returns ~/.aws/sso/cache, where the AWS CLI and SDKs look for SSO tokens
+func SSOCacheDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".aws", "sso", "cache"), nil
+}
+
 // generateCacheFileName generates the file name based on the start URL hash
 func generateCacheFileName(startURL string) string {
 	hash := sha1.Sum([]byte(startURL))
 	return hex.EncodeToString(hash[:]) + ".json"
 }
 
-// ReadTokenFromCache reads the access token from the cache
-func ReadTokenFromCache(startURL string) (*CachedToken, error) {
-	homeDir, err := os.UserHomeDir()
+// tokenCacheFileName is the file ark caches the token of a start URL and SSO region in
+func tokenCacheFileName(startURL, region string) string {
+	return "ark-" + generateCacheFileName(startURL+"\n"+region)
+}
+
+// LoginLockPath is the file concurrent logins to a start URL and SSO region lock, so only one of them signs in
+func LoginLockPath(startURL, region string) (string, error) {
+	dir, err := paths.CacheDir()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %w", err)
+		return "", err
 	}
+	return filepath.Join(dir, "sso-login-"+strings.TrimSuffix(generateCacheFileName(startURL+"\n"+region), ".json")+".lock"), nil
+}
 
-	cacheDir := filepath.Join(homeDir, ".aws", "sso", "cache")
-	fileName := generateCacheFileName(startURL)
-	filePath := filepath.Join(cacheDir, fileName)
+// tokenCacheFileNames are the files the token of a start URL and SSO region is cached in, ark's own first
+func tokenCacheFileNames(startURL, region string) []string {
+	return []string{tokenCacheFileName(startURL, region), generateCacheFileName(startURL)}
+}
 
-	data, err := os.ReadFile(filePath)
+// TokenCacheIssues returns the cached token files of a start URL and SSO region that other users can read
+func TokenCacheIssues(startURL, region string) []lib.PermissionIssue {
+	cacheDir, err := SSOCacheDir()
 	if err != nil {
-		return nil, fmt.Errorf("failed to read cache file: %w", err)
+		return nil
+	}
+	var issues []lib.PermissionIssue
+	for _, fileName := range tokenCacheFileNames(startURL, region) {
+		if issue := lib.CheckPrivateFile(filepath.Join(cacheDir, fileName)); issue != nil {
+			issues = append(issues, *issue)
+		}
+	}
+	return issues
+}
+
+// ReadTokenFromCache reads the access token of a start URL and SSO region from the cache
+// A token within aws.clock_skew of its expiration is reported as expired
+// Tokens cached before ark keyed them by region are read from the AWS CLI file of the start URL
+// A token whose start URL or region doesn't match fails with ErrTokenMismatch, so the user re-authenticates,
+// and one in a file other users can read fails with ErrInsecureTokenCache
+func ReadTokenFromCache(startURL, region string) (*CachedToken, error) {
+	cachedToken, expiresAt, err := readCachedToken(startURL, region)
+	if err != nil {
+		return nil, err
+	}
+
+	if ValidFor(expiresAt, time.Now()) <= 0 {
+		return nil, fmt.Errorf("token has expired")
+	}
+
+	return cachedToken, nil
+}
+
+// TokenExpiry returns when the cached token of a start URL and SSO region expires, even if it already has
+func TokenExpiry(startURL, region string) (time.Time, error) {
+	_, expiresAt, err := readCachedToken(startURL, region)
+	return expiresAt, err
+}
+
+// readCachedToken reads the cached token of a start URL and SSO region with its expiration, expired or not
+func readCachedToken(startURL, region string) (*CachedToken, time.Time, error) {
+	cacheDir, err := SSOCacheDir()
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	path := filepath.Join(cacheDir, tokenCacheFileName(startURL, region))
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		path = filepath.Join(cacheDir, generateCacheFileName(startURL))
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to read cache file: %w", err)
+	}
+	if issue := lib.CheckPrivateFile(path); issue != nil {
+		return nil, time.Time{}, fmt.Errorf("%w: %s has mode %04o; run `chmod 600 %s` or sign in again with `ark aws sso --force`",
+			ErrInsecureTokenCache, path, issue.Mode, path)
 	}
 
 	var cachedToken CachedToken
 	if err := json.Unmarshal(data, &cachedToken); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal cache file: %w", err)
+		return nil, time.Time{}, fmt.Errorf("failed to unmarshal cache file: %w", err)
+	}
+
+	if cachedToken.StartURL != startURL || cachedToken.Region != region {
+		return nil, time.Time{}, fmt.Errorf("%w: it was issued for %s (%s), not %s (%s); run `ark aws sso --force` to re-authenticate",
+			ErrTokenMismatch, cachedToken.StartURL, cachedToken.Region, startURL, region)
 	}
 
-	// Verify if the token has expired
 	expiresAt, err := time.Parse(time.RFC3339, cachedToken.ExpiresAt)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse expiration time: %w", err)
+		return nil, time.Time{}, fmt.Errorf("failed to parse expiration time: %w", err)
 	}
+	return &cachedToken, expiresAt, nil
+}
 
-	if time.Now().After(expiresAt) {
-		return nil, fmt.Errorf("token has expired")
+// cachedClientRegistration is the cache file of an OIDC client registration, in the format of the AWS CLI
+type cachedClientRegistration struct {
+	ClientID     string `json:"clientId"`
+	ClientSecret string `json:"clientSecret"`
+	ExpiresAt    string `json:"expiresAt"` // ISO8601 format
+	// AuthorizationEndpoint is only set for authorization code clients
+	AuthorizationEndpoint string `json:"authorizationEndpoint,omitempty"`
+}
+
+// clientRegistrationFileName is the file ark caches the client registered in an SSO region in
+// Registrations are not tied to a start URL, so every portal of the region shares one
+func clientRegistrationFileName(region string) string {
+	return "ark-client-" + generateCacheFileName(region)
+}
+
+// authCodeClientRegistrationFileName is the file ark caches the authorization code client of a portal in
+// Unlike device code clients, they are registered for the start URL they sign in to
+func authCodeClientRegistrationFileName(startURL, region string) string {
+	return "ark-client-authcode-" + generateCacheFileName(startURL+"\n"+region)
+}
+
+// SaveClientRegistration caches the OIDC client registered in the SSO region, so the next logins reuse it
+func (s *SSOClient) SaveClientRegistration(registration *ClientRegistration) error {
+	return saveClientRegistration(clientRegistrationFileName(s.Region), registration)
+}
+
+// ReadClientRegistration returns the OIDC client cached for the SSO region, expired or not
+func (s *SSOClient) ReadClientRegistration() (*ClientRegistration, error) {
+	return readClientRegistration(clientRegistrationFileName(s.Region))
+}
+
+// saveClientRegistration writes a client registration to a file of the SSO cache
+func saveClientRegistration(fileName string, registration *ClientRegistration) error {
+	cacheDir, err := SSOCacheDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(cacheDir, 0700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
 	}
 
-	return &cachedToken, nil
+	data, err := json.MarshalIndent(cachedClientRegistration{
+		ClientID:              registration.ClientID,
+		ClientSecret:          registration.ClientSecret,
+		ExpiresAt:             time.Unix(registration.ExpiresAt, 0).UTC().Format(time.RFC3339),
+		AuthorizationEndpoint: registration.AuthorizationEndpoint,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal client registration: %w", err)
+	}
+	if err := lib.WritePrivateFile(filepath.Join(cacheDir, fileName), data); err != nil {
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+	return nil
+}
+
+// readClientRegistration reads a client registration from a file of the SSO cache
+// A file other users can read is refused, so its client secret is replaced by registering a new client
+func readClientRegistration(fileName string) (*ClientRegistration, error) {
+	cacheDir, err := SSOCacheDir()
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(cacheDir, fileName)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache file: %w", err)
+	}
+	if issue := lib.CheckPrivateFile(path); issue != nil {
+		return nil, fmt.Errorf("%s: %w", path, issue.Err)
+	}
+
+	var cached cachedClientRegistration
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal cache file: %w", err)
+	}
+	expiresAt, err := time.Parse(time.RFC3339, cached.ExpiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse expiration time: %w", err)
+	}
+	return &ClientRegistration{
+		ClientID:              cached.ClientID,
+		ClientSecret:          cached.ClientSecret,
+		ExpiresAt:             expiresAt.Unix(),
+		AuthorizationEndpoint: cached.AuthorizationEndpoint,
+	}, nil
 }
@@ -0,0 +1,151 @@
+package services_aws
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/andresgarcia29/ark-cli/lib"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenCacheIsolation(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	orgA := &SSOClient{StartURL: "https://org-a.awsapps.com/start", Region: "us-east-1"}
+	orgB := &SSOClient{StartURL: "https://org-b.awsapps.com/start", Region: "eu-west-1"}
+	require.NoError(t, orgA.SaveTokenToCache(&TokenResponse{AccessToken: "token-a", ExpiresIn: 3600}))
+	require.NoError(t, orgB.SaveTokenToCache(&TokenResponse{AccessToken: "token-b", ExpiresIn: 3600}))
+
+	cached, err := ReadTokenFromCache(orgA.StartURL, orgA.Region)
+	require.NoError(t, err)
+	assert.Equal(t, "token-a", cached.AccessToken)
+	cached, err = ReadTokenFromCache(orgB.StartURL, orgB.Region)
+	require.NoError(t, err)
+	assert.Equal(t, "token-b", cached.AccessToken)
+
+	// The AWS CLI file of the start URL holds the token for the region it was issued in
+	_, err = ReadTokenFromCache(orgA.StartURL, "eu-west-1")
+	assert.ErrorIs(t, err, ErrTokenMismatch)
+
+	_, err = ReadTokenFromCache("https://org-c.awsapps.com/start", "us-east-1")
+	assert.ErrorContains(t, err, "failed to read cache file")
+}
+
+func TestReadTokenFromCacheAWSCLIFile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	startURL := "https://org-a.awsapps.com/start"
+	dir := filepath.Join(home, ".aws", "sso", "cache")
+	require.NoError(t, os.MkdirAll(dir, 0700))
+
+	writeToken := func(token CachedToken) {
+		data, err := json.Marshal(token)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, generateCacheFileName(startURL)), data, 0600))
+	}
+
+	writeToken(CachedToken{StartURL: startURL, Region: "us-east-1", AccessToken: "cli-token", ExpiresAt: time.Now().Add(time.Hour).UTC().Format(time.RFC3339)})
+	cached, err := ReadTokenFromCache(startURL, "us-east-1")
+	require.NoError(t, err)
+	assert.Equal(t, "cli-token", cached.AccessToken)
+
+	// A file that doesn't describe the start URL it is named after is never trusted
+	writeToken(CachedToken{StartURL: "https://org-b.awsapps.com/start", Region: "us-east-1", AccessToken: "other", ExpiresAt: time.Now().Add(time.Hour).UTC().Format(time.RFC3339)})
+	_, err = ReadTokenFromCache(startURL, "us-east-1")
+	assert.ErrorIs(t, err, ErrTokenMismatch)
+
+	expired := time.Now().Add(-time.Minute).UTC().Truncate(time.Second)
+	writeToken(CachedToken{StartURL: startURL, Region: "us-east-1", AccessToken: "old", ExpiresAt: expired.Format(time.RFC3339)})
+	_, err = ReadTokenFromCache(startURL, "us-east-1")
+	assert.ErrorContains(t, err, "expired")
+
+	// TokenExpiry still tells when an expired token expired
+	expiresAt, err := TokenExpiry(startURL, "us-east-1")
+	require.NoError(t, err)
+	assert.True(t, expired.Equal(expiresAt))
+}
+
+func TestTokenCacheReadableByOthers(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("modes are not checked on Windows")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	client := &SSOClient{StartURL: "https://org-a.awsapps.com/start", Region: "us-east-1"}
+	dir := filepath.Join(home, ".aws", "sso", "cache")
+	arkFile := filepath.Join(dir, tokenCacheFileName(client.StartURL, client.Region))
+	cliFile := filepath.Join(dir, generateCacheFileName(client.StartURL))
+
+	// A file another tool left open is restricted when the token is saved
+	require.NoError(t, os.MkdirAll(dir, 0700))
+	require.NoError(t, os.WriteFile(cliFile, []byte("{}"), 0644))
+	require.NoError(t, os.Chmod(cliFile, 0644))
+	require.NoError(t, client.SaveTokenToCache(&TokenResponse{AccessToken: "token-a", ExpiresIn: 3600}))
+	for _, path := range []string{arkFile, cliFile} {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), path)
+	}
+	assert.Empty(t, TokenCacheIssues(client.StartURL, client.Region))
+
+	// A token other users can read is refused until the file is restricted again
+	require.NoError(t, os.Chmod(arkFile, 0644))
+	_, err := ReadTokenFromCache(client.StartURL, client.Region)
+	assert.ErrorIs(t, err, ErrInsecureTokenCache)
+	assert.ErrorContains(t, err, "chmod 600 "+arkFile)
+	_, err = TokenExpiry(client.StartURL, client.Region)
+	assert.ErrorIs(t, err, ErrInsecureTokenCache)
+
+	issues := TokenCacheIssues(client.StartURL, client.Region)
+	require.Len(t, issues, 1)
+	assert.Equal(t, arkFile, issues[0].Path)
+	assert.True(t, issues[0].Fixable)
+	require.NoError(t, lib.FixPermissions(issues))
+	cached, err := ReadTokenFromCache(client.StartURL, client.Region)
+	require.NoError(t, err)
+	assert.Equal(t, "token-a", cached.AccessToken)
+
+	// So is a client registration, whose secret is replaced by registering a new client
+	expiresAt := time.Now().Add(24 * time.Hour).Unix()
+	require.NoError(t, client.SaveClientRegistration(&ClientRegistration{ClientID: "client", ClientSecret: "secret", ExpiresAt: expiresAt}))
+	require.NoError(t, os.Chmod(filepath.Join(dir, clientRegistrationFileName(client.Region)), 0640))
+	_, err = client.ReadClientRegistration()
+	assert.ErrorIs(t, err, lib.ErrTooPermissive)
+}
+
+func TestClientRegistrationCache(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	east := &SSOClient{StartURL: "https://org-a.awsapps.com/start", Region: "us-east-1"}
+	_, err := east.ReadClientRegistration()
+	assert.ErrorContains(t, err, "failed to read cache file")
+
+	expiresAt := time.Now().Add(90 * 24 * time.Hour).Unix()
+	require.NoError(t, east.SaveClientRegistration(&ClientRegistration{ClientID: "client", ClientSecret: "secret", ExpiresAt: expiresAt}))
+
+	// Portals of the same region share the registration
+	otherPortal := &SSOClient{StartURL: "https://org-b.awsapps.com/start", Region: "us-east-1"}
+	registration, err := otherPortal.ReadClientRegistration()
+	require.NoError(t, err)
+	assert.Equal(t, &ClientRegistration{ClientID: "client", ClientSecret: "secret", ExpiresAt: expiresAt}, registration)
+
+	_, err = (&SSOClient{Region: "eu-west-1"}).ReadClientRegistration()
+	assert.Error(t, err, "registrations are cached per region")
+
+	path := filepath.Join(home, ".aws", "sso", "cache", clientRegistrationFileName("us-east-1"))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var cached map[string]string
+	require.NoError(t, json.Unmarshal(data, &cached))
+	assert.Equal(t, time.Unix(expiresAt, 0).UTC().Format(time.RFC3339), cached["expiresAt"])
+}
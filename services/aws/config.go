@@ -1,7 +1,9 @@
 package services_aws
 
 import (
+	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
 	"slices"
@@ -10,50 +12,60 @@ import (
 	"github.com/andresgarcia29/ark-cli/logs"
 )
 
-// WriteConfigFile writes profiles to the ~/.aws/config file
+// ErrNoAWSConfig is returned by ReadAllProfilesFromConfig on a fresh machine, when neither the AWS config
+// nor any file layered over it exists yet
+var ErrNoAWSConfig = errors.New("no AWS config yet (run `ark bootstrap` to create a profile for every account and role of your SSO portal)")
+
+// WriteConfigFile writes profiles to the AWS config file (~/.aws/config unless AWS_CONFIG_FILE is set)
 func (s *SSOClient) WriteConfigFile(profiles []AWSProfile) error {
 	logger := logs.GetLogger()
 	logger.Infow("Writing config file", "profiles_count", len(profiles), "start_url", s.StartURL, "region", s.Region)
 
-	homeDir, err := os.UserHomeDir()
+	configPath, err := ConfigFilePath()
 	if err != nil {
-		logger.Errorw("Failed to get home directory", "error", err)
-		return fmt.Errorf("failed to get home directory: %w", err)
+		logger.Errorw("Failed to resolve config file path", "error", err)
+		return err
 	}
-
-	configDir := filepath.Join(homeDir, ".aws")
-	configPath := filepath.Join(configDir, "config")
+	configDir := filepath.Dir(configPath)
 	logger.Debugw("Config file path", "path", configPath)
 
 	// Create directory if it doesn't exist
-	logger.Debugw("Ensuring .aws directory exists", "path", configDir)
+	logger.Debugw("Ensuring config directory exists", "path", configDir)
 	if err := os.MkdirAll(configDir, 0700); err != nil {
-		logger.Errorw("Failed to create .aws directory", "path", configDir, "error", err)
-		return fmt.Errorf("failed to create .aws directory: %w", err)
+		logger.Errorw("Failed to create config directory", "path", configDir, "error", err)
+		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
-	// Generate file content
-	var content strings.Builder
-	logger.Debug("Generating config file content")
+	// Read existing file; everything but the profile sections being replaced is kept as is
+	doc := &iniDocument{}
+	if data, err := os.ReadFile(configPath); err == nil {
+		doc = parseINIDocument(string(data))
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
 
+	generated := make(map[string]bool, len(profiles))
 	for _, profile := range profiles {
-		profileName := generateProfileName(profile.AccountName, profile.RoleName)
+		profileName := ProfileNameFor(profile)
 		logger.Debugw("Writing profile", "profile_name", profileName, "account_id", profile.AccountID, "role_name", profile.RoleName)
 
-		content.WriteString(fmt.Sprintf("[profile %s]\n", profileName))
-		content.WriteString(fmt.Sprintf("sso_start_url = %s\n", s.StartURL))
-		content.WriteString(fmt.Sprintf("sso_region = %s\n", s.Region))
-		content.WriteString(fmt.Sprintf("sso_account_id = %s\n", profile.AccountID))
-		content.WriteString(fmt.Sprintf("sso_role_name = %s\n", profile.RoleName))
-		content.WriteString(fmt.Sprintf("region = %s\n", s.Region))
-		content.WriteString("\n") // Blank line between profiles
+		section := s.setProfileSection(doc, profileName, profile)
+		generated[section.name] = true
+	}
+
+	// Profiles that are no longer generated go away, as confirmed through ProfilesRemovedByWrite;
+	// other sections ([default], [sso-session ...], [services ...]) are not ark's to remove
+	for _, section := range slices.Clone(doc.sections) {
+		if strings.HasPrefix(section.name, "profile ") && !generated[section.name] {
+			doc.remove(section.name)
+		}
 	}
 
 	logger.Debugw("Generated config file content", "total_profiles", len(profiles))
 
 	// Write file
 	logger.Debugw("Writing config file", "path", configPath)
-	if err := os.WriteFile(configPath, []byte(content.String()), 0600); err != nil {
+	if err := writeFileAtomic(configPath, []byte(doc.String()), 0600); err != nil {
 		logger.Errorw("Failed to write config file", "path", configPath, "error", err)
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
@@ -62,10 +74,272 @@ func (s *SSOClient) WriteConfigFile(profiles []AWSProfile) error {
 	return nil
 }
 
+// setProfileSection writes the SSO settings of a profile, keeping the keys added by hand (output, a different region...)
+func (s *SSOClient) setProfileSection(doc *iniDocument, profileName string, profile AWSProfile) *iniSection {
+	section := doc.upsert("profile "+profileName, false)
+	section.setComment(arkManagedComment, arkManagedComment)
+	section.set("sso_start_url", s.StartURL)
+	section.set("sso_region", s.Region)
+	section.set("sso_account_id", profile.AccountID)
+	section.set("sso_role_name", profile.RoleName)
+	if _, ok := section.get("region"); !ok {
+		section.set("region", s.Region)
+	}
+	// Tags that weren't read this time are kept as they were
+	switch {
+	case profile.Tags == nil:
+	case len(profile.Tags) == 0:
+		section.removeComment(arkTagsComment)
+	default:
+		section.setComment(arkTagsComment, arkTagsComment+" "+FormatTags(profile.Tags))
+	}
+	return section
+}
+
+// arkTagsComment starts the comment above a profile holding its account's tags, which AWS tools ignore
+const arkTagsComment = "# ark tags:"
+
+// AddProfile writes one profile to the AWS config file, leaving the other profiles untouched
+// An empty profileName is generated from the account and role names, like WriteConfigFile does
+func (s *SSOClient) AddProfile(profileName string, profile AWSProfile) (string, error) {
+	if profileName == "" {
+		profileName = ProfileNameFor(profile)
+	}
+
+	configPath, doc, err := readConfigDocument()
+	if err != nil {
+		return "", err
+	}
+	s.setProfileSection(doc, profileName, profile)
+	if err := writeFileAtomic(configPath, []byte(doc.String()), 0600); err != nil {
+		return "", fmt.Errorf("failed to write config file: %w", err)
+	}
+	return profileName, nil
+}
+
+// SetDefaultRegion writes the region of the [default] profile of the AWS config, the region of commands
+// run without --region or AWS_REGION; the rest of the file is kept as is
+func SetDefaultRegion(region string) error {
+	configPath, doc, err := readConfigDocument()
+	if err != nil {
+		return err
+	}
+	doc.upsert("default", true).set("region", region)
+	if err := writeFileAtomic(configPath, []byte(doc.String()), 0600); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// AppendNewProfiles writes the profiles that are not in the AWS config file yet and returns their names, sorted
+// Existing profiles are left exactly as they are, including the settings edited by hand
+func (s *SSOClient) AppendNewProfiles(profiles []AWSProfile) ([]string, error) {
+	configPath, doc, err := readConfigDocument()
+	if err != nil {
+		return nil, err
+	}
+
+	var added []string
+	for _, profile := range profiles {
+		profileName := ProfileNameFor(profile)
+		if doc.section("profile "+profileName) != nil {
+			continue
+		}
+		s.setProfileSection(doc, profileName, profile)
+		added = append(added, profileName)
+	}
+	if len(added) == 0 {
+		return nil, nil
+	}
+
+	if err := writeFileAtomic(configPath, []byte(doc.String()), 0600); err != nil {
+		return nil, fmt.Errorf("failed to write config file: %w", err)
+	}
+	slices.Sort(added)
+	return added, nil
+}
+
+// readConfigDocument reads the AWS config file for an update, creating its directory; a missing file is empty
+func readConfigDocument() (string, *iniDocument, error) {
+	configPath, err := ConfigFilePath()
+	if err != nil {
+		return "", nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(configPath), 0700); err != nil {
+		return "", nil, fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	doc := &iniDocument{}
+	if data, err := os.ReadFile(configPath); err == nil {
+		doc = parseINIDocument(string(data))
+	} else if !os.IsNotExist(err) {
+		return "", nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	return configPath, doc, nil
+}
+
+// SSOPortal is an SSO start URL with the region of its portal
+type SSOPortal struct {
+	StartURL string
+	Region   string
+}
+
+// ConfiguredSSOPortals lists the distinct SSO portals the SSO profiles of the AWS config point to, sorted by start URL
+func ConfiguredSSOPortals() ([]SSOPortal, error) {
+	profiles, err := ReadAllProfilesFromConfig()
+	if errors.Is(err, ErrNoAWSConfig) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return SSOPortalsOf(profiles), nil
+}
+
+// SSOPortalsOf lists the distinct SSO portals of the SSO profiles, sorted by start URL
+func SSOPortalsOf(profiles []ProfileConfig) []SSOPortal {
+	var portals []SSOPortal
+	for _, profile := range profiles {
+		portal := SSOPortal{StartURL: profile.StartURL, Region: profile.SSORegion}
+		if profile.ProfileType != ProfileTypeSSO || portal.StartURL == "" || slices.Contains(portals, portal) {
+			continue
+		}
+		portals = append(portals, portal)
+	}
+	slices.SortFunc(portals, func(a, b SSOPortal) int { return strings.Compare(a.StartURL+a.Region, b.StartURL+b.Region) })
+	return portals
+}
+
+// ProfileNameFor is the name a bootstrap gives the profile of an account and role:
+// its ProfileName when set by a name template, else the sanitized account-role name
+func ProfileNameFor(profile AWSProfile) string {
+	if profile.ProfileName != "" {
+		return profile.ProfileName
+	}
+	return generateProfileName(profile.AccountName, profile.RoleName)
+}
+
+// ProfilesRemovedByWrite returns the profiles in ~/.aws/config that WriteConfigFile would drop
+// because they are not part of the generated profiles
+func ProfilesRemovedByWrite(profiles []AWSProfile) ([]string, error) {
+	diff, err := (&SSOClient{}).DiffConfigFile(profiles)
+	if err != nil {
+		return nil, err
+	}
+	return diff.Removed, nil
+}
+
+// ProfileChange is a profile WriteConfigFile would update, with its changed settings as "key: old → new"
+type ProfileChange struct {
+	Name    string
+	Changes []string
+}
+
+// ProfileDiff is what WriteConfigFile would change in ~/.aws/config, with profile names sorted
+type ProfileDiff struct {
+	Added     []string
+	Updated   []ProfileChange
+	Removed   []string
+	Unchanged int
+}
+
+// AddedOnly is the part of the diff AppendNewProfiles applies: updated profiles count as unchanged and none are removed
+func (d ProfileDiff) AddedOnly() ProfileDiff {
+	return ProfileDiff{Added: d.Added, Unchanged: d.Unchanged + len(d.Updated)}
+}
+
+// Empty reports whether writing the profiles would leave ~/.aws/config as it is
+func (d ProfileDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Updated) == 0 && len(d.Removed) == 0
+}
+
+// DiffConfigFile compares the profiles WriteConfigFile would write with the ones in ~/.aws/config
+func (s *SSOClient) DiffConfigFile(profiles []AWSProfile) (ProfileDiff, error) {
+	var diff ProfileDiff
+
+	configPath, err := ConfigFilePath()
+	if err != nil {
+		return diff, err
+	}
+	existing := map[string]ProfileConfig{}
+	data, err := os.ReadFile(configPath)
+	if err != nil && !os.IsNotExist(err) {
+		return diff, fmt.Errorf("failed to read config file: %w", err)
+	}
+	if err == nil {
+		parsed, err := parseAllProfilesFromConfigData(data)
+		if err != nil {
+			return diff, fmt.Errorf("failed to parse config file: %w", err)
+		}
+		for _, profile := range parsed {
+			existing[profile.ProfileName] = profile
+		}
+	}
+
+	generated := make(map[string]bool, len(profiles))
+	for _, profile := range profiles {
+		name := ProfileNameFor(profile)
+		if generated[name] {
+			continue
+		}
+		generated[name] = true
+
+		current, ok := existing[name]
+		if !ok {
+			diff.Added = append(diff.Added, name)
+			continue
+		}
+		var changes []string
+		for _, setting := range []struct{ key, old, new string }{
+			{"sso_start_url", current.StartURL, s.StartURL},
+			{"sso_region", current.SSORegion, s.Region},
+			{"sso_account_id", current.AccountID, profile.AccountID},
+			{"sso_role_name", current.RoleName, profile.RoleName},
+		} {
+			if setting.old != setting.new {
+				changes = append(changes, fmt.Sprintf("%s: %s → %s", setting.key, valueOrNone(setting.old), setting.new))
+			}
+		}
+		if profile.Tags != nil && !maps.Equal(current.Tags, profile.Tags) {
+			changes = append(changes, fmt.Sprintf("tags: %s → %s", valueOrNone(FormatTags(current.Tags)), valueOrNone(FormatTags(profile.Tags))))
+		}
+		if len(changes) == 0 {
+			diff.Unchanged++
+			continue
+		}
+		diff.Updated = append(diff.Updated, ProfileChange{Name: name, Changes: changes})
+	}
+
+	for name := range existing {
+		if !generated[name] {
+			diff.Removed = append(diff.Removed, name)
+		}
+	}
+
+	slices.Sort(diff.Added)
+	slices.SortFunc(diff.Updated, func(a, b ProfileChange) int { return strings.Compare(a.Name, b.Name) })
+	slices.Sort(diff.Removed)
+	return diff, nil
+}
+
+// valueOrNone shows unset settings in a ProfileChange
+func valueOrNone(value string) string {
+	if value == "" {
+		return "(none)"
+	}
+	return value
+}
+
 // generateProfileName generates a sanitized profile name
 func generateProfileName(accountName, roleName string) string {
+	return sanitizeProfileName(accountName + "-" + roleName)
+}
+
+// sanitizeProfileName lowercases a profile name and keeps only letters, numbers and hyphens
+func sanitizeProfileName(name string) string {
 	// Convert to lowercase and replace spaces/special characters with hyphens
-	name := strings.ToLower(accountName + "-" + roleName)
+	name = strings.ToLower(name)
 	name = strings.ReplaceAll(name, " ", "-")
 	name = strings.ReplaceAll(name, "_", "-")
 
@@ -80,6 +354,19 @@ func generateProfileName(accountName, roleName string) string {
 	return result.String()
 }
 
+// detectProfileType determines the profile type from its properties, or "" when unknown
+func detectProfileType(profileConfig *ProfileConfig) ProfileType {
+	switch {
+	case profileConfig.RoleARN != "" && profileConfig.WebIdentityTokenFile != "":
+		return ProfileTypeWebIdentity
+	case profileConfig.RoleARN != "":
+		return ProfileTypeAssumeRole
+	case profileConfig.StartURL != "":
+		return ProfileTypeSSO
+	}
+	return ""
+}
+
 // parseProfileFromConfigData parses a specific profile from configuration file data
 func parseProfileFromConfigData(data []byte, profileName string) (*ProfileConfig, error) {
 	lines := strings.Split(string(data), "\n")
@@ -126,6 +413,10 @@ func parseProfileFromConfigData(data []byte, profileName string) (*ProfileConfig
 					profileConfig.SourceProfile = value
 				case "external_id":
 					profileConfig.ExternalID = value
+				case "role_session_name":
+					profileConfig.SessionName = value
+				case "web_identity_token_file":
+					profileConfig.WebIdentityTokenFile = value
 				}
 			}
 		}
@@ -141,42 +432,45 @@ func parseProfileFromConfigData(data []byte, profileName string) (*ProfileConfig
 	}
 
 	// Determine profile type based on found properties
-	if profileConfig.RoleARN != "" {
-		profileConfig.ProfileType = ProfileTypeAssumeRole
-	} else if profileConfig.StartURL != "" {
-		profileConfig.ProfileType = ProfileTypeSSO
-	} else {
+	profileConfig.ProfileType = detectProfileType(profileConfig)
+	if profileConfig.ProfileType == "" {
 		return nil, fmt.Errorf("profile %s is neither SSO nor assume role profile", profileName)
 	}
 
 	return profileConfig, nil
 }
 
-// ReadProfileFromConfig reads a specific profile from ~/.aws/config and ~/.aws/custom_config files
+// ReadProfileFromConfig reads a specific profile from ~/.aws/config and the files layered over it
+// (ark.d/*.conf and custom_config), looking at the highest priority file first
 func ReadProfileFromConfig(profileName string) (*ProfileConfig, error) {
 	logger := logs.GetLogger()
 	logger.Debugw("Reading profile from config", "profile", profileName)
 
-	homeDir, err := os.UserHomeDir()
+	configPath, overlays, err := profileConfigPaths()
 	if err != nil {
-		logger.Errorw("Failed to get home directory", "error", err)
-		return nil, fmt.Errorf("failed to get home directory: %w", err)
+		logger.Errorw("Failed to resolve config file paths", "error", err)
+		return nil, err
 	}
 
-	// First try to read from custom_config if it exists (has priority)
-	customConfigPath := filepath.Join(homeDir, ".aws", "custom_config")
-	if data, err := os.ReadFile(customConfigPath); err == nil {
-		logger.Debugw("Reading from custom_config", "path", customConfigPath)
+	// First try the layered files, which have priority over the main config
+	for i := len(overlays) - 1; i >= 0; i-- {
+		overlayPath := overlays[i]
+		data, err := os.ReadFile(overlayPath)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				logger.Warnw("Error reading layered config (will continue with main config)", "path", overlayPath, "error", err)
+			}
+			continue
+		}
+
+		logger.Debugw("Reading from layered config", "path", overlayPath)
 		if profileConfig, err := parseProfileFromConfigData(data, profileName); err == nil && profileConfig != nil {
-			logger.Debugw("Profile found in custom_config", "profile", profileName, "type", profileConfig.ProfileType)
+			logger.Debugw("Profile found in layered config", "profile", profileName, "path", overlayPath, "type", profileConfig.ProfileType)
 			return profileConfig, nil
 		}
-	} else if !os.IsNotExist(err) {
-		logger.Warnw("Error reading custom_config (will continue with main config)", "path", customConfigPath, "error", err)
 	}
 
-	// If not found in custom_config, read from main config
-	configPath := filepath.Join(homeDir, ".aws", "config")
+	// If not found in the layered files, read from main config
 	logger.Debugw("Reading from main config", "path", configPath)
 
 	data, err := os.ReadFile(configPath)
@@ -202,6 +496,7 @@ func ReadProfileFromConfig(profileName string) (*ProfileConfig, error) {
 
 // ResolveSSOConfiguration resolves the SSO configuration for a profile
 // If it's an assume role profile, it gets the configuration from the source profile
+// Web identity profiles return empty values since no SSO session is needed
 func ResolveSSOConfiguration(profileName string) (ssoRegion, ssoStartURL string, err error) {
 	profileConfig, err := ReadProfileFromConfig(profileName)
 	if err != nil {
@@ -217,6 +512,11 @@ func ResolveSSOConfiguration(profileName string) (ssoRegion, ssoStartURL string,
 		return profileConfig.SSORegion, profileConfig.StartURL, nil
 	}
 
+	// Web identity profiles exchange an OIDC token and don't involve SSO
+	if profileConfig.ProfileType == ProfileTypeWebIdentity {
+		return "", "", nil
+	}
+
 	// If it's an assume role profile, get the configuration from the source profile
 	if profileConfig.ProfileType == ProfileTypeAssumeRole {
 		if profileConfig.SourceProfile == "" {
@@ -247,20 +547,22 @@ func parseAllProfilesFromConfigData(data []byte) ([]ProfileConfig, error) {
 	var profiles []ProfileConfig
 	lines := strings.Split(string(data), "\n")
 	var currentProfile *ProfileConfig
+	// pendingTags comes from an "# ark tags:" comment, which sits above the header of its profile
+	var pendingTags map[string]string
 
 	for _, line := range lines {
 		line = strings.TrimSpace(line)
 
+		if tags, ok := strings.CutPrefix(line, arkTagsComment); ok {
+			pendingTags = parseTags(tags)
+			continue
+		}
+
 		// Detect profile start
 		if strings.HasPrefix(line, "[profile ") && strings.HasSuffix(line, "]") {
 			// Save the previous profile if it exists and is valid
 			if currentProfile != nil && (currentProfile.AccountID != "" || currentProfile.RoleARN != "") {
-				// Determine profile type
-				if currentProfile.RoleARN != "" {
-					currentProfile.ProfileType = ProfileTypeAssumeRole
-				} else if currentProfile.StartURL != "" {
-					currentProfile.ProfileType = ProfileTypeSSO
-				}
+				currentProfile.ProfileType = detectProfileType(currentProfile)
 				profiles = append(profiles, *currentProfile)
 			}
 
@@ -268,8 +570,12 @@ func parseAllProfilesFromConfigData(data []byte) ([]ProfileConfig, error) {
 			profileName := strings.TrimSuffix(strings.TrimPrefix(line, "[profile "), "]")
 			currentProfile = &ProfileConfig{
 				ProfileName: profileName,
+				Tags:        pendingTags,
 			}
 		}
+		if strings.HasPrefix(line, "[") {
+			pendingTags = nil
+		}
 
 		// Read current profile properties
 		if currentProfile != nil && strings.Contains(line, "=") {
@@ -295,6 +601,10 @@ func parseAllProfilesFromConfigData(data []byte) ([]ProfileConfig, error) {
 					currentProfile.SourceProfile = value
 				case "external_id":
 					currentProfile.ExternalID = value
+				case "role_session_name":
+					currentProfile.SessionName = value
+				case "web_identity_token_file":
+					currentProfile.WebIdentityTokenFile = value
 				}
 			}
 		}
@@ -302,39 +612,35 @@ func parseAllProfilesFromConfigData(data []byte) ([]ProfileConfig, error) {
 
 	// Add the last profile if it is valid
 	if currentProfile != nil && (currentProfile.AccountID != "" || currentProfile.RoleARN != "") {
-		// Determine profile type
-		if currentProfile.RoleARN != "" {
-			currentProfile.ProfileType = ProfileTypeAssumeRole
-		} else if currentProfile.StartURL != "" {
-			currentProfile.ProfileType = ProfileTypeSSO
-		}
+		currentProfile.ProfileType = detectProfileType(currentProfile)
 		profiles = append(profiles, *currentProfile)
 	}
 
 	return profiles, nil
 }
 
-// ReadAllProfilesFromConfig reads all profiles from ~/.aws/config and ~/.aws/custom_config files
-// Profiles from custom_config have priority over main config
+// ReadAllProfilesFromConfig reads all profiles from ~/.aws/config and the files layered over it
+// Profiles from ark.d/*.conf override the main config, and custom_config overrides them all
+// When none of these files exists, ErrNoAWSConfig is returned instead of an empty list
 func ReadAllProfilesFromConfig() ([]ProfileConfig, error) {
 	logger := logs.GetLogger()
-	homeDir, err := os.UserHomeDir()
+	configPath, overlays, err := profileConfigPaths()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %w", err)
+		return nil, err
 	}
 
 	// Read profiles from main config file
-	configPath := filepath.Join(homeDir, ".aws", "config")
 	profilesMap := make(map[string]ProfileConfig)
 
 	data, err := os.ReadFile(configPath)
-	if err != nil {
-		logger.Warnw("Failed to read main config file (will try custom_config)", "path", configPath, "error", err)
-	} else {
+	missing := os.IsNotExist(err)
+	if err != nil && !missing {
+		logger.Warnw("Failed to read main config file (will try layered configs)", "path", configPath, "error", err)
+	} else if err == nil {
 		logger.Debugw("Reading profiles from main config", "path", configPath)
 		profiles, err := parseAllProfilesFromConfigData(data)
 		if err != nil {
-			logger.Warnw("Failed to parse main config (will try custom_config)", "error", err)
+			logger.Warnw("Failed to parse main config (will try layered configs)", "error", err)
 		} else {
 			// Add profiles from main config to map
 			for _, profile := range profiles {
@@ -344,22 +650,32 @@ func ReadAllProfilesFromConfig() ([]ProfileConfig, error) {
 		}
 	}
 
-	// Read profiles from custom_config file if it exists (has priority)
-	customConfigPath := filepath.Join(homeDir, ".aws", "custom_config")
-	if data, err := os.ReadFile(customConfigPath); err == nil {
-		logger.Debugw("Reading profiles from custom_config", "path", customConfigPath)
-		customProfiles, err := parseAllProfilesFromConfigData(data)
+	// Read the layered files that exist, each one overriding the previous ones
+	for _, overlayPath := range overlays {
+		data, err := os.ReadFile(overlayPath)
 		if err != nil {
-			logger.Warnw("Failed to parse custom_config", "error", err)
-		} else {
-			// Profiles from custom_config overwrite or add to main config profiles
-			for _, profile := range customProfiles {
-				profilesMap[profile.ProfileName] = profile
+			if !os.IsNotExist(err) {
+				logger.Warnw("Error reading layered config (skipping it)", "path", overlayPath, "error", err)
 			}
-			logger.Debugw("Merged profiles from custom_config", "count", len(customProfiles), "total", len(profilesMap))
+			continue
 		}
-	} else if !os.IsNotExist(err) {
-		logger.Warnw("Error reading custom_config (will continue with main config only)", "path", customConfigPath, "error", err)
+		missing = false
+
+		logger.Debugw("Reading profiles from layered config", "path", overlayPath)
+		overlayProfiles, err := parseAllProfilesFromConfigData(data)
+		if err != nil {
+			logger.Warnw("Failed to parse layered config", "path", overlayPath, "error", err)
+			continue
+		}
+		for _, profile := range overlayProfiles {
+			profilesMap[profile.ProfileName] = profile
+		}
+		logger.Debugw("Merged profiles from layered config", "path", overlayPath, "count", len(overlayProfiles), "total", len(profilesMap))
+	}
+
+	if missing {
+		logger.Debugw("No AWS config file found", "path", configPath)
+		return nil, fmt.Errorf("%s doesn't exist: %w", configPath, ErrNoAWSConfig)
 	}
 
 	// Convert map to slice
@@ -0,0 +1,189 @@
+package services_aws
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ConfigIssueKind is the kind of problem LintConfig finds in the AWS config files
+type ConfigIssueKind string
+
+const (
+	// ConfigIssueShadowed is a profile defined in several files; the last file wins as a whole,
+	// its keys aren't merged with the others
+	ConfigIssueShadowed ConfigIssueKind = "shadowed"
+	// ConfigIssueDuplicate is a profile defined twice in the same file
+	ConfigIssueDuplicate ConfigIssueKind = "duplicate"
+	// ConfigIssueMissingSource is a profile whose source_profile is in none of the AWS files
+	ConfigIssueMissingSource ConfigIssueKind = "missing-source-profile"
+)
+
+// ConfigIssue is a problem with a profile of the AWS config files
+type ConfigIssue struct {
+	Kind    ConfigIssueKind
+	Profile string
+	// Files are the files defining the profile, lowest priority first: the last one is the one read
+	Files []string
+	// Details are the keys the definitions disagree on, "key: value (file) vs value (file)", or the
+	// missing source profile
+	Details []string
+}
+
+// Summary describes the issue in one line
+func (i ConfigIssue) Summary() string {
+	names := make([]string, 0, len(i.Files))
+	for _, file := range i.Files {
+		names = append(names, filepath.Base(file))
+	}
+	switch i.Kind {
+	case ConfigIssueShadowed:
+		return fmt.Sprintf("profile %s is defined in %s; only %s is read", i.Profile, strings.Join(names, ", "), names[len(names)-1])
+	case ConfigIssueDuplicate:
+		return fmt.Sprintf("profile %s is defined twice in %s; only the last definition is read", i.Profile, names[0])
+	case ConfigIssueMissingSource:
+		return fmt.Sprintf("profile %s uses source_profile %s, which is not in any AWS config or credentials file", i.Profile, i.Details[0])
+	}
+	return fmt.Sprintf("profile %s: %s", i.Profile, i.Kind)
+}
+
+// configFile is the content of one of the AWS config files
+type configFile struct {
+	Path string
+	Data string
+}
+
+// LintConfig reports the profiles of ~/.aws/config and the files layered over it (ark.d/*.conf, custom_config)
+// that are defined more than once, and the profiles whose source_profile doesn't exist. Missing files are skipped
+func LintConfig() ([]ConfigIssue, error) {
+	configPath, overlays, err := profileConfigPaths()
+	if err != nil {
+		return nil, err
+	}
+	var files []configFile
+	for _, path := range append([]string{configPath}, overlays...) {
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		files = append(files, configFile{Path: path, Data: string(data)})
+	}
+
+	// source_profile may also name a section of the credentials file, e.g. static keys
+	var credentialProfiles []string
+	if credentialsPath, err := CredentialsFilePath(); err == nil {
+		if data, err := os.ReadFile(credentialsPath); err == nil {
+			for _, section := range parseINIDocument(string(data)).sections {
+				credentialProfiles = append(credentialProfiles, section.name)
+			}
+		}
+	}
+	return lintConfigFiles(files, credentialProfiles), nil
+}
+
+// lintConfigFiles finds the issues of config files given lowest priority first
+func lintConfigFiles(files []configFile, credentialProfiles []string) []ConfigIssue {
+	type definition struct {
+		path   string
+		values map[string]string
+	}
+	definitions := make(map[string][]definition)
+	var names []string
+	var issues []ConfigIssue
+
+	for _, file := range files {
+		seen := make(map[string]bool)
+		for _, section := range parseINIDocument(file.Data).sections {
+			name, ok := configProfileName(section.name)
+			if !ok {
+				continue
+			}
+			values := make(map[string]string)
+			for _, line := range section.body {
+				if key, value, ok := parseINIKeyValue(line); ok {
+					values[key] = value
+				}
+			}
+			// ark reads the last definition of a profile in a file
+			if seen[name] {
+				issues = append(issues, ConfigIssue{Kind: ConfigIssueDuplicate, Profile: name, Files: []string{file.Path}})
+				defs := definitions[name]
+				defs[len(defs)-1].values = values
+				continue
+			}
+			seen[name] = true
+			if _, known := definitions[name]; !known {
+				names = append(names, name)
+			}
+			definitions[name] = append(definitions[name], definition{path: file.Path, values: values})
+		}
+	}
+
+	slices.Sort(names)
+	for _, name := range names {
+		defs := definitions[name]
+		if len(defs) < 2 {
+			continue
+		}
+		issue := ConfigIssue{Kind: ConfigIssueShadowed, Profile: name}
+		keys := make(map[string]bool)
+		for _, def := range defs {
+			issue.Files = append(issue.Files, def.path)
+			for key := range def.values {
+				keys[key] = true
+			}
+		}
+		for _, key := range slices.Sorted(maps.Keys(keys)) {
+			values := make([]string, 0, len(defs))
+			for _, def := range defs {
+				value, ok := def.values[key]
+				if !ok {
+					value = "(unset)"
+				}
+				values = append(values, value)
+			}
+			if !slices.ContainsFunc(values, func(value string) bool { return value != values[0] }) {
+				continue
+			}
+			described := make([]string, 0, len(defs))
+			for i, def := range defs {
+				described = append(described, fmt.Sprintf("%s (%s)", values[i], filepath.Base(def.path)))
+			}
+			issue.Details = append(issue.Details, fmt.Sprintf("%s: %s", key, strings.Join(described, " vs ")))
+		}
+		issues = append(issues, issue)
+	}
+
+	for _, name := range names {
+		defs := definitions[name]
+		source, ok := defs[len(defs)-1].values["source_profile"]
+		if !ok || source == "" {
+			continue
+		}
+		if _, exists := definitions[source]; exists || slices.Contains(credentialProfiles, source) {
+			continue
+		}
+		issues = append(issues, ConfigIssue{
+			Kind:    ConfigIssueMissingSource,
+			Profile: name,
+			Files:   []string{defs[len(defs)-1].path},
+			Details: []string{source},
+		})
+	}
+	return issues
+}
+
+// configProfileName returns the profile of a config section: "profile <name>" or "default"
+func configProfileName(section string) (string, bool) {
+	if section == "default" {
+		return section, true
+	}
+	name, ok := strings.CutPrefix(section, "profile ")
+	return strings.TrimSpace(name), ok
+}
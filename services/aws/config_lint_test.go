@@ -0,0 +1,87 @@
+package services_aws
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintConfigFiles(t *testing.T) {
+	files := []configFile{
+		{Path: "/home/me/.aws/config", Data: `[default]
+region = us-east-1
+
+[profile dev]
+sso_account_id = 111111111111
+sso_role_name = ReadOnly
+region = us-east-1
+
+[profile ci]
+role_arn = arn:aws:iam::111111111111:role/Deploy
+source_profile = dev
+
+[profile ci]
+role_arn = arn:aws:iam::111111111111:role/Deploy
+source_profile = gone
+
+[sso-session corp]
+sso_start_url = https://example.awsapps.com/start
+`},
+		{Path: "/home/me/.aws/custom_config", Data: `[profile dev]
+sso_account_id = 111111111111
+sso_role_name = Admin
+
+[profile tools]
+role_arn = arn:aws:iam::222222222222:role/Tools
+source_profile = static
+`},
+	}
+
+	issues := lintConfigFiles(files, []string{"static"})
+	require.Len(t, issues, 3)
+
+	assert.Equal(t, ConfigIssue{Kind: ConfigIssueDuplicate, Profile: "ci", Files: []string{"/home/me/.aws/config"}}, issues[0])
+	assert.Equal(t, "profile ci is defined twice in config; only the last definition is read", issues[0].Summary())
+
+	assert.Equal(t, ConfigIssue{
+		Kind:    ConfigIssueShadowed,
+		Profile: "dev",
+		Files:   []string{"/home/me/.aws/config", "/home/me/.aws/custom_config"},
+		Details: []string{
+			"region: us-east-1 (config) vs (unset) (custom_config)",
+			"sso_role_name: ReadOnly (config) vs Admin (custom_config)",
+		},
+	}, issues[1])
+	assert.Equal(t, "profile dev is defined in config, custom_config; only custom_config is read", issues[1].Summary())
+
+	assert.Equal(t, ConfigIssue{Kind: ConfigIssueMissingSource, Profile: "ci", Files: []string{"/home/me/.aws/config"}, Details: []string{"gone"}}, issues[2],
+		"the last definition of a profile is checked, and credentials file profiles count as sources")
+	assert.Contains(t, issues[2].Summary(), "source_profile gone")
+
+	assert.Empty(t, lintConfigFiles(files[1:], []string{"static"}), "dev is only shadowed when both files define it")
+}
+
+func TestLintConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	issues, err := LintConfig()
+	require.NoError(t, err)
+	assert.Empty(t, issues, "no AWS files, no issues")
+
+	awsDir := filepath.Join(home, ".aws")
+	require.NoError(t, os.MkdirAll(filepath.Join(awsDir, "ark.d"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(awsDir, "config"), []byte("[profile dev]\nregion = us-east-1\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(awsDir, "ark.d", "team.conf"), []byte("[profile dev]\nregion = eu-west-1\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(awsDir, "credentials"), []byte("[static]\naws_access_key_id = AKIA\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(awsDir, "custom_config"), []byte("[profile ci]\nsource_profile = static\n"), 0600))
+
+	issues, err = LintConfig()
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, ConfigIssueShadowed, issues[0].Kind)
+	assert.Equal(t, []string{"region: us-east-1 (config) vs eu-west-1 (team.conf)"}, issues[0].Details)
+}
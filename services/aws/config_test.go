@@ -1,9 +1,13 @@
 package services_aws
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestSelectProfilesPerAccount(t *testing.T) {
@@ -270,3 +274,341 @@ func TestSelectProfileByARN(t *testing.T) {
 		})
 	}
 }
+
+func TestProfilesRemovedByWrite(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	removed, err := ProfilesRemovedByWrite(nil)
+	require.NoError(t, err)
+	assert.Empty(t, removed, "no config file means nothing is removed")
+
+	config := `[profile dev-readonly]
+sso_account_id = 111111111111
+sso_role_name = ReadOnly
+
+[profile legacy]
+role_arn = arn:aws:iam::222222222222:role/Legacy
+source_profile = dev-readonly
+`
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".aws"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".aws", "config"), []byte(config), 0600))
+
+	removed, err = ProfilesRemovedByWrite([]AWSProfile{{AccountName: "dev", RoleName: "readonly"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"legacy"}, removed)
+}
+
+func TestDiffConfigFile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	client := &SSOClient{StartURL: "https://example.awsapps.com/start", Region: "us-east-1"}
+	profiles := []AWSProfile{
+		{AccountID: "111111111111", AccountName: "dev", RoleName: "ReadOnly"},
+		{AccountID: "222222222222", AccountName: "prod", RoleName: "Admin"},
+		{AccountID: "333333333333", AccountName: "sandbox", RoleName: "Admin"},
+	}
+
+	diff, err := client.DiffConfigFile(profiles)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dev-readonly", "prod-admin", "sandbox-admin"}, diff.Added, "no config file means every profile is added")
+
+	config := `[profile dev-readonly]
+sso_start_url = https://example.awsapps.com/start
+sso_region = us-east-1
+sso_account_id = 111111111111
+sso_role_name = ReadOnly
+
+[profile prod-admin]
+sso_start_url = https://old.awsapps.com/start
+sso_region = us-east-1
+sso_account_id = 222222222222
+sso_role_name = Admin
+
+[profile legacy]
+role_arn = arn:aws:iam::222222222222:role/Legacy
+source_profile = dev-readonly
+`
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".aws"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".aws", "config"), []byte(config), 0600))
+
+	diff, err = client.DiffConfigFile(profiles)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sandbox-admin"}, diff.Added)
+	assert.Equal(t, []ProfileChange{{
+		Name:    "prod-admin",
+		Changes: []string{"sso_start_url: https://old.awsapps.com/start → https://example.awsapps.com/start"},
+	}}, diff.Updated)
+	assert.Equal(t, []string{"legacy"}, diff.Removed)
+	assert.Equal(t, 1, diff.Unchanged)
+	assert.False(t, diff.Empty())
+
+	diff, err = client.DiffConfigFile(profiles[:1])
+	require.NoError(t, err)
+	assert.Equal(t, []string{"legacy", "prod-admin"}, diff.Removed)
+	assert.Empty(t, diff.Added)
+	assert.Empty(t, diff.Updated)
+}
+
+func TestAppendNewProfiles(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	config := `[profile dev-readonly]
+sso_start_url = https://example.awsapps.com/start
+sso_region = us-east-1
+sso_account_id = 111111111111
+sso_role_name = ReadOnly
+region = eu-west-1
+
+[profile legacy]
+role_arn = arn:aws:iam::222222222222:role/Legacy
+source_profile = dev-readonly
+`
+	configPath := filepath.Join(home, ".aws", "config")
+	require.NoError(t, os.MkdirAll(filepath.Dir(configPath), 0700))
+	require.NoError(t, os.WriteFile(configPath, []byte(config), 0600))
+
+	client := &SSOClient{StartURL: "https://new.awsapps.com/start", Region: "us-east-1"}
+	profiles := []AWSProfile{
+		{AccountID: "111111111111", AccountName: "dev", RoleName: "ReadOnly"},
+		{AccountID: "333333333333", AccountName: "sandbox", RoleName: "Admin"},
+	}
+
+	added, err := client.AppendNewProfiles(profiles)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sandbox-admin"}, added)
+
+	data, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), config), "existing profiles are kept byte for byte")
+
+	profile, err := ReadProfileFromConfig("sandbox-admin")
+	require.NoError(t, err)
+	assert.Equal(t, "333333333333", profile.AccountID)
+	assert.Equal(t, "https://new.awsapps.com/start", profile.StartURL)
+
+	added, err = client.AppendNewProfiles(profiles)
+	require.NoError(t, err)
+	assert.Empty(t, added, "a second run has nothing to add")
+}
+
+func TestSetDefaultRegion(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("AWS_CONFIG_FILE", "")
+
+	config := `[profile dev-readonly]
+sso_start_url = https://example.awsapps.com/start
+sso_region = us-east-1
+sso_account_id = 111111111111
+sso_role_name = ReadOnly
+region = eu-west-1
+`
+	configPath := filepath.Join(home, ".aws", "config")
+	require.NoError(t, os.MkdirAll(filepath.Dir(configPath), 0700))
+	require.NoError(t, os.WriteFile(configPath, []byte(config), 0600))
+
+	require.NoError(t, SetDefaultRegion("us-east-1"))
+	data, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "[default]\nregion = us-east-1\n\n"+config, string(data), "the [default] profile goes first")
+
+	require.NoError(t, SetDefaultRegion("eu-central-1"))
+	data, err = os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "[default]\nregion = eu-central-1\n\n"+config, string(data))
+}
+
+func TestProfileDiffAddedOnly(t *testing.T) {
+	diff := ProfileDiff{
+		Added:     []string{"sandbox-admin"},
+		Updated:   []ProfileChange{{Name: "prod-admin"}},
+		Removed:   []string{"legacy"},
+		Unchanged: 2,
+	}
+	assert.Equal(t, ProfileDiff{Added: []string{"sandbox-admin"}, Unchanged: 3}, diff.AddedOnly())
+	assert.True(t, ProfileDiff{Updated: diff.Updated, Removed: diff.Removed}.AddedOnly().Empty())
+}
+
+func TestParseWebIdentityProfile(t *testing.T) {
+	data := []byte(`[profile ci]
+role_arn = arn:aws:iam::123456789012:role/GitHubActions
+web_identity_token_file = /tmp/token
+role_session_name = gha-run
+region = us-west-2
+
+[profile admin]
+role_arn = arn:aws:iam::123456789012:role/Admin
+source_profile = sso
+`)
+
+	profile, err := parseProfileFromConfigData(data, "ci")
+	require.NoError(t, err)
+	require.NotNil(t, profile)
+	assert.Equal(t, ProfileTypeWebIdentity, profile.ProfileType)
+	assert.Equal(t, "/tmp/token", profile.WebIdentityTokenFile)
+	assert.Equal(t, "gha-run", profile.SessionName)
+
+	profiles, err := parseAllProfilesFromConfigData(data)
+	require.NoError(t, err)
+	require.Len(t, profiles, 2)
+	assert.Equal(t, ProfileTypeWebIdentity, profiles[0].ProfileType)
+	assert.Equal(t, ProfileTypeAssumeRole, profiles[1].ProfileType)
+}
+
+func TestDetectProfileType(t *testing.T) {
+	assert.Equal(t, ProfileTypeSSO, detectProfileType(&ProfileConfig{StartURL: "https://example.awsapps.com/start"}))
+	assert.Equal(t, ProfileTypeAssumeRole, detectProfileType(&ProfileConfig{RoleARN: "arn", SourceProfile: "sso"}))
+	assert.Equal(t, ProfileTypeWebIdentity, detectProfileType(&ProfileConfig{RoleARN: "arn", WebIdentityTokenFile: "/tmp/token"}))
+	assert.Equal(t, ProfileType(""), detectProfileType(&ProfileConfig{}))
+}
+
+func TestLayeredConfigFiles(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	awsDir := filepath.Join(home, ".aws")
+	includeDir := filepath.Join(awsDir, "ark.d")
+	require.NoError(t, os.MkdirAll(includeDir, 0700))
+
+	files := map[string]string{
+		filepath.Join(awsDir, "config"): `[profile dev]
+sso_start_url = https://example.awsapps.com/start
+sso_account_id = 111111111111
+sso_role_name = ReadOnly
+region = us-east-1
+
+[profile shared]
+sso_start_url = https://example.awsapps.com/start
+sso_account_id = 111111111111
+sso_role_name = FromConfig
+`,
+		// Team-managed snippets, merged in lexical order
+		filepath.Join(includeDir, "10-platform.conf"): `[profile shared]
+sso_start_url = https://example.awsapps.com/start
+sso_account_id = 222222222222
+sso_role_name = FromPlatform
+
+[profile platform]
+role_arn = arn:aws:iam::222222222222:role/Platform
+source_profile = dev
+`,
+		filepath.Join(includeDir, "20-security.conf"): `[profile shared]
+sso_start_url = https://example.awsapps.com/start
+sso_account_id = 333333333333
+sso_role_name = FromSecurity
+`,
+		// Not a .conf file: ignored
+		filepath.Join(includeDir, "notes.txt"): `[profile ignored]
+sso_start_url = https://example.awsapps.com/start
+sso_account_id = 444444444444
+sso_role_name = Ignored
+`,
+		// Personal overrides win over everything
+		filepath.Join(awsDir, "custom_config"): `[profile dev]
+sso_start_url = https://example.awsapps.com/start
+sso_account_id = 111111111111
+sso_role_name = ReadOnly
+region = eu-west-1
+`,
+	}
+	for path, content := range files {
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	}
+
+	profiles, err := ReadAllProfilesFromConfig()
+	require.NoError(t, err)
+	byName := make(map[string]ProfileConfig)
+	for _, profile := range profiles {
+		byName[profile.ProfileName] = profile
+	}
+	assert.Len(t, byName, 3)
+	assert.NotContains(t, byName, "ignored")
+	assert.Equal(t, "FromSecurity", byName["shared"].RoleName)
+	assert.Equal(t, "eu-west-1", byName["dev"].Region)
+	assert.Equal(t, ProfileTypeAssumeRole, byName["platform"].ProfileType)
+
+	for name, wantRole := range map[string]string{"shared": "FromSecurity", "dev": "ReadOnly"} {
+		profile, err := ReadProfileFromConfig(name)
+		require.NoError(t, err)
+		assert.Equal(t, wantRole, profile.RoleName)
+	}
+	profile, err := ReadProfileFromConfig("dev")
+	require.NoError(t, err)
+	assert.Equal(t, "eu-west-1", profile.Region)
+
+	_, err = ReadProfileFromConfig("ignored")
+	assert.Error(t, err)
+}
+
+func TestAddProfileAndConfiguredSSOPortals(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	portals, err := ConfiguredSSOPortals()
+	require.NoError(t, err)
+	assert.Empty(t, portals)
+
+	config := `[profile dev-readonly]
+sso_start_url = https://org-a.awsapps.com/start
+sso_region = us-east-1
+sso_account_id = 111111111111
+sso_role_name = ReadOnly
+
+[profile legacy]
+role_arn = arn:aws:iam::222222222222:role/Legacy
+source_profile = dev-readonly
+`
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".aws"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".aws", "config"), []byte(config), 0600))
+
+	client := &SSOClient{StartURL: "https://org-b.awsapps.com/start", Region: "eu-west-1"}
+	name, err := client.AddProfile("", AWSProfile{AccountID: "333333333333", AccountName: "Data Lake", RoleName: "Analyst"})
+	require.NoError(t, err)
+	assert.Equal(t, "data-lake-analyst", name)
+
+	// Existing profiles are kept, unlike WriteConfigFile
+	profile, err := ReadProfileFromConfig("legacy")
+	require.NoError(t, err)
+	assert.Equal(t, ProfileTypeAssumeRole, profile.ProfileType)
+	profile, err = ReadProfileFromConfig("data-lake-analyst")
+	require.NoError(t, err)
+	assert.Equal(t, "333333333333", profile.AccountID)
+	assert.Equal(t, "eu-west-1", profile.SSORegion)
+
+	portals, err = ConfiguredSSOPortals()
+	require.NoError(t, err)
+	assert.Equal(t, []SSOPortal{
+		{StartURL: "https://org-a.awsapps.com/start", Region: "us-east-1"},
+		{StartURL: "https://org-b.awsapps.com/start", Region: "eu-west-1"},
+	}, portals)
+}
+
+func TestReadAllProfilesFromConfigFreshMachine(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("AWS_CONFIG_FILE", "")
+
+	_, err := ReadAllProfilesFromConfig()
+	assert.ErrorIs(t, err, ErrNoAWSConfig)
+	assert.ErrorContains(t, err, "ark bootstrap")
+
+	// A layered file alone is a configured machine, even without ~/.aws/config
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".aws"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".aws", "custom_config"), []byte(`[profile dev]
+sso_start_url = https://example.awsapps.com/start
+sso_account_id = 111111111111
+sso_role_name = ReadOnly
+`), 0600))
+	profiles, err := ReadAllProfilesFromConfig()
+	require.NoError(t, err)
+	assert.Len(t, profiles, 1)
+
+	// An empty config has no profiles, but isn't a first run
+	require.NoError(t, os.Remove(filepath.Join(home, ".aws", "custom_config")))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".aws", "config"), nil, 0600))
+	profiles, err = ReadAllProfilesFromConfig()
+	require.NoError(t, err)
+	assert.Empty(t, profiles)
+}
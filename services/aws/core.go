@@ -3,10 +3,11 @@ package services_aws
 import (
 	"context"
 	"fmt"
+	"time"
 
+	ark_config "github.com/andresgarcia29/ark-cli/config"
 	"github.com/andresgarcia29/ark-cli/logs"
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/eks"
 	"github.com/aws/aws-sdk-go-v2/service/sso"
 	"github.com/aws/aws-sdk-go-v2/service/ssooidc"
@@ -25,18 +26,15 @@ func NewSSOClient(ctx context.Context, region, startURL string) (*SSOClient, err
 
 	// Use anonymous credentials to avoid loading profile configuration
 	// SSO device authorization flow doesn't require existing credentials
-	cfg, err := config.LoadDefaultConfig(ctx,
-		config.WithRegion(region),
-		config.WithCredentialsProvider(aws.AnonymousCredentials{}),
-	)
+	cfg, err := NewAWSConfig(ctx, ClientConfig{Region: region, Anonymous: true})
 	if err != nil {
 		logger.Errorw("Failed to load SDK config", "region", region, "error", err)
 		return nil, fmt.Errorf("unable to load SDK config: %w", err)
 	}
 
 	client := &SSOClient{
-		oidcClient: ssooidc.NewFromConfig(cfg),
-		ssoClient:  sso.NewFromConfig(cfg),
+		oidcClient: newSSOOIDCClient(cfg),
+		ssoClient:  newSSOClient(cfg),
 		Region:     region,
 		StartURL:   startURL,
 	}
@@ -50,6 +48,8 @@ type ClientRegistration struct {
 	ClientID     string
 	ClientSecret string
 	ExpiresAt    int64 // Unix timestamp of when it expires
+	// AuthorizationEndpoint is where authorization code clients send the browser; empty for device code clients
+	AuthorizationEndpoint string
 }
 
 // RegisterClient registers the application as a client with AWS SSO
@@ -114,6 +114,8 @@ type Account struct {
 type Role struct {
 	RoleName  string
 	AccountID string
+	// Description is the hint configured in aws.role_descriptions, if any
+	Description string
 }
 
 // AWSProfile represents a combination of account and role
@@ -122,14 +124,19 @@ type AWSProfile struct {
 	AccountName  string
 	RoleName     string
 	EmailAddress string
+	// ProfileName is the name written to the AWS config; empty means the default account-role name
+	ProfileName string `json:",omitempty"`
+	// Tags are the account's tags in AWS Organizations; nil when they weren't read
+	Tags map[string]string `json:",omitempty"`
 }
 
 // ProfileType represents the profile type
 type ProfileType string
 
 const (
-	ProfileTypeSSO        ProfileType = "sso"
-	ProfileTypeAssumeRole ProfileType = "assume_role"
+	ProfileTypeSSO         ProfileType = "sso"
+	ProfileTypeAssumeRole  ProfileType = "assume_role"
+	ProfileTypeWebIdentity ProfileType = "web_identity"
 )
 
 // ProfileConfig represents the configuration of an AWS profile
@@ -145,6 +152,12 @@ type ProfileConfig struct {
 	RoleARN       string
 	SourceProfile string
 	ExternalID    string
+	// SessionName overrides ark's session name template (role_session_name)
+	SessionName string
+	// Web identity fields
+	WebIdentityTokenFile string
+	// Tags are the account tags bootstrap wrote in the "# ark tags:" comment above the profile
+	Tags map[string]string
 }
 
 // Credentials represents temporary AWS credentials
@@ -152,7 +165,33 @@ type Credentials struct {
 	AccessKeyID     string
 	SecretAccessKey string
 	SessionToken    string
-	Expiration      int64 // Unix timestamp
+	Expiration      int64 // Unix timestamp in milliseconds, as returned by GetRoleCredentials
+}
+
+// ValidFor returns how long something expiring at expiresAt can still be used at now, zero or less once it can't
+// aws.clock_skew is taken off, so a clock running behind AWS's doesn't send tokens or credentials AWS already refuses
+func ValidFor(expiresAt, now time.Time) time.Duration {
+	return validFor(expiresAt, now, ark_config.Get().AWS.ClockSkew)
+}
+
+// validFor is ValidFor with the clock skew given; a negative skew counts as none
+func validFor(expiresAt, now time.Time, skew time.Duration) time.Duration {
+	return expiresAt.Sub(now) - max(skew, 0)
+}
+
+// ExpiresAt returns when the credentials expire
+func (c *Credentials) ExpiresAt() time.Time {
+	return time.UnixMilli(c.Expiration)
+}
+
+// IsExpired reports whether the credentials expire within skew, so they are not used just before AWS refuses them
+func (c *Credentials) IsExpired(skew time.Duration) bool {
+	return validFor(c.ExpiresAt(), time.Now(), skew) <= 0
+}
+
+// TimeRemaining returns how long the credentials are still valid, zero once they expired
+func (c *Credentials) TimeRemaining() time.Duration {
+	return max(validFor(c.ExpiresAt(), time.Now(), 0), 0)
 }
 
 // EKSCluster represents an EKS cluster
@@ -161,6 +200,11 @@ type EKSCluster struct {
 	Region    string
 	AccountID string
 	Profile   string
+	// Details populated by DescribeCluster
+	ARN                      string
+	Endpoint                 string
+	CertificateAuthorityData string
+	Version                  string
 }
 
 // EKSClient encapsulates the EKS client
@@ -174,17 +218,14 @@ func NewEKSClient(ctx context.Context, region, profile string) (*EKSClient, erro
 	logger := logs.GetLogger()
 	logger.Debugw("Creating new EKS client", "region", region, "profile", profile)
 
-	cfg, err := config.LoadDefaultConfig(ctx,
-		config.WithRegion(region),
-		config.WithSharedConfigProfile(profile),
-	)
+	cfg, err := NewAWSConfig(ctx, ClientConfig{Region: region, Profile: profile})
 	if err != nil {
 		logger.Errorw("Failed to load SDK config for EKS client", "region", region, "profile", profile, "error", err)
 		return nil, fmt.Errorf("unable to load SDK config: %w", err)
 	}
 
 	client := &EKSClient{
-		client: eks.NewFromConfig(cfg),
+		client: newEKSClient(cfg),
 		region: region,
 	}
 
@@ -4,159 +4,179 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
+	"slices"
+	"sort"
 	"time"
 
+	ark_config "github.com/andresgarcia29/ark-cli/config"
 	"github.com/andresgarcia29/ark-cli/logs"
 )
 
-// WriteCredentialsFile writes credentials to ~/.aws/credentials
+// WriteCredentialsFile writes credentials to ~/.aws/credentials (or AWS_SHARED_CREDENTIALS_FILE)
 // If setAsDefault is true, it also writes them to the [default] profile
 func WriteCredentialsFile(profileName string, creds *Credentials, setAsDefault bool) error {
+	entries := map[string]*Credentials{profileName: creds}
+	if setAsDefault {
+		entries["default"] = creds
+	}
+	return WriteCredentials(entries)
+}
+
+// WriteCredentials writes the credentials of several profiles to the credentials file in a single write
+// Other profiles already in the file are kept
+func WriteCredentials(entries map[string]*Credentials) error {
 	logger := logs.GetLogger()
-	logger.Infow("Writing credentials file", "profile", profileName, "set_as_default", setAsDefault)
+	logger.Infow("Writing credentials file", "profiles", len(entries))
 
-	homeDir, err := os.UserHomeDir()
+	credentialsPath, err := CredentialsFilePath()
 	if err != nil {
-		logger.Errorw("Failed to get home directory", "error", err)
-		return fmt.Errorf("failed to get home directory: %w", err)
+		logger.Errorw("Failed to resolve credentials file path", "error", err)
+		return err
 	}
-
-	awsDir := filepath.Join(homeDir, ".aws")
-	credentialsPath := filepath.Join(awsDir, "credentials")
+	credentialsDir := filepath.Dir(credentialsPath)
 	logger.Debugw("Credentials file path", "path", credentialsPath)
 
 	// Create directory if it doesn't exist
-	logger.Debugw("Ensuring .aws directory exists", "path", awsDir)
-	if err := os.MkdirAll(awsDir, 0700); err != nil {
-		logger.Errorw("Failed to create .aws directory", "path", awsDir, "error", err)
-		return fmt.Errorf("failed to create .aws directory: %w", err)
+	logger.Debugw("Ensuring credentials directory exists", "path", credentialsDir)
+	if err := os.MkdirAll(credentialsDir, 0700); err != nil {
+		logger.Errorw("Failed to create credentials directory", "path", credentialsDir, "error", err)
+		return fmt.Errorf("failed to create credentials directory: %w", err)
 	}
 
-	// Read existing file if it exists
-	existingContent := make(map[string]map[string]string)
+	// Read existing file if it exists; only the written sections change, everything else is kept as is
+	doc := &iniDocument{}
 	if data, err := os.ReadFile(credentialsPath); err == nil {
 		logger.Debug("Reading existing credentials file")
-		existingContent = parseINIFile(string(data))
-		logger.Debugw("Existing profiles found", "count", len(existingContent))
-	} else {
+		doc = parseINIDocument(string(data))
+		logger.Debugw("Existing profiles found", "count", len(doc.sections))
+	} else if os.IsNotExist(err) {
 		logger.Debug("No existing credentials file found, creating new one")
-	}
-
-	// Calculate expiration time
-	expirationTime := time.Unix(creds.Expiration/1000, 0) // Convert from milliseconds
-	logger.Debugw("Credentials expiration", "expiration_time", expirationTime.Format(time.RFC3339))
-
-	// Update/add the specific profile
-	if existingContent[profileName] == nil {
-		existingContent[profileName] = make(map[string]string)
-		logger.Debugw("Creating new profile section", "profile", profileName)
 	} else {
-		logger.Debugw("Updating existing profile", "profile", profileName)
+		return fmt.Errorf("failed to read credentials file: %w", err)
 	}
-	existingContent[profileName]["aws_access_key_id"] = creds.AccessKeyID
-	existingContent[profileName]["aws_secret_access_key"] = creds.SecretAccessKey
-	existingContent[profileName]["aws_session_token"] = creds.SessionToken
-	existingContent[profileName]["expiration"] = expirationTime.Format(time.RFC3339)
 
-	// If required, also set as default
-	if setAsDefault {
-		logger.Debug("Setting credentials as default profile")
-		if existingContent["default"] == nil {
-			existingContent["default"] = make(map[string]string)
-		}
-		existingContent["default"]["aws_access_key_id"] = creds.AccessKeyID
-		existingContent["default"]["aws_secret_access_key"] = creds.SecretAccessKey
-		existingContent["default"]["aws_session_token"] = creds.SessionToken
-		existingContent["default"]["expiration"] = expirationTime.Format(time.RFC3339)
+	// Update/add each profile, in a stable order for new sections
+	profileNames := make([]string, 0, len(entries))
+	for profileName := range entries {
+		profileNames = append(profileNames, profileName)
 	}
-
-	// Generate file content
-	var content strings.Builder
-	logger.Debug("Generating credentials file content")
-
-	// Write default first if it exists
-	if defaultCreds, ok := existingContent["default"]; ok {
-		logger.Debug("Writing default profile section")
-		content.WriteString("[default]\n")
-		writeCredentialSection(&content, defaultCreds)
-		content.WriteString("\n")
+	sort.Strings(profileNames)
+	for _, profileName := range profileNames {
+		logger.Debugw("Writing profile section", "profile", profileName)
+		setCredentialSection(doc, profileName, entries[profileName])
 	}
 
-	// Write other profiles
-	profileCount := 0
-	for profile, creds := range existingContent {
-		if profile == "default" {
-			continue // Already written
+	// Drop entries ark wrote earlier that have expired since
+	for _, profile := range expiredProfiles(doc, ark_config.Get().AWS.ClockSkew) {
+		if _, ok := entries[profile]; !ok {
+			logger.Debugw("Pruning expired credentials", "profile", profile)
+			doc.remove(profile)
 		}
-		profileCount++
-		logger.Debugw("Writing profile section", "profile", profile)
-		content.WriteString(fmt.Sprintf("[%s]\n", profile))
-		writeCredentialSection(&content, creds)
-		content.WriteString("\n")
 	}
 
-	logger.Debugw("Generated credentials file content", "total_profiles", profileCount+1)
-
 	// Write file
 	logger.Debugw("Writing credentials file", "path", credentialsPath)
-	if err := os.WriteFile(credentialsPath, []byte(content.String()), 0600); err != nil {
+	if err := writeFileAtomic(credentialsPath, []byte(doc.String()), 0600); err != nil {
 		logger.Errorw("Failed to write credentials file", "path", credentialsPath, "error", err)
 		return fmt.Errorf("failed to write credentials file: %w", err)
 	}
 
-	logger.Infow("Credentials file written successfully", "profile", profileName, "path", credentialsPath)
+	logger.Infow("Credentials file written successfully", "profiles", len(entries), "path", credentialsPath)
 	return nil
 }
 
-// parseINIFile parses a simple INI file
-func parseINIFile(content string) map[string]map[string]string {
-	result := make(map[string]map[string]string)
-	lines := strings.Split(content, "\n")
-	var currentSection string
+// PruneCredentialsFile removes the entries whose expiration has passed from the credentials file
+// Only the entries ark wrote, marked with arkManagedComment, are considered; with dryRun the file is left untouched
+func PruneCredentialsFile(dryRun bool) ([]string, error) {
+	credentialsPath, err := CredentialsFilePath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(credentialsPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credentials file: %w", err)
+	}
 
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
+	doc := parseINIDocument(string(data))
+	expired := expiredProfiles(doc, ark_config.Get().AWS.ClockSkew)
+	if len(expired) == 0 || dryRun {
+		return expired, nil
+	}
 
-		// Ignore empty lines and comments
-		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
-			continue
-		}
+	for _, profile := range expired {
+		doc.remove(profile)
+	}
+	if err := writeFileAtomic(credentialsPath, []byte(doc.String()), 0600); err != nil {
+		return nil, fmt.Errorf("failed to write credentials file: %w", err)
+	}
+	logs.GetLogger().Infow("Pruned expired credentials", "profiles", expired, "path", credentialsPath)
+	return expired, nil
+}
 
-		// Detect section
-		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
-			currentSection = strings.Trim(line, "[]")
-			result[currentSection] = make(map[string]string)
+// expiredProfiles returns, sorted, the sections ark manages that are expired or expire within skew
+// Only sections carrying the arkManagedComment count: entries written by other tools (aws-vault, saml2aws) or by
+// hand are left alone even once expired, as are sections without a parsable expiration such as static keys
+func expiredProfiles(doc *iniDocument, skew time.Duration) []string {
+	var expired []string
+	for _, section := range doc.sections {
+		if !section.hasComment(arkManagedComment) || slices.Contains(expired, section.name) {
 			continue
 		}
-
-		// Parse key=value
-		if currentSection != "" && strings.Contains(line, "=") {
-			parts := strings.SplitN(line, "=", 2)
-			if len(parts) == 2 {
-				key := strings.TrimSpace(parts[0])
-				value := strings.TrimSpace(parts[1])
-				result[currentSection][key] = value
-			}
+		value, _ := section.get("expiration")
+		expiration, err := time.Parse(time.RFC3339, value)
+		if err == nil && (&Credentials{Expiration: expiration.UnixMilli()}).IsExpired(skew) {
+			expired = append(expired, section.name)
 		}
 	}
+	sort.Strings(expired)
+	return expired
+}
 
-	return result
+// arkManagedComment starts the comment ark puts above the sections it manages, in the credentials and config files
+const arkManagedComment = "# managed by ark"
+
+// setCredentialSection writes temporary credentials into a section, keeping its other keys and comments
+// A new [default] goes first, where the AWS CLI writes it too
+func setCredentialSection(doc *iniDocument, profileName string, creds *Credentials) {
+	expiration := creds.ExpiresAt().Format(time.RFC3339)
+
+	section := doc.upsert(profileName, profileName == "default")
+	section.setComment(arkManagedComment, fmt.Sprintf("%s, expires %s", arkManagedComment, expiration))
+	section.set("aws_access_key_id", creds.AccessKeyID)
+	section.set("aws_secret_access_key", creds.SecretAccessKey)
+	section.set("aws_session_token", creds.SessionToken)
+	section.set("expiration", expiration)
 }
 
-// writeCredentialSection writes a credentials section
-func writeCredentialSection(builder *strings.Builder, creds map[string]string) {
-	// Specific order for credentials
-	if val, ok := creds["aws_access_key_id"]; ok {
-		fmt.Fprintf(builder, "aws_access_key_id = %s\n", val)
+// HasStaticDefaultCredentials reports whether the [default] profile in ~/.aws/credentials holds
+// long-lived keys (no expiration), i.e. credentials ark did not write and would overwrite
+func HasStaticDefaultCredentials() (bool, error) {
+	credentialsPath, err := CredentialsFilePath()
+	if err != nil {
+		return false, err
 	}
-	if val, ok := creds["aws_secret_access_key"]; ok {
-		fmt.Fprintf(builder, "aws_secret_access_key = %s\n", val)
+
+	data, err := os.ReadFile(credentialsPath)
+	if os.IsNotExist(err) {
+		return false, nil
 	}
-	if val, ok := creds["aws_session_token"]; ok {
-		fmt.Fprintf(builder, "aws_session_token = %s\n", val)
+	if err != nil {
+		return false, fmt.Errorf("failed to read credentials file: %w", err)
 	}
-	if val, ok := creds["expiration"]; ok {
-		fmt.Fprintf(builder, "expiration = %s\n", val)
+
+	defaultCreds, ok := parseINIFile(string(data))["default"]
+	if !ok || defaultCreds["aws_access_key_id"] == "" {
+		return false, nil
 	}
+	_, hasExpiration := defaultCreds["expiration"]
+	return !hasExpiration, nil
+}
+
+// parseINIFile returns the keys of every section of an INI file
+func parseINIFile(content string) map[string]map[string]string {
+	return parseINIDocument(content).values()
 }
@@ -0,0 +1,215 @@
+package services_aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/andresgarcia29/ark-cli/lib"
+	"github.com/andresgarcia29/ark-cli/logs"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/ssocreds"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go"
+)
+
+// CredentialHealth is the outcome of checking the credentials of a profile
+type CredentialHealth string
+
+const (
+	// CredentialHealthOK means AWS accepted the credentials of the profile
+	CredentialHealthOK CredentialHealth = "ok"
+	// CredentialHealthNeedsLogin means the SSO session or the source credentials expired
+	CredentialHealthNeedsLogin CredentialHealth = "needs-login"
+	// CredentialHealthMisconfigured means the profile is incomplete, or points to a role it can't use
+	CredentialHealthMisconfigured CredentialHealth = "misconfigured"
+	// CredentialHealthUnknown means the check failed for another reason, e.g. the network
+	CredentialHealthUnknown CredentialHealth = "unknown"
+)
+
+// CallerIdentity is who AWS sees behind a profile's credentials
+type CallerIdentity struct {
+	AccountID string
+	ARN       string
+}
+
+// IdentityChecker returns the identity behind the credentials of a profile
+type IdentityChecker interface {
+	CallerIdentity(ctx context.Context) (*CallerIdentity, error)
+}
+
+// IdentityCheckerFactory creates the IdentityChecker of a profile
+type IdentityCheckerFactory func(ctx context.Context, profile string) (IdentityChecker, error)
+
+// STSClient calls STS with the credentials the SDK resolves for a profile, the way the AWS CLI would
+type STSClient struct {
+	client *sts.Client
+}
+
+// NewIdentityChecker is the default IdentityCheckerFactory, backed by sts:GetCallerIdentity
+func NewIdentityChecker(ctx context.Context, profile string) (IdentityChecker, error) {
+	cfg, err := NewAWSConfig(ctx, ClientConfig{Profile: profile})
+	if err != nil {
+		return nil, fmt.Errorf("unable to load SDK config: %w", err)
+	}
+	return &STSClient{client: newSTSClient(cfg)}, nil
+}
+
+// CallerIdentity calls sts:GetCallerIdentity, which needs no permission and works with any valid credentials
+func (c *STSClient) CallerIdentity(ctx context.Context) (*CallerIdentity, error) {
+	output, err := c.client.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return nil, err
+	}
+	return &CallerIdentity{AccountID: aws.ToString(output.Account), ARN: aws.ToString(output.Arn)}, nil
+}
+
+// CredentialCheck is the outcome of checking one profile
+type CredentialCheck struct {
+	Profile  ProfileConfig
+	Health   CredentialHealth
+	Identity *CallerIdentity
+	Duration time.Duration
+	Err      error
+}
+
+// CheckCredentials checks the credentials of profiles in parallel with one sts:GetCallerIdentity each
+// onResult is called as each profile finishes, from several goroutines; results keep the order of profiles
+func CheckCredentials(ctx context.Context, profiles []ProfileConfig, newChecker IdentityCheckerFactory, onResult func(CredentialCheck)) []CredentialCheck {
+	logger := logs.GetLogger()
+
+	byName := make(map[string]ProfileConfig, len(profiles))
+	names := make([]string, 0, len(profiles))
+	for _, profile := range profiles {
+		byName[profile.ProfileName] = profile
+		names = append(names, profile.ProfileName)
+	}
+
+	var mu sync.Mutex
+	checksByName := make(map[string]CredentialCheck, len(profiles))
+
+	config := lib.ServiceConfig(lib.ServiceSTS)
+	config.Phase = "the credential checks"
+	_, errs := lib.ProcessAccountsInParallel(ctx, names, config,
+		func(ctx context.Context, name string) (*CallerIdentity, error) {
+			started := time.Now()
+			check := checkProfileCredentials(ctx, byName[name], newChecker)
+			check.Duration = time.Since(started)
+			if check.Err != nil {
+				logger.Debugw("Credential check failed", "profile", name, "health", check.Health, "error", check.Err)
+			}
+
+			mu.Lock()
+			checksByName[name] = check
+			mu.Unlock()
+			if onResult != nil {
+				onResult(check)
+			}
+			if check.Err != nil {
+				// The failure is the answer, checking the profile again would report it twice
+				return nil, lib.Permanent(check.Err)
+			}
+			return check.Identity, nil
+		},
+	)
+
+	notProcessed := ctx.Err()
+	var deadlineErr *lib.DeadlineError
+	for _, err := range errs {
+		if notProcessed == nil && errors.As(err, &deadlineErr) {
+			notProcessed = deadlineErr
+		}
+	}
+	checks := make([]CredentialCheck, 0, len(profiles))
+	for _, name := range names {
+		check, ok := checksByName[name]
+		if !ok {
+			check = CredentialCheck{Profile: byName[name], Health: CredentialHealthUnknown, Err: fmt.Errorf("profile %s was not checked: %w", name, notProcessed)}
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// checkProfileCredentials checks one profile, without calling AWS when its configuration or SSO session
+// already tells the answer
+func checkProfileCredentials(ctx context.Context, profile ProfileConfig, newChecker IdentityCheckerFactory) CredentialCheck {
+	check := CredentialCheck{Profile: profile}
+	if err := profileConfigProblem(profile); err != nil {
+		check.Health, check.Err = CredentialHealthMisconfigured, err
+		return check
+	}
+	if profile.ProfileType == ProfileTypeSSO {
+		if _, err := ReadTokenFromCache(profile.StartURL, profile.SSORegion); err != nil {
+			check.Health = CredentialHealthNeedsLogin
+			if errors.Is(err, ErrInsecureTokenCache) {
+				check.Health = CredentialHealthMisconfigured
+			}
+			check.Err = fmt.Errorf("no valid SSO session for %s: %w", profile.StartURL, err)
+			return check
+		}
+	}
+
+	checker, err := newChecker(ctx, profile.ProfileName)
+	if err == nil {
+		check.Identity, err = checker.CallerIdentity(ctx)
+	}
+	check.Health, check.Err = ClassifyCredentialError(err), err
+	return check
+}
+
+// profileConfigProblem returns what is missing for ark or the SDK to get credentials for the profile
+func profileConfigProblem(profile ProfileConfig) error {
+	switch profile.ProfileType {
+	case ProfileTypeSSO:
+		if profile.StartURL == "" || profile.SSORegion == "" {
+			return fmt.Errorf("sso_start_url and sso_region are required for SSO profile %s", profile.ProfileName)
+		}
+		if profile.RoleName == "" {
+			return fmt.Errorf("sso_role_name is required for SSO profile %s", profile.ProfileName)
+		}
+	case ProfileTypeAssumeRole:
+		if profile.SourceProfile == "" {
+			return fmt.Errorf("source_profile is required for assume role profile %s", profile.ProfileName)
+		}
+	case ProfileTypeWebIdentity:
+		if _, err := os.Stat(profile.WebIdentityTokenFile); err != nil {
+			return fmt.Errorf("web_identity_token_file of profile %s can't be read: %w", profile.ProfileName, err)
+		}
+	default:
+		return fmt.Errorf("profile %s has neither SSO, role_arn nor web identity settings", profile.ProfileName)
+	}
+	return nil
+}
+
+// ClassifyCredentialError tells whether err, from resolving or using the credentials of a profile,
+// means signing in again or fixing the profile; nil is CredentialHealthOK
+func ClassifyCredentialError(err error) CredentialHealth {
+	if err == nil {
+		return CredentialHealthOK
+	}
+
+	var invalidToken *ssocreds.InvalidTokenError
+	var profileNotExist config.SharedConfigProfileNotExistError
+	var assumeRoleErr config.SharedConfigAssumeRoleError
+	var apiErr smithy.APIError
+	switch {
+	case errors.As(err, &invalidToken):
+		return CredentialHealthNeedsLogin
+	case errors.As(err, &profileNotExist), errors.As(err, &assumeRoleErr):
+		return CredentialHealthMisconfigured
+	case errors.As(err, &apiErr):
+		switch apiErr.ErrorCode() {
+		case "UnauthorizedException", "ExpiredToken", "ExpiredTokenException", "InvalidGrantException", "RequestExpired":
+			return CredentialHealthNeedsLogin
+		case "AccessDenied", "AccessDeniedException", "ForbiddenException", "ResourceNotFoundException",
+			"InvalidClientTokenId", "InvalidIdentityToken", "ValidationError":
+			return CredentialHealthMisconfigured
+		}
+	}
+	return CredentialHealthUnknown
+}
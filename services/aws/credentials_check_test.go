@@ -0,0 +1,98 @@
+package services_aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/ssocreds"
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeIdentityChecker answers for a profile from memory
+type fakeIdentityChecker struct {
+	profile string
+	errs    map[string]error
+}
+
+func (f *fakeIdentityChecker) CallerIdentity(ctx context.Context) (*CallerIdentity, error) {
+	if err := f.errs[f.profile]; err != nil {
+		return nil, err
+	}
+	return &CallerIdentity{AccountID: "111111111111", ARN: "arn:aws:sts::111111111111:assumed-role/" + f.profile + "/ark"}, nil
+}
+
+func TestClassifyCredentialError(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want CredentialHealth
+	}{
+		{nil, CredentialHealthOK},
+		{fmt.Errorf("get identity: %w", &ssocreds.InvalidTokenError{}), CredentialHealthNeedsLogin},
+		{&smithy.GenericAPIError{Code: "UnauthorizedException"}, CredentialHealthNeedsLogin},
+		{&smithy.GenericAPIError{Code: "ExpiredToken"}, CredentialHealthNeedsLogin},
+		{&smithy.GenericAPIError{Code: "ForbiddenException"}, CredentialHealthMisconfigured},
+		{&smithy.GenericAPIError{Code: "AccessDenied"}, CredentialHealthMisconfigured},
+		{config.SharedConfigProfileNotExistError{Profile: "gone"}, CredentialHealthMisconfigured},
+		{&smithy.GenericAPIError{Code: "Throttling"}, CredentialHealthUnknown},
+		{errors.New("dial tcp: i/o timeout"), CredentialHealthUnknown},
+	} {
+		assert.Equal(t, tc.want, ClassifyCredentialError(tc.err), "%v", tc.err)
+	}
+}
+
+func TestCheckCredentials(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	startURL := "https://example.awsapps.com/start"
+	writeCachedSSOToken(t, home, startURL)
+
+	profiles := []ProfileConfig{
+		{ProfileName: "a-readonly", ProfileType: ProfileTypeSSO, StartURL: startURL, SSORegion: "us-east-1", AccountID: "111111111111", RoleName: "ReadOnly"},
+		{ProfileName: "b-admin", ProfileType: ProfileTypeSSO, StartURL: startURL, SSORegion: "us-east-1", AccountID: "222222222222", RoleName: "Admin"},
+		{ProfileName: "c-other-portal", ProfileType: ProfileTypeSSO, StartURL: "https://other.awsapps.com/start", SSORegion: "us-east-1", AccountID: "333333333333", RoleName: "ReadOnly"},
+		{ProfileName: "d-deploy", ProfileType: ProfileTypeAssumeRole, RoleARN: "arn:aws:iam::444444444444:role/Deploy"},
+		{ProfileName: "e-deploy", ProfileType: ProfileTypeAssumeRole, RoleARN: "arn:aws:iam::555555555555:role/Deploy", SourceProfile: "a-readonly"},
+	}
+	errs := map[string]error{"b-admin": &smithy.GenericAPIError{Code: "ForbiddenException", Message: "No access"}}
+	var mu sync.Mutex
+	var called []string
+	newChecker := func(ctx context.Context, profile string) (IdentityChecker, error) {
+		mu.Lock()
+		called = append(called, profile)
+		mu.Unlock()
+		return &fakeIdentityChecker{profile: profile, errs: errs}, nil
+	}
+
+	var reported int
+	checks := CheckCredentials(context.Background(), profiles, newChecker, func(CredentialCheck) {
+		mu.Lock()
+		reported++
+		mu.Unlock()
+	})
+	require.Len(t, checks, len(profiles))
+	assert.Equal(t, len(profiles), reported)
+
+	health := map[string]CredentialHealth{}
+	for i, check := range checks {
+		assert.Equal(t, profiles[i].ProfileName, check.Profile.ProfileName, "checks keep the order of profiles")
+		health[check.Profile.ProfileName] = check.Health
+	}
+	assert.Equal(t, map[string]CredentialHealth{
+		"a-readonly":     CredentialHealthOK,
+		"b-admin":        CredentialHealthMisconfigured,
+		"c-other-portal": CredentialHealthNeedsLogin,
+		"d-deploy":       CredentialHealthMisconfigured,
+		"e-deploy":       CredentialHealthOK,
+	}, health)
+	assert.Equal(t, "arn:aws:sts::111111111111:assumed-role/a-readonly/ark", checks[0].Identity.ARN)
+	assert.ErrorContains(t, checks[3].Err, "source_profile is required")
+
+	// A missing SSO session or an incomplete profile is known without calling AWS
+	assert.ElementsMatch(t, []string{"a-readonly", "b-admin", "e-deploy"}, called)
+}
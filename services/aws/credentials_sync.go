@@ -0,0 +1,181 @@
+package services_aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/andresgarcia29/ark-cli/lib"
+	"github.com/andresgarcia29/ark-cli/logs"
+)
+
+// CredentialProviderFactory creates the CredentialProvider for an SSO region and start URL
+type CredentialProviderFactory func(ctx context.Context, region, startURL string) (CredentialProvider, error)
+
+// NewCredentialProvider is the default CredentialProviderFactory, backed by the SSO API
+func NewCredentialProvider(ctx context.Context, region, startURL string) (CredentialProvider, error) {
+	return NewSSOClient(ctx, region, startURL)
+}
+
+// CredentialsResult is the outcome of fetching the credentials of one profile
+type CredentialsResult struct {
+	Profile     ProfileConfig
+	Credentials *Credentials
+	Duration    time.Duration
+	Err         error
+}
+
+// FilterSSOProfiles returns the SSO profiles whose name matches one of patterns (path.Match globs), sorted by name
+// Without patterns every SSO profile is returned
+func FilterSSOProfiles(profiles []ProfileConfig, patterns []string) ([]ProfileConfig, error) {
+	var sso []ProfileConfig
+	for _, profile := range profiles {
+		if profile.ProfileType == ProfileTypeSSO {
+			sso = append(sso, profile)
+		}
+	}
+	return FilterProfiles(sso, patterns)
+}
+
+// FilterProfiles returns the profiles whose name matches one of patterns (path.Match globs), sorted by name
+// Without patterns every profile is returned
+func FilterProfiles(profiles []ProfileConfig, patterns []string) ([]ProfileConfig, error) {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid profile pattern %q: %w", pattern, err)
+		}
+	}
+
+	var selected []ProfileConfig
+	for _, profile := range profiles {
+		if len(patterns) > 0 && !matchesAnyGlob(profile.ProfileName, patterns) {
+			continue
+		}
+		selected = append(selected, profile)
+	}
+
+	sort.Slice(selected, func(i, j int) bool {
+		return selected[i].ProfileName < selected[j].ProfileName
+	})
+	return selected, nil
+}
+
+// matchesAnyGlob reports whether name matches one of the already validated patterns
+func matchesAnyGlob(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// FetchSSOCredentials gets role credentials for SSO profiles in parallel, with one provider per SSO region and start URL
+// onResult is called as each profile finishes, from several goroutines; results keep the order of profiles
+func FetchSSOCredentials(ctx context.Context, profiles []ProfileConfig, newProvider CredentialProviderFactory, onResult func(CredentialsResult)) []CredentialsResult {
+	logger := logs.GetLogger()
+
+	var mu sync.Mutex
+	providers := make(map[string]CredentialProvider)
+	providerFor := func(ctx context.Context, profile ProfileConfig) (CredentialProvider, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		key := profile.SSORegion + "|" + profile.StartURL
+		if provider, ok := providers[key]; ok {
+			return provider, nil
+		}
+		provider, err := newProvider(ctx, profile.SSORegion, profile.StartURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create SSO client: %w", err)
+		}
+		providers[key] = provider
+		return provider, nil
+	}
+
+	byName := make(map[string]ProfileConfig, len(profiles))
+	names := make([]string, 0, len(profiles))
+	for _, profile := range profiles {
+		byName[profile.ProfileName] = profile
+		names = append(names, profile.ProfileName)
+	}
+
+	var resultsMu sync.Mutex
+	resultsByName := make(map[string]CredentialsResult, len(profiles))
+
+	config := lib.ServiceConfig(lib.ServiceSSO)
+	config.Phase = "fetching credentials"
+	_, errs := lib.ProcessAccountsInParallel(ctx, names, config,
+		func(ctx context.Context, name string) (*Credentials, error) {
+			profile := byName[name]
+			started := time.Now()
+
+			provider, err := providerFor(ctx, profile)
+			var creds *Credentials
+			if err == nil {
+				creds, err = SSORoleCredentials(ctx, provider, &profile)
+			}
+			if err != nil {
+				logger.Warnw("Failed to get credentials", "profile", name, "error", err)
+			}
+
+			result := CredentialsResult{Profile: profile, Credentials: creds, Duration: time.Since(started), Err: err}
+			resultsMu.Lock()
+			resultsByName[name] = result
+			resultsMu.Unlock()
+			if onResult != nil {
+				onResult(result)
+			}
+			if err != nil {
+				// The SDK already retries throttling; a retry here would report the profile twice
+				return nil, lib.Permanent(err)
+			}
+			return creds, nil
+		},
+	)
+
+	// Profiles never fetched were cancelled by the caller, or cut off by the time budget
+	notProcessed := ctx.Err()
+	var deadlineErr *lib.DeadlineError
+	for _, err := range errs {
+		if notProcessed == nil && errors.As(err, &deadlineErr) {
+			notProcessed = deadlineErr
+		}
+	}
+	results := make([]CredentialsResult, 0, len(profiles))
+	for _, name := range names {
+		result, ok := resultsByName[name]
+		if !ok {
+			result = CredentialsResult{Profile: byName[name], Err: fmt.Errorf("profile %s was not processed: %w", name, notProcessed)}
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// SaveSyncedCredentials writes the successful results to the credentials file at once and audits them
+func SaveSyncedCredentials(results []CredentialsResult) (int, error) {
+	entries := make(map[string]*Credentials)
+	for _, result := range results {
+		if result.Err == nil && result.Credentials != nil {
+			entries[result.Profile.ProfileName] = result.Credentials
+		}
+	}
+	if len(entries) == 0 {
+		return 0, nil
+	}
+
+	if err := WriteCredentials(entries); err != nil {
+		return 0, fmt.Errorf("failed to write credentials: %w", err)
+	}
+	for _, result := range results {
+		if _, ok := entries[result.Profile.ProfileName]; ok {
+			recordIssuedCredentials(result.Profile, result.Credentials)
+		}
+	}
+	return len(entries), nil
+}
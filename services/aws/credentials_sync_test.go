@@ -0,0 +1,144 @@
+package services_aws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCredentialProvider issues credentials from memory, failing for the roles in errs
+type fakeCredentialProvider struct {
+	errs map[string]error
+}
+
+func (f *fakeCredentialProvider) GetRoleCredentials(ctx context.Context, accessToken, accountID, roleName string) (*Credentials, error) {
+	if err := f.errs[roleName]; err != nil {
+		return nil, err
+	}
+	return &Credentials{
+		AccessKeyID:     "AKIA" + accountID,
+		SecretAccessKey: "secret-" + roleName,
+		SessionToken:    "token-" + accessToken,
+		Expiration:      time.Now().Add(time.Hour).UnixMilli(),
+	}, nil
+}
+
+// writeCachedSSOToken stores a valid SSO access token for startURL under home
+func writeCachedSSOToken(t *testing.T, home, startURL string) {
+	t.Helper()
+	dir := filepath.Join(home, ".aws", "sso", "cache")
+	require.NoError(t, os.MkdirAll(dir, 0700))
+	data, err := json.Marshal(CachedToken{
+		StartURL:    startURL,
+		Region:      "us-east-1",
+		AccessToken: "sso-token",
+		ExpiresAt:   time.Now().Add(time.Hour).UTC().Format(time.RFC3339),
+	})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, generateCacheFileName(startURL)), data, 0600))
+}
+
+func TestFilterSSOProfiles(t *testing.T) {
+	profiles := []ProfileConfig{
+		{ProfileName: "prod-readonly", ProfileType: ProfileTypeSSO},
+		{ProfileName: "dev-admin", ProfileType: ProfileTypeSSO},
+		{ProfileName: "prod-deploy", ProfileType: ProfileTypeAssumeRole},
+		{ProfileName: "prod-admin", ProfileType: ProfileTypeSSO},
+	}
+
+	all, err := FilterSSOProfiles(profiles, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dev-admin", "prod-admin", "prod-readonly"}, profileNames(all))
+
+	prod, err := FilterSSOProfiles(profiles, []string{"prod-*"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"prod-admin", "prod-readonly"}, profileNames(prod))
+
+	_, err = FilterSSOProfiles(profiles, []string{"prod-["})
+	assert.ErrorContains(t, err, `invalid profile pattern "prod-["`)
+}
+
+func TestFetchSSOCredentials(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	startURL := "https://example.awsapps.com/start"
+	writeCachedSSOToken(t, home, startURL)
+
+	profiles := []ProfileConfig{
+		{ProfileName: "a-readonly", ProfileType: ProfileTypeSSO, StartURL: startURL, SSORegion: "us-east-1", AccountID: "111111111111", RoleName: "ReadOnly"},
+		{ProfileName: "b-admin", ProfileType: ProfileTypeSSO, StartURL: startURL, SSORegion: "us-east-1", AccountID: "222222222222", RoleName: "Admin"},
+		{ProfileName: "c-readonly", ProfileType: ProfileTypeSSO, StartURL: startURL, SSORegion: "us-east-1", AccountID: "333333333333", RoleName: "ReadOnly"},
+	}
+	provider := &fakeCredentialProvider{errs: map[string]error{"Admin": errors.New("ForbiddenException")}}
+
+	var mu sync.Mutex
+	factoryCalls := 0
+	factory := func(ctx context.Context, region, url string) (CredentialProvider, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		factoryCalls++
+		return provider, nil
+	}
+	var reported []string
+	onResult := func(result CredentialsResult) {
+		mu.Lock()
+		defer mu.Unlock()
+		reported = append(reported, result.Profile.ProfileName)
+	}
+
+	results := FetchSSOCredentials(context.Background(), profiles, factory, onResult)
+	require.Len(t, results, 3)
+	assert.Equal(t, 1, factoryCalls, "one provider per SSO region and start URL")
+	assert.ElementsMatch(t, []string{"a-readonly", "b-admin", "c-readonly"}, reported, "each profile is reported once")
+
+	assert.Equal(t, "a-readonly", results[0].Profile.ProfileName)
+	require.NoError(t, results[0].Err)
+	assert.Equal(t, "token-sso-token", results[0].Credentials.SessionToken)
+	assert.ErrorContains(t, results[1].Err, "ForbiddenException")
+	assert.NoError(t, results[2].Err)
+
+	credentialsPath := filepath.Join(home, "credentials")
+	t.Setenv(CredentialsFileEnv, credentialsPath)
+	written, err := SaveSyncedCredentials(results)
+	require.NoError(t, err)
+	assert.Equal(t, 2, written)
+
+	data, err := os.ReadFile(credentialsPath)
+	require.NoError(t, err)
+	sections := parseINIFile(string(data))
+	assert.Equal(t, "AKIA111111111111", sections["a-readonly"]["aws_access_key_id"])
+	assert.Equal(t, "AKIA333333333333", sections["c-readonly"]["aws_access_key_id"])
+	assert.NotContains(t, sections, "b-admin")
+}
+
+func TestWriteCredentialsKeepsOtherProfiles(t *testing.T) {
+	credentialsPath := filepath.Join(t.TempDir(), "credentials")
+	t.Setenv(CredentialsFileEnv, credentialsPath)
+	require.NoError(t, os.WriteFile(credentialsPath, []byte("[static]\naws_access_key_id = AKIASTATIC\naws_secret_access_key = s\n"), 0600))
+
+	creds := &Credentials{AccessKeyID: "AKIANEW", SecretAccessKey: "n", SessionToken: "t", Expiration: time.Now().UnixMilli()}
+	require.NoError(t, WriteCredentials(map[string]*Credentials{"one": creds, "two": creds}))
+
+	data, err := os.ReadFile(credentialsPath)
+	require.NoError(t, err)
+	sections := parseINIFile(string(data))
+	assert.Equal(t, "AKIASTATIC", sections["static"]["aws_access_key_id"])
+	assert.Equal(t, "AKIANEW", sections["one"]["aws_access_key_id"])
+	assert.Equal(t, "AKIANEW", sections["two"]["aws_access_key_id"])
+}
+
+func profileNames(profiles []ProfileConfig) []string {
+	names := make([]string, 0, len(profiles))
+	for _, profile := range profiles {
+		names = append(names, profile.ProfileName)
+	}
+	return names
+}
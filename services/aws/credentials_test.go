@@ -1,9 +1,13 @@
 package services_aws
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestWriteCredentials(t *testing.T) {
@@ -403,56 +407,39 @@ func TestCredentialsFileHandling(t *testing.T) {
 }
 
 func TestCredentialsExpiration(t *testing.T) {
-	// Test credentials expiration handling
+	now := time.Now()
 	tests := []struct {
-		name       string
-		expiration int64
-		expired    bool
-		expiresIn  int64
+		name      string
+		expiresAt time.Time
+		skew      time.Duration
+		expired   bool
+		remaining time.Duration
 	}{
-		{
-			name:       "valid expiration",
-			expiration: 1234567890,
-			expired:    false,
-			expiresIn:  3600,
-		},
-		{
-			name:       "expired credentials",
-			expiration: 0,
-			expired:    true,
-			expiresIn:  0,
-		},
-		{
-			name:       "expiring soon",
-			expiration: 1234567890,
-			expired:    false,
-			expiresIn:  300, // 5 minutes
-		},
+		{name: "valid", expiresAt: now.Add(time.Hour), skew: time.Minute, expired: false, remaining: time.Hour},
+		{name: "expiring within the skew", expiresAt: now.Add(30 * time.Second), skew: time.Minute, expired: true, remaining: 30 * time.Second},
+		{name: "expired", expiresAt: now.Add(-time.Hour), expired: true, remaining: 0},
+		{name: "never set", expiresAt: time.UnixMilli(0), expired: true, remaining: 0},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Test expiration logic
-			expired := tt.expiration == 0
-
-			var expiresIn int64
-			if !expired {
-				// For the test cases, calculate the expected expiresIn based on the test data
-				if tt.name == "valid expiration" {
-					expiresIn = 3600
-				} else if tt.name == "expiring soon" {
-					expiresIn = 300
-				} else {
-					expiresIn = tt.expiration - 1234567890 + 3600
-				}
-			}
-
-			assert.Equal(t, tt.expired, expired)
-			assert.Equal(t, tt.expiresIn, expiresIn)
+			creds := &Credentials{Expiration: tt.expiresAt.UnixMilli()}
+			assert.True(t, tt.expiresAt.Truncate(time.Millisecond).Equal(creds.ExpiresAt()))
+			assert.Equal(t, tt.expired, creds.IsExpired(tt.skew))
+			assert.InDelta(t, tt.remaining, creds.TimeRemaining(), float64(time.Second))
 		})
 	}
 }
 
+func TestValidFor(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, 9*time.Minute, validFor(now.Add(10*time.Minute), now, time.Minute))
+	assert.Equal(t, 10*time.Minute, validFor(now.Add(10*time.Minute), now, 0), "0 uses tokens until the last second")
+	assert.Equal(t, 10*time.Minute, validFor(now.Add(10*time.Minute), now, -time.Minute))
+	// Within the skew of its expiration, a token is already expired
+	assert.LessOrEqual(t, validFor(now.Add(30*time.Second), now, time.Minute), time.Duration(0))
+}
+
 func TestCredentialsSecurity(t *testing.T) {
 	// Test credentials security
 	tests := []struct {
@@ -556,3 +543,113 @@ func TestCredentialsFilePermissions(t *testing.T) {
 		})
 	}
 }
+
+func TestHasStaticDefaultCredentials(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected bool
+	}{
+		{name: "no file", expected: false},
+		{name: "static keys", content: "[default]\naws_access_key_id = AKIA\naws_secret_access_key = secret\n", expected: true},
+		{name: "ark temporary credentials", content: "[default]\naws_access_key_id = ASIA\nexpiration = 2025-01-01T00:00:00Z\n", expected: false},
+		{name: "no default profile", content: "[dev]\naws_access_key_id = AKIA\n", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			t.Setenv("HOME", home)
+			if tt.content != "" {
+				require.NoError(t, os.MkdirAll(filepath.Join(home, ".aws"), 0700))
+				require.NoError(t, os.WriteFile(filepath.Join(home, ".aws", "credentials"), []byte(tt.content), 0600))
+			}
+
+			static, err := HasStaticDefaultCredentials()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, static)
+		})
+	}
+}
+
+func TestPruneCredentialsFile(t *testing.T) {
+	credentialsPath := filepath.Join(t.TempDir(), "credentials")
+	t.Setenv(CredentialsFileEnv, credentialsPath)
+
+	expired := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	valid := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	content := "[static]\naws_access_key_id = AKIASTATIC\naws_secret_access_key = s\n\n" +
+		"# managed by ark, expires " + expired + "\n[old]\naws_access_key_id = AKIAOLD\naws_secret_access_key = s\nexpiration = " + expired + "\n\n" +
+		"# managed by ark, expires " + expired + "\n[default]\naws_access_key_id = AKIAOLD\naws_secret_access_key = s\nexpiration = " + expired + "\n\n" +
+		"[saml]\naws_access_key_id = AKIASAML\naws_secret_access_key = s\nexpiration = " + expired + "\n\n" +
+		"# managed by ark, expires " + valid + "\n[fresh]\naws_access_key_id = AKIAFRESH\naws_secret_access_key = s\nexpiration = " + valid + "\n"
+	require.NoError(t, os.WriteFile(credentialsPath, []byte(content), 0600))
+
+	pruned, err := PruneCredentialsFile(true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"default", "old"}, pruned)
+	data, err := os.ReadFile(credentialsPath)
+	require.NoError(t, err)
+	assert.Equal(t, content, string(data), "dry run leaves the file alone")
+
+	pruned, err = PruneCredentialsFile(false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"default", "old"}, pruned)
+
+	data, err = os.ReadFile(credentialsPath)
+	require.NoError(t, err)
+	sections := parseINIFile(string(data))
+	assert.Contains(t, sections, "static")
+	assert.Contains(t, sections, "saml", "an expired section another tool wrote is left alone")
+	assert.Contains(t, sections, "fresh")
+	assert.NotContains(t, sections, "old")
+	assert.NotContains(t, sections, "default")
+	assert.Equal(t, "[static]\naws_access_key_id = AKIASTATIC\naws_secret_access_key = s\n\n"+
+		"[saml]\naws_access_key_id = AKIASAML\naws_secret_access_key = s\nexpiration = "+expired+"\n\n"+
+		"# managed by ark, expires "+valid+"\n[fresh]\naws_access_key_id = AKIAFRESH\naws_secret_access_key = s\nexpiration = "+valid+"\n", string(data),
+		"the remaining sections are kept as they were")
+
+	pruned, err = PruneCredentialsFile(false)
+	require.NoError(t, err)
+	assert.Empty(t, pruned)
+}
+
+func TestExpiredProfiles(t *testing.T) {
+	managed := func(name string, expiresIn time.Duration) string {
+		expiration := time.Now().Add(expiresIn).UTC().Format(time.RFC3339)
+		return "# managed by ark, expires " + expiration + "\n[" + name + "]\nexpiration = " + expiration + "\n"
+	}
+	doc := parseINIDocument("[static]\naws_access_key_id = AKIASTATIC\n" +
+		managed("old", -time.Hour) + managed("expiring", 30*time.Second) + managed("fresh", time.Hour) +
+		"[saml]\nexpiration = " + time.Now().Add(-time.Hour).UTC().Format(time.RFC3339) + "\n")
+
+	assert.Equal(t, []string{"expiring", "old"}, expiredProfiles(doc, time.Minute), "expiring within the skew counts as expired")
+	assert.Equal(t, []string{"old"}, expiredProfiles(doc, 0), "sections ark didn't write are never expired")
+}
+
+func TestWriteCredentialsPrunesExpired(t *testing.T) {
+	credentialsPath := filepath.Join(t.TempDir(), "credentials")
+	t.Setenv(CredentialsFileEnv, credentialsPath)
+
+	expired := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	require.NoError(t, os.WriteFile(credentialsPath, []byte("# managed by ark, expires "+expired+"\n[old]\naws_access_key_id = AKIAOLD\nexpiration = "+expired+"\n\n"+
+		"[saml]\naws_access_key_id = AKIASAML\nexpiration = "+expired+"\n"), 0600))
+
+	creds := &Credentials{AccessKeyID: "AKIANEW", SecretAccessKey: "s", SessionToken: "t", Expiration: time.Now().Add(time.Hour).UnixMilli()}
+	require.NoError(t, WriteCredentialsFile("new", creds, false))
+
+	data, err := os.ReadFile(credentialsPath)
+	require.NoError(t, err)
+	sections := parseINIFile(string(data))
+	assert.NotContains(t, sections, "old")
+	assert.Equal(t, "AKIASAML", sections["saml"]["aws_access_key_id"], "an expired section another tool wrote is left alone")
+	assert.Equal(t, "AKIANEW", sections["new"]["aws_access_key_id"])
+}
+
+func TestPruneCredentialsFileMissing(t *testing.T) {
+	t.Setenv(CredentialsFileEnv, filepath.Join(t.TempDir(), "credentials"))
+
+	pruned, err := PruneCredentialsFile(false)
+	require.NoError(t, err)
+	assert.Empty(t, pruned)
+}
@@ -0,0 +1,154 @@
+package services_aws
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/andresgarcia29/ark-cli/cachefile"
+	"github.com/andresgarcia29/ark-cli/logs"
+	"github.com/andresgarcia29/ark-cli/paths"
+)
+
+// ErrNoDiscoveryCache is returned in offline mode when nothing was cached yet
+var ErrNoDiscoveryCache = errors.New("no cached discovery data (run the command once while online)")
+
+const clusterCacheFile = "clusters.json"
+
+// ClusterCache is the last list of EKS clusters discovered online
+type ClusterCache struct {
+	UpdatedAt time.Time    `json:"updated_at"`
+	Clusters  []EKSCluster `json:"clusters"`
+}
+
+// ProfileCache is the last list of accounts and roles fetched from an SSO portal
+type ProfileCache struct {
+	StartURL  string       `json:"start_url"`
+	UpdatedAt time.Time    `json:"updated_at"`
+	Profiles  []AWSProfile `json:"profiles"`
+}
+
+// describedClusters remembers the details returned by DescribeCluster during the run
+// so they can be cached with the cluster list and reused offline
+var describedClusters sync.Map
+
+// clusterKey identifies a cluster across accounts and regions
+func clusterKey(cluster EKSCluster) string {
+	return cluster.AccountID + "/" + cluster.Region + "/" + cluster.Name
+}
+
+// rememberClusterDetails records the details of a described cluster
+func rememberClusterDetails(cluster EKSCluster) {
+	describedClusters.Store(clusterKey(cluster), cluster)
+}
+
+// withClusterDetails fills endpoint, certificate and version from clusters described during the run
+func withClusterDetails(clusters []EKSCluster) []EKSCluster {
+	enriched := make([]EKSCluster, len(clusters))
+	for i, cluster := range clusters {
+		if described, ok := describedClusters.Load(clusterKey(cluster)); ok && cluster.Endpoint == "" {
+			cluster = described.(EKSCluster)
+		}
+		enriched[i] = cluster
+	}
+	return enriched
+}
+
+// discoveryCachePath returns the path of a cache file, creating the cache directory
+func discoveryCachePath(name string) (string, error) {
+	dir, err := paths.CacheDir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// profileCacheFile names the cache of an SSO portal after its start URL
+func profileCacheFile(startURL string) string {
+	return "profiles-" + generateCacheFileName(startURL)
+}
+
+// writeDiscoveryCache stores a cache entry as JSON, encrypted when cache.encrypt is set
+func writeDiscoveryCache(name string, value any) error {
+	path, err := discoveryCachePath(name)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache: %w", err)
+	}
+	if err := cachefile.Write(path, data); err != nil {
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+	logs.GetLogger().Debugw("Discovery cache updated", "path", path)
+	return nil
+}
+
+// readDiscoveryCache loads a cache entry, returning ErrNoDiscoveryCache when it doesn't exist
+func readDiscoveryCache(name string, value any) error {
+	path, err := discoveryCachePath(name)
+	if err != nil {
+		return err
+	}
+	data, err := cachefile.Read(path)
+	if os.IsNotExist(err) {
+		return ErrNoDiscoveryCache
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read cache file: %w", err)
+	}
+	if err := json.Unmarshal(data, value); err != nil {
+		return fmt.Errorf("failed to parse cache file %s: %w", path, err)
+	}
+	return nil
+}
+
+// SaveClusterCache stores discovered clusters, with the details described during the run
+func SaveClusterCache(clusters []EKSCluster) error {
+	cached := withClusterDetails(clusters)
+	sort.Slice(cached, func(i, j int) bool { return clusterKey(cached[i]) < clusterKey(cached[j]) })
+	return writeDiscoveryCache(clusterCacheFile, ClusterCache{UpdatedAt: time.Now(), Clusters: cached})
+}
+
+// LoadClusterCache returns the clusters cached by the last online discovery
+func LoadClusterCache() (*ClusterCache, error) {
+	var cache ClusterCache
+	if err := readDiscoveryCache(clusterCacheFile, &cache); err != nil {
+		return nil, err
+	}
+	return &cache, nil
+}
+
+// SaveProfileCache stores the accounts and roles of an SSO portal
+func SaveProfileCache(startURL string, profiles []AWSProfile) error {
+	cached := append([]AWSProfile(nil), profiles...)
+	sort.Slice(cached, func(i, j int) bool {
+		if cached[i].AccountID != cached[j].AccountID {
+			return cached[i].AccountID < cached[j].AccountID
+		}
+		return cached[i].RoleName < cached[j].RoleName
+	})
+	return writeDiscoveryCache(profileCacheFile(startURL), ProfileCache{
+		StartURL:  startURL,
+		UpdatedAt: time.Now(),
+		Profiles:  cached,
+	})
+}
+
+// LoadProfileCache returns the accounts and roles cached for an SSO portal
+func LoadProfileCache(startURL string) (*ProfileCache, error) {
+	var cache ProfileCache
+	if err := readDiscoveryCache(profileCacheFile(startURL), &cache); err != nil {
+		return nil, err
+	}
+	return &cache, nil
+}
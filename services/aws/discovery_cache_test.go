@@ -0,0 +1,54 @@
+package services_aws
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClusterCache(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	_, err := LoadClusterCache()
+	assert.ErrorIs(t, err, ErrNoDiscoveryCache)
+
+	described := EKSCluster{Name: "prod", Region: "us-west-2", AccountID: "222222222222", Profile: "prod-ro", Endpoint: "https://prod.eks"}
+	rememberClusterDetails(described)
+
+	require.NoError(t, SaveClusterCache([]EKSCluster{
+		{Name: "prod", Region: "us-west-2", AccountID: "222222222222", Profile: "prod-ro"},
+		{Name: "dev", Region: "us-west-2", AccountID: "111111111111", Profile: "dev-ro"},
+	}))
+
+	cache, err := LoadClusterCache()
+	require.NoError(t, err)
+	require.Len(t, cache.Clusters, 2)
+	assert.Equal(t, "dev", cache.Clusters[0].Name, "clusters are sorted for deterministic output")
+	assert.Empty(t, cache.Clusters[0].Endpoint)
+	assert.Equal(t, "https://prod.eks", cache.Clusters[1].Endpoint, "described details are cached")
+	assert.False(t, cache.UpdatedAt.IsZero())
+}
+
+func TestProfileCache(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	startURL := "https://my-org.awsapps.com/start"
+
+	_, err := LoadProfileCache(startURL)
+	assert.ErrorIs(t, err, ErrNoDiscoveryCache)
+
+	require.NoError(t, SaveProfileCache(startURL, []AWSProfile{
+		{AccountID: "222222222222", RoleName: "Admin"},
+		{AccountID: "111111111111", RoleName: "ReadOnly"},
+		{AccountID: "111111111111", RoleName: "Admin"},
+	}))
+
+	cache, err := LoadProfileCache(startURL)
+	require.NoError(t, err)
+	assert.Equal(t, startURL, cache.StartURL)
+	require.Len(t, cache.Profiles, 3)
+	assert.Equal(t, AWSProfile{AccountID: "111111111111", RoleName: "Admin"}, cache.Profiles[0])
+
+	_, err = LoadProfileCache("https://other.awsapps.com/start")
+	assert.ErrorIs(t, err, ErrNoDiscoveryCache, "caches are kept per start URL")
+}
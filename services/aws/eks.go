@@ -3,6 +3,9 @@ package services_aws
 import (
 	"context"
 	"fmt"
+	"maps"
+	"slices"
+	"time"
 
 	"github.com/andresgarcia29/ark-cli/lib"
 	"github.com/andresgarcia29/ark-cli/logs"
@@ -38,6 +41,51 @@ func (e *EKSClient) ListClusters(ctx context.Context) ([]string, error) {
 	return clusters, nil
 }
 
+// DescribeCluster fills the endpoint, certificate authority, ARN and version of a cluster
+func (e *EKSClient) DescribeCluster(ctx context.Context, cluster *EKSCluster) error {
+	logger := logs.GetLogger()
+	logger.Debugw("Describing EKS cluster", "cluster", cluster.Name, "region", e.region)
+
+	output, err := e.client.DescribeCluster(ctx, &eks.DescribeClusterInput{
+		Name: aws.String(cluster.Name),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to describe EKS cluster %s: %w", cluster.Name, err)
+	}
+
+	if output.Cluster == nil {
+		return fmt.Errorf("EKS cluster %s returned no details", cluster.Name)
+	}
+
+	cluster.ARN = aws.ToString(output.Cluster.Arn)
+	cluster.Endpoint = aws.ToString(output.Cluster.Endpoint)
+	cluster.Version = aws.ToString(output.Cluster.Version)
+	if output.Cluster.CertificateAuthority != nil {
+		cluster.CertificateAuthorityData = aws.ToString(output.Cluster.CertificateAuthority.Data)
+	}
+
+	return nil
+}
+
+// DescribeClusterWithProfile creates an EKS client for the cluster's region and profile and describes it
+// The details are remembered so SaveClusterCache can keep them for offline use
+func DescribeClusterWithProfile(ctx context.Context, cluster *EKSCluster) error {
+	return DescribeClusterWith(ctx, NewClusterLister, cluster)
+}
+
+// DescribeClusterWith is DescribeClusterWithProfile using the ClusterLister created by newLister
+func DescribeClusterWith(ctx context.Context, newLister ClusterListerFactory, cluster *EKSCluster) error {
+	lister, err := newLister(ctx, cluster.Region, cluster.Profile)
+	if err != nil {
+		return fmt.Errorf("failed to create EKS client: %w", err)
+	}
+	if err := lister.DescribeCluster(ctx, cluster); err != nil {
+		return err
+	}
+	rememberClusterDetails(*cluster)
+	return nil
+}
+
 // GetClustersForAccountRegion gets all clusters for a specific account and region
 func GetClustersForAccountRegion(ctx context.Context, profile, accountID, region string) ([]EKSCluster, error) {
 	// Create EKS client
@@ -46,8 +94,12 @@ func GetClustersForAccountRegion(ctx context.Context, profile, accountID, region
 		return nil, fmt.Errorf("failed to create EKS client: %w", err)
 	}
 
-	// List clusters
-	clusterNames, err := eksClient.ListClusters(ctx)
+	return ListClustersWith(ctx, eksClient, profile, accountID, region)
+}
+
+// ListClustersWith lists the clusters of an account and region through lister
+func ListClustersWith(ctx context.Context, lister ClusterLister, profile, accountID, region string) ([]EKSCluster, error) {
+	clusterNames, err := lister.ListClusters(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -85,8 +137,9 @@ func GetClustersForAccountMultiRegion(ctx context.Context, profile, accountID st
 		"total_regions", len(regions),
 		"account_id", accountID)
 
-	// Configuration for parallelization
-	config := lib.ConservativeConfig()
+	// Every region has its own EKS quota, so the EKS limits of one region are enough
+	config := lib.ServiceConfig(lib.ServiceEKS)
+	config.Phase = "the region scan of account " + accountID
 
 	// Use our specialized function to process regions in parallel
 	// This function automatically handles:
@@ -112,30 +165,25 @@ func GetClustersForAccountMultiRegion(ctx context.Context, profile, accountID st
 func GetClustersFromAllAccounts(ctx context.Context, regions []string, rolePrefixs []string, roleARN string) ([]EKSCluster, error) {
 	logger := logs.GetLogger()
 
-	// If no regions are specified, use default
-	if len(regions) == 0 {
-		regions = []string{"us-west-2"}
-	}
+	regions = scanRegions(regions)
 
-	// Step 1: Read all profiles
-	logger.Info("Reading profiles from ~/.aws/config")
-	allProfiles, err := ReadAllProfilesFromConfig()
+	// Steps 1 and 2: Read all profiles and select one per account
+	selectedProfiles, err := selectScanProfiles(rolePrefixs, roleARN)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read profiles: %w", err)
-	}
-
-	// Step 2: Select profiles based on prefix or specific ARN
-	var selectedProfiles map[string]ProfileConfig
-	if roleARN != "" {
-		logger.Infow("Searching for profile with specific Role ARN", "role_arn", roleARN)
-		selectedProfiles = SelectProfileByARN(allProfiles, roleARN)
-	} else {
-		selectedProfiles = SelectProfilesPerAccount(allProfiles, rolePrefixs)
+		return nil, err
 	}
 
 	logger.Infow("Accounts found to scan",
 		"total_accounts", len(selectedProfiles))
+	return GetClustersForProfiles(ctx, selectedProfiles, regions)
+}
+
+// GetClustersForProfiles logs in with the profile of each account, keyed by account ID, and gets the clusters
+// of the account in regions, accounts in parallel. Accounts that fail are logged and left out
+func GetClustersForProfiles(ctx context.Context, selectedProfiles map[string]ProfileConfig, regions []string) ([]EKSCluster, error) {
+	logger := logs.GetLogger()
 
+	regions = scanRegions(regions)
 	if len(selectedProfiles) == 0 {
 		logger.Warn("No accounts found to process")
 		return []EKSCluster{}, nil
@@ -148,8 +196,10 @@ func GetClustersFromAllAccounts(ctx context.Context, regions []string, rolePrefi
 		}
 	}
 
-	// Configuration for parallelization
-	config := lib.ConservativeConfig()
+	// Every account starts with a login, so the login API paces the accounts; the EKS calls
+	// that follow go to the account's own quota
+	config := lib.ServiceConfig(loginService(selectedProfiles))
+	config.Phase = "the cluster scan"
 
 	// Convert the profile map to a list of account IDs
 	var accountIDs []string
@@ -208,6 +258,89 @@ func GetClustersFromAllAccounts(ctx context.Context, regions []string, rolePrefi
 	return allClusters, nil
 }
 
+// ScanPlan is the size of a scan of every account in some regions, known before calling AWS
+type ScanPlan struct {
+	Accounts int
+	Regions  []string
+	// Portals are the SSO portals whose token the scan signs in to the accounts with
+	Portals []SSOPortal
+}
+
+// accountScanTime is about how long signing in to an account and listing its clusters in every region takes
+const accountScanTime = 3 * time.Second
+
+// Targets is the number of account×region combinations scanned
+func (p ScanPlan) Targets() int {
+	return p.Accounts * len(p.Regions)
+}
+
+// EstimatedAPICalls is a lower bound of the AWS API calls of the scan: one sign-in per account and one
+// ListClusters per account and region. Describing the clusters found adds more
+func (p ScanPlan) EstimatedAPICalls() int {
+	return p.Accounts + p.Targets()
+}
+
+// EstimatedDuration is about how long the scan takes: accounts are scanned in parallel batches,
+// paced like GetClustersFromAllAccounts paces them
+func (p ScanPlan) EstimatedDuration() time.Duration {
+	login := lib.ServiceSTS
+	if len(p.Portals) > 0 {
+		login = lib.ServiceSSO
+	}
+	config := lib.ServiceConfig(login)
+	batches := (p.Accounts + config.MaxWorkers - 1) / config.MaxWorkers
+	return max(time.Duration(batches)*accountScanTime, time.Duration(p.Accounts)*config.RateLimitDelay)
+}
+
+// PlanClusterScan selects the accounts GetClustersFromAllAccounts would scan, without calling AWS
+func PlanClusterScan(regions []string, rolePrefixs []string, roleARN string) (ScanPlan, error) {
+	profiles, err := selectScanProfiles(rolePrefixs, roleARN)
+	if err != nil {
+		return ScanPlan{}, err
+	}
+	return ScanPlan{
+		Accounts: len(profiles),
+		Regions:  scanRegions(regions),
+		Portals:  SSOPortalsOf(slices.Collect(maps.Values(profiles))),
+	}, nil
+}
+
+// scanRegions defaults an empty region list to us-west-2
+func scanRegions(regions []string) []string {
+	if len(regions) == 0 {
+		return []string{"us-west-2"}
+	}
+	return regions
+}
+
+// selectScanProfiles reads ~/.aws/config and picks the profile to scan each account with,
+// by role prefix or, when roleARN is set, the profile of that role
+func selectScanProfiles(rolePrefixs []string, roleARN string) (map[string]ProfileConfig, error) {
+	logger := logs.GetLogger()
+
+	logger.Info("Reading profiles from ~/.aws/config")
+	allProfiles, err := ReadAllProfilesFromConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profiles: %w", err)
+	}
+
+	if roleARN != "" {
+		logger.Infow("Searching for profile with specific Role ARN", "role_arn", roleARN)
+		return SelectProfileByARN(allProfiles, roleARN), nil
+	}
+	return SelectProfilesPerAccount(allProfiles, rolePrefixs), nil
+}
+
+// loginService returns the API signing in to profiles calls: the SSO portal, or STS when no profile uses SSO
+func loginService(profiles map[string]ProfileConfig) lib.Service {
+	for _, profile := range profiles {
+		if profile.ProfileType == ProfileTypeSSO {
+			return lib.ServiceSSO
+		}
+	}
+	return lib.ServiceSTS
+}
+
 // processAccount processes a specific account: logs in and gets all clusters
 // This function is separated to facilitate parallelization and testing
 func processAccount(ctx context.Context, accountID string, profile ProfileConfig, regions []string) ([]EKSCluster, error) {
@@ -0,0 +1,285 @@
+package services_aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/andresgarcia29/ark-cli/logs"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	"github.com/aws/aws-sdk-go-v2/service/eks/types"
+)
+
+// ErrAccessEntryExists is returned by CreateAccessEntry when the principal already has an access entry
+var ErrAccessEntryExists = errors.New("access entry already exists")
+
+// accessPolicyARNPrefix prefixes the ARNs of the access policies managed by EKS
+const accessPolicyARNPrefix = "arn:aws:eks::aws:cluster-access-policy/"
+
+// accessPolicyAliases are the short names accepted for the EKS access policies
+var accessPolicyAliases = map[string]string{
+	"cluster-admin": "AmazonEKSClusterAdminPolicy",
+	"admin":         "AmazonEKSAdminPolicy",
+	"edit":          "AmazonEKSEditPolicy",
+	"view":          "AmazonEKSViewPolicy",
+}
+
+// EKSAccessPolicy is an access policy associated with a principal, for the whole cluster or some namespaces
+type EKSAccessPolicy struct {
+	PolicyARN string
+	// Namespaces limits the policy to these namespaces; empty means cluster-wide
+	Namespaces []string
+}
+
+// Scope describes where the policy applies, "cluster" or the namespaces
+func (p EKSAccessPolicy) Scope() string {
+	if len(p.Namespaces) == 0 {
+		return "cluster"
+	}
+	return "namespaces: " + strings.Join(p.Namespaces, ",")
+}
+
+// EKSAccessEntry is an IAM principal allowed into a cluster through the EKS access entries API
+type EKSAccessEntry struct {
+	PrincipalARN     string
+	Type             string
+	Username         string
+	KubernetesGroups []string
+	Policies         []EKSAccessPolicy
+}
+
+// AccessGrant gives an IAM principal access to a cluster with an access policy
+type AccessGrant struct {
+	PrincipalARN string
+	// Policy is an access policy ARN, name (AmazonEKSViewPolicy) or alias (cluster-admin, admin, edit, view)
+	Policy string
+	// Namespaces limits the policy to these namespaces; empty grants it cluster-wide
+	Namespaces []string
+	// KubernetesGroups are only set when the access entry is created
+	KubernetesGroups []string
+}
+
+// AccessPolicyARN resolves an access policy alias or name to its ARN; ARNs are returned unchanged
+func AccessPolicyARN(policy string) (string, error) {
+	if policy == "" {
+		return "", errors.New("an access policy is required (cluster-admin, admin, edit, view or a policy ARN)")
+	}
+	if strings.HasPrefix(policy, "arn:") {
+		return policy, nil
+	}
+	if name, ok := accessPolicyAliases[policy]; ok {
+		policy = name
+	}
+	if !strings.HasPrefix(policy, "AmazonEKS") {
+		return "", fmt.Errorf("unknown access policy %q (expected cluster-admin, admin, edit, view, an AmazonEKS* policy name or a policy ARN)", policy)
+	}
+	return accessPolicyARNPrefix + policy, nil
+}
+
+// AccessPolicyName shortens the ARN of an EKS access policy to its name; other ARNs are returned unchanged
+func AccessPolicyName(policyARN string) string {
+	return strings.TrimPrefix(policyARN, accessPolicyARNPrefix)
+}
+
+// ListAccessEntries lists the principal ARNs with an access entry in a cluster
+func (e *EKSClient) ListAccessEntries(ctx context.Context, clusterName string) ([]string, error) {
+	var principals []string
+	var nextToken *string
+
+	for {
+		output, err := e.client.ListAccessEntries(ctx, &eks.ListAccessEntriesInput{
+			ClusterName: aws.String(clusterName),
+			MaxResults:  aws.Int32(100),
+			NextToken:   nextToken,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list access entries of %s: %w", clusterName, err)
+		}
+
+		principals = append(principals, output.AccessEntries...)
+
+		if output.NextToken == nil {
+			break
+		}
+		nextToken = output.NextToken
+	}
+
+	return principals, nil
+}
+
+// DescribeAccessEntry returns the type, username and Kubernetes groups of an access entry, without its policies
+func (e *EKSClient) DescribeAccessEntry(ctx context.Context, clusterName, principalARN string) (*EKSAccessEntry, error) {
+	output, err := e.client.DescribeAccessEntry(ctx, &eks.DescribeAccessEntryInput{
+		ClusterName:  aws.String(clusterName),
+		PrincipalArn: aws.String(principalARN),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe access entry %s of %s: %w", principalARN, clusterName, err)
+	}
+	if output.AccessEntry == nil {
+		return nil, fmt.Errorf("access entry %s of %s returned no details", principalARN, clusterName)
+	}
+
+	return &EKSAccessEntry{
+		PrincipalARN:     principalARN,
+		Type:             aws.ToString(output.AccessEntry.Type),
+		Username:         aws.ToString(output.AccessEntry.Username),
+		KubernetesGroups: output.AccessEntry.KubernetesGroups,
+	}, nil
+}
+
+// ListAssociatedAccessPolicies lists the access policies associated with a principal
+func (e *EKSClient) ListAssociatedAccessPolicies(ctx context.Context, clusterName, principalARN string) ([]EKSAccessPolicy, error) {
+	var policies []EKSAccessPolicy
+	var nextToken *string
+
+	for {
+		output, err := e.client.ListAssociatedAccessPolicies(ctx, &eks.ListAssociatedAccessPoliciesInput{
+			ClusterName:  aws.String(clusterName),
+			PrincipalArn: aws.String(principalARN),
+			MaxResults:   aws.Int32(100),
+			NextToken:    nextToken,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list access policies of %s in %s: %w", principalARN, clusterName, err)
+		}
+
+		for _, associated := range output.AssociatedAccessPolicies {
+			policy := EKSAccessPolicy{PolicyARN: aws.ToString(associated.PolicyArn)}
+			if associated.AccessScope != nil && associated.AccessScope.Type == types.AccessScopeTypeNamespace {
+				policy.Namespaces = associated.AccessScope.Namespaces
+			}
+			policies = append(policies, policy)
+		}
+
+		if output.NextToken == nil {
+			break
+		}
+		nextToken = output.NextToken
+	}
+
+	return policies, nil
+}
+
+// CreateAccessEntry creates the access entry of a principal, returning ErrAccessEntryExists when it already has one
+func (e *EKSClient) CreateAccessEntry(ctx context.Context, clusterName, principalARN string, kubernetesGroups []string) error {
+	_, err := e.client.CreateAccessEntry(ctx, &eks.CreateAccessEntryInput{
+		ClusterName:      aws.String(clusterName),
+		PrincipalArn:     aws.String(principalARN),
+		KubernetesGroups: kubernetesGroups,
+	})
+	var inUse *types.ResourceInUseException
+	if errors.As(err, &inUse) {
+		return ErrAccessEntryExists
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create access entry %s in %s: %w", principalARN, clusterName, err)
+	}
+	return nil
+}
+
+// AssociateAccessPolicy associates an access policy with a principal, cluster-wide or for some namespaces
+func (e *EKSClient) AssociateAccessPolicy(ctx context.Context, clusterName, principalARN string, policy EKSAccessPolicy) error {
+	scope := &types.AccessScope{Type: types.AccessScopeTypeCluster}
+	if len(policy.Namespaces) > 0 {
+		scope = &types.AccessScope{Type: types.AccessScopeTypeNamespace, Namespaces: policy.Namespaces}
+	}
+
+	_, err := e.client.AssociateAccessPolicy(ctx, &eks.AssociateAccessPolicyInput{
+		ClusterName:  aws.String(clusterName),
+		PrincipalArn: aws.String(principalARN),
+		PolicyArn:    aws.String(policy.PolicyARN),
+		AccessScope:  scope,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to associate %s with %s in %s: %w", policy.PolicyARN, principalARN, clusterName, err)
+	}
+	return nil
+}
+
+// DisassociateAccessPolicy removes an access policy from a principal, keeping its access entry
+func (e *EKSClient) DisassociateAccessPolicy(ctx context.Context, clusterName, principalARN, policyARN string) error {
+	_, err := e.client.DisassociateAccessPolicy(ctx, &eks.DisassociateAccessPolicyInput{
+		ClusterName:  aws.String(clusterName),
+		PrincipalArn: aws.String(principalARN),
+		PolicyArn:    aws.String(policyARN),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to disassociate %s from %s in %s: %w", policyARN, principalARN, clusterName, err)
+	}
+	return nil
+}
+
+// DeleteAccessEntry removes the access entry of a principal, with all its policies
+func (e *EKSClient) DeleteAccessEntry(ctx context.Context, clusterName, principalARN string) error {
+	_, err := e.client.DeleteAccessEntry(ctx, &eks.DeleteAccessEntryInput{
+		ClusterName:  aws.String(clusterName),
+		PrincipalArn: aws.String(principalARN),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete access entry %s of %s: %w", principalARN, clusterName, err)
+	}
+	return nil
+}
+
+// ListClusterAccess returns every access entry of a cluster with its associated policies
+func ListClusterAccess(ctx context.Context, manager AccessManager, clusterName string) ([]EKSAccessEntry, error) {
+	principals, err := manager.ListAccessEntries(ctx, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]EKSAccessEntry, 0, len(principals))
+	for _, principal := range principals {
+		entry, err := manager.DescribeAccessEntry(ctx, clusterName, principal)
+		if err != nil {
+			return nil, err
+		}
+		entry.Policies, err = manager.ListAssociatedAccessPolicies(ctx, clusterName, principal)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *entry)
+	}
+	return entries, nil
+}
+
+// GrantClusterAccess creates the access entry of a principal when it has none, then associates the policy
+func GrantClusterAccess(ctx context.Context, manager AccessManager, clusterName string, grant AccessGrant) error {
+	logger := logs.GetLogger()
+
+	policyARN, err := AccessPolicyARN(grant.Policy)
+	if err != nil {
+		return err
+	}
+
+	err = manager.CreateAccessEntry(ctx, clusterName, grant.PrincipalARN, grant.KubernetesGroups)
+	switch {
+	case errors.Is(err, ErrAccessEntryExists):
+		logger.Debugw("Access entry already exists, associating the policy", "cluster", clusterName, "principal", grant.PrincipalARN)
+	case err != nil:
+		return err
+	default:
+		logger.Infow("Access entry created", "cluster", clusterName, "principal", grant.PrincipalARN)
+	}
+
+	return manager.AssociateAccessPolicy(ctx, clusterName, grant.PrincipalARN, EKSAccessPolicy{
+		PolicyARN:  policyARN,
+		Namespaces: grant.Namespaces,
+	})
+}
+
+// RevokeClusterAccess removes one access policy from a principal, or its whole access entry when policy is empty
+func RevokeClusterAccess(ctx context.Context, manager AccessManager, clusterName, principalARN, policy string) error {
+	if policy == "" {
+		return manager.DeleteAccessEntry(ctx, clusterName, principalARN)
+	}
+
+	policyARN, err := AccessPolicyARN(policy)
+	if err != nil {
+		return err
+	}
+	return manager.DisassociateAccessPolicy(ctx, clusterName, principalARN, policyARN)
+}
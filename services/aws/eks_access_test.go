@@ -0,0 +1,121 @@
+package services_aws
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAccessManager keeps access entries in memory and records the calls made
+type fakeAccessManager struct {
+	entries map[string]*EKSAccessEntry
+	calls   []string
+	err     error
+}
+
+func (f *fakeAccessManager) ListAccessEntries(ctx context.Context, clusterName string) ([]string, error) {
+	var principals []string
+	for principal := range f.entries {
+		principals = append(principals, principal)
+	}
+	return principals, f.err
+}
+
+func (f *fakeAccessManager) DescribeAccessEntry(ctx context.Context, clusterName, principalARN string) (*EKSAccessEntry, error) {
+	entry := *f.entries[principalARN]
+	entry.Policies = nil
+	return &entry, nil
+}
+
+func (f *fakeAccessManager) ListAssociatedAccessPolicies(ctx context.Context, clusterName, principalARN string) ([]EKSAccessPolicy, error) {
+	return f.entries[principalARN].Policies, nil
+}
+
+func (f *fakeAccessManager) CreateAccessEntry(ctx context.Context, clusterName, principalARN string, kubernetesGroups []string) error {
+	f.calls = append(f.calls, "create "+principalARN)
+	if f.err != nil {
+		return f.err
+	}
+	if f.entries[principalARN] != nil {
+		return ErrAccessEntryExists
+	}
+	f.entries[principalARN] = &EKSAccessEntry{PrincipalARN: principalARN, KubernetesGroups: kubernetesGroups}
+	return nil
+}
+
+func (f *fakeAccessManager) AssociateAccessPolicy(ctx context.Context, clusterName, principalARN string, policy EKSAccessPolicy) error {
+	f.calls = append(f.calls, "associate "+AccessPolicyName(policy.PolicyARN))
+	entry := f.entries[principalARN]
+	entry.Policies = append(entry.Policies, policy)
+	return nil
+}
+
+func (f *fakeAccessManager) DisassociateAccessPolicy(ctx context.Context, clusterName, principalARN, policyARN string) error {
+	f.calls = append(f.calls, "disassociate "+AccessPolicyName(policyARN))
+	return nil
+}
+
+func (f *fakeAccessManager) DeleteAccessEntry(ctx context.Context, clusterName, principalARN string) error {
+	f.calls = append(f.calls, "delete "+principalARN)
+	delete(f.entries, principalARN)
+	return nil
+}
+
+const testPrincipal = "arn:aws:iam::111111111111:role/Platform"
+
+func TestAccessPolicyARN(t *testing.T) {
+	tests := map[string]string{
+		"view":                        "arn:aws:eks::aws:cluster-access-policy/AmazonEKSViewPolicy",
+		"cluster-admin":               "arn:aws:eks::aws:cluster-access-policy/AmazonEKSClusterAdminPolicy",
+		"AmazonEKSEditPolicy":         "arn:aws:eks::aws:cluster-access-policy/AmazonEKSEditPolicy",
+		"arn:aws:eks::aws:custom/foo": "arn:aws:eks::aws:custom/foo",
+	}
+	for policy, want := range tests {
+		got, err := AccessPolicyARN(policy)
+		require.NoError(t, err, policy)
+		assert.Equal(t, want, got)
+	}
+
+	_, err := AccessPolicyARN("superuser")
+	assert.ErrorContains(t, err, "unknown access policy")
+	_, err = AccessPolicyARN("")
+	assert.ErrorContains(t, err, "required")
+
+	assert.Equal(t, "AmazonEKSViewPolicy", AccessPolicyName("arn:aws:eks::aws:cluster-access-policy/AmazonEKSViewPolicy"))
+}
+
+func TestGrantClusterAccess(t *testing.T) {
+	manager := &fakeAccessManager{entries: map[string]*EKSAccessEntry{}}
+
+	require.NoError(t, GrantClusterAccess(context.Background(), manager, "prod", AccessGrant{PrincipalARN: testPrincipal, Policy: "view"}))
+	// A second grant reuses the existing access entry
+	require.NoError(t, GrantClusterAccess(context.Background(), manager, "prod", AccessGrant{PrincipalARN: testPrincipal, Policy: "edit", Namespaces: []string{"team-a"}}))
+	assert.Equal(t, []string{
+		"create " + testPrincipal, "associate AmazonEKSViewPolicy",
+		"create " + testPrincipal, "associate AmazonEKSEditPolicy",
+	}, manager.calls)
+
+	entries, err := ListClusterAccess(context.Background(), manager, "prod")
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Len(t, entries[0].Policies, 2)
+	assert.Equal(t, "cluster", entries[0].Policies[0].Scope())
+	assert.Equal(t, "namespaces: team-a", entries[0].Policies[1].Scope())
+
+	manager.err = errors.New("access denied")
+	err = GrantClusterAccess(context.Background(), manager, "prod", AccessGrant{PrincipalARN: testPrincipal, Policy: "view"})
+	assert.ErrorContains(t, err, "access denied")
+}
+
+func TestRevokeClusterAccess(t *testing.T) {
+	manager := &fakeAccessManager{entries: map[string]*EKSAccessEntry{testPrincipal: {PrincipalARN: testPrincipal}}}
+
+	require.NoError(t, RevokeClusterAccess(context.Background(), manager, "prod", testPrincipal, "view"))
+	require.NoError(t, RevokeClusterAccess(context.Background(), manager, "prod", testPrincipal, ""))
+	assert.Equal(t, []string{"disassociate AmazonEKSViewPolicy", "delete " + testPrincipal}, manager.calls)
+
+	assert.Error(t, RevokeClusterAccess(context.Background(), manager, "prod", testPrincipal, "superuser"))
+}
@@ -0,0 +1,201 @@
+package services_aws
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+)
+
+// EKSAddon is an add-on installed in a cluster, with the latest version available for the cluster's Kubernetes version
+type EKSAddon struct {
+	Cluster       string
+	Name          string
+	Version       string
+	Status        string
+	LatestVersion string
+}
+
+// UpdateAvailable reports whether a newer version of the add-on can be installed
+func (a EKSAddon) UpdateAvailable() bool {
+	return a.LatestVersion != "" && CompareAddonVersions(a.LatestVersion, a.Version) > 0
+}
+
+// ListAddons lists the add-ons installed in a cluster
+func (e *EKSClient) ListAddons(ctx context.Context, clusterName string) ([]string, error) {
+	var addons []string
+	var nextToken *string
+
+	for {
+		output, err := e.client.ListAddons(ctx, &eks.ListAddonsInput{
+			ClusterName: aws.String(clusterName),
+			MaxResults:  aws.Int32(100),
+			NextToken:   nextToken,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list add-ons of %s: %w", clusterName, err)
+		}
+
+		addons = append(addons, output.Addons...)
+
+		if output.NextToken == nil {
+			break
+		}
+		nextToken = output.NextToken
+	}
+
+	return addons, nil
+}
+
+// DescribeAddon returns the installed version and status of an add-on
+func (e *EKSClient) DescribeAddon(ctx context.Context, clusterName, name string) (*EKSAddon, error) {
+	output, err := e.client.DescribeAddon(ctx, &eks.DescribeAddonInput{
+		ClusterName: aws.String(clusterName),
+		AddonName:   aws.String(name),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe add-on %s of %s: %w", name, clusterName, err)
+	}
+	if output.Addon == nil {
+		return nil, fmt.Errorf("add-on %s of %s returned no details", name, clusterName)
+	}
+
+	return &EKSAddon{
+		Cluster: clusterName,
+		Name:    name,
+		Version: aws.ToString(output.Addon.AddonVersion),
+		Status:  string(output.Addon.Status),
+	}, nil
+}
+
+// LatestAddonVersion returns the newest version of an add-on compatible with a Kubernetes version
+func (e *EKSClient) LatestAddonVersion(ctx context.Context, name, kubernetesVersion string) (string, error) {
+	latest := ""
+	var nextToken *string
+
+	for {
+		output, err := e.client.DescribeAddonVersions(ctx, &eks.DescribeAddonVersionsInput{
+			AddonName:         aws.String(name),
+			KubernetesVersion: aws.String(kubernetesVersion),
+			NextToken:         nextToken,
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to describe versions of add-on %s: %w", name, err)
+		}
+
+		for _, addon := range output.Addons {
+			for _, version := range addon.AddonVersions {
+				if candidate := aws.ToString(version.AddonVersion); latest == "" || CompareAddonVersions(candidate, latest) > 0 {
+					latest = candidate
+				}
+			}
+		}
+
+		if output.NextToken == nil {
+			break
+		}
+		nextToken = output.NextToken
+	}
+
+	return latest, nil
+}
+
+// GetClusterAddons describes a cluster, for its Kubernetes version, and each installed add-on with its latest version
+// Latest versions are looked up once per add-on and Kubernetes version through latest, which callers share across clusters
+func GetClusterAddons(ctx context.Context, lister AddonLister, cluster EKSCluster, latest *AddonVersionCache) ([]EKSAddon, error) {
+	if err := lister.DescribeCluster(ctx, &cluster); err != nil {
+		return nil, err
+	}
+
+	names, err := lister.ListAddons(ctx, cluster.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	addons := make([]EKSAddon, 0, len(names))
+	for _, name := range names {
+		addon, err := lister.DescribeAddon(ctx, cluster.Name, name)
+		if err != nil {
+			return nil, err
+		}
+		addon.LatestVersion, err = latest.Get(ctx, lister, cluster.Region, name, cluster.Version)
+		if err != nil {
+			return nil, err
+		}
+		addons = append(addons, *addon)
+	}
+	return addons, nil
+}
+
+// AddonVersionCache remembers the latest add-on versions looked up during a run; it is safe for concurrent use
+// A nil cache looks every version up
+type AddonVersionCache struct {
+	mu       sync.Mutex
+	versions map[string]string
+}
+
+// Get returns the latest version of an add-on for a Kubernetes version, asking lister the first time
+func (c *AddonVersionCache) Get(ctx context.Context, lister AddonLister, region, name, kubernetesVersion string) (string, error) {
+	if c == nil {
+		return lister.LatestAddonVersion(ctx, name, kubernetesVersion)
+	}
+
+	key := region + "/" + name + "/" + kubernetesVersion
+	c.mu.Lock()
+	version, ok := c.versions[key]
+	c.mu.Unlock()
+	if ok {
+		return version, nil
+	}
+
+	version, err := lister.LatestAddonVersion(ctx, name, kubernetesVersion)
+	if err != nil {
+		return "", err
+	}
+	c.mu.Lock()
+	if c.versions == nil {
+		c.versions = make(map[string]string)
+	}
+	c.versions[key] = version
+	c.mu.Unlock()
+	return version, nil
+}
+
+// CompareAddonVersions orders add-on versions such as "v1.18.3-eksbuild.1": negative when a is older than b,
+// positive when newer and 0 when equal. Numbers are compared numerically, so v1.10.0 is newer than v1.9.0
+func CompareAddonVersions(a, b string) int {
+	partsA, partsB := addonVersionParts(a), addonVersionParts(b)
+	for i := 0; i < len(partsA) || i < len(partsB); i++ {
+		var x, y int
+		if i < len(partsA) {
+			x = partsA[i]
+		}
+		if i < len(partsB) {
+			y = partsB[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// addonVersionParts extracts the numbers of a version, e.g. [1 18 3 1] for v1.18.3-eksbuild.1
+func addonVersionParts(version string) []int {
+	var parts []int
+	for _, field := range strings.FieldsFunc(version, func(r rune) bool { return r < '0' || r > '9' }) {
+		number, err := strconv.Atoi(field)
+		if err != nil {
+			continue
+		}
+		parts = append(parts, number)
+	}
+	return parts
+}
@@ -0,0 +1,103 @@
+package services_aws
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAddonLister serves installed add-ons and latest versions from memory, counting version lookups
+type fakeAddonLister struct {
+	fakeClusterLister
+	version   string
+	installed map[string][]EKSAddon
+	latest    map[string]string
+
+	mu      sync.Mutex
+	lookups int
+}
+
+func (f *fakeAddonLister) DescribeCluster(ctx context.Context, cluster *EKSCluster) error {
+	cluster.Version = f.version
+	return f.err
+}
+
+func (f *fakeAddonLister) ListAddons(ctx context.Context, clusterName string) ([]string, error) {
+	var names []string
+	for _, addon := range f.installed[clusterName] {
+		names = append(names, addon.Name)
+	}
+	return names, nil
+}
+
+func (f *fakeAddonLister) DescribeAddon(ctx context.Context, clusterName, name string) (*EKSAddon, error) {
+	for _, addon := range f.installed[clusterName] {
+		if addon.Name == name {
+			return &addon, nil
+		}
+	}
+	return nil, errors.New("add-on not found")
+}
+
+func (f *fakeAddonLister) LatestAddonVersion(ctx context.Context, name, kubernetesVersion string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lookups++
+	return f.latest[name+"@"+kubernetesVersion], nil
+}
+
+func TestGetClusterAddons(t *testing.T) {
+	lister := &fakeAddonLister{
+		version: "1.30",
+		installed: map[string][]EKSAddon{
+			"api": {
+				{Cluster: "api", Name: "vpc-cni", Version: "v1.18.1-eksbuild.1", Status: "ACTIVE"},
+				{Cluster: "api", Name: "coredns", Version: "v1.11.1-eksbuild.9", Status: "ACTIVE"},
+			},
+			"web": {{Cluster: "web", Name: "vpc-cni", Version: "v1.18.3-eksbuild.2", Status: "ACTIVE"}},
+		},
+		latest: map[string]string{"vpc-cni@1.30": "v1.18.3-eksbuild.2", "coredns@1.30": "v1.11.1-eksbuild.9"},
+	}
+	cache := &AddonVersionCache{}
+
+	addons, err := GetClusterAddons(context.Background(), lister, EKSCluster{Name: "api", Region: "us-west-2"}, cache)
+	require.NoError(t, err)
+	require.Len(t, addons, 2)
+	assert.Equal(t, "v1.18.3-eksbuild.2", addons[0].LatestVersion)
+	assert.True(t, addons[0].UpdateAvailable())
+	assert.False(t, addons[1].UpdateAvailable())
+
+	// The latest vpc-cni version for 1.30 in us-west-2 is only looked up once
+	addons, err = GetClusterAddons(context.Background(), lister, EKSCluster{Name: "web", Region: "us-west-2"}, cache)
+	require.NoError(t, err)
+	assert.False(t, addons[0].UpdateAvailable())
+	assert.Equal(t, 2, lister.lookups)
+
+	// Without a cache every version is looked up
+	_, err = GetClusterAddons(context.Background(), lister, EKSCluster{Name: "web", Region: "us-west-2"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 3, lister.lookups)
+
+	lister.err = errors.New("access denied")
+	_, err = GetClusterAddons(context.Background(), lister, EKSCluster{Name: "api", Region: "us-west-2"}, cache)
+	assert.ErrorContains(t, err, "access denied")
+}
+
+func TestCompareAddonVersions(t *testing.T) {
+	assert.Equal(t, 0, CompareAddonVersions("v1.18.3-eksbuild.1", "v1.18.3-eksbuild.1"))
+	assert.Equal(t, 1, CompareAddonVersions("v1.18.3-eksbuild.2", "v1.18.3-eksbuild.1"))
+	assert.Equal(t, 1, CompareAddonVersions("v1.10.0-eksbuild.1", "v1.9.9-eksbuild.3"))
+	assert.Equal(t, -1, CompareAddonVersions("v1.18.3", "v1.18.3-eksbuild.1"))
+	assert.Equal(t, -1, CompareAddonVersions("v1.29.0-eksbuild.1", "v1.30.0-eksbuild.1"))
+}
+
+func TestEKSAddonUpdateAvailable(t *testing.T) {
+	assert.True(t, EKSAddon{Version: "v1.11.1-eksbuild.8", LatestVersion: "v1.11.1-eksbuild.9"}.UpdateAvailable())
+	assert.False(t, EKSAddon{Version: "v1.11.1-eksbuild.9", LatestVersion: "v1.11.1-eksbuild.9"}.UpdateAvailable())
+	// Nothing is reported when the latest version is unknown
+	assert.False(t, EKSAddon{Version: "v1.11.1-eksbuild.9"}.UpdateAvailable())
+}
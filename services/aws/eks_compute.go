@@ -0,0 +1,236 @@
+package services_aws
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/andresgarcia29/ark-cli/logs"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+)
+
+// EKSNodegroup is a managed nodegroup with its scaling configuration
+type EKSNodegroup struct {
+	Cluster        string
+	Name           string
+	Status         string
+	AMIType        string
+	CapacityType   string
+	InstanceTypes  []string
+	Version        string
+	ReleaseVersion string
+	MinSize        int32
+	MaxSize        int32
+	DesiredSize    int32
+}
+
+// EKSFargateProfile is a Fargate profile with the namespaces it selects
+type EKSFargateProfile struct {
+	Cluster    string
+	Name       string
+	Status     string
+	Namespaces []string
+}
+
+// ClusterCompute is a cluster with the nodegroups and Fargate profiles running its workloads
+type ClusterCompute struct {
+	Cluster         EKSCluster
+	Nodegroups      []EKSNodegroup
+	FargateProfiles []EKSFargateProfile
+}
+
+// ListNodegroups lists the managed nodegroups of a cluster
+func (e *EKSClient) ListNodegroups(ctx context.Context, clusterName string) ([]string, error) {
+	var nodegroups []string
+	var nextToken *string
+
+	for {
+		output, err := e.client.ListNodegroups(ctx, &eks.ListNodegroupsInput{
+			ClusterName: aws.String(clusterName),
+			MaxResults:  aws.Int32(100),
+			NextToken:   nextToken,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list nodegroups of %s: %w", clusterName, err)
+		}
+
+		nodegroups = append(nodegroups, output.Nodegroups...)
+
+		if output.NextToken == nil {
+			break
+		}
+		nextToken = output.NextToken
+	}
+
+	return nodegroups, nil
+}
+
+// DescribeNodegroup returns the scaling configuration, AMI type and version of a nodegroup
+func (e *EKSClient) DescribeNodegroup(ctx context.Context, clusterName, name string) (*EKSNodegroup, error) {
+	logger := logs.GetLogger()
+	logger.Debugw("Describing EKS nodegroup", "cluster", clusterName, "nodegroup", name, "region", e.region)
+
+	output, err := e.client.DescribeNodegroup(ctx, &eks.DescribeNodegroupInput{
+		ClusterName:   aws.String(clusterName),
+		NodegroupName: aws.String(name),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe nodegroup %s of %s: %w", name, clusterName, err)
+	}
+	if output.Nodegroup == nil {
+		return nil, fmt.Errorf("nodegroup %s of %s returned no details", name, clusterName)
+	}
+
+	nodegroup := &EKSNodegroup{
+		Cluster:        clusterName,
+		Name:           name,
+		Status:         string(output.Nodegroup.Status),
+		AMIType:        string(output.Nodegroup.AmiType),
+		CapacityType:   string(output.Nodegroup.CapacityType),
+		InstanceTypes:  output.Nodegroup.InstanceTypes,
+		Version:        aws.ToString(output.Nodegroup.Version),
+		ReleaseVersion: aws.ToString(output.Nodegroup.ReleaseVersion),
+	}
+	if scaling := output.Nodegroup.ScalingConfig; scaling != nil {
+		nodegroup.MinSize = aws.ToInt32(scaling.MinSize)
+		nodegroup.MaxSize = aws.ToInt32(scaling.MaxSize)
+		nodegroup.DesiredSize = aws.ToInt32(scaling.DesiredSize)
+	}
+	return nodegroup, nil
+}
+
+// ListFargateProfiles lists the Fargate profiles of a cluster
+func (e *EKSClient) ListFargateProfiles(ctx context.Context, clusterName string) ([]string, error) {
+	var profiles []string
+	var nextToken *string
+
+	for {
+		output, err := e.client.ListFargateProfiles(ctx, &eks.ListFargateProfilesInput{
+			ClusterName: aws.String(clusterName),
+			MaxResults:  aws.Int32(100),
+			NextToken:   nextToken,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list Fargate profiles of %s: %w", clusterName, err)
+		}
+
+		profiles = append(profiles, output.FargateProfileNames...)
+
+		if output.NextToken == nil {
+			break
+		}
+		nextToken = output.NextToken
+	}
+
+	return profiles, nil
+}
+
+// DescribeFargateProfile returns the status and selected namespaces of a Fargate profile
+func (e *EKSClient) DescribeFargateProfile(ctx context.Context, clusterName, name string) (*EKSFargateProfile, error) {
+	output, err := e.client.DescribeFargateProfile(ctx, &eks.DescribeFargateProfileInput{
+		ClusterName:        aws.String(clusterName),
+		FargateProfileName: aws.String(name),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe Fargate profile %s of %s: %w", name, clusterName, err)
+	}
+	if output.FargateProfile == nil {
+		return nil, fmt.Errorf("Fargate profile %s of %s returned no details", name, clusterName)
+	}
+
+	profile := &EKSFargateProfile{
+		Cluster: clusterName,
+		Name:    name,
+		Status:  string(output.FargateProfile.Status),
+	}
+	for _, selector := range output.FargateProfile.Selectors {
+		profile.Namespaces = append(profile.Namespaces, aws.ToString(selector.Namespace))
+	}
+	return profile, nil
+}
+
+// GetAccountCompute lists every cluster of an account in the given regions with its nodegroups and Fargate profiles
+func GetAccountCompute(ctx context.Context, newLister ComputeListerFactory, profile, accountID string, regions []string) ([]ClusterCompute, error) {
+	var computes []ClusterCompute
+	for _, region := range regions {
+		lister, err := newLister(ctx, region, profile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create EKS client: %w", err)
+		}
+
+		clusters, err := ListClustersWith(ctx, lister, profile, accountID, region)
+		if err != nil {
+			return nil, fmt.Errorf("region %s: %w", region, err)
+		}
+		for _, cluster := range clusters {
+			compute, err := GetClusterCompute(ctx, lister, cluster)
+			if err != nil {
+				return nil, err
+			}
+			computes = append(computes, *compute)
+		}
+	}
+	return computes, nil
+}
+
+// GetClusterCompute describes a cluster, for its control plane version, and all its nodegroups and Fargate profiles
+func GetClusterCompute(ctx context.Context, lister ComputeLister, cluster EKSCluster) (*ClusterCompute, error) {
+	if err := lister.DescribeCluster(ctx, &cluster); err != nil {
+		return nil, err
+	}
+	compute := &ClusterCompute{Cluster: cluster}
+
+	nodegroups, err := lister.ListNodegroups(ctx, cluster.Name)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range nodegroups {
+		nodegroup, err := lister.DescribeNodegroup(ctx, cluster.Name, name)
+		if err != nil {
+			return nil, err
+		}
+		compute.Nodegroups = append(compute.Nodegroups, *nodegroup)
+	}
+
+	profiles, err := lister.ListFargateProfiles(ctx, cluster.Name)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range profiles {
+		profile, err := lister.DescribeFargateProfile(ctx, cluster.Name, name)
+		if err != nil {
+			return nil, err
+		}
+		compute.FargateProfiles = append(compute.FargateProfiles, *profile)
+	}
+	return compute, nil
+}
+
+// VersionSkew returns how many minor versions a nodegroup is behind its control plane, e.g. 2 for 1.28 nodes on 1.30
+func VersionSkew(controlPlane, nodegroup string) (int, error) {
+	controlMinor, err := KubernetesMinorVersion(controlPlane)
+	if err != nil {
+		return 0, err
+	}
+	nodeMinor, err := KubernetesMinorVersion(nodegroup)
+	if err != nil {
+		return 0, err
+	}
+	return controlMinor - nodeMinor, nil
+}
+
+// KubernetesMinorVersion extracts the minor number of a Kubernetes version such as "1.30"
+func KubernetesMinorVersion(version string) (int, error) {
+	major, minor, ok := strings.Cut(version, ".")
+	if !ok || major != "1" {
+		return 0, fmt.Errorf("unexpected Kubernetes version %q", version)
+	}
+	minor, _, _ = strings.Cut(minor, ".")
+	number, err := strconv.Atoi(minor)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected Kubernetes version %q", version)
+	}
+	return number, nil
+}
@@ -0,0 +1,121 @@
+package services_aws
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeComputeLister serves clusters, nodegroups and Fargate profiles from memory
+type fakeComputeLister struct {
+	fakeClusterLister
+	version    string
+	nodegroups map[string][]EKSNodegroup
+	fargate    map[string][]EKSFargateProfile
+	computeErr error
+}
+
+func (f *fakeComputeLister) DescribeCluster(ctx context.Context, cluster *EKSCluster) error {
+	cluster.Version = f.version
+	return f.err
+}
+
+func (f *fakeComputeLister) ListNodegroups(ctx context.Context, clusterName string) ([]string, error) {
+	var names []string
+	for _, nodegroup := range f.nodegroups[clusterName] {
+		names = append(names, nodegroup.Name)
+	}
+	return names, f.computeErr
+}
+
+func (f *fakeComputeLister) DescribeNodegroup(ctx context.Context, clusterName, name string) (*EKSNodegroup, error) {
+	for _, nodegroup := range f.nodegroups[clusterName] {
+		if nodegroup.Name == name {
+			return &nodegroup, nil
+		}
+	}
+	return nil, errors.New("nodegroup not found")
+}
+
+func (f *fakeComputeLister) ListFargateProfiles(ctx context.Context, clusterName string) ([]string, error) {
+	var names []string
+	for _, profile := range f.fargate[clusterName] {
+		names = append(names, profile.Name)
+	}
+	return names, nil
+}
+
+func (f *fakeComputeLister) DescribeFargateProfile(ctx context.Context, clusterName, name string) (*EKSFargateProfile, error) {
+	for _, profile := range f.fargate[clusterName] {
+		if profile.Name == name {
+			return &profile, nil
+		}
+	}
+	return nil, errors.New("Fargate profile not found")
+}
+
+func TestGetAccountCompute(t *testing.T) {
+	lister := &fakeComputeLister{
+		fakeClusterLister: fakeClusterLister{names: []string{"api", "batch"}},
+		version:           "1.30",
+		nodegroups: map[string][]EKSNodegroup{
+			"api": {{Cluster: "api", Name: "default", Version: "1.29", MinSize: 1, DesiredSize: 2, MaxSize: 4}},
+		},
+		fargate: map[string][]EKSFargateProfile{
+			"batch": {{Cluster: "batch", Name: "jobs", Namespaces: []string{"jobs"}}},
+		},
+	}
+	var regions []string
+	newLister := func(ctx context.Context, region, profile string) (ComputeLister, error) {
+		regions = append(regions, region)
+		return lister, nil
+	}
+
+	computes, err := GetAccountCompute(context.Background(), newLister, "dev", "111111111111", []string{"us-west-2", "eu-west-1"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"us-west-2", "eu-west-1"}, regions)
+	require.Len(t, computes, 4)
+
+	api := computes[0]
+	assert.Equal(t, EKSCluster{Name: "api", Region: "us-west-2", AccountID: "111111111111", Profile: "dev", Version: "1.30"}, api.Cluster)
+	assert.Equal(t, []EKSNodegroup{{Cluster: "api", Name: "default", Version: "1.29", MinSize: 1, DesiredSize: 2, MaxSize: 4}}, api.Nodegroups)
+	assert.Empty(t, api.FargateProfiles)
+	assert.Equal(t, []EKSFargateProfile{{Cluster: "batch", Name: "jobs", Namespaces: []string{"jobs"}}}, computes[1].FargateProfiles)
+	assert.Equal(t, "eu-west-1", computes[2].Cluster.Region)
+
+	lister.computeErr = errors.New("access denied")
+	_, err = GetAccountCompute(context.Background(), newLister, "dev", "111111111111", []string{"us-west-2"})
+	assert.ErrorContains(t, err, "access denied")
+
+	_, err = GetAccountCompute(context.Background(), func(ctx context.Context, region, profile string) (ComputeLister, error) {
+		return nil, errors.New("no credentials")
+	}, "dev", "111111111111", []string{"us-west-2"})
+	assert.ErrorContains(t, err, "failed to create EKS client")
+}
+
+func TestVersionSkew(t *testing.T) {
+	tests := []struct {
+		controlPlane, nodegroup string
+		skew                    int
+		wantErr                 bool
+	}{
+		{controlPlane: "1.30", nodegroup: "1.30", skew: 0},
+		{controlPlane: "1.30", nodegroup: "1.28", skew: 2},
+		{controlPlane: "1.29", nodegroup: "1.30", skew: -1},
+		{controlPlane: "1.30", nodegroup: "1.29.6", skew: 1},
+		{controlPlane: "", nodegroup: "1.29", wantErr: true},
+		{controlPlane: "1.30", nodegroup: "latest", wantErr: true},
+	}
+	for _, tt := range tests {
+		skew, err := VersionSkew(tt.controlPlane, tt.nodegroup)
+		if tt.wantErr {
+			assert.Error(t, err, "%s vs %s", tt.controlPlane, tt.nodegroup)
+			continue
+		}
+		require.NoError(t, err)
+		assert.Equal(t, tt.skew, skew, "%s vs %s", tt.controlPlane, tt.nodegroup)
+	}
+}
@@ -3,9 +3,14 @@ package services_aws
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/andresgarcia29/ark-cli/lib"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestListEKSClusters(t *testing.T) {
@@ -189,6 +194,55 @@ func TestGetClustersFromAllAccounts(t *testing.T) {
 	}
 }
 
+func TestPlanClusterScan(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	config := `[profile dev-readonly]
+sso_account_id = 111111111111
+sso_role_name = ReadOnly
+
+[profile dev-admin]
+sso_account_id = 111111111111
+sso_role_name = Admin
+
+[profile prod-readonly]
+sso_account_id = 222222222222
+sso_role_name = ReadOnly
+
+[profile sandbox-admin]
+sso_account_id = 333333333333
+sso_role_name = Admin
+
+[profile sandbox-deploy]
+sso_account_id = 333333333333
+role_arn = arn:aws:iam::333333333333:role/Deploy
+`
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".aws"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".aws", "config"), []byte(config), 0600))
+
+	plan, err := PlanClusterScan([]string{"us-west-2", "eu-west-1"}, []string{"readonly"}, "")
+	require.NoError(t, err)
+	assert.Equal(t, ScanPlan{Accounts: 3, Regions: []string{"us-west-2", "eu-west-1"}}, plan, "every account is scanned once")
+	assert.Equal(t, 6, plan.Targets())
+	assert.Equal(t, 9, plan.EstimatedAPICalls())
+	assert.Equal(t, 3*time.Second, plan.EstimatedDuration(), "one batch of accounts")
+
+	plan, err = PlanClusterScan(nil, nil, "arn:aws:iam::333333333333:role/Deploy")
+	require.NoError(t, err)
+	assert.Equal(t, ScanPlan{Accounts: 1, Regions: []string{"us-west-2"}}, plan, "no regions scans the default one")
+}
+
+func TestLoginService(t *testing.T) {
+	assert.Equal(t, lib.ServiceSSO, loginService(map[string]ProfileConfig{
+		"111111111111": {ProfileType: ProfileTypeAssumeRole},
+		"222222222222": {ProfileType: ProfileTypeSSO},
+	}))
+	assert.Equal(t, lib.ServiceSTS, loginService(map[string]ProfileConfig{
+		"111111111111": {ProfileType: ProfileTypeAssumeRole},
+	}))
+}
+
 func TestEKSClusterStruct(t *testing.T) {
 	// Test EKSCluster struct fields
 	cluster := EKSCluster{
@@ -0,0 +1,80 @@
+package services_aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/andresgarcia29/ark-cli/logs"
+	"github.com/andresgarcia29/ark-cli/services/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/iam/types"
+)
+
+// IAMPrincipal is the account, kind ("role" or "user") and name of an IAM principal ARN
+type IAMPrincipal struct {
+	AccountID string
+	Kind      string
+	Name      string
+}
+
+// ParseIAMPrincipal splits an IAM role or user ARN such as arn:aws:iam::123456789012:role/path/RoleName
+// The path is dropped, IAM names are unique within an account
+func ParseIAMPrincipal(principalARN string) (IAMPrincipal, error) {
+	parsed, err := arn.Parse(principalARN)
+	if err != nil || parsed.Service != "iam" {
+		return IAMPrincipal{}, fmt.Errorf("not an IAM ARN: %q", principalARN)
+	}
+
+	kind, resource, ok := strings.Cut(parsed.Resource, "/")
+	if !ok || (kind != "role" && kind != "user") || resource == "" {
+		return IAMPrincipal{}, fmt.Errorf("not an IAM role or user ARN: %q", principalARN)
+	}
+	return IAMPrincipal{
+		AccountID: parsed.AccountID,
+		Kind:      kind,
+		Name:      resource[strings.LastIndex(resource, "/")+1:],
+	}, nil
+}
+
+// IAMClient looks up the IAM roles and users of an account
+type IAMClient struct {
+	client *iam.Client
+}
+
+// NewIAMClient creates an IAM client for the account of a profile
+func NewIAMClient(ctx context.Context, profile string) (*IAMClient, error) {
+	cfg, err := NewAWSConfig(ctx, ClientConfig{Profile: profile})
+	if err != nil {
+		return nil, fmt.Errorf("unable to load SDK config: %w", err)
+	}
+	return &IAMClient{client: newIAMClient(cfg)}, nil
+}
+
+// PrincipalExists reports whether the IAM role or user of an ARN still exists
+func (c *IAMClient) PrincipalExists(ctx context.Context, principalARN string) (bool, error) {
+	logger := logs.GetLogger()
+
+	principal, err := ParseIAMPrincipal(principalARN)
+	if err != nil {
+		return false, err
+	}
+
+	if principal.Kind == "role" {
+		_, err = c.client.GetRole(ctx, &iam.GetRoleInput{RoleName: aws.String(principal.Name)})
+	} else {
+		_, err = c.client.GetUser(ctx, &iam.GetUserInput{UserName: aws.String(principal.Name)})
+	}
+
+	var notFound *types.NoSuchEntityException
+	if errors.As(err, &notFound) {
+		logger.Debugw("IAM principal not found", "arn", principalARN)
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to look up %s %s: %w", principal.Kind, principal.Name, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,28 @@
+package services_aws
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseIAMPrincipal(t *testing.T) {
+	principal, err := ParseIAMPrincipal("arn:aws:iam::111111111111:role/service-role/Deployer")
+	require.NoError(t, err)
+	assert.Equal(t, IAMPrincipal{AccountID: "111111111111", Kind: "role", Name: "Deployer"}, principal)
+
+	principal, err = ParseIAMPrincipal("arn:aws:iam::111111111111:user/alice")
+	require.NoError(t, err)
+	assert.Equal(t, IAMPrincipal{AccountID: "111111111111", Kind: "user", Name: "alice"}, principal)
+
+	for _, arn := range []string{
+		"111111111111",
+		"arn:aws:sts::111111111111:assumed-role/Admin/alice",
+		"arn:aws:iam::111111111111:group/Developers",
+		"arn:aws:iam::111111111111:role/",
+	} {
+		_, err := ParseIAMPrincipal(arn)
+		assert.Error(t, err, arn)
+	}
+}
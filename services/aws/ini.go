@@ -0,0 +1,268 @@
+package services_aws
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// iniDocument is an AWS config or credentials file that keeps comments, blank lines, key order and
+// unknown keys, so updating one section leaves the rest of the file as it was
+type iniDocument struct {
+	// preamble holds the lines before the first section
+	preamble []string
+	sections []*iniSection
+}
+
+// iniSection is one [name] block
+type iniSection struct {
+	name string
+	// comments are the comment lines directly above the header
+	comments []string
+	// body holds the lines after the header, blank lines and comments included
+	body []string
+}
+
+// parseINIDocument parses an INI file; comment lines right above a header belong to that section
+func parseINIDocument(content string) *iniDocument {
+	doc := &iniDocument{}
+	if content == "" {
+		return doc
+	}
+
+	var current *iniSection
+	var pending []string
+	for _, line := range strings.Split(strings.TrimSuffix(content, "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]"):
+			current = &iniSection{name: strings.TrimSpace(trimmed[1 : len(trimmed)-1]), comments: pending}
+			doc.sections = append(doc.sections, current)
+			pending = nil
+		case isINIComment(trimmed):
+			pending = append(pending, line)
+		default:
+			doc.appendLines(current, pending...)
+			doc.appendLines(current, line)
+			pending = nil
+		}
+	}
+	doc.appendLines(current, pending...)
+	return doc
+}
+
+// isINIComment reports whether a trimmed line is a comment
+func isINIComment(trimmed string) bool {
+	return strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, ";")
+}
+
+// appendLines adds lines to the body of section, or to the preamble before the first section
+func (d *iniDocument) appendLines(section *iniSection, lines ...string) {
+	if section == nil {
+		d.preamble = append(d.preamble, lines...)
+		return
+	}
+	section.body = append(section.body, lines...)
+}
+
+// String renders the document, ending with a newline
+func (d *iniDocument) String() string {
+	var lines []string
+	lines = append(lines, d.preamble...)
+	for _, section := range d.sections {
+		lines = append(lines, section.comments...)
+		lines = append(lines, fmt.Sprintf("[%s]", section.name))
+		lines = append(lines, section.body...)
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// section returns the first section called name, or nil
+func (d *iniDocument) section(name string) *iniSection {
+	for _, section := range d.sections {
+		if section.name == name {
+			return section
+		}
+	}
+	return nil
+}
+
+// upsert returns the section called name, adding an empty one at the end (or at the start when first is set)
+func (d *iniDocument) upsert(name string, first bool) *iniSection {
+	if section := d.section(name); section != nil {
+		return section
+	}
+
+	section := &iniSection{name: name}
+	if first && len(d.sections) > 0 {
+		section.body = []string{""}
+		d.sections = append([]*iniSection{section}, d.sections...)
+		return section
+	}
+
+	// Keep a blank line between the previous block and the new one
+	if last := d.lastLine(); last != nil && strings.TrimSpace(*last) != "" {
+		if len(d.sections) > 0 {
+			previous := d.sections[len(d.sections)-1]
+			previous.body = append(previous.body, "")
+		} else {
+			d.preamble = append(d.preamble, "")
+		}
+	}
+	d.sections = append(d.sections, section)
+	return section
+}
+
+// lastLine returns the last rendered line, or nil for an empty document
+func (d *iniDocument) lastLine() *string {
+	if n := len(d.sections); n > 0 {
+		if body := d.sections[n-1].body; len(body) > 0 {
+			return &body[len(body)-1]
+		}
+		header := fmt.Sprintf("[%s]", d.sections[n-1].name)
+		return &header
+	}
+	if n := len(d.preamble); n > 0 {
+		return &d.preamble[n-1]
+	}
+	return nil
+}
+
+// remove deletes every section called name with the comments above it, reporting whether one existed
+func (d *iniDocument) remove(name string) bool {
+	kept := d.sections[:0]
+	removed := false
+	for _, section := range d.sections {
+		if section.name == name {
+			removed = true
+			continue
+		}
+		kept = append(kept, section)
+	}
+	d.sections = kept
+	return removed
+}
+
+// values returns the keys of every section; later duplicates win, as in the AWS SDK
+func (d *iniDocument) values() map[string]map[string]string {
+	result := make(map[string]map[string]string, len(d.sections))
+	for _, section := range d.sections {
+		values := result[section.name]
+		if values == nil {
+			values = make(map[string]string)
+			result[section.name] = values
+		}
+		for _, line := range section.body {
+			if key, value, ok := parseINIKeyValue(line); ok {
+				values[key] = value
+			}
+		}
+	}
+	return result
+}
+
+// parseINIKeyValue splits a key = value line
+func parseINIKeyValue(line string) (key, value string, ok bool) {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || isINIComment(trimmed) {
+		return "", "", false
+	}
+	key, value, ok = strings.Cut(trimmed, "=")
+	if !ok {
+		return "", "", false
+	}
+	return strings.TrimSpace(key), strings.TrimSpace(value), true
+}
+
+// get returns the value of a key in the section
+func (s *iniSection) get(key string) (string, bool) {
+	for _, line := range s.body {
+		if k, value, ok := parseINIKeyValue(line); ok && k == key {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// set replaces a key in place, or adds it after the last key of the section
+func (s *iniSection) set(key, value string) {
+	line := fmt.Sprintf("%s = %s", key, value)
+	lastKey := -1
+	for i, existing := range s.body {
+		k, _, ok := parseINIKeyValue(existing)
+		if !ok {
+			continue
+		}
+		if k == key {
+			s.body[i] = line
+			return
+		}
+		lastKey = i
+	}
+	s.body = append(s.body[:lastKey+1], append([]string{line}, s.body[lastKey+1:]...)...)
+}
+
+// setComment replaces the comment above the header that starts with prefix, adding it when missing
+func (s *iniSection) setComment(prefix, comment string) {
+	for i, existing := range s.comments {
+		if strings.HasPrefix(strings.TrimSpace(existing), prefix) {
+			s.comments[i] = comment
+			return
+		}
+	}
+	s.comments = append(s.comments, comment)
+}
+
+// hasComment reports whether a comment above the header starts with prefix
+func (s *iniSection) hasComment(prefix string) bool {
+	return slices.ContainsFunc(s.comments, func(existing string) bool {
+		return strings.HasPrefix(strings.TrimSpace(existing), prefix)
+	})
+}
+
+// removeComment deletes the comments above the header that start with prefix
+func (s *iniSection) removeComment(prefix string) {
+	s.comments = slices.DeleteFunc(s.comments, func(existing string) bool {
+		return strings.HasPrefix(strings.TrimSpace(existing), prefix)
+	})
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames it over path,
+// so a crash or a full disk never leaves a truncated config or credentials file
+// The rename would replace a symlinked file (e.g. one kept in a dotfiles repo) with a regular file, so the file
+// it points to is written instead
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	if target, err := filepath.EvalSymlinks(path); err == nil {
+		path = target
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	// A unique temporary file, so concurrent writers never write into each other's
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".ark-tmp-*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), perm)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package services_aws
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestINIDocumentRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "empty", content: ""},
+		{name: "preamble only", content: "# credentials for the team\n"},
+		{
+			name: "comments, blank lines and odd spacing",
+			content: "# top comment\n\n" +
+				"[default]\naws_access_key_id=AKIA1\n; inline note\naws_secret_access_key =  s1\n\n\n" +
+				"# above static\n[static]\n  region = eu-west-1\naws_access_key_id = AKIA2\n\n" +
+				"# trailing comment\n",
+		},
+		{
+			name:    "sso-session and duplicate keys",
+			content: "[sso-session corp]\nsso_start_url = https://corp.awsapps.com/start\n[profile a]\nregion = us-east-1\nregion = us-west-2\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.content, parseINIDocument(tt.content).String())
+		})
+	}
+
+	// A missing final newline is the only thing normalized
+	assert.Equal(t, "[a]\nk = v\n", parseINIDocument("[a]\nk = v").String())
+}
+
+func TestINIDocumentEdits(t *testing.T) {
+	doc := parseINIDocument("# keep me\n[static]\naws_access_key_id = AKIASTATIC\n\n# about old\n[old]\nregion = us-east-1\n# old note\n")
+
+	old := doc.section("old")
+	require.NotNil(t, old)
+	assert.Equal(t, []string{"# about old"}, old.comments)
+	old.set("region", "eu-west-1")
+	old.set("output", "json")
+	value, ok := old.get("output")
+	assert.True(t, ok)
+	assert.Equal(t, "json", value)
+
+	doc.upsert("new", false).set("k", "v")
+	doc.upsert("default", true).set("k", "d")
+
+	// Comments directly above a header move with their section
+	assert.Equal(t, "[default]\nk = d\n\n"+
+		"# keep me\n[static]\naws_access_key_id = AKIASTATIC\n\n"+
+		"# about old\n[old]\nregion = eu-west-1\noutput = json\n# old note\n\n"+
+		"[new]\nk = v\n", doc.String())
+
+	assert.True(t, doc.remove("old"))
+	assert.False(t, doc.remove("missing"))
+	assert.NotContains(t, doc.String(), "about old")
+	assert.Equal(t, map[string]string{"aws_access_key_id": "AKIASTATIC"}, doc.values()["static"])
+}
+
+func TestWriteCredentialsPreservesFile(t *testing.T) {
+	credentialsPath := filepath.Join(t.TempDir(), "credentials")
+	t.Setenv(CredentialsFileEnv, credentialsPath)
+
+	original := "# Long-lived keys, rotate quarterly\n" +
+		"[static]\naws_access_key_id = AKIASTATIC\naws_secret_access_key = s\nregion = eu-west-1\n\n" +
+		"[dev]\n# used by terraform\naws_access_key_id = AKIAOLD\naws_secret_access_key = old\noutput = json\n"
+	require.NoError(t, os.WriteFile(credentialsPath, []byte(original), 0600))
+
+	expiration := time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC)
+	creds := &Credentials{AccessKeyID: "AKIANEW", SecretAccessKey: "new", SessionToken: "token", Expiration: expiration.UnixMilli()}
+	require.NoError(t, WriteCredentialsFile("dev", creds, false))
+
+	data, err := os.ReadFile(credentialsPath)
+	require.NoError(t, err)
+	expires := expiration.Local().Format(time.RFC3339)
+	assert.Equal(t, "# Long-lived keys, rotate quarterly\n"+
+		"[static]\naws_access_key_id = AKIASTATIC\naws_secret_access_key = s\nregion = eu-west-1\n\n"+
+		"# managed by ark, expires "+expires+"\n"+
+		"[dev]\n# used by terraform\naws_access_key_id = AKIANEW\naws_secret_access_key = new\noutput = json\n"+
+		"aws_session_token = token\nexpiration = "+expires+"\n", string(data))
+
+	// Writing the same profile again only changes its values
+	require.NoError(t, WriteCredentialsFile("dev", creds, false))
+	again, err := os.ReadFile(credentialsPath)
+	require.NoError(t, err)
+	assert.Equal(t, string(data), string(again))
+
+	entries, err := os.ReadDir(filepath.Dir(credentialsPath))
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "no temporary file is left behind")
+	assert.Equal(t, "credentials", entries[0].Name())
+}
+
+func TestWriteCredentialsFileKeepsSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "dotfiles", "credentials")
+	require.NoError(t, os.MkdirAll(filepath.Dir(target), 0700))
+	require.NoError(t, os.WriteFile(target, []byte("[static]\naws_access_key_id = AKIASTATIC\n"), 0600))
+	link := filepath.Join(dir, "credentials")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks are not supported: %v", err)
+	}
+	t.Setenv(CredentialsFileEnv, link)
+
+	creds := &Credentials{AccessKeyID: "AKIANEW", SecretAccessKey: "new", SessionToken: "token", Expiration: time.Now().Add(time.Hour).UnixMilli()}
+	require.NoError(t, WriteCredentialsFile("dev", creds, false))
+
+	info, err := os.Lstat(link)
+	require.NoError(t, err)
+	assert.Equal(t, os.ModeSymlink, info.Mode().Type(), "the credentials file is still a symlink")
+	data, err := os.ReadFile(target)
+	require.NoError(t, err)
+	sections := parseINIFile(string(data))
+	assert.Equal(t, "AKIASTATIC", sections["static"]["aws_access_key_id"])
+	assert.Equal(t, "AKIANEW", sections["dev"]["aws_access_key_id"], "the file it points to was written")
+
+	entries, err := os.ReadDir(filepath.Dir(target))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temporary file is left behind")
+}
+
+func TestWriteConfigFilePreservesFile(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config")
+	t.Setenv(ConfigFileEnv, configPath)
+
+	original := "# Managed partly by hand\n" +
+		"[default]\nregion = eu-west-1\n\n" +
+		"[sso-session corp]\nsso_start_url = https://corp.awsapps.com/start\n\n" +
+		"# prod access\n[profile prod-readonly]\nsso_start_url = https://old.awsapps.com/start\nsso_region = us-east-1\nsso_account_id = 222222222222\nsso_role_name = ReadOnly\nregion = us-west-2\noutput = json\n\n" +
+		"[profile gone-admin]\nsso_account_id = 333333333333\nsso_role_name = Admin\n"
+	require.NoError(t, os.WriteFile(configPath, []byte(original), 0600))
+
+	client := &SSOClient{Region: "us-east-1", StartURL: "https://example.awsapps.com/start"}
+	require.NoError(t, client.WriteConfigFile([]AWSProfile{
+		{AccountID: "222222222222", AccountName: "prod", RoleName: "ReadOnly"},
+		{AccountID: "111111111111", AccountName: "dev", RoleName: "ReadOnly"},
+	}))
+
+	data, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "# Managed partly by hand\n"+
+		"[default]\nregion = eu-west-1\n\n"+
+		"[sso-session corp]\nsso_start_url = https://corp.awsapps.com/start\n\n"+
+		"# prod access\n# managed by ark\n[profile prod-readonly]\nsso_start_url = https://example.awsapps.com/start\nsso_region = us-east-1\nsso_account_id = 222222222222\nsso_role_name = ReadOnly\nregion = us-west-2\noutput = json\n\n"+
+		"# managed by ark\n[profile dev-readonly]\nsso_start_url = https://example.awsapps.com/start\nsso_region = us-east-1\nsso_account_id = 111111111111\nsso_role_name = ReadOnly\nregion = us-east-1\n", string(data))
+}
+
+func TestWriteConfigFileAccountTags(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config")
+	t.Setenv(ConfigFileEnv, configPath)
+
+	original := "# ark tags: env=dev\n# dev access\n[profile dev-readonly]\nsso_account_id = 111111111111\nsso_role_name = ReadOnly\n\n" +
+		"# ark tags: env=prod\n[profile prod-readonly]\nsso_account_id = 222222222222\nsso_role_name = ReadOnly\n\n" +
+		"# ark tags: env=sandbox\n[profile sandbox-readonly]\nsso_account_id = 333333333333\nsso_role_name = ReadOnly\n"
+	require.NoError(t, os.WriteFile(configPath, []byte(original), 0600))
+
+	client := &SSOClient{Region: "us-east-1", StartURL: "https://example.awsapps.com/start"}
+	profiles := []AWSProfile{
+		// Tags that weren't read are kept, an empty set clears them
+		{AccountID: "111111111111", AccountName: "dev", RoleName: "ReadOnly"},
+		{AccountID: "222222222222", AccountName: "prod", RoleName: "ReadOnly", Tags: map[string]string{"env": "prod", "team": "payments"}},
+		{AccountID: "333333333333", AccountName: "sandbox", RoleName: "ReadOnly", Tags: map[string]string{}},
+	}
+
+	diff, err := client.DiffConfigFile(profiles)
+	require.NoError(t, err)
+	require.Len(t, diff.Updated, 3)
+	assert.Contains(t, diff.Updated[1].Changes, "tags: env=prod → env=prod, team=payments")
+	assert.Contains(t, diff.Updated[2].Changes, "tags: env=sandbox → (none)")
+
+	require.NoError(t, client.WriteConfigFile(profiles))
+	data, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "# ark tags: env=dev\n# dev access\n# managed by ark\n[profile dev-readonly]")
+	assert.Contains(t, string(data), "# ark tags: env=prod, team=payments\n# managed by ark\n[profile prod-readonly]")
+	assert.NotContains(t, string(data), "env=sandbox")
+
+	parsed, err := parseAllProfilesFromConfigData(data)
+	require.NoError(t, err)
+	require.Len(t, parsed, 3)
+	assert.Equal(t, map[string]string{"env": "dev"}, parsed[0].Tags)
+	assert.Equal(t, map[string]string{"env": "prod", "team": "payments"}, parsed[1].Tags)
+	assert.Nil(t, parsed[2].Tags)
+}
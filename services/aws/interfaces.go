@@ -0,0 +1,167 @@
+package services_aws
+
+import "context"
+
+// AccountLister lists the accounts an SSO access token can reach
+type AccountLister interface {
+	ListAccounts(ctx context.Context, accessToken string) ([]Account, error)
+}
+
+// RoleLister lists the roles an SSO access token can assume in an account
+type RoleLister interface {
+	ListAccountRoles(ctx context.Context, accessToken, accountID string) ([]Role, error)
+}
+
+// ProfileLister is everything CollectProfiles needs to build the account+role profiles
+type ProfileLister interface {
+	AccountLister
+	RoleLister
+}
+
+// DeviceAuthorizer runs the OIDC device authorization flow of an SSO portal and caches the token it gets,
+// and the client registration used to get it
+type DeviceAuthorizer interface {
+	RegisterClient(ctx context.Context) (*ClientRegistration, error)
+	ReadClientRegistration() (*ClientRegistration, error)
+	SaveClientRegistration(registration *ClientRegistration) error
+	StartDeviceAuthorization(ctx context.Context, clientID, clientSecret string) (*DeviceAuthorization, error)
+	CreateToken(ctx context.Context, clientID, clientSecret, deviceCode string, interval, expiresIn int32) (*TokenResponse, error)
+	SaveTokenToCache(token *TokenResponse) error
+}
+
+// AuthCodeAuthorizer runs the OIDC authorization code flow with PKCE of an SSO portal, where the browser
+// redirects to a loopback listener instead of the user confirming a device code
+type AuthCodeAuthorizer interface {
+	RegisterAuthCodeClient(ctx context.Context) (*ClientRegistration, error)
+	ReadAuthCodeClientRegistration() (*ClientRegistration, error)
+	SaveAuthCodeClientRegistration(registration *ClientRegistration) error
+	AuthorizationURL(registration *ClientRegistration, redirectURI, state, codeChallenge string) string
+	CreateTokenWithAuthCode(ctx context.Context, clientID, clientSecret, code, codeVerifier, redirectURI string) (*TokenResponse, error)
+	SaveTokenToCache(token *TokenResponse) error
+}
+
+// ProfileConfigWriter writes the profiles of an SSO portal to the AWS config file
+type ProfileConfigWriter interface {
+	DiffConfigFile(profiles []AWSProfile) (ProfileDiff, error)
+	WriteConfigFile(profiles []AWSProfile) error
+	AppendNewProfiles(profiles []AWSProfile) ([]string, error)
+}
+
+// SSOPortalClient is everything signing in to an SSO portal and bootstrapping its profiles needs
+type SSOPortalClient interface {
+	DeviceAuthorizer
+	ProfileLister
+	ProfileConfigWriter
+	// CredentialProvider signs in to the role reading account tags
+	CredentialProvider
+}
+
+// SSOPortalClientFactory creates the SSOPortalClient for an SSO region and start URL
+type SSOPortalClientFactory func(ctx context.Context, region, startURL string) (SSOPortalClient, error)
+
+// CredentialProvider exchanges an SSO access token for temporary role credentials
+type CredentialProvider interface {
+	GetRoleCredentials(ctx context.Context, accessToken, accountID, roleName string) (*Credentials, error)
+}
+
+// ClusterLister lists and describes the EKS clusters of one account and region
+type ClusterLister interface {
+	ListClusters(ctx context.Context) ([]string, error)
+	DescribeCluster(ctx context.Context, cluster *EKSCluster) error
+}
+
+// ClusterListerFactory creates the ClusterLister for a region and profile
+type ClusterListerFactory func(ctx context.Context, region, profile string) (ClusterLister, error)
+
+// ComputeLister also lists and describes the nodegroups and Fargate profiles of the clusters
+type ComputeLister interface {
+	ClusterLister
+	ListNodegroups(ctx context.Context, clusterName string) ([]string, error)
+	DescribeNodegroup(ctx context.Context, clusterName, name string) (*EKSNodegroup, error)
+	ListFargateProfiles(ctx context.Context, clusterName string) ([]string, error)
+	DescribeFargateProfile(ctx context.Context, clusterName, name string) (*EKSFargateProfile, error)
+}
+
+// ComputeListerFactory creates the ComputeLister for a region and profile
+type ComputeListerFactory func(ctx context.Context, region, profile string) (ComputeLister, error)
+
+// AddonLister also lists and describes the add-ons of the clusters, and the versions available for them
+type AddonLister interface {
+	ClusterLister
+	ListAddons(ctx context.Context, clusterName string) ([]string, error)
+	DescribeAddon(ctx context.Context, clusterName, name string) (*EKSAddon, error)
+	LatestAddonVersion(ctx context.Context, name, kubernetesVersion string) (string, error)
+}
+
+// AddonListerFactory creates the AddonLister for a region and profile
+type AddonListerFactory func(ctx context.Context, region, profile string) (AddonLister, error)
+
+// AccessManager manages the access entries and access policies of EKS clusters
+type AccessManager interface {
+	ListAccessEntries(ctx context.Context, clusterName string) ([]string, error)
+	DescribeAccessEntry(ctx context.Context, clusterName, principalARN string) (*EKSAccessEntry, error)
+	ListAssociatedAccessPolicies(ctx context.Context, clusterName, principalARN string) ([]EKSAccessPolicy, error)
+	CreateAccessEntry(ctx context.Context, clusterName, principalARN string, kubernetesGroups []string) error
+	AssociateAccessPolicy(ctx context.Context, clusterName, principalARN string, policy EKSAccessPolicy) error
+	DisassociateAccessPolicy(ctx context.Context, clusterName, principalARN, policyARN string) error
+	DeleteAccessEntry(ctx context.Context, clusterName, principalARN string) error
+}
+
+// RegionLister lists the regions enabled for an account
+type RegionLister interface {
+	ListRegions(ctx context.Context) ([]Region, error)
+}
+
+// PrincipalChecker checks whether IAM roles and users exist
+type PrincipalChecker interface {
+	PrincipalExists(ctx context.Context, principalARN string) (bool, error)
+}
+
+var (
+	_ ProfileLister      = (*SSOClient)(nil)
+	_ SSOPortalClient    = (*SSOClient)(nil)
+	_ AuthCodeAuthorizer = (*SSOClient)(nil)
+	_ CredentialProvider = (*SSOClient)(nil)
+	_ ClusterLister      = (*EKSClient)(nil)
+	_ ComputeLister      = (*EKSClient)(nil)
+	_ AddonLister        = (*EKSClient)(nil)
+	_ AccessManager      = (*EKSClient)(nil)
+	_ PrincipalChecker   = (*IAMClient)(nil)
+	_ IdentityChecker    = (*STSClient)(nil)
+	_ RegionLister       = (*EC2Client)(nil)
+)
+
+// NewSSOPortalClient is the default SSOPortalClientFactory, backed by the SSO and SSO OIDC APIs
+func NewSSOPortalClient(ctx context.Context, region, startURL string) (SSOPortalClient, error) {
+	return NewSSOClient(ctx, region, startURL)
+}
+
+// NewClusterLister is the default ClusterListerFactory, backed by the EKS API
+func NewClusterLister(ctx context.Context, region, profile string) (ClusterLister, error) {
+	return NewEKSClient(ctx, region, profile)
+}
+
+// NewComputeLister is the default ComputeListerFactory, backed by the EKS API
+func NewComputeLister(ctx context.Context, region, profile string) (ComputeLister, error) {
+	return NewEKSClient(ctx, region, profile)
+}
+
+// NewAddonLister is the default AddonListerFactory, backed by the EKS API
+func NewAddonLister(ctx context.Context, region, profile string) (AddonLister, error) {
+	return NewEKSClient(ctx, region, profile)
+}
+
+// NewAccessManager creates the AccessManager for a region and profile, backed by the EKS API
+func NewAccessManager(ctx context.Context, region, profile string) (AccessManager, error) {
+	return NewEKSClient(ctx, region, profile)
+}
+
+// NewPrincipalChecker creates the PrincipalChecker for the account of a profile, backed by the IAM API
+func NewPrincipalChecker(ctx context.Context, profile string) (PrincipalChecker, error) {
+	return NewIAMClient(ctx, profile)
+}
+
+// NewRegionLister creates the RegionLister for the account of a profile, backed by the EC2 API
+func NewRegionLister(ctx context.Context, profile string) (RegionLister, error) {
+	return NewEC2Client(ctx, profile)
+}
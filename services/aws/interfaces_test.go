@@ -0,0 +1,167 @@
+package services_aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProfileLister serves accounts and roles from memory
+type fakeProfileLister struct {
+	accounts    []Account
+	roles       map[string][]Role
+	accountsErr error
+	rolesErr    map[string]error
+}
+
+func (f *fakeProfileLister) ListAccounts(ctx context.Context, accessToken string) ([]Account, error) {
+	return f.accounts, f.accountsErr
+}
+
+func (f *fakeProfileLister) ListAccountRoles(ctx context.Context, accessToken, accountID string) ([]Role, error) {
+	if err := f.rolesErr[accountID]; err != nil {
+		return nil, err
+	}
+	return f.roles[accountID], nil
+}
+
+// fakeClusterLister serves cluster names from memory
+type fakeClusterLister struct {
+	names []string
+	err   error
+}
+
+func (f *fakeClusterLister) ListClusters(ctx context.Context) ([]string, error) {
+	return f.names, f.err
+}
+
+func (f *fakeClusterLister) DescribeCluster(ctx context.Context, cluster *EKSCluster) error {
+	cluster.Endpoint = "https://" + cluster.Name + ".eks.fake"
+	return f.err
+}
+
+func TestCollectProfiles(t *testing.T) {
+	lister := &fakeProfileLister{
+		accounts: []Account{
+			{AccountID: "111111111111", AccountName: "dev"},
+			{AccountID: "222222222222", AccountName: "prod"},
+			{AccountID: "333333333333", AccountName: "broken"},
+		},
+		roles: map[string][]Role{
+			"111111111111": {{RoleName: "AdministratorAccess"}, {RoleName: "ReadOnlyAccess"}},
+			"222222222222": {{RoleName: "ReadOnlyAccess"}},
+		},
+		rolesErr: map[string]error{"333333333333": errors.New("access denied")},
+	}
+
+	var statuses []string
+	profiles, err := CollectProfiles(context.Background(), lister, "token", func(message string) {
+		statuses = append(statuses, message)
+	})
+	require.NoError(t, err)
+
+	var names []string
+	for _, profile := range profiles {
+		names = append(names, profile.AccountName+"/"+profile.RoleName)
+	}
+	sort.Strings(names)
+	// Accounts whose roles can't be listed are skipped instead of failing the whole run
+	assert.Equal(t, []string{"dev/AdministratorAccess", "dev/ReadOnlyAccess", "prod/ReadOnlyAccess"}, names)
+	assert.Equal(t, "Listing accounts", statuses[0])
+
+	lister.accountsErr = errors.New("expired token")
+	_, err = CollectProfiles(context.Background(), lister, "token", func(string) {})
+	assert.ErrorContains(t, err, "expired token")
+}
+
+// slowProfileLister takes a while to list roles, recording how many calls overlap
+type slowProfileLister struct {
+	fakeProfileLister
+	delay    time.Duration
+	mu       sync.Mutex
+	inFlight int
+	overlap  int
+	calls    map[string]int
+}
+
+func (f *slowProfileLister) ListAccountRoles(ctx context.Context, accessToken, accountID string) ([]Role, error) {
+	f.mu.Lock()
+	f.inFlight++
+	f.overlap = max(f.overlap, f.inFlight)
+	f.calls[accountID]++
+	f.mu.Unlock()
+
+	time.Sleep(f.delay)
+
+	f.mu.Lock()
+	f.inFlight--
+	f.mu.Unlock()
+	return f.fakeProfileLister.ListAccountRoles(ctx, accessToken, accountID)
+}
+
+func TestCollectProfilesInParallel(t *testing.T) {
+	lister := &slowProfileLister{delay: 300 * time.Millisecond, calls: map[string]int{}}
+	lister.roles = map[string][]Role{}
+	lister.rolesErr = map[string]error{"000000000000": errors.New("access denied")}
+	for i := range 20 {
+		id := fmt.Sprintf("%012d", i)
+		lister.accounts = append(lister.accounts, Account{AccountID: id, AccountName: "account-" + id})
+		lister.roles[id] = []Role{{RoleName: "ReadOnlyAccess"}}
+	}
+
+	profiles, err := CollectProfiles(context.Background(), lister, "token", func(string) {})
+	require.NoError(t, err)
+	assert.Len(t, profiles, 19)
+	assert.Greater(t, lister.overlap, 1, "roles are listed for several accounts at once")
+	assert.Equal(t, 1, lister.calls["000000000000"], "failed accounts are not retried on top of the SDK retries")
+}
+
+func TestListClustersWith(t *testing.T) {
+	clusters, err := ListClustersWith(context.Background(), &fakeClusterLister{names: []string{"a", "b"}}, "dev", "111111111111", "us-west-2")
+	require.NoError(t, err)
+	require.Len(t, clusters, 2)
+	assert.Equal(t, EKSCluster{Name: "a", Region: "us-west-2", AccountID: "111111111111", Profile: "dev"}, clusters[0])
+
+	_, err = ListClustersWith(context.Background(), &fakeClusterLister{err: errors.New("throttled")}, "dev", "111111111111", "us-west-2")
+	assert.ErrorContains(t, err, "throttled")
+}
+
+func TestDescribeClusterWith(t *testing.T) {
+	cluster := EKSCluster{Name: "a", Region: "us-west-2", Profile: "dev"}
+	err := DescribeClusterWith(context.Background(), func(ctx context.Context, region, profile string) (ClusterLister, error) {
+		return &fakeClusterLister{}, nil
+	}, &cluster)
+	require.NoError(t, err)
+	assert.Equal(t, "https://a.eks.fake", cluster.Endpoint)
+
+	err = DescribeClusterWith(context.Background(), func(ctx context.Context, region, profile string) (ClusterLister, error) {
+		return nil, errors.New("no credentials")
+	}, &cluster)
+	assert.ErrorContains(t, err, "failed to create EKS client")
+}
+
+func TestResolveAccountRole(t *testing.T) {
+	lister := &fakeProfileLister{
+		accounts: []Account{{AccountID: "111111111111", AccountName: "dev", EmailAddress: "dev@example.com"}},
+		roles: map[string][]Role{
+			"111111111111": {{RoleName: "ReadOnlyAccess"}, {RoleName: "AdministratorAccess"}},
+		},
+	}
+
+	profile, err := ResolveAccountRole(context.Background(), lister, "token", "111111111111", "AdministratorAccess")
+	require.NoError(t, err)
+	assert.Equal(t, AWSProfile{AccountID: "111111111111", AccountName: "dev", RoleName: "AdministratorAccess", EmailAddress: "dev@example.com"}, *profile)
+
+	_, err = ResolveAccountRole(context.Background(), lister, "token", "111111111111", "Admin")
+	assert.EqualError(t, err, "role Admin not found in account dev (111111111111), available roles: AdministratorAccess, ReadOnlyAccess")
+
+	_, err = ResolveAccountRole(context.Background(), lister, "token", "999999999999", "Admin")
+	assert.ErrorContains(t, err, "account 999999999999 is not available")
+}
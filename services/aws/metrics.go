@@ -0,0 +1,136 @@
+package services_aws
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// APICallStat aggregates the AWS API calls made for one service operation
+type APICallStat struct {
+	Service   string
+	Operation string
+	Calls     int
+	// Attempts includes retries, so Attempts > Calls means requests were throttled or failed
+	Attempts int
+	Errors   int
+	Duration time.Duration
+}
+
+// APICallSummary totals every AWS API call made during the run
+type APICallSummary struct {
+	Calls    int
+	Attempts int
+	Errors   int
+	Duration time.Duration
+}
+
+// apiCallRecorder collects call statistics from the SDK middleware
+type apiCallRecorder struct {
+	mu    sync.Mutex
+	stats map[string]*APICallStat
+}
+
+var apiCalls = &apiCallRecorder{stats: map[string]*APICallStat{}}
+
+// stat returns the entry for an operation, creating it on first use; the caller holds the lock
+func (r *apiCallRecorder) stat(ctx context.Context) *APICallStat {
+	service, operation := awsmiddleware.GetServiceID(ctx), awsmiddleware.GetOperationName(ctx)
+	key := service + "/" + operation
+	stat, ok := r.stats[key]
+	if !ok {
+		stat = &APICallStat{Service: service, Operation: operation}
+		r.stats[key] = stat
+	}
+	return stat
+}
+
+// recordCall counts a complete call (retries included) and its duration
+func (r *apiCallRecorder) recordCall(ctx context.Context, duration time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stat := r.stat(ctx)
+	stat.Calls++
+	stat.Duration += duration
+	if err != nil {
+		stat.Errors++
+	}
+}
+
+// recordAttempt counts a single HTTP attempt
+func (r *apiCallRecorder) recordAttempt(ctx context.Context) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.stat(ctx).Attempts++
+}
+
+// recordAPICalls adds the instrumentation middleware to an SDK client stack
+// Presign stacks have no "Retry" step and send nothing, so they are left alone
+func recordAPICalls(stack *middleware.Stack) error {
+	if _, ok := stack.Finalize.Get("Retry"); !ok {
+		return nil
+	}
+
+	// Finalize middlewares before "Retry" run once per call, after service metadata is set
+	err := stack.Finalize.Insert(middleware.FinalizeMiddlewareFunc("ArkRecordAPICall",
+		func(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (middleware.FinalizeOutput, middleware.Metadata, error) {
+			start := time.Now()
+			out, metadata, err := next.HandleFinalize(ctx, in)
+			apiCalls.recordCall(ctx, time.Since(start), err)
+			return out, metadata, err
+		}), "Retry", middleware.Before)
+	if err != nil {
+		return err
+	}
+
+	// and those after it once per attempt
+	return stack.Finalize.Insert(middleware.FinalizeMiddlewareFunc("ArkRecordAPIAttempt",
+		func(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (middleware.FinalizeOutput, middleware.Metadata, error) {
+			apiCalls.recordAttempt(ctx)
+			return next.HandleFinalize(ctx, in)
+		}), "Retry", middleware.After)
+}
+
+// APICallStats returns the calls made so far, sorted by service and operation
+func APICallStats() []APICallStat {
+	apiCalls.mu.Lock()
+	defer apiCalls.mu.Unlock()
+
+	stats := make([]APICallStat, 0, len(apiCalls.stats))
+	for _, stat := range apiCalls.stats {
+		stats = append(stats, *stat)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Service != stats[j].Service {
+			return stats[i].Service < stats[j].Service
+		}
+		return stats[i].Operation < stats[j].Operation
+	})
+	return stats
+}
+
+// SummarizeAPICalls totals the calls made so far
+func SummarizeAPICalls() APICallSummary {
+	var summary APICallSummary
+	for _, stat := range APICallStats() {
+		summary.Calls += stat.Calls
+		summary.Attempts += stat.Attempts
+		summary.Errors += stat.Errors
+		summary.Duration += stat.Duration
+	}
+	return summary
+}
+
+// ResetAPICallStats clears the recorded calls
+func ResetAPICallStats() {
+	apiCalls.mu.Lock()
+	defer apiCalls.mu.Unlock()
+
+	apiCalls.stats = map[string]*APICallStat{}
+}
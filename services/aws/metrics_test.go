@@ -0,0 +1,87 @@
+package services_aws
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const callerIdentityResponse = `<GetCallerIdentityResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <GetCallerIdentityResult>
+    <Arn>arn:aws:iam::123456789012:user/alice</Arn>
+    <UserId>AIDEXAMPLE</UserId>
+    <Account>123456789012</Account>
+  </GetCallerIdentityResult>
+  <ResponseMetadata><RequestId>1</RequestId></ResponseMetadata>
+</GetCallerIdentityResponse>`
+
+func TestRecordAPICalls(t *testing.T) {
+	ResetAPICallStats()
+	t.Cleanup(ResetAPICallStats)
+
+	// The first request fails with a retryable error, the second succeeds
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "text/xml")
+		_, _ = w.Write([]byte(callerIdentityResponse))
+	}))
+	defer server.Close()
+
+	client := sts.New(sts.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String(server.URL),
+		Credentials:  aws.AnonymousCredentials{},
+		APIOptions:   []func(*middleware.Stack) error{recordAPICalls},
+		Retryer: retry.NewStandard(func(o *retry.StandardOptions) {
+			o.Backoff = retry.BackoffDelayerFunc(func(int, error) (time.Duration, error) { return 0, nil })
+		}),
+	})
+
+	_, err := client.GetCallerIdentity(context.Background(), &sts.GetCallerIdentityInput{})
+	require.NoError(t, err)
+
+	stats := APICallStats()
+	require.Len(t, stats, 1)
+	assert.Equal(t, "STS", stats[0].Service)
+	assert.Equal(t, "GetCallerIdentity", stats[0].Operation)
+	assert.Equal(t, 1, stats[0].Calls)
+	assert.Equal(t, 2, stats[0].Attempts)
+	assert.Equal(t, 0, stats[0].Errors)
+	assert.Positive(t, stats[0].Duration)
+
+	summary := SummarizeAPICalls()
+	assert.Equal(t, 1, summary.Calls)
+	assert.Equal(t, 2, summary.Attempts)
+}
+
+func TestAPICallStatsSorted(t *testing.T) {
+	ResetAPICallStats()
+	t.Cleanup(ResetAPICallStats)
+
+	apiCalls.recordCall(awsmiddleware.SetServiceID(context.Background(), "SSO"), time.Second, nil)
+	apiCalls.recordCall(awsmiddleware.SetServiceID(context.Background(), "EKS"), time.Second, assert.AnError)
+
+	stats := APICallStats()
+	require.Len(t, stats, 2)
+	assert.Equal(t, "EKS", stats[0].Service)
+	assert.Equal(t, 1, stats[0].Errors)
+
+	summary := SummarizeAPICalls()
+	assert.Equal(t, 2, summary.Calls)
+	assert.Equal(t, 2*time.Second, summary.Duration)
+}
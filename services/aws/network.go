@@ -0,0 +1,193 @@
+package services_aws
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	ark_config "github.com/andresgarcia29/ark-cli/config"
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// NetworkEndpoint is an AWS API endpoint ark calls, checked by `ark doctor network`
+type NetworkEndpoint struct {
+	// Service is the key of the service in aws.endpoints, e.g. sso_oidc
+	Service string
+	Region  string
+	URL     string
+}
+
+// NetworkCheck is the outcome of reaching a NetworkEndpoint
+type NetworkCheck struct {
+	NetworkEndpoint
+	// Proxy is the proxy the request went through, with any password hidden; empty when it went direct
+	Proxy string
+	// DNS is how long resolving the host took; DNSErr is set when it failed
+	DNS    time.Duration
+	DNSErr error
+	// TLS is how long the TLS handshake took
+	TLS time.Duration
+	// Status is the HTTP status the endpoint answered with; any status means it is reachable
+	Status int
+	// Err is set when no HTTP response came back
+	Err error
+}
+
+// OK reports whether the endpoint answered
+func (c NetworkCheck) OK() bool {
+	return c.Err == nil
+}
+
+// regionalEndpoint returns the https URL of a regional endpoint, e.g. https://sts.eu-west-1.amazonaws.com
+func regionalEndpoint(prefix, region string) string {
+	if strings.HasPrefix(region, "cn-") {
+		return "https://" + prefix + "." + region + ".amazonaws.com.cn"
+	}
+	return "https://" + prefix + "." + region + ".amazonaws.com"
+}
+
+// NetworkEndpoints lists the endpoints a login and a cluster scan call: SSO OIDC and the SSO portal in
+// ssoRegions, STS and EKS in regions. Endpoints overridden in aws.endpoints are listed once, as configured
+func NetworkEndpoints(ssoRegions, regions []string) []NetworkEndpoint {
+	settings := ark_config.Get().AWS
+	var endpoints []NetworkEndpoint
+	add := func(service, prefix string, regions []string) {
+		if override := settings.Endpoint(service); override != "" {
+			endpoints = append(endpoints, NetworkEndpoint{Service: service, URL: override})
+			return
+		}
+		for _, region := range regions {
+			endpoints = append(endpoints, NetworkEndpoint{Service: service, Region: region, URL: regionalEndpoint(prefix, region)})
+		}
+	}
+	add(serviceSSOOIDC, "oidc", ssoRegions)
+	add(serviceSSO, "portal.sso", ssoRegions)
+	add(serviceSTS, "sts", regions)
+	add(serviceEKS, "eks", regions)
+	return endpoints
+}
+
+// ConfiguredRegions returns the SSO regions and the regions of the profiles in ~/.aws/config, sorted
+// A machine without an AWS config has none, which is no reason to skip checking the network
+func ConfiguredRegions() (ssoRegions, regions []string, err error) {
+	profiles, err := ReadAllProfilesFromConfig()
+	if errors.Is(err, ErrNoAWSConfig) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, profile := range profiles {
+		if profile.SSORegion != "" {
+			ssoRegions = append(ssoRegions, profile.SSORegion)
+		}
+		if profile.Region != "" {
+			regions = append(regions, profile.Region)
+		}
+	}
+	slices.Sort(ssoRegions)
+	slices.Sort(regions)
+	return slices.Compact(ssoRegions), slices.Compact(regions), nil
+}
+
+// NetworkChecker resolves and connects to endpoints the way ark's AWS clients do: through aws.proxy or
+// HTTPS_PROXY, with aws.ca_bundle, so a failure here is the failure a login would hit
+type NetworkChecker struct {
+	client   aws.HTTPClient
+	resolver *net.Resolver
+	proxy    func(*http.Request) (*url.URL, error)
+	timeout  time.Duration
+}
+
+// NewNetworkChecker creates a checker giving each endpoint up to timeout to answer
+func NewNetworkChecker(ctx context.Context, timeout time.Duration) (*NetworkChecker, error) {
+	cfg, err := NewAWSConfig(ctx, ClientConfig{Anonymous: true})
+	if err != nil {
+		return nil, fmt.Errorf("unable to load SDK config: %w", err)
+	}
+
+	proxy := http.ProxyFromEnvironment
+	if setting := ark_config.Get().AWS.Proxy; setting != "" {
+		proxyURL, err := url.Parse(setting)
+		if err != nil {
+			return nil, fmt.Errorf("invalid aws.proxy %q: %w", setting, err)
+		}
+		proxy = http.ProxyURL(proxyURL)
+	}
+
+	return &NetworkChecker{
+		client:   cfg.HTTPClient,
+		resolver: net.DefaultResolver,
+		proxy:    proxy,
+		timeout:  timeout,
+	}, nil
+}
+
+// ProxyFor returns the proxy requests to endpoint go through, with any password hidden, or "" when they go direct
+func (c *NetworkChecker) ProxyFor(endpoint string) string {
+	request, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return ""
+	}
+	proxyURL, err := c.proxy(request)
+	if err != nil || proxyURL == nil {
+		return ""
+	}
+	return proxyURL.Redacted()
+}
+
+// Check resolves the host of endpoint, then sends it an unsigned request and times the TLS handshake
+// A DNS failure doesn't stop the request when a proxy is used, since the proxy resolves the host
+func (c *NetworkChecker) Check(ctx context.Context, endpoint NetworkEndpoint) NetworkCheck {
+	result := NetworkCheck{NetworkEndpoint: endpoint, Proxy: c.ProxyFor(endpoint.URL)}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	parsed, err := url.Parse(endpoint.URL)
+	if err != nil || parsed.Hostname() == "" {
+		result.Err = fmt.Errorf("invalid endpoint %q", endpoint.URL)
+		return result
+	}
+
+	started := time.Now()
+	_, result.DNSErr = c.resolver.LookupHost(ctx, parsed.Hostname())
+	result.DNS = time.Since(started)
+	if result.DNSErr != nil && result.Proxy == "" {
+		result.Err = fmt.Errorf("failed to resolve %s: %w", parsed.Hostname(), result.DNSErr)
+		return result
+	}
+
+	var handshakeStarted time.Time
+	trace := &httptrace.ClientTrace{
+		TLSHandshakeStart: func() { handshakeStarted = time.Now() },
+		TLSHandshakeDone: func(_ tls.ConnectionState, err error) {
+			if err == nil {
+				result.TLS = time.Since(handshakeStarted)
+			}
+		},
+	}
+	request, err := http.NewRequestWithContext(httptrace.WithClientTrace(ctx, trace), http.MethodGet, endpoint.URL, nil)
+	if err != nil {
+		result.Err = fmt.Errorf("invalid endpoint %q: %w", endpoint.URL, err)
+		return result
+	}
+	response, err := c.client.Do(request)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	_, _ = io.Copy(io.Discard, response.Body)
+	response.Body.Close()
+	result.Status = response.StatusCode
+	return result
+}
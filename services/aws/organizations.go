@@ -0,0 +1,175 @@
+package services_aws
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/andresgarcia29/ark-cli/lib"
+	"github.com/andresgarcia29/ark-cli/logs"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
+)
+
+// organizationsRegion is where the global AWS Organizations API is served
+const organizationsRegion = "us-east-1"
+
+// AccountTags maps account IDs to the tags of the account in AWS Organizations
+type AccountTags map[string]map[string]string
+
+// TagSource is the account and role whose credentials read the tags of the accounts, e.g. a read-only role
+// of the management account or of a delegated administrator
+type TagSource struct {
+	AccountID string
+	RoleName  string
+}
+
+// ParseTagSource parses an account and role written as <account-id>/<role>, e.g. 111111111111/OrgReadOnly
+func ParseTagSource(value string) (TagSource, error) {
+	accountID, roleName, ok := strings.Cut(value, "/")
+	if !ok || accountID == "" || roleName == "" {
+		return TagSource{}, fmt.Errorf("invalid tag source %q (expected <account-id>/<role>, e.g. 111111111111/OrgReadOnly)", value)
+	}
+	return TagSource{AccountID: accountID, RoleName: roleName}, nil
+}
+
+// AccountTagLister lists the tags of an account
+type AccountTagLister interface {
+	ListAccountTags(ctx context.Context, accountID string) (map[string]string, error)
+}
+
+// OrganizationsClient reads account metadata from AWS Organizations
+type OrganizationsClient struct {
+	client *organizations.Client
+}
+
+// NewOrganizationsClient creates an Organizations client signing with temporary role credentials
+func NewOrganizationsClient(ctx context.Context, creds *Credentials) (*OrganizationsClient, error) {
+	cfg, err := NewAWSConfig(ctx, ClientConfig{Region: organizationsRegion})
+	if err != nil {
+		return nil, fmt.Errorf("unable to load SDK config: %w", err)
+	}
+	cfg.Credentials = aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+		return aws.Credentials{
+			AccessKeyID:     creds.AccessKeyID,
+			SecretAccessKey: creds.SecretAccessKey,
+			SessionToken:    creds.SessionToken,
+		}, nil
+	})
+	return &OrganizationsClient{client: newOrganizationsClient(cfg)}, nil
+}
+
+// ListAccountTags lists the tags of an account
+func (c *OrganizationsClient) ListAccountTags(ctx context.Context, accountID string) (map[string]string, error) {
+	tags := make(map[string]string)
+	var nextToken *string
+	for {
+		output, err := c.client.ListTagsForResource(ctx, &organizations.ListTagsForResourceInput{
+			ResourceId: aws.String(accountID),
+			NextToken:  nextToken,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tags of account %s: %w", accountID, err)
+		}
+		for _, tag := range output.Tags {
+			tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+		}
+		if output.NextToken == nil {
+			return tags, nil
+		}
+		nextToken = output.NextToken
+	}
+}
+
+// CollectAccountTags reads the tags of every account in parallel
+// Accounts whose tags can't be read are left out; it only fails when no account could be read
+func CollectAccountTags(ctx context.Context, lister AccountTagLister, accountIDs []string) (AccountTags, error) {
+	logger := logs.GetLogger()
+
+	results, errors := lib.ProcessAccountsInParallel(ctx, accountIDs, lib.ServiceConfig(lib.ServiceOrganizations),
+		func(ctx context.Context, accountID string) (map[string]string, error) {
+			return lister.ListAccountTags(ctx, accountID)
+		},
+	)
+	if len(errors) > 0 {
+		if len(results) == 0 {
+			return nil, errors[0]
+		}
+		logger.Warnw("Some accounts had errors reading tags", "error_count", len(errors))
+		for _, err := range errors {
+			logger.Warnf("  - %v", err)
+		}
+	}
+	return AccountTags(results), nil
+}
+
+// ApplyAccountTags sets the tags of every profile whose account tags were read
+// Those accounts get a non-nil set, even without tags, so writing the profile clears stale tags
+func ApplyAccountTags(profiles []AWSProfile, tags AccountTags) []AWSProfile {
+	tagged := make([]AWSProfile, 0, len(profiles))
+	for _, profile := range profiles {
+		if accountTags, ok := tags[profile.AccountID]; ok {
+			profile.Tags = maps.Clone(accountTags)
+			if profile.Tags == nil {
+				profile.Tags = map[string]string{}
+			}
+		}
+		tagged = append(tagged, profile)
+	}
+	return tagged
+}
+
+// ParseTagFilter parses key=value tag filters; values are path.Match globs compared without case
+func ParseTagFilter(filters []string) (map[string]string, error) {
+	if len(filters) == 0 {
+		return nil, nil
+	}
+	parsed := make(map[string]string, len(filters))
+	for _, filter := range filters {
+		key, value, ok := strings.Cut(filter, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid tag filter %q (expected key=value, e.g. env=prod)", filter)
+		}
+		if _, err := path.Match(value, ""); err != nil {
+			return nil, fmt.Errorf("invalid tag filter %q: %w", filter, err)
+		}
+		parsed[key] = value
+	}
+	return parsed, nil
+}
+
+// MatchesTags reports whether tags has every key of filter with a value matching its glob
+func MatchesTags(tags, filter map[string]string) bool {
+	for key, pattern := range filter {
+		value, ok := tags[key]
+		if !ok || !matchesAnyGlobFold(value, []string{pattern}) {
+			return false
+		}
+	}
+	return true
+}
+
+// FormatTags writes tags as key=value pairs sorted by key, e.g. "env=prod, team=payments"
+// Tag keys and values can't contain commas, so the list can be split again
+func FormatTags(tags map[string]string) string {
+	pairs := make([]string, 0, len(tags))
+	for _, key := range slices.Sorted(maps.Keys(tags)) {
+		pairs = append(pairs, key+"="+tags[key])
+	}
+	return strings.Join(pairs, ", ")
+}
+
+// parseTags reads tags written by FormatTags
+func parseTags(text string) map[string]string {
+	tags := make(map[string]string)
+	for _, pair := range strings.Split(text, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if ok && key != "" {
+			tags[key] = value
+		}
+	}
+	return tags
+}
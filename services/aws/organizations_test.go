@@ -0,0 +1,94 @@
+package services_aws
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTagLister returns the tags of known accounts and fails for the others
+type fakeTagLister map[string]map[string]string
+
+func (f fakeTagLister) ListAccountTags(ctx context.Context, accountID string) (map[string]string, error) {
+	tags, ok := f[accountID]
+	if !ok {
+		return nil, errors.New("AccessDenied")
+	}
+	return tags, nil
+}
+
+func TestParseTagSource(t *testing.T) {
+	source, err := ParseTagSource("111111111111/OrgReadOnly")
+	require.NoError(t, err)
+	assert.Equal(t, TagSource{AccountID: "111111111111", RoleName: "OrgReadOnly"}, source)
+
+	for _, value := range []string{"111111111111", "/OrgReadOnly", "111111111111/"} {
+		_, err := ParseTagSource(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestCollectAccountTags(t *testing.T) {
+	lister := fakeTagLister{
+		"111111111111": {"env": "prod", "team": "payments"},
+		"222222222222": {},
+	}
+
+	tags, err := CollectAccountTags(context.Background(), lister, []string{"111111111111", "222222222222", "333333333333"})
+	require.NoError(t, err)
+	assert.Equal(t, AccountTags{
+		"111111111111": {"env": "prod", "team": "payments"},
+		"222222222222": {},
+	}, tags, "accounts whose tags can't be read are left out")
+
+	_, err = CollectAccountTags(context.Background(), lister, []string{"333333333333"})
+	assert.ErrorContains(t, err, "AccessDenied")
+}
+
+func TestApplyAccountTags(t *testing.T) {
+	profiles := ApplyAccountTags([]AWSProfile{
+		{AccountID: "111111111111", RoleName: "ReadOnly"},
+		{AccountID: "222222222222", RoleName: "ReadOnly"},
+		{AccountID: "333333333333", RoleName: "ReadOnly"},
+	}, AccountTags{
+		"111111111111": {"env": "prod"},
+		"222222222222": nil,
+	})
+
+	assert.Equal(t, map[string]string{"env": "prod"}, profiles[0].Tags)
+	assert.NotNil(t, profiles[1].Tags, "accounts read without tags get an empty set")
+	assert.Empty(t, profiles[1].Tags)
+	assert.Nil(t, profiles[2].Tags, "accounts that weren't read keep no tags")
+}
+
+func TestParseTagFilter(t *testing.T) {
+	filter, err := ParseTagFilter([]string{"env=prod*", "team=payments"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"env": "prod*", "team": "payments"}, filter)
+
+	_, err = ParseTagFilter([]string{"env"})
+	assert.ErrorContains(t, err, "expected key=value")
+	_, err = ParseTagFilter([]string{"env=[prod"})
+	assert.Error(t, err)
+}
+
+func TestMatchesTags(t *testing.T) {
+	tags := map[string]string{"env": "Production", "team": "payments"}
+
+	assert.True(t, MatchesTags(tags, nil))
+	assert.True(t, MatchesTags(tags, map[string]string{"env": "prod*"}), "values are globs compared without case")
+	assert.True(t, MatchesTags(tags, map[string]string{"env": "production", "team": "pay*"}))
+	assert.False(t, MatchesTags(tags, map[string]string{"env": "staging"}))
+	assert.False(t, MatchesTags(tags, map[string]string{"owner": "*"}), "a missing key never matches")
+	assert.False(t, MatchesTags(nil, map[string]string{"env": "*"}))
+}
+
+func TestFormatTags(t *testing.T) {
+	tags := map[string]string{"team": "payments", "env": "prod", "cost-center": "CC 42"}
+	assert.Equal(t, "cost-center=CC 42, env=prod, team=payments", FormatTags(tags))
+	assert.Equal(t, tags, parseTags(FormatTags(tags)))
+	assert.Empty(t, FormatTags(nil))
+}
@@ -29,6 +29,7 @@ func ProcessRegionsInParallel(
 	config lib.ParallelConfig,
 ) ([]EKSCluster, error) {
 	logger := logs.GetLogger()
+	config = config.WithOverrides()
 
 	// Create context with timeout
 	timeoutCtx, cancel := context.WithTimeout(ctx, config.Timeout)
@@ -108,7 +109,7 @@ func ProcessRegionsInParallel(
 
 	// Collect results
 	var allClusters []EKSCluster
-	var hasErrors bool
+	var firstErr error
 
 	for result := range resultChan {
 		if result.Error != nil {
@@ -116,18 +117,25 @@ func ProcessRegionsInParallel(
 				"region", result.Region,
 				"account_id", accountID,
 				"error", result.Error)
-			hasErrors = true
+			if firstErr == nil {
+				firstErr = result.Error
+			}
 		} else {
 			// Add all clusters from this region
 			allClusters = append(allClusters, result.Clusters...)
 		}
 	}
 
+	// Regions still waiting for a worker when the budget ran out send no result at all
+	if firstErr == nil && len(allClusters) == 0 && timeoutCtx.Err() != nil {
+		firstErr = timeoutCtx.Err()
+	}
+
 	// If all regions failed, return error
-	if hasErrors && len(allClusters) == 0 {
+	if firstErr != nil && len(allClusters) == 0 {
 		logger.Errorw("All regions failed",
 			"account_id", accountID)
-		return nil, fmt.Errorf("all regions failed for account %s", accountID)
+		return nil, fmt.Errorf("all regions failed for account %s: %w", accountID, lib.ExplainDeadline(ctx, config, firstErr))
 	}
 
 	logger.Infow("Region scan completed",
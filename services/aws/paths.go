@@ -0,0 +1,86 @@
+package services_aws
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Environment variables shared with the AWS CLI and SDKs to relocate the config and credentials files
+const (
+	ConfigFileEnv      = "AWS_CONFIG_FILE"
+	CredentialsFileEnv = "AWS_SHARED_CREDENTIALS_FILE"
+)
+
+// Profile snippets dropped in ark.d/*.conf, next to the AWS config file, are merged into it
+const (
+	configIncludeDirName = "ark.d"
+	configIncludeExt     = ".conf"
+)
+
+// ConfigFilePath returns the AWS config file: $AWS_CONFIG_FILE when set, ~/.aws/config otherwise
+func ConfigFilePath() (string, error) {
+	return awsFilePath(ConfigFileEnv, "config")
+}
+
+// CredentialsFilePath returns the AWS credentials file: $AWS_SHARED_CREDENTIALS_FILE when set, ~/.aws/credentials otherwise
+func CredentialsFilePath() (string, error) {
+	return awsFilePath(CredentialsFileEnv, "credentials")
+}
+
+// customConfigFilePath returns ark's custom_config, which lives next to the AWS config file
+func customConfigFilePath() (string, error) {
+	configPath, err := ConfigFilePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(configPath), "custom_config"), nil
+}
+
+// configIncludeDir returns ark.d, the directory next to the AWS config file where *.conf snippets are merged from
+func configIncludeDir() (string, error) {
+	configPath, err := ConfigFilePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(configPath), configIncludeDirName), nil
+}
+
+// profileConfigPaths returns the AWS config file and the files layered over it, lowest priority first:
+// ark.d/*.conf in lexical order, then custom_config, so personal profiles win over managed snippets
+func profileConfigPaths() (string, []string, error) {
+	configPath, err := ConfigFilePath()
+	if err != nil {
+		return "", nil, err
+	}
+	includeDir, err := configIncludeDir()
+	if err != nil {
+		return "", nil, err
+	}
+	customConfigPath, err := customConfigFilePath()
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Glob only fails on a malformed pattern, and returns the matches sorted
+	overlays, _ := filepath.Glob(filepath.Join(includeDir, "*"+configIncludeExt))
+	return configPath, append(overlays, customConfigPath), nil
+}
+
+// awsFilePath resolves a file under ~/.aws that env can relocate, expanding a leading ~ like the AWS CLI
+func awsFilePath(env, name string) (string, error) {
+	path := os.Getenv(env)
+	if path != "" && path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	if path == "" {
+		return filepath.Join(homeDir, ".aws", name), nil
+	}
+	return filepath.Join(homeDir, strings.TrimPrefix(path, "~")), nil
+}
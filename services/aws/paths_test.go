@@ -0,0 +1,92 @@
+package services_aws
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAWSFilePaths(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	tests := []struct {
+		name            string
+		configEnv       string
+		credentialsEnv  string
+		wantConfig      string
+		wantCredentials string
+		wantCustom      string
+	}{
+		{
+			name:            "defaults under ~/.aws",
+			wantConfig:      filepath.Join(home, ".aws", "config"),
+			wantCredentials: filepath.Join(home, ".aws", "credentials"),
+			wantCustom:      filepath.Join(home, ".aws", "custom_config"),
+		},
+		{
+			name:            "absolute overrides",
+			configEnv:       "/etc/aws/work-config",
+			credentialsEnv:  "/etc/aws/work-credentials",
+			wantConfig:      "/etc/aws/work-config",
+			wantCredentials: "/etc/aws/work-credentials",
+			wantCustom:      "/etc/aws/custom_config",
+		},
+		{
+			name:            "tilde is expanded",
+			configEnv:       "~/work/aws-config",
+			credentialsEnv:  "~/work/aws-credentials",
+			wantConfig:      filepath.Join(home, "work", "aws-config"),
+			wantCredentials: filepath.Join(home, "work", "aws-credentials"),
+			wantCustom:      filepath.Join(home, "work", "custom_config"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(ConfigFileEnv, tt.configEnv)
+			t.Setenv(CredentialsFileEnv, tt.credentialsEnv)
+
+			configPath, err := ConfigFilePath()
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantConfig, configPath)
+
+			credentialsPath, err := CredentialsFilePath()
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantCredentials, credentialsPath)
+
+			customPath, err := customConfigFilePath()
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantCustom, customPath)
+		})
+	}
+}
+
+func TestAWSFileOverridesAreHonored(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	dir := filepath.Join(t.TempDir(), "split")
+	t.Setenv(ConfigFileEnv, filepath.Join(dir, "config"))
+	t.Setenv(CredentialsFileEnv, filepath.Join(dir, "credentials"))
+
+	client := &SSOClient{Region: "us-east-1", StartURL: "https://example.awsapps.com/start"}
+	require.NoError(t, client.WriteConfigFile([]AWSProfile{{AccountID: "111111111111", AccountName: "dev", RoleName: "ReadOnlyAccess"}}))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "custom_config"), []byte("[profile custom]\nrole_arn = arn:aws:iam::111111111111:role/custom\nsource_profile = dev-readonly\n"), 0600))
+
+	profiles, err := ReadAllProfilesFromConfig()
+	require.NoError(t, err)
+	assert.Len(t, profiles, 2)
+
+	profile, err := ReadProfileFromConfig("custom")
+	require.NoError(t, err)
+	assert.Equal(t, ProfileTypeAssumeRole, profile.ProfileType)
+
+	require.NoError(t, WriteCredentialsFile("dev-readonly", &Credentials{AccessKeyID: "AKIA"}, false))
+	assert.FileExists(t, filepath.Join(dir, "credentials"))
+
+	// Nothing was written to the default location
+	assert.NoDirExists(t, filepath.Join(home, ".aws"))
+}
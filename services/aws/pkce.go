@@ -0,0 +1,225 @@
+package services_aws
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/andresgarcia29/ark-cli/logs"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssooidc"
+)
+
+const (
+	// authCodeCallbackPath is where the browser is redirected to on the loopback listener
+	authCodeCallbackPath = "/oauth/callback"
+	// authCodeRedirectURI is registered for authorization code clients; the listener's port is added when
+	// signing in, which loopback redirect URIs allow (RFC 8252, section 7.3)
+	authCodeRedirectURI = "http://127.0.0.1" + authCodeCallbackPath
+	// ssoAccessScope lets the token list the accounts and roles of the portal and get their credentials
+	ssoAccessScope = "sso:account:access"
+)
+
+// ErrAuthorizationDenied is returned by AuthCodeListener.Wait when the browser comes back with an error,
+// e.g. the user denied the access request
+var ErrAuthorizationDenied = errors.New("the authorization request was denied")
+
+// RegisterAuthCodeClient registers a client for the authorization code flow with PKCE, tied to the start URL
+func (s *SSOClient) RegisterAuthCodeClient(ctx context.Context) (*ClientRegistration, error) {
+	logger := logs.GetLogger()
+	logger.Debugw("Registering authorization code client with AWS SSO", "start_url", s.StartURL)
+
+	output, err := s.oidcClient.RegisterClient(ctx, &ssooidc.RegisterClientInput{
+		ClientName:   aws.String("ark"),
+		ClientType:   aws.String("public"),
+		GrantTypes:   []string{"authorization_code", "refresh_token"},
+		RedirectUris: []string{authCodeRedirectURI},
+		IssuerUrl:    aws.String(s.StartURL),
+		Scopes:       []string{ssoAccessScope},
+	})
+	if err != nil {
+		logger.Errorw("Failed to register authorization code client", "error", err)
+		return nil, fmt.Errorf("failed to register client: %w", err)
+	}
+
+	registration := &ClientRegistration{
+		ClientID:              aws.ToString(output.ClientId),
+		ClientSecret:          aws.ToString(output.ClientSecret),
+		ExpiresAt:             output.ClientSecretExpiresAt,
+		AuthorizationEndpoint: aws.ToString(output.AuthorizationEndpoint),
+	}
+	logger.Debugw("Authorization code client registered", "client_id", registration.ClientID, "expires_at", registration.ExpiresAt)
+	return registration, nil
+}
+
+// SaveAuthCodeClientRegistration caches the authorization code client of the portal, so the next logins reuse it
+func (s *SSOClient) SaveAuthCodeClientRegistration(registration *ClientRegistration) error {
+	return saveClientRegistration(authCodeClientRegistrationFileName(s.StartURL, s.Region), registration)
+}
+
+// ReadAuthCodeClientRegistration returns the authorization code client cached for the portal, expired or not
+func (s *SSOClient) ReadAuthCodeClientRegistration() (*ClientRegistration, error) {
+	return readClientRegistration(authCodeClientRegistrationFileName(s.StartURL, s.Region))
+}
+
+// AuthorizationURL returns the page the browser signs in on; it redirects to redirectURI with the code and state
+func (s *SSOClient) AuthorizationURL(registration *ClientRegistration, redirectURI, state, codeChallenge string) string {
+	endpoint := registration.AuthorizationEndpoint
+	if endpoint == "" {
+		var baseEndpoint *string
+		overrideEndpoint(&baseEndpoint, serviceSSOOIDC)
+		base := aws.ToString(baseEndpoint)
+		if base == "" {
+			base = fmt.Sprintf("https://oidc.%s.amazonaws.com", s.Region)
+		}
+		endpoint = strings.TrimSuffix(base, "/") + "/authorize"
+	}
+
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {registration.ClientID},
+		"redirect_uri":          {redirectURI},
+		"state":                 {state},
+		"code_challenge_method": {"S256"},
+		"code_challenge":        {codeChallenge},
+		"scopes":                {ssoAccessScope},
+	}
+	return endpoint + "?" + query.Encode()
+}
+
+// CreateTokenWithAuthCode exchanges the code the browser was redirected with for a token
+func (s *SSOClient) CreateTokenWithAuthCode(ctx context.Context, clientID, clientSecret, code, codeVerifier, redirectURI string) (*TokenResponse, error) {
+	logger := logs.GetLogger()
+	output, err := s.oidcClient.CreateToken(ctx, &ssooidc.CreateTokenInput{
+		ClientId:     aws.String(clientID),
+		ClientSecret: aws.String(clientSecret),
+		GrantType:    aws.String("authorization_code"),
+		Code:         aws.String(code),
+		CodeVerifier: aws.String(codeVerifier),
+		RedirectUri:  aws.String(redirectURI),
+	})
+	if err != nil {
+		logger.Errorw("Failed to create token from authorization code", "error", err)
+		return nil, fmt.Errorf("failed to create token: %w", err)
+	}
+
+	token := &TokenResponse{
+		AccessToken:  aws.ToString(output.AccessToken),
+		ExpiresIn:    output.ExpiresIn,
+		TokenType:    aws.ToString(output.TokenType),
+		RefreshToken: aws.ToString(output.RefreshToken),
+	}
+	logger.Infow("Token created from authorization code", "expires_in", token.ExpiresIn)
+	return token, nil
+}
+
+// NewPKCEChallenge returns a random code verifier and its S256 code challenge (RFC 7636, section 4)
+func NewPKCEChallenge() (verifier, challenge string, err error) {
+	if verifier, err = randomURLToken(32); err != nil {
+		return "", "", err
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	return verifier, base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// randomURLToken returns n random bytes encoded for a URL
+func randomURLToken(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate random value: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// authCodeResult is what the browser was redirected with
+type authCodeResult struct {
+	code string
+	err  error
+}
+
+// AuthCodeListener receives the browser redirect of the authorization code flow on a loopback port
+type AuthCodeListener struct {
+	// State is sent with the authorization request and must come back with the code, so a redirect
+	// started by another page is refused
+	State    string
+	listener net.Listener
+	server   *http.Server
+	results  chan authCodeResult
+}
+
+// ListenForAuthCode starts the loopback listener on a free port of 127.0.0.1
+func ListenForAuthCode() (*AuthCodeListener, error) {
+	state, err := randomURLToken(16)
+	if err != nil {
+		return nil, err
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for the browser redirect: %w", err)
+	}
+
+	l := &AuthCodeListener{State: state, listener: listener, results: make(chan authCodeResult, 1)}
+	mux := http.NewServeMux()
+	mux.HandleFunc(authCodeCallbackPath, l.callback)
+	l.server = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go l.server.Serve(listener)
+	return l, nil
+}
+
+// RedirectURI is the URI the browser is redirected to, with the listener's port
+func (l *AuthCodeListener) RedirectURI() string {
+	return fmt.Sprintf("http://127.0.0.1:%d%s", l.listener.Addr().(*net.TCPAddr).Port, authCodeCallbackPath)
+}
+
+// Wait returns the code the browser was redirected with, or an error when the user denied access,
+// or when ctx ends first
+func (l *AuthCodeListener) Wait(ctx context.Context) (string, error) {
+	select {
+	case result := <-l.results:
+		return result.code, result.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// Close stops the listener
+func (l *AuthCodeListener) Close() error {
+	return l.server.Close()
+}
+
+// callback handles the browser redirect; redirects with another state are refused without ending the wait
+func (l *AuthCodeListener) callback(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("state") != l.State {
+		http.Error(w, "Unexpected authorization state, start the login again from ark.", http.StatusBadRequest)
+		return
+	}
+
+	result := authCodeResult{code: query.Get("code")}
+	switch {
+	case query.Get("error") != "":
+		result = authCodeResult{err: fmt.Errorf("%w: %s %s", ErrAuthorizationDenied, query.Get("error"), query.Get("error_description"))}
+	case result.code == "":
+		result = authCodeResult{err: fmt.Errorf("%w: the redirect has no code", ErrAuthorizationDenied)}
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if result.err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "<html><body><p>ark was not authorized. You can close this tab and return to the terminal.</p></body></html>")
+	} else {
+		fmt.Fprint(w, "<html><body><p>ark is signed in. You can close this tab and return to the terminal.</p></body></html>")
+	}
+	select {
+	case l.results <- result:
+	default:
+	}
+}
@@ -0,0 +1,120 @@
+package services_aws
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPKCEChallenge(t *testing.T) {
+	verifier, challenge, err := NewPKCEChallenge()
+	require.NoError(t, err)
+	// RFC 7636 requires 43 to 128 characters of the unreserved set
+	assert.Len(t, verifier, 43)
+	sum := sha256.Sum256([]byte(verifier))
+	assert.Equal(t, base64.RawURLEncoding.EncodeToString(sum[:]), challenge)
+
+	other, _, err := NewPKCEChallenge()
+	require.NoError(t, err)
+	assert.NotEqual(t, verifier, other)
+}
+
+func TestAuthorizationURL(t *testing.T) {
+	client := &SSOClient{Region: "eu-west-1", StartURL: "https://example.awsapps.com/start"}
+	registration := &ClientRegistration{ClientID: "client-id"}
+
+	parsed, err := url.Parse(client.AuthorizationURL(registration, "http://127.0.0.1:4242/oauth/callback", "state-1", "challenge-1"))
+	require.NoError(t, err)
+	assert.Equal(t, "oidc.eu-west-1.amazonaws.com", parsed.Host)
+	assert.Equal(t, "/authorize", parsed.Path)
+	assert.Equal(t, url.Values{
+		"response_type":         {"code"},
+		"client_id":             {"client-id"},
+		"redirect_uri":          {"http://127.0.0.1:4242/oauth/callback"},
+		"state":                 {"state-1"},
+		"code_challenge_method": {"S256"},
+		"code_challenge":        {"challenge-1"},
+		"scopes":                {"sso:account:access"},
+	}, parsed.Query())
+
+	// The endpoint returned by RegisterClient wins
+	registration.AuthorizationEndpoint = "https://oidc.gateway.internal/authorize"
+	parsed, err = url.Parse(client.AuthorizationURL(registration, "http://127.0.0.1:4242/oauth/callback", "state-1", "challenge-1"))
+	require.NoError(t, err)
+	assert.Equal(t, "oidc.gateway.internal", parsed.Host)
+}
+
+func TestAuthCodeListener(t *testing.T) {
+	listener, err := ListenForAuthCode()
+	require.NoError(t, err)
+	defer listener.Close()
+	redirect := listener.RedirectURI()
+	assert.Regexp(t, `^http://127\.0\.0\.1:\d+/oauth/callback$`, redirect)
+
+	get := func(values url.Values) int {
+		resp, err := http.Get(redirect + "?" + values.Encode())
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// A redirect with another state is refused and the wait goes on
+	assert.Equal(t, http.StatusBadRequest, get(url.Values{"code": {"forged"}, "state": {"other"}}))
+	assert.Equal(t, http.StatusOK, get(url.Values{"code": {"the-code"}, "state": {listener.State}}))
+
+	code, err := listener.Wait(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "the-code", code)
+}
+
+func TestAuthCodeListenerDenied(t *testing.T) {
+	listener, err := ListenForAuthCode()
+	require.NoError(t, err)
+	defer listener.Close()
+
+	resp, err := http.Get(listener.RedirectURI() + "?" + url.Values{"error": {"access_denied"}, "state": {listener.State}}.Encode())
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	_, err = listener.Wait(context.Background())
+	assert.ErrorIs(t, err, ErrAuthorizationDenied)
+	assert.Contains(t, err.Error(), "access_denied")
+
+	// Nothing else comes back: the wait ends with the context
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = listener.Wait(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestAuthCodeClientRegistrationCache(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	client := &SSOClient{Region: "us-east-1", StartURL: "https://example.awsapps.com/start"}
+	other := &SSOClient{Region: "us-east-1", StartURL: "https://other.awsapps.com/start"}
+
+	registration := &ClientRegistration{
+		ClientID:              "authcode-client",
+		ClientSecret:          "secret",
+		ExpiresAt:             time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC).Unix(),
+		AuthorizationEndpoint: "https://oidc.us-east-1.amazonaws.com/authorize",
+	}
+	require.NoError(t, client.SaveAuthCodeClientRegistration(registration))
+
+	read, err := client.ReadAuthCodeClientRegistration()
+	require.NoError(t, err)
+	assert.Equal(t, registration, read)
+
+	// Authorization code clients are registered for one start URL, and kept apart from the device code client
+	_, err = other.ReadAuthCodeClientRegistration()
+	assert.Error(t, err)
+	_, err = client.ReadClientRegistration()
+	assert.Error(t, err)
+}
@@ -0,0 +1,159 @@
+package services_aws
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+
+	ark_config "github.com/andresgarcia29/ark-cli/config"
+	"github.com/andresgarcia29/ark-cli/logs"
+	"github.com/andresgarcia29/ark-cli/services/aws/arn"
+)
+
+// maxPolicySize bounds how much of a remote policy is read
+const maxPolicySize = 1 << 20
+
+// Deprecation marks an account, a role name, or a role in one account as deprecated
+// Empty fields match anything, so at least one of AccountID and Role must be set
+type Deprecation struct {
+	AccountID string `yaml:"account_id"`
+	Role      string `yaml:"role"`
+	// Replacement is the profile (or account/role) to use instead
+	Replacement string `yaml:"replacement"`
+	// Reason explains the deprecation, e.g. "account is being migrated to the new org"
+	Reason string `yaml:"reason"`
+}
+
+// DeprecationPolicy is the team-published list of deprecated accounts and roles
+//
+//	deprecations:
+//	  - account_id: "111111111111"
+//	    role: AdministratorAccess
+//	    replacement: platform-admin
+//	    reason: AdministratorAccess is replaced by scoped admin roles
+type DeprecationPolicy struct {
+	Deprecations []Deprecation `yaml:"deprecations"`
+}
+
+var (
+	currentPolicy     *DeprecationPolicy
+	currentPolicyOnce sync.Once
+)
+
+// CurrentDeprecationPolicy loads the policy configured in aws.deprecation_policy once per process
+// It returns nil when no policy is configured or it cannot be loaded, which Lookup treats as empty
+func CurrentDeprecationPolicy(ctx context.Context) *DeprecationPolicy {
+	currentPolicyOnce.Do(func() {
+		source := ark_config.Get().AWS.DeprecationPolicy
+		if source == "" {
+			return
+		}
+		policy, err := LoadDeprecationPolicy(ctx, source)
+		if err != nil {
+			logs.GetLogger().Warnw("Failed to load deprecation policy", "source", source, "error", err)
+			return
+		}
+		currentPolicy = policy
+	})
+	return currentPolicy
+}
+
+// LoadDeprecationPolicy reads a policy from a local path or an http(s) URL
+func LoadDeprecationPolicy(ctx context.Context, source string) (*DeprecationPolicy, error) {
+	var data []byte
+	var err error
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		data, err = fetchPolicy(ctx, source)
+	} else {
+		data, err = os.ReadFile(source)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read deprecation policy: %w", err)
+	}
+	return parseDeprecationPolicy(data)
+}
+
+// fetchPolicy downloads a remote policy, bounded by the AWS timeout of ark's config
+func fetchPolicy(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{Timeout: ark_config.Get().AWS.Timeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxPolicySize))
+}
+
+// parseDeprecationPolicy decodes a policy, rejecting entries that would match every profile
+func parseDeprecationPolicy(data []byte) (*DeprecationPolicy, error) {
+	policy := &DeprecationPolicy{}
+	if err := yaml.Unmarshal(data, policy); err != nil {
+		return nil, fmt.Errorf("failed to parse deprecation policy: %w", err)
+	}
+	for i, deprecation := range policy.Deprecations {
+		if deprecation.AccountID == "" && deprecation.Role == "" {
+			return nil, fmt.Errorf("deprecation %d: account_id or role is required", i+1)
+		}
+	}
+	return policy, nil
+}
+
+// Lookup returns the first deprecation matching the profile's account and role
+func (p *DeprecationPolicy) Lookup(profile ProfileConfig) (Deprecation, bool) {
+	if p == nil {
+		return Deprecation{}, false
+	}
+
+	accountID, roleName := ProfileAccountAndRole(profile)
+	for _, deprecation := range p.Deprecations {
+		if deprecation.AccountID != "" && deprecation.AccountID != accountID {
+			continue
+		}
+		if deprecation.Role != "" && !strings.EqualFold(deprecation.Role, roleName) {
+			continue
+		}
+		return deprecation, true
+	}
+	return Deprecation{}, false
+}
+
+// Warning renders the message shown when a deprecated profile is used
+func (d Deprecation) Warning(profileName string) string {
+	message := fmt.Sprintf("profile %s is deprecated", profileName)
+	if d.Reason != "" {
+		message += ": " + d.Reason
+	}
+	if d.Replacement != "" {
+		message += fmt.Sprintf(" (use %s instead)", d.Replacement)
+	}
+	return message
+}
+
+// ProfileAccountAndRole returns the account and role a profile points to,
+// from sso_account_id/sso_role_name or from role_arn
+func ProfileAccountAndRole(profile ProfileConfig) (accountID, roleName string) {
+	if profile.RoleARN == "" {
+		return profile.AccountID, profile.RoleName
+	}
+
+	parsed, err := arn.Parse(profile.RoleARN)
+	if err != nil {
+		return "", ""
+	}
+	return parsed.AccountID, parsed.RoleName()
+}
@@ -0,0 +1,118 @@
+package services_aws
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPolicy = `deprecations:
+  - account_id: "111111111111"
+    role: AdministratorAccess
+    replacement: dev-platform-admin
+    reason: AdministratorAccess is replaced by scoped admin roles
+  - account_id: "999999999999"
+    replacement: new-org
+`
+
+func TestLoadDeprecationPolicy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(testPolicy), 0600))
+
+	policy, err := LoadDeprecationPolicy(context.Background(), path)
+	require.NoError(t, err)
+	assert.Len(t, policy.Deprecations, 2)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/policy.yaml" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(testPolicy))
+	}))
+	defer server.Close()
+
+	policy, err = LoadDeprecationPolicy(context.Background(), server.URL+"/policy.yaml")
+	require.NoError(t, err)
+	assert.Len(t, policy.Deprecations, 2)
+
+	_, err = LoadDeprecationPolicy(context.Background(), server.URL+"/missing.yaml")
+	assert.ErrorContains(t, err, "404")
+
+	_, err = LoadDeprecationPolicy(context.Background(), filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
+
+func TestParseDeprecationPolicyRejectsCatchAll(t *testing.T) {
+	_, err := parseDeprecationPolicy([]byte("deprecations:\n  - replacement: anything\n"))
+	assert.ErrorContains(t, err, "account_id or role is required")
+}
+
+func TestDeprecationPolicyLookup(t *testing.T) {
+	policy, err := parseDeprecationPolicy([]byte(testPolicy))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name            string
+		profile         ProfileConfig
+		wantReplacement string
+	}{
+		{
+			name:            "sso profile with deprecated role",
+			profile:         ProfileConfig{AccountID: "111111111111", RoleName: "administratoraccess"},
+			wantReplacement: "dev-platform-admin",
+		},
+		{
+			name:    "same account, other role",
+			profile: ProfileConfig{AccountID: "111111111111", RoleName: "ReadOnlyAccess"},
+		},
+		{
+			name:            "assume role profile in deprecated account",
+			profile:         ProfileConfig{RoleARN: "arn:aws:iam::999999999999:role/teams/Deploy"},
+			wantReplacement: "new-org",
+		},
+		{
+			name:    "unrelated account",
+			profile: ProfileConfig{AccountID: "222222222222", RoleName: "AdministratorAccess"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deprecation, ok := policy.Lookup(tt.profile)
+			assert.Equal(t, tt.wantReplacement != "", ok)
+			assert.Equal(t, tt.wantReplacement, deprecation.Replacement)
+		})
+	}
+
+	var nilPolicy *DeprecationPolicy
+	_, ok := nilPolicy.Lookup(ProfileConfig{AccountID: "111111111111"})
+	assert.False(t, ok)
+}
+
+func TestDeprecationWarning(t *testing.T) {
+	assert.Equal(t,
+		"profile dev-admin is deprecated: moving to scoped roles (use dev-platform-admin instead)",
+		Deprecation{Reason: "moving to scoped roles", Replacement: "dev-platform-admin"}.Warning("dev-admin"))
+	assert.Equal(t, "profile dev-admin is deprecated", Deprecation{}.Warning("dev-admin"))
+}
+
+func TestProfileAccountAndRole(t *testing.T) {
+	accountID, roleName := ProfileAccountAndRole(ProfileConfig{AccountID: "111111111111", RoleName: "ReadOnly"})
+	assert.Equal(t, "111111111111", accountID)
+	assert.Equal(t, "ReadOnly", roleName)
+
+	accountID, roleName = ProfileAccountAndRole(ProfileConfig{RoleARN: "arn:aws:iam::222222222222:role/path/to/Deploy"})
+	assert.Equal(t, "222222222222", accountID)
+	assert.Equal(t, "Deploy", roleName)
+
+	accountID, roleName = ProfileAccountAndRole(ProfileConfig{RoleARN: "not-an-arn"})
+	assert.Empty(t, accountID)
+	assert.Empty(t, roleName)
+}
@@ -0,0 +1,49 @@
+package services_aws
+
+import (
+	"sort"
+	"strings"
+
+	ark_config "github.com/andresgarcia29/ark-cli/config"
+)
+
+// LowerPrivilegeAlternative returns a read-only profile for the same account when selected uses an admin role
+// A role matching both kinds of patterns (e.g. AdminReadOnly) is not considered admin
+func LowerPrivilegeAlternative(selected ProfileConfig, profiles []ProfileConfig, settings ark_config.LeastPrivilegeConfig) (ProfileConfig, bool) {
+	if !settings.Enabled {
+		return ProfileConfig{}, false
+	}
+
+	accountID, roleName := ProfileAccountAndRole(selected)
+	if accountID == "" || !matchesAnyPattern(roleName, settings.AdminPatterns) || matchesAnyPattern(roleName, settings.ReadOnlyPatterns) {
+		return ProfileConfig{}, false
+	}
+
+	var candidates []ProfileConfig
+	for _, profile := range profiles {
+		candidateAccount, candidateRole := ProfileAccountAndRole(profile)
+		if candidateAccount == accountID && matchesAnyPattern(candidateRole, settings.ReadOnlyPatterns) {
+			candidates = append(candidates, profile)
+		}
+	}
+	if len(candidates) == 0 {
+		return ProfileConfig{}, false
+	}
+
+	// Profiles come from a map, so pick deterministically
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].ProfileName < candidates[j].ProfileName
+	})
+	return candidates[0], true
+}
+
+// matchesAnyPattern reports whether value contains one of patterns, ignoring case
+func matchesAnyPattern(value string, patterns []string) bool {
+	value = strings.ToLower(value)
+	for _, pattern := range patterns {
+		if pattern != "" && strings.Contains(value, strings.ToLower(pattern)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package services_aws
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	ark_config "github.com/andresgarcia29/ark-cli/config"
+)
+
+func TestLowerPrivilegeAlternative(t *testing.T) {
+	settings := ark_config.DefaultConfig().AWS.LeastPrivilege
+	profiles := []ProfileConfig{
+		{ProfileName: "dev-admin", AccountID: "111111111111", RoleName: "AdministratorAccess"},
+		{ProfileName: "dev-readonly", AccountID: "111111111111", RoleName: "ReadOnlyAccess"},
+		{ProfileName: "dev-adminreadonly", AccountID: "111111111111", RoleName: "AdminReadOnly"},
+		{ProfileName: "prod-admin", AccountID: "222222222222", RoleName: "AdministratorAccess"},
+		{ProfileName: "prod-viewer", RoleARN: "arn:aws:iam::333333333333:role/ViewOnlyAccess"},
+		{ProfileName: "prod-poweruser", RoleARN: "arn:aws:iam::333333333333:role/PowerUserAccess"},
+	}
+
+	tests := []struct {
+		name        string
+		selected    int
+		settings    ark_config.LeastPrivilegeConfig
+		alternative string
+	}{
+		{name: "admin role with read-only sibling", selected: 0, settings: settings, alternative: "dev-adminreadonly"},
+		{name: "read-only role", selected: 1, settings: settings},
+		{name: "admin read-only role is not admin", selected: 2, settings: settings},
+		{name: "no read-only role in the account", selected: 3, settings: settings},
+		{name: "assume-role profiles compare the ARN account", selected: 5, settings: settings, alternative: "prod-viewer"},
+		{name: "disabled", selected: 0, settings: ark_config.LeastPrivilegeConfig{Enabled: false, AdminPatterns: []string{"admin"}, ReadOnlyPatterns: []string{"readonly"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alternative, ok := LowerPrivilegeAlternative(profiles[tt.selected], profiles, tt.settings)
+			assert.Equal(t, tt.alternative != "", ok)
+			assert.Equal(t, tt.alternative, alternative.ProfileName)
+		})
+	}
+}
+
+func TestMatchesAnyPattern(t *testing.T) {
+	assert.True(t, matchesAnyPattern("PowerUserAccess", []string{"poweruser"}))
+	assert.True(t, matchesAnyPattern("read-only-eks", []string{"READ-ONLY"}))
+	assert.False(t, matchesAnyPattern("Developer", []string{"admin", ""}))
+	assert.False(t, matchesAnyPattern("Admin", nil))
+}
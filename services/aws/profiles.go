@@ -3,12 +3,15 @@ package services_aws
 import (
 	"context"
 	"fmt"
-	"time"
+	"os"
+	"slices"
+	"strings"
+	"sync/atomic"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
 
+	"github.com/andresgarcia29/ark-cli/audit"
 	"github.com/andresgarcia29/ark-cli/lib"
 	"github.com/andresgarcia29/ark-cli/logs"
 )
@@ -16,11 +19,22 @@ import (
 // GetAllProfiles gets all available account+role combinations
 // OPTIMIZED VERSION: Parallelizes role retrieval for multiple accounts
 func (s *SSOClient) GetAllProfiles(ctx context.Context, accessToken string) ([]AWSProfile, error) {
+	return s.GetAllProfilesWithStatus(ctx, accessToken, func(string) {})
+}
+
+// GetAllProfilesWithStatus is GetAllProfiles reporting what it is doing through status
+func (s *SSOClient) GetAllProfilesWithStatus(ctx context.Context, accessToken string, status func(message string)) ([]AWSProfile, error) {
+	return CollectProfiles(ctx, s, accessToken, status)
+}
+
+// CollectProfiles builds one profile per account+role combination reachable with accessToken
+func CollectProfiles(ctx context.Context, lister ProfileLister, accessToken string, status func(message string)) ([]AWSProfile, error) {
 	logger := logs.GetLogger()
 
 	// Step 1: Get all accounts (this must be sequential)
 	logger.Info("Getting account list")
-	accounts, err := s.ListAccounts(ctx, accessToken)
+	status("Listing accounts")
+	accounts, err := lister.ListAccounts(ctx, accessToken)
 	if err != nil {
 		return nil, fmt.Errorf("error getting accounts: %w", err)
 	}
@@ -28,8 +42,13 @@ func (s *SSOClient) GetAllProfiles(ctx context.Context, accessToken string) ([]A
 	logger.Infow("Accounts found, getting roles in parallel",
 		"total_accounts", len(accounts))
 
-	// Configuration for parallel operations
-	config := lib.ConservativeConfig()
+	// The SSO portal allows more calls per second than the conservative configuration makes,
+	// which kept organizations with 100+ accounts waiting most of a minute
+	config := lib.ServiceConfig(lib.ServiceSSO)
+	config.Phase = "fetching the roles of every account"
+
+	var processed int32
+	status(fmt.Sprintf("Fetching roles (0/%d accounts)", len(accounts)))
 
 	// Step 2: Use generic function to process accounts in parallel
 	// This function will execute ListAccountRoles for each account simultaneously
@@ -50,14 +69,16 @@ func (s *SSOClient) GetAllProfiles(ctx context.Context, accessToken string) ([]A
 
 			// This is where we make the actual call to the AWS SSO API
 			// This function can take several seconds, that's why we parallelize it
-			roles, err := s.ListAccountRoles(ctx, accessToken, accountID)
+			roles, err := lister.ListAccountRoles(ctx, accessToken, accountID)
 			if err != nil {
-				return nil, fmt.Errorf("error getting roles for account %s: %w", accountID, err)
+				// The SDK already retries throttling; access errors would only fail again
+				return nil, lib.Permanent(fmt.Errorf("error getting roles for account %s: %w", accountID, err))
 			}
 
 			logger.Infow("Roles obtained for account",
 				"account_id", accountID,
 				"roles_count", len(roles))
+			status(fmt.Sprintf("Fetching roles (%d/%d accounts)", atomic.AddInt32(&processed, 1), len(accounts)))
 			return roles, nil
 		},
 	)
@@ -108,6 +129,54 @@ func (s *SSOClient) GetAllProfiles(ctx context.Context, accessToken string) ([]A
 	return profiles, nil
 }
 
+// ResolveAccountRole finds an account and role reachable with accessToken, for logins without a profile
+// Both are checked against the SSO portal so a typo fails with the accounts or roles that are available
+func ResolveAccountRole(ctx context.Context, lister ProfileLister, accessToken, accountID, roleName string) (*AWSProfile, error) {
+	accounts, err := lister.ListAccounts(ctx, accessToken)
+	if err != nil {
+		return nil, fmt.Errorf("error getting accounts: %w", err)
+	}
+	index := slices.IndexFunc(accounts, func(account Account) bool { return account.AccountID == accountID })
+	if index < 0 {
+		return nil, fmt.Errorf("account %s is not available to this SSO user", accountID)
+	}
+	account := accounts[index]
+
+	roles, err := lister.ListAccountRoles(ctx, accessToken, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting roles for account %s: %w", accountID, err)
+	}
+	available := make([]string, 0, len(roles))
+	for _, role := range roles {
+		if role.RoleName == roleName {
+			return &AWSProfile{
+				AccountID:    account.AccountID,
+				AccountName:  account.AccountName,
+				RoleName:     role.RoleName,
+				EmailAddress: account.EmailAddress,
+			}, nil
+		}
+		available = append(available, role.RoleName)
+	}
+	slices.Sort(available)
+	return nil, fmt.Errorf("role %s not found in account %s (%s), available roles: %s",
+		roleName, account.AccountName, accountID, strings.Join(available, ", "))
+}
+
+// WriteAccountRoleCredentials writes the credentials of an account and role under profileName and records them in the audit log
+func WriteAccountRoleCredentials(profileName string, profile AWSProfile, creds *Credentials, setAsDefault bool) error {
+	if err := WriteCredentialsFile(profileName, creds, setAsDefault); err != nil {
+		return fmt.Errorf("failed to write credentials: %w", err)
+	}
+	recordIssuedCredentials(ProfileConfig{
+		ProfileName: profileName,
+		ProfileType: ProfileTypeSSO,
+		AccountID:   profile.AccountID,
+		RoleName:    profile.RoleName,
+	}, creds)
+	return nil
+}
+
 // LoginWithProfile performs complete login with a specific profile
 func LoginWithProfile(ctx context.Context, profileName string, setAsDefault bool) error {
 	logger := logs.GetLogger()
@@ -129,22 +198,15 @@ func LoginWithProfile(ctx context.Context, profileName string, setAsDefault bool
 	case ProfileTypeSSO:
 		logger.Info("Processing SSO profile")
 
-		// Read token from cache
-		cachedToken, err := ReadTokenFromCache(profileConfig.StartURL)
-		if err != nil {
-			return fmt.Errorf("failed to read token from cache (you may need to run login first): %w", err)
-		}
-
 		// Create SSO client
 		client, err := NewSSOClient(ctx, profileConfig.SSORegion, profileConfig.StartURL)
 		if err != nil {
 			return fmt.Errorf("failed to create SSO client: %w", err)
 		}
 
-		// Get temporary credentials
-		creds, err = client.GetRoleCredentials(ctx, cachedToken.AccessToken, profileConfig.AccountID, profileConfig.RoleName)
+		creds, err = SSORoleCredentials(ctx, client, profileConfig)
 		if err != nil {
-			return fmt.Errorf("failed to get role credentials: %w", err)
+			return err
 		}
 
 	case ProfileTypeAssumeRole:
@@ -164,6 +226,14 @@ func LoginWithProfile(ctx context.Context, profileName string, setAsDefault bool
 			return fmt.Errorf("failed to assume role: %w", err)
 		}
 
+	case ProfileTypeWebIdentity:
+		logger.Info("Processing web identity profile")
+
+		creds, err = AssumeRoleWithWebIdentity(ctx, profileConfig)
+		if err != nil {
+			return fmt.Errorf("failed to assume role with web identity: %w", err)
+		}
+
 	default:
 		return fmt.Errorf("unsupported profile type: %s", profileConfig.ProfileType)
 	}
@@ -173,6 +243,8 @@ func LoginWithProfile(ctx context.Context, profileName string, setAsDefault bool
 		return fmt.Errorf("failed to write credentials: %w", err)
 	}
 
+	recordIssuedCredentials(*profileConfig, creds)
+
 	logger.Infow("Login successful",
 		"profile_name", profileName,
 		"profile_type", profileConfig.ProfileType)
@@ -180,24 +252,50 @@ func LoginWithProfile(ctx context.Context, profileName string, setAsDefault bool
 	return nil
 }
 
+// recordIssuedCredentials adds credentials written for a profile to the audit log
+func recordIssuedCredentials(profile ProfileConfig, creds *Credentials) {
+	accountID, roleName := ProfileAccountAndRole(profile)
+	audit.Record(audit.Event{
+		Event:      audit.EventCredentials,
+		Profile:    profile.ProfileName,
+		AccountID:  accountID,
+		Role:       roleName,
+		Expiration: creds.ExpiresAt().UTC(),
+	})
+}
+
+// SSORoleCredentials gets temporary credentials for an SSO profile using the cached SSO token
+func SSORoleCredentials(ctx context.Context, provider CredentialProvider, profileConfig *ProfileConfig) (*Credentials, error) {
+	cachedToken, err := ReadTokenFromCache(profileConfig.StartURL, profileConfig.SSORegion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token from cache (you may need to run login first): %w", err)
+	}
+
+	creds, err := provider.GetRoleCredentials(ctx, cachedToken.AccessToken, profileConfig.AccountID, profileConfig.RoleName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get role credentials: %w", err)
+	}
+	return creds, nil
+}
+
 // AssumeRoleWithProfile assumes a role using source profile credentials
 func AssumeRoleWithProfile(ctx context.Context, profileConfig *ProfileConfig) (*Credentials, error) {
 	// Create source profile configuration
-	cfg, err := config.LoadDefaultConfig(ctx,
-		config.WithSharedConfigProfile(profileConfig.SourceProfile),
-		config.WithRegion(profileConfig.Region),
-	)
+	cfg, err := NewAWSConfig(ctx, ClientConfig{
+		Region:  profileConfig.Region,
+		Profile: profileConfig.SourceProfile,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to load source profile config: %w", err)
 	}
 
 	// Create STS client
-	stsClient := sts.NewFromConfig(cfg)
+	stsClient := newSTSClient(cfg)
 
 	// Prepare assume role input
 	input := &sts.AssumeRoleInput{
 		RoleArn:         aws.String(profileConfig.RoleARN),
-		RoleSessionName: aws.String(fmt.Sprintf("ark-cli-%d", time.Now().Unix())),
+		RoleSessionName: aws.String(profileSessionName(profileConfig)),
 	}
 
 	// Add ExternalID if present
@@ -221,3 +319,47 @@ func AssumeRoleWithProfile(ctx context.Context, profileConfig *ProfileConfig) (*
 
 	return creds, nil
 }
+
+// AssumeRoleWithWebIdentity exchanges the OIDC token in web_identity_token_file for role credentials
+// The call is unsigned, the same way GitHub Actions or IRSA workloads obtain their credentials
+func AssumeRoleWithWebIdentity(ctx context.Context, profileConfig *ProfileConfig) (*Credentials, error) {
+	if profileConfig.RoleARN == "" {
+		return nil, fmt.Errorf("role_arn is required for web identity profile")
+	}
+
+	token, err := os.ReadFile(profileConfig.WebIdentityTokenFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read web identity token file: %w", err)
+	}
+
+	cfg, err := NewAWSConfig(ctx, ClientConfig{Region: profileConfig.Region, Anonymous: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	stsClient := newSTSClient(cfg)
+
+	result, err := stsClient.AssumeRoleWithWebIdentity(ctx, &sts.AssumeRoleWithWebIdentityInput{
+		RoleArn:          aws.String(profileConfig.RoleARN),
+		RoleSessionName:  aws.String(profileSessionName(profileConfig)),
+		WebIdentityToken: aws.String(strings.TrimSpace(string(token))),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to assume role with web identity: %w", err)
+	}
+
+	return &Credentials{
+		AccessKeyID:     aws.ToString(result.Credentials.AccessKeyId),
+		SecretAccessKey: aws.ToString(result.Credentials.SecretAccessKey),
+		SessionToken:    aws.ToString(result.Credentials.SessionToken),
+		Expiration:      result.Credentials.Expiration.UnixMilli(),
+	}, nil
+}
+
+// profileSessionName prefers the profile's role_session_name over ark's template
+func profileSessionName(profileConfig *ProfileConfig) string {
+	if profileConfig.SessionName != "" {
+		return profileConfig.SessionName
+	}
+	return RoleSessionName()
+}
@@ -3,6 +3,7 @@ package services_aws
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -776,3 +777,18 @@ func TestProfileValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestAssumeRoleWithWebIdentityValidation(t *testing.T) {
+	_, err := AssumeRoleWithWebIdentity(context.Background(), &ProfileConfig{WebIdentityTokenFile: "/tmp/token"})
+	assert.ErrorContains(t, err, "role_arn is required")
+
+	_, err = AssumeRoleWithWebIdentity(context.Background(), &ProfileConfig{
+		RoleARN:              "arn:aws:iam::123456789012:role/GitHubActions",
+		WebIdentityTokenFile: filepath.Join(t.TempDir(), "missing"),
+	})
+	assert.ErrorContains(t, err, "failed to read web identity token file")
+}
+
+func TestProfileSessionName(t *testing.T) {
+	assert.Equal(t, "gha-run", profileSessionName(&ProfileConfig{SessionName: "gha-run"}))
+}
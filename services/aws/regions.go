@@ -0,0 +1,107 @@
+package services_aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+
+	ark_config "github.com/andresgarcia29/ark-cli/config"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+)
+
+// Region is a region enabled for an account
+type Region struct {
+	Name string
+	// OptInStatus is "opt-in-not-required" for the regions enabled by default and "opted-in" for the others
+	OptInStatus string
+}
+
+// EC2Client lists the regions of an account
+type EC2Client struct {
+	client *ec2.Client
+}
+
+// NewEC2Client creates an EC2 client for the account of a profile; an empty profile uses the SDK defaults
+func NewEC2Client(ctx context.Context, profile string) (*EC2Client, error) {
+	cfg, err := NewAWSConfig(ctx, ClientConfig{Profile: profile})
+	if err != nil {
+		return nil, fmt.Errorf("unable to load SDK config: %w", err)
+	}
+	return &EC2Client{client: newEC2Client(cfg)}, nil
+}
+
+// ListRegions lists the regions enabled for the account, sorted by name
+func (c *EC2Client) ListRegions(ctx context.Context) ([]Region, error) {
+	output, err := c.client.DescribeRegions(ctx, &ec2.DescribeRegionsInput{AllRegions: aws.Bool(false)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe regions: %w", err)
+	}
+
+	regions := make([]Region, 0, len(output.Regions))
+	for _, region := range output.Regions {
+		regions = append(regions, Region{
+			Name:        aws.ToString(region.RegionName),
+			OptInStatus: aws.ToString(region.OptInStatus),
+		})
+	}
+	sort.Slice(regions, func(i, j int) bool { return regions[i].Name < regions[j].Name })
+	return regions, nil
+}
+
+// STSEndpoint returns the regional STS endpoint of a region
+func STSEndpoint(region string) string {
+	return regionalEndpoint("sts", region)
+}
+
+// LatencyProber measures round trips to HTTPS endpoints, through the proxy and timeout of ark's AWS settings
+type LatencyProber struct {
+	client   aws.HTTPClient
+	attempts int
+}
+
+// NewLatencyProber creates a prober that keeps the fastest of attempts round trips
+func NewLatencyProber(attempts int) (*LatencyProber, error) {
+	client, err := newHTTPClient(ark_config.Get().AWS)
+	if err != nil {
+		return nil, err
+	}
+	return &LatencyProber{client: client, attempts: max(attempts, 1)}, nil
+}
+
+// Probe returns the fastest round trip of an unsigned request to endpoint
+// The first request also pays for the TLS handshake; the next ones reuse the connection and show the network distance.
+// Any HTTP response counts, the endpoint only has to answer
+func (p *LatencyProber) Probe(ctx context.Context, endpoint string) (time.Duration, error) {
+	var fastest time.Duration
+	var errs []error
+	for range p.attempts {
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+		if err != nil {
+			return 0, fmt.Errorf("invalid endpoint %q: %w", endpoint, err)
+		}
+
+		started := time.Now()
+		response, err := p.client.Do(request)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		elapsed := time.Since(started)
+		_, _ = io.Copy(io.Discard, response.Body)
+		response.Body.Close()
+
+		if fastest == 0 || elapsed < fastest {
+			fastest = elapsed
+		}
+	}
+
+	if fastest == 0 {
+		return 0, fmt.Errorf("no response from %s: %w", endpoint, errors.Join(errs...))
+	}
+	return fastest, nil
+}
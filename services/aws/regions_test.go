@@ -0,0 +1,43 @@
+package services_aws
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSTSEndpoint(t *testing.T) {
+	assert.Equal(t, "https://sts.us-west-2.amazonaws.com", STSEndpoint("us-west-2"))
+	assert.Equal(t, "https://sts.cn-north-1.amazonaws.com.cn", STSEndpoint("cn-north-1"))
+}
+
+func TestLatencyProberProbe(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		// STS answers unsigned requests with an error, which still measures the round trip
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte("<ErrorResponse/>"))
+	}))
+	defer server.Close()
+
+	prober := &LatencyProber{client: server.Client(), attempts: 3}
+	latency, err := prober.Probe(context.Background(), server.URL)
+	require.NoError(t, err)
+	assert.Positive(t, latency)
+	assert.Equal(t, 3, requests)
+}
+
+func TestLatencyProberProbeUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	prober := &LatencyProber{client: http.DefaultClient, attempts: 2}
+	_, err := prober.Probe(context.Background(), url)
+	assert.ErrorContains(t, err, "no response from "+url)
+}
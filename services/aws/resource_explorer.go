@@ -0,0 +1,282 @@
+package services_aws
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	ark_config "github.com/andresgarcia29/ark-cli/config"
+	"github.com/andresgarcia29/ark-cli/lib"
+	"github.com/andresgarcia29/ark-cli/logs"
+	"github.com/andresgarcia29/ark-cli/services/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+)
+
+// DiscoveryBackend selects how the EKS clusters of every account are found
+type DiscoveryBackend string
+
+const (
+	// DiscoveryListClusters signs in to every account and calls ListClusters in every region
+	DiscoveryListClusters DiscoveryBackend = "list-clusters"
+	// DiscoveryResourceExplorer asks the aggregator index of AWS Resource Explorer for every cluster at once
+	DiscoveryResourceExplorer DiscoveryBackend = "resource-explorer"
+)
+
+// ParseDiscoveryBackend validates a discovery backend name; empty selects ListClusters
+func ParseDiscoveryBackend(name string) (DiscoveryBackend, error) {
+	switch DiscoveryBackend(name) {
+	case "", DiscoveryListClusters:
+		return DiscoveryListClusters, nil
+	case DiscoveryResourceExplorer:
+		return DiscoveryResourceExplorer, nil
+	default:
+		return "", fmt.Errorf("invalid discovery backend %q (expected list-clusters or resource-explorer)", name)
+	}
+}
+
+// resourceExplorerSigningName is the SigV4 service name and endpoint prefix of Resource Explorer
+const resourceExplorerSigningName = "resource-explorer-2"
+
+// eksClusterQuery is the Resource Explorer query matching EKS clusters
+const eksClusterQuery = "resourcetype:eks:cluster"
+
+// searchPageSize is the largest page Resource Explorer returns
+const searchPageSize = 1000
+
+// ResourceExplorerClient searches the resources indexed by AWS Resource Explorer
+// The Search API is called over signed HTTP, like the SDK clients it shares its configuration with
+type ResourceExplorerClient struct {
+	httpClient  aws.HTTPClient
+	credentials aws.CredentialsProvider
+	signer      *v4.Signer
+	endpoint    string
+	region      string
+	// viewARN is the view to search; empty uses the default view of the region
+	viewARN string
+}
+
+// searchInput is the body of a Search request
+type searchInput struct {
+	QueryString string  `json:"QueryString"`
+	MaxResults  int     `json:"MaxResults,omitempty"`
+	NextToken   *string `json:"NextToken,omitempty"`
+	ViewArn     string  `json:"ViewArn,omitempty"`
+}
+
+// searchOutput is the subset of a Search response ark reads
+type searchOutput struct {
+	Resources []struct {
+		Arn             string `json:"Arn"`
+		OwningAccountId string `json:"OwningAccountId"`
+		Region          string `json:"Region"`
+	} `json:"Resources"`
+	NextToken *string `json:"NextToken"`
+	Count     *struct {
+		Complete       bool  `json:"Complete"`
+		TotalResources int64 `json:"TotalResources"`
+	} `json:"Count"`
+}
+
+// NewResourceExplorerClient creates a client searching the index of region with profile's credentials
+// The region should be the one of the aggregator index, which sees the resources of every account and region
+func NewResourceExplorerClient(ctx context.Context, region, profile, viewARN string) (*ResourceExplorerClient, error) {
+	cfg, err := NewAWSConfig(ctx, ClientConfig{Region: region, Profile: profile})
+	if err != nil {
+		return nil, fmt.Errorf("unable to load SDK config: %w", err)
+	}
+
+	var baseEndpoint *string
+	overrideEndpoint(&baseEndpoint, serviceResourceExplorer)
+	endpoint := aws.ToString(baseEndpoint)
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://%s.%s.amazonaws.com", resourceExplorerSigningName, cfg.Region)
+	}
+
+	return &ResourceExplorerClient{
+		httpClient:  cfg.HTTPClient,
+		credentials: cfg.Credentials,
+		signer:      v4.NewSigner(),
+		endpoint:    endpoint,
+		region:      cfg.Region,
+		viewARN:     viewARN,
+	}, nil
+}
+
+// SearchClusters lists the EKS clusters visible to the index, with their account and region
+// complete is false when Resource Explorer reports that it didn't return every match
+func (c *ResourceExplorerClient) SearchClusters(ctx context.Context) (clusters []EKSCluster, complete bool, err error) {
+	complete = true
+	input := searchInput{QueryString: eksClusterQuery, MaxResults: searchPageSize, ViewArn: c.viewARN}
+	for {
+		output, err := c.search(ctx, input)
+		if err != nil {
+			return nil, false, err
+		}
+
+		for _, resource := range output.Resources {
+			name, ok := clusterNameFromARN(resource.Arn)
+			if !ok {
+				logs.GetLogger().Debugw("Skipping resource that is not an EKS cluster", "arn", resource.Arn)
+				continue
+			}
+			clusters = append(clusters, EKSCluster{
+				Name:      name,
+				Region:    resource.Region,
+				AccountID: resource.OwningAccountId,
+				ARN:       resource.Arn,
+			})
+		}
+		if output.Count != nil && !output.Count.Complete {
+			complete = false
+		}
+
+		if output.NextToken == nil || *output.NextToken == "" {
+			return clusters, complete, nil
+		}
+		input.NextToken = output.NextToken
+	}
+}
+
+// search sends one signed Search request, retrying throttling and server errors
+func (c *ResourceExplorerClient) search(ctx context.Context, input searchInput) (*searchOutput, error) {
+	body, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+	payloadHash := sha256.Sum256(body)
+
+	var output searchOutput
+	err = lib.ExecuteWithRetry(ctx, lib.ServiceConfig(lib.ServiceResourceExplorer), func() error {
+		credentials, err := c.credentials.Retrieve(ctx)
+		if err != nil {
+			return lib.Permanent(fmt.Errorf("failed to get credentials for Resource Explorer: %w", err))
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(c.endpoint, "/")+"/Search", bytes.NewReader(body))
+		if err != nil {
+			return lib.Permanent(err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "ark/"+Version)
+		if err := c.signer.SignHTTP(ctx, credentials, req, hex.EncodeToString(payloadHash[:]), resourceExplorerSigningName, c.region, time.Now()); err != nil {
+			return lib.Permanent(fmt.Errorf("failed to sign Resource Explorer request: %w", err))
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to search Resource Explorer: %w", err)
+		}
+		defer resp.Body.Close()
+
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read Resource Explorer response: %w", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			err := fmt.Errorf("failed to search Resource Explorer: %s: %s", resp.Status, strings.TrimSpace(string(data)))
+			// Throttling and server errors may pass; a missing index, view or permission won't
+			if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+				return err
+			}
+			return lib.Permanent(err)
+		}
+		return json.Unmarshal(data, &output)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &output, nil
+}
+
+// clusterNameFromARN returns the name of an EKS cluster ARN, arn:aws:eks:<region>:<account>:cluster/<name>
+func clusterNameFromARN(clusterARN string) (string, bool) {
+	cluster, err := arn.ParseCluster(clusterARN)
+	if err != nil {
+		return "", false
+	}
+	return cluster.Name, true
+}
+
+// GetClustersFromResourceExplorer finds the clusters of every account in one search of the Resource Explorer
+// aggregator index (kubernetes.resource_explorer in ark's config), instead of signing in to every account
+// Clusters get the profile GetClustersFromAllAccounts would scan their account with; accounts without one are skipped
+func GetClustersFromResourceExplorer(ctx context.Context, regions []string, rolePrefixs []string, roleARN string) ([]EKSCluster, error) {
+	logger := logs.GetLogger()
+	settings := ark_config.Get().Kubernetes.ResourceExplorer
+
+	selectedProfiles, err := selectScanProfiles(rolePrefixs, roleARN)
+	if err != nil {
+		return nil, err
+	}
+	if len(selectedProfiles) == 0 {
+		logger.Warn("No accounts found to process")
+		return []EKSCluster{}, nil
+	}
+
+	if settings.Profile != "" {
+		if err := LoginWithProfile(ctx, settings.Profile, false); err != nil {
+			return nil, fmt.Errorf("failed to login with profile %s: %w", settings.Profile, err)
+		}
+	}
+	client, err := NewResourceExplorerClient(ctx, settings.Region, settings.Profile, settings.ViewARN)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Resource Explorer client: %w", err)
+	}
+
+	found, complete, err := client.SearchClusters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !complete {
+		logger.Warnw("Resource Explorer did not return every cluster; narrow the view or use list-clusters discovery",
+			"returned", len(found))
+	}
+
+	clusters := assignScanProfiles(found, scanRegions(regions), selectedProfiles)
+	logger.Infow("Resource Explorer search completed",
+		"clusters_found", len(found),
+		"clusters_kept", len(clusters))
+	return clusters, nil
+}
+
+// assignScanProfiles keeps the clusters in regions whose account has a scan profile, giving them that profile
+func assignScanProfiles(found []EKSCluster, regions []string, profiles map[string]ProfileConfig) []EKSCluster {
+	logger := logs.GetLogger()
+	wanted := make(map[string]bool, len(regions))
+	for _, region := range regions {
+		wanted[region] = true
+	}
+
+	clusters := []EKSCluster{}
+	for _, cluster := range found {
+		if !wanted[cluster.Region] {
+			continue
+		}
+		profile, ok := profiles[cluster.AccountID]
+		if !ok {
+			logger.Warnw("Skipping cluster of an account without a matching profile",
+				"cluster", cluster.Name,
+				"account_id", cluster.AccountID)
+			continue
+		}
+		cluster.Profile = profile.ProfileName
+		clusters = append(clusters, cluster)
+	}
+	return clusters
+}
+
+// DiscoverClusters finds the clusters of every account in regions with the given backend
+func DiscoverClusters(ctx context.Context, backend DiscoveryBackend, regions []string, rolePrefixs []string, roleARN string) ([]EKSCluster, error) {
+	if backend == DiscoveryResourceExplorer {
+		return GetClustersFromResourceExplorer(ctx, regions, rolePrefixs, roleARN)
+	}
+	return GetClustersFromAllAccounts(ctx, regions, rolePrefixs, roleARN)
+}
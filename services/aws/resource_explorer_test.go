@@ -0,0 +1,133 @@
+package services_aws
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestResourceExplorerClient points a client with static credentials at server
+func newTestResourceExplorerClient(server *httptest.Server, viewARN string) *ResourceExplorerClient {
+	return &ResourceExplorerClient{
+		httpClient: server.Client(),
+		credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret"}, nil
+		}),
+		signer:   v4.NewSigner(),
+		endpoint: server.URL,
+		region:   "us-east-1",
+		viewARN:  viewARN,
+	}
+}
+
+func TestParseDiscoveryBackend(t *testing.T) {
+	backend, err := ParseDiscoveryBackend("")
+	require.NoError(t, err)
+	assert.Equal(t, DiscoveryListClusters, backend)
+
+	backend, err = ParseDiscoveryBackend("resource-explorer")
+	require.NoError(t, err)
+	assert.Equal(t, DiscoveryResourceExplorer, backend)
+
+	_, err = ParseDiscoveryBackend("config-aggregator")
+	assert.ErrorContains(t, err, "invalid discovery backend")
+}
+
+func TestClusterNameFromARN(t *testing.T) {
+	tests := []struct {
+		arn  string
+		name string
+		ok   bool
+	}{
+		{arn: "arn:aws:eks:us-east-1:111111111111:cluster/prod", name: "prod", ok: true},
+		{arn: "arn:aws-cn:eks:cn-north-1:111111111111:cluster/prod-cn", name: "prod-cn", ok: true},
+		{arn: "arn:aws:eks:us-east-1:111111111111:nodegroup/prod/ng/abc"},
+		{arn: "arn:aws:ec2:us-east-1:111111111111:cluster/prod"},
+		{arn: "not-an-arn"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.arn, func(t *testing.T) {
+			name, ok := clusterNameFromARN(tt.arn)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.name, name)
+		})
+	}
+}
+
+func TestResourceExplorerSearchClusters(t *testing.T) {
+	var requests []searchInput
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/Search", r.URL.Path)
+		assert.Contains(t, r.Header.Get("Authorization"), "/us-east-1/resource-explorer-2/aws4_request")
+
+		var input searchInput
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&input))
+		requests = append(requests, input)
+
+		if input.NextToken == nil {
+			w.Write([]byte(`{"Resources": [
+				{"Arn": "arn:aws:eks:us-east-1:111111111111:cluster/prod", "OwningAccountId": "111111111111", "Region": "us-east-1"},
+				{"Arn": "arn:aws:eks:us-east-1:111111111111:nodegroup/prod/ng/abc", "OwningAccountId": "111111111111", "Region": "us-east-1"}
+			], "NextToken": "page-2", "Count": {"Complete": true, "TotalResources": 3}}`))
+			return
+		}
+		w.Write([]byte(`{"Resources": [
+			{"Arn": "arn:aws:eks:eu-west-1:222222222222:cluster/shared", "OwningAccountId": "222222222222", "Region": "eu-west-1"}
+		], "Count": {"Complete": false, "TotalResources": 3}}`))
+	}))
+	defer server.Close()
+
+	client := newTestResourceExplorerClient(server, "arn:aws:resource-explorer-2:us-east-1:111111111111:view/all/abc")
+	clusters, complete, err := client.SearchClusters(context.Background())
+	require.NoError(t, err)
+
+	assert.False(t, complete)
+	assert.Equal(t, []EKSCluster{
+		{Name: "prod", Region: "us-east-1", AccountID: "111111111111", ARN: "arn:aws:eks:us-east-1:111111111111:cluster/prod"},
+		{Name: "shared", Region: "eu-west-1", AccountID: "222222222222", ARN: "arn:aws:eks:eu-west-1:222222222222:cluster/shared"},
+	}, clusters)
+
+	require.Len(t, requests, 2)
+	assert.Equal(t, eksClusterQuery, requests[0].QueryString)
+	assert.Equal(t, "arn:aws:resource-explorer-2:us-east-1:111111111111:view/all/abc", requests[0].ViewArn)
+	assert.Equal(t, "page-2", aws.ToString(requests[1].NextToken))
+}
+
+func TestResourceExplorerSearchClustersClientError(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"message": "no default view in us-east-1"}`))
+	}))
+	defer server.Close()
+
+	_, _, err := newTestResourceExplorerClient(server, "").SearchClusters(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no default view")
+	assert.Equal(t, 1, calls, "client errors are not retried")
+}
+
+func TestAssignScanProfiles(t *testing.T) {
+	found := []EKSCluster{
+		{Name: "prod", Region: "us-east-1", AccountID: "111111111111"},
+		{Name: "prod-eu", Region: "eu-west-1", AccountID: "111111111111"},
+		{Name: "orphan", Region: "us-east-1", AccountID: "999999999999"},
+	}
+	profiles := map[string]ProfileConfig{
+		"111111111111": {ProfileName: "prod-readonly"},
+	}
+
+	clusters := assignScanProfiles(found, []string{"us-east-1"}, profiles)
+	assert.Equal(t, []EKSCluster{
+		{Name: "prod", Region: "us-east-1", AccountID: "111111111111", Profile: "prod-readonly"},
+	}, clusters)
+}
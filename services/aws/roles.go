@@ -3,12 +3,35 @@ package services_aws
 import (
 	"context"
 	"fmt"
+	"strings"
 
+	ark_config "github.com/andresgarcia29/ark-cli/config"
 	"github.com/andresgarcia29/ark-cli/logs"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sso"
 )
 
+// RoleDescription returns the hint configured for a role name in aws.role_descriptions, ignoring case
+// It helps telling apart similar permission sets such as Admin, AdminRO and AdminBreakGlass
+func RoleDescription(roleName string) string {
+	return lookupRoleDescription(ark_config.Get().AWS.RoleDescriptions, roleName)
+}
+
+func lookupRoleDescription(descriptions map[string]string, roleName string) string {
+	if roleName == "" {
+		return ""
+	}
+	if description, ok := descriptions[roleName]; ok {
+		return description
+	}
+	for name, description := range descriptions {
+		if strings.EqualFold(name, roleName) {
+			return description
+		}
+	}
+	return ""
+}
+
 // ListAccountRoles lists all available roles for a specific account
 func (s *SSOClient) ListAccountRoles(ctx context.Context, accessToken, accountID string) ([]Role, error) {
 	logger := logs.GetLogger()
@@ -40,8 +63,9 @@ func (s *SSOClient) ListAccountRoles(ctx context.Context, accessToken, accountID
 		// Add roles from this page
 		for _, role := range output.RoleList {
 			roleObj := Role{
-				RoleName:  aws.ToString(role.RoleName),
-				AccountID: accountID,
+				RoleName:    aws.ToString(role.RoleName),
+				AccountID:   accountID,
+				Description: RoleDescription(aws.ToString(role.RoleName)),
 			}
 			roles = append(roles, roleObj)
 			logger.Debugw("Role added", "account_id", accountID, "role_name", roleObj.RoleName)
@@ -83,6 +107,6 @@ func (s *SSOClient) GetRoleCredentials(ctx context.Context, accessToken, account
 		Expiration:      output.RoleCredentials.Expiration,
 	}
 
-	logger.Debugw("Role credentials obtained successfully", "account_id", accountID, "role_name", roleName, "expiration", credentials.Expiration)
+	logger.Debugw("Role credentials obtained successfully", "account_id", accountID, "role_name", roleName, "expires_at", credentials.ExpiresAt())
 	return credentials, nil
 }
@@ -605,3 +605,17 @@ func TestRoleCredentialsValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestLookupRoleDescription(t *testing.T) {
+	descriptions := map[string]string{
+		"Admin":           "Full access, audited",
+		"AdminRO":         "Admin console, read-only",
+		"AdminBreakGlass": "Emergency only, pages security",
+	}
+
+	assert.Equal(t, "Admin console, read-only", lookupRoleDescription(descriptions, "AdminRO"))
+	assert.Equal(t, "Emergency only, pages security", lookupRoleDescription(descriptions, "adminbreakglass"), "names match ignoring case")
+	assert.Empty(t, lookupRoleDescription(descriptions, "ReadOnly"))
+	assert.Empty(t, lookupRoleDescription(descriptions, ""))
+	assert.Empty(t, lookupRoleDescription(nil, "Admin"))
+}
@@ -0,0 +1,175 @@
+package services_aws
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	ark_config "github.com/andresgarcia29/ark-cli/config"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	"github.com/aws/aws-sdk-go-v2/service/sso"
+	"github.com/aws/aws-sdk-go-v2/service/ssooidc"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// Service names used as keys of aws.endpoints in ark's config
+const (
+	serviceSSO           = "sso"
+	serviceSSOOIDC       = "sso_oidc"
+	serviceSTS           = "sts"
+	serviceEKS           = "eks"
+	serviceIAM           = "iam"
+	serviceEC2           = "ec2"
+	serviceOrganizations = "organizations"
+	// serviceResourceExplorer has no SDK client: see ResourceExplorerClient
+	serviceResourceExplorer = "resource_explorer"
+)
+
+// defaultRegion is used when neither the caller, the profile nor the environment sets a region
+const defaultRegion = "us-east-1"
+
+// ClientConfig describes the SDK configuration a client needs
+type ClientConfig struct {
+	// Region is resolved from the profile or environment when empty, then defaults to us-east-1
+	Region  string
+	Profile string
+	// Anonymous skips credential loading, for unsigned calls (SSO device flow, web identity)
+	Anonymous bool
+}
+
+// NewAWSConfig builds the SDK configuration shared by every AWS client
+// It applies adaptive retries, the ark/<version> user agent, call instrumentation, timeouts, proxy and CA bundle
+func NewAWSConfig(ctx context.Context, clientConfig ClientConfig) (aws.Config, error) {
+	opts, err := sdkLoadOptions(ark_config.Get().AWS, clientConfig)
+	if err != nil {
+		return aws.Config{}, err
+	}
+
+	cfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return aws.Config{}, err
+	}
+	if cfg.Region == "" {
+		cfg.Region = defaultRegion
+	}
+	return cfg, nil
+}
+
+// newHTTPClient builds the HTTP client of the SDK clients, with the timeout and proxy of ark's AWS settings
+func newHTTPClient(settings ark_config.AWSConfig) (*awshttp.BuildableClient, error) {
+	httpClient := awshttp.NewBuildableClient()
+	if settings.Timeout > 0 {
+		httpClient = httpClient.WithTimeout(settings.Timeout)
+	}
+	if settings.Proxy != "" {
+		proxyURL, err := url.Parse(settings.Proxy)
+		if err != nil || proxyURL.Scheme == "" || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid aws.proxy %q: expected a URL such as http://proxy.internal:3128", settings.Proxy)
+		}
+		httpClient = httpClient.WithTransportOptions(func(tr *http.Transport) {
+			tr.Proxy = http.ProxyURL(proxyURL)
+		})
+	}
+	return httpClient, nil
+}
+
+// sdkLoadOptions turns ark's AWS settings and the client needs into SDK load options
+func sdkLoadOptions(settings ark_config.AWSConfig, clientConfig ClientConfig) ([]func(*config.LoadOptions) error, error) {
+	httpClient, err := newHTTPClient(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []func(*config.LoadOptions) error{
+		config.WithHTTPClient(httpClient),
+		config.WithRetryMode(aws.RetryModeAdaptive),
+		config.WithAPIOptions([]func(*middleware.Stack) error{
+			awsmiddleware.AddUserAgentKeyValue("ark", Version),
+			recordAPICalls,
+		}),
+	}
+	if settings.MaxAttempts > 0 {
+		opts = append(opts, config.WithRetryMaxAttempts(settings.MaxAttempts))
+	}
+
+	if settings.CABundle != "" {
+		bundle, err := os.ReadFile(settings.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read aws.ca_bundle: %w", err)
+		}
+		opts = append(opts, config.WithCustomCABundle(bytes.NewReader(bundle)))
+	}
+
+	if clientConfig.Region != "" {
+		opts = append(opts, config.WithRegion(clientConfig.Region))
+	}
+	if clientConfig.Profile != "" {
+		opts = append(opts, config.WithSharedConfigProfile(clientConfig.Profile))
+	}
+	if clientConfig.Anonymous {
+		opts = append(opts, config.WithCredentialsProvider(aws.AnonymousCredentials{}))
+	}
+
+	return opts, nil
+}
+
+// testEndpoint sends every client to one endpoint; it can only be set in e2e builds (see sdk_e2e.go)
+var testEndpoint string
+
+// overrideEndpoint replaces a client's base endpoint when ark's config sets one for the service
+// Without an override the SDK resolution applies, including AWS_ENDPOINT_URL_<SERVICE>
+func overrideEndpoint(baseEndpoint **string, service string) {
+	if testEndpoint != "" {
+		*baseEndpoint = aws.String(testEndpoint)
+		return
+	}
+	if endpoint := ark_config.Get().AWS.Endpoint(service); endpoint != "" {
+		*baseEndpoint = aws.String(endpoint)
+	}
+}
+
+// newSSOClient creates an SSO portal client from the shared configuration
+func newSSOClient(cfg aws.Config) *sso.Client {
+	return sso.NewFromConfig(cfg, func(o *sso.Options) { overrideEndpoint(&o.BaseEndpoint, serviceSSO) })
+}
+
+// newSSOOIDCClient creates an SSO OIDC client from the shared configuration
+func newSSOOIDCClient(cfg aws.Config) *ssooidc.Client {
+	return ssooidc.NewFromConfig(cfg, func(o *ssooidc.Options) { overrideEndpoint(&o.BaseEndpoint, serviceSSOOIDC) })
+}
+
+// newSTSClient creates an STS client from the shared configuration
+func newSTSClient(cfg aws.Config) *sts.Client {
+	return sts.NewFromConfig(cfg, func(o *sts.Options) { overrideEndpoint(&o.BaseEndpoint, serviceSTS) })
+}
+
+// newEKSClient creates an EKS client from the shared configuration
+func newEKSClient(cfg aws.Config) *eks.Client {
+	return eks.NewFromConfig(cfg, func(o *eks.Options) { overrideEndpoint(&o.BaseEndpoint, serviceEKS) })
+}
+
+// newIAMClient creates an IAM client from the shared configuration
+func newIAMClient(cfg aws.Config) *iam.Client {
+	return iam.NewFromConfig(cfg, func(o *iam.Options) { overrideEndpoint(&o.BaseEndpoint, serviceIAM) })
+}
+
+// newEC2Client creates an EC2 client from the shared configuration
+func newEC2Client(cfg aws.Config) *ec2.Client {
+	return ec2.NewFromConfig(cfg, func(o *ec2.Options) { overrideEndpoint(&o.BaseEndpoint, serviceEC2) })
+}
+
+// newOrganizationsClient creates an Organizations client from the shared configuration
+func newOrganizationsClient(cfg aws.Config) *organizations.Client {
+	return organizations.NewFromConfig(cfg, func(o *organizations.Options) { overrideEndpoint(&o.BaseEndpoint, serviceOrganizations) })
+}
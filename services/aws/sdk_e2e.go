@@ -0,0 +1,9 @@
+//go:build e2e
+
+package services_aws
+
+// SetTestEndpoint points every AWS client at a fake server such as internal/fakeaws
+// It only exists in builds with the e2e tag, so release binaries can't be redirected
+func SetTestEndpoint(url string) {
+	testEndpoint = url
+}
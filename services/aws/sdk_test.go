@@ -0,0 +1,74 @@
+package services_aws
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	ark_config "github.com/andresgarcia29/ark-cli/config"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSDKLoadOptions(t *testing.T) {
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(bundle, []byte("-----BEGIN CERTIFICATE-----\n"), 0600))
+
+	tests := []struct {
+		name         string
+		settings     ark_config.AWSConfig
+		clientConfig ClientConfig
+		expected     int
+		expectError  string
+	}{
+		{name: "defaults", expected: 3},
+		{name: "max attempts", settings: ark_config.AWSConfig{MaxAttempts: 5, Timeout: time.Second}, expected: 4},
+		{name: "proxy", settings: ark_config.AWSConfig{Proxy: "http://proxy.internal:3128"}, expected: 3},
+		{name: "ca bundle", settings: ark_config.AWSConfig{CABundle: bundle}, expected: 4},
+		{name: "client config", clientConfig: ClientConfig{Region: "eu-west-1", Profile: "dev", Anonymous: true}, expected: 6},
+		{name: "invalid proxy", settings: ark_config.AWSConfig{Proxy: "proxy.internal"}, expectError: "invalid aws.proxy"},
+		{name: "missing ca bundle", settings: ark_config.AWSConfig{CABundle: filepath.Join(t.TempDir(), "missing.pem")}, expectError: "failed to read aws.ca_bundle"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := sdkLoadOptions(tt.settings, tt.clientConfig)
+			if tt.expectError != "" {
+				assert.ErrorContains(t, err, tt.expectError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, opts, tt.expected)
+		})
+	}
+}
+
+func TestNewAWSConfig(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(ark_config.ConfigPathEnvVar, filepath.Join(dir, "config.yaml"))
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(dir, "aws-config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "aws-credentials"))
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_DEFAULT_REGION", "")
+
+	cfg, err := NewAWSConfig(context.Background(), ClientConfig{Anonymous: true})
+	require.NoError(t, err)
+	assert.Equal(t, defaultRegion, cfg.Region, "falls back to the default region")
+	assert.Equal(t, aws.RetryModeAdaptive, cfg.RetryMode)
+
+	cfg, err = NewAWSConfig(context.Background(), ClientConfig{Region: "eu-west-1", Anonymous: true})
+	require.NoError(t, err)
+	assert.Equal(t, "eu-west-1", cfg.Region)
+}
+
+func TestOverrideEndpoint(t *testing.T) {
+	existing := "https://sts.from-env.example"
+	endpoint := &existing
+
+	// Without an aws.endpoints entry the endpoint resolved by the SDK is kept
+	overrideEndpoint(&endpoint, "not-configured")
+	assert.Equal(t, "https://sts.from-env.example", *endpoint)
+}
@@ -0,0 +1,88 @@
+package services_aws
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/user"
+	"regexp"
+	"strings"
+	"text/template"
+
+	ark_config "github.com/andresgarcia29/ark-cli/config"
+	"github.com/andresgarcia29/ark-cli/logs"
+)
+
+// Version is ark's version as reported in role session names; cmd sets it at startup
+var Version = "dev"
+
+const (
+	minSessionNameLength = 2
+	maxSessionNameLength = 64
+)
+
+// invalidSessionNameChars matches characters STS rejects in RoleSessionName
+var invalidSessionNameChars = regexp.MustCompile(`[^\w+=,.@-]`)
+
+// SessionNameData holds the fields available to session name templates
+type SessionNameData struct {
+	User     string
+	Hostname string
+	Version  string
+}
+
+// currentSessionNameData describes the local user running ark
+func currentSessionNameData() SessionNameData {
+	data := SessionNameData{User: "unknown", Version: Version}
+	if current, err := user.Current(); err == nil && current.Username != "" {
+		// Windows usernames look like DOMAIN\user
+		data.User = current.Username[strings.LastIndex(current.Username, `\`)+1:]
+	}
+	if hostname, err := os.Hostname(); err == nil {
+		data.Hostname = hostname
+	}
+	return data
+}
+
+// renderSessionName executes a session name template and makes the result valid for STS
+func renderSessionName(text string, data SessionNameData) (string, error) {
+	tmpl, err := template.New("session_name").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid session name template %q: %w", text, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("invalid session name template %q: %w", text, err)
+	}
+
+	name := invalidSessionNameChars.ReplaceAllString(buf.String(), "-")
+	if len(name) > maxSessionNameLength {
+		name = name[:maxSessionNameLength]
+	}
+	if len(name) < minSessionNameLength {
+		return "", fmt.Errorf("session name %q is too short (minimum %d characters)", name, minSessionNameLength)
+	}
+	return name, nil
+}
+
+// RoleSessionName returns the session name used when assuming roles
+// It follows the aws.session_name template so CloudTrail events can be attributed to a person
+// SSO role credentials don't take a session name: AWS already uses the SSO user name there
+func RoleSessionName() string {
+	logger := logs.GetLogger()
+	data := currentSessionNameData()
+
+	name, err := renderSessionName(ark_config.Get().AWS.SessionName, data)
+	if err == nil {
+		return name
+	}
+
+	logger.Warnw("Invalid session name template, using the default", "error", err)
+	name, err = renderSessionName(ark_config.DefaultSessionName, data)
+	if err != nil {
+		// The default template always renders at least "@ark-"
+		return "ark-cli"
+	}
+	return name
+}
@@ -0,0 +1,55 @@
+package services_aws
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	ark_config "github.com/andresgarcia29/ark-cli/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderSessionName(t *testing.T) {
+	data := SessionNameData{User: "alice", Hostname: "laptop.local", Version: "1.4.0"}
+
+	tests := []struct {
+		name        string
+		template    string
+		expected    string
+		expectError bool
+	}{
+		{name: "default", template: "{{.User}}@ark-{{.Version}}", expected: "alice@ark-1.4.0"},
+		{name: "hostname", template: "{{.User}}.{{.Hostname}}", expected: "alice.laptop.local"},
+		{name: "invalid characters replaced", template: "{{.User}} on {{.Hostname}}/x", expected: "alice-on-laptop.local-x"},
+		{name: "unknown field", template: "{{.Team}}", expectError: true},
+		{name: "parse error", template: "{{.User", expectError: true},
+		{name: "too short", template: "a", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, err := renderSessionName(tt.template, data)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, name)
+		})
+	}
+}
+
+func TestRenderSessionNameTruncates(t *testing.T) {
+	name, err := renderSessionName("{{.User}}", SessionNameData{User: strings.Repeat("a", 100)})
+	require.NoError(t, err)
+	assert.Len(t, name, maxSessionNameLength)
+}
+
+func TestRoleSessionName(t *testing.T) {
+	t.Setenv(ark_config.ConfigPathEnvVar, filepath.Join(t.TempDir(), "config.yaml"))
+
+	name := RoleSessionName()
+	assert.Contains(t, name, "@ark-"+Version)
+	assert.Regexp(t, `^[\w+=,.@-]{2,64}$`, name)
+}
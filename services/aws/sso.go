@@ -4,12 +4,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/andresgarcia29/ark-cli/logs"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ssooidc"
+	"github.com/aws/aws-sdk-go-v2/service/ssooidc/types"
 	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
 )
 
 func StartSSOSession(ctx context.Context, region, startURL string) error {
@@ -19,6 +23,10 @@ func StartSSOSession(ctx context.Context, region, startURL string) error {
 	return nil
 }
 
+// ErrInvalidClient is returned by StartDeviceAuthorization when the portal rejects the client registration,
+// e.g. a cached one that was revoked
+var ErrInvalidClient = errors.New("the SSO client registration is no longer valid")
+
 // StartDeviceAuthorization starts the device authorization flow
 func (s *SSOClient) StartDeviceAuthorization(ctx context.Context, clientID, clientSecret string) (*DeviceAuthorization, error) {
 	logger := logs.GetLogger()
@@ -31,6 +39,12 @@ func (s *SSOClient) StartDeviceAuthorization(ctx context.Context, clientID, clie
 	}
 
 	output, err := s.oidcClient.StartDeviceAuthorization(ctx, input)
+	var invalidClient *types.InvalidClientException
+	var unauthorizedClient *types.UnauthorizedClientException
+	if errors.As(err, &invalidClient) || errors.As(err, &unauthorizedClient) {
+		logger.Debugw("Client registration rejected", "client_id", clientID, "error", err)
+		return nil, fmt.Errorf("%w: %w", ErrInvalidClient, err)
+	}
 	if err != nil {
 		logger.Errorw("Failed to start device authorization", "client_id", clientID, "error", err)
 		return nil, fmt.Errorf("failed to start device authorization: %w", err)
@@ -49,21 +63,54 @@ func (s *SSOClient) StartDeviceAuthorization(ctx context.Context, clientID, clie
 	return auth, nil
 }
 
-// CreateToken polls until the user authorizes or the time expires
-func (s *SSOClient) CreateToken(ctx context.Context, clientID, clientSecret, deviceCode string, interval int32) (*TokenResponse, error) {
+// ErrDeviceAuthorizationExpired is returned by CreateToken when the device authorization was not approved in time
+var ErrDeviceAuthorizationExpired = errors.New("the authorization request expired before it was approved")
+
+const (
+	// defaultPollInterval is used when the device authorization doesn't set an interval (RFC 8628, section 3.2)
+	defaultPollInterval = 5 * time.Second
+	// slowDownIncrement is added to the polling interval on every SlowDownException (RFC 8628, section 3.5)
+	slowDownIncrement = 5 * time.Second
+	// defaultDeviceAuthorizationLifetime bounds polling when the device authorization doesn't say when it expires
+	defaultDeviceAuthorizationLifetime = 10 * time.Minute
+)
+
+// CreateToken polls until the user authorizes or the device authorization expires, expiresIn seconds from now
+// SlowDownException permanently lengthens the interval and a Retry-After header delays the next poll
+func (s *SSOClient) CreateToken(ctx context.Context, clientID, clientSecret, deviceCode string, interval, expiresIn int32) (*TokenResponse, error) {
 	logger := logs.GetLogger()
-	logger.Debugw("Starting token creation polling", "client_id", clientID, "interval", interval)
+	logger.Debugw("Starting token creation polling", "client_id", clientID, "interval", interval, "expires_in", expiresIn)
 
-	ticker := time.NewTicker(time.Duration(interval) * time.Second)
-	defer ticker.Stop()
+	pollInterval := time.Duration(interval) * time.Second
+	if pollInterval <= 0 {
+		pollInterval = defaultPollInterval
+	}
+	lifetime := time.Duration(expiresIn) * time.Second
+	if lifetime <= 0 {
+		lifetime = defaultDeviceAuthorizationLifetime
+	}
+
+	// The deadline also cancels a CreateToken call still in flight when the authorization expires
+	pollCtx, cancel := context.WithTimeout(ctx, lifetime)
+	defer cancel()
+	expired := func() error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		logger.Debugw("Device authorization expired", "lifetime", lifetime)
+		return fmt.Errorf("%w (it was valid for %s)", ErrDeviceAuthorizationExpired, lifetime)
+	}
+
+	timer := time.NewTimer(pollInterval)
+	defer timer.Stop()
 	pollCount := 0
 
 	for {
 		select {
-		case <-ctx.Done():
-			logger.Debug("Token creation cancelled by context")
-			return nil, ctx.Err()
-		case <-ticker.C:
+		case <-pollCtx.Done():
+			logger.Debug("Token creation stopped by context")
+			return nil, expired()
+		case <-timer.C:
 			pollCount++
 			logger.Debugw("Polling for token", "attempt", pollCount)
 
@@ -74,23 +121,32 @@ func (s *SSOClient) CreateToken(ctx context.Context, clientID, clientSecret, dev
 				GrantType:    aws.String("urn:ietf:params:oauth:grant-type:device_code"),
 			}
 
-			output, err := s.oidcClient.CreateToken(ctx, input)
+			output, err := s.oidcClient.CreateToken(pollCtx, input)
 			if err != nil {
-				// If it is AuthorizationPendingException, continue polling
-				if isAuthorizationPending(err) {
-					logger.Debugw("Authorization still pending", "attempt", pollCount)
-					continue
+				if pollCtx.Err() != nil {
+					return nil, expired()
+				}
+				if isExpiredDeviceCode(err) {
+					return nil, expired()
 				}
 				// If it is SlowDownException, increase the interval
 				if isSlowDown(err) {
-					newInterval := interval + 5
-					logger.Debugw("Rate limited, increasing interval", "old_interval", interval, "new_interval", newInterval)
-					ticker.Reset(time.Duration(newInterval) * time.Second)
-					continue
+					logger.Debugw("Rate limited, increasing interval", "old_interval", pollInterval, "new_interval", pollInterval+slowDownIncrement)
+					pollInterval += slowDownIncrement
+				} else if !isAuthorizationPending(err) {
+					// Any other error, fail
+					logger.Errorw("Failed to create token", "attempt", pollCount, "error", err)
+					return nil, fmt.Errorf("failed to create token: %w", err)
+				}
+
+				// Poll again after the interval, or later if the server asked to
+				wait := pollInterval
+				if retryAfter, ok := retryAfterDelay(err, time.Now()); ok && retryAfter > wait {
+					wait = retryAfter
 				}
-				// Any other error, fail
-				logger.Errorw("Failed to create token", "attempt", pollCount, "error", err)
-				return nil, fmt.Errorf("failed to create token: %w", err)
+				logger.Debugw("Authorization still pending", "attempt", pollCount, "next_poll", wait)
+				timer.Reset(wait)
+				continue
 			}
 
 			// Token obtained successfully
@@ -107,6 +163,23 @@ func (s *SSOClient) CreateToken(ctx context.Context, clientID, clientSecret, dev
 	}
 }
 
+// retryAfterDelay reads the Retry-After header of an error response, in seconds or as an HTTP date
+func retryAfterDelay(err error, now time.Time) (time.Duration, bool) {
+	var responseErr *smithyhttp.ResponseError
+	if !errors.As(err, &responseErr) || responseErr.Response == nil {
+		return 0, false
+	}
+
+	value := responseErr.Response.Header.Get("Retry-After")
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return date.Sub(now), true
+	}
+	return 0, false
+}
+
 // Helper functions to identify specific errors
 func isAuthorizationPending(err error) bool {
 	var apiErr smithy.APIError
@@ -116,6 +189,14 @@ func isAuthorizationPending(err error) bool {
 	return false
 }
 
+func isExpiredDeviceCode(err error) bool {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.ErrorCode() == "ExpiredTokenException"
+	}
+	return false
+}
+
 func isSlowDown(err error) bool {
 	var apiErr smithy.APIError
 	if errors.As(err, &apiErr) {
@@ -2,10 +2,17 @@ package services_aws
 
 import (
 	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssooidc"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestStartSSOSession(t *testing.T) {
@@ -612,3 +619,114 @@ func TestSSOClientCreateTokenSelect(t *testing.T) {
 		})
 	}
 }
+
+// newPollingTestClient points an SSO client at a fake CreateToken endpoint answering with the given responses in turn
+func newPollingTestClient(t *testing.T, responses ...func(w http.ResponseWriter)) (*SSOClient, *int) {
+	t.Helper()
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		respond := responses[len(responses)-1]
+		if polls < len(responses) {
+			respond = responses[polls]
+		}
+		polls++
+		respond(w)
+	}))
+	t.Cleanup(server.Close)
+
+	oidcClient := ssooidc.New(ssooidc.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String(server.URL),
+		Retryer:      aws.NopRetryer{},
+	})
+	return &SSOClient{oidcClient: oidcClient, Region: "us-east-1"}, &polls
+}
+
+func oidcError(code string, headers map[string]string) func(w http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		for key, value := range headers {
+			w.Header().Set(key, value)
+		}
+		w.Header().Set("x-amzn-errortype", code)
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"message":"` + code + `"}`))
+	}
+}
+
+func oidcToken(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write([]byte(`{"accessToken":"token","tokenType":"Bearer","expiresIn":3600}`))
+}
+
+func TestCreateTokenDeadline(t *testing.T) {
+	client, polls := newPollingTestClient(t, oidcError("AuthorizationPendingException", nil))
+
+	start := time.Now()
+	_, err := client.CreateToken(context.Background(), "id", "secret", "device", 1, 2)
+	require.ErrorIs(t, err, ErrDeviceAuthorizationExpired)
+	assert.Less(t, time.Since(start), 3*time.Second, "polling stops when the authorization expires")
+	assert.GreaterOrEqual(t, *polls, 1)
+
+	// Cancelling the caller's context is not reported as an expiry
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = client.CreateToken(ctx, "id", "secret", "device", 1, 2)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestStartDeviceAuthorizationInvalidClient(t *testing.T) {
+	for _, code := range []string{"InvalidClientException", "UnauthorizedClientException"} {
+		client, _ := newPollingTestClient(t, oidcError(code, nil))
+		_, err := client.StartDeviceAuthorization(context.Background(), "revoked", "secret")
+		assert.ErrorIs(t, err, ErrInvalidClient, code)
+	}
+
+	client, _ := newPollingTestClient(t, oidcError("InternalServerException", nil))
+	_, err := client.StartDeviceAuthorization(context.Background(), "id", "secret")
+	assert.NotErrorIs(t, err, ErrInvalidClient)
+}
+
+func TestCreateTokenExpiredDeviceCode(t *testing.T) {
+	client, _ := newPollingTestClient(t, oidcError("ExpiredTokenException", nil))
+
+	_, err := client.CreateToken(context.Background(), "id", "secret", "device", 1, 600)
+	assert.ErrorIs(t, err, ErrDeviceAuthorizationExpired)
+}
+
+func TestCreateTokenRetryAfter(t *testing.T) {
+	client, polls := newPollingTestClient(t,
+		oidcError("AuthorizationPendingException", map[string]string{"Retry-After": "2"}),
+		oidcToken,
+	)
+
+	start := time.Now()
+	token, err := client.CreateToken(context.Background(), "id", "secret", "device", 1, 600)
+	require.NoError(t, err)
+	assert.Equal(t, "token", token.AccessToken)
+	assert.Equal(t, 2, *polls)
+	assert.GreaterOrEqual(t, time.Since(start), 3*time.Second, "the second poll waits for Retry-After, not the interval")
+}
+
+func TestRetryAfterDelay(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	responseErr := func(value string) error {
+		response := &http.Response{Header: http.Header{}}
+		if value != "" {
+			response.Header.Set("Retry-After", value)
+		}
+		return &smithyhttp.ResponseError{Response: &smithyhttp.Response{Response: response}}
+	}
+
+	delay, ok := retryAfterDelay(responseErr("7"), now)
+	assert.True(t, ok)
+	assert.Equal(t, 7*time.Second, delay)
+
+	delay, ok = retryAfterDelay(responseErr(now.Add(time.Minute).Format(http.TimeFormat)), now)
+	assert.True(t, ok)
+	assert.Equal(t, time.Minute, delay)
+
+	_, ok = retryAfterDelay(responseErr(""), now)
+	assert.False(t, ok)
+	_, ok = retryAfterDelay(errors.New("plain"), now)
+	assert.False(t, ok)
+}
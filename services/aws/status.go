@@ -0,0 +1,100 @@
+package services_aws
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	ark_config "github.com/andresgarcia29/ark-cli/config"
+)
+
+// CredentialStatus describes the credentials of a profile as found in local files, without calling AWS
+type CredentialStatus struct {
+	// Profile is the profile the credentials were issued for; for [default], the profile ark copied them from
+	Profile   string
+	AccountID string
+	// AccountName is the name of the account cached by `ark aws sso`, empty when it isn't cached
+	AccountName string
+	// Expiration is zero for credentials without one, e.g. static keys or profiles not in the credentials file
+	Expiration time.Time
+}
+
+// ReadCredentialStatus reads the status of a profile's credentials from the credentials file, the AWS config
+// and the discovery cache only, so shell prompts can call it on every command. It returns nil when the
+// profile is neither in the credentials file nor in the AWS config
+func ReadCredentialStatus(profileName string) (*CredentialStatus, error) {
+	credentialsPath, err := CredentialsFilePath()
+	if err != nil {
+		return nil, err
+	}
+	var sections map[string]map[string]string
+	data, err := os.ReadFile(credentialsPath)
+	switch {
+	case err == nil:
+		sections = parseINIFile(string(data))
+	case !os.IsNotExist(err):
+		return nil, fmt.Errorf("failed to read credentials file: %w", err)
+	}
+
+	status := &CredentialStatus{Profile: profileName}
+	section, inCredentials := sections[profileName]
+	if inCredentials {
+		status.Expiration, _ = time.Parse(time.RFC3339, section["expiration"])
+		if profileName == "default" {
+			status.Profile = copiedFrom(sections, section)
+		}
+	}
+
+	profile, err := ReadProfileFromConfig(status.Profile)
+	if err != nil {
+		// A profile the AWS config doesn't have (or no config at all) only has what the credentials file says
+		if inCredentials {
+			return status, nil
+		}
+		return nil, nil
+	}
+	status.AccountID = profile.AccountID
+	if status.AccountID == "" && profile.RoleARN != "" {
+		if principal, err := ParseIAMPrincipal(profile.RoleARN); err == nil {
+			status.AccountID = principal.AccountID
+		}
+	}
+	status.AccountName = cachedAccountName(profile.StartURL, status.AccountID)
+	return status, nil
+}
+
+// copiedFrom returns the profile whose credentials [default] holds, since ark writes the same keys to both,
+// or "default" when no other section has them
+func copiedFrom(sections map[string]map[string]string, defaultSection map[string]string) string {
+	accessKey := defaultSection["aws_access_key_id"]
+	names := make([]string, 0, len(sections))
+	for name := range sections {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		if name != "default" && accessKey != "" && sections[name]["aws_access_key_id"] == accessKey {
+			return name
+		}
+	}
+	return "default"
+}
+
+// cachedAccountName looks up the name of an account in the profiles cached for an SSO portal
+// An encrypted cache is skipped: reading its key from the keychain starts a process, too slow for a prompt
+func cachedAccountName(startURL, accountID string) string {
+	if startURL == "" || accountID == "" || ark_config.Get().Cache.Encrypt {
+		return ""
+	}
+	cache, err := LoadProfileCache(startURL)
+	if err != nil {
+		return ""
+	}
+	for _, profile := range cache.Profiles {
+		if profile.AccountID == accountID {
+			return profile.AccountName
+		}
+	}
+	return ""
+}
@@ -0,0 +1,70 @@
+package services_aws
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadCredentialStatus(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("AWS_CONFIG_FILE", "")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", "")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".aws"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".aws", "config"), []byte(`[profile payments-prod-readonly]
+sso_start_url = https://my-org.awsapps.com/start
+sso_region = us-east-1
+sso_account_id = 222222222222
+sso_role_name = ReadOnly
+
+[profile legacy]
+role_arn = arn:aws:iam::333333333333:role/Legacy
+source_profile = payments-prod-readonly
+`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".aws", "credentials"), []byte(`[default]
+aws_access_key_id = ASIAPROD
+aws_secret_access_key = secret
+expiration = 2026-10-15T12:30:00Z
+
+[payments-prod-readonly]
+aws_access_key_id = ASIAPROD
+aws_secret_access_key = secret
+expiration = 2026-10-15T12:30:00Z
+
+[static]
+aws_access_key_id = AKIASTATIC
+aws_secret_access_key = secret
+`), 0o600))
+	require.NoError(t, SaveProfileCache("https://my-org.awsapps.com/start", []AWSProfile{
+		{AccountID: "222222222222", AccountName: "payments-prod", RoleName: "ReadOnly"},
+	}))
+
+	expiration := time.Date(2026, 10, 15, 12, 30, 0, 0, time.UTC)
+
+	status, err := ReadCredentialStatus("default")
+	require.NoError(t, err)
+	assert.Equal(t, &CredentialStatus{
+		Profile:     "payments-prod-readonly",
+		AccountID:   "222222222222",
+		AccountName: "payments-prod",
+		Expiration:  expiration,
+	}, status, "[default] is named after the profile it was copied from")
+
+	status, err = ReadCredentialStatus("static")
+	require.NoError(t, err)
+	assert.Equal(t, &CredentialStatus{Profile: "static"}, status, "static keys don't expire")
+
+	status, err = ReadCredentialStatus("legacy")
+	require.NoError(t, err)
+	assert.Equal(t, &CredentialStatus{Profile: "legacy", AccountID: "333333333333"}, status, "assume-role profiles take the account of their role")
+
+	status, err = ReadCredentialStatus("missing")
+	require.NoError(t, err)
+	assert.Nil(t, status)
+}
@@ -0,0 +1,136 @@
+package services_aws
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/andresgarcia29/ark-cli/logs"
+)
+
+// RemoveArkProfiles removes the profiles of the AWS config that ark marked as written by it, returning their
+// names sorted. Profiles written by hand or by other tools, and the other sections, are left as they are
+func RemoveArkProfiles(dryRun bool) ([]string, error) {
+	configPath, err := ConfigFilePath()
+	if err != nil {
+		return nil, err
+	}
+	return removeMarkedSections(configPath, "config", dryRun, func(name string) string {
+		return strings.TrimPrefix(name, "profile ")
+	})
+}
+
+// RemoveArkCredentials removes the sections of the credentials file that ark marked as written by it, expired
+// or not, returning their names sorted. Static keys are left as they are
+func RemoveArkCredentials(dryRun bool) ([]string, error) {
+	credentialsPath, err := CredentialsFilePath()
+	if err != nil {
+		return nil, err
+	}
+	return removeMarkedSections(credentialsPath, "credentials", dryRun, func(name string) string { return name })
+}
+
+// removeMarkedSections removes the sections of an INI file with ark's managed comment above their header
+func removeMarkedSections(path, kind string, dryRun bool, displayName func(string) string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s file: %w", kind, err)
+	}
+
+	doc := parseINIDocument(string(data))
+	var marked []string
+	for _, section := range doc.sections {
+		if section.hasComment(arkManagedComment) {
+			marked = append(marked, section.name)
+		}
+	}
+	if len(marked) == 0 || dryRun {
+		return displayNames(marked, displayName), nil
+	}
+
+	for _, name := range marked {
+		doc.remove(name)
+	}
+	if err := writeFileAtomic(path, []byte(doc.String()), 0600); err != nil {
+		return nil, fmt.Errorf("failed to write %s file: %w", kind, err)
+	}
+	logs.GetLogger().Infow("Removed ark sections", "kind", kind, "sections", marked, "path", path)
+	return displayNames(marked, displayName), nil
+}
+
+// displayNames maps section names for display, sorted
+func displayNames(sections []string, displayName func(string) string) []string {
+	names := make([]string, 0, len(sections))
+	for _, section := range sections {
+		names = append(names, displayName(section))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CachedTokenFiles lists the SSO tokens and client registrations ark cached in ~/.aws/sso/cache, sorted. That is every
+// ark-* file, and the AWS CLI file of the start URLs ark signed in to, which ark writes for the SDKs; tokens of portals
+// ark never signed in to are left out
+func CachedTokenFiles() ([]string, error) {
+	cacheDir, err := SSOCacheDir()
+	if err != nil {
+		return nil, err
+	}
+
+	arkFiles, err := filepath.Glob(filepath.Join(cacheDir, "ark-*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]bool, len(arkFiles))
+	for _, path := range arkFiles {
+		files[path] = true
+		// Client registrations don't hold a start URL, only tokens do
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var token CachedToken
+		if json.Unmarshal(data, &token) != nil || token.StartURL == "" {
+			continue
+		}
+		shared := filepath.Join(cacheDir, generateCacheFileName(token.StartURL))
+		if _, err := os.Stat(shared); err == nil {
+			files[shared] = true
+		}
+	}
+
+	listed := make([]string, 0, len(files))
+	for path := range files {
+		listed = append(listed, path)
+	}
+	sort.Strings(listed)
+	return listed, nil
+}
+
+// RemoveCachedTokens removes the files of CachedTokenFiles, returning the paths removed
+func RemoveCachedTokens(dryRun bool) ([]string, error) {
+	removed, err := CachedTokenFiles()
+	if err != nil {
+		return nil, err
+	}
+	if dryRun {
+		return removed, nil
+	}
+
+	for _, path := range removed {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to remove cached token: %w", err)
+		}
+	}
+	if len(removed) > 0 {
+		logs.GetLogger().Infow("Removed cached SSO tokens", "files", len(removed), "path", filepath.Dir(removed[0]))
+	}
+	return removed, nil
+}
@@ -0,0 +1,84 @@
+package services_aws
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoveArkProfiles(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config")
+	t.Setenv(ConfigFileEnv, configPath)
+
+	original := "[default]\nregion = eu-west-1\n\n" +
+		"[profile personal]\nregion = us-east-1\n\n" +
+		"# prod access\n[profile prod-readonly]\nsso_account_id = 222222222222\n"
+	require.NoError(t, os.WriteFile(configPath, []byte(original), 0600))
+	client := &SSOClient{Region: "us-east-1", StartURL: "https://example.awsapps.com/start"}
+	_, err := client.AddProfile("dev-readonly", AWSProfile{AccountID: "111111111111", RoleName: "ReadOnly"})
+	require.NoError(t, err)
+
+	removed, err := RemoveArkProfiles(true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dev-readonly"}, removed, "only profiles ark wrote are listed")
+	data, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "[profile dev-readonly]", "a dry run changes nothing")
+
+	removed, err = RemoveArkProfiles(false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dev-readonly"}, removed)
+	data, err = os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, parseINIFile(original), parseINIFile(string(data)))
+	assert.Contains(t, string(data), "# prod access\n[profile prod-readonly]", "the other sections keep their comments")
+}
+
+func TestRemoveArkCredentials(t *testing.T) {
+	credentialsPath := filepath.Join(t.TempDir(), "credentials")
+	t.Setenv(CredentialsFileEnv, credentialsPath)
+	require.NoError(t, os.WriteFile(credentialsPath, []byte("[static]\naws_access_key_id = AKIASTATIC\n"), 0600))
+
+	creds := &Credentials{AccessKeyID: "AKIANEW", SecretAccessKey: "new", SessionToken: "token", Expiration: time.Now().Add(time.Hour).UnixMilli()}
+	require.NoError(t, WriteCredentialsFile("dev", creds, true))
+
+	removed, err := RemoveArkCredentials(false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"default", "dev"}, removed)
+	data, err := os.ReadFile(credentialsPath)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]map[string]string{"static": {"aws_access_key_id": "AKIASTATIC"}}, parseINIFile(string(data)))
+
+	t.Setenv(CredentialsFileEnv, filepath.Join(t.TempDir(), "missing"))
+	removed, err = RemoveArkCredentials(false)
+	require.NoError(t, err)
+	assert.Empty(t, removed)
+}
+
+func TestRemoveCachedTokens(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	cacheDir := filepath.Join(home, ".aws", "sso", "cache")
+
+	client := &SSOClient{Region: "us-east-1", StartURL: "https://example.awsapps.com/start"}
+	require.NoError(t, client.SaveTokenToCache(&TokenResponse{AccessToken: "token", ExpiresIn: 3600}))
+	require.NoError(t, client.SaveClientRegistration(&ClientRegistration{ClientID: "id", ClientSecret: "secret", ExpiresAt: time.Now().Add(time.Hour).Unix()}))
+	other := filepath.Join(cacheDir, generateCacheFileName("https://other.awsapps.com/start"))
+	require.NoError(t, os.WriteFile(other, []byte(`{"startUrl":"https://other.awsapps.com/start"}`), 0600))
+
+	removed, err := RemoveCachedTokens(false)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		filepath.Join(cacheDir, tokenCacheFileName(client.StartURL, client.Region)),
+		filepath.Join(cacheDir, generateCacheFileName(client.StartURL)),
+		filepath.Join(cacheDir, clientRegistrationFileName(client.Region)),
+	}, removed)
+
+	remaining, err := filepath.Glob(filepath.Join(cacheDir, "*"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{other}, remaining, "tokens of portals ark never signed in to are kept")
+}
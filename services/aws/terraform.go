@@ -0,0 +1,121 @@
+package services_aws
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// TerraformProvider is an aliased aws provider of Terraform or OpenTofu, signing in with a profile
+type TerraformProvider struct {
+	Alias       string
+	Profile     string
+	Region      string
+	AccountID   string
+	AccountName string
+}
+
+// TerraformProviders picks one profile per account matching accounts (globs of account IDs or of the names cached
+// by `ark aws sso`) and roles (globs of role names), and returns a provider for each, sorted by alias.
+// An account left with several profiles is an error, since the role its resources are managed with must be chosen
+func TerraformProviders(profiles []ProfileConfig, accounts, roles []string, region string) ([]TerraformProvider, error) {
+	for _, pattern := range slices.Concat(accounts, roles) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid filter pattern %q: %w", pattern, err)
+		}
+	}
+
+	byAccount := make(map[string][]ProfileConfig)
+	var accountIDs []string
+	for _, profile := range profiles {
+		accountID, roleName := ProfileAccountAndRole(profile)
+		if accountID == "" {
+			continue
+		}
+		accountName := cachedAccountName(profile.StartURL, accountID)
+		if len(accounts) > 0 && !matchesAnyGlobFold(accountID, accounts) && !matchesAnyGlobFold(accountName, accounts) {
+			continue
+		}
+		if len(roles) > 0 && !matchesAnyGlobFold(roleName, roles) {
+			continue
+		}
+		if _, ok := byAccount[accountID]; !ok {
+			accountIDs = append(accountIDs, accountID)
+		}
+		byAccount[accountID] = append(byAccount[accountID], profile)
+	}
+
+	providers := make([]TerraformProvider, 0, len(accountIDs))
+	for _, accountID := range accountIDs {
+		candidates := byAccount[accountID]
+		if len(candidates) > 1 {
+			names := make([]string, len(candidates))
+			for i, candidate := range candidates {
+				names[i] = candidate.ProfileName
+			}
+			slices.Sort(names)
+			return nil, fmt.Errorf("account %s has %d matching profiles (%s): pick one role with --role", accountID, len(names), strings.Join(names, ", "))
+		}
+
+		profile := candidates[0]
+		provider := TerraformProvider{
+			Alias:       terraformIdentifier(profile.ProfileName),
+			Profile:     profile.ProfileName,
+			Region:      region,
+			AccountID:   accountID,
+			AccountName: cachedAccountName(profile.StartURL, accountID),
+		}
+		if provider.Region == "" {
+			provider.Region = profile.Region
+		}
+		if provider.Region == "" {
+			provider.Region = defaultRegion
+		}
+		providers = append(providers, provider)
+	}
+
+	slices.SortFunc(providers, func(a, b TerraformProvider) int { return strings.Compare(a.Alias, b.Alias) })
+	return providers, nil
+}
+
+// terraformIdentifier turns a profile name into a valid alias: letters, digits, underscores and dashes,
+// not starting with a digit
+func terraformIdentifier(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	identifier := b.String()
+	if identifier == "" || identifier[0] >= '0' && identifier[0] <= '9' || identifier[0] == '-' {
+		identifier = "_" + identifier
+	}
+	return identifier
+}
+
+// RenderTerraformProviders writes the provider blocks as HCL. allowed_account_ids makes Terraform refuse to run
+// when the profile signs in to another account than the one the provider was generated for
+func RenderTerraformProviders(providers []TerraformProvider) string {
+	var b strings.Builder
+	b.WriteString("# Generated by `ark terraform providers` from the profiles of the AWS config\n")
+	for _, provider := range providers {
+		b.WriteString("\n")
+		if provider.AccountName != "" {
+			fmt.Fprintf(&b, "# %s (%s)\n", provider.AccountName, provider.AccountID)
+		} else {
+			fmt.Fprintf(&b, "# %s\n", provider.AccountID)
+		}
+		b.WriteString("provider \"aws\" {\n")
+		fmt.Fprintf(&b, "  alias               = %q\n", provider.Alias)
+		fmt.Fprintf(&b, "  profile             = %q\n", provider.Profile)
+		fmt.Fprintf(&b, "  region              = %q\n", provider.Region)
+		fmt.Fprintf(&b, "  allowed_account_ids = [%q]\n", provider.AccountID)
+		b.WriteString("}\n")
+	}
+	return b.String()
+}
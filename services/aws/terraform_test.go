@@ -0,0 +1,59 @@
+package services_aws
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTerraformProviders(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	profiles := []ProfileConfig{
+		{ProfileName: "payments-prod-admin", ProfileType: ProfileTypeSSO, AccountID: "111111111111", RoleName: "AdministratorAccess", Region: "eu-west-1"},
+		{ProfileName: "payments-prod-readonly", ProfileType: ProfileTypeSSO, AccountID: "111111111111", RoleName: "ReadOnlyAccess"},
+		{ProfileName: "shared.deploy", ProfileType: ProfileTypeAssumeRole, RoleARN: "arn:aws:iam::222222222222:role/AdministratorAccess"},
+		{ProfileName: "sandbox-admin", ProfileType: ProfileTypeSSO, AccountID: "333333333333", RoleName: "AdministratorAccess"},
+	}
+
+	providers, err := TerraformProviders(profiles, []string{"111111111111", "2222*"}, []string{"admin*"}, "")
+	require.NoError(t, err)
+	assert.Equal(t, []TerraformProvider{
+		{Alias: "payments-prod-admin", Profile: "payments-prod-admin", Region: "eu-west-1", AccountID: "111111111111"},
+		{Alias: "shared_deploy", Profile: "shared.deploy", Region: "us-east-1", AccountID: "222222222222"},
+	}, providers)
+
+	// --region wins over the region of the profile
+	providers, err = TerraformProviders(profiles, []string{"333333333333"}, nil, "us-west-2")
+	require.NoError(t, err)
+	require.Len(t, providers, 1)
+	assert.Equal(t, "us-west-2", providers[0].Region)
+
+	_, err = TerraformProviders(profiles, []string{"111111111111"}, nil, "")
+	assert.EqualError(t, err, "account 111111111111 has 2 matching profiles (payments-prod-admin, payments-prod-readonly): pick one role with --role")
+
+	_, err = TerraformProviders(profiles, []string{"["}, nil, "")
+	assert.ErrorContains(t, err, `invalid filter pattern "["`)
+}
+
+func TestTerraformIdentifier(t *testing.T) {
+	assert.Equal(t, "prod-admin", terraformIdentifier("prod-admin"))
+	assert.Equal(t, "shared_deploy", terraformIdentifier("shared.deploy"))
+	assert.Equal(t, "_123-admin", terraformIdentifier("123-admin"))
+}
+
+func TestRenderTerraformProviders(t *testing.T) {
+	rendered := RenderTerraformProviders([]TerraformProvider{
+		{Alias: "payments-prod-admin", Profile: "payments-prod-admin", Region: "eu-west-1", AccountID: "111111111111", AccountName: "payments-prod"},
+	})
+	assert.Equal(t, `# Generated by `+"`ark terraform providers`"+` from the profiles of the AWS config
+
+# payments-prod (111111111111)
+provider "aws" {
+  alias               = "payments-prod-admin"
+  profile             = "payments-prod-admin"
+  region              = "eu-west-1"
+  allowed_account_ids = ["111111111111"]
+}
+`, rendered)
+}
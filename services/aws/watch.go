@@ -0,0 +1,102 @@
+package services_aws
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/andresgarcia29/ark-cli/logs"
+)
+
+// configWatchDebounce groups the burst of events editors and WriteConfigFile produce for a single save
+const configWatchDebounce = 200 * time.Millisecond
+
+// WatchConfig calls onChange whenever the AWS config file, custom_config or a snippet in ark.d is written,
+// created, renamed or removed, until ctx is done
+// Directories are watched because editors usually replace files instead of writing them
+func WatchConfig(ctx context.Context, onChange func()) error {
+	configPath, err := ConfigFilePath()
+	if err != nil {
+		return err
+	}
+	customConfigPath, err := customConfigFilePath()
+	if err != nil {
+		return err
+	}
+	includeDir, err := configIncludeDir()
+	if err != nil {
+		return err
+	}
+
+	dirs := []string{filepath.Dir(configPath)}
+	if info, err := os.Stat(includeDir); err == nil && info.IsDir() {
+		dirs = append(dirs, includeDir)
+	}
+
+	match := func(path string) bool {
+		switch {
+		case path == filepath.Clean(configPath), path == filepath.Clean(customConfigPath):
+			return true
+		case filepath.Dir(path) == filepath.Clean(includeDir):
+			return filepath.Ext(path) == configIncludeExt
+		default:
+			return false
+		}
+	}
+	return watchPaths(ctx, dirs, match, configWatchDebounce, onChange)
+}
+
+// watchPaths calls onChange, at most once per debounce window, when a file of dirs accepted by match changes
+func watchPaths(ctx context.Context, dirs []string, match func(path string) bool, debounce time.Duration, onChange func()) error {
+	logger := logs.GetLogger()
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create file watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	for _, dir := range dirs {
+		if err := watcher.Add(dir); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", dir, err)
+		}
+	}
+
+	var timer *time.Timer
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if !match(filepath.Clean(event.Name)) || event.Op == fsnotify.Chmod {
+				continue
+			}
+			logger.Debugw("AWS config changed", "path", event.Name, "op", event.Op.String())
+
+			if timer != nil {
+				timer.Stop()
+			}
+			timer = time.AfterFunc(debounce, onChange)
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			logger.Warnw("Error watching AWS config", "error", err)
+		}
+	}
+}
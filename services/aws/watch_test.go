@@ -0,0 +1,84 @@
+package services_aws
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchPaths(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config")
+	require.NoError(t, os.WriteFile(configPath, []byte("[profile a]\n"), 0600))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var changes atomic.Int32
+	done := make(chan error, 1)
+	go func() {
+		match := func(path string) bool { return path == configPath }
+		done <- watchPaths(ctx, []string{dir}, match, 50*time.Millisecond, func() { changes.Add(1) })
+	}()
+	// Give the watcher time to register the directory
+	time.Sleep(100 * time.Millisecond)
+
+	// Unrelated files in the same directory are ignored
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "credentials"), []byte("x"), 0600))
+	time.Sleep(150 * time.Millisecond)
+	assert.Equal(t, int32(0), changes.Load())
+
+	// A burst of writes, including a replace through rename, is reported once
+	require.NoError(t, os.WriteFile(configPath, []byte("[profile b]\n"), 0600))
+	tmpPath := filepath.Join(dir, "config.tmp")
+	require.NoError(t, os.WriteFile(tmpPath, []byte("[profile c]\n"), 0600))
+	require.NoError(t, os.Rename(tmpPath, configPath))
+	assert.Eventually(t, func() bool { return changes.Load() == 1 }, 2*time.Second, 10*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, int32(1), changes.Load())
+
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+func TestWatchPathsMissingDirectory(t *testing.T) {
+	err := watchPaths(context.Background(), []string{filepath.Join(t.TempDir(), "missing")}, func(string) bool { return true }, time.Millisecond, func() {})
+	assert.Error(t, err)
+}
+
+func TestWatchConfigIncludeDir(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(ConfigFileEnv, filepath.Join(dir, "config"))
+	includeDir := filepath.Join(dir, configIncludeDirName)
+	require.NoError(t, os.MkdirAll(includeDir, 0700))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changed := make(chan struct{}, 1)
+	go WatchConfig(ctx, func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	})
+	time.Sleep(100 * time.Millisecond)
+
+	// Files without the .conf extension are not merged, so they don't trigger a reload
+	require.NoError(t, os.WriteFile(filepath.Join(includeDir, "README"), []byte("x"), 0600))
+	select {
+	case <-changed:
+		t.Fatal("unexpected reload for a non .conf file")
+	case <-time.After(2 * configWatchDebounce):
+	}
+
+	require.NoError(t, os.WriteFile(filepath.Join(includeDir, "10-platform.conf"), []byte("[profile a]\n"), 0600))
+	select {
+	case <-changed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("no reload after a snippet was added to ark.d")
+	}
+}
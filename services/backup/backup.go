@@ -0,0 +1,319 @@
+package services_backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/andresgarcia29/ark-cli/paths"
+)
+
+// KeyEnvVar provides the key of encrypted backups as base64, 32 bytes kept somewhere both machines can read
+const KeyEnvVar = "ARK_BACKUP_KEY"
+
+// header starts every encrypted backup
+var header = []byte("ark-encrypted-backup:v1\n")
+
+// additionalData is authenticated with every encrypted backup, so other ark files sealed with the same key can't
+// be restored as one
+var additionalData = []byte("ark-backup")
+
+// The sections of a backup, the top directory of each file in the archive
+const (
+	SectionConfig  = "config"
+	SectionState   = "state"
+	SectionHistory = "history"
+	SectionTokens  = "tokens"
+)
+
+// Locations are where the files of a backup are read from, and restored to
+type Locations struct {
+	// Dirs are ark's directories; the config and state directories are backed up, not the cache and logs
+	Dirs paths.Dirs
+	// AuditLog is the audit log, the history of the credentials ark issued
+	AuditLog string
+	// SSOCache is ~/.aws/sso/cache, where SSO tokens are restored
+	SSOCache string
+}
+
+// File is a file of a backup: its name in the archive, e.g. config/config.yaml, and its contents
+type File struct {
+	Name string
+	Data []byte
+}
+
+// Key returns the key of ARK_BACKUP_KEY, nil when it is not set
+func Key() ([]byte, error) {
+	encoded := os.Getenv(KeyEnvVar)
+	if encoded == "" {
+		return nil, nil
+	}
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(key) != 32 {
+		return nil, fmt.Errorf("%s must be 32 bytes encoded as base64 (e.g. openssl rand -base64 32)", KeyEnvVar)
+	}
+	return key, nil
+}
+
+// Collect reads the files to back up: ark's config and state directories, the audit log, and tokenFiles
+// Logs and lock files are left out, as is anything of another ark directory nested in the legacy ~/.ark
+func Collect(loc Locations, tokenFiles []string) ([]File, error) {
+	var files []File
+	others := []string{loc.Dirs.Cache, loc.Dirs.Logs}
+	for _, section := range []struct {
+		name string
+		dir  string
+	}{
+		{SectionConfig, loc.Dirs.Config},
+		{SectionState, loc.Dirs.State},
+	} {
+		found, err := collectDir(section.name, section.dir, append(others, loc.Dirs.Config, loc.Dirs.State))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, found...)
+	}
+
+	if loc.AuditLog != "" {
+		data, err := os.ReadFile(loc.AuditLog)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("failed to read the audit log: %w", err)
+		}
+		if err == nil {
+			files = append(files, File{Name: path.Join(SectionHistory, filepath.Base(loc.AuditLog)), Data: data})
+		}
+	}
+
+	for _, tokenFile := range tokenFiles {
+		data, err := os.ReadFile(tokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read cached token: %w", err)
+		}
+		files = append(files, File{Name: path.Join(SectionTokens, filepath.Base(tokenFile)), Data: data})
+	}
+	return files, nil
+}
+
+// collectDir reads the files under dir as section/<relative path>, skipping the directories of skip other than dir
+func collectDir(section, dir string, skip []string) ([]File, error) {
+	var files []File
+	err := filepath.WalkDir(dir, func(file string, entry fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) && file == dir {
+			return filepath.SkipDir
+		}
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if file != dir && slices.Contains(skip, file) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() || strings.HasSuffix(file, ".log") || strings.HasSuffix(file, ".lock") {
+			return nil
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		files = append(files, File{Name: path.Join(section, filepath.ToSlash(rel)), Data: data})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+	return files, nil
+}
+
+// Pack writes the files as a gzipped tar, sealed with AES-256-GCM under key unless it is nil
+func Pack(files []File, key []byte, now time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, file := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: file.Name, Mode: 0600, Size: int64(len(file.Data)), ModTime: now}); err != nil {
+			return nil, err
+		}
+		if _, err := tw.Write(file.Data); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return buf.Bytes(), nil
+	}
+
+	aead, err := backupCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := aead.Seal(nonce, nonce, buf.Bytes(), additionalData)
+	return append(append([]byte(nil), header...), sealed...), nil
+}
+
+// IsEncrypted reports whether data is an encrypted backup
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, header)
+}
+
+// Unpack reads the files of a backup written by Pack, decrypting it with key when it is encrypted
+// Names that would be restored outside their section are refused
+func Unpack(data, key []byte) ([]File, error) {
+	if IsEncrypted(data) {
+		if key == nil {
+			return nil, fmt.Errorf("the backup is encrypted: set %s to the key it was exported with", KeyEnvVar)
+		}
+		aead, err := backupCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		sealed := data[len(header):]
+		if len(sealed) < aead.NonceSize() {
+			return nil, errors.New("the encrypted backup is truncated")
+		}
+		if data, err = aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], additionalData); err != nil {
+			return nil, fmt.Errorf("failed to decrypt the backup (is %s the key it was exported with?): %w", KeyEnvVar, err)
+		}
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("not an ark backup: %w", err)
+	}
+	tr := tar.NewReader(gz)
+	var files []File
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the backup: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := checkName(hdr.Name); err != nil {
+			return nil, err
+		}
+		contents, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s from the backup: %w", hdr.Name, err)
+		}
+		files = append(files, File{Name: hdr.Name, Data: contents})
+	}
+	return files, nil
+}
+
+// checkName refuses names outside the known sections, or leaving them
+func checkName(name string) error {
+	section, rel, _ := strings.Cut(name, "/")
+	valid := filepath.IsLocal(filepath.FromSlash(rel)) && path.Clean(rel) == rel && rel != "."
+	switch section {
+	case SectionConfig, SectionState:
+	case SectionHistory, SectionTokens:
+		valid = valid && !strings.Contains(rel, "/")
+	default:
+		valid = false
+	}
+	if !valid {
+		return fmt.Errorf("the backup has an unexpected file %q; it was not restored", name)
+	}
+	return nil
+}
+
+// Restore is where a file of a backup is written
+type Restore struct {
+	File File
+	Path string
+	// Replaces is set when a different file is at Path; Unchanged when the same one is
+	Replaces  bool
+	Unchanged bool
+}
+
+// Plan maps the files of a backup to where they are restored, noting which replace existing files
+func Plan(files []File, loc Locations) ([]Restore, error) {
+	restores := make([]Restore, 0, len(files))
+	for _, file := range files {
+		section, rel, _ := strings.Cut(file.Name, "/")
+		var target string
+		switch section {
+		case SectionConfig:
+			target = filepath.Join(loc.Dirs.Config, filepath.FromSlash(rel))
+		case SectionState:
+			target = filepath.Join(loc.Dirs.State, filepath.FromSlash(rel))
+		case SectionHistory:
+			target = loc.AuditLog
+		case SectionTokens:
+			target = filepath.Join(loc.SSOCache, rel)
+		}
+		if target == "" {
+			return nil, fmt.Errorf("no location to restore %s to", file.Name)
+		}
+
+		restore := Restore{File: file, Path: target}
+		existing, err := os.ReadFile(target)
+		switch {
+		case err == nil:
+			restore.Unchanged = bytes.Equal(existing, file.Data)
+			restore.Replaces = !restore.Unchanged
+		case !errors.Is(err, fs.ErrNotExist):
+			return nil, fmt.Errorf("failed to read %s: %w", target, err)
+		}
+		restores = append(restores, restore)
+	}
+	return restores, nil
+}
+
+// Apply writes the files of a plan, readable by the user only since they may hold tokens and webhooks
+func Apply(restores []Restore) error {
+	for _, restore := range restores {
+		if restore.Unchanged {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(restore.Path), 0700); err != nil {
+			return fmt.Errorf("failed to create %s: %w", filepath.Dir(restore.Path), err)
+		}
+		if err := os.WriteFile(restore.Path, restore.File.Data, 0600); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", restore.Path, err)
+		}
+	}
+	return nil
+}
+
+// backupCipher returns the AES-GCM cipher of a key
+func backupCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid backup key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
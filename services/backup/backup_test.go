@@ -0,0 +1,172 @@
+package services_backup
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/andresgarcia29/ark-cli/paths"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testLocations lays out ark's directories like on Linux, where logs live in the state directory
+func testLocations(t *testing.T) Locations {
+	t.Helper()
+	home := t.TempDir()
+	state := filepath.Join(home, "state", "ark")
+	return Locations{
+		Dirs: paths.Dirs{
+			Config: filepath.Join(home, "config", "ark"),
+			Cache:  filepath.Join(home, "cache", "ark"),
+			State:  state,
+			Logs:   state,
+		},
+		AuditLog: filepath.Join(state, "audit.log"),
+		SSOCache: filepath.Join(home, ".aws", "sso", "cache"),
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+}
+
+func TestCollect(t *testing.T) {
+	loc := testLocations(t)
+	writeFile(t, filepath.Join(loc.Dirs.Config, "config.yaml"), "aws:\n  max_attempts: 8\n")
+	writeFile(t, filepath.Join(loc.Dirs.Config, "cluster_profiles.yaml"), "prod: prod-admin\n")
+	writeFile(t, filepath.Join(loc.Dirs.State, "kubernetes-previous-context"), "staging\n")
+	writeFile(t, filepath.Join(loc.Dirs.State, "ark.log"), "debug output\n")
+	writeFile(t, loc.AuditLog, `{"event":"login"}`+"\n")
+	writeFile(t, filepath.Join(loc.Dirs.Cache, "profiles.json"), "{}")
+	token := filepath.Join(loc.SSOCache, "ark-token.json")
+	writeFile(t, token, `{"accessToken":"secret"}`)
+
+	files, err := Collect(loc, []string{token})
+	require.NoError(t, err)
+	names := make([]string, len(files))
+	for i, file := range files {
+		names[i] = file.Name
+	}
+	assert.Equal(t, []string{
+		"config/cluster_profiles.yaml",
+		"config/config.yaml",
+		"state/kubernetes-previous-context",
+		"history/audit.log",
+		"tokens/ark-token.json",
+	}, names, "logs and caches are left out")
+
+	// Nothing set up yet is not an error
+	files, err = Collect(testLocations(t), nil)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestCollectLegacyDir(t *testing.T) {
+	legacy := filepath.Join(t.TempDir(), ".ark")
+	loc := Locations{Dirs: paths.Dirs{
+		Config: legacy,
+		Cache:  filepath.Join(legacy, "cache"),
+		State:  filepath.Join(legacy, "state"),
+		Logs:   legacy,
+	}}
+	writeFile(t, filepath.Join(legacy, "config.yaml"), "audit:\n  enabled: true\n")
+	writeFile(t, filepath.Join(legacy, "cache", "profiles.json"), "{}")
+	writeFile(t, filepath.Join(legacy, "state", "kubernetes-previous-context"), "staging\n")
+
+	files, err := Collect(loc, nil)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	assert.Equal(t, "config/config.yaml", files[0].Name)
+	assert.Equal(t, "state/kubernetes-previous-context", files[1].Name, "nested directories are only backed up once")
+}
+
+func TestPackUnpack(t *testing.T) {
+	files := []File{
+		{Name: "config/config.yaml", Data: []byte("aws:\n  max_attempts: 8\n")},
+		{Name: "tokens/ark-token.json", Data: []byte(`{"accessToken":"secret"}`)},
+	}
+
+	plain, err := Pack(files, nil, time.Now())
+	require.NoError(t, err)
+	assert.False(t, IsEncrypted(plain))
+	unpacked, err := Unpack(plain, nil)
+	require.NoError(t, err)
+	assert.Equal(t, files, unpacked)
+
+	key := make([]byte, 32)
+	key[0] = 1
+	sealed, err := Pack(files, key, time.Now())
+	require.NoError(t, err)
+	assert.True(t, IsEncrypted(sealed))
+	assert.NotContains(t, string(sealed), "secret")
+
+	unpacked, err = Unpack(sealed, key)
+	require.NoError(t, err)
+	assert.Equal(t, files, unpacked)
+
+	_, err = Unpack(sealed, nil)
+	assert.ErrorContains(t, err, "the backup is encrypted: set ARK_BACKUP_KEY")
+	_, err = Unpack(sealed, make([]byte, 32))
+	assert.ErrorContains(t, err, "failed to decrypt the backup")
+	_, err = Unpack([]byte("not a backup"), nil)
+	assert.ErrorContains(t, err, "not an ark backup")
+}
+
+func TestUnpackRefusesUnexpectedNames(t *testing.T) {
+	for _, name := range []string{"config/../../.bashrc", "tokens/nested/ark.json", "other/file", "config/.", "/etc/passwd"} {
+		data, err := Pack([]File{{Name: name, Data: []byte("x")}}, nil, time.Now())
+		require.NoError(t, err)
+		_, err = Unpack(data, nil)
+		assert.ErrorContains(t, err, "unexpected file", name)
+	}
+}
+
+func TestPlanApply(t *testing.T) {
+	loc := testLocations(t)
+	writeFile(t, filepath.Join(loc.Dirs.Config, "config.yaml"), "aws:\n  max_attempts: 3\n")
+	writeFile(t, filepath.Join(loc.Dirs.State, "kubernetes-previous-context"), "staging\n")
+
+	restores, err := Plan([]File{
+		{Name: "config/config.yaml", Data: []byte("aws:\n  max_attempts: 8\n")},
+		{Name: "state/kubernetes-previous-context", Data: []byte("staging\n")},
+		{Name: "history/audit.log", Data: []byte(`{"event":"login"}` + "\n")},
+		{Name: "tokens/ark-token.json", Data: []byte(`{"accessToken":"secret"}`)},
+	}, loc)
+	require.NoError(t, err)
+	require.Len(t, restores, 4)
+	assert.Equal(t, filepath.Join(loc.Dirs.Config, "config.yaml"), restores[0].Path)
+	assert.True(t, restores[0].Replaces)
+	assert.True(t, restores[1].Unchanged)
+	assert.Equal(t, loc.AuditLog, restores[2].Path)
+	assert.Equal(t, filepath.Join(loc.SSOCache, "ark-token.json"), restores[3].Path)
+	assert.False(t, restores[3].Replaces || restores[3].Unchanged)
+
+	require.NoError(t, Apply(restores))
+	data, err := os.ReadFile(filepath.Join(loc.Dirs.Config, "config.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "aws:\n  max_attempts: 8\n", string(data))
+	data, err = os.ReadFile(filepath.Join(loc.SSOCache, "ark-token.json"))
+	require.NoError(t, err)
+	assert.Equal(t, `{"accessToken":"secret"}`, string(data))
+}
+
+func TestKey(t *testing.T) {
+	t.Setenv(KeyEnvVar, "")
+	key, err := Key()
+	require.NoError(t, err)
+	assert.Nil(t, key)
+
+	t.Setenv(KeyEnvVar, base64.StdEncoding.EncodeToString(make([]byte, 32)))
+	key, err = Key()
+	require.NoError(t, err)
+	assert.Len(t, key, 32)
+
+	t.Setenv(KeyEnvVar, "c2hvcnQ=")
+	_, err = Key()
+	assert.ErrorContains(t, err, "ARK_BACKUP_KEY must be 32 bytes")
+}
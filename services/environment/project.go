@@ -0,0 +1,142 @@
+package services_environment
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/andresgarcia29/ark-cli/logs"
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectFileNames lists the file names searched for a directory-based profile, in priority order
+var ProjectFileNames = []string{".ark.yaml", ".ark"}
+
+// ProjectConfig represents the environment declared by a .ark file in a project directory
+type ProjectConfig struct {
+	Profile    string `yaml:"profile"`
+	Region     string `yaml:"region"`
+	Cluster    string `yaml:"cluster"`
+	Kubeconfig string `yaml:"kubeconfig"`
+	// Path is the absolute path of the file the configuration was loaded from
+	Path string `yaml:"-"`
+	// Hash identifies the contents the configuration was loaded from, to check they were allowed
+	Hash string `yaml:"-"`
+}
+
+// EnvVar represents a single environment variable exported for a project
+type EnvVar struct {
+	Name  string
+	Value string
+}
+
+// ManagedEnvVars lists every variable ark may export, so they can be restored when leaving a project
+var ManagedEnvVars = []string{"AWS_PROFILE", "AWS_REGION", "AWS_DEFAULT_REGION", "KUBECONFIG", "ARK_CLUSTER", "ARK_ENV_FILE"}
+
+// FindProjectFile walks from startDir up to the filesystem root looking for a .ark file
+// It returns an empty string when no file is found
+func FindProjectFile(startDir string) (string, error) {
+	logger := logs.GetLogger()
+
+	dir, err := filepath.Abs(startDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve directory %s: %w", startDir, err)
+	}
+
+	for {
+		for _, name := range ProjectFileNames {
+			candidate := filepath.Join(dir, name)
+			if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+				logger.Debugw("Project file found", "path", candidate)
+				return candidate, nil
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	logger.Debugw("No project file found", "start_dir", startDir)
+	return "", nil
+}
+
+// LoadProjectFile reads and validates a .ark file
+func LoadProjectFile(path string) (*ProjectConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read project file: %w", err)
+	}
+
+	var project ProjectConfig
+	if err := yaml.Unmarshal(data, &project); err != nil {
+		return nil, fmt.Errorf("failed to parse project file %s: %w", path, err)
+	}
+
+	if project.Profile == "" && project.Region == "" && project.Cluster == "" && project.Kubeconfig == "" {
+		return nil, fmt.Errorf("project file %s does not declare profile, region, cluster or kubeconfig", path)
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve project file path: %w", err)
+	}
+	project.Path = absPath
+	project.Hash = hashProjectFile(data)
+
+	// Relative kubeconfig paths are resolved against the project directory
+	if project.Kubeconfig != "" {
+		project.Kubeconfig = resolvePath(project.Kubeconfig, filepath.Dir(absPath))
+	}
+
+	return &project, nil
+}
+
+// LoadProjectForDir finds and loads the .ark file that applies to dir
+// It returns nil without error when the directory is not inside a project
+func LoadProjectForDir(dir string) (*ProjectConfig, error) {
+	path, err := FindProjectFile(dir)
+	if err != nil {
+		return nil, err
+	}
+	if path == "" {
+		return nil, nil
+	}
+	return LoadProjectFile(path)
+}
+
+// EnvVars returns the variables that should be exported for the project
+func (p *ProjectConfig) EnvVars() []EnvVar {
+	var vars []EnvVar
+	if p.Profile != "" {
+		vars = append(vars, EnvVar{Name: "AWS_PROFILE", Value: p.Profile})
+	}
+	if p.Region != "" {
+		vars = append(vars, EnvVar{Name: "AWS_REGION", Value: p.Region})
+		vars = append(vars, EnvVar{Name: "AWS_DEFAULT_REGION", Value: p.Region})
+	}
+	if p.Kubeconfig != "" {
+		vars = append(vars, EnvVar{Name: "KUBECONFIG", Value: p.Kubeconfig})
+	}
+	if p.Cluster != "" {
+		vars = append(vars, EnvVar{Name: "ARK_CLUSTER", Value: p.Cluster})
+	}
+	vars = append(vars, EnvVar{Name: "ARK_ENV_FILE", Value: p.Path})
+	return vars
+}
+
+// resolvePath expands ~ and makes relative paths absolute against baseDir
+func resolvePath(path, baseDir string) string {
+	if strings.HasPrefix(path, "~/") {
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(homeDir, path[2:])
+		}
+	}
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(baseDir, path)
+}
@@ -0,0 +1,145 @@
+package services_environment
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindProjectFile(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "a", "b")
+	require.NoError(t, os.MkdirAll(nested, 0700))
+
+	// No file anywhere in the tree
+	path, err := FindProjectFile(nested)
+	require.NoError(t, err)
+	assert.Empty(t, path)
+
+	// .ark in the root is found from a nested directory
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".ark"), []byte("profile: root\n"), 0600))
+	path, err = FindProjectFile(nested)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, ".ark"), path)
+
+	// .ark.yaml takes priority over .ark in the same directory
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".ark.yaml"), []byte("profile: root\n"), 0600))
+	path, err = FindProjectFile(nested)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, ".ark.yaml"), path)
+
+	// The closest file wins
+	require.NoError(t, os.WriteFile(filepath.Join(root, "a", ".ark"), []byte("profile: a\n"), 0600))
+	path, err = FindProjectFile(nested)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, "a", ".ark"), path)
+}
+
+func TestLoadProjectFile(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		expectError bool
+		validate    func(t *testing.T, dir string, p *ProjectConfig)
+	}{
+		{
+			name:    "full project file",
+			content: "profile: prod-readonly\nregion: us-east-1\ncluster: prod\nkubeconfig: ./kubeconfig\n",
+			validate: func(t *testing.T, dir string, p *ProjectConfig) {
+				assert.Equal(t, "prod-readonly", p.Profile)
+				assert.Equal(t, "us-east-1", p.Region)
+				assert.Equal(t, "prod", p.Cluster)
+				assert.Equal(t, filepath.Join(dir, "kubeconfig"), p.Kubeconfig)
+				assert.Equal(t, filepath.Join(dir, ".ark"), p.Path)
+			},
+		},
+		{
+			name:    "absolute kubeconfig is kept",
+			content: "kubeconfig: /etc/kube/config\n",
+			validate: func(t *testing.T, dir string, p *ProjectConfig) {
+				assert.Equal(t, "/etc/kube/config", p.Kubeconfig)
+			},
+		},
+		{
+			name:        "empty file",
+			content:     "",
+			expectError: true,
+		},
+		{
+			name:        "invalid yaml",
+			content:     "profile: [unterminated\n",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, ".ark")
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0600))
+
+			project, err := LoadProjectFile(path)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			tt.validate(t, dir, project)
+		})
+	}
+}
+
+func TestProjectConfigEnvVars(t *testing.T) {
+	project := &ProjectConfig{
+		Profile: "dev",
+		Region:  "eu-west-1",
+		Cluster: "dev-cluster",
+		Path:    "/work/.ark",
+	}
+
+	vars := project.EnvVars()
+	values := make(map[string]string)
+	for _, v := range vars {
+		values[v.Name] = v.Value
+	}
+
+	assert.Equal(t, "dev", values["AWS_PROFILE"])
+	assert.Equal(t, "eu-west-1", values["AWS_REGION"])
+	assert.Equal(t, "eu-west-1", values["AWS_DEFAULT_REGION"])
+	assert.Equal(t, "dev-cluster", values["ARK_CLUSTER"])
+	assert.Equal(t, "/work/.ark", values["ARK_ENV_FILE"])
+	assert.NotContains(t, values, "KUBECONFIG")
+}
+
+func TestRenderExports(t *testing.T) {
+	vars := []EnvVar{{Name: "AWS_PROFILE", Value: "it's"}}
+
+	bash, err := RenderExports("bash", vars)
+	require.NoError(t, err)
+	assert.Equal(t, "export AWS_PROFILE='it'\\''s'\n", bash)
+
+	fish, err := RenderExports("fish", vars)
+	require.NoError(t, err)
+	assert.Equal(t, "set -gx AWS_PROFILE 'it\\'s'\n", fish)
+
+	_, err = RenderExports("tcsh", vars)
+	assert.Error(t, err)
+}
+
+func TestRenderUnsetsAndHook(t *testing.T) {
+	unsets, err := RenderUnsets("zsh", []string{"AWS_PROFILE"})
+	require.NoError(t, err)
+	assert.Equal(t, "unset AWS_PROFILE\n", unsets)
+
+	for _, shell := range SupportedShells {
+		hook, err := RenderHook(shell)
+		require.NoError(t, err)
+		assert.Contains(t, hook, "ark env --shell "+shell)
+	}
+
+	_, err = RenderHook("powershell")
+	assert.Error(t, err)
+}
@@ -0,0 +1,131 @@
+package services_environment
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// SupportedShells lists the shells for which export statements and hooks can be rendered
+var SupportedShells = []string{"bash", "zsh", "fish"}
+
+// RenderExports renders export statements for the given shell
+func RenderExports(shell string, vars []EnvVar) (string, error) {
+	var s strings.Builder
+	for _, v := range vars {
+		switch shell {
+		case "bash", "zsh":
+			fmt.Fprintf(&s, "export %s=%s\n", v.Name, quote(shell, v.Value))
+		case "fish":
+			fmt.Fprintf(&s, "set -gx %s %s\n", v.Name, quote(shell, v.Value))
+		default:
+			return "", fmt.Errorf("unsupported shell: %s (supported: %s)", shell, strings.Join(SupportedShells, ", "))
+		}
+	}
+	return s.String(), nil
+}
+
+// RenderUnsets renders statements that remove the given variables for the given shell
+func RenderUnsets(shell string, names []string) (string, error) {
+	var s strings.Builder
+	for _, name := range names {
+		switch shell {
+		case "bash", "zsh":
+			fmt.Fprintf(&s, "unset %s\n", name)
+		case "fish":
+			fmt.Fprintf(&s, "set -e %s\n", name)
+		default:
+			return "", fmt.Errorf("unsupported shell: %s (supported: %s)", shell, strings.Join(SupportedShells, ", "))
+		}
+	}
+	return s.String(), nil
+}
+
+// PreviousValuePrefix names the variables keeping the value a managed variable had before ark env exported a
+// project, e.g. _ARK_PREV_AWS_PROFILE
+const PreviousValuePrefix = "_ARK_PREV_"
+
+// RenderSavePrevious renders exports keeping the value of every managed variable that is set, so RenderRestore can
+// put it back when the shell leaves the project
+func RenderSavePrevious(shell string, lookupEnv func(string) (string, bool)) (string, error) {
+	var saved []EnvVar
+	for _, name := range ManagedEnvVars {
+		if value, ok := lookupEnv(name); ok {
+			saved = append(saved, EnvVar{Name: PreviousValuePrefix + name, Value: value})
+		}
+	}
+	return RenderExports(shell, saved)
+}
+
+// RenderRestore renders statements putting the managed variables other than keep back as they were before the
+// project: a saved value is exported again, and a variable that was unset is unset
+// With forget, the saved values are dropped as well, for leaving projects altogether
+func RenderRestore(shell string, keep []string, forget bool, lookupEnv func(string) (string, bool)) (string, error) {
+	var restored []EnvVar
+	var unset []string
+	for _, name := range ManagedEnvVars {
+		if slices.Contains(keep, name) {
+			continue
+		}
+		value, ok := lookupEnv(PreviousValuePrefix + name)
+		if !ok {
+			unset = append(unset, name)
+			continue
+		}
+		restored = append(restored, EnvVar{Name: name, Value: value})
+		if forget {
+			unset = append(unset, PreviousValuePrefix+name)
+		}
+	}
+	exports, err := RenderExports(shell, restored)
+	if err != nil {
+		return "", err
+	}
+	unsets, err := RenderUnsets(shell, unset)
+	if err != nil {
+		return "", err
+	}
+	return exports + unsets, nil
+}
+
+// RenderHook renders a shell hook that re-evaluates `ark env` whenever the directory changes
+func RenderHook(shell string) (string, error) {
+	switch shell {
+	case "bash":
+		return `_ark_env_hook() {
+  if [ "$PWD" != "$_ARK_LAST_PWD" ]; then
+    _ARK_LAST_PWD="$PWD"
+    eval "$(ark env --shell bash)"
+  fi
+}
+if [[ ";${PROMPT_COMMAND:-};" != *";_ark_env_hook;"* ]]; then
+  PROMPT_COMMAND="_ark_env_hook${PROMPT_COMMAND:+;$PROMPT_COMMAND}"
+fi
+`, nil
+	case "zsh":
+		return `_ark_env_hook() {
+  eval "$(ark env --shell zsh)"
+}
+autoload -U add-zsh-hook
+add-zsh-hook chpwd _ark_env_hook
+_ark_env_hook
+`, nil
+	case "fish":
+		return `function _ark_env_hook --on-variable PWD
+  ark env --shell fish | source
+end
+_ark_env_hook
+`, nil
+	default:
+		return "", fmt.Errorf("unsupported shell: %s (supported: %s)", shell, strings.Join(SupportedShells, ", "))
+	}
+}
+
+// quote wraps a value in single quotes, escaping embedded single quotes for the given shell
+func quote(shell, value string) string {
+	if shell == "fish" {
+		value = strings.ReplaceAll(value, `\`, `\\`)
+		return "'" + strings.ReplaceAll(value, "'", `\'`) + "'"
+	}
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
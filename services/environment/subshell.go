@@ -0,0 +1,242 @@
+package services_environment
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/andresgarcia29/ark-cli/lib"
+	"github.com/andresgarcia29/ark-cli/logs"
+)
+
+// ShellLevelEnvVar tracks how many ark shells are nested in the current process tree
+const ShellLevelEnvVar = "ARK_SHELL_LEVEL"
+
+// ShellOptions describes the environment to inject into an ark subshell
+type ShellOptions struct {
+	Shell      string // Path to the shell binary, defaults to $SHELL
+	Profile    string
+	Region     string
+	Cluster    string
+	Kubeconfig string
+}
+
+// ShellSession is a prepared subshell ready to be executed
+type ShellSession struct {
+	Path  string
+	Args  []string
+	Env   []string
+	Level int
+	// tempDir holds the generated rc files and isolated kubeconfig, removed by Cleanup
+	tempDir string
+}
+
+// CurrentShellLevel returns the nesting level of the current ark shell (0 when not inside one)
+func CurrentShellLevel() int {
+	level, err := strconv.Atoi(os.Getenv(ShellLevelEnvVar))
+	if err != nil || level < 0 {
+		return 0
+	}
+	return level
+}
+
+// PrepareShellSession builds the environment, rc files and isolated kubeconfig for a subshell
+func PrepareShellSession(opts ShellOptions) (*ShellSession, error) {
+	logger := logs.GetLogger()
+
+	shellPath := opts.Shell
+	if shellPath == "" {
+		shellPath = os.Getenv("SHELL")
+	}
+	if shellPath == "" {
+		shellPath = "/bin/sh"
+	}
+
+	tempDir, err := os.MkdirTemp("", "ark-shell-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session directory: %w", err)
+	}
+
+	session := &ShellSession{
+		Path:    shellPath,
+		Level:   CurrentShellLevel() + 1,
+		tempDir: tempDir,
+	}
+
+	overrides := map[string]string{
+		ShellLevelEnvVar: strconv.Itoa(session.Level),
+	}
+	if opts.Profile != "" {
+		overrides["AWS_PROFILE"] = opts.Profile
+		overrides["ARK_SHELL_PROFILE"] = opts.Profile
+	}
+	if opts.Region != "" {
+		overrides["AWS_REGION"] = opts.Region
+		overrides["AWS_DEFAULT_REGION"] = opts.Region
+	}
+
+	// The session gets its own kubeconfig copy so switching context inside it never
+	// leaks into other terminals
+	if opts.Cluster != "" || opts.Kubeconfig != "" {
+		kubeconfigPath, err := isolateKubeconfig(tempDir, opts.Kubeconfig, opts.Cluster)
+		if err != nil {
+			session.Cleanup()
+			return nil, err
+		}
+		overrides["KUBECONFIG"] = kubeconfigPath
+		if opts.Cluster != "" {
+			overrides["ARK_SHELL_CLUSTER"] = opts.Cluster
+		}
+	}
+
+	prompt := promptPrefix(opts, session.Level)
+	overrides["ARK_SHELL_PROMPT"] = prompt
+
+	args, extraEnv, err := promptSetup(shellPath, tempDir, prompt)
+	if err != nil {
+		session.Cleanup()
+		return nil, err
+	}
+	session.Args = args
+	for k, v := range extraEnv {
+		overrides[k] = v
+	}
+
+	session.Env = mergeEnv(os.Environ(), overrides)
+
+	logger.Debugw("Shell session prepared", "shell", shellPath, "level", session.Level, "profile", opts.Profile, "cluster", opts.Cluster)
+	return session, nil
+}
+
+// Cleanup removes the temporary files created for the session
+func (s *ShellSession) Cleanup() {
+	if s.tempDir != "" {
+		_ = os.RemoveAll(s.tempDir)
+	}
+}
+
+// promptPrefix builds the prompt marker shown in the subshell
+func promptPrefix(opts ShellOptions, level int) string {
+	var parts []string
+	if opts.Profile != "" {
+		parts = append(parts, opts.Profile)
+	}
+	if opts.Cluster != "" {
+		parts = append(parts, "⎈ "+opts.Cluster)
+	}
+	label := "ark"
+	if len(parts) > 0 {
+		label += ":" + strings.Join(parts, " ")
+	}
+	if level > 1 {
+		label += fmt.Sprintf(" (%d)", level)
+	}
+	return "[" + label + "] "
+}
+
+// promptSetup returns shell arguments and environment that prepend the ark marker to the prompt
+// User rc files are still sourced so aliases and functions keep working
+func promptSetup(shellPath, tempDir, prompt string) ([]string, map[string]string, error) {
+	switch filepath.Base(shellPath) {
+	case "bash":
+		rcPath := filepath.Join(tempDir, "bashrc")
+		rc := `[ -f "$HOME/.bashrc" ] && . "$HOME/.bashrc"
+PS1="$ARK_SHELL_PROMPT$PS1"
+`
+		if err := os.WriteFile(rcPath, []byte(rc), 0600); err != nil {
+			return nil, nil, fmt.Errorf("failed to write shell rc file: %w", err)
+		}
+		return []string{"--rcfile", rcPath, "-i"}, nil, nil
+
+	case "zsh":
+		// zsh reads every startup file from ZDOTDIR, so the session's own files stand in for the user's:
+		// .zshenv runs the user's .zshenv, then .zshrc runs the user's .zprofile and .zshrc before changing the prompt
+		originalDotDir := os.Getenv("ZDOTDIR")
+		if originalDotDir == "" {
+			originalDotDir = os.Getenv("HOME")
+		}
+		env := fmt.Sprintf(`ZDOTDIR=%q
+[ -f "$ZDOTDIR/.zshenv" ] && . "$ZDOTDIR/.zshenv"
+_ark_zdotdir="$ZDOTDIR"
+ZDOTDIR=%q
+`, originalDotDir, tempDir)
+		rc := `ZDOTDIR="$_ark_zdotdir"
+unset _ark_zdotdir
+[ -f "$ZDOTDIR/.zprofile" ] && . "$ZDOTDIR/.zprofile"
+[ -f "$ZDOTDIR/.zshrc" ] && . "$ZDOTDIR/.zshrc"
+PROMPT="$ARK_SHELL_PROMPT$PROMPT"
+`
+		for name, contents := range map[string]string{".zshenv": env, ".zshrc": rc} {
+			if err := os.WriteFile(filepath.Join(tempDir, name), []byte(contents), 0600); err != nil {
+				return nil, nil, fmt.Errorf("failed to write shell rc file: %w", err)
+			}
+		}
+		return []string{"-i"}, map[string]string{"ZDOTDIR": tempDir}, nil
+
+	case "fish":
+		init := `functions -c fish_prompt _ark_original_prompt; function fish_prompt; printf '%s' "$ARK_SHELL_PROMPT"; _ark_original_prompt; end`
+		return []string{"--init-command", init}, nil, nil
+
+	default:
+		return []string{"-i"}, map[string]string{"PS1": prompt + "$ "}, nil
+	}
+}
+
+// isolateKubeconfig writes a flattened copy of the kubeconfig into the session directory
+// and selects the requested context in it
+func isolateKubeconfig(tempDir, sourceKubeconfig, cluster string) (string, error) {
+	logger := logs.GetLogger()
+	target := filepath.Join(tempDir, "kubeconfig")
+
+	viewCmd := exec.Command("kubectl", "config", "view", "--flatten")
+	if sourceKubeconfig != "" {
+		viewCmd.Env = mergeEnv(os.Environ(), map[string]string{"KUBECONFIG": sourceKubeconfig})
+	}
+	var stdout, stderr bytes.Buffer
+	viewCmd.Stdout = &stdout
+	viewCmd.Stderr = &stderr
+
+	logger.Debugw("Flattening kubeconfig for shell session", "source", sourceKubeconfig)
+	if err := lib.RunCommand(viewCmd); err != nil {
+		return "", fmt.Errorf("failed to read kubeconfig: %w", err)
+	}
+
+	if err := os.WriteFile(target, stdout.Bytes(), 0600); err != nil {
+		return "", fmt.Errorf("failed to write session kubeconfig: %w", err)
+	}
+
+	if cluster != "" {
+		useCmd := exec.Command("kubectl", "config", "use-context", cluster)
+		useCmd.Env = mergeEnv(os.Environ(), map[string]string{"KUBECONFIG": target})
+		stderr.Reset()
+		useCmd.Stderr = &stderr
+		if err := lib.RunCommand(useCmd); err != nil {
+			return "", fmt.Errorf("failed to switch to context %s: %w", cluster, err)
+		}
+	}
+
+	return target, nil
+}
+
+// mergeEnv returns base with the given overrides applied
+func mergeEnv(base []string, overrides map[string]string) []string {
+	result := make([]string, 0, len(base)+len(overrides))
+	for _, entry := range base {
+		name := entry
+		if idx := strings.Index(entry, "="); idx >= 0 {
+			name = entry[:idx]
+		}
+		if _, overridden := overrides[name]; overridden {
+			continue
+		}
+		result = append(result, entry)
+	}
+	for name, value := range overrides {
+		result = append(result, name+"="+value)
+	}
+	return result
+}
@@ -0,0 +1,112 @@
+package services_environment
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func envMap(env []string) map[string]string {
+	result := make(map[string]string)
+	for _, entry := range env {
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) == 2 {
+			result[parts[0]] = parts[1]
+		}
+	}
+	return result
+}
+
+func TestCurrentShellLevel(t *testing.T) {
+	t.Setenv(ShellLevelEnvVar, "")
+	assert.Equal(t, 0, CurrentShellLevel())
+
+	t.Setenv(ShellLevelEnvVar, "2")
+	assert.Equal(t, 2, CurrentShellLevel())
+
+	t.Setenv(ShellLevelEnvVar, "garbage")
+	assert.Equal(t, 0, CurrentShellLevel())
+}
+
+func TestPrepareShellSession(t *testing.T) {
+	t.Setenv(ShellLevelEnvVar, "1")
+	t.Setenv("AWS_PROFILE", "old-profile")
+
+	session, err := PrepareShellSession(ShellOptions{
+		Shell:   "/bin/bash",
+		Profile: "dev",
+		Region:  "us-west-2",
+	})
+	require.NoError(t, err)
+	defer session.Cleanup()
+
+	env := envMap(session.Env)
+	assert.Equal(t, 2, session.Level)
+	assert.Equal(t, "2", env[ShellLevelEnvVar])
+	assert.Equal(t, "dev", env["AWS_PROFILE"])
+	assert.Equal(t, "us-west-2", env["AWS_REGION"])
+	assert.Equal(t, "[ark:dev (2)] ", env["ARK_SHELL_PROMPT"])
+	assert.NotContains(t, env, "ARK_SHELL_CLUSTER")
+
+	// bash gets a generated rcfile that keeps the user's rc
+	require.Len(t, session.Args, 3)
+	assert.Equal(t, "--rcfile", session.Args[0])
+	rc, err := os.ReadFile(session.Args[1])
+	require.NoError(t, err)
+	assert.Contains(t, string(rc), ".bashrc")
+	assert.Contains(t, string(rc), "ARK_SHELL_PROMPT")
+
+	// Cleanup removes the session directory
+	session.Cleanup()
+	_, err = os.Stat(session.Args[1])
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestPromptPrefix(t *testing.T) {
+	assert.Equal(t, "[ark] ", promptPrefix(ShellOptions{}, 1))
+	assert.Equal(t, "[ark:prod ⎈ eks-prod] ", promptPrefix(ShellOptions{Profile: "prod", Cluster: "eks-prod"}, 1))
+	assert.Equal(t, "[ark:prod (3)] ", promptPrefix(ShellOptions{Profile: "prod"}, 3))
+}
+
+func TestMergeEnv(t *testing.T) {
+	merged := envMap(mergeEnv([]string{"A=1", "B=2"}, map[string]string{"B": "3", "C": "4"}))
+	assert.Equal(t, map[string]string{"A": "1", "B": "3", "C": "4"}, merged)
+}
+
+func TestPromptSetupZsh(t *testing.T) {
+	userDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(userDir, ".zshenv"), []byte("FROM_ENV=1\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(userDir, ".zprofile"), []byte("FROM_PROFILE=1\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(userDir, ".zshrc"), []byte("FROM_RC=1\nPROMPT='%# '\n"), 0600))
+	t.Setenv("ZDOTDIR", userDir)
+
+	tempDir := t.TempDir()
+	args, env, err := promptSetup("/bin/zsh", tempDir, "[ark] ")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"-i"}, args)
+	assert.Equal(t, map[string]string{"ZDOTDIR": tempDir}, env)
+
+	// The user's .zshenv runs from the session's, before zsh moves on to the session's .zshrc
+	zshenv, err := os.ReadFile(filepath.Join(tempDir, ".zshenv"))
+	require.NoError(t, err)
+	assert.Contains(t, string(zshenv), userDir)
+	assert.Contains(t, string(zshenv), `. "$ZDOTDIR/.zshenv"`)
+	zshrc, err := os.ReadFile(filepath.Join(tempDir, ".zshrc"))
+	require.NoError(t, err)
+	assert.Less(t, strings.Index(string(zshrc), ".zprofile"), strings.Index(string(zshrc), `. "$ZDOTDIR/.zshrc"`))
+
+	zsh, err := exec.LookPath("zsh")
+	if err != nil {
+		t.Skip("zsh is not installed")
+	}
+	cmd := exec.Command(zsh, append(args, "-c", `print -r -- "$FROM_ENV $FROM_PROFILE $FROM_RC $ZDOTDIR $PROMPT"`)...)
+	cmd.Env = mergeEnv(os.Environ(), map[string]string{"ZDOTDIR": tempDir, "ARK_SHELL_PROMPT": "[ark] "})
+	output, err := cmd.Output()
+	require.NoError(t, err)
+	assert.Equal(t, "1 1 1 "+userDir+" [ark] %# ", strings.TrimSuffix(string(output), "\n"))
+}
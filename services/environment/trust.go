@@ -0,0 +1,107 @@
+package services_environment
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/andresgarcia29/ark-cli/paths"
+	"gopkg.in/yaml.v3"
+)
+
+// trustedProjectsFile is the state file recording the .ark files allowed with `ark env allow`, by path, with the
+// hash of the contents that were reviewed, like direnv's allow list
+const trustedProjectsFile = "trusted-projects.yaml"
+
+var (
+	// ErrProjectNotAllowed is returned for a .ark file that was never allowed
+	ErrProjectNotAllowed = errors.New("is not allowed")
+	// ErrProjectChanged is returned for a .ark file that changed since it was allowed
+	ErrProjectChanged = errors.New("changed since it was allowed")
+)
+
+// hashProjectFile returns the hash recorded for the contents of a .ark file
+func hashProjectFile(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// trustedProjectsPath returns the state file of the allowed .ark files
+func trustedProjectsPath() (string, error) {
+	dir, err := paths.StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, trustedProjectsFile), nil
+}
+
+// loadTrustedProjects reads the allowed .ark files, mapping their path to the hash of their contents
+func loadTrustedProjects() (map[string]string, error) {
+	path, err := trustedProjectsPath()
+	if err != nil {
+		return nil, err
+	}
+	trusted := make(map[string]string)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return trusted, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read allowed project files: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &trusted); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return trusted, nil
+}
+
+// AllowProjectFile records the .ark file at path, as it is now, as trusted: ark env exports it until it changes
+func AllowProjectFile(path string) (*ProjectConfig, error) {
+	project, err := LoadProjectFile(path)
+	if err != nil {
+		return nil, err
+	}
+	trusted, err := loadTrustedProjects()
+	if err != nil {
+		return nil, err
+	}
+	trusted[project.Path] = project.Hash
+
+	data, err := yaml.Marshal(trusted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode allowed project files: %w", err)
+	}
+	statePath, err := trustedProjectsPath()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(statePath), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+	if err := os.WriteFile(statePath, data, 0600); err != nil {
+		return nil, fmt.Errorf("failed to record allowed project file: %w", err)
+	}
+	return project, nil
+}
+
+// CheckProjectTrust returns nil when the project's .ark file was allowed as it is now, else an error wrapping
+// ErrProjectNotAllowed or ErrProjectChanged
+// A .ark file can point KUBECONFIG at a kubeconfig whose exec plugin runs any command, so a cloned repository's
+// file must be reviewed before ark exports it
+func CheckProjectTrust(project *ProjectConfig) error {
+	trusted, err := loadTrustedProjects()
+	if err != nil {
+		return err
+	}
+	hash, ok := trusted[project.Path]
+	switch {
+	case !ok:
+		return fmt.Errorf("%s %w", project.Path, ErrProjectNotAllowed)
+	case hash != project.Hash:
+		return fmt.Errorf("%s %w", project.Path, ErrProjectChanged)
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package services_environment
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectTrust(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_STATE_HOME", "")
+	path := filepath.Join(t.TempDir(), ".ark")
+	require.NoError(t, os.WriteFile(path, []byte("profile: dev\n"), 0600))
+
+	project, err := LoadProjectFile(path)
+	require.NoError(t, err)
+	assert.ErrorIs(t, CheckProjectTrust(project), ErrProjectNotAllowed)
+
+	_, err = AllowProjectFile(path)
+	require.NoError(t, err)
+	assert.NoError(t, CheckProjectTrust(project))
+
+	// A file changed after it was allowed, e.g. by a git pull, must be reviewed again
+	require.NoError(t, os.WriteFile(path, []byte("profile: dev\nkubeconfig: ./evil.yaml\n"), 0600))
+	changed, err := LoadProjectFile(path)
+	require.NoError(t, err)
+	assert.ErrorIs(t, CheckProjectTrust(changed), ErrProjectChanged)
+
+	_, err = AllowProjectFile(path)
+	require.NoError(t, err)
+	assert.NoError(t, CheckProjectTrust(changed))
+}
@@ -0,0 +1,32 @@
+package services_kubernetes
+
+// AdoptionCandidate is a context pointing at an EKS cluster that isn't marked as managed by ark
+type AdoptionCandidate struct {
+	Context string
+	// Metadata holds what the kubeconfig tells about the cluster; the account is only known from a cluster ARN
+	Metadata ArkMetadata
+}
+
+// AdoptionCandidates returns, in kubeconfig order, the contexts ark could manage: those without the ark extension
+// whose cluster entry is named after an EKS ARN, as `aws eks update-kubeconfig` writes them, or whose user
+// gets its token for an EKS cluster (a --cluster-name exec argument, as `aws eks get-token` takes)
+func (k *Kubeconfig) AdoptionCandidates() []AdoptionCandidate {
+	var candidates []AdoptionCandidate
+	for _, entry := range k.Contexts {
+		if _, managed := entry.Context.Extensions.Ark(); managed {
+			continue
+		}
+
+		var metadata ArkMetadata
+		if data, ok := parseEKSClusterARN(entry.Context.Cluster); ok {
+			metadata = ArkMetadata{AccountID: data.AccountID, Region: data.Region, Cluster: data.Cluster}
+		} else if user := k.FindUser(entry.Context.User); user != nil && user.User.Exec != nil {
+			_, metadata.Region, metadata.Cluster = parseExecDetails(user.User.Exec)
+		}
+		if metadata.Cluster == "" {
+			continue
+		}
+		candidates = append(candidates, AdoptionCandidate{Context: entry.Name, Metadata: metadata})
+	}
+	return candidates
+}
@@ -0,0 +1,35 @@
+package services_kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdoptionCandidates(t *testing.T) {
+	kubeconfig := NewKubeconfig()
+	// Already managed
+	kubeconfig.UpsertEKSContext(EKSContextOptions{Alias: "prod", ClusterName: "prod", Region: "us-east-1", AccountID: "111111111111"})
+	// Written by `aws eks update-kubeconfig`
+	kubeconfig.UpsertUser(NamedUser{Name: "aws-cli", User: KubeUser{Exec: &ExecConfig{
+		Command: "aws",
+		Args:    []string{"--region", "eu-west-1", "eks", "get-token", "--cluster-name", "dev", "--output", "json"},
+	}}})
+	kubeconfig.UpsertContext(NamedContext{Name: "arn:aws:eks:eu-west-1:222222222222:cluster/dev", Context: KubeContext{
+		Cluster: "arn:aws:eks:eu-west-1:222222222222:cluster/dev",
+		User:    "aws-cli",
+	}})
+	// Written by hand, with the token from the AWS CLI
+	kubeconfig.UpsertUser(NamedUser{Name: "hand", User: KubeUser{Exec: &ExecConfig{
+		Command: "aws",
+		Args:    []string{"eks", "get-token", "--cluster-name", "data", "--region", "us-west-2"},
+	}}})
+	kubeconfig.UpsertContext(NamedContext{Name: "data", Context: KubeContext{Cluster: "data", User: "hand"}})
+	// Not EKS
+	kubeconfig.UpsertContext(NamedContext{Name: "kind", Context: KubeContext{Cluster: "kind", User: "kind"}})
+
+	assert.Equal(t, []AdoptionCandidate{
+		{Context: "arn:aws:eks:eu-west-1:222222222222:cluster/dev", Metadata: ArkMetadata{AccountID: "222222222222", Region: "eu-west-1", Cluster: "dev"}},
+		{Context: "data", Metadata: ArkMetadata{Region: "us-west-2", Cluster: "data"}},
+	}, kubeconfig.AdoptionCandidates())
+}
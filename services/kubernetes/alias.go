@@ -0,0 +1,170 @@
+package services_kubernetes
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+
+	ark_config "github.com/andresgarcia29/ark-cli/config"
+	"github.com/andresgarcia29/ark-cli/logs"
+	"github.com/andresgarcia29/ark-cli/services/aws/arn"
+)
+
+// ContextAliasData holds the fields available to context alias templates
+type ContextAliasData struct {
+	Cluster   string
+	Region    string
+	AccountID string
+	Profile   string
+}
+
+// RenderContextAlias executes a context alias template
+func RenderContextAlias(text string, data ContextAliasData) (string, error) {
+	tmpl, err := template.New("context_alias").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid context alias template %q: %w", text, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("invalid context alias template %q: %w", text, err)
+	}
+
+	alias := strings.TrimSpace(buf.String())
+	if alias == "" {
+		return "", fmt.Errorf("context alias template %q renders an empty name for cluster %s", text, data.Cluster)
+	}
+	return alias, nil
+}
+
+// ContextAlias names the context of a cluster following kubernetes.context_alias
+// An invalid template falls back to the cluster name so setup never fails on it
+func ContextAlias(data ContextAliasData) string {
+	alias, err := RenderContextAlias(ark_config.Get().Kubernetes.ContextAlias, data)
+	if err != nil {
+		logs.GetLogger().Warnw("Invalid context alias template, using the cluster name", "error", err)
+		return data.Cluster
+	}
+	return alias
+}
+
+// ContextRename is a context whose name no longer matches the alias template
+type ContextRename struct {
+	From string
+	To   string
+}
+
+// ManagedContextData returns the alias fields of a context written by ark setup
+// Those contexts carry an ark extension with their account, region and cluster; contexts written before it
+// (or by the AWS CLI) are recognized by their cluster entry named after the EKS ARN
+// (arn:aws:eks:<region>:<account>:cluster/<name>). The profile comes from the exec block of their user
+func (k *Kubeconfig) ManagedContextData(entry NamedContext) (ContextAliasData, bool) {
+	data, fromARN := parseEKSClusterARN(entry.Context.Cluster)
+	metadata, fromExtension := entry.Context.Extensions.Ark()
+	if !fromARN && (!fromExtension || metadata.Cluster == "") {
+		return ContextAliasData{}, false
+	}
+	if fromExtension {
+		data.Cluster = firstNonEmpty(metadata.Cluster, data.Cluster)
+		data.Region = firstNonEmpty(metadata.Region, data.Region)
+		data.AccountID = firstNonEmpty(metadata.AccountID, data.AccountID)
+	}
+
+	if user := k.FindUser(entry.Context.User); user != nil && user.User.Exec != nil {
+		data.Profile, _, _ = parseExecDetails(user.User.Exec)
+	}
+	return data, true
+}
+
+// parseEKSClusterARN reads the cluster, region and account of an EKS cluster ARN
+func parseEKSClusterARN(clusterARN string) (ContextAliasData, bool) {
+	cluster, err := arn.ParseCluster(clusterARN)
+	if err != nil {
+		return ContextAliasData{}, false
+	}
+	return ContextAliasData{Cluster: cluster.Name, Region: cluster.Region, AccountID: cluster.AccountID}, true
+}
+
+// firstNonEmpty returns the first of values that is not empty
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// PlanContextRenames renders the alias template for every ark-managed context and returns, sorted,
+// the ones whose name changes. Renames that would collide with another context are rejected
+func (k *Kubeconfig) PlanContextRenames(aliasTemplate string) ([]ContextRename, error) {
+	var renames []ContextRename
+	targets := make(map[string]string)
+	for _, entry := range k.Contexts {
+		data, ok := k.ManagedContextData(entry)
+		if !ok {
+			continue
+		}
+		alias, err := RenderContextAlias(aliasTemplate, data)
+		if err != nil {
+			return nil, err
+		}
+		if previous, ok := targets[alias]; ok {
+			return nil, fmt.Errorf("contexts %s and %s would both be named %s; add fields such as {{.AccountID}} to the template", previous, entry.Name, alias)
+		}
+		targets[alias] = entry.Name
+		if alias != entry.Name {
+			renames = append(renames, ContextRename{From: entry.Name, To: alias})
+		}
+	}
+
+	// A new name may only take the place of a context that is being renamed away
+	renamed := make(map[string]bool, len(renames))
+	for _, rename := range renames {
+		renamed[rename.From] = true
+	}
+	for _, rename := range renames {
+		if k.FindContext(rename.To) != nil && !renamed[rename.To] {
+			return nil, fmt.Errorf("cannot rename %s to %s: a context with that name already exists", rename.From, rename.To)
+		}
+	}
+
+	sort.Slice(renames, func(i, j int) bool { return renames[i].From < renames[j].From })
+	return renames, nil
+}
+
+// RenameContexts applies renames to the contexts and the current context
+// Cluster and user entries named after the old context (written without an ARN) are renamed with it
+func (k *Kubeconfig) RenameContexts(renames []ContextRename) {
+	to := make(map[string]string, len(renames))
+	for _, rename := range renames {
+		to[rename.From] = rename.To
+	}
+
+	for i := range k.Contexts {
+		entry := &k.Contexts[i]
+		newName, ok := to[entry.Name]
+		if !ok {
+			continue
+		}
+		if entry.Context.Cluster == entry.Name {
+			if cluster := k.FindCluster(entry.Name); cluster != nil {
+				cluster.Name = newName
+			}
+			entry.Context.Cluster = newName
+		}
+		if entry.Context.User == entry.Name {
+			if user := k.FindUser(entry.Name); user != nil {
+				user.Name = newName
+			}
+			entry.Context.User = newName
+		}
+		entry.Name = newName
+	}
+
+	if newName, ok := to[k.CurrentContext]; ok {
+		k.CurrentContext = newName
+	}
+}
@@ -0,0 +1,112 @@
+package services_kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderContextAlias(t *testing.T) {
+	data := ContextAliasData{Cluster: "prod", Region: "us-west-2", AccountID: "111111111111", Profile: "prod-readonly"}
+
+	tests := []struct {
+		name          string
+		template      string
+		expected      string
+		expectedError bool
+	}{
+		{name: "default", template: "{{.Cluster}}", expected: "prod"},
+		{name: "all fields", template: "{{.AccountID}}/{{.Region}}/{{.Cluster}} ({{.Profile}})", expected: "111111111111/us-west-2/prod (prod-readonly)"},
+		{name: "unknown field", template: "{{.Namespace}}", expectedError: true},
+		{name: "empty result", template: "{{if false}}x{{end}}", expectedError: true},
+		{name: "invalid syntax", template: "{{.Cluster", expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alias, err := RenderContextAlias(tt.template, data)
+			if tt.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, alias)
+		})
+	}
+}
+
+func aliasTestKubeconfig() *Kubeconfig {
+	kubeconfig := NewKubeconfig()
+	for _, opts := range []EKSContextOptions{
+		{Alias: "prod", ClusterName: "prod", ARN: "arn:aws:eks:us-west-2:111111111111:cluster/prod", Region: "us-west-2", Profile: "prod-readonly"},
+		{Alias: "dev", ClusterName: "dev", ARN: "arn:aws:eks:eu-west-1:222222222222:cluster/dev", Region: "eu-west-1", Profile: "dev-readonly"},
+	} {
+		kubeconfig.UpsertEKSContext(opts)
+	}
+	kubeconfig.UpsertContext(NamedContext{Name: "minikube", Context: KubeContext{Cluster: "minikube", User: "minikube"}})
+	kubeconfig.CurrentContext = "prod"
+	return kubeconfig
+}
+
+func TestManagedContextData(t *testing.T) {
+	kubeconfig := aliasTestKubeconfig()
+
+	data, ok := kubeconfig.ManagedContextData(*kubeconfig.FindContext("prod"))
+	require.True(t, ok)
+	assert.Equal(t, ContextAliasData{Cluster: "prod", Region: "us-west-2", AccountID: "111111111111", Profile: "prod-readonly"}, data)
+
+	_, ok = kubeconfig.ManagedContextData(*kubeconfig.FindContext("minikube"))
+	assert.False(t, ok)
+}
+
+func TestPlanContextRenames(t *testing.T) {
+	kubeconfig := aliasTestKubeconfig()
+
+	renames, err := kubeconfig.PlanContextRenames("{{.Cluster}}")
+	require.NoError(t, err)
+	assert.Empty(t, renames)
+
+	renames, err = kubeconfig.PlanContextRenames("{{.Cluster}}-{{.Region}}")
+	require.NoError(t, err)
+	assert.Equal(t, []ContextRename{
+		{From: "dev", To: "dev-eu-west-1"},
+		{From: "prod", To: "prod-us-west-2"},
+	}, renames)
+
+	// Unmanaged contexts are never renamed, nor overwritten
+	_, err = kubeconfig.PlanContextRenames("minikube{{if false}}{{.Cluster}}{{end}}")
+	assert.ErrorContains(t, err, "would both be named minikube")
+
+	_, err = kubeconfig.PlanContextRenames(`{{if eq .Cluster "prod"}}minikube{{else}}{{.Cluster}}{{end}}`)
+	assert.ErrorContains(t, err, "already exists")
+}
+
+func TestPlanContextRenamesSwap(t *testing.T) {
+	kubeconfig := aliasTestKubeconfig()
+
+	renames, err := kubeconfig.PlanContextRenames(`{{if eq .Cluster "prod"}}dev{{else}}prod{{end}}`)
+	require.NoError(t, err)
+	require.Len(t, renames, 2)
+
+	kubeconfig.RenameContexts(renames)
+	assert.Equal(t, "arn:aws:eks:us-west-2:111111111111:cluster/prod", kubeconfig.FindContext("dev").Context.Cluster)
+	assert.Equal(t, "arn:aws:eks:eu-west-1:222222222222:cluster/dev", kubeconfig.FindContext("prod").Context.Cluster)
+	assert.Equal(t, "dev", kubeconfig.CurrentContext)
+}
+
+func TestRenameContextsWithoutARN(t *testing.T) {
+	// Entries written without an ARN share the context name and are renamed with it
+	kubeconfig := NewKubeconfig()
+	kubeconfig.UpsertEKSContext(EKSContextOptions{Alias: "prod", ClusterName: "prod", Region: "us-west-2"})
+
+	kubeconfig.RenameContexts([]ContextRename{{From: "prod", To: "prod-us-west-2"}})
+
+	context := kubeconfig.FindContext("prod-us-west-2")
+	require.NotNil(t, context)
+	assert.Equal(t, "prod-us-west-2", context.Context.Cluster)
+	assert.Equal(t, "prod-us-west-2", context.Context.User)
+	assert.NotNil(t, kubeconfig.FindCluster("prod-us-west-2"))
+	assert.NotNil(t, kubeconfig.FindUser("prod-us-west-2"))
+	assert.Nil(t, kubeconfig.FindCluster("prod"))
+}
@@ -0,0 +1,96 @@
+package services_kubernetes
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// The aws-auth ConfigMap maps IAM principals to Kubernetes users on clusters without access entries
+const (
+	awsAuthNamespace = "kube-system"
+	awsAuthName      = "aws-auth"
+)
+
+// ErrNoAWSAuth is returned by GetAWSAuth when the cluster has no aws-auth ConfigMap
+var ErrNoAWSAuth = errors.New("no aws-auth ConfigMap in kube-system")
+
+// AWSAuthMapping is one entry of the aws-auth ConfigMap
+type AWSAuthMapping struct {
+	// Kind is "role", "user" or "account", from mapRoles, mapUsers and mapAccounts
+	Kind     string
+	ARN      string // Account ID for account mappings
+	Username string
+	Groups   []string
+}
+
+// awsAuthEntry is the YAML layout of the mapRoles and mapUsers entries
+type awsAuthEntry struct {
+	RoleARN  string   `yaml:"rolearn"`
+	UserARN  string   `yaml:"userarn"`
+	Username string   `yaml:"username"`
+	Groups   []string `yaml:"groups"`
+}
+
+// NewEKSClientset creates a Kubernetes client for an EKS endpoint authenticated with a bearer token
+// caData is the base64 certificate authority returned by DescribeCluster
+func NewEKSClientset(endpoint, caData, token string) (kubernetes.Interface, error) {
+	ca, err := base64.StdEncoding.DecodeString(caData)
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificate authority data: %w", err)
+	}
+
+	return kubernetes.NewForConfig(&rest.Config{
+		Host:            endpoint,
+		BearerToken:     token,
+		TLSClientConfig: rest.TLSClientConfig{CAData: ca},
+	})
+}
+
+// GetAWSAuth reads the mappings of a cluster's aws-auth ConfigMap, returning ErrNoAWSAuth when it has none
+func GetAWSAuth(ctx context.Context, clientset kubernetes.Interface) ([]AWSAuthMapping, error) {
+	configMap, err := clientset.CoreV1().ConfigMaps(awsAuthNamespace).Get(ctx, awsAuthName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, ErrNoAWSAuth
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the aws-auth ConfigMap: %w", err)
+	}
+	return ParseAWSAuth(configMap.Data)
+}
+
+// ParseAWSAuth parses the mapRoles, mapUsers and mapAccounts keys of the aws-auth ConfigMap
+func ParseAWSAuth(data map[string]string) ([]AWSAuthMapping, error) {
+	var mappings []AWSAuthMapping
+
+	for _, key := range []struct{ name, kind string }{{"mapRoles", "role"}, {"mapUsers", "user"}} {
+		var entries []awsAuthEntry
+		if err := yaml.Unmarshal([]byte(data[key.name]), &entries); err != nil {
+			return nil, fmt.Errorf("invalid %s in aws-auth: %w", key.name, err)
+		}
+		for _, entry := range entries {
+			arn := entry.RoleARN
+			if key.kind == "user" {
+				arn = entry.UserARN
+			}
+			mappings = append(mappings, AWSAuthMapping{Kind: key.kind, ARN: arn, Username: entry.Username, Groups: entry.Groups})
+		}
+	}
+
+	var accounts []string
+	if err := yaml.Unmarshal([]byte(data["mapAccounts"]), &accounts); err != nil {
+		return nil, fmt.Errorf("invalid mapAccounts in aws-auth: %w", err)
+	}
+	for _, account := range accounts {
+		mappings = append(mappings, AWSAuthMapping{Kind: "account", ARN: account})
+	}
+
+	return mappings, nil
+}
@@ -0,0 +1,89 @@
+package services_kubernetes
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const awsAuthData = `
+- rolearn: arn:aws:iam::111111111111:role/NodeInstanceRole
+  username: system:node:{{EC2PrivateDNSName}}
+  groups:
+    - system:bootstrappers
+    - system:nodes
+- rolearn: arn:aws:iam::111111111111:role/Admin
+  username: admin
+  groups: [system:masters]
+`
+
+func TestParseAWSAuth(t *testing.T) {
+	mappings, err := ParseAWSAuth(map[string]string{
+		"mapRoles":    awsAuthData,
+		"mapUsers":    "- userarn: arn:aws:iam::111111111111:user/alice\n  username: alice\n",
+		"mapAccounts": "- \"222222222222\"\n",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []AWSAuthMapping{
+		{Kind: "role", ARN: "arn:aws:iam::111111111111:role/NodeInstanceRole", Username: "system:node:{{EC2PrivateDNSName}}", Groups: []string{"system:bootstrappers", "system:nodes"}},
+		{Kind: "role", ARN: "arn:aws:iam::111111111111:role/Admin", Username: "admin", Groups: []string{"system:masters"}},
+		{Kind: "user", ARN: "arn:aws:iam::111111111111:user/alice", Username: "alice"},
+		{Kind: "account", ARN: "222222222222"},
+	}, mappings)
+
+	mappings, err = ParseAWSAuth(nil)
+	require.NoError(t, err)
+	assert.Empty(t, mappings)
+
+	_, err = ParseAWSAuth(map[string]string{"mapRoles": "rolearn: [unclosed"})
+	assert.ErrorContains(t, err, "invalid mapRoles")
+}
+
+func TestGetAWSAuth(t *testing.T) {
+	_, err := GetAWSAuth(context.Background(), fake.NewSimpleClientset())
+	assert.ErrorIs(t, err, ErrNoAWSAuth)
+
+	clientset := fake.NewSimpleClientset(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "aws-auth"},
+		Data:       map[string]string{"mapRoles": awsAuthData},
+	})
+	mappings, err := GetAWSAuth(context.Background(), clientset)
+	require.NoError(t, err)
+	assert.Len(t, mappings, 2)
+}
+
+func TestNewEKSClientset(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer k8s-aws-v1.token" || r.URL.Path != "/api/v1/namespaces/kube-system/configmaps/aws-auth" {
+			http.Error(w, "unexpected request", http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"data":       map[string]string{"mapRoles": awsAuthData},
+		})
+	}))
+	defer server.Close()
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	clientset, err := NewEKSClientset(server.URL, base64.StdEncoding.EncodeToString(ca), "k8s-aws-v1.token")
+	require.NoError(t, err)
+	mappings, err := GetAWSAuth(context.Background(), clientset)
+	require.NoError(t, err)
+	assert.Len(t, mappings, 2)
+
+	_, err = NewEKSClientset(server.URL, "not base64!", "k8s-aws-v1.token")
+	assert.ErrorContains(t, err, "certificate authority")
+}
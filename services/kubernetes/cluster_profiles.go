@@ -0,0 +1,106 @@
+package services_kubernetes
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	ark_config "github.com/andresgarcia29/ark-cli/config"
+	"github.com/andresgarcia29/ark-cli/paths"
+	"gopkg.in/yaml.v3"
+)
+
+// clusterProfilesFile is read from ark's config directory when kubernetes.cluster_profiles is not set
+const clusterProfilesFile = "cluster_profiles.yaml"
+
+// ClusterProfileRule gives the profile kubeconfig contexts use for the clusters it matches, instead of the
+// profile that discovered them, e.g. a hub account role for clusters shared with the spokes
+// Cluster is a path.Match glob compared without case; empty fields match every cluster
+type ClusterProfileRule struct {
+	Cluster   string `yaml:"cluster"`
+	AccountID string `yaml:"account_id"`
+	Region    string `yaml:"region"`
+	Profile   string `yaml:"profile"`
+}
+
+// ClusterProfiles is the per-cluster profile mapping file; the first matching rule wins
+type ClusterProfiles struct {
+	Clusters []ClusterProfileRule `yaml:"clusters"`
+}
+
+// LoadClusterProfiles reads the mapping file, or the configured one when file is empty
+// Only a file given explicitly has to exist: without one, no cluster is remapped
+func LoadClusterProfiles(file string) (*ClusterProfiles, error) {
+	explicit := file != ""
+	if !explicit {
+		var err error
+		if file, err = clusterProfilesPath(); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) && !explicit {
+		return &ClusterProfiles{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cluster profiles: %w", err)
+	}
+	return parseClusterProfiles(file, data)
+}
+
+// clusterProfilesPath returns kubernetes.cluster_profiles, or cluster_profiles.yaml in ark's config directory
+func clusterProfilesPath() (string, error) {
+	if file := ark_config.Get().Kubernetes.ClusterProfiles; file != "" {
+		return ResolveKubeconfigPath(file)
+	}
+	dir, err := paths.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, clusterProfilesFile), nil
+}
+
+// parseClusterProfiles decodes and validates a mapping file
+func parseClusterProfiles(file string, data []byte) (*ClusterProfiles, error) {
+	var profiles ClusterProfiles
+	if err := yaml.Unmarshal(data, &profiles); err != nil {
+		return nil, fmt.Errorf("failed to parse cluster profiles %s: %w", file, err)
+	}
+	for i, rule := range profiles.Clusters {
+		switch {
+		case rule.Profile == "":
+			return nil, fmt.Errorf("cluster profiles %s: rule %d has no profile", file, i+1)
+		case rule.Cluster == "" && rule.AccountID == "":
+			return nil, fmt.Errorf("cluster profiles %s: rule %d needs a cluster or an account_id", file, i+1)
+		}
+		if _, err := path.Match(rule.Cluster, ""); err != nil {
+			return nil, fmt.Errorf("cluster profiles %s: rule %d has an invalid cluster pattern %q: %w", file, i+1, rule.Cluster, err)
+		}
+	}
+	return &profiles, nil
+}
+
+// ProfileFor returns the profile of the first rule matching a cluster
+func (p *ClusterProfiles) ProfileFor(cluster, accountID, region string) (string, bool) {
+	if p == nil {
+		return "", false
+	}
+	for _, rule := range p.Clusters {
+		if rule.AccountID != "" && rule.AccountID != accountID {
+			continue
+		}
+		if rule.Region != "" && rule.Region != region {
+			continue
+		}
+		if rule.Cluster != "" {
+			if matched, _ := path.Match(strings.ToLower(rule.Cluster), strings.ToLower(cluster)); !matched {
+				continue
+			}
+		}
+		return rule.Profile, true
+	}
+	return "", false
+}
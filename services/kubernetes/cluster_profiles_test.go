@@ -0,0 +1,93 @@
+package services_kubernetes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sampleClusterProfiles = `clusters:
+  - cluster: shared-*
+    account_id: "222222222222"
+    profile: hub-readonly
+  - account_id: "333333333333"
+    region: eu-west-1
+    profile: spoke-eu
+  - cluster: Payments
+    profile: payments-admin
+`
+
+func TestLoadClusterProfiles(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "cluster_profiles.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(sampleClusterProfiles), 0600))
+
+	profiles, err := LoadClusterProfiles(file)
+	require.NoError(t, err)
+	assert.Len(t, profiles.Clusters, 3)
+
+	_, err = LoadClusterProfiles(filepath.Join(dir, "missing.yaml"))
+	assert.Error(t, err, "a file given explicitly has to exist")
+}
+
+func TestParseClusterProfilesValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{name: "empty file", data: ""},
+		{name: "no profile", data: "clusters:\n  - cluster: prod\n", wantErr: "rule 1 has no profile"},
+		{name: "no cluster or account", data: "clusters:\n  - region: us-east-1\n    profile: hub\n", wantErr: "rule 1 needs a cluster or an account_id"},
+		{name: "invalid pattern", data: "clusters:\n  - cluster: '[prod'\n    profile: hub\n", wantErr: "invalid cluster pattern"},
+		{name: "invalid yaml", data: "clusters: [", wantErr: "failed to parse"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseClusterProfiles("cluster_profiles.yaml", []byte(tt.data))
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestClusterProfilesProfileFor(t *testing.T) {
+	profiles, err := parseClusterProfiles("cluster_profiles.yaml", []byte(sampleClusterProfiles))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		cluster     string
+		accountID   string
+		region      string
+		wantProfile string
+		wantOK      bool
+	}{
+		{name: "glob and account", cluster: "shared-prod", accountID: "222222222222", region: "us-east-1", wantProfile: "hub-readonly", wantOK: true},
+		{name: "glob with another account", cluster: "shared-prod", accountID: "111111111111", region: "us-east-1"},
+		{name: "account and region", cluster: "anything", accountID: "333333333333", region: "eu-west-1", wantProfile: "spoke-eu", wantOK: true},
+		{name: "account in another region", cluster: "anything", accountID: "333333333333", region: "us-east-1"},
+		{name: "cluster names ignore case", cluster: "payments", accountID: "444444444444", region: "us-east-1", wantProfile: "payments-admin", wantOK: true},
+		{name: "no rule", cluster: "dev", accountID: "111111111111", region: "us-east-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile, ok := profiles.ProfileFor(tt.cluster, tt.accountID, tt.region)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantProfile, profile)
+		})
+	}
+
+	var none *ClusterProfiles
+	_, ok := none.ProfileFor("shared-prod", "222222222222", "us-east-1")
+	assert.False(t, ok, "a nil mapping matches nothing")
+}
@@ -8,27 +8,23 @@ import (
 	"github.com/andresgarcia29/ark-cli/logs"
 )
 
-// CleanKubeconfig cleans the ~/.kube/config file
+// CleanKubeconfig empties the kubeconfig file, keeping a .backup copy
 func CleanKubeconfig(kubeconfigPath string) error {
 	logger := logs.GetLogger()
 	logger.Infow("Starting kubeconfig cleanup", "path", kubeconfigPath)
 
-	homeDir, err := os.UserHomeDir()
+	kubeconfigPath, err := ResolveKubeconfigPath(kubeconfigPath)
 	if err != nil {
-		logger.Errorw("Failed to get home directory", "error", err)
-		return fmt.Errorf("failed to get home directory: %w", err)
-	}
-
-	if kubeconfigPath == "" {
-		kubeconfigPath = filepath.Join(homeDir, ".kube", "config")
-		logger.Debugw("Using default kubeconfig path", "path", kubeconfigPath)
+		logger.Errorw("Failed to resolve kubeconfig path", "error", err)
+		return err
 	}
+	logger.Debugw("Resolved kubeconfig path", "path", kubeconfigPath)
 
 	// Check if the file exists
 	if _, err := os.Stat(kubeconfigPath); os.IsNotExist(err) {
 		// File does not exist, nothing to clean
 		logger.Infow("Kubeconfig file does not exist, nothing to clean", "path", kubeconfigPath)
-		fmt.Println("~/.kube/config does not exist, nothing to clean")
+		fmt.Printf("%s does not exist, nothing to clean\n", kubeconfigPath)
 		return nil
 	}
 
@@ -46,8 +42,8 @@ func CleanKubeconfig(kubeconfigPath string) error {
 	logger.Infow("Backup created successfully", "backup", backupPath)
 	fmt.Printf("Backup created at: %s\n", backupPath)
 
-	// Create ~/.kube directory if it doesn't exist
-	kubeDir := filepath.Join(homeDir, ".kube")
+	// Create the kubeconfig directory if it doesn't exist
+	kubeDir := filepath.Dir(kubeconfigPath)
 	logger.Debugw("Ensuring .kube directory exists", "path", kubeDir)
 
 	if err := os.MkdirAll(kubeDir, 0700); err != nil {
@@ -0,0 +1,123 @@
+package services_kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/andresgarcia29/ark-cli/cachefile"
+	"github.com/andresgarcia29/ark-cli/logs"
+	"github.com/andresgarcia29/ark-cli/paths"
+)
+
+// contextSummaryCacheFile keeps the parsed contexts of the merged kubeconfig between selector openings
+const contextSummaryCacheFile = "kubernetes-contexts.json"
+
+// kubeconfigStamp identifies the version of a kubeconfig file the summary was parsed from
+type kubeconfigStamp struct {
+	Path    string    `json:"path"`
+	Exists  bool      `json:"exists"`
+	ModTime time.Time `json:"mod_time"`
+	Size    int64     `json:"size"`
+}
+
+// contextSummary is the cached list of contexts, valid while every file keeps its stamp
+type contextSummary struct {
+	Files    []kubeconfigStamp `json:"files"`
+	Contexts []ClusterContext  `json:"contexts"`
+}
+
+// stampKubeconfigs returns the current stamp of each file
+func stampKubeconfigs(paths []string) ([]kubeconfigStamp, error) {
+	stamps := make([]kubeconfigStamp, 0, len(paths))
+	for _, path := range paths {
+		stamp := kubeconfigStamp{Path: path}
+		info, err := os.Stat(path)
+		switch {
+		case err == nil:
+			stamp.Exists = true
+			stamp.ModTime = info.ModTime()
+			stamp.Size = info.Size()
+		case !os.IsNotExist(err):
+			return nil, fmt.Errorf("failed to stat kubeconfig: %w", err)
+		}
+		stamps = append(stamps, stamp)
+	}
+	return stamps, nil
+}
+
+// sameStamps reports whether the files are unchanged since the summary was cached
+func sameStamps(cached, current []kubeconfigStamp) bool {
+	if len(cached) != len(current) {
+		return false
+	}
+	for i := range cached {
+		if cached[i].Path != current[i].Path || cached[i].Exists != current[i].Exists ||
+			cached[i].Size != current[i].Size || !cached[i].ModTime.Equal(current[i].ModTime) {
+			return false
+		}
+	}
+	return true
+}
+
+// contextSummaryPath returns the cache file of the context summary
+func contextSummaryPath() (string, error) {
+	dir, err := paths.CacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, contextSummaryCacheFile), nil
+}
+
+// LoadContextSummary returns the contexts of the merged kubeconfig with their details, sorted by name
+// The parse is cached and reused until a kubeconfig file changes size or modification time,
+// or KUBECONFIG lists other files, so large kubeconfigs are only parsed again after a change
+func LoadContextSummary() ([]ClusterContext, error) {
+	logger := logs.GetLogger()
+
+	paths, err := KubeconfigPaths()
+	if err != nil {
+		return nil, err
+	}
+	stamps, err := stampKubeconfigs(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	cachePath, err := contextSummaryPath()
+	if err != nil {
+		return nil, err
+	}
+	if data, err := cachefile.Read(cachePath); err == nil {
+		var cached contextSummary
+		if err := json.Unmarshal(data, &cached); err == nil && sameStamps(cached.Files, stamps) {
+			logger.Debugw("Using cached kubeconfig summary", "path", cachePath, "contexts", len(cached.Contexts))
+			return cached.Contexts, nil
+		}
+	}
+
+	kubeconfig, err := LoadMergedKubeconfig()
+	if err != nil {
+		return nil, err
+	}
+	contexts := kubeconfig.ClusterContexts()
+	sort.SliceStable(contexts, func(i, j int) bool { return contexts[i].Name < contexts[j].Name })
+
+	// A failed cache write only costs the next opening another parse
+	if err := writeContextSummary(cachePath, contextSummary{Files: stamps, Contexts: contexts}); err != nil {
+		logger.Debugw("Failed to cache kubeconfig summary", "path", cachePath, "error", err)
+	}
+	return contexts, nil
+}
+
+// writeContextSummary stores the summary as JSON, encrypted when cache.encrypt is set
+func writeContextSummary(path string, summary contextSummary) error {
+	data, err := json.Marshal(summary)
+	if err != nil {
+		return fmt.Errorf("failed to marshal kubeconfig summary: %w", err)
+	}
+	return cachefile.Write(path, data)
+}
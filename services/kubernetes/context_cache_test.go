@@ -0,0 +1,65 @@
+package services_kubernetes
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadContextSummary(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	kubeconfigPath := filepath.Join(home, "config")
+	t.Setenv(KubeconfigEnv, kubeconfigPath)
+
+	kubeconfig := NewKubeconfig()
+	kubeconfig.UpsertContext(NamedContext{Name: "prod", Context: KubeContext{Cluster: "prod", User: "prod"}})
+	kubeconfig.UpsertContext(NamedContext{Name: "dev", Context: KubeContext{Cluster: "dev", User: "dev"}})
+	kubeconfig.CurrentContext = "prod"
+	require.NoError(t, SaveKubeconfig(kubeconfigPath, kubeconfig))
+
+	contexts, err := LoadContextSummary()
+	require.NoError(t, err)
+	assert.Equal(t, []ClusterContext{{Name: "dev"}, {Name: "prod", Current: true}}, contexts)
+
+	// An unchanged kubeconfig is answered from the cache
+	cachePath, err := contextSummaryPath()
+	require.NoError(t, err)
+	var cached contextSummary
+	data, err := os.ReadFile(cachePath)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &cached))
+	cached.Contexts = []ClusterContext{{Name: "from-cache"}}
+	require.NoError(t, writeContextSummary(cachePath, cached))
+
+	contexts, err = LoadContextSummary()
+	require.NoError(t, err)
+	assert.Equal(t, []ClusterContext{{Name: "from-cache"}}, contexts)
+
+	// Changing the kubeconfig invalidates the cache
+	kubeconfig.CurrentContext = "dev"
+	require.NoError(t, SaveKubeconfig(kubeconfigPath, kubeconfig))
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(kubeconfigPath, later, later))
+
+	contexts, err = LoadContextSummary()
+	require.NoError(t, err)
+	assert.Equal(t, []ClusterContext{{Name: "dev", Current: true}, {Name: "prod"}}, contexts)
+}
+
+func TestSameStamps(t *testing.T) {
+	now := time.Now()
+	stamps := []kubeconfigStamp{{Path: "/a", Exists: true, ModTime: now, Size: 10}, {Path: "/b"}}
+
+	assert.True(t, sameStamps(stamps, []kubeconfigStamp{{Path: "/a", Exists: true, ModTime: now, Size: 10}, {Path: "/b"}}))
+	assert.False(t, sameStamps(stamps, stamps[:1]))
+	assert.False(t, sameStamps(stamps, []kubeconfigStamp{{Path: "/a", Exists: true, ModTime: now, Size: 11}, {Path: "/b"}}))
+	assert.False(t, sameStamps(stamps, []kubeconfigStamp{{Path: "/a", Exists: true, ModTime: now.Add(time.Second), Size: 10}, {Path: "/b"}}))
+	assert.False(t, sameStamps(stamps, []kubeconfigStamp{{Path: "/a", Exists: true, ModTime: now, Size: 10}, {Path: "/b", Exists: true}}))
+	assert.False(t, sameStamps(stamps, []kubeconfigStamp{{Path: "/b"}, {Path: "/a", Exists: true, ModTime: now, Size: 10}}))
+}
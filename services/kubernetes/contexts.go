@@ -2,10 +2,14 @@ package services_kubernetes
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"os/exec"
 	"strings"
+	"time"
 
+	"github.com/andresgarcia29/ark-cli/lib"
 	"github.com/andresgarcia29/ark-cli/logs"
 )
 
@@ -16,11 +20,28 @@ type ClusterContext struct {
 	Profile     string
 	Region      string
 	ClusterName string
+	// AccountID and ClusterARN are only known for contexts whose cluster entry is an EKS ARN
+	AccountID  string
+	ClusterARN string
 }
 
-// GetClusterContexts retrieves all available cluster contexts from kubectl
+// GetClusterContexts retrieves all available cluster contexts
+// They are read from the cached kubeconfig summary, and from kubectl when the kubeconfig can't be parsed
 func GetClusterContexts() ([]ClusterContext, error) {
 	logger := logs.GetLogger()
+
+	contexts, err := LoadContextSummary()
+	if err == nil {
+		logger.Infow("Successfully retrieved cluster contexts", "count", len(contexts))
+		return contexts, nil
+	}
+	logger.Warnw("Failed to read kubeconfig summary, asking kubectl", "error", err)
+	return getClusterContextsFromKubectl()
+}
+
+// getClusterContextsFromKubectl lists the contexts known to kubectl
+func getClusterContextsFromKubectl() ([]ClusterContext, error) {
+	logger := logs.GetLogger()
 	logger.Debug("Starting to retrieve cluster contexts from kubectl")
 
 	// Get all context names
@@ -30,9 +51,9 @@ func GetClusterContexts() ([]ClusterContext, error) {
 	cmd.Stderr = &stderr
 
 	logger.Debug("Executing kubectl config get-contexts command")
-	if err := cmd.Run(); err != nil {
+	if err := lib.RunCommand(cmd); err != nil {
 		logger.Errorw("Failed to get cluster contexts", "error", err, "stderr", stderr.String())
-		return nil, fmt.Errorf("failed to get cluster contexts: %w\nStderr: %s", err, stderr.String())
+		return nil, fmt.Errorf("failed to get cluster contexts: %w", err)
 	}
 
 	contextNames := strings.Split(strings.TrimSpace(stdout.String()), "\n")
@@ -54,6 +75,16 @@ func GetClusterContexts() ([]ClusterContext, error) {
 		logger.Debugw("Current context retrieved", "context", currentContext)
 	}
 
+	// Details come from the kubeconfig files themselves: one parse instead of a kubectl call per context
+	details := make(map[string]ClusterContext)
+	if kubeconfig, err := LoadMergedKubeconfig(); err == nil {
+		for _, clusterContext := range kubeconfig.ClusterContexts() {
+			details[clusterContext.Name] = clusterContext
+		}
+	} else {
+		logger.Warnw("Failed to read kubeconfig, contexts are listed without details", "error", err)
+	}
+
 	contexts := make([]ClusterContext, 0, len(contextNames))
 	for _, name := range contextNames {
 		if name != "" {
@@ -71,11 +102,12 @@ func GetClusterContexts() ([]ClusterContext, error) {
 			// }
 
 			context := ClusterContext{
-				Name:    name,
-				Current: name == currentContext,
-				// Profile:     profile,
-				// Region:      region,
-				// ClusterName: clusterName,
+				Name:        name,
+				Current:     name == currentContext,
+				Profile:     details[name].Profile,
+				Region:      details[name].Region,
+				ClusterName: details[name].ClusterName,
+				AccountID:   details[name].AccountID,
 			}
 			contexts = append(contexts, context)
 			logger.Debugw("Context added to results", "context", context)
@@ -96,9 +128,9 @@ func getCurrentContext() (string, error) {
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
-	if err := cmd.Run(); err != nil {
+	if err := lib.RunCommand(cmd); err != nil {
 		logger.Errorw("Failed to get current context", "error", err, "stderr", stderr.String())
-		return "", fmt.Errorf("failed to get current context: %w\nStderr: %s", err, stderr.String())
+		return "", fmt.Errorf("failed to get current context: %w", err)
 	}
 
 	currentContext := strings.TrimSpace(stdout.String())
@@ -118,9 +150,9 @@ func GetKubernetesContextDetails(contextName string) (profile, region, clusterNa
 	cmd.Stderr = &stderr
 
 	logger.Debugw("Executing kubectl config view command", "context", contextName)
-	if err := cmd.Run(); err != nil {
+	if err := lib.RunCommand(cmd); err != nil {
 		logger.Errorw("Failed to get context details", "context", contextName, "error", err, "stderr", stderr.String())
-		return "", "", "", fmt.Errorf("failed to get context details: %w\nStderr: %s", err, stderr.String())
+		return "", "", "", fmt.Errorf("failed to get context details: %w", err)
 	}
 
 	config := stdout.String()
@@ -178,6 +210,15 @@ func GetKubernetesContextDetails(contextName string) (profile, region, clusterNa
 				}
 			}
 
+			// Look for --profile (ark exec blocks embed it as an argument)
+			if profile == "" && strings.Contains(line, "--profile") && i+1 < len(lines) {
+				nextLine := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(lines[i+1]), "- "))
+				if !strings.HasPrefix(nextLine, "-") {
+					profile = nextLine
+					logger.Debugw("Extracted AWS profile from args", "context", contextName, "profile", profile)
+				}
+			}
+
 			// Look for --cluster-name followed by the cluster name
 			if strings.Contains(line, "--cluster-name") && i+1 < len(lines) {
 				nextLine := strings.TrimSpace(lines[i+1])
@@ -194,21 +235,73 @@ func GetKubernetesContextDetails(contextName string) (profile, region, clusterNa
 }
 
 // SwitchToContext switches to the specified cluster context
+// The context active until now is remembered so `ark ctx -` can switch back to it
 func SwitchToContext(contextName string) error {
 	logger := logs.GetLogger()
 	logger.Infow("Switching to cluster context", "context", contextName)
 
+	previous, _ := getCurrentContext()
+
 	cmd := exec.Command("kubectl", "config", "use-context", contextName)
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
 	logger.Debugw("Executing kubectl config use-context command", "context", contextName)
-	if err := cmd.Run(); err != nil {
+	if err := lib.RunCommand(cmd); err != nil {
 		logger.Errorw("Failed to switch to context", "context", contextName, "error", err, "stderr", stderr.String())
-		return fmt.Errorf("failed to switch to context %s: %w\nStderr: %s", contextName, err, stderr.String())
+		return fmt.Errorf("failed to switch to context %s: %w", contextName, err)
+	}
+
+	if previous != "" && previous != contextName {
+		if err := savePreviousContext(previous); err != nil {
+			logger.Warnw("Failed to record previous context", "context", previous, "error", err)
+		}
 	}
 
 	logger.Infow("Successfully switched to context", "context", contextName)
 	return nil
 }
+
+// SetContextNamespace sets the default namespace of a context, the current one when contextName is empty
+func SetContextNamespace(contextName, namespace string) error {
+	logger := logs.GetLogger()
+	logger.Infow("Setting context namespace", "context", contextName, "namespace", namespace)
+
+	args := []string{"config", "set-context", "--current", "--namespace", namespace}
+	if contextName != "" {
+		args = []string{"config", "set-context", contextName, "--namespace", namespace}
+	}
+	cmd := exec.Command("kubectl", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := lib.RunCommand(cmd); err != nil {
+		logger.Errorw("Failed to set context namespace", "context", contextName, "error", err, "stderr", stderr.String())
+		return fmt.Errorf("failed to set namespace %s: %w", namespace, err)
+	}
+	return nil
+}
+
+// ProbeContext asks the API server of a context for its version, which needs both a reachable
+// endpoint and working credentials; the error explains what failed
+func ProbeContext(ctx context.Context, contextName string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "kubectl", "--context", contextName, "--request-timeout", timeout.String(), "get", "--raw", "/version")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := lib.RunCommand(cmd); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("no answer within %s", timeout)
+		}
+		message, _, _ := strings.Cut(strings.TrimSpace(stderr.String()), "\n")
+		if message == "" {
+			return err
+		}
+		return errors.New(message)
+	}
+	return nil
+}
@@ -1,7 +1,14 @@
 package services_kubernetes
 
 import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestGetClusterContexts(t *testing.T) {
@@ -53,3 +60,48 @@ func TestSwitchToContext(t *testing.T) {
 		}
 	}
 }
+
+// fakeKubectl puts a kubectl script running body first on PATH
+func fakeKubectl(t *testing.T, body string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake kubectl is a shell script")
+	}
+	dir := t.TempDir()
+	script := "#!/bin/sh\n" + body + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "kubectl"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestProbeContext(t *testing.T) {
+	fakeKubectl(t, `echo '{"gitVersion":"v1.30.0"}'`)
+	assert.NoError(t, ProbeContext(context.Background(), "dev", time.Second))
+
+	fakeKubectl(t, "echo 'error: You must be logged in to the server (Unauthorized)' >&2\necho 'second line' >&2\nexit 1")
+	err := ProbeContext(context.Background(), "dev", time.Second)
+	assert.EqualError(t, err, "error: You must be logged in to the server (Unauthorized)")
+
+	fakeKubectl(t, "exec sleep 5")
+	err = ProbeContext(context.Background(), "dev", 100*time.Millisecond)
+	assert.EqualError(t, err, "no answer within 100ms")
+}
+
+func TestSetContextNamespace(t *testing.T) {
+	argsFile := filepath.Join(t.TempDir(), "args")
+	fakeKubectl(t, `echo "$@" > `+argsFile)
+
+	assert.NoError(t, SetContextNamespace("payments-prod", "payments"))
+	args, err := os.ReadFile(argsFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "config set-context payments-prod --namespace payments\n", string(args))
+
+	assert.NoError(t, SetContextNamespace("", "payments"))
+	args, err = os.ReadFile(argsFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "config set-context --current --namespace payments\n", string(args))
+
+	fakeKubectl(t, "echo 'error: no context exists with the name: \"missing\"' >&2\nexit 1")
+	assert.ErrorContains(t, SetContextNamespace("missing", "payments"), "no context exists")
+}
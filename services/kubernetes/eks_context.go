@@ -0,0 +1,154 @@
+package services_kubernetes
+
+import "time"
+
+// AuthMode controls how users written for EKS contexts obtain their credentials
+type AuthMode string
+
+const (
+	// AuthModeExec writes an exec block that calls ark to mint a fresh token on demand
+	AuthModeExec AuthMode = "exec"
+	// AuthModeStatic embeds a short-lived token directly, for air-gapped debugging
+	AuthModeStatic AuthMode = "static"
+)
+
+// DefaultExecCommand is the binary invoked by exec blocks written by ark
+const DefaultExecCommand = "ark"
+
+// EKSContextOptions describes an EKS context to write into a kubeconfig
+type EKSContextOptions struct {
+	Alias                    string // Context name
+	ClusterName              string
+	ARN                      string
+	Endpoint                 string
+	CertificateAuthorityData string
+	Region                   string
+	AccountID                string
+	Profile                  string
+	Namespace                string
+	AuthMode                 AuthMode
+	StaticToken              string // Used when AuthMode is AuthModeStatic
+	ExecCommand              string // Defaults to DefaultExecCommand
+	// DiscoveredAt is when the cluster was found, recorded with the account and region in the ark extension
+	DiscoveredAt time.Time
+}
+
+// BuildArkExecConfig builds an exec block that resolves tokens through `ark kubernetes token`
+// Region and profile are embedded as arguments (and AWS_PROFILE env) so the token keeps resolving
+// to the right account even when AWS_PROFILE changes in the user's shell
+func BuildArkExecConfig(command, clusterName, region, profile string) *ExecConfig {
+	if command == "" {
+		command = DefaultExecCommand
+	}
+
+	args := []string{"kubernetes", "token", "--cluster-name", clusterName, "--region", region}
+	var env []ExecEnvVar
+	if profile != "" {
+		args = append(args, "--profile", profile)
+		env = append(env, ExecEnvVar{Name: "AWS_PROFILE", Value: profile})
+	}
+
+	return &ExecConfig{
+		APIVersion:      "client.authentication.k8s.io/v1beta1",
+		Command:         command,
+		Args:            args,
+		Env:             env,
+		InteractiveMode: "Never",
+	}
+}
+
+// UpsertEKSContext adds or replaces the cluster, user and context entries for an EKS cluster
+// The cluster and context are marked as managed by ark in their extensions
+func (k *Kubeconfig) UpsertEKSContext(opts EKSContextOptions) {
+	entryName := opts.ARN
+	if entryName == "" {
+		entryName = opts.Alias
+	}
+
+	k.UpsertCluster(NamedCluster{
+		Name: entryName,
+		Cluster: KubeCluster{
+			Server:                   opts.Endpoint,
+			CertificateAuthorityData: opts.CertificateAuthorityData,
+		},
+	})
+
+	user := KubeUser{}
+	if opts.AuthMode == AuthModeStatic {
+		user.Token = opts.StaticToken
+	} else {
+		user.Exec = BuildArkExecConfig(opts.ExecCommand, opts.ClusterName, opts.Region, opts.Profile)
+	}
+	k.UpsertUser(NamedUser{Name: entryName, User: user})
+
+	k.UpsertContext(NamedContext{
+		Name: opts.Alias,
+		Context: KubeContext{
+			Cluster:   entryName,
+			User:      entryName,
+			Namespace: opts.Namespace,
+		},
+	})
+	accountID := opts.AccountID
+	if fromARN, ok := parseEKSClusterARN(opts.ARN); ok && accountID == "" {
+		accountID = fromARN.AccountID
+	}
+	k.MarkManaged(opts.Alias, ArkMetadata{
+		AccountID:    accountID,
+		Region:       opts.Region,
+		Cluster:      opts.ClusterName,
+		DiscoveredAt: opts.DiscoveredAt,
+	})
+}
+
+// ClusterContexts returns every context of the kubeconfig with the AWS details found in its user
+// Profile, region and cluster name are read from exec blocks written by ark or the AWS CLI,
+// the account from the cluster ARN of contexts written by ark setup
+func (k *Kubeconfig) ClusterContexts() []ClusterContext {
+	contexts := make([]ClusterContext, 0, len(k.Contexts))
+	for _, entry := range k.Contexts {
+		clusterContext := ClusterContext{
+			Name:    entry.Name,
+			Current: entry.Name == k.CurrentContext,
+		}
+		if user := k.FindUser(entry.Context.User); user != nil && user.User.Exec != nil {
+			clusterContext.Profile, clusterContext.Region, clusterContext.ClusterName = parseExecDetails(user.User.Exec)
+		}
+		if data, ok := k.ManagedContextData(entry); ok {
+			clusterContext.AccountID = data.AccountID
+			if _, ok := parseEKSClusterARN(entry.Context.Cluster); ok {
+				clusterContext.ClusterARN = entry.Context.Cluster
+			}
+			if clusterContext.Region == "" {
+				clusterContext.Region = data.Region
+			}
+			if clusterContext.ClusterName == "" {
+				clusterContext.ClusterName = data.Cluster
+			}
+		}
+		contexts = append(contexts, clusterContext)
+	}
+	return contexts
+}
+
+// parseExecDetails extracts the AWS profile, region and cluster name from an exec block
+func parseExecDetails(exec *ExecConfig) (profile, region, clusterName string) {
+	for i := 0; i < len(exec.Args)-1; i++ {
+		switch exec.Args[i] {
+		case "--profile":
+			profile = exec.Args[i+1]
+		case "--region":
+			region = exec.Args[i+1]
+		case "--cluster-name":
+			clusterName = exec.Args[i+1]
+		}
+	}
+	if profile == "" {
+		for _, env := range exec.Env {
+			if env.Name == "AWS_PROFILE" {
+				profile = env.Value
+			}
+		}
+	}
+	return profile, region, clusterName
+}
@@ -0,0 +1,114 @@
+package services_kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildArkExecConfig(t *testing.T) {
+	exec := BuildArkExecConfig("", "prod", "us-east-1", "prod-readonly")
+
+	assert.Equal(t, DefaultExecCommand, exec.Command)
+	assert.Equal(t, "client.authentication.k8s.io/v1beta1", exec.APIVersion)
+	assert.Equal(t, []string{
+		"kubernetes", "token",
+		"--cluster-name", "prod",
+		"--region", "us-east-1",
+		"--profile", "prod-readonly",
+	}, exec.Args)
+	require.Len(t, exec.Env, 1)
+	assert.Equal(t, ExecEnvVar{Name: "AWS_PROFILE", Value: "prod-readonly"}, exec.Env[0])
+
+	// Without a profile the default credential chain is used
+	exec = BuildArkExecConfig("/usr/local/bin/ark", "prod", "us-east-1", "")
+	assert.Equal(t, "/usr/local/bin/ark", exec.Command)
+	assert.NotContains(t, exec.Args, "--profile")
+	assert.Empty(t, exec.Env)
+}
+
+func TestUpsertEKSContext(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     EKSContextOptions
+		validate func(t *testing.T, k *Kubeconfig)
+	}{
+		{
+			name: "exec mode uses ARN for cluster and user entries",
+			opts: EKSContextOptions{
+				Alias:                    "prod",
+				ClusterName:              "prod",
+				ARN:                      "arn:aws:eks:us-east-1:123456789012:cluster/prod",
+				Endpoint:                 "https://prod.example.com",
+				CertificateAuthorityData: "Y2E=",
+				Region:                   "us-east-1",
+				Profile:                  "prod-readonly",
+				AuthMode:                 AuthModeExec,
+			},
+			validate: func(t *testing.T, k *Kubeconfig) {
+				arn := "arn:aws:eks:us-east-1:123456789012:cluster/prod"
+				ctx := k.FindContext("prod")
+				require.NotNil(t, ctx)
+				assert.Equal(t, arn, ctx.Context.Cluster)
+				assert.Equal(t, arn, ctx.Context.User)
+				assert.Equal(t, "https://prod.example.com", k.FindCluster(arn).Cluster.Server)
+				assert.Equal(t, "Y2E=", k.FindCluster(arn).Cluster.CertificateAuthorityData)
+				user := k.FindUser(arn)
+				require.NotNil(t, user.User.Exec)
+				assert.Empty(t, user.User.Token)
+			},
+		},
+		{
+			name: "static mode embeds the token",
+			opts: EKSContextOptions{
+				Alias:       "debug",
+				ClusterName: "debug",
+				Endpoint:    "https://debug.example.com",
+				Region:      "us-west-2",
+				AuthMode:    AuthModeStatic,
+				StaticToken: "k8s-aws-v1.abc",
+			},
+			validate: func(t *testing.T, k *Kubeconfig) {
+				user := k.FindUser("debug")
+				require.NotNil(t, user)
+				assert.Equal(t, "k8s-aws-v1.abc", user.User.Token)
+				assert.Nil(t, user.User.Exec)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kubeconfig := NewKubeconfig()
+			kubeconfig.UpsertEKSContext(tt.opts)
+			tt.validate(t, kubeconfig)
+		})
+	}
+}
+
+func TestClusterContexts(t *testing.T) {
+	kubeconfig := NewKubeconfig()
+	kubeconfig.UpsertEKSContext(EKSContextOptions{
+		Alias:       "prod",
+		ClusterName: "prod-cluster",
+		Region:      "us-east-1",
+		Profile:     "prod-readonly",
+	})
+	// Shape written by `aws eks update-kubeconfig`
+	kubeconfig.UpsertUser(NamedUser{Name: "aws-cli", User: KubeUser{Exec: &ExecConfig{
+		Command: "aws",
+		Args:    []string{"--region", "eu-west-1", "eks", "get-token", "--cluster-name", "dev-cluster", "--output", "json"},
+		Env:     []ExecEnvVar{{Name: "AWS_PROFILE", Value: "dev"}},
+	}}})
+	kubeconfig.UpsertContext(NamedContext{Name: "dev", Context: KubeContext{Cluster: "arn:aws:eks:eu-west-1:111111111111:cluster/dev-cluster", User: "aws-cli"}})
+	kubeconfig.UpsertContext(NamedContext{Name: "kind", Context: KubeContext{User: "kind"}})
+	kubeconfig.CurrentContext = "dev"
+
+	contexts := kubeconfig.ClusterContexts()
+	require.Len(t, contexts, 3)
+	assert.Equal(t, ClusterContext{Name: "prod", Profile: "prod-readonly", Region: "us-east-1", ClusterName: "prod-cluster"}, contexts[0])
+	assert.Equal(t, ClusterContext{Name: "dev", Current: true, Profile: "dev", Region: "eu-west-1", ClusterName: "dev-cluster",
+		AccountID: "111111111111", ClusterARN: "arn:aws:eks:eu-west-1:111111111111:cluster/dev-cluster"}, contexts[1])
+	assert.Equal(t, ClusterContext{Name: "kind"}, contexts[2])
+}
@@ -0,0 +1,146 @@
+package services_kubernetes
+
+import (
+	"slices"
+	"time"
+)
+
+// ArkExtensionName is the name of the kubeconfig extension holding the metadata of entries written by ark
+const ArkExtensionName = "ark"
+
+// managedByArk is the managed-by value of the ark extension
+const managedByArk = "ark"
+
+// NamedExtension is an entry of the extensions list of a kubeconfig cluster or context
+// The extension is kept as a map so extensions of other tools (e.g. minikube) round-trip unchanged
+type NamedExtension struct {
+	Name      string                 `yaml:"name"`
+	Extension map[string]interface{} `yaml:"extension"`
+}
+
+// Extensions is the extensions list of a kubeconfig cluster or context
+type Extensions []NamedExtension
+
+// ArkMetadata is what ark records about the clusters and contexts it writes, so later commands can recognize
+// them without parsing names or ARNs
+type ArkMetadata struct {
+	AccountID    string
+	Region       string
+	Cluster      string
+	DiscoveredAt time.Time
+}
+
+// Ark returns the metadata of the ark extension, and whether the entry has one
+func (e Extensions) Ark() (ArkMetadata, bool) {
+	for _, extension := range e {
+		if extension.Name != ArkExtensionName {
+			continue
+		}
+		fields := extension.Extension
+		if stringField(fields, "managed-by") != managedByArk {
+			return ArkMetadata{}, false
+		}
+		metadata := ArkMetadata{
+			AccountID: stringField(fields, "account"),
+			Region:    stringField(fields, "region"),
+			Cluster:   stringField(fields, "cluster"),
+		}
+		// yaml.v3 decodes unquoted timestamps as time.Time and quoted ones as strings
+		switch discoveredAt := fields["discovered-at"].(type) {
+		case time.Time:
+			metadata.DiscoveredAt = discoveredAt
+		case string:
+			metadata.DiscoveredAt, _ = time.Parse(time.RFC3339, discoveredAt)
+		}
+		return metadata, true
+	}
+	return ArkMetadata{}, false
+}
+
+// WithArk returns the extensions with the ark extension set to metadata, keeping the others
+func (e Extensions) WithArk(metadata ArkMetadata) Extensions {
+	extension := NamedExtension{
+		Name: ArkExtensionName,
+		Extension: map[string]interface{}{
+			"managed-by":    managedByArk,
+			"account":       metadata.AccountID,
+			"region":        metadata.Region,
+			"cluster":       metadata.Cluster,
+			"discovered-at": metadata.DiscoveredAt.UTC().Format(time.RFC3339),
+		},
+	}
+
+	updated := make(Extensions, 0, len(e)+1)
+	for _, existing := range e {
+		if existing.Name != ArkExtensionName {
+			updated = append(updated, existing)
+		}
+	}
+	return append(updated, extension)
+}
+
+// MarkManaged records metadata in the ark extension of a context and of the cluster entry it points to
+func (k *Kubeconfig) MarkManaged(contextName string, metadata ArkMetadata) {
+	entry := k.FindContext(contextName)
+	if entry == nil {
+		return
+	}
+	entry.Context.Extensions = entry.Context.Extensions.WithArk(metadata)
+	if cluster := k.FindCluster(entry.Context.Cluster); cluster != nil {
+		cluster.Cluster.Extensions = cluster.Cluster.Extensions.WithArk(metadata)
+	}
+}
+
+// RemoveManagedContexts removes the contexts marked as managed by ark, with the clusters and users only they
+// used, and returns their names. The current context is cleared when it is one of them. Contexts recognized
+// only by their name or cluster ARN are kept: they may have been written by the AWS CLI
+func (k *Kubeconfig) RemoveManagedContexts() []string {
+	var removed []string
+	kept := k.Contexts[:0]
+	orphanClusters := make(map[string]bool)
+	orphanUsers := make(map[string]bool)
+	for _, entry := range k.Contexts {
+		if _, ok := entry.Context.Extensions.Ark(); !ok {
+			kept = append(kept, entry)
+			continue
+		}
+		removed = append(removed, entry.Name)
+		orphanClusters[entry.Context.Cluster] = true
+		orphanUsers[entry.Context.User] = true
+		if k.CurrentContext == entry.Name {
+			k.CurrentContext = ""
+		}
+	}
+	k.Contexts = kept
+
+	for _, entry := range k.Contexts {
+		delete(orphanClusters, entry.Context.Cluster)
+		delete(orphanUsers, entry.Context.User)
+	}
+	k.Clusters = slices.DeleteFunc(k.Clusters, func(entry NamedCluster) bool { return orphanClusters[entry.Name] })
+	k.Users = slices.DeleteFunc(k.Users, func(entry NamedUser) bool { return orphanUsers[entry.Name] })
+	return removed
+}
+
+// RemoveManagedContextsFromFile removes the contexts managed by ark from a kubeconfig file, see
+// RemoveManagedContexts. A missing file has nothing to remove
+func RemoveManagedContextsFromFile(path string, dryRun bool) ([]string, error) {
+	kubeconfig, err := LoadKubeconfig(path)
+	if err != nil {
+		return nil, err
+	}
+	removed := kubeconfig.RemoveManagedContexts()
+	if len(removed) == 0 || dryRun {
+		return removed, nil
+	}
+	if err := SaveKubeconfig(path, kubeconfig); err != nil {
+		return nil, err
+	}
+	return removed, nil
+}
+
+// stringField returns a string field of an extension, empty when missing or of another type
+func stringField(fields map[string]interface{}, key string) string {
+	value, _ := fields[key].(string)
+	return value
+}
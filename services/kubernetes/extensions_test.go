@@ -0,0 +1,145 @@
+package services_kubernetes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+func TestExtensionsArk(t *testing.T) {
+	discoveredAt := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	minikube := NamedExtension{Name: "cluster_info", Extension: map[string]interface{}{"provider": "minikube.sigs.k8s.io"}}
+	metadata := ArkMetadata{AccountID: "111111111111", Region: "us-east-1", Cluster: "prod", DiscoveredAt: discoveredAt}
+
+	extensions := Extensions{minikube}.WithArk(ArkMetadata{Cluster: "old"}).WithArk(metadata)
+	require.Len(t, extensions, 2, "the ark extension is replaced, others are kept")
+	assert.Equal(t, minikube, extensions[0])
+
+	got, ok := extensions.Ark()
+	require.True(t, ok)
+	assert.Equal(t, metadata, got)
+
+	_, ok = Extensions{minikube}.Ark()
+	assert.False(t, ok)
+	_, ok = Extensions{{Name: ArkExtensionName, Extension: map[string]interface{}{"managed-by": "someone-else"}}}.Ark()
+	assert.False(t, ok, "only entries managed by ark count")
+}
+
+func TestMarkManagedRoundTrip(t *testing.T) {
+	discoveredAt := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	kubeconfig := NewKubeconfig()
+	kubeconfig.UpsertEKSContext(EKSContextOptions{
+		Alias:        "prod",
+		ClusterName:  "prod-cluster",
+		ARN:          "arn:aws:eks:us-east-1:111111111111:cluster/prod-cluster",
+		Endpoint:     "https://prod.eks.amazonaws.com",
+		Region:       "us-east-1",
+		Profile:      "prod-readonly",
+		DiscoveredAt: discoveredAt,
+	})
+	kubeconfig.UpsertCluster(NamedCluster{Name: "minikube", Cluster: KubeCluster{
+		Server:     "https://192.168.49.2:8443",
+		Extensions: Extensions{{Name: "cluster_info", Extension: map[string]interface{}{"provider": "minikube.sigs.k8s.io"}}},
+	}})
+	kubeconfig.UpsertContext(NamedContext{Name: "minikube", Context: KubeContext{Cluster: "minikube", User: "minikube"}})
+
+	path := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, SaveKubeconfig(path, kubeconfig))
+	loaded, err := LoadKubeconfig(path)
+	require.NoError(t, err)
+
+	want := ArkMetadata{AccountID: "111111111111", Region: "us-east-1", Cluster: "prod-cluster", DiscoveredAt: discoveredAt}
+	metadata, ok := loaded.FindContext("prod").Context.Extensions.Ark()
+	require.True(t, ok)
+	assert.Equal(t, want, metadata, "the account is taken from the ARN when not given")
+	metadata, ok = loaded.FindCluster("arn:aws:eks:us-east-1:111111111111:cluster/prod-cluster").Cluster.Extensions.Ark()
+	require.True(t, ok)
+	assert.Equal(t, want, metadata)
+	assert.Equal(t, "minikube.sigs.k8s.io", loaded.FindCluster("minikube").Cluster.Extensions[0].Extension["provider"])
+
+	// kubectl (client-go) must keep loading files with extensions
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	config, err := clientcmd.Load(raw)
+	require.NoError(t, err)
+	assert.Contains(t, config.Contexts["prod"].Extensions, ArkExtensionName)
+}
+
+func TestManagedContextDataFromExtension(t *testing.T) {
+	kubeconfig := NewKubeconfig()
+	// Without an ARN, the cluster entry is named after the alias and only the extension tells it apart
+	kubeconfig.UpsertEKSContext(EKSContextOptions{
+		Alias:       "data",
+		ClusterName: "data-cluster",
+		Region:      "eu-west-1",
+		AccountID:   "222222222222",
+		Profile:     "data-readonly",
+	})
+
+	data, ok := kubeconfig.ManagedContextData(*kubeconfig.FindContext("data"))
+	require.True(t, ok)
+	assert.Equal(t, ContextAliasData{Cluster: "data-cluster", Region: "eu-west-1", AccountID: "222222222222", Profile: "data-readonly"}, data)
+
+	_, ok = kubeconfig.ManagedContextData(NamedContext{Name: "kind", Context: KubeContext{Cluster: "kind"}})
+	assert.False(t, ok)
+}
+
+func TestRemoveManagedContexts(t *testing.T) {
+	kubeconfig := NewKubeconfig()
+	kubeconfig.UpsertEKSContext(EKSContextOptions{
+		Alias:       "prod",
+		ClusterName: "prod",
+		ARN:         "arn:aws:eks:us-east-1:111111111111:cluster/prod",
+		Region:      "us-east-1",
+	})
+	kubeconfig.UpsertEKSContext(EKSContextOptions{
+		Alias:       "staging",
+		ClusterName: "staging",
+		ARN:         "arn:aws:eks:us-east-1:111111111111:cluster/staging",
+		Region:      "us-east-1",
+	})
+	// Written by the AWS CLI: same ARN naming, but no extension
+	kubeconfig.UpsertCluster(NamedCluster{Name: "arn:aws:eks:eu-west-1:111111111111:cluster/dev"})
+	kubeconfig.UpsertUser(NamedUser{Name: "arn:aws:eks:eu-west-1:111111111111:cluster/dev"})
+	kubeconfig.UpsertContext(NamedContext{Name: "dev", Context: KubeContext{
+		Cluster: "arn:aws:eks:eu-west-1:111111111111:cluster/dev",
+		User:    "arn:aws:eks:eu-west-1:111111111111:cluster/dev",
+	}})
+	// A hand-written context sharing the user of an ark context keeps it
+	kubeconfig.UpsertContext(NamedContext{Name: "prod-admin", Context: KubeContext{
+		Cluster: "arn:aws:eks:us-east-1:111111111111:cluster/prod",
+		User:    "arn:aws:eks:us-east-1:111111111111:cluster/prod",
+	}})
+	kubeconfig.CurrentContext = "prod"
+
+	path := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, SaveKubeconfig(path, kubeconfig))
+
+	removed, err := RemoveManagedContextsFromFile(path, true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"prod", "staging"}, removed)
+	unchanged, err := LoadKubeconfig(path)
+	require.NoError(t, err)
+	assert.NotNil(t, unchanged.FindContext("prod"), "a dry run changes nothing")
+
+	removed, err = RemoveManagedContextsFromFile(path, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"prod", "staging"}, removed)
+	loaded, err := LoadKubeconfig(path)
+	require.NoError(t, err)
+	assert.Nil(t, loaded.FindContext("prod"))
+	assert.NotNil(t, loaded.FindContext("dev"), "contexts without the ark extension are kept")
+	assert.NotNil(t, loaded.FindCluster("arn:aws:eks:us-east-1:111111111111:cluster/prod"), "entries still used are kept")
+	assert.Nil(t, loaded.FindCluster("arn:aws:eks:us-east-1:111111111111:cluster/staging"), "entries only ark contexts used go away")
+	assert.Nil(t, loaded.FindUser("arn:aws:eks:us-east-1:111111111111:cluster/staging"))
+	assert.Empty(t, loaded.CurrentContext)
+
+	removed, err = RemoveManagedContextsFromFile(filepath.Join(t.TempDir(), "missing"), false)
+	require.NoError(t, err)
+	assert.Empty(t, removed)
+}
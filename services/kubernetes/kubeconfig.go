@@ -0,0 +1,277 @@
+package services_kubernetes
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/andresgarcia29/ark-cli/logs"
+	"gopkg.in/yaml.v3"
+)
+
+// Kubeconfig is the subset of the kubeconfig file format that ark reads and writes
+// Unknown fields are preserved through the inline Extra maps
+type Kubeconfig struct {
+	APIVersion     string                 `yaml:"apiVersion"`
+	Kind           string                 `yaml:"kind"`
+	Preferences    map[string]interface{} `yaml:"preferences"`
+	Clusters       []NamedCluster         `yaml:"clusters"`
+	Contexts       []NamedContext         `yaml:"contexts"`
+	Users          []NamedUser            `yaml:"users"`
+	CurrentContext string                 `yaml:"current-context"`
+	Extra          map[string]interface{} `yaml:",inline"`
+}
+
+// NamedCluster is a cluster entry in the kubeconfig
+type NamedCluster struct {
+	Name    string      `yaml:"name"`
+	Cluster KubeCluster `yaml:"cluster"`
+}
+
+// KubeCluster holds the connection information of a cluster
+type KubeCluster struct {
+	Server                   string                 `yaml:"server"`
+	CertificateAuthorityData string                 `yaml:"certificate-authority-data,omitempty"`
+	Extensions               Extensions             `yaml:"extensions,omitempty"`
+	Extra                    map[string]interface{} `yaml:",inline"`
+}
+
+// NamedContext is a context entry in the kubeconfig
+type NamedContext struct {
+	Name    string      `yaml:"name"`
+	Context KubeContext `yaml:"context"`
+}
+
+// KubeContext binds a cluster and a user
+type KubeContext struct {
+	Cluster    string                 `yaml:"cluster"`
+	User       string                 `yaml:"user"`
+	Namespace  string                 `yaml:"namespace,omitempty"`
+	Extensions Extensions             `yaml:"extensions,omitempty"`
+	Extra      map[string]interface{} `yaml:",inline"`
+}
+
+// NamedUser is a user entry in the kubeconfig
+type NamedUser struct {
+	Name string   `yaml:"name"`
+	User KubeUser `yaml:"user"`
+}
+
+// KubeUser holds the authentication information of a user
+type KubeUser struct {
+	Token string                 `yaml:"token,omitempty"`
+	Exec  *ExecConfig            `yaml:"exec,omitempty"`
+	Extra map[string]interface{} `yaml:",inline"`
+}
+
+// ExecConfig is an exec credential plugin configuration
+type ExecConfig struct {
+	APIVersion      string                 `yaml:"apiVersion"`
+	Command         string                 `yaml:"command"`
+	Args            []string               `yaml:"args,omitempty"`
+	Env             []ExecEnvVar           `yaml:"env,omitempty"`
+	InteractiveMode string                 `yaml:"interactiveMode,omitempty"`
+	Extra           map[string]interface{} `yaml:",inline"`
+}
+
+// ExecEnvVar is an environment variable passed to an exec credential plugin
+type ExecEnvVar struct {
+	Name  string `yaml:"name"`
+	Value string `yaml:"value"`
+}
+
+// ResolveKubeconfigPath expands ~ and falls back to DefaultKubeconfigPath when path is empty
+func ResolveKubeconfigPath(path string) (string, error) {
+	if path == "" {
+		return DefaultKubeconfigPath()
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	if path == "~" {
+		return homeDir, nil
+	}
+	if strings.HasPrefix(path, "~/") {
+		return filepath.Join(homeDir, path[2:]), nil
+	}
+	return path, nil
+}
+
+// LoadKubeconfig reads a kubeconfig file, returning an empty config when it doesn't exist
+func LoadKubeconfig(path string) (*Kubeconfig, error) {
+	logger := logs.GetLogger()
+
+	resolved, err := ResolveKubeconfigPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(resolved)
+	if os.IsNotExist(err) {
+		logger.Debugw("Kubeconfig does not exist, starting empty", "path", resolved)
+		return NewKubeconfig(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read kubeconfig: %w", err)
+	}
+
+	kubeconfig := NewKubeconfig()
+	if err := yaml.Unmarshal(data, kubeconfig); err != nil {
+		return nil, fmt.Errorf("failed to parse kubeconfig %s: %w", resolved, err)
+	}
+	if kubeconfig.APIVersion == "" {
+		kubeconfig.APIVersion = "v1"
+	}
+	if kubeconfig.Kind == "" {
+		kubeconfig.Kind = "Config"
+	}
+
+	logger.Debugw("Kubeconfig loaded", "path", resolved, "contexts", len(kubeconfig.Contexts))
+	return kubeconfig, nil
+}
+
+// NewKubeconfig returns an empty kubeconfig
+func NewKubeconfig() *Kubeconfig {
+	return &Kubeconfig{
+		APIVersion:  "v1",
+		Kind:        "Config",
+		Preferences: map[string]interface{}{},
+	}
+}
+
+// SaveKubeconfig writes the kubeconfig atomically with restrictive permissions
+func SaveKubeconfig(path string, kubeconfig *Kubeconfig) error {
+	logger := logs.GetLogger()
+
+	resolved, err := ResolveKubeconfigPath(path)
+	if err != nil {
+		return err
+	}
+	// The rename below would replace a symlinked kubeconfig (e.g. one kept in a dotfiles repo) with a regular
+	// file, so the file it points to is written instead
+	if target, err := filepath.EvalSymlinks(resolved); err == nil {
+		resolved = target
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to resolve kubeconfig path: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(resolved), 0700); err != nil {
+		return fmt.Errorf("failed to create kubeconfig directory: %w", err)
+	}
+
+	data, err := yaml.Marshal(kubeconfig)
+	if err != nil {
+		return fmt.Errorf("failed to marshal kubeconfig: %w", err)
+	}
+
+	// Write to a temporary file and rename so a crash never leaves a truncated kubeconfig; the temporary file is
+	// unique, so concurrent writers never write into each other's
+	tmp, err := os.CreateTemp(filepath.Dir(resolved), "."+filepath.Base(resolved)+".ark-tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write kubeconfig: %w", err)
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0600)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write kubeconfig: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), resolved); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to replace kubeconfig: %w", err)
+	}
+
+	logger.Debugw("Kubeconfig saved", "path", resolved, "contexts", len(kubeconfig.Contexts))
+	return nil
+}
+
+// UpsertCluster adds or replaces a cluster entry by name
+func (k *Kubeconfig) UpsertCluster(entry NamedCluster) {
+	for i := range k.Clusters {
+		if k.Clusters[i].Name == entry.Name {
+			k.Clusters[i] = entry
+			return
+		}
+	}
+	k.Clusters = append(k.Clusters, entry)
+}
+
+// UpsertContext adds or replaces a context entry by name
+func (k *Kubeconfig) UpsertContext(entry NamedContext) {
+	for i := range k.Contexts {
+		if k.Contexts[i].Name == entry.Name {
+			k.Contexts[i] = entry
+			return
+		}
+	}
+	k.Contexts = append(k.Contexts, entry)
+}
+
+// UpsertUser adds or replaces a user entry by name
+func (k *Kubeconfig) UpsertUser(entry NamedUser) {
+	for i := range k.Users {
+		if k.Users[i].Name == entry.Name {
+			k.Users[i] = entry
+			return
+		}
+	}
+	k.Users = append(k.Users, entry)
+}
+
+// Merge upserts every cluster, context and user of other into k
+// The current context is taken from other when it sets one
+func (k *Kubeconfig) Merge(other *Kubeconfig) {
+	for _, cluster := range other.Clusters {
+		k.UpsertCluster(cluster)
+	}
+	for _, context := range other.Contexts {
+		k.UpsertContext(context)
+	}
+	for _, user := range other.Users {
+		k.UpsertUser(user)
+	}
+	if other.CurrentContext != "" {
+		k.CurrentContext = other.CurrentContext
+	}
+}
+
+// FindContext returns the context with the given name, or nil
+func (k *Kubeconfig) FindContext(name string) *NamedContext {
+	for i := range k.Contexts {
+		if k.Contexts[i].Name == name {
+			return &k.Contexts[i]
+		}
+	}
+	return nil
+}
+
+// FindCluster returns the cluster with the given name, or nil
+func (k *Kubeconfig) FindCluster(name string) *NamedCluster {
+	for i := range k.Clusters {
+		if k.Clusters[i].Name == name {
+			return &k.Clusters[i]
+		}
+	}
+	return nil
+}
+
+// FindUser returns the user with the given name, or nil
+func (k *Kubeconfig) FindUser(name string) *NamedUser {
+	for i := range k.Users {
+		if k.Users[i].Name == name {
+			return &k.Users[i]
+		}
+	}
+	return nil
+}
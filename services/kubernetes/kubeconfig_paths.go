@@ -0,0 +1,110 @@
+package services_kubernetes
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	ark_config "github.com/andresgarcia29/ark-cli/config"
+	"github.com/andresgarcia29/ark-cli/logs"
+)
+
+// KubeconfigEnv lists kubeconfig files, separated like PATH, that kubectl merges into one view
+const KubeconfigEnv = "KUBECONFIG"
+
+// KubeconfigPaths returns the files of the merged kubeconfig view: the KUBECONFIG list, or ~/.kube/config
+// Empty and duplicate entries are ignored, as kubectl does
+func KubeconfigPaths() ([]string, error) {
+	var paths []string
+	seen := make(map[string]bool)
+	for _, entry := range filepath.SplitList(os.Getenv(KubeconfigEnv)) {
+		if entry == "" {
+			continue
+		}
+		path, err := ResolveKubeconfigPath(entry)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[path] {
+			seen[path] = true
+			paths = append(paths, path)
+		}
+	}
+	if len(paths) > 0 {
+		return paths, nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return []string{filepath.Join(homeDir, ".kube", "config")}, nil
+}
+
+// DefaultKubeconfigPath returns the file ark writes its contexts to when no path is given:
+// kubernetes.kubeconfig_file from the ark config, or the file kubectl would write new entries to
+func DefaultKubeconfigPath() (string, error) {
+	if designated := ark_config.Get().Kubernetes.KubeconfigFile; designated != "" {
+		return ResolveKubeconfigPath(designated)
+	}
+
+	paths, err := KubeconfigPaths()
+	if err != nil {
+		return "", err
+	}
+	return kubeconfigWriteTarget(paths), nil
+}
+
+// kubeconfigWriteTarget applies kubectl's rule for new entries: the first file of the list that exists,
+// or the last one when none does
+func kubeconfigWriteTarget(paths []string) string {
+	for _, path := range paths {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return paths[len(paths)-1]
+}
+
+// LoadMergedKubeconfig reads every file of KubeconfigPaths into one view, like `kubectl config view`
+// The first file defining a cluster, context or user wins, and so does the first current-context
+func LoadMergedKubeconfig() (*Kubeconfig, error) {
+	paths, err := KubeconfigPaths()
+	if err != nil {
+		return nil, err
+	}
+
+	merged := NewKubeconfig()
+	for _, path := range paths {
+		kubeconfig, err := LoadKubeconfig(path)
+		if err != nil {
+			return nil, err
+		}
+		merged.mergeMissing(kubeconfig)
+	}
+
+	logs.GetLogger().Debugw("Merged kubeconfig loaded", "files", paths, "contexts", len(merged.Contexts))
+	return merged, nil
+}
+
+// mergeMissing adds the entries of other that k doesn't define yet, keeping k's current context when set
+func (k *Kubeconfig) mergeMissing(other *Kubeconfig) {
+	for _, cluster := range other.Clusters {
+		if k.FindCluster(cluster.Name) == nil {
+			k.Clusters = append(k.Clusters, cluster)
+		}
+	}
+	for _, context := range other.Contexts {
+		if k.FindContext(context.Name) == nil {
+			k.Contexts = append(k.Contexts, context)
+		}
+	}
+	for _, user := range other.Users {
+		if k.FindUser(user.Name) == nil {
+			k.Users = append(k.Users, user)
+		}
+	}
+	if k.CurrentContext == "" {
+		k.CurrentContext = other.CurrentContext
+	}
+}
@@ -0,0 +1,85 @@
+package services_kubernetes
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKubeconfigPaths(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	t.Setenv(KubeconfigEnv, "")
+	paths, err := KubeconfigPaths()
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(home, ".kube", "config")}, paths)
+
+	list := strings.Join([]string{"~/.kube/work", "", "/etc/kube/shared", "~/.kube/work"}, string(os.PathListSeparator))
+	t.Setenv(KubeconfigEnv, list)
+	paths, err = KubeconfigPaths()
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(home, ".kube", "work"), "/etc/kube/shared"}, paths)
+}
+
+func TestKubeconfigWriteTarget(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first")
+	second := filepath.Join(dir, "second")
+	last := filepath.Join(dir, "last")
+
+	// Like kubectl: the last file when none exists, otherwise the first existing one
+	assert.Equal(t, last, kubeconfigWriteTarget([]string{first, second, last}))
+
+	require.NoError(t, os.WriteFile(second, nil, 0600))
+	assert.Equal(t, second, kubeconfigWriteTarget([]string{first, second, last}))
+
+	require.NoError(t, os.WriteFile(first, nil, 0600))
+	assert.Equal(t, first, kubeconfigWriteTarget([]string{first, second, last}))
+}
+
+func TestResolveKubeconfigPathFromList(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	personal := filepath.Join(dir, "personal")
+	ark := filepath.Join(dir, "ark")
+	require.NoError(t, os.WriteFile(ark, nil, 0600))
+	t.Setenv(KubeconfigEnv, personal+string(os.PathListSeparator)+ark)
+
+	path, err := ResolveKubeconfigPath("")
+	require.NoError(t, err)
+	assert.Equal(t, ark, path)
+}
+
+func TestLoadMergedKubeconfig(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first")
+	second := filepath.Join(dir, "second")
+	require.NoError(t, os.WriteFile(first, []byte(`apiVersion: v1
+kind: Config
+contexts:
+- name: shared
+  context:
+    cluster: from-first
+    user: from-first
+`), 0600))
+	// The second file defines the same context name, plus its own context and current-context
+	secondContent := strings.Replace(sampleKubeconfig, "contexts:\n", "contexts:\n- name: shared\n  context:\n    cluster: from-second\n    user: from-second\n", 1)
+	require.NoError(t, os.WriteFile(second, []byte(secondContent), 0600))
+
+	missing := filepath.Join(dir, "missing")
+	t.Setenv(KubeconfigEnv, strings.Join([]string{first, missing, second}, string(os.PathListSeparator)))
+
+	merged, err := LoadMergedKubeconfig()
+	require.NoError(t, err)
+
+	require.Len(t, merged.Contexts, 2)
+	assert.Equal(t, "from-first", merged.FindContext("shared").Context.Cluster, "the first file defining an entry wins")
+	assert.NotNil(t, merged.FindContext("existing"))
+	assert.NotNil(t, merged.FindCluster("existing"))
+	assert.Equal(t, "existing", merged.CurrentContext)
+}
@@ -0,0 +1,153 @@
+package services_kubernetes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sampleKubeconfig = `apiVersion: v1
+kind: Config
+preferences: {}
+clusters:
+- name: existing
+  cluster:
+    server: https://existing.example.com
+    insecure-skip-tls-verify: true
+contexts:
+- name: existing
+  context:
+    cluster: existing
+    user: existing
+users:
+- name: existing
+  user:
+    client-certificate-data: Y2VydA==
+current-context: existing
+`
+
+func TestResolveKubeconfigPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(KubeconfigEnv, "")
+
+	path, err := ResolveKubeconfigPath("")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, ".kube", "config"), path)
+
+	path, err = ResolveKubeconfigPath("~/.kube/other")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, ".kube", "other"), path)
+
+	path, err = ResolveKubeconfigPath("/tmp/config")
+	require.NoError(t, err)
+	assert.Equal(t, "/tmp/config", path)
+}
+
+func TestLoadKubeconfigMissingFile(t *testing.T) {
+	kubeconfig, err := LoadKubeconfig(filepath.Join(t.TempDir(), "missing"))
+	require.NoError(t, err)
+	assert.Equal(t, "v1", kubeconfig.APIVersion)
+	assert.Equal(t, "Config", kubeconfig.Kind)
+	assert.Empty(t, kubeconfig.Contexts)
+}
+
+func TestKubeconfigRoundTripPreservesUnknownFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, os.WriteFile(path, []byte(sampleKubeconfig), 0600))
+
+	kubeconfig, err := LoadKubeconfig(path)
+	require.NoError(t, err)
+	require.Len(t, kubeconfig.Contexts, 1)
+	assert.Equal(t, "existing", kubeconfig.CurrentContext)
+
+	kubeconfig.UpsertEKSContext(EKSContextOptions{
+		Alias:       "new-cluster",
+		ClusterName: "new-cluster",
+		ARN:         "arn:aws:eks:us-west-2:123456789012:cluster/new-cluster",
+		Endpoint:    "https://new.example.com",
+		Region:      "us-west-2",
+		Profile:     "dev",
+		AuthMode:    AuthModeExec,
+	})
+	require.NoError(t, SaveKubeconfig(path, kubeconfig))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	reloaded, err := LoadKubeconfig(path)
+	require.NoError(t, err)
+	assert.Len(t, reloaded.Contexts, 2)
+	assert.Equal(t, true, reloaded.FindCluster("existing").Cluster.Extra["insecure-skip-tls-verify"])
+	assert.Equal(t, "Y2VydA==", reloaded.FindUser("existing").User.Extra["client-certificate-data"])
+	assert.Equal(t, "existing", reloaded.CurrentContext)
+}
+
+func TestSaveKubeconfigKeepsSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "dotfiles", "kubeconfig")
+	require.NoError(t, os.MkdirAll(filepath.Dir(target), 0700))
+	require.NoError(t, os.WriteFile(target, []byte(sampleKubeconfig), 0600))
+	link := filepath.Join(dir, "config")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks are not supported: %v", err)
+	}
+
+	kubeconfig, err := LoadKubeconfig(link)
+	require.NoError(t, err)
+	kubeconfig.UpsertContext(NamedContext{Name: "added", Context: KubeContext{Cluster: "existing"}})
+	require.NoError(t, SaveKubeconfig(link, kubeconfig))
+
+	info, err := os.Lstat(link)
+	require.NoError(t, err)
+	assert.Equal(t, os.ModeSymlink, info.Mode().Type(), "the kubeconfig is still a symlink")
+	reloaded, err := LoadKubeconfig(target)
+	require.NoError(t, err)
+	assert.NotNil(t, reloaded.FindContext("added"), "the file it points to was written")
+
+	entries, err := os.ReadDir(filepath.Dir(target))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temporary file is left behind")
+}
+
+func TestKubeconfigUpsertReplacesByName(t *testing.T) {
+	kubeconfig := NewKubeconfig()
+	kubeconfig.UpsertContext(NamedContext{Name: "a", Context: KubeContext{Cluster: "one"}})
+	kubeconfig.UpsertContext(NamedContext{Name: "a", Context: KubeContext{Cluster: "two"}})
+	kubeconfig.UpsertCluster(NamedCluster{Name: "c"})
+	kubeconfig.UpsertUser(NamedUser{Name: "u"})
+
+	require.Len(t, kubeconfig.Contexts, 1)
+	assert.Equal(t, "two", kubeconfig.FindContext("a").Context.Cluster)
+	assert.NotNil(t, kubeconfig.FindCluster("c"))
+	assert.NotNil(t, kubeconfig.FindUser("u"))
+	assert.Nil(t, kubeconfig.FindContext("missing"))
+}
+
+func TestKubeconfigMerge(t *testing.T) {
+	kubeconfig := NewKubeconfig()
+	kubeconfig.UpsertContext(NamedContext{Name: "a", Context: KubeContext{Cluster: "old"}})
+	kubeconfig.CurrentContext = "a"
+
+	fragment := NewKubeconfig()
+	fragment.UpsertCluster(NamedCluster{Name: "c"})
+	fragment.UpsertContext(NamedContext{Name: "a", Context: KubeContext{Cluster: "new"}})
+	fragment.UpsertContext(NamedContext{Name: "b", Context: KubeContext{Cluster: "c"}})
+	fragment.UpsertUser(NamedUser{Name: "u"})
+	fragment.CurrentContext = "b"
+
+	kubeconfig.Merge(fragment)
+
+	require.Len(t, kubeconfig.Contexts, 2)
+	assert.Equal(t, "new", kubeconfig.FindContext("a").Context.Cluster)
+	assert.NotNil(t, kubeconfig.FindCluster("c"))
+	assert.NotNil(t, kubeconfig.FindUser("u"))
+	assert.Equal(t, "b", kubeconfig.CurrentContext)
+
+	kubeconfig.Merge(NewKubeconfig())
+	assert.Equal(t, "b", kubeconfig.CurrentContext)
+}
@@ -0,0 +1,32 @@
+package services_kubernetes
+
+import (
+	"strings"
+
+	"github.com/andresgarcia29/ark-cli/lib"
+)
+
+// MatchContexts returns the contexts matching query, from the most to the least precise kind of match:
+// an exact name (ignoring case) wins, then names containing the query, then fuzzy matches
+// so that "prod" picks "prod" over "prod-eu" and "pdeu" still finds "prod-eu"
+func MatchContexts(contexts []ClusterContext, query string) []ClusterContext {
+	query = strings.ToLower(query)
+
+	var contains, fuzzy []ClusterContext
+	for _, context := range contexts {
+		name := strings.ToLower(context.Name)
+		switch {
+		case name == query:
+			return []ClusterContext{context}
+		case strings.Contains(name, query):
+			contains = append(contains, context)
+		case lib.FuzzyMatch(query, name):
+			fuzzy = append(fuzzy, context)
+		}
+	}
+
+	if len(contains) > 0 {
+		return contains
+	}
+	return fuzzy
+}
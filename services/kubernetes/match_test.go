@@ -0,0 +1,41 @@
+package services_kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchContexts(t *testing.T) {
+	contexts := []ClusterContext{
+		{Name: "prod"},
+		{Name: "prod-eu"},
+		{Name: "staging-us"},
+		{Name: "dev"},
+	}
+	names := func(matches []ClusterContext) []string {
+		result := make([]string, 0, len(matches))
+		for _, match := range matches {
+			result = append(result, match.Name)
+		}
+		return result
+	}
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{query: "prod", expected: []string{"prod"}},
+		{query: "PROD-EU", expected: []string{"prod-eu"}},
+		{query: "pro", expected: []string{"prod", "prod-eu"}},
+		{query: "-", expected: []string{"prod-eu", "staging-us"}},
+		{query: "stgus", expected: []string{"staging-us"}},
+		{query: "qa", expected: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			assert.Equal(t, tt.expected, names(MatchContexts(contexts, tt.query)))
+		})
+	}
+}
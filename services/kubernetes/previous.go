@@ -0,0 +1,60 @@
+package services_kubernetes
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/andresgarcia29/ark-cli/paths"
+)
+
+// ErrNoPreviousContext is returned when ark has not switched contexts yet
+var ErrNoPreviousContext = errors.New("no previous context recorded (switch once with ark first)")
+
+// previousContextFile is where the context active before the last switch is kept, like kubectx does
+const previousContextFile = "kubernetes-previous-context"
+
+// previousContextPath returns the state file holding the previous context
+func previousContextPath() (string, error) {
+	dir, err := paths.StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, previousContextFile), nil
+}
+
+// PreviousContext returns the context that was active before the last switch made by ark
+func PreviousContext() (string, error) {
+	path, err := previousContextPath()
+	if err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", ErrNoPreviousContext
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read previous context: %w", err)
+	}
+
+	name := strings.TrimSpace(string(data))
+	if name == "" {
+		return "", ErrNoPreviousContext
+	}
+	return name, nil
+}
+
+// savePreviousContext records the context being switched away from
+func savePreviousContext(name string) error {
+	path, err := previousContextPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	return os.WriteFile(path, []byte(name+"\n"), 0600)
+}
@@ -0,0 +1,25 @@
+package services_kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreviousContext(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	_, err := PreviousContext()
+	assert.ErrorIs(t, err, ErrNoPreviousContext)
+
+	require.NoError(t, savePreviousContext("staging"))
+	previous, err := PreviousContext()
+	require.NoError(t, err)
+	assert.Equal(t, "staging", previous)
+
+	require.NoError(t, savePreviousContext("prod"))
+	previous, err = PreviousContext()
+	require.NoError(t, err)
+	assert.Equal(t, "prod", previous)
+}
@@ -0,0 +1,84 @@
+package services_kubernetes
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// ContextState is the outcome of verifying a kubeconfig context
+type ContextState string
+
+const (
+	// ContextWorking means the context authenticates and may read the cluster
+	ContextWorking ContextState = "ok"
+	// ContextForbidden means the credentials are accepted but RBAC (or the access entry) grants nothing to read
+	ContextForbidden ContextState = "forbidden"
+	// ContextUnauthorized means the API server rejects the credentials
+	ContextUnauthorized ContextState = "unauthorized"
+	// ContextUnreachable means the context can't be loaded or the API server didn't answer
+	ContextUnreachable ContextState = "unreachable"
+)
+
+// ContextVerification is the outcome of VerifyContext
+type ContextVerification struct {
+	Context string
+	State   ContextState
+	// Err explains any state but ContextWorking
+	Err error
+}
+
+// VerifyContext loads a context of a kubeconfig file like kubectl does, exec plugin included, and reads
+// from its cluster without changing anything: API discovery tells whether the credentials authenticate,
+// then listing one namespace tells whether RBAC lets them read
+func VerifyContext(ctx context.Context, kubeconfigPath, contextName string, timeout time.Duration) ContextVerification {
+	result := ContextVerification{Context: contextName}
+
+	loader := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfigPath},
+		&clientcmd.ConfigOverrides{CurrentContext: contextName},
+	)
+	restConfig, err := loader.ClientConfig()
+	if err != nil {
+		result.State, result.Err = ContextUnreachable, fmt.Errorf("failed to load context: %w", err)
+		return result
+	}
+	restConfig.Timeout = timeout
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		result.State, result.Err = ContextUnreachable, fmt.Errorf("failed to create client: %w", err)
+		return result
+	}
+
+	// Discovery is open to every authenticated user, so only the credentials can make it fail
+	if _, err := clientset.Discovery().ServerGroups(); err != nil {
+		result.State, result.Err = classifyVerifyError(err), err
+		return result
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if _, err := clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{Limit: 1}); err != nil {
+		result.State, result.Err = classifyVerifyError(err), err
+		return result
+	}
+	result.State = ContextWorking
+	return result
+}
+
+// classifyVerifyError maps an API error to the state it reveals
+func classifyVerifyError(err error) ContextState {
+	switch {
+	case apierrors.IsUnauthorized(err):
+		return ContextUnauthorized
+	case apierrors.IsForbidden(err):
+		return ContextForbidden
+	default:
+		return ContextUnreachable
+	}
+}
@@ -0,0 +1,102 @@
+package services_kubernetes
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAPIServer answers discovery to the "reader" and "outsider" tokens, and lists namespaces to "reader" only.
+// It serves TLS because client-go only sends credentials over it
+func fakeAPIServer(t *testing.T) *httptest.Server {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		token := r.Header.Get("Authorization")
+		if token != "Bearer reader" && token != "Bearer outsider" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"Unauthorized","code":401}`)
+			return
+		}
+		switch r.URL.Path {
+		case "/api":
+			fmt.Fprint(w, `{"kind":"APIVersions","versions":["v1"]}`)
+		case "/apis":
+			fmt.Fprint(w, `{"kind":"APIGroupList","groups":[]}`)
+		case "/api/v1/namespaces":
+			if token != "Bearer reader" {
+				w.WriteHeader(http.StatusForbidden)
+				fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"Forbidden","code":403}`)
+				return
+			}
+			fmt.Fprint(w, `{"kind":"NamespaceList","apiVersion":"v1","items":[]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestVerifyContext(t *testing.T) {
+	server := fakeAPIServer(t)
+	kubeconfigPath := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, os.WriteFile(kubeconfigPath, []byte(`apiVersion: v1
+kind: Config
+clusters:
+  - name: fake
+    cluster:
+      server: `+server.URL+`
+      insecure-skip-tls-verify: true
+  - name: down
+    cluster:
+      server: https://127.0.0.1:1
+      insecure-skip-tls-verify: true
+contexts:
+  - name: reader
+    context: {cluster: fake, user: reader}
+  - name: outsider
+    context: {cluster: fake, user: outsider}
+  - name: expired
+    context: {cluster: fake, user: expired}
+  - name: down
+    context: {cluster: down, user: reader}
+users:
+  - name: reader
+    user: {token: reader}
+  - name: outsider
+    user: {token: outsider}
+  - name: expired
+    user: {token: expired}
+`), 0600))
+
+	tests := []struct {
+		context string
+		state   ContextState
+	}{
+		{context: "reader", state: ContextWorking},
+		{context: "outsider", state: ContextForbidden},
+		{context: "expired", state: ContextUnauthorized},
+		{context: "down", state: ContextUnreachable},
+		{context: "missing", state: ContextUnreachable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.context, func(t *testing.T) {
+			result := VerifyContext(context.Background(), kubeconfigPath, tt.context, 5*time.Second)
+			assert.Equal(t, tt.context, result.Context)
+			assert.Equal(t, tt.state, result.State, result.Err)
+			if tt.state == ContextWorking {
+				assert.NoError(t, result.Err)
+			} else {
+				assert.Error(t, result.Err)
+			}
+		})
+	}
+}
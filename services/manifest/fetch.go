@@ -0,0 +1,144 @@
+package services_manifest
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	ark_config "github.com/andresgarcia29/ark-cli/config"
+	"github.com/andresgarcia29/ark-cli/lib"
+	services_aws "github.com/andresgarcia29/ark-cli/services/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+)
+
+// maxManifestSize bounds what is read from a manifest location
+const maxManifestSize = 1 << 20
+
+// signatureSuffix is appended to a manifest location to find its detached signature
+const signatureSuffix = ".sig"
+
+// fetch reads a manifest and its signature from an https:// URL, s3://bucket/key, git+<repo>#<path> or a local file
+func fetch(ctx context.Context, location string) (data, signature []byte, err error) {
+	if repo, path, ok := strings.Cut(strings.TrimPrefix(location, "git+"), "#"); ok && strings.HasPrefix(location, "git+") {
+		return fetchGit(ctx, repo, path)
+	}
+
+	read := func(location string) ([]byte, error) {
+		switch {
+		case strings.HasPrefix(location, "https://"), strings.HasPrefix(location, "http://"):
+			return fetchHTTP(ctx, location)
+		case strings.HasPrefix(location, "s3://"):
+			return fetchS3(ctx, location)
+		case strings.HasPrefix(location, "git+"):
+			return nil, fmt.Errorf("git location %q must name the manifest after #, e.g. git+https://github.com/my-org/ark.git#ark.yaml", location)
+		}
+		return os.ReadFile(location)
+	}
+	if data, err = read(location); err != nil {
+		return nil, nil, err
+	}
+	if signature, err = read(location + signatureSuffix); err != nil {
+		return nil, nil, fmt.Errorf("failed to read the signature %s: %w", location+signatureSuffix, err)
+	}
+	return data, signature, nil
+}
+
+// fetchHTTP downloads a file, bounded by the AWS timeout of ark's config
+func fetchHTTP(ctx context.Context, location string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{Timeout: ark_config.Get().AWS.Timeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", location, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxManifestSize))
+}
+
+// fetchS3 downloads an object with the default AWS credentials, signing the request like the SDK would
+// A bucket in another region answers with its region, and the request is signed again for it
+func fetchS3(ctx context.Context, location string) ([]byte, error) {
+	bucket, key, ok := strings.Cut(strings.TrimPrefix(location, "s3://"), "/")
+	if !ok || bucket == "" || key == "" {
+		return nil, fmt.Errorf("%q is not an S3 location (expected s3://<bucket>/<key>)", location)
+	}
+	cfg, err := services_aws.NewAWSConfig(ctx, services_aws.ClientConfig{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to load SDK config: %w", err)
+	}
+	credentials, err := cfg.Credentials.Retrieve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get credentials for S3: %w", err)
+	}
+
+	emptyHash := sha256.Sum256(nil)
+	payloadHash := hex.EncodeToString(emptyHash[:])
+	get := func(region string) (*http.Response, error) {
+		objectURL := fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", bucket, region, (&url.URL{Path: key}).EscapedPath())
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, objectURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+		if err := v4.NewSigner().SignHTTP(ctx, credentials, req, payloadHash, "s3", region, time.Now()); err != nil {
+			return nil, fmt.Errorf("failed to sign S3 request: %w", err)
+		}
+		return cfg.HTTPClient.Do(req)
+	}
+
+	resp, err := get(cfg.Region)
+	if err != nil {
+		return nil, err
+	}
+	if region := resp.Header.Get("X-Amz-Bucket-Region"); resp.StatusCode != http.StatusOK && region != "" && region != cfg.Region {
+		resp.Body.Close()
+		if resp, err = get(region); err != nil {
+			return nil, err
+		}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", location, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxManifestSize))
+}
+
+// fetchGit reads a manifest and its signature from a shallow clone of the repository's default branch
+func fetchGit(ctx context.Context, repo, path string) ([]byte, []byte, error) {
+	if !filepath.IsLocal(path) {
+		return nil, nil, fmt.Errorf("manifest path %q must be relative to the repository", path)
+	}
+	dir, err := os.MkdirTemp("", "ark-manifest-")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	if err := lib.RunCommand(exec.CommandContext(ctx, "git", "clone", "--quiet", "--depth", "1", repo, dir)); err != nil {
+		return nil, nil, fmt.Errorf("failed to clone %s: %w", repo, err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, path))
+	if err != nil {
+		return nil, nil, err
+	}
+	signature, err := os.ReadFile(filepath.Join(dir, path+signatureSuffix))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read the signature %s: %w", path+signatureSuffix, err)
+	}
+	return data, signature, nil
+}
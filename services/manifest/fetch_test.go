@@ -0,0 +1,78 @@
+package services_manifest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchHTTP(t *testing.T) {
+	privateKey, publicKey := testKey(t)
+	signature := Sign([]byte(testManifest), privateKey)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ark/mycorp.yaml":
+			w.Write([]byte(testManifest))
+		case "/ark/mycorp.yaml.sig":
+			w.Write(signature)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	manifest, err := Load(context.Background(), Source{Org: "mycorp", Location: server.URL + "/ark/mycorp.yaml", PublicKey: publicKey})
+	require.NoError(t, err)
+	assert.Equal(t, "mycorp", manifest.Org)
+
+	_, _, err = fetch(context.Background(), server.URL+"/ark/missing.yaml")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "404 Not Found")
+}
+
+func TestFetchGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	privateKey, _ := testKey(t)
+	repo := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, "manifests"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "manifests", "mycorp.yaml"), []byte(testManifest), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "manifests", "mycorp.yaml.sig"), Sign([]byte(testManifest), privateKey), 0600))
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"add", "."},
+		{"-c", "user.name=ark", "-c", "user.email=ark@example.com", "commit", "--quiet", "-m", "manifest"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+
+	data, signature, err := fetch(context.Background(), "git+file://"+repo+"#manifests/mycorp.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, testManifest, string(data))
+	assert.Equal(t, string(Sign([]byte(testManifest), privateKey)), string(signature))
+
+	_, _, err = fetch(context.Background(), "git+file://"+repo+"#../outside.yaml")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "must be relative to the repository")
+
+	_, _, err = fetch(context.Background(), "git+file://"+repo)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "must name the manifest after #")
+}
+
+func TestFetchS3InvalidLocation(t *testing.T) {
+	_, err := fetchS3(context.Background(), "s3://bucket-only")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is not an S3 location")
+}
@@ -0,0 +1,134 @@
+package services_manifest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Manifest is what an organization publishes so `ark init --org <name>` configures every laptop the same way
+type Manifest struct {
+	// Org must match the name the manifest was fetched for, so one org's manifest can't stand in for another's
+	Org        string             `yaml:"org"`
+	SSO        SSOSettings        `yaml:"sso"`
+	Bootstrap  BootstrapSettings  `yaml:"bootstrap"`
+	Kubernetes KubernetesSettings `yaml:"kubernetes"`
+	// Settings are ark config keys written with `ark config set`, e.g. kubernetes.context_alias: "{{.Cluster}}"
+	Settings map[string]string `yaml:"settings"`
+}
+
+// SSOSettings is the SSO portal the profiles are written for
+type SSOSettings struct {
+	StartURL string `yaml:"start_url"`
+	Region   string `yaml:"region"`
+}
+
+// BootstrapSettings selects and names the profiles written by ark bootstrap
+type BootstrapSettings struct {
+	// Accounts are glob patterns of account names or IDs, e.g. "prod-*"
+	Accounts []string `yaml:"accounts"`
+	// Roles are glob patterns of the preferred roles, e.g. "ReadOnly*"
+	Roles []string `yaml:"roles"`
+	// NameTemplate names the profiles, e.g. "{{.AccountName}}-{{.RoleName}}"
+	NameTemplate string `yaml:"name_template"`
+}
+
+// KubernetesSettings are the arguments suggested for ark kubernetes setup
+type KubernetesSettings struct {
+	Regions      []string `yaml:"regions"`
+	RolePrefixes []string `yaml:"role_prefixes"`
+}
+
+// Source locates an organization's manifest and the key its signature is checked with
+type Source struct {
+	Org string
+	// Location is an https:// URL, s3://bucket/key, git+https://host/repo.git#path or a local file;
+	// the signature is read from the same location with .sig appended
+	Location string
+	// PublicKey is the Ed25519 key the manifest is signed with, base64 encoded
+	PublicKey string
+}
+
+// Load fetches the manifest of source, checks its signature, decrypts it when it is encrypted and parses it
+// Encrypted manifests are decrypted with the key of ARK_MANIFEST_KEY
+func Load(ctx context.Context, source Source) (*Manifest, error) {
+	if source.PublicKey == "" {
+		return nil, fmt.Errorf("no public key for org %s: manifests are only applied once their signature is checked", source.Org)
+	}
+	data, signature, err := fetch(ctx, source.Location)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the manifest of org %s: %w", source.Org, err)
+	}
+	if err := Verify(data, signature, source.PublicKey); err != nil {
+		return nil, fmt.Errorf("manifest of org %s from %s: %w", source.Org, source.Location, err)
+	}
+	if IsEncrypted(data) {
+		if data, err = Decrypt(data, source.Org); err != nil {
+			return nil, err
+		}
+	}
+	return Parse(data, source.Org)
+}
+
+// Parse decodes a manifest, rejecting unknown keys and a manifest written for another org
+func Parse(data []byte, org string) (*Manifest, error) {
+	manifest := &Manifest{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	if manifest.Org != org {
+		return nil, fmt.Errorf("the manifest is for org %q, not %q", manifest.Org, org)
+	}
+	if !strings.HasPrefix(manifest.SSO.StartURL, "https://") {
+		return nil, fmt.Errorf("manifest of org %s: sso.start_url must be an https:// URL", org)
+	}
+	if manifest.SSO.Region == "" {
+		manifest.SSO.Region = "us-east-1"
+	}
+	return manifest, nil
+}
+
+// Summary describes what applying the manifest changes, one line per item
+func (m *Manifest) Summary() []string {
+	lines := []string{fmt.Sprintf("SSO portal: %s (%s)", m.SSO.StartURL, m.SSO.Region)}
+	if len(m.Bootstrap.Accounts) > 0 {
+		lines = append(lines, "Accounts: "+strings.Join(m.Bootstrap.Accounts, ", "))
+	}
+	if len(m.Bootstrap.Roles) > 0 {
+		lines = append(lines, "Roles: "+strings.Join(m.Bootstrap.Roles, ", "))
+	}
+	if m.Bootstrap.NameTemplate != "" {
+		lines = append(lines, "Profile names: "+m.Bootstrap.NameTemplate)
+	}
+	if len(m.Kubernetes.Regions) > 0 {
+		lines = append(lines, "Kubernetes regions: "+strings.Join(m.Kubernetes.Regions, ", "))
+	}
+	for _, key := range m.SettingKeys() {
+		lines = append(lines, fmt.Sprintf("Setting %s: %s", key, m.Settings[key]))
+	}
+	return lines
+}
+
+// SettingKeys lists the keys of Settings in order
+func (m *Manifest) SettingKeys() []string {
+	return slices.Sorted(maps.Keys(m.Settings))
+}
+
+// KubernetesSetupCommand returns the ark kubernetes setup command for the manifest's regions, empty without any
+func (m *Manifest) KubernetesSetupCommand() string {
+	if len(m.Kubernetes.Regions) == 0 {
+		return ""
+	}
+	command := "ark kubernetes setup --regions " + strings.Join(m.Kubernetes.Regions, ",")
+	if len(m.Kubernetes.RolePrefixes) > 0 {
+		command += " --role-prefixs " + strings.Join(m.Kubernetes.RolePrefixes, ",")
+	}
+	return command
+}
@@ -0,0 +1,144 @@
+package services_manifest
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testManifest = `org: mycorp
+sso:
+  start_url: https://mycorp.awsapps.com/start
+  region: eu-west-1
+bootstrap:
+  accounts: [prod-*, staging-*]
+  roles: [ReadOnly*]
+  name_template: "{{.AccountName}}-{{.RoleName}}"
+kubernetes:
+  regions: [eu-west-1, us-east-1]
+  role_prefixes: [readonly]
+settings:
+  kubernetes.context_alias: "{{.Cluster}}-{{.Region}}"
+  aws.max_attempts: "8"
+`
+
+// testKey returns a new Ed25519 private key and its public key as base64
+func testKey(t *testing.T) (ed25519.PrivateKey, string) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return privateKey, PublicKey(privateKey)
+}
+
+// writeSigned writes a manifest and its signature to a temp directory, returning the manifest's path
+func writeSigned(t *testing.T, data []byte, privateKey ed25519.PrivateKey) string {
+	path := filepath.Join(t.TempDir(), "mycorp.yaml")
+	require.NoError(t, os.WriteFile(path, data, 0600))
+	require.NoError(t, os.WriteFile(path+".sig", Sign(data, privateKey), 0600))
+	return path
+}
+
+func TestParse(t *testing.T) {
+	manifest, err := Parse([]byte(testManifest), "mycorp")
+	require.NoError(t, err)
+	assert.Equal(t, SSOSettings{StartURL: "https://mycorp.awsapps.com/start", Region: "eu-west-1"}, manifest.SSO)
+	assert.Equal(t, []string{"prod-*", "staging-*"}, manifest.Bootstrap.Accounts)
+	assert.Equal(t, "{{.AccountName}}-{{.RoleName}}", manifest.Bootstrap.NameTemplate)
+	assert.Equal(t, []string{"aws.max_attempts", "kubernetes.context_alias"}, manifest.SettingKeys())
+	assert.Equal(t, "ark kubernetes setup --regions eu-west-1,us-east-1 --role-prefixs readonly", manifest.KubernetesSetupCommand())
+	assert.Contains(t, manifest.Summary(), "SSO portal: https://mycorp.awsapps.com/start (eu-west-1)")
+
+	// The SSO region defaults like ark bootstrap's
+	manifest, err = Parse([]byte("org: mycorp\nsso:\n  start_url: https://mycorp.awsapps.com/start\n"), "mycorp")
+	require.NoError(t, err)
+	assert.Equal(t, "us-east-1", manifest.SSO.Region)
+	assert.Empty(t, manifest.KubernetesSetupCommand())
+
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{name: "other org", data: testManifest, wantErr: `the manifest is for org "mycorp", not "othercorp"`},
+		{name: "unknown key", data: "org: othercorp\nsso:\n  start_url: https://x.awsapps.com/start\nprofiles: []\n", wantErr: "field profiles not found"},
+		{name: "no start url", data: "org: othercorp\n", wantErr: "sso.start_url must be an https:// URL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data), "othercorp")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	ctx := context.Background()
+	privateKey, publicKey := testKey(t)
+	path := writeSigned(t, []byte(testManifest), privateKey)
+
+	manifest, err := Load(ctx, Source{Org: "mycorp", Location: path, PublicKey: publicKey})
+	require.NoError(t, err)
+	assert.Equal(t, "mycorp", manifest.Org)
+
+	// Another key, or a manifest changed after signing, is refused
+	_, otherKey := testKey(t)
+	_, err = Load(ctx, Source{Org: "mycorp", Location: path, PublicKey: otherKey})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "the signature doesn't match")
+
+	require.NoError(t, os.WriteFile(path, []byte(testManifest+"  extra: true\n"), 0600))
+	_, err = Load(ctx, Source{Org: "mycorp", Location: path, PublicKey: publicKey})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "the signature doesn't match")
+
+	// A manifest is never applied unsigned
+	_, err = Load(ctx, Source{Org: "mycorp", Location: path})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no public key")
+
+	require.NoError(t, os.Remove(path+".sig"))
+	_, err = Load(ctx, Source{Org: "mycorp", Location: path, PublicKey: publicKey})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read the signature")
+}
+
+func TestLoadEncrypted(t *testing.T) {
+	ctx := context.Background()
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	t.Setenv(KeyEnvVar, base64.StdEncoding.EncodeToString(key))
+
+	encrypted, err := Encrypt([]byte(testManifest), "mycorp")
+	require.NoError(t, err)
+	assert.True(t, IsEncrypted(encrypted))
+	assert.NotContains(t, string(encrypted), "mycorp.awsapps.com")
+
+	privateKey, publicKey := testKey(t)
+	path := writeSigned(t, encrypted, privateKey)
+	manifest, err := Load(ctx, Source{Org: "mycorp", Location: path, PublicKey: publicKey})
+	require.NoError(t, err)
+	assert.Equal(t, "https://mycorp.awsapps.com/start", manifest.SSO.StartURL)
+
+	// The org is authenticated: another org's name doesn't decrypt it
+	_, err = Decrypt(encrypted, "othercorp")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to decrypt the manifest of org othercorp")
+
+	t.Setenv(KeyEnvVar, "")
+	_, err = Load(ctx, Source{Org: "mycorp", Location: path, PublicKey: publicKey})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "set ARK_MANIFEST_KEY")
+
+	t.Setenv(KeyEnvVar, "c2hvcnQ=")
+	_, err = Decrypt(encrypted, "mycorp")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "must be 32 bytes")
+}
@@ -0,0 +1,123 @@
+package services_manifest
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// KeyEnvVar provides the key of encrypted manifests as base64, 32 bytes shared through the team's secret store
+const KeyEnvVar = "ARK_MANIFEST_KEY"
+
+// header starts every encrypted manifest
+var header = []byte("ark-encrypted-manifest:v1\n")
+
+// IsEncrypted reports whether data is an encrypted manifest
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, header)
+}
+
+// Verify checks the detached signature of a manifest, base64 text, against a base64 Ed25519 public key
+// Encrypted manifests are signed once encrypted, so the signature is checked before anything is decrypted
+func Verify(data, signature []byte, publicKey string) error {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(publicKey))
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return fmt.Errorf("the public key must be an Ed25519 key of %d bytes encoded as base64", ed25519.PublicKeySize)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+	if err != nil {
+		return fmt.Errorf("the signature is not base64: %w", err)
+	}
+	if !ed25519.Verify(key, data, decoded) {
+		return fmt.Errorf("the signature doesn't match the public key; the manifest was not applied")
+	}
+	return nil
+}
+
+// Sign returns the detached signature of a manifest as base64 text
+func Sign(data []byte, privateKey ed25519.PrivateKey) []byte {
+	return []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, data)) + "\n")
+}
+
+// ReadPrivateKey reads a PEM-encoded PKCS #8 Ed25519 private key, as written by `openssl genpkey -algorithm ed25519`
+func ReadPrivateKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s is not a PEM file", path)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key %s: %w", path, err)
+	}
+	key, ok := parsed.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an Ed25519 private key", path)
+	}
+	return key, nil
+}
+
+// PublicKey returns the public key of a private key as base64, the form the org's public_key is configured in
+func PublicKey(privateKey ed25519.PrivateKey) string {
+	return base64.StdEncoding.EncodeToString(privateKey.Public().(ed25519.PublicKey))
+}
+
+// Encrypt seals a manifest with AES-256-GCM under the key of ARK_MANIFEST_KEY
+// The org name is authenticated, so one org's manifest can't be served for another
+func Encrypt(data []byte, org string) ([]byte, error) {
+	aead, err := manifestCipher()
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := aead.Seal(nonce, nonce, data, []byte(org))
+	return append(append([]byte(nil), header...), sealed...), nil
+}
+
+// Decrypt opens a manifest sealed by Encrypt
+func Decrypt(data []byte, org string) ([]byte, error) {
+	aead, err := manifestCipher()
+	if err != nil {
+		return nil, err
+	}
+	sealed := data[len(header):]
+	if len(sealed) < aead.NonceSize() {
+		return nil, fmt.Errorf("the encrypted manifest of org %s is truncated", org)
+	}
+	plain, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(org))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt the manifest of org %s (is %s the org's key?): %w", org, KeyEnvVar, err)
+	}
+	return plain, nil
+}
+
+// manifestCipher returns the AES-GCM cipher of the key in ARK_MANIFEST_KEY
+func manifestCipher() (cipher.AEAD, error) {
+	encoded := os.Getenv(KeyEnvVar)
+	if encoded == "" {
+		return nil, fmt.Errorf("the manifest is encrypted: set %s to the org's manifest key", KeyEnvVar)
+	}
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(key) != 32 {
+		return nil, fmt.Errorf("%s must be 32 bytes encoded as base64", KeyEnvVar)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid manifest key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,47 @@
+package services_manifest
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerify(t *testing.T) {
+	privateKey, publicKey := testKey(t)
+	data := []byte(testManifest)
+	signature := Sign(data, privateKey)
+
+	require.NoError(t, Verify(data, signature, publicKey))
+	// Surrounding whitespace, e.g. a trailing newline in the config, is ignored
+	require.NoError(t, Verify(data, signature, " "+publicKey+"\n"))
+
+	err := Verify(data, []byte("not base64!"), publicKey)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "the signature is not base64")
+
+	err = Verify(data, signature, "c2hvcnQ=")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Ed25519 key of 32 bytes")
+}
+
+func TestReadPrivateKey(t *testing.T) {
+	privateKey, publicKey := testKey(t)
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "key.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600))
+
+	read, err := ReadPrivateKey(path)
+	require.NoError(t, err)
+	assert.Equal(t, publicKey, PublicKey(read))
+
+	require.NoError(t, os.WriteFile(path, []byte("not pem"), 0600))
+	_, err = ReadPrivateKey(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is not a PEM file")
+}